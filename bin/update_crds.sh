@@ -45,3 +45,14 @@ if [ ! -f "${API_TMP}/kubernetes/customresourcedefinitions.gen.yaml" ]; then
 fi
 rm -f "${ROOTDIR}/install/kubernetes/helm/istio-init/files/crd-all.gen.yaml"
 cp "${API_TMP}/kubernetes/customresourcedefinitions.gen.yaml" "${ROOTDIR}/install/kubernetes/helm/istio-init/files/crd-all.gen.yaml"
+
+# Replace the validation of the CRDs with the structural schemas generated from the protos, described
+# with the proto comments of the same commit of istio/api.
+METADATA="${ROOTDIR}/galley/pkg/config/meta/metadata"
+cd "${ROOTDIR}"
+go run "${ROOTDIR}/galley/pkg/config/meta/schema/codegen/tools/openapi.main.go" \
+  "${METADATA}/metadata.yaml" "${API_TMP}" "${METADATA}/openapi.gen.yaml"
+go run "${ROOTDIR}/galley/pkg/config/meta/schema/codegen/tools/crds.main.go" \
+  "${METADATA}/metadata.yaml" "${METADATA}/openapi.gen.yaml" \
+  "${ROOTDIR}/install/kubernetes/helm/istio-init/files/crd-all.gen.yaml" \
+  "${ROOTDIR}/install/kubernetes/helm/istio-init/files/crd-mixer.yaml"
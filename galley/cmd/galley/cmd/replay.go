@@ -0,0 +1,188 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"istio.io/istio/galley/pkg/config/meta/metadata"
+	"istio.io/istio/galley/pkg/config/processing/journal"
+	"istio.io/istio/galley/pkg/config/processing/snapshotter"
+	"istio.io/istio/galley/pkg/config/processor"
+	"istio.io/istio/galley/pkg/config/processor/transforms"
+)
+
+const (
+	// replaySettleTime is the time without new snapshots after which the replay is considered complete. It is longer
+	// than the maximum wait of the debounce strategy of the snapshots.
+	replaySettleTime = 2 * time.Second
+
+	// replayPollInterval is the interval of checking the completion of the replay.
+	replayPollInterval = 100 * time.Millisecond
+)
+
+func replayCmd() *cobra.Command {
+	var (
+		filter       journal.Filter
+		domainSuffix string
+		snapshots    []string
+		outputFormat string
+		timeout      time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "replay <journal-file>",
+		Short: "Replay a recorded event journal through the config processing pipeline",
+		Long: `Replays the source events of an event journal, recorded by a Galley server running with
+--enableEventJournal and downloaded from its ctrlz journal page, through the config processing pipeline, and prints
+the events of the replay: the source events, and the events sent by the transformers for the snapshots.`,
+		Example: `curl -o journal.json http://localhost:9876/journalj/
+galley replay journal.json --resource default/reviews`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputFormat != "short" && outputFormat != "json" {
+				return fmt.Errorf("unknown output format: %q", outputFormat)
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			records, err := journal.Read(f)
+			_ = f.Close()
+			if err != nil {
+				return fmt.Errorf("invalid journal %s: %v", args[0], err)
+			}
+
+			replayed, err := replay(records, domainSuffix, snapshots, timeout)
+			if err != nil {
+				return err
+			}
+
+			var selected []journal.Record
+			for _, r := range replayed {
+				if filter.Matches(r) {
+					selected = append(selected, r)
+				}
+			}
+			if outputFormat == "json" {
+				return journal.Write(cmd.OutOrStdout(), selected)
+			}
+			printRecords(cmd.OutOrStdout(), selected)
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&filter.Resource, "resource", "",
+		"Full name (namespace/name) of the resource to print the events of")
+	cmd.PersistentFlags().StringVar(&filter.Collection, "collection", "",
+		"Collection to print the events of")
+	cmd.PersistentFlags().StringVar(&domainSuffix, "domain", "cluster.local", "DNS domain suffix")
+	cmd.PersistentFlags().StringSliceVar(&snapshots, "snapshots", []string{metadata.Default, metadata.SyntheticServiceEntry},
+		"Snapshots to build from the replayed events")
+	cmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "short", "Output format: one of json|short")
+	cmd.PersistentFlags().DurationVar(&timeout, "timeout", 30*time.Second,
+		"Maximum time to wait for the snapshots to be built")
+
+	return cmd
+}
+
+// replay the source events of the records through a processing pipeline, and returns the records of the replay.
+func replay(records []journal.Record, domainSuffix string, snapshots []string, timeout time.Duration) ([]journal.Record, error) {
+	m := metadata.MustGet()
+
+	// Keep all the records of the replay, which are the source events and the events they cause.
+	size := journal.DefaultSize
+	if n := 10 * len(records); n > size {
+		size = n
+	}
+	j := journal.New(size)
+	d := &replayDistributor{published: make(map[string]time.Time)}
+
+	rt, err := processor.Initialize(processor.Settings{
+		Metadata:           m,
+		DomainSuffix:       domainSuffix,
+		Source:             journal.NewReplaySource(records),
+		TransformProviders: transforms.Providers(m),
+		Distributor:        d,
+		EnabledSnapshots:   snapshots,
+		Journal:            j,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rt.Start()
+	defer rt.Stop()
+
+	deadline := time.Now().Add(timeout)
+	for !d.settled(snapshots, replaySettleTime) {
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("snapshots %v not built from the journal after %v: the journal may not have the "+
+				"full sync events of all the collections", snapshots, timeout)
+		}
+		time.Sleep(replayPollInterval)
+	}
+	return j.Records(journal.Filter{}), nil
+}
+
+// replayDistributor records the last time the snapshots were published.
+type replayDistributor struct {
+	mu        sync.Mutex
+	published map[string]time.Time
+}
+
+var _ snapshotter.Distributor = &replayDistributor{}
+
+// Distribute implements snapshotter.Distributor
+func (d *replayDistributor) Distribute(name string, _ *snapshotter.Snapshot) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.published[name] = time.Now()
+}
+
+// settled returns true if all the snapshots were published, and none was published since the settle time.
+func (d *replayDistributor) settled(snapshots []string, settleTime time.Duration) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, s := range snapshots {
+		t, ok := d.published[s]
+		if !ok || time.Since(t) < settleTime {
+			return false
+		}
+	}
+	return true
+}
+
+func printRecords(w io.Writer, records []journal.Record) {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "TIME\tSTAGE\tTRANSFORMER\tKIND\tCOLLECTION\tRESOURCE\tVERSION")
+	for _, r := range records {
+		var name, version string
+		if r.Event.Entry != nil {
+			name = r.Event.Entry.Metadata.Name.String()
+			version = string(r.Event.Entry.Metadata.Version)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Time.Format("15:04:05.000"), r.Stage,
+			r.Transformer, r.Event.Kind, r.Event.Source, name, version)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,57 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestReplayDistributor_Settled(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	d := &replayDistributor{published: make(map[string]time.Time)}
+	g.Expect(d.settled([]string{"a", "b"}, time.Millisecond)).To(BeFalse())
+
+	d.Distribute("a", nil)
+	d.Distribute("b", nil)
+	g.Expect(d.settled([]string{"a", "b"}, time.Hour)).To(BeFalse())
+	g.Eventually(func() bool { return d.settled([]string{"a", "b"}, time.Millisecond) }).Should(BeTrue())
+}
+
+func TestReplay_InvalidJournal(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dir, err := ioutil.TempDir("", "replay")
+	g.Expect(err).To(BeNil())
+	defer func() { _ = os.RemoveAll(dir) }()
+	file := filepath.Join(dir, "journal.json")
+	g.Expect(ioutil.WriteFile(file, []byte(`[{"kind": "Exploded"}]`), 0644)).To(Succeed())
+
+	cmd := replayCmd()
+	cmd.SetArgs([]string{file})
+	cmd.SetOutput(ioutil.Discard)
+	g.Expect(cmd.Execute()).To(MatchError(ContainSubstring("invalid journal")))
+
+	cmd = replayCmd()
+	cmd.SetArgs([]string{file, "-o", "yaml"})
+	cmd.SetOutput(ioutil.Discard)
+	g.Expect(cmd.Execute()).To(MatchError(ContainSubstring("unknown output format")))
+}
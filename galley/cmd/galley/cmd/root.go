@@ -63,6 +63,7 @@ func GetRootCmd(args []string) *cobra.Command {
 	rootCmd.PersistentFlags().AddGoFlagSet(flag.CommandLine)
 	rootCmd.AddCommand(serverCmd())
 	rootCmd.AddCommand(probeCmd())
+	rootCmd.AddCommand(replayCmd())
 	rootCmd.AddCommand(version.CobraCommand())
 
 	// TODO: We need to filter out the collaterals, as Galley has code-level dependencies on other component's code.
@@ -149,6 +149,9 @@ func serverCmd() *cobra.Command {
 		serverArgs.SinkAddress, "Address of MCP Resource Sink server for Galley to connect to. Ex: 'foo.com:1234'")
 	svr.PersistentFlags().StringVar(&serverArgs.SinkAuthMode, "sinkAuthMode",
 		serverArgs.SinkAuthMode, "Name of authentication plugin to use for connection to sink server.")
+	svr.PersistentFlags().StringSliceVar(&serverArgs.SinkAuthConfig, "sinkAuthConfig",
+		serverArgs.SinkAuthConfig, "Comma-separated list of key=values to configure the authentication plugin with. Values can't contain commas. "+
+			"Ex: 'issuer=https://foo.com,clientID=galley,scopes=mcp.read mcp.write'")
 	svr.PersistentFlags().StringSliceVar(&serverArgs.SinkMeta, "sinkMeta",
 		serverArgs.SinkMeta, "Comma-separated list of key=values to attach as metadata to outgoing sink connections. Ex: 'key=value,key2=value2'")
 	svr.PersistentFlags().BoolVar(&serverArgs.EnableServiceDiscovery, "enableServiceDiscovery", false,
@@ -160,6 +163,10 @@ func serverCmd() *cobra.Command {
 		"Enable the Fsnotify for watching config source files on the disk and implicit signaling on a config change. Explicit signaling will still be enabled")
 	svr.PersistentFlags().BoolVar(&serverArgs.EnableConfigAnalysis, "enableAnalysis", serverArgs.EnableConfigAnalysis,
 		"Enable config analysis service")
+	svr.PersistentFlags().BoolVar(&serverArgs.EnableEventJournal, "enableEventJournal", serverArgs.EnableEventJournal,
+		"Record the events of the config processing pipeline, and list them in the ctrlz journal page")
+	svr.PersistentFlags().IntVar(&serverArgs.EventJournalSize, "eventJournalSize", serverArgs.EventJournalSize,
+		"Maximum number of events kept by the event journal")
 
 	// validation config
 	svr.PersistentFlags().StringVar(&serverArgs.ValidationArgs.WebhookConfigFile,
@@ -214,6 +221,8 @@ func setupAliases() {
 	viper.RegisterAlias("general.enable_profiling", "enableProfiling")
 	viper.RegisterAlias("processing.analysis.enable", "enableAnalysis")
 	viper.RegisterAlias("processing.discovery.enable", "enableServiceDiscovery")
+	viper.RegisterAlias("processing.journal.enable", "enableEventJournal")
+	viper.RegisterAlias("processing.journal.size", "eventJournalSize")
 	viper.RegisterAlias("processing.domainSuffix", "domain")
 	viper.RegisterAlias("processing.oldprocessor", "useOldProcessor")
 	viper.RegisterAlias("processing.server.enable", "enable-server")
@@ -19,6 +19,8 @@ import (
 
 	"istio.io/istio/galley/pkg/authplugins/google"
 	"istio.io/istio/galley/pkg/authplugins/none"
+	"istio.io/istio/galley/pkg/authplugins/oidc"
+	"istio.io/istio/galley/pkg/authplugins/tokenfile"
 )
 
 // Inventory returns a slice of all supported plugins. For new plugins
@@ -27,6 +29,8 @@ func Inventory() []authplugin.InfoFn {
 	return []authplugin.InfoFn{
 		google.GetInfo,
 		none.GetInfo,
+		oidc.GetInfo,
+		tokenfile.GetInfo,
 	}
 }
 
@@ -0,0 +1,142 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oidc authenticates to the sink with access tokens obtained from an OIDC provider, with
+// the OAuth 2.0 client credentials flow.
+//
+// Config keys:
+//
+//	issuer           URL of the OIDC provider, used to discover the token endpoint.
+//	tokenURL         URL of the token endpoint. Takes precedence over issuer.
+//	clientID         Client ID. Required.
+//	clientSecret     Client secret.
+//	clientSecretFile File containing the client secret. Takes precedence over clientSecret.
+//	scopes           Space separated list of the requested scopes. The config is passed as a comma
+//	                 separated list of key=values, which can't hold commas.
+//	audience         Audience of the requested tokens, sent as the audience parameter.
+//	caCertFile       CA certificates to verify the sink with. The system roots are used if unset.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2/clientcredentials"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/oauth"
+
+	"istio.io/istio/galley/pkg/authplugin"
+)
+
+const (
+	discoveryPath    = "/.well-known/openid-configuration"
+	discoveryTimeout = 10 * time.Second
+)
+
+var httpClient = &http.Client{Timeout: discoveryTimeout}
+
+func returnAuth(config map[string]string) ([]grpc.DialOption, error) {
+	creds, err := newCredentials(config)
+	if err != nil {
+		return nil, err
+	}
+	tc := credentials.NewClientTLSFromCert(nil, "")
+	if caCertFile := config["caCertFile"]; caCertFile != "" {
+		if tc, err = credentials.NewClientTLSFromFile(caCertFile, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	grpcOpts := []grpc.DialOption{
+		grpc.WithPerRPCCredentials(creds),
+		grpc.WithTransportCredentials(tc),
+	}
+
+	return grpcOpts, nil
+}
+
+// newCredentials returns the per RPC credentials of the config. The tokens are requested when the
+// first RPC is made, and renewed when they expire.
+func newCredentials(config map[string]string) (credentials.PerRPCCredentials, error) {
+	cc := &clientcredentials.Config{
+		ClientID:     config["clientID"],
+		ClientSecret: config["clientSecret"],
+		TokenURL:     config["tokenURL"],
+		Scopes:       strings.Fields(config["scopes"]),
+	}
+	if cc.ClientID == "" {
+		return nil, fmt.Errorf("oidc: clientID is required")
+	}
+	if f := config["clientSecretFile"]; f != "" {
+		secret, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("oidc: failed to read the client secret: %v", err)
+		}
+		cc.ClientSecret = strings.TrimSpace(string(secret))
+	}
+	if audience := config["audience"]; audience != "" {
+		cc.EndpointParams = url.Values{"audience": []string{audience}}
+	}
+	if cc.TokenURL == "" {
+		issuer := config["issuer"]
+		if issuer == "" {
+			return nil, fmt.Errorf("oidc: either issuer or tokenURL is required")
+		}
+		tokenURL, err := discoverTokenURL(issuer)
+		if err != nil {
+			return nil, err
+		}
+		cc.TokenURL = tokenURL
+	}
+
+	return oauth.TokenSource{TokenSource: cc.TokenSource(context.Background())}, nil
+}
+
+// discoverTokenURL returns the token endpoint of the OIDC provider, from its discovery document.
+func discoverTokenURL(issuer string) (string, error) {
+	u := strings.TrimSuffix(issuer, "/") + discoveryPath
+	resp, err := httpClient.Get(u)
+	if err != nil {
+		return "", fmt.Errorf("oidc: failed to get the provider configuration: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("oidc: failed to get the provider configuration from %s: status %d", u, resp.StatusCode)
+	}
+
+	var doc struct {
+		TokenEndpoint string `json:"token_endpoint"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return "", fmt.Errorf("oidc: invalid provider configuration from %s: %v", u, err)
+	}
+	if doc.TokenEndpoint == "" {
+		return "", fmt.Errorf("oidc: provider configuration from %s has no token endpoint", u)
+	}
+	return doc.TokenEndpoint, nil
+}
+
+func GetInfo() authplugin.Info {
+	return authplugin.Info{
+		Name:    "OIDC",
+		GetAuth: returnAuth,
+	}
+}
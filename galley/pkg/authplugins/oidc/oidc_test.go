@@ -0,0 +1,225 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newProvider returns a local OIDC provider issuing the token to the client galley with the
+// secret s3cret. Requests to the token endpoint are recorded in requests.
+func newProvider(t *testing.T, requests *[]*http.Request) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"issuer":%q,"token_endpoint":%q}`, srv.URL, srv.URL+"/token")
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		*requests = append(*requests, r)
+		id, secret, _ := r.BasicAuth()
+		if id == "" {
+			id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+		if r.PostForm.Get("grant_type") != "client_credentials" || id != "galley" || secret != "s3cret" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("token-%d", len(*requests)),
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	})
+	srv = httptest.NewServer(mux)
+	return srv
+}
+
+func TestInfo(t *testing.T) {
+	i := GetInfo()
+	if i.Name == "" {
+		t.Error("Name not valid")
+	}
+	if i.GetAuth == nil {
+		t.Error("GetAuth not valid")
+	}
+}
+
+func TestAuth(t *testing.T) {
+	var requests []*http.Request
+	srv := newProvider(t, &requests)
+	defer srv.Close()
+
+	opts, err := returnAuth(map[string]string{
+		"issuer":       srv.URL,
+		"clientID":     "galley",
+		"clientSecret": "s3cret",
+	})
+	if err != nil {
+		t.Fatalf("Error with auth: %v", err)
+	}
+	if len(opts) != 2 {
+		t.Errorf("Should have 2 options, got %d", len(opts))
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	var requests []*http.Request
+	srv := newProvider(t, &requests)
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "oidc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	secretFile := filepath.Join(dir, "secret")
+	if err := ioutil.WriteFile(secretFile, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		config map[string]string
+	}{
+		{
+			name:   "issuer",
+			config: map[string]string{"issuer": srv.URL + "/", "clientID": "galley", "clientSecret": "s3cret"},
+		},
+		{
+			name:   "token url",
+			config: map[string]string{"tokenURL": srv.URL + "/token", "clientID": "galley", "clientSecret": "s3cret"},
+		},
+		{
+			name:   "secret file",
+			config: map[string]string{"issuer": srv.URL, "clientID": "galley", "clientSecretFile": secretFile},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			requests = nil
+			creds, err := newCredentials(c.config)
+			if err != nil {
+				t.Fatalf("newCredentials() failed: %v", err)
+			}
+			for i := 0; i < 2; i++ {
+				md, err := creds.GetRequestMetadata(context.Background())
+				if err != nil {
+					t.Fatalf("GetRequestMetadata() failed: %v", err)
+				}
+				if got := md["authorization"]; got != "Bearer token-1" {
+					t.Errorf("got authorization %q, want %q", got, "Bearer token-1")
+				}
+			}
+			if len(requests) != 1 {
+				t.Errorf("got %d token requests, want the token to be cached", len(requests))
+			}
+		})
+	}
+}
+
+func TestCredentialsParameters(t *testing.T) {
+	var requests []*http.Request
+	srv := newProvider(t, &requests)
+	defer srv.Close()
+
+	creds, err := newCredentials(map[string]string{
+		"tokenURL":     srv.URL + "/token",
+		"clientID":     "galley",
+		"clientSecret": "s3cret",
+		"scopes":       "mcp.read mcp.write",
+		"audience":     "sink.example.com",
+	})
+	if err != nil {
+		t.Fatalf("newCredentials() failed: %v", err)
+	}
+	if _, err := creds.GetRequestMetadata(context.Background()); err != nil {
+		t.Fatalf("GetRequestMetadata() failed: %v", err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("got %d token requests, want 1", len(requests))
+	}
+	if got, want := requests[0].PostForm.Get("scope"), "mcp.read mcp.write"; got != want {
+		t.Errorf("got scope %q, want %q", got, want)
+	}
+	if got, want := requests[0].PostForm.Get("audience"), "sink.example.com"; got != want {
+		t.Errorf("got audience %q, want %q", got, want)
+	}
+}
+
+func TestCredentialsErrors(t *testing.T) {
+	var requests []*http.Request
+	srv := newProvider(t, &requests)
+	defer srv.Close()
+
+	cases := []struct {
+		name   string
+		config map[string]string
+	}{
+		{
+			name:   "no client id",
+			config: map[string]string{"issuer": srv.URL},
+		},
+		{
+			name:   "no issuer nor token url",
+			config: map[string]string{"clientID": "galley"},
+		},
+		{
+			name:   "missing secret file",
+			config: map[string]string{"issuer": srv.URL, "clientID": "galley", "clientSecretFile": "/does/not/exist"},
+		},
+		{
+			name:   "no discovery document",
+			config: map[string]string{"issuer": srv.URL + "/unknown", "clientID": "galley"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := newCredentials(c.config); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestCredentialsUnauthorized(t *testing.T) {
+	var requests []*http.Request
+	srv := newProvider(t, &requests)
+	defer srv.Close()
+
+	creds, err := newCredentials(map[string]string{"issuer": srv.URL, "clientID": "galley", "clientSecret": "wrong"})
+	if err != nil {
+		t.Fatalf("newCredentials() failed: %v", err)
+	}
+	if _, err := creds.GetRequestMetadata(context.Background()); err == nil {
+		t.Error("expected error with an invalid client secret")
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tokenfile authenticates to the sink with a bearer token read from a file. The file is
+// read again when it changes, so that the token can be rotated, as with projected service account
+// tokens.
+//
+// Config keys:
+//   path       File containing the token. Required.
+//   caCertFile CA certificates to verify the sink with. The system roots are used if unset.
+package tokenfile
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"istio.io/istio/galley/pkg/authplugin"
+)
+
+func returnAuth(config map[string]string) ([]grpc.DialOption, error) {
+	path := config["path"]
+	if path == "" {
+		return nil, fmt.Errorf("tokenfile: path is required")
+	}
+	// Fail early if the token can't be read.
+	creds := &fileToken{path: path}
+	if _, err := creds.token(); err != nil {
+		return nil, err
+	}
+
+	tc := credentials.NewClientTLSFromCert(nil, "")
+	if caCertFile := config["caCertFile"]; caCertFile != "" {
+		var err error
+		if tc, err = credentials.NewClientTLSFromFile(caCertFile, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	grpcOpts := []grpc.DialOption{
+		grpc.WithPerRPCCredentials(creds),
+		grpc.WithTransportCredentials(tc),
+	}
+
+	return grpcOpts, nil
+}
+
+// fileToken is a credentials.PerRPCCredentials that reads the token from a file, and reads it
+// again whenever the file is modified.
+type fileToken struct {
+	path string
+
+	mu      sync.Mutex
+	value   string
+	modTime time.Time
+	size    int64
+}
+
+var _ credentials.PerRPCCredentials = &fileToken{}
+
+// GetRequestMetadata implements credentials.PerRPCCredentials.
+func (f *fileToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	token, err := f.token()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"authorization": "Bearer " + token}, nil
+}
+
+// RequireTransportSecurity implements credentials.PerRPCCredentials.
+func (f *fileToken) RequireTransportSecurity() bool {
+	return true
+}
+
+// token returns the current token of the file.
+func (f *fileToken) token() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("tokenfile: %v", err)
+	}
+	if f.value != "" && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.value, nil
+	}
+
+	b, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("tokenfile: %v", err)
+	}
+	value := strings.TrimSpace(string(b))
+	if value == "" {
+		return "", fmt.Errorf("tokenfile: %s is empty", f.path)
+	}
+	f.value = value
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	return f.value, nil
+}
+
+func GetInfo() authplugin.Info {
+	return authplugin.Info{
+		Name:    "TOKENFILE",
+		GetAuth: returnAuth,
+	}
+}
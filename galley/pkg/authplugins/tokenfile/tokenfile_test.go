@@ -0,0 +1,126 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenfile
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeToken(t *testing.T, path, token string, modTime time.Time) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, []byte(token), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// Set the modification time explicitly, as the resolution of the file system may be too
+	// coarse to tell the writes of a test apart.
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func authorization(t *testing.T, f *fileToken) string {
+	t.Helper()
+	md, err := f.GetRequestMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("GetRequestMetadata() failed: %v", err)
+	}
+	return md["authorization"]
+}
+
+func TestInfo(t *testing.T) {
+	i := GetInfo()
+	if i.Name == "" {
+		t.Error("Name not valid")
+	}
+	if i.GetAuth == nil {
+		t.Error("GetAuth not valid")
+	}
+}
+
+func TestAuth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokenfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "token")
+	writeToken(t, path, "token", time.Now())
+
+	opts, err := returnAuth(map[string]string{"path": path})
+	if err != nil {
+		t.Fatalf("Error with auth: %v", err)
+	}
+	if len(opts) != 2 {
+		t.Errorf("Should have 2 options, got %d", len(opts))
+	}
+
+	for _, config := range []map[string]string{
+		nil,
+		{"path": filepath.Join(dir, "missing")},
+		{"path": path, "caCertFile": filepath.Join(dir, "missing")},
+	} {
+		if _, err := returnAuth(config); err == nil {
+			t.Errorf("expected error with config %v", config)
+		}
+	}
+}
+
+func TestRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokenfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "token")
+	now := time.Now()
+
+	writeToken(t, path, "first\n", now)
+	f := &fileToken{path: path}
+	if got := authorization(t, f); got != "Bearer first" {
+		t.Errorf("got %q, want %q", got, "Bearer first")
+	}
+
+	// A rotated token of the same length.
+	writeToken(t, path, "other\n", now.Add(time.Minute))
+	if got := authorization(t, f); got != "Bearer other" {
+		t.Errorf("got %q after rotation, want %q", got, "Bearer other")
+	}
+
+	// An emptied file is an error rather than an empty token.
+	writeToken(t, path, "", now.Add(2*time.Minute))
+	if _, err := f.GetRequestMetadata(context.Background()); err == nil {
+		t.Error("expected error with an empty token file")
+	}
+
+	writeToken(t, path, "third", now.Add(3*time.Minute))
+	if got := authorization(t, f); got != "Bearer third" {
+		t.Errorf("got %q, want %q", got, "Bearer third")
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.GetRequestMetadata(context.Background()); err == nil {
+		t.Error("expected error with a removed token file")
+	}
+	if !f.RequireTransportSecurity() {
+		t.Error("bearer tokens should require transport security")
+	}
+}
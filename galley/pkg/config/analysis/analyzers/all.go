@@ -43,6 +43,7 @@ func All() []analysis.Analyzer {
 		&injection.Analyzer{},
 		&injection.VersionAnalyzer{},
 		&service.PortNameAnalyzer{},
+		&service.ProtocolDetectionAnalyzer{},
 		&sidecar.DefaultSelectorAnalyzer{},
 		&sidecar.SelectorAnalyzer{},
 		&virtualservice.ConflictingMeshGatewayHostsAnalyzer{},
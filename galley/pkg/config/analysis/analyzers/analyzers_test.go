@@ -287,6 +287,16 @@ var testGrid = []testCase{
 		analyzer:   &service.PortNameAnalyzer{},
 		expected:   []message{},
 	},
+	{
+		name:       "protocolDetectionOnServerFirstPort",
+		inputFiles: []string{"testdata/service-server-first-port.yaml"},
+		analyzer:   &service.ProtocolDetectionAnalyzer{},
+		expected: []message{
+			{msg.ProtocolDetectionOnServerFirstPort, "Service mysql.my-namespace1"},
+			{msg.ProtocolDetectionOnServerFirstPort, "Service mail.my-namespace1"},
+			{msg.ProtocolDetectionOnServerFirstPort, "Service imap.my-namespace1"},
+		},
+	},
 	{
 		name:       "sidecarDefaultSelector",
 		inputFiles: []string{"testdata/sidecar-default-selector.yaml"},
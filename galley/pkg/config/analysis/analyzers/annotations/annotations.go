@@ -24,13 +24,14 @@ import (
 	"istio.io/istio/galley/pkg/config/meta/metadata"
 	"istio.io/istio/galley/pkg/config/meta/schema/collection"
 	"istio.io/istio/galley/pkg/config/resource"
+	configannotation "istio.io/istio/pkg/config/annotation"
 )
 
 // K8sAnalyzer checks for misplaced and invalid Istio annotations in K8s resources
 type K8sAnalyzer struct{}
 
 var (
-	istioAnnotations = annotation.AllResourceAnnotations()
+	istioAnnotations = append(annotation.AllResourceAnnotations(), configannotation.All()...)
 )
 
 // Metadata implements analyzer.Analyzer
@@ -0,0 +1,122 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"strconv"
+
+	"istio.io/istio/galley/pkg/config/analysis"
+	"istio.io/istio/galley/pkg/config/analysis/msg"
+	"istio.io/istio/galley/pkg/config/meta/metadata"
+	"istio.io/istio/galley/pkg/config/meta/schema/collection"
+	"istio.io/istio/galley/pkg/config/resource"
+	"istio.io/istio/pkg/config/annotation"
+	configKube "istio.io/istio/pkg/config/kube"
+
+	v1 "k8s.io/api/core/v1"
+	k8s_labels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ProtocolDetectionAnalyzer checks for service ports where protocol detection is expected to time out.
+// Pilot treats the well known server-first service ports as TCP, so only the service ports with such
+// a target port are reported.
+// Ports for which all the selected pods disable protocol sniffing with annotations are not reported.
+type ProtocolDetectionAnalyzer struct{}
+
+var _ analysis.Analyzer = &ProtocolDetectionAnalyzer{}
+
+// Metadata implements Analyzer
+func (s *ProtocolDetectionAnalyzer) Metadata() analysis.Metadata {
+	return analysis.Metadata{
+		Name:        "service.ProtocolDetectionAnalyzer",
+		Description: "Checks for service ports where protocol detection will time out",
+		Inputs: collection.Names{
+			metadata.K8SCoreV1Services,
+			metadata.K8SCoreV1Pods,
+		},
+	}
+}
+
+// Analyze implements Analyzer
+func (s *ProtocolDetectionAnalyzer) Analyze(c analysis.Context) {
+	c.ForEach(metadata.K8SCoreV1Services, func(r *resource.Entry) bool {
+		s.analyzeService(r, c)
+		return true
+	})
+}
+
+func (s *ProtocolDetectionAnalyzer) analyzeService(r *resource.Entry, c analysis.Context) {
+	svc := r.Item.(*v1.ServiceSpec)
+	for _, port := range svc.Ports {
+		if instance := configKube.ConvertProtocol(port.Port, port.Name, port.Protocol); !instance.IsUnsupported() {
+			continue
+		}
+		protocol, ok := configKube.ServerFirstPorts[int32(port.TargetPort.IntValue())]
+		if ok && !sniffingDisabledForPods(r, svc, port, c) {
+			c.Report(metadata.K8SCoreV1Services, msg.NewProtocolDetectionOnServerFirstPort(
+				r, int(port.Port), port.TargetPort.String(), protocol))
+		}
+	}
+}
+
+// sniffingDisabledForPods returns true if the service selects pods, and all of them disable protocol
+// sniffing for the target port, either for the whole workload or for the port only.
+func sniffingDisabledForPods(r *resource.Entry, svc *v1.ServiceSpec, port v1.ServicePort, c analysis.Context) bool {
+	if len(svc.Selector) == 0 {
+		return false
+	}
+	svcNs, _ := r.Metadata.Name.InterpretAsNamespaceAndName()
+	svcSelector := k8s_labels.SelectorFromSet(svc.Selector)
+
+	matched, disabled := false, true
+	c.ForEach(metadata.K8SCoreV1Pods, func(rPod *resource.Entry) bool {
+		podNs, _ := rPod.Metadata.Name.InterpretAsNamespaceAndName()
+		pod := rPod.Item.(*v1.Pod)
+		if podNs != svcNs || !svcSelector.Matches(k8s_labels.Set(pod.ObjectMeta.Labels)) {
+			return true
+		}
+		matched = true
+		if !podDisablesSniffing(pod, port.TargetPort) {
+			disabled = false
+			return false
+		}
+		return true
+	})
+	return matched && disabled
+}
+
+// podDisablesSniffing returns true if the annotations of the pod disable protocol sniffing for
+// the target port.
+func podDisablesSniffing(pod *v1.Pod, targetPort intstr.IntOrString) bool {
+	if enabled, err := strconv.ParseBool(pod.Annotations[annotation.SidecarProtocolSniffing.Name]); err == nil && !enabled {
+		return true
+	}
+	excluded, f := pod.Annotations[annotation.SidecarProtocolSniffingExcludePorts.Name]
+	if !f {
+		return false
+	}
+	if targetPort.Type == intstr.Int {
+		return annotation.ProtocolSniffingExcludesPort(excluded, targetPort.IntValue())
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, containerPort := range container.Ports {
+			if containerPort.Name == targetPort.StrVal {
+				return annotation.ProtocolSniffingExcludesPort(excluded, int(containerPort.ContainerPort))
+			}
+		}
+	}
+	return false
+}
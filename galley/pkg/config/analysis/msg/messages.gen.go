@@ -88,6 +88,10 @@ var (
 	// PortNameIsNotUnderNamingConvention defines a diag.MessageType for message "PortNameIsNotUnderNamingConvention".
 	// Description: Port name is not under naming convention. Protocol detection is applied to the port.
 	PortNameIsNotUnderNamingConvention = diag.NewMessageType(diag.Info, "IST0118", "Port name %s (port: %d, targetPort: %s) doesn't follow the naming convention of Istio port.")
+
+	// ProtocolDetectionOnServerFirstPort defines a diag.MessageType for message "ProtocolDetectionOnServerFirstPort".
+	// Description: A port relying on protocol detection is commonly used by a server-first protocol, so detection will time out.
+	ProtocolDetectionOnServerFirstPort = diag.NewMessageType(diag.Warning, "IST0119", "Port %d (targetPort: %s) is commonly used by %s, a protocol where the server speaks first. Protocol detection will delay each connection until it times out; name the port with a tcp- prefix or list it in the sidecar.istio.io/protocolSniffingExcludePorts annotation of the pods to disable detection.")
 )
 
 // NewInternalError returns a new diag.Message based on InternalError.
@@ -289,6 +293,17 @@ func NewPortNameIsNotUnderNamingConvention(entry *resource.Entry, portName strin
 	)
 }
 
+// NewProtocolDetectionOnServerFirstPort returns a new diag.Message based on ProtocolDetectionOnServerFirstPort.
+func NewProtocolDetectionOnServerFirstPort(entry *resource.Entry, port int, targetPort string, protocol string) diag.Message {
+	return diag.NewMessage(
+		ProtocolDetectionOnServerFirstPort,
+		originOrNil(entry),
+		port,
+		targetPort,
+		protocol,
+	)
+}
+
 func originOrNil(e *resource.Entry) resource.Origin {
 	var o resource.Origin
 	if e != nil {
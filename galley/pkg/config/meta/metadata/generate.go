@@ -23,6 +23,12 @@ package metadata
 // Create collection constants
 //go:generate go run $REPO_ROOT/galley/pkg/config/meta/schema/codegen/tools/collections.main.go metadata metadata.yaml collections.gen.go
 
+// Create OpenAPI validation schemas of the collections, documented with the proto comments
+//go:generate sh -c "go run $REPO_ROOT/galley/pkg/config/meta/schema/codegen/tools/openapi.main.go metadata.yaml $(go list -m -f {{.Dir}} istio.io/api) openapi.gen.yaml"
+
+// Set the validation schemas of the CRDs in the install manifests
+//go:generate go run $REPO_ROOT/galley/pkg/config/meta/schema/codegen/tools/crds.main.go metadata.yaml openapi.gen.yaml $REPO_ROOT/install/kubernetes/helm/istio-init/files/crd-all.gen.yaml $REPO_ROOT/install/kubernetes/helm/istio-init/files/crd-mixer.yaml
+
 // Create snapshot constants
 //go:generate go run $REPO_ROOT/galley/pkg/config/meta/schema/codegen/tools/snapshots.main.go metadata metadata.yaml snapshots.gen.go
 
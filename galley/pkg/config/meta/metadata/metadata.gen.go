@@ -164,7 +164,7 @@ collections:
     protoPackage: "istio.io/api/rbac/v1alpha1"
 
   - name: "istio/rbac/v1alpha1/servicerolebindings"
-    proto: "istio.rbac.v1alpha1.ServiceRoleBindings"
+    proto: "istio.rbac.v1alpha1.ServiceRoleBinding"
     protoPackage: "istio.io/api/rbac/v1alpha1"
 
   - name: "istio/rbac/v1alpha1/serviceroles"
@@ -0,0 +1,135 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"istio.io/istio/galley/pkg/config/meta/schema"
+)
+
+const crdSeparator = "\n---\n"
+
+// CRDSchemas sets the validation of the CustomResourceDefinitions in the manifest to the structural
+// schemas of their collections, as generated by OpenAPISchemas. The order of the keys and the leading
+// comments of the manifest are kept; the definitions of resources without a schema are left as is.
+func CRDSchemas(manifest string, resources schema.KubeResources, schemas string) (string, error) {
+	var validations map[string]yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(schemas), &validations); err != nil {
+		return "", fmt.Errorf("unable to parse the schemas: %v", err)
+	}
+
+	var header []string
+	lines := strings.Split(manifest, "\n")
+	for _, l := range lines {
+		if !strings.HasPrefix(l, "#") {
+			break
+		}
+		header = append(header, l)
+	}
+	body := strings.Join(lines[len(header):], "\n")
+
+	var docs []string
+	for _, doc := range strings.Split(body, crdSeparator) {
+		if strings.TrimSpace(doc) == "" {
+			docs = append(docs, doc)
+			continue
+		}
+		var crd yaml.MapSlice
+		if err := yaml.Unmarshal([]byte(doc), &crd); err != nil {
+			return "", fmt.Errorf("unable to parse the manifest: %v", err)
+		}
+		if err := setCRDSchema(crd, resources, validations); err != nil {
+			return "", err
+		}
+		b, err := yaml.Marshal(crd)
+		if err != nil {
+			return "", err
+		}
+		docs = append(docs, string(b))
+	}
+
+	result := strings.Join(docs, crdSeparator)
+	if len(header) > 0 {
+		result = strings.Join(header, "\n") + "\n" + result
+	}
+	return result, nil
+}
+
+func setCRDSchema(crd yaml.MapSlice, resources schema.KubeResources, validations map[string]yaml.MapSlice) error {
+	if kind, _ := mapValue(crd, "kind").(string); kind != "CustomResourceDefinition" {
+		return nil
+	}
+	spec, _ := mapValue(crd, "spec").(yaml.MapSlice)
+	names, _ := mapValue(spec, "names").(yaml.MapSlice)
+	group, _ := mapValue(spec, "group").(string)
+	plural, _ := mapValue(names, "plural").(string)
+	if group == "" || plural == "" {
+		return fmt.Errorf("invalid CustomResourceDefinition: missing group or plural name")
+	}
+	version, _ := mapValue(spec, "version").(string)
+	if versions, ok := mapValue(spec, "versions").([]interface{}); ok && len(versions) > 0 {
+		if v, ok := versions[0].(yaml.MapSlice); ok {
+			version, _ = mapValue(v, "name").(string)
+		}
+	}
+
+	for _, r := range resources {
+		if r.Group != group || r.Version != version || r.Plural != plural {
+			continue
+		}
+		validation, ok := validations[r.Collection.Name.String()]
+		if !ok {
+			return nil
+		}
+		for i := range crd {
+			if crd[i].Key == "spec" {
+				crd[i].Value = setMapValue(spec, "validation", validation, "versions")
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+func mapValue(m yaml.MapSlice, key string) interface{} {
+	for _, item := range m {
+		if item.Key == key {
+			return item.Value
+		}
+	}
+	return nil
+}
+
+// setMapValue replaces the value of the key, or inserts it before the key named before.
+func setMapValue(m yaml.MapSlice, key string, value interface{}, before string) yaml.MapSlice {
+	for i := range m {
+		if m[i].Key == key {
+			m[i].Value = value
+			return m
+		}
+	}
+	for i := range m {
+		if m[i].Key == before {
+			result := append(yaml.MapSlice{}, m[:i]...)
+			result = append(result, yaml.MapItem{Key: key, Value: value})
+			return append(result, m[i:]...)
+		}
+	}
+	return append(m, yaml.MapItem{Key: key, Value: value})
+}
@@ -0,0 +1,148 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"istio.io/istio/galley/pkg/config/meta/metadata"
+	"istio.io/istio/galley/pkg/config/meta/schema"
+	"istio.io/istio/galley/pkg/config/meta/schema/collection"
+)
+
+// installCRDs holds the CRD manifests installed by the istio-init chart.
+const installCRDs = "../../../../../../install/kubernetes/helm/istio-init/files"
+
+const crdManifest = `# DO NOT EDIT
+kind: CustomResourceDefinition
+apiVersion: apiextensions.k8s.io/v1beta1
+metadata:
+  name: servicerolebindings.rbac.istio.io
+spec:
+  group: rbac.istio.io
+  names:
+    kind: ServiceRoleBinding
+    plural: servicerolebindings
+  scope: Namespaced
+  validation:
+    openAPIV3Schema:
+      type: object
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: true
+---
+kind: CustomResourceDefinition
+apiVersion: apiextensions.k8s.io/v1beta1
+metadata:
+  name: others.rbac.istio.io
+spec:
+  group: rbac.istio.io
+  names:
+    kind: Other
+    plural: others
+  scope: Namespaced
+  version: v1alpha1
+`
+
+const crdSchemas = `k8s/rbac.istio.io/v1alpha1/policy:
+  openAPIV3Schema:
+    properties:
+      spec:
+        type: object
+    type: object
+`
+
+func TestCRDSchemas(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	resources := schema.KubeResources{
+		{
+			Collection: collection.Spec{Name: collection.NewName("k8s/rbac.istio.io/v1alpha1/policy")},
+			Group:      "rbac.istio.io",
+			Version:    "v1alpha1",
+			Kind:       "ServiceRoleBinding",
+			Plural:     "servicerolebindings",
+		},
+	}
+	out, err := CRDSchemas(crdManifest, resources, crdSchemas)
+	g.Expect(err).To(BeNil())
+	g.Expect(out).To(Equal(`# DO NOT EDIT
+kind: CustomResourceDefinition
+apiVersion: apiextensions.k8s.io/v1beta1
+metadata:
+  name: servicerolebindings.rbac.istio.io
+spec:
+  group: rbac.istio.io
+  names:
+    kind: ServiceRoleBinding
+    plural: servicerolebindings
+  scope: Namespaced
+  validation:
+    openAPIV3Schema:
+      properties:
+        spec:
+          type: object
+      type: object
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: true
+
+---
+kind: CustomResourceDefinition
+apiVersion: apiextensions.k8s.io/v1beta1
+metadata:
+  name: others.rbac.istio.io
+spec:
+  group: rbac.istio.io
+  names:
+    kind: Other
+    plural: others
+  scope: Namespaced
+  version: v1alpha1
+`))
+}
+
+func TestCRDSchemas_Invalid(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	_, err := CRDSchemas("kind: CustomResourceDefinition\nspec: {}\n", nil, crdSchemas)
+	g.Expect(err).NotTo(BeNil())
+
+	_, err = CRDSchemas(crdManifest, nil, "invalid")
+	g.Expect(err).NotTo(BeNil())
+}
+
+func TestCRDSchemas_Install(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// The generated schemas, which are documented with the OpenAPI files of istio.io/api.
+	schemas, err := ioutil.ReadFile("../../metadata/openapi.gen.yaml")
+	g.Expect(err).To(BeNil())
+	resources := metadata.MustGet().KubeSource().Resources()
+
+	for _, name := range []string{"crd-all.gen.yaml", "crd-mixer.yaml"} {
+		manifest, err := ioutil.ReadFile(filepath.Join(installCRDs, name))
+		g.Expect(err).To(BeNil())
+		out, err := CRDSchemas(string(manifest), resources, string(schemas))
+		g.Expect(err).To(BeNil())
+		g.Expect(out).To(Equal(string(manifest)), "%s is out of date, run go generate", name)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"istio.io/istio/galley/pkg/config/meta/schema/collection"
+)
+
+const fieldDocsTemplate = `
+// GENERATED FILE -- DO NOT EDIT
+//
+
+package {{.PackageName}}
+
+// fieldDocs holds the documentation of the proto messages of the collections, keyed by message name, and of
+// their fields, keyed by message name and JSON field name.
+var fieldDocs = map[string]string{
+{{- range .Docs}}
+	{{printf "%q" .Key}}: {{printf "%q" .Doc}},
+{{- end}}
+}
+`
+
+// refPrefix is the prefix of the references to the messages in the OpenAPI files of istio.io/api.
+const refPrefix = "#/components/schemas/"
+
+// openAPIFile is the subset of the OpenAPI files generated in istio.io/api used for the documentation.
+type openAPIFile struct {
+	Components struct {
+		Schemas map[string]*openAPIProperty `json:"schemas"`
+	} `json:"components"`
+}
+
+type openAPIProperty struct {
+	Description          string                      `json:"description"`
+	Ref                  string                      `json:"$ref"`
+	Properties           map[string]*openAPIProperty `json:"properties"`
+	Items                *openAPIProperty            `json:"items"`
+	AdditionalProperties json.RawMessage             `json:"additionalProperties"`
+	OneOf                []*openAPIProperty          `json:"oneOf"`
+	AnyOf                []*openAPIProperty          `json:"anyOf"`
+	AllOf                []*openAPIProperty          `json:"allOf"`
+}
+
+// FieldDocs generates a Go file holding the documentation of the proto messages of the collections, and of
+// the messages they reference, read from the contents of the OpenAPI files generated in istio.io/api.
+func FieldDocs(packageName string, collections []collection.Spec, openAPIFiles [][]byte) (string, error) {
+	docs, err := ProtoDocs(collections, openAPIFiles)
+	if err != nil {
+		return "", err
+	}
+
+	type entry struct {
+		Key string
+		Doc string
+	}
+	entries := make([]entry, 0, len(docs))
+	for k, d := range docs {
+		entries = append(entries, entry{Key: k, Doc: d})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+
+	context := struct {
+		PackageName string
+		Docs        []entry
+	}{PackageName: packageName, Docs: entries}
+	return applyTemplate(fieldDocsTemplate, context)
+}
+
+// ProtoDocs returns the documentation of the proto messages of the collections, keyed by message name, and of
+// their fields, keyed by message name and JSON field name, read from the contents of the OpenAPI files
+// generated in istio.io/api. The messages referenced by the collections are included.
+func ProtoDocs(collections []collection.Spec, openAPIFiles [][]byte) (map[string]string, error) {
+	messages := make(map[string]*openAPIProperty)
+	for i, f := range openAPIFiles {
+		var parsed openAPIFile
+		if err := json.Unmarshal(f, &parsed); err != nil {
+			return nil, fmt.Errorf("OpenAPI file %d: %v", i, err)
+		}
+		for name, m := range parsed.Components.Schemas {
+			messages[name] = m
+		}
+	}
+
+	docs := make(map[string]string)
+	visited := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		m, ok := messages[name]
+		if !ok {
+			// Messages defined outside of istio.io/api, such as the well-known types, are not documented.
+			return
+		}
+		if m.Description != "" {
+			docs[name] = m.Description
+		}
+		var refs []string
+		for field, p := range properties(m) {
+			if p.Description != "" {
+				docs[name+"."+field] = p.Description
+			}
+			refs = append(refs, references(p)...)
+		}
+		sort.Strings(refs)
+		for _, ref := range refs {
+			visit(ref)
+		}
+	}
+	for _, c := range collections {
+		if strings.HasPrefix(c.ProtoPackage, "k8s.io/") {
+			continue
+		}
+		visit(c.MessageName)
+	}
+	return docs, nil
+}
+
+// ReadOpenAPIFiles reads the OpenAPI files generated next to the protos of istio.io/api.
+func ReadOpenAPIFiles(apiDir string) ([][]byte, error) {
+	var files [][]byte
+	err := filepath.Walk(apiDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".gen.json") {
+			return nil
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, b)
+		return nil
+	})
+	return files, err
+}
+
+// properties returns the fields of a message, including those of its oneofs.
+func properties(m *openAPIProperty) map[string]*openAPIProperty {
+	out := make(map[string]*openAPIProperty)
+	for name, p := range m.Properties {
+		out[name] = p
+	}
+	for _, alternatives := range [][]*openAPIProperty{m.OneOf, m.AnyOf, m.AllOf} {
+		for _, a := range alternatives {
+			for name, p := range properties(a) {
+				if _, ok := out[name]; !ok {
+					out[name] = p
+				}
+			}
+		}
+	}
+	return out
+}
+
+// references returns the names of the messages referenced by the value of a field.
+func references(p *openAPIProperty) []string {
+	if p == nil {
+		return nil
+	}
+	if p.Ref != "" {
+		return []string{strings.TrimPrefix(p.Ref, refPrefix)}
+	}
+	out := references(p.Items)
+	if len(p.AdditionalProperties) > 0 {
+		// additionalProperties is either a boolean or the schema of the values.
+		values := &openAPIProperty{}
+		if err := json.Unmarshal(p.AdditionalProperties, values); err == nil {
+			out = append(out, references(values)...)
+		}
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"istio.io/istio/galley/pkg/config/meta/schema/collection"
+)
+
+const testOpenAPIFile = `{
+  "components": {
+    "schemas": {
+      "istio.test.v1.Route": {
+        "description": "A route.",
+        "type": "object",
+        "properties": {
+          "destinations": {
+            "description": "The destinations of the route.",
+            "type": "array",
+            "items": {"$ref": "#/components/schemas/istio.test.v1.Destination"}
+          },
+          "headers": {
+            "type": "object",
+            "additionalProperties": {"$ref": "#/components/schemas/istio.test.v1.Match"}
+          }
+        }
+      },
+      "istio.test.v1.Destination": {
+        "type": "object",
+        "properties": {
+          "host": {"description": "The host of the \"destination\".", "type": "string"}
+        }
+      },
+      "istio.test.v1.Match": {
+        "type": "object",
+        "oneOf": [
+          {"required": ["exact"], "properties": {"exact": {"description": "Exact match.", "type": "string"}}},
+          {"required": ["prefix"], "properties": {"prefix": {"description": "Prefix match.", "type": "string"}}}
+        ]
+      },
+      "istio.test.v1.Unused": {
+        "description": "Not referenced by the collections.",
+        "type": "object"
+      }
+    }
+  }
+}`
+
+func TestFieldDocs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	collections := []collection.Spec{
+		{Name: collection.NewName("test/routes"), ProtoPackage: "istio.io/test/v1", MessageName: "istio.test.v1.Route"},
+		{Name: collection.NewName("k8s/services"), ProtoPackage: "k8s.io/api/core/v1", MessageName: "k8s.io.api.core.v1.ServiceSpec"},
+	}
+	out, err := FieldDocs("pkg", collections, [][]byte{[]byte(testOpenAPIFile)})
+	g.Expect(err).To(BeNil())
+
+	expected := `
+// GENERATED FILE -- DO NOT EDIT
+//
+
+package pkg
+
+// fieldDocs holds the documentation of the proto messages of the collections, keyed by message name, and of
+// their fields, keyed by message name and JSON field name.
+var fieldDocs = map[string]string{
+	"istio.test.v1.Destination.host": "The host of the \"destination\".",
+	"istio.test.v1.Match.exact": "Exact match.",
+	"istio.test.v1.Match.prefix": "Prefix match.",
+	"istio.test.v1.Route": "A route.",
+	"istio.test.v1.Route.destinations": "The destinations of the route.",
+}
+`
+	g.Expect(strings.TrimSpace(out)).To(Equal(strings.TrimSpace(expected)))
+}
+
+func TestFieldDocs_InvalidFile(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	collections := []collection.Spec{
+		{Name: collection.NewName("test/routes"), ProtoPackage: "istio.io/test/v1", MessageName: "istio.test.v1.Route"},
+	}
+	_, err := FieldDocs("pkg", collections, [][]byte{[]byte("{")})
+	g.Expect(err).NotTo(BeNil())
+}
@@ -0,0 +1,385 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+
+	"istio.io/istio/galley/pkg/config/meta/schema/collection"
+)
+
+const openAPIHeader = `# GENERATED FILE -- DO NOT EDIT
+#
+# Structural OpenAPI v3 validation schemas of the Istio collections, keyed by collection name.
+`
+
+// durationPattern matches the durations accepted by the proto JSON mapping, such as 1.5s or 1h30m.
+const durationPattern = `^-?(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+
+var (
+	durationType    = reflect.TypeOf(types.Duration{})
+	stdDurationType = reflect.TypeOf(time.Duration(0))
+	timestampType   = reflect.TypeOf(types.Timestamp{})
+	stdTimeType     = reflect.TypeOf(time.Time{})
+	structType      = reflect.TypeOf(types.Struct{})
+	valueType       = reflect.TypeOf(types.Value{})
+	listValueType   = reflect.TypeOf(types.ListValue{})
+	anyType         = reflect.TypeOf(types.Any{})
+
+	// wrapperTypes maps the wrappers of primitive values to the schema of the value.
+	wrapperTypes = map[reflect.Type]apiext.JSONSchemaProps{
+		reflect.TypeOf(types.BoolValue{}):   {Type: "boolean"},
+		reflect.TypeOf(types.StringValue{}): {Type: "string"},
+		reflect.TypeOf(types.BytesValue{}):  {Type: "string", Format: "byte"},
+		reflect.TypeOf(types.Int32Value{}):  {Type: "integer", Format: "int32"},
+		reflect.TypeOf(types.UInt32Value{}): {Type: "integer"},
+		reflect.TypeOf(types.Int64Value{}):  {XIntOrString: true},
+		reflect.TypeOf(types.UInt64Value{}): {XIntOrString: true},
+		reflect.TypeOf(types.FloatValue{}):  {Type: "number", Format: "float"},
+		reflect.TypeOf(types.DoubleValue{}): {Type: "number", Format: "double"},
+	}
+
+	preserveUnknownFields = true
+)
+
+// OpenAPISchemas generates a YAML file with the structural OpenAPI v3 validation of the CRDs for the
+// proto messages of the collections. Collections of Kubernetes built-in types are skipped. The schemas
+// are described with the first sentence of the docs, as returned by ProtoDocs.
+func OpenAPISchemas(collections []collection.Spec, docs map[string]string) (string, error) {
+	validations := make(map[string]*apiext.CustomResourceValidation)
+	for _, c := range collections {
+		if strings.HasPrefix(c.ProtoPackage, "k8s.io/") {
+			continue
+		}
+		spec, err := OpenAPISchema(c.MessageName, docs)
+		if err != nil {
+			return "", fmt.Errorf("collection %s: %v", c.Name, err)
+		}
+		validations[c.Name.String()] = &apiext.CustomResourceValidation{
+			OpenAPIV3Schema: &apiext.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]apiext.JSONSchemaProps{
+					"spec": *spec,
+				},
+			},
+		}
+	}
+
+	b, err := yaml.Marshal(validations)
+	if err != nil {
+		return "", err
+	}
+	return openAPIHeader + string(b), nil
+}
+
+// OpenAPISchema returns the structural OpenAPI v3 schema of the JSON form of the proto message, described
+// with the docs of the messages and fields.
+func OpenAPISchema(messageName string, docs map[string]string) (*apiext.JSONSchemaProps, error) {
+	t := proto.MessageType(messageName)
+	if t == nil {
+		return nil, fmt.Errorf("proto message not found: %v", messageName)
+	}
+	b := &schemaBuilder{visiting: make(map[reflect.Type]bool), docs: docs}
+	s, err := b.build(t, "")
+	if err != nil {
+		return nil, fmt.Errorf("message %s: %v", messageName, err)
+	}
+	s.Description = summary(docs[messageName])
+	return &s, nil
+}
+
+// schemaBuilder walks the Go types generated for the proto messages.
+type schemaBuilder struct {
+	// visiting holds the messages being built, to cut recursive messages.
+	visiting map[reflect.Type]bool
+	// docs holds the documentation of the fields, keyed by message name and JSON field name.
+	docs map[string]string
+}
+
+// build returns the schema of a Go type. tag is the protobuf struct tag of the field holding the value,
+// which carries the name of the enum of integer fields.
+func (b *schemaBuilder) build(t reflect.Type, tag string) (apiext.JSONSchemaProps, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case durationType, stdDurationType:
+		return apiext.JSONSchemaProps{Type: "string", Pattern: durationPattern}, nil
+	case timestampType, stdTimeType:
+		return apiext.JSONSchemaProps{Type: "string", Format: "date-time"}, nil
+	case structType, anyType:
+		return apiext.JSONSchemaProps{Type: "object", XPreserveUnknownFields: &preserveUnknownFields}, nil
+	case valueType:
+		return apiext.JSONSchemaProps{XPreserveUnknownFields: &preserveUnknownFields}, nil
+	case listValueType:
+		return apiext.JSONSchemaProps{
+			Type: "array",
+			Items: &apiext.JSONSchemaPropsOrArray{
+				Schema: &apiext.JSONSchemaProps{XPreserveUnknownFields: &preserveUnknownFields},
+			},
+		}, nil
+	}
+	if s, ok := wrapperTypes[t]; ok {
+		return s, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return b.buildMessage(t)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return apiext.JSONSchemaProps{Type: "string", Format: "byte"}, nil
+		}
+		items, err := b.build(t.Elem(), tag)
+		if err != nil {
+			return apiext.JSONSchemaProps{}, err
+		}
+		return apiext.JSONSchemaProps{
+			Type:  "array",
+			Items: &apiext.JSONSchemaPropsOrArray{Schema: &items},
+		}, nil
+	case reflect.Map:
+		// The enum of map values is found in the tag of the value.
+		values, err := b.build(t.Elem(), "")
+		if err != nil {
+			return apiext.JSONSchemaProps{}, err
+		}
+		return apiext.JSONSchemaProps{
+			Type:                 "object",
+			AdditionalProperties: &apiext.JSONSchemaPropsOrBool{Allows: true, Schema: &values},
+		}, nil
+	case reflect.Int32:
+		if enum := tagValue(tag, "enum"); enum != "" {
+			return enumSchema(enum)
+		}
+		return apiext.JSONSchemaProps{Type: "integer", Format: "int32"}, nil
+	case reflect.Uint32:
+		return apiext.JSONSchemaProps{Type: "integer"}, nil
+	case reflect.Int64, reflect.Uint64:
+		// The proto JSON mapping writes 64-bit integers as strings, and accepts both forms.
+		return apiext.JSONSchemaProps{XIntOrString: true}, nil
+	case reflect.Float32:
+		return apiext.JSONSchemaProps{Type: "number", Format: "float"}, nil
+	case reflect.Float64:
+		return apiext.JSONSchemaProps{Type: "number", Format: "double"}, nil
+	case reflect.Bool:
+		return apiext.JSONSchemaProps{Type: "boolean"}, nil
+	case reflect.String:
+		// As in the schemas of istio.io/api.
+		return apiext.JSONSchemaProps{Type: "string", Format: "string"}, nil
+	}
+
+	return apiext.JSONSchemaProps{}, fmt.Errorf("unsupported type %v", t)
+}
+
+// buildMessage returns the schema of a message. Oneof fields are properties of the message, with a
+// constraint that at most one of them is set.
+func (b *schemaBuilder) buildMessage(t reflect.Type) (apiext.JSONSchemaProps, error) {
+	if b.visiting[t] {
+		// Recursive messages can't be expressed in a structural schema.
+		return apiext.JSONSchemaProps{Type: "object", XPreserveUnknownFields: &preserveUnknownFields}, nil
+	}
+	b.visiting[t] = true
+	defer delete(b.visiting, t)
+
+	s := apiext.JSONSchemaProps{Type: "object"}
+	oneofs := oneofWrappers(t)
+	messageName := protoMessageName(t)
+	var constraints []apiext.JSONSchemaProps
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if strings.HasPrefix(f.Name, "XXX_") {
+			continue
+		}
+
+		if f.Tag.Get("protobuf_oneof") != "" {
+			var names []string
+			for _, w := range oneofs {
+				if !w.Implements(f.Type) {
+					continue
+				}
+				wf := w.Elem().Field(0)
+				name, err := b.addField(&s, messageName, wf)
+				if err != nil {
+					return apiext.JSONSchemaProps{}, err
+				}
+				names = append(names, name)
+			}
+			if len(names) > 0 {
+				constraints = append(constraints, oneofConstraint(names))
+			}
+			continue
+		}
+
+		if _, err := b.addField(&s, messageName, f); err != nil {
+			return apiext.JSONSchemaProps{}, err
+		}
+	}
+
+	switch len(constraints) {
+	case 0:
+	case 1:
+		s.OneOf = constraints[0].OneOf
+	default:
+		s.AllOf = constraints
+	}
+	return s, nil
+}
+
+// addField adds the schema of the struct field of the message as a property, and returns its JSON name.
+func (b *schemaBuilder) addField(s *apiext.JSONSchemaProps, messageName string, f reflect.StructField) (string, error) {
+	tag := f.Tag.Get("protobuf")
+	name := tagValue(tag, "json")
+	if name == "" {
+		name = tagValue(tag, "name")
+	}
+	if name == "" {
+		return "", fmt.Errorf("field %s has no proto name", f.Name)
+	}
+
+	var (
+		p   apiext.JSONSchemaProps
+		err error
+	)
+	if f.Type.Kind() == reflect.Map {
+		p, err = b.build(f.Type, "")
+		if err == nil {
+			// Map values of enum types carry their enum in a separate tag.
+			if enum := tagValue(f.Tag.Get("protobuf_val"), "enum"); enum != "" {
+				var values apiext.JSONSchemaProps
+				values, err = enumSchema(enum)
+				p.AdditionalProperties.Schema = &values
+			}
+		}
+	} else {
+		p, err = b.build(f.Type, tag)
+	}
+	if err != nil {
+		return "", fmt.Errorf("field %s: %v", name, err)
+	}
+
+	p.Description = summary(b.docs[messageName+"."+name])
+	if s.Properties == nil {
+		s.Properties = make(map[string]apiext.JSONSchemaProps)
+	}
+	s.Properties[name] = p
+	return name, nil
+}
+
+// protoMessageName returns the name of the proto message of the Go type, or "" if it isn't a message.
+func protoMessageName(t reflect.Type) string {
+	if m, ok := reflect.New(t).Interface().(proto.Message); ok {
+		return proto.MessageName(m)
+	}
+	return ""
+}
+
+// abbreviations end with a period which doesn't end a sentence.
+var abbreviations = []string{"e.g.", "i.e.", "etc."}
+
+// summary returns the first sentence of the documentation, like the descriptions of the CRDs of
+// istio.io/api.
+func summary(doc string) string {
+	doc = strings.Join(strings.Fields(doc), " ")
+	for start := 0; ; {
+		i := strings.Index(doc[start:], ". ")
+		if i < 0 {
+			return doc
+		}
+		end := start + i + 1
+		abbreviated := false
+		for _, a := range abbreviations {
+			if strings.HasSuffix(doc[:end], " "+a) || doc[:end] == a {
+				abbreviated = true
+				break
+			}
+		}
+		if !abbreviated {
+			return doc[:end]
+		}
+		start = end
+	}
+}
+
+// oneofConstraint returns a schema that allows at most one of the properties to be set: either none of
+// them is, or exactly one is.
+func oneofConstraint(names []string) apiext.JSONSchemaProps {
+	var set []apiext.JSONSchemaProps
+	for _, n := range names {
+		set = append(set, apiext.JSONSchemaProps{Required: []string{n}})
+	}
+	none := apiext.JSONSchemaProps{Not: &apiext.JSONSchemaProps{AnyOf: set}}
+	return apiext.JSONSchemaProps{OneOf: append([]apiext.JSONSchemaProps{none}, set...)}
+}
+
+// enumSchema returns the schema of an enum, as the names of its values.
+func enumSchema(enumName string) (apiext.JSONSchemaProps, error) {
+	values := proto.EnumValueMap(enumName)
+	if values == nil {
+		return apiext.JSONSchemaProps{}, fmt.Errorf("proto enum not found: %v", enumName)
+	}
+	var names []string
+	for n := range values {
+		names = append(names, n)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if values[names[i]] != values[names[j]] {
+			return values[names[i]] < values[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	s := apiext.JSONSchemaProps{Type: "string"}
+	for _, n := range names {
+		raw, _ := json.Marshal(n)
+		s.Enum = append(s.Enum, apiext.JSON{Raw: raw})
+	}
+	return s, nil
+}
+
+// oneofWrappers returns the pointer types of the wrappers of the oneof fields of a message.
+func oneofWrappers(t reflect.Type) []reflect.Type {
+	m, ok := reflect.New(t).Interface().(interface{ XXX_OneofWrappers() []interface{} })
+	if !ok {
+		return nil
+	}
+	var wrappers []reflect.Type
+	for _, w := range m.XXX_OneofWrappers() {
+		wrappers = append(wrappers, reflect.TypeOf(w))
+	}
+	return wrappers
+}
+
+// tagValue returns the value of a key=value entry of a protobuf struct tag.
+func tagValue(tag, key string) string {
+	for _, part := range strings.Split(tag, ",") {
+		if strings.HasPrefix(part, key+"=") {
+			return strings.TrimPrefix(part, key+"=")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,273 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/gogo/protobuf/jsonpb"
+	. "github.com/onsi/gomega"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	apiservervalidation "k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"istio.io/istio/galley/pkg/config/meta/metadata"
+)
+
+// sampleConfigs holds Istio configs of all kinds, used to round-trip through the schemas.
+const sampleConfigs = "../../../../../../tests/testdata/config"
+
+// loadOpenAPISchemas generates the schemas of all the collections, and parses them back.
+func loadOpenAPISchemas(t *testing.T) map[string]*apiextensions.CustomResourceValidation {
+	t.Helper()
+	g := NewGomegaWithT(t)
+
+	out, err := OpenAPISchemas(metadata.MustGet().AllCollections().All(), nil)
+	g.Expect(err).To(BeNil())
+
+	var parsed map[string]*apiext.CustomResourceValidation
+	g.Expect(yaml.Unmarshal([]byte(out), &parsed)).To(Succeed())
+
+	validations := make(map[string]*apiextensions.CustomResourceValidation)
+	for name, v := range parsed {
+		internal := &apiextensions.CustomResourceValidation{}
+		err := apiext.Convert_v1beta1_CustomResourceValidation_To_apiextensions_CustomResourceValidation(v, internal, nil)
+		g.Expect(err).To(BeNil())
+		validations[name] = internal
+	}
+	return validations
+}
+
+// validateSpec validates the spec of a resource of the collection with its schema.
+func validateSpec(t *testing.T, v *apiextensions.CustomResourceValidation, spec interface{}) field.ErrorList {
+	t.Helper()
+	validator, _, err := apiservervalidation.NewSchemaValidator(v)
+	if err != nil {
+		t.Fatalf("Failed to create the schema validator: %v", err)
+	}
+	return apiservervalidation.ValidateCustomResource(nil, map[string]interface{}{"spec": spec}, validator)
+}
+
+func TestOpenAPISchemas_Structural(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	validations := loadOpenAPISchemas(t)
+	g.Expect(validations).To(HaveKey("k8s/networking.istio.io/v1alpha3/virtualservices"))
+	g.Expect(validations).NotTo(HaveKey("k8s/core/v1/pods"))
+
+	for name, v := range validations {
+		s, err := structuralschema.NewStructural(v.OpenAPIV3Schema)
+		if err != nil {
+			t.Errorf("Collection %s: not a structural schema: %v", name, err)
+			continue
+		}
+		if errs := structuralschema.ValidateStructural(nil, s); len(errs) > 0 {
+			t.Errorf("Collection %s: not a structural schema: %v", name, errs.ToAggregate())
+		}
+	}
+}
+
+func TestOpenAPISchemas_RoundTrip(t *testing.T) {
+	validations := loadOpenAPISchemas(t)
+	resources := metadata.MustGet().KubeSource().Resources()
+
+	files, err := filepath.Glob(filepath.Join(sampleConfigs, "*.yaml"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("Failed to find the sample configs: %v", err)
+	}
+
+	validated := 0
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, doc := range strings.Split(string(b), "\n---") {
+			var obj struct {
+				APIVersion string                 `json:"apiVersion"`
+				Kind       string                 `json:"kind"`
+				Spec       map[string]interface{} `json:"spec"`
+			}
+			if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+				t.Fatalf("%s: %v", file, err)
+			}
+			group := strings.Split(obj.APIVersion, "/")[0]
+			r, found := resources.Find(group, obj.Kind)
+			if !found || obj.Spec == nil {
+				continue
+			}
+			v := validations[r.Collection.Name.String()]
+			if v == nil {
+				continue
+			}
+
+			if errs := validateSpec(t, v, obj.Spec); len(errs) > 0 {
+				t.Errorf("%s: %s is invalid: %v", file, obj.Kind, errs.ToAggregate())
+				continue
+			}
+
+			// The config written back from its proto must conform to the schema as well.
+			js, _ := json.Marshal(obj.Spec)
+			pb := r.Collection.NewProtoInstance()
+			if err := jsonpb.Unmarshal(bytes.NewReader(js), pb); err != nil {
+				t.Fatalf("%s: %v", file, err)
+			}
+			out, err := (&jsonpb.Marshaler{}).MarshalToString(pb)
+			if err != nil {
+				t.Fatalf("%s: %v", file, err)
+			}
+			var spec map[string]interface{}
+			if err := json.Unmarshal([]byte(out), &spec); err != nil {
+				t.Fatal(err)
+			}
+			if errs := validateSpec(t, v, spec); len(errs) > 0 {
+				t.Errorf("%s: %s from proto is invalid: %v", file, obj.Kind, errs.ToAggregate())
+			}
+			validated++
+		}
+	}
+
+	if validated == 0 {
+		t.Fatal("No sample config was validated")
+	}
+}
+
+func TestOpenAPISchemas_Invalid(t *testing.T) {
+	validations := loadOpenAPISchemas(t)
+
+	var cases = []struct {
+		name       string
+		collection string
+		spec       string
+		valid      bool
+	}{
+		{
+			name:       "valid",
+			collection: "k8s/networking.istio.io/v1alpha3/virtualservices",
+			spec: `
+hosts: [reviews]
+http:
+- match:
+  - uri:
+      prefix: /v1
+  timeout: 1.5s
+  route:
+  - destination:
+      host: reviews
+    weight: 100`,
+			valid: true,
+		},
+		{
+			name:       "two oneof fields",
+			collection: "k8s/networking.istio.io/v1alpha3/virtualservices",
+			spec: `
+http:
+- match:
+  - uri:
+      prefix: /v1
+      exact: /v1/foo`,
+		},
+		{
+			name:       "invalid duration",
+			collection: "k8s/networking.istio.io/v1alpha3/virtualservices",
+			spec: `
+http:
+- timeout: 10 seconds`,
+		},
+		{
+			name:       "invalid type",
+			collection: "k8s/networking.istio.io/v1alpha3/virtualservices",
+			spec: `
+http:
+- route:
+  - weight: heavy`,
+		},
+		{
+			name:       "valid enum",
+			collection: "k8s/networking.istio.io/v1alpha3/destinationrules",
+			spec: `
+host: reviews
+trafficPolicy:
+  tls:
+    mode: ISTIO_MUTUAL`,
+			valid: true,
+		},
+		{
+			name:       "invalid enum",
+			collection: "k8s/networking.istio.io/v1alpha3/destinationrules",
+			spec: `
+host: reviews
+trafficPolicy:
+  tls:
+    mode: SOMETIMES`,
+		},
+		{
+			name:       "nested enum",
+			collection: "k8s/networking.istio.io/v1alpha3/destinationrules",
+			spec: `
+host: reviews
+trafficPolicy:
+  portLevelSettings:
+  - port:
+      number: 80
+    loadBalancer:
+      simple: RANDOM`,
+			valid: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var spec map[string]interface{}
+			if err := yaml.Unmarshal([]byte(c.spec), &spec); err != nil {
+				t.Fatal(err)
+			}
+			errs := validateSpec(t, validations[c.collection], spec)
+			if c.valid && len(errs) > 0 {
+				t.Errorf("expected the spec to be valid, got %v", errs.ToAggregate())
+			}
+			if !c.valid && len(errs) == 0 {
+				t.Error("expected the spec to be invalid")
+			}
+		})
+	}
+}
+
+func TestOpenAPISchema_Descriptions(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	docs := map[string]string{
+		"istio.networking.v1alpha3.VirtualService":       "Configuration affecting traffic routing.\nSee the docs.",
+		"istio.networking.v1alpha3.VirtualService.hosts": "The destination hosts to which traffic is being sent. Could be a DNS name.",
+		"istio.networking.v1alpha3.HTTPRoute.name":       "The name assigned to the route, e.g. for debugging. Optional.",
+	}
+	s, err := OpenAPISchema("istio.networking.v1alpha3.VirtualService", docs)
+	g.Expect(err).To(BeNil())
+
+	g.Expect(s.Description).To(Equal("Configuration affecting traffic routing."))
+	g.Expect(s.Properties["hosts"].Description).To(Equal("The destination hosts to which traffic is being sent."))
+	g.Expect(s.Properties["hosts"].Items.Schema.Format).To(Equal("string"))
+	g.Expect(s.Properties["http"].Items.Schema.Properties["name"].Description).
+		To(Equal("The name assigned to the route, e.g. for debugging."))
+	g.Expect(s.Properties["gateways"].Description).To(BeEmpty())
+}
@@ -0,0 +1,69 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ignore
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"istio.io/istio/galley/pkg/config/meta/schema"
+	"istio.io/istio/galley/pkg/config/meta/schema/codegen"
+)
+
+// Utility for setting the validation schemas of the CRDs in the install manifests to the schemas
+// in openapi.gen.yaml. Called from gen.go and bin/update_crds.sh.
+func main() {
+	if len(os.Args) < 4 {
+		fmt.Printf("Invalid args: %v", os.Args)
+		os.Exit(-1)
+	}
+
+	b, err := ioutil.ReadFile(os.Args[1])
+	if err != nil {
+		fmt.Printf("Error reading metadata: %v", err)
+		os.Exit(-2)
+	}
+	m, err := schema.ParseAndBuild(string(b))
+	if err != nil {
+		fmt.Printf("Error reading metadata: %v", err)
+		os.Exit(-2)
+	}
+
+	schemas, err := ioutil.ReadFile(os.Args[2])
+	if err != nil {
+		fmt.Printf("Error reading OpenAPI schemas: %v", err)
+		os.Exit(-3)
+	}
+
+	for _, path := range os.Args[3:] {
+		manifest, err := ioutil.ReadFile(path)
+		if err != nil {
+			fmt.Printf("Error reading manifest: %v", err)
+			os.Exit(-4)
+		}
+		contents, err := codegen.CRDSchemas(string(manifest), m.KubeSource().Resources(), string(schemas))
+		if err != nil {
+			fmt.Printf("Error setting the schemas of %s: %v", path, err)
+			os.Exit(-5)
+		}
+		if err = ioutil.WriteFile(path, []byte(contents), os.ModePerm); err != nil {
+			fmt.Printf("Error writing output file: %v", err)
+			os.Exit(-6)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build ignore
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"istio.io/istio/galley/pkg/config/meta/schema"
+	"istio.io/istio/galley/pkg/config/meta/schema/codegen"
+
+	// Register the proto types of the collections.
+	_ "istio.io/istio/galley/pkg/config/meta/metadata"
+)
+
+// Utility for generating the documentation of the fields of the collections from the OpenAPI files of the
+// istio.io/api repository. Called from generate.go
+func main() {
+	if len(os.Args) != 5 {
+		fmt.Printf("Invalid args: %v", os.Args)
+		os.Exit(-1)
+	}
+
+	pkg := os.Args[1]
+	input := os.Args[2]
+	apiDir := os.Args[3]
+	output := os.Args[4]
+
+	m, err := readMetadata(input)
+	if err != nil {
+		fmt.Printf("Error reading metadata: %v", err)
+		os.Exit(-2)
+	}
+
+	files, err := codegen.ReadOpenAPIFiles(apiDir)
+	if err != nil {
+		fmt.Printf("Error reading OpenAPI files: %v", err)
+		os.Exit(-3)
+	}
+
+	contents, err := codegen.FieldDocs(pkg, m.AllCollections().All(), files)
+	if err != nil {
+		fmt.Printf("Error generating field documentation: %v", err)
+		os.Exit(-4)
+	}
+
+	if err = ioutil.WriteFile(output, []byte(contents), os.ModePerm); err != nil {
+		fmt.Printf("Error writing output file: %v", err)
+		os.Exit(-5)
+	}
+}
+
+func readMetadata(path string) (*schema.Metadata, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read input file: %v", err)
+	}
+
+	return schema.ParseAndBuild(string(b))
+}
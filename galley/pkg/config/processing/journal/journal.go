@@ -0,0 +1,295 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package journal records the events flowing through the config processing pipeline, for debugging.
+package journal
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"istio.io/istio/galley/pkg/config/event"
+	"istio.io/istio/galley/pkg/config/meta/schema/collection"
+	"istio.io/istio/galley/pkg/config/resource"
+)
+
+// DefaultSize is the default number of records kept by a Journal.
+const DefaultSize = 10000
+
+// Stage is the stage of the processing pipeline at which an event was recorded.
+type Stage string
+
+const (
+	// SourceStage is the stage of the events sent by the sources to the runtime.
+	SourceStage Stage = "source"
+
+	// TransformStage is the stage of the events sent by the transformers to the snapshots.
+	TransformStage Stage = "transform"
+)
+
+// Record is an event recorded in a Journal.
+type Record struct {
+	Time  time.Time
+	Stage Stage
+
+	// Transformer is the comma-separated input collections of the transformer which sent the event, for the
+	// TransformStage.
+	Transformer string
+
+	Event event.Event
+
+	// Baseline is set on the records standing for the evicted records of the SourceStage: an Added record
+	// for each resource they left, and the FullSync record of each collection they synced.
+	Baseline bool
+}
+
+// Filter selects the records of a Journal. Empty fields match all records.
+type Filter struct {
+	// Resource is the full name of the resources.
+	Resource string
+
+	// Collection is the collection of the events.
+	Collection string
+}
+
+// Matches returns true if the filter selects the record.
+func (f Filter) Matches(r Record) bool {
+	if f.Collection != "" && r.Event.Source.String() != f.Collection {
+		return false
+	}
+	if f.Resource != "" && (r.Event.Entry == nil || r.Event.Entry.Metadata.Name.String() != f.Resource) {
+		return false
+	}
+	return true
+}
+
+// Journal keeps the most recent events of the pipeline, up to a maximum number of records. The state left by the
+// evicted records of the SourceStage is kept as a baseline, so that the records can still be replayed.
+type Journal struct {
+	mu      sync.Mutex
+	records []Record
+	next    int
+	full    bool
+
+	baseline map[collection.Name]*baseline
+}
+
+// baseline is the state of a collection left by the evicted records.
+type baseline struct {
+	entries map[resource.Name]Record
+	synced  *Record
+}
+
+// New returns a new Journal keeping up to size records.
+func New(size int) *Journal {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return &Journal{
+		records:  make([]Record, size),
+		baseline: make(map[collection.Name]*baseline),
+	}
+}
+
+// Record the event at the given stage.
+func (j *Journal) Record(stage Stage, transformer string, e event.Event) {
+	// The entries may be modified after the event is handled.
+	r := Record{
+		Time:        time.Now(),
+		Stage:       stage,
+		Transformer: transformer,
+		Event:       e.Clone(),
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.full {
+		j.evict(j.records[j.next])
+	}
+	j.records[j.next] = r
+	j.next++
+	if j.next == len(j.records) {
+		j.next = 0
+		j.full = true
+	}
+}
+
+// Records returns the records selected by the filter, oldest first.
+func (j *Journal) Records(f Filter) []Record {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	all := j.baselineRecords()
+	if j.full {
+		all = append(all, j.records[j.next:]...)
+	}
+	all = append(all, j.records[:j.next]...)
+
+	var result []Record
+	for _, r := range all {
+		if f.Matches(r) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// evict applies an evicted record of the SourceStage to the baseline.
+func (j *Journal) evict(r Record) {
+	if r.Stage != SourceStage {
+		return
+	}
+	e := r.Event
+	if e.Kind == event.Reset {
+		// The sources restart from scratch.
+		j.baseline = make(map[collection.Name]*baseline)
+		return
+	}
+
+	b := j.baseline[e.Source]
+	if b == nil {
+		b = &baseline{entries: make(map[resource.Name]Record)}
+		j.baseline[e.Source] = b
+	}
+	r.Baseline = true
+	switch e.Kind {
+	case event.Added, event.Updated:
+		r.Event.Kind = event.Added
+		b.entries[e.Entry.Metadata.Name] = r
+	case event.Deleted:
+		delete(b.entries, e.Entry.Metadata.Name)
+	case event.FullSync:
+		b.synced = &r
+	}
+}
+
+// baselineRecords returns the records of the baseline, by collection and resource name.
+func (j *Journal) baselineRecords() []Record {
+	collections := make([]collection.Name, 0, len(j.baseline))
+	for c := range j.baseline {
+		collections = append(collections, c)
+	}
+	sort.Slice(collections, func(i, k int) bool {
+		return collections[i].String() < collections[k].String()
+	})
+
+	var result []Record
+	for _, c := range collections {
+		b := j.baseline[c]
+		names := make([]resource.Name, 0, len(b.entries))
+		for n := range b.entries {
+			names = append(names, n)
+		}
+		sort.Slice(names, func(i, k int) bool {
+			return names[i].String() < names[k].String()
+		})
+		for _, n := range names {
+			result = append(result, b.entries[n])
+		}
+		if b.synced != nil {
+			result = append(result, *b.synced)
+		}
+	}
+	return result
+}
+
+// Handler returns a handler recording the events at the given stage, before passing them to h.
+func (j *Journal) Handler(stage Stage, transformer string, h event.Handler) event.Handler {
+	return event.HandlerFromFn(func(e event.Event) {
+		j.Record(stage, transformer, e)
+		h.Handle(e)
+	})
+}
+
+// Source returns a source recording the events of s at the SourceStage.
+func (j *Journal) Source(s event.Source) event.Source {
+	return &source{Source: s, j: j}
+}
+
+// Transformers returns the transformers recording the events they send at the TransformStage.
+func (j *Journal) Transformers(xforms []event.Transformer) []event.Transformer {
+	result := make([]event.Transformer, 0, len(xforms))
+	for _, x := range xforms {
+		result = append(result, &transformer{
+			Transformer: x,
+			j:           j,
+			name:        joinNames(x.Inputs()),
+		})
+	}
+	return result
+}
+
+type source struct {
+	event.Source
+	j *Journal
+}
+
+var _ event.Source = &source{}
+
+// Dispatch implements event.Source
+func (s *source) Dispatch(h event.Handler) {
+	s.Source.Dispatch(s.j.Handler(SourceStage, "", h))
+}
+
+type transformer struct {
+	event.Transformer
+	j    *Journal
+	name string
+}
+
+var _ event.Transformer = &transformer{}
+
+// DispatchFor implements event.Transformer
+func (t *transformer) DispatchFor(c collection.Name, h event.Handler) {
+	t.Transformer.DispatchFor(c, t.j.Handler(TransformStage, t.name, h))
+}
+
+func joinNames(names collection.Names) string {
+	s := make([]string, 0, len(names))
+	for _, n := range names {
+		s = append(s, n.String())
+	}
+	return strings.Join(s, ",")
+}
+
+// Write the records as a JSON array, which Read reads.
+func Write(w io.Writer, records []Record) error {
+	if records == nil {
+		records = []Record{}
+	}
+	b, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// Read the records written by Write, or served by the ctrlz topic of the journal.
+func Read(r io.Reader) ([]Record, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	if err := json.Unmarshal(b, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,214 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"istio.io/istio/galley/pkg/config/event"
+	"istio.io/istio/galley/pkg/config/meta/schema/collection"
+	"istio.io/istio/galley/pkg/config/testing/data"
+	"istio.io/istio/galley/pkg/config/testing/fixtures"
+)
+
+func events(records []Record) []event.Event {
+	var result []event.Event
+	for _, r := range records {
+		result = append(result, r.Event)
+	}
+	return result
+}
+
+func TestJournal_Records(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	j := New(10)
+	j.Record(SourceStage, "", data.Event1Col1AddItem1)
+	j.Record(SourceStage, "", data.Event2Col1AddItem2)
+	j.Record(TransformStage, "collection1", data.Event3Col2AddItem1)
+
+	g.Expect(events(j.Records(Filter{}))).To(Equal([]event.Event{
+		data.Event1Col1AddItem1, data.Event2Col1AddItem2, data.Event3Col2AddItem1}))
+	g.Expect(events(j.Records(Filter{Resource: "n1/i1"}))).To(Equal([]event.Event{
+		data.Event1Col1AddItem1, data.Event3Col2AddItem1}))
+	g.Expect(events(j.Records(Filter{Resource: "n1/i1", Collection: "collection2"}))).To(Equal([]event.Event{
+		data.Event3Col2AddItem1}))
+	g.Expect(j.Records(Filter{Resource: "unknown/name"})).To(BeEmpty())
+
+	r := j.Records(Filter{Collection: "collection2"})[0]
+	g.Expect(r.Stage).To(Equal(TransformStage))
+	g.Expect(r.Transformer).To(Equal("collection1"))
+	g.Expect(r.Time.IsZero()).To(BeFalse())
+}
+
+func TestJournal_Evicts(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	j := New(2)
+	j.Record(TransformStage, "collection1", data.Event3Col2AddItem1)
+	j.Record(SourceStage, "", data.Event1Col1AddItem1)
+	j.Record(SourceStage, "", data.Event1Col1UpdateItem1)
+	j.Record(SourceStage, "", data.Event1Col1DeleteItem1)
+
+	// The evicted Added record is kept in the baseline.
+	records := j.Records(Filter{})
+	g.Expect(events(records)).To(Equal([]event.Event{
+		data.Event1Col1AddItem1, data.Event1Col1UpdateItem1, data.Event1Col1DeleteItem1}))
+	g.Expect(records[0].Baseline).To(BeTrue())
+	g.Expect(records[1].Baseline).To(BeFalse())
+
+	// The evicted Deleted record removes the resource from the baseline.
+	j.Record(SourceStage, "", data.Event1Col1Synced)
+	j.Record(SourceStage, "", data.Event2Col1AddItem2)
+	g.Expect(events(j.Records(Filter{}))).To(Equal([]event.Event{
+		data.Event1Col1Synced, data.Event2Col1AddItem2}))
+}
+
+func TestJournal_Baseline(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	j := New(2)
+	j.Record(SourceStage, "", data.Event1Col1AddItem1)
+	j.Record(SourceStage, "", data.Event2Col1AddItem2)
+	j.Record(SourceStage, "", data.Event1Col1Synced)
+	j.Record(SourceStage, "", data.Event1Col1UpdateItem1)
+	j.Record(SourceStage, "", data.Event1Col1DeleteItem2)
+
+	// The replay starts from the state left by the evicted records, including the initial sync.
+	updated := data.Event1Col1UpdateItem1.Clone()
+	updated.Kind = event.Added
+	s := NewReplaySource(j.Records(Filter{}))
+	acc := &fixtures.Accumulator{}
+	s.Dispatch(acc)
+	s.Start()
+	g.Expect(acc.Events()).To(Equal([]event.Event{
+		data.Event1Col1AddItem1, data.Event2Col1AddItem2, data.Event1Col1Synced,
+		data.Event1Col1UpdateItem1, data.Event1Col1DeleteItem2}))
+
+	j.Record(SourceStage, "", data.Event2Col1AddItem2)
+	g.Expect(events(j.Records(Filter{}))).To(Equal([]event.Event{
+		updated, data.Event2Col1AddItem2, data.Event1Col1Synced, data.Event1Col1DeleteItem2, data.Event2Col1AddItem2}))
+
+	// A Reset clears the baseline, as the sources restart from scratch.
+	j.Record(SourceStage, "", event.Event{Kind: event.Reset})
+	j.Record(SourceStage, "", data.Event1Col1AddItem1)
+	j.Record(SourceStage, "", data.Event1Col1Synced)
+	g.Expect(events(j.Records(Filter{}))).To(Equal([]event.Event{
+		data.Event1Col1AddItem1, data.Event1Col1Synced}))
+}
+
+func TestJournal_RecordsCopies(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	j := New(10)
+	e := data.Event1Col1AddItem1.Clone()
+	j.Record(SourceStage, "", e)
+	e.Entry.Metadata.Version = "changed"
+
+	g.Expect(j.Records(Filter{})[0].Event.Entry.Metadata.Version).To(BeEquivalentTo("v1"))
+}
+
+func TestJournal_SourceAndTransformers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	j := New(10)
+	src := &fixtures.Source{}
+	xform := fixtures.NewTransformer(collection.Names{data.Collection1}, collection.Names{data.Collection2},
+		func(tr *fixtures.Transformer, e event.Event) {
+			tr.Publish(data.Collection2, e.WithSource(data.Collection2))
+		})
+	xforms := j.Transformers([]event.Transformer{xform})
+	acc := &fixtures.Accumulator{}
+	xforms[0].DispatchFor(data.Collection2, acc)
+	j.Source(src).Dispatch(xforms[0])
+
+	src.Handle(data.Event1Col1AddItem1)
+
+	g.Expect(acc.Events()).To(Equal([]event.Event{data.Event1Col1AddItem1.WithSource(data.Collection2)}))
+	records := j.Records(Filter{})
+	g.Expect(records).To(HaveLen(2))
+	g.Expect(records[0].Stage).To(Equal(SourceStage))
+	g.Expect(records[0].Event).To(Equal(data.Event1Col1AddItem1))
+	g.Expect(records[1].Stage).To(Equal(TransformStage))
+	g.Expect(records[1].Transformer).To(Equal("collection1"))
+	g.Expect(records[1].Event).To(Equal(data.Event1Col1AddItem1.WithSource(data.Collection2)))
+}
+
+func TestWriteRead(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	j := New(10)
+	j.Record(SourceStage, "", data.Event1Col1AddItem1)
+	j.Record(SourceStage, "", data.Event1Col1DeleteItem1)
+	j.Record(SourceStage, "", data.Event1Col1Synced)
+	j.Record(SourceStage, "", event.Event{Kind: event.Reset})
+	j.Record(TransformStage, "collection1", data.Event3Col2AddItem1)
+
+	var b bytes.Buffer
+	g.Expect(Write(&b, j.Records(Filter{}))).To(Succeed())
+	records, err := Read(&b)
+	g.Expect(err).To(BeNil())
+
+	expected := j.Records(Filter{})
+	g.Expect(records).To(HaveLen(len(expected)))
+	for i := range records {
+		g.Expect(records[i].Time.Equal(expected[i].Time)).To(BeTrue())
+		g.Expect(records[i].Stage).To(Equal(expected[i].Stage))
+		g.Expect(records[i].Transformer).To(Equal(expected[i].Transformer))
+		g.Expect(records[i].Event.Kind).To(Equal(expected[i].Event.Kind))
+		g.Expect(records[i].Event.Source).To(Equal(expected[i].Event.Source))
+	}
+	g.Expect(records[0].Event.Entry.Metadata.Name).To(Equal(data.EntryN1I1V1.Metadata.Name))
+	g.Expect(records[0].Event.Entry.Metadata.Version).To(Equal(data.EntryN1I1V1.Metadata.Version))
+	g.Expect(records[0].Event.Entry.Item).To(Equal(data.EntryN1I1V1.Item))
+	g.Expect(records[1].Event.Entry.Metadata.Name).To(Equal(data.EntryN1I1V1.Metadata.Name))
+	g.Expect(records[2].Event.Entry).To(BeNil())
+}
+
+func TestRead_Invalid(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	_, err := Read(strings.NewReader(`[{"stage": "source", "kind": "Exploded"}]`))
+	g.Expect(err).NotTo(BeNil())
+
+	_, err = Read(strings.NewReader(`{`))
+	g.Expect(err).NotTo(BeNil())
+}
+
+func TestReplaySource(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	records := []Record{
+		{Stage: SourceStage, Event: data.Event1Col1AddItem1},
+		{Stage: TransformStage, Event: data.Event3Col2AddItem1},
+		{Stage: SourceStage, Event: event.Event{Kind: event.Reset}},
+		{Stage: SourceStage, Event: data.Event1Col1Synced},
+	}
+	s := NewReplaySource(records)
+	acc := &fixtures.Accumulator{}
+	s.Dispatch(acc)
+
+	s.Start()
+	g.Expect(acc.Events()).To(Equal([]event.Event{data.Event1Col1AddItem1, data.Event1Col1Synced}))
+
+	// Each start replays the events.
+	s.Stop()
+	s.Start()
+	g.Expect(acc.Events()).To(HaveLen(4))
+}
@@ -0,0 +1,71 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journal
+
+import (
+	"sync"
+
+	"istio.io/istio/galley/pkg/config/event"
+	"istio.io/istio/galley/pkg/config/scope"
+)
+
+// ReplaySource is a source sending the events recorded at the SourceStage of a journal, in order, when started.
+//
+// The Reset events are not replayed, as they would cause the processing runtime to restart the source, and the
+// replay, indefinitely.
+type ReplaySource struct {
+	mu      sync.Mutex
+	handler event.Handler
+	events  []event.Event
+}
+
+var _ event.Source = &ReplaySource{}
+
+// NewReplaySource returns a new ReplaySource for the records.
+func NewReplaySource(records []Record) *ReplaySource {
+	var events []event.Event
+	for _, r := range records {
+		if r.Stage != SourceStage || r.Event.Kind == event.Reset {
+			continue
+		}
+		events = append(events, r.Event)
+	}
+
+	return &ReplaySource{
+		handler: event.SentinelHandler(),
+		events:  events,
+	}
+}
+
+// Dispatch implements event.Source
+func (s *ReplaySource) Dispatch(h event.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handler = event.CombineHandlers(s.handler, h)
+}
+
+// Start implements event.Source
+func (s *ReplaySource) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scope.Processing.Infof("ReplaySource.Start: replaying %d events", len(s.events))
+	for _, e := range s.events {
+		s.handler.Handle(e.Clone())
+	}
+}
+
+// Stop implements event.Source
+func (s *ReplaySource) Stop() {}
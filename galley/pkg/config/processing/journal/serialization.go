@@ -0,0 +1,139 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/types"
+
+	mcp "istio.io/api/mcp/v1alpha1"
+
+	"istio.io/istio/galley/pkg/config/event"
+	"istio.io/istio/galley/pkg/config/meta/schema/collection"
+	"istio.io/istio/galley/pkg/config/resource"
+)
+
+// recordJSON is the JSON form of a Record. The resources are in their enveloped form.
+type recordJSON struct {
+	Time        time.Time       `json:"time"`
+	Stage       Stage           `json:"stage"`
+	Transformer string          `json:"transformer,omitempty"`
+	Kind        string          `json:"kind"`
+	Collection  string          `json:"collection,omitempty"`
+	Resource    json.RawMessage `json:"resource,omitempty"`
+	Baseline    bool            `json:"baseline,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (r Record) MarshalJSON() ([]byte, error) {
+	out := recordJSON{
+		Time:        r.Time,
+		Stage:       r.Stage,
+		Transformer: r.Transformer,
+		Kind:        r.Event.Kind.String(),
+		Baseline:    r.Baseline,
+	}
+	if r.Event.Kind != event.Reset {
+		out.Collection = r.Event.Source.String()
+	}
+	if r.Event.Entry != nil {
+		env, err := serialize(r.Event.Entry)
+		if err != nil {
+			return nil, err
+		}
+		var b bytes.Buffer
+		if err := (&jsonpb.Marshaler{}).Marshal(&b, env); err != nil {
+			return nil, err
+		}
+		out.Resource = b.Bytes()
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (r *Record) UnmarshalJSON(b []byte) error {
+	var in recordJSON
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+	kind, err := parseKind(in.Kind)
+	if err != nil {
+		return err
+	}
+
+	*r = Record{
+		Time:        in.Time,
+		Stage:       in.Stage,
+		Transformer: in.Transformer,
+		Event:       event.Event{Kind: kind},
+		Baseline:    in.Baseline,
+	}
+	if in.Collection != "" {
+		r.Event.Source = collection.NewName(in.Collection)
+	}
+	if len(in.Resource) > 0 {
+		env := &mcp.Resource{}
+		if err := jsonpb.Unmarshal(bytes.NewReader(in.Resource), env); err != nil {
+			return fmt.Errorf("invalid resource of %s event: %v", in.Kind, err)
+		}
+		if r.Event.Entry, err = deserialize(env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serialize envelopes the entry. The entries of the Deleted events have no body.
+func serialize(e *resource.Entry) (*mcp.Resource, error) {
+	if e.Item != nil {
+		return resource.Serialize(e)
+	}
+	m, err := resource.SerializeMetadata(e.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.Resource{Metadata: m}, nil
+}
+
+func deserialize(env *mcp.Resource) (*resource.Entry, error) {
+	if env.Metadata == nil {
+		env.Metadata = &mcp.Metadata{}
+	}
+	if env.Metadata.CreateTime == nil {
+		env.Metadata.CreateTime = &types.Timestamp{}
+	}
+	if env.Body != nil {
+		return resource.Deserialize(env)
+	}
+	m, err := resource.DeserializeMetadata(env.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &resource.Entry{Metadata: m}, nil
+}
+
+func parseKind(s string) (event.Kind, error) {
+	for k := event.None; k <= event.Reset; k++ {
+		if k.String() == s {
+			return k, nil
+		}
+	}
+	return event.None, fmt.Errorf("unknown event kind: %q", s)
+}
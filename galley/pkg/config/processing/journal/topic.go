@@ -0,0 +1,125 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package journal
+
+import (
+	"html/template"
+	"net/http"
+
+	"istio.io/pkg/ctrlz/fw"
+)
+
+const journalTemplate = `{{ define "content" }}
+
+<p>
+    The events recorded by the config processing pipeline, oldest first: the events sent by the sources, and the
+    events sent by the transformers for the snapshots. The JSON form of the records can be replayed offline with
+    'galley replay'.
+</p>
+
+<form method="get">
+    <input type="text" name="resource" placeholder="namespace/name" value="{{.Filter.Resource}}">
+    <input type="text" name="collection" placeholder="collection" value="{{.Filter.Collection}}">
+    <input type="submit" value="Filter">
+</form>
+
+<table>
+    <thead>
+        <tr>
+            <th>Time</th>
+            <th>Stage</th>
+            <th>Transformer</th>
+            <th>Kind</th>
+            <th>Collection</th>
+            <th>Resource</th>
+            <th>Version</th>
+        </tr>
+    </thead>
+
+    <tbody>
+        {{ range .Records }}
+        <tr>
+            <td>{{.Time.Format "2006-01-02T15:04:05.000Z07:00"}}</td>
+            <td>{{.Stage}}</td>
+            <td>{{.Transformer}}</td>
+            <td>{{.Event.Kind}}</td>
+            <td>{{.Event.Source}}</td>
+            {{ if .Event.Entry }}
+            <td><a href="?resource={{.Event.Entry.Metadata.Name}}">{{.Event.Entry.Metadata.Name}}</a></td>
+            <td>{{.Event.Entry.Metadata.Version}}</td>
+            {{ else }}
+            <td></td>
+            <td></td>
+            {{ end }}
+        </tr>
+        {{ end }}
+    </tbody>
+</table>
+
+{{ end }}
+`
+
+// topic is a ctrlz topic that lists the records of a journal.
+type topic struct {
+	j *Journal
+}
+
+var _ fw.Topic = &topic{}
+
+// Topic returns a ctrlz topic that lists the records of the journal, selected by the resource and collection
+// query parameters.
+func Topic(j *Journal) fw.Topic {
+	return &topic{j: j}
+}
+
+// Title is implementation of Topic.Title.
+func (t *topic) Title() string {
+	return "Processing Journal"
+}
+
+// Prefix is implementation of Topic.Prefix.
+func (t *topic) Prefix() string {
+	return "journal"
+}
+
+// Activate is implementation of Topic.Activate.
+func (t *topic) Activate(context fw.TopicContext) {
+	l := template.Must(context.Layout().Clone())
+	tmpl := template.Must(l.Parse(journalTemplate))
+
+	_ = context.HTMLRouter().StrictSlash(true).NewRoute().Path("/").HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		f := filterFromRequest(req)
+		fw.RenderHTML(w, tmpl, struct {
+			Filter  Filter
+			Records []Record
+		}{Filter: f, Records: t.j.Records(f)})
+	})
+
+	_ = context.JSONRouter().StrictSlash(true).NewRoute().Methods("GET").Path("/").HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		records := t.j.Records(filterFromRequest(req))
+		if records == nil {
+			records = []Record{}
+		}
+		fw.RenderJSON(w, http.StatusOK, records)
+	})
+}
+
+func filterFromRequest(req *http.Request) Filter {
+	q := req.URL.Query()
+	return Filter{
+		Resource:   q.Get("resource"),
+		Collection: q.Get("collection"),
+	}
+}
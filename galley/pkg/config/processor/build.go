@@ -18,6 +18,7 @@ import (
 	"istio.io/istio/galley/pkg/config/event"
 	"istio.io/istio/galley/pkg/config/meta/schema"
 	"istio.io/istio/galley/pkg/config/processing"
+	"istio.io/istio/galley/pkg/config/processing/journal"
 	"istio.io/istio/galley/pkg/config/processing/snapshotter"
 	"istio.io/istio/galley/pkg/config/processing/snapshotter/strategy"
 	"istio.io/istio/galley/pkg/config/processing/transformer"
@@ -32,6 +33,9 @@ type Settings struct {
 	TransformProviders transformer.Providers
 	Distributor        snapshotter.Distributor
 	EnabledSnapshots   []string
+
+	// Journal records the events of the sources and of the transformers, if set.
+	Journal *journal.Journal
 }
 
 // Initialize a processing runtime for Galley.
@@ -61,6 +65,9 @@ func Initialize(settings Settings) (*processing.Runtime, error) {
 	// This is passed as a provider so it can be evaluated once ProcessorOptions become available
 	procProvider := func(o processing.ProcessorOptions) event.Processor {
 		xforms := settings.TransformProviders.Create(o)
+		if settings.Journal != nil {
+			xforms = settings.Journal.Transformers(xforms)
+		}
 
 		s, err := snapshotter.NewSnapshotter(xforms, options)
 		if err != nil {
@@ -69,10 +76,15 @@ func Initialize(settings Settings) (*processing.Runtime, error) {
 		return s
 	}
 
+	src := settings.Source
+	if settings.Journal != nil {
+		src = settings.Journal.Source(src)
+	}
+
 	rtOpt := processing.RuntimeOptions{
 		ProcessorProvider: procProvider,
 		DomainSuffix:      settings.DomainSuffix,
-		Source:            settings.Source,
+		Source:            src,
 	}
 
 	return processing.NewRuntime(rtOpt), nil
@@ -15,6 +15,7 @@
 package processor
 
 import (
+	"bytes"
 	"testing"
 	"time"
 
@@ -23,6 +24,7 @@ import (
 	"istio.io/istio/galley/pkg/config/event"
 	"istio.io/istio/galley/pkg/config/meshcfg"
 	"istio.io/istio/galley/pkg/config/meta/metadata"
+	"istio.io/istio/galley/pkg/config/processing/journal"
 	"istio.io/istio/galley/pkg/config/processing/snapshotter"
 	"istio.io/istio/galley/pkg/config/processor/transforms"
 	"istio.io/istio/galley/pkg/config/source/kube/inmemory"
@@ -80,3 +82,54 @@ func TestProcessor(t *testing.T) {
 	time.Sleep(time.Second)
 	_ = distributor.GetSnapshot("default")
 }
+
+func TestProcessor_JournalReplay(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	meshSrc := meshcfg.NewInmemory()
+	src := inmemory.NewKubeSource(metadata.MustGet().KubeSource().Resources())
+	meshSrc.Set(meshcfg.Default())
+	j := journal.New(journal.DefaultSize)
+
+	processorSettings := Settings{
+		Metadata:           metadata.MustGet(),
+		DomainSuffix:       "svc.local",
+		Source:             event.CombineSources(meshSrc, src),
+		TransformProviders: transforms.Providers(metadata.MustGet()),
+		Distributor:        snapshotter.NewInMemoryDistributor(),
+		EnabledSnapshots:   []string{metadata.Default},
+		Journal:            j,
+	}
+
+	rt, err := Initialize(processorSettings)
+	g.Expect(err).To(BeNil())
+	rt.Start()
+	defer rt.Stop()
+
+	err = src.ApplyContent("foo", yml)
+	g.Expect(err).To(BeNil())
+
+	gateways := journal.Filter{Resource: "helloworld-gateway", Collection: "istio/networking/v1alpha3/gateways"}
+	g.Eventually(func() []journal.Record { return j.Records(gateways) }).Should(HaveLen(1))
+	g.Expect(j.Records(gateways)[0].Stage).To(Equal(journal.TransformStage))
+	g.Expect(j.Records(journal.Filter{Resource: "helloworld-gateway",
+		Collection: "k8s/networking.istio.io/v1alpha3/gateways"})[0].Stage).To(Equal(journal.SourceStage))
+
+	var b bytes.Buffer
+	g.Expect(journal.Write(&b, j.Records(journal.Filter{}))).To(Succeed())
+	records, err := journal.Read(&b)
+	g.Expect(err).To(BeNil())
+
+	// The replay of the journal produces the same transformed events.
+	replayed := journal.New(journal.DefaultSize)
+	processorSettings.Source = journal.NewReplaySource(records)
+	processorSettings.Distributor = snapshotter.NewInMemoryDistributor()
+	processorSettings.Journal = replayed
+	rt2, err := Initialize(processorSettings)
+	g.Expect(err).To(BeNil())
+	rt2.Start()
+	defer rt2.Stop()
+
+	g.Eventually(func() []journal.Record { return replayed.Records(gateways) }).Should(HaveLen(1))
+	g.Expect(replayed.Records(gateways)[0].Event.Entry.Item).To(Equal(j.Records(gateways)[0].Event.Entry.Item))
+}
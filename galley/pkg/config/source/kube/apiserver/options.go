@@ -20,6 +20,7 @@ import (
 	"istio.io/istio/galley/pkg/config/meta/schema"
 	"istio.io/istio/galley/pkg/config/source/kube"
 	"istio.io/istio/galley/pkg/config/source/kube/apiserver/status"
+	"istio.io/istio/pkg/kube/events"
 )
 
 // Options for the kube controller
@@ -33,5 +34,9 @@ type Options struct {
 
 	StatusController status.Controller
 
+	// EventRecorder emits the errors found by the analysis as events on the resources. The events
+	// are not emitted if nil.
+	EventRecorder *events.Recorder
+
 	// TODO: Add target namespaces here when we do namespace specific listeners.
 }
@@ -30,6 +30,7 @@ import (
 	"istio.io/istio/galley/pkg/config/scope"
 	"istio.io/istio/galley/pkg/config/source/kube/apiserver/status"
 	"istio.io/istio/galley/pkg/config/source/kube/rt"
+	"istio.io/istio/pkg/kube/events"
 )
 
 var (
@@ -246,6 +247,38 @@ func (s *Source) Update(messages diag.Messages) {
 	}
 
 	s.statusCtl.Report(messages)
+	s.recordEvents(messages)
+}
+
+// recordEvents emits the errors found by the analysis as events on the resources they are about.
+func (s *Source) recordEvents(messages diag.Messages) {
+	if s.options.EventRecorder == nil {
+		return
+	}
+
+	for _, m := range messages {
+		if m.Type.Level() != diag.Error {
+			continue
+		}
+		origin, ok := m.Origin.(*rt.Origin)
+		if !ok {
+			continue
+		}
+
+		ns, name := origin.Name.InterpretAsNamespaceAndName()
+		ref := events.ObjectReference("", origin.Kind, ns, name)
+		for _, r := range s.options.Resources {
+			if r.Collection.Name == origin.Collection {
+				ref.APIVersion = r.Version
+				if r.Group != "" {
+					ref.APIVersion = r.Group + "/" + r.Version
+				}
+				break
+			}
+		}
+		s.options.EventRecorder.Eventf(ref, events.Warning, "ConfigAnalysisError", "[%s] %s",
+			m.Type.Code(), fmt.Sprintf(m.Type.Template(), m.Parameters...))
+	}
 }
 
 func (s *Source) stop() {
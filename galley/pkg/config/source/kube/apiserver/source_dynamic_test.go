@@ -34,6 +34,7 @@ import (
 	"istio.io/istio/galley/pkg/config/testing/basicmeta"
 	"istio.io/istio/galley/pkg/config/testing/fixtures"
 	"istio.io/istio/galley/pkg/testing/mock"
+	"istio.io/istio/pkg/kube/events"
 
 	"github.com/gogo/protobuf/types"
 	. "github.com/onsi/gomega"
@@ -131,6 +132,47 @@ func TestReport(t *testing.T) {
 	g.Expect(sc.latestReport()).To(Equal(diag.Messages{m}))
 }
 
+func TestReport_EmitsEvents(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// Create the source
+	w, _, cl := createMocks()
+	defer w.Stop()
+
+	recorder, emitted := events.NewFakeRecorder(2)
+	o := apiserver.Options{
+		Resources:        basicmeta.MustGet().KubeSource().Resources(),
+		Client:           cl,
+		StatusController: &statusCtl{},
+		EventRecorder:    recorder,
+	}
+	s := apiserver.New(o)
+
+	s.Start()
+	defer s.Stop()
+
+	e := resource.Entry{
+		Origin: &rt.Origin{
+			Collection: basicmeta.Collection1,
+			Kind:       "Kind1",
+			Name:       resource.NewName("foo", "bar"),
+			Version:    resource.Version("v1"),
+		},
+	}
+	s.Update(diag.Messages{msg.NewInternalError(&e, "foo"), msg.NewDeprecated(&e, "foo")})
+
+	// Only the errors are emitted as events.
+	g.Expect(emitted).To(HaveLen(1))
+	event := <-emitted
+	g.Expect(event.Ref.APIVersion).To(Equal("testdata.istio.io/v1alpha1"))
+	g.Expect(event.Ref.Kind).To(Equal("Kind1"))
+	g.Expect(event.Ref.Namespace).To(Equal("foo"))
+	g.Expect(event.Ref.Name).To(Equal("bar"))
+	g.Expect(event.Type).To(Equal(events.Warning))
+	g.Expect(event.Reason).To(Equal("ConfigAnalysisError"))
+	g.Expect(event.Message).To(Equal("[IST0001] Internal error: foo"))
+}
+
 func TestEvents(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -61,14 +61,15 @@ func defaultCalloutPT() calloutPatchTable {
 // newCallout initializes a callout struct. Address should be the
 // "host:port" of the server to dial. Auth should be the name of an
 // existing auth plugin under
-// istio.io/istio/galley/pkg/authplugins. Metadata elements should be
-// in the format of "key=value".
-func newCallout(address, auth string, metadata metadata.MD,
+// istio.io/istio/galley/pkg/authplugins, and authConfig its
+// configuration. Metadata elements should be in the format of
+// "key=value".
+func newCallout(address, auth string, authConfig map[string]string, metadata metadata.MD,
 	so *source.Options) (*callout, error) {
-	return newCalloutPT(address, auth, metadata, so, defaultCalloutPT())
+	return newCalloutPT(address, auth, authConfig, metadata, so, defaultCalloutPT())
 }
 
-func newCalloutPT(address, auth string, metadata metadata.MD, so *source.Options,
+func newCalloutPT(address, auth string, authConfig map[string]string, metadata metadata.MD, so *source.Options,
 	pt calloutPatchTable) (*callout, error) {
 	auths := authplugins.AuthMap()
 
@@ -77,7 +78,7 @@ func newCalloutPT(address, auth string, metadata metadata.MD, so *source.Options
 		return nil, fmt.Errorf("auth plugin %v not found", auth)
 	}
 
-	opts, err := f(nil)
+	opts, err := f(authConfig)
 	if err != nil {
 		return nil, err
 	}
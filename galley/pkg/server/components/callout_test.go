@@ -26,7 +26,7 @@ import (
 )
 
 func TestCallout(t *testing.T) {
-	co, err := newCallout("foo", "NONE", metadata.MD{"foo": []string{"bar"}}, &source.Options{})
+	co, err := newCallout("foo", "NONE", nil, metadata.MD{"foo": []string{"bar"}}, &source.Options{})
 	if err != nil {
 		t.Errorf("Callout creation failed: %v", err)
 	}
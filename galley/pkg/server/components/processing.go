@@ -25,6 +25,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/keepalive"
 	grpcMetadata "google.golang.org/grpc/metadata"
+	"k8s.io/client-go/kubernetes"
 
 	mcp "istio.io/api/mcp/v1alpha1"
 
@@ -38,6 +39,7 @@ import (
 	"istio.io/istio/galley/pkg/config/meta/schema"
 	"istio.io/istio/galley/pkg/config/meta/schema/collection"
 	"istio.io/istio/galley/pkg/config/processing"
+	"istio.io/istio/galley/pkg/config/processing/journal"
 	"istio.io/istio/galley/pkg/config/processing/snapshotter"
 	"istio.io/istio/galley/pkg/config/processor"
 	"istio.io/istio/galley/pkg/config/processor/groups"
@@ -48,6 +50,7 @@ import (
 	"istio.io/istio/galley/pkg/config/util/kuberesource"
 	"istio.io/istio/galley/pkg/server/process"
 	"istio.io/istio/galley/pkg/server/settings"
+	"istio.io/istio/pkg/kube/events"
 	configz "istio.io/istio/pkg/mcp/configz/server"
 	"istio.io/istio/pkg/mcp/creds"
 	"istio.io/istio/pkg/mcp/monitoring"
@@ -66,6 +69,7 @@ type Processing struct {
 
 	mcpCache     *snapshot.Cache
 	configzTopic fw.Topic
+	journal      *journal.Journal
 
 	k kube.Interfaces
 
@@ -77,6 +81,7 @@ type Processing struct {
 	callOut       *callout
 	listenerMutex sync.Mutex
 	listener      net.Listener
+	recorder      *events.Recorder
 	stopCh        chan struct{}
 }
 
@@ -85,11 +90,15 @@ var _ process.Component = &Processing{}
 // NewProcessing returns a new processing component.
 func NewProcessing(a *settings.Args) *Processing {
 	mcpCache := snapshot.New(groups.IndexFunction)
-	return &Processing{
+	p := &Processing{
 		args:         a,
 		mcpCache:     mcpCache,
 		configzTopic: configz.CreateTopic(mcpCache),
 	}
+	if a.EnableEventJournal {
+		p.journal = journal.New(a.EventJournalSize)
+	}
+	return p
 }
 
 // Start implements process.Component
@@ -142,6 +151,7 @@ func (p *Processing) Start() (err error) {
 		TransformProviders: transformProviders,
 		Distributor:        distributor,
 		EnabledSnapshots:   p.args.Snapshots,
+		Journal:            p.journal,
 	}
 	if p.runtime, err = processorInitialize(processorSettings); err != nil {
 		return
@@ -184,7 +194,11 @@ func (p *Processing) Start() (err error) {
 	}
 
 	if p.args.SinkAddress != "" {
-		p.callOut, err = newCallout(p.args.SinkAddress, p.args.SinkAuthMode, md, options)
+		var authConfig map[string]string
+		if authConfig, err = parseSinkAuthConfig(p.args.SinkAuthConfig); err != nil {
+			return
+		}
+		p.callOut, err = newCallout(p.args.SinkAddress, p.args.SinkAuthMode, authConfig, md, options)
 		if err != nil {
 			p.callOut = nil
 			err = fmt.Errorf("callout could not be initialized: %v", err)
@@ -256,6 +270,14 @@ func (p *Processing) ConfigZTopic() fw.Topic {
 	return p.configzTopic
 }
 
+// JournalTopic returns the ctrlz topic of the event journal, or nil if the journal is disabled.
+func (p *Processing) JournalTopic() fw.Topic {
+	if p.journal == nil {
+		return nil
+	}
+	return journal.Topic(p.journal)
+}
+
 func (p *Processing) getServerGrpcOptions() []grpc.ServerOption {
 	var grpcOptions []grpc.ServerOption
 	grpcOptions = append(grpcOptions,
@@ -307,6 +329,12 @@ func (p *Processing) createSourceAndStatusUpdater(resources schema.KubeResources
 		var statusCtl status.Controller
 		if p.args.EnableConfigAnalysis {
 			statusCtl = status.NewController("validationMessages")
+
+			var client kubernetes.Interface
+			if client, err = k.KubeClient(); err != nil {
+				return
+			}
+			p.recorder = events.NewRecorder(client.CoreV1(), "galley")
 		}
 
 		o := apiserver.Options{
@@ -314,6 +342,7 @@ func (p *Processing) createSourceAndStatusUpdater(resources schema.KubeResources
 			ResyncPeriod:     p.args.ResyncPeriod,
 			Resources:        resources,
 			StatusController: statusCtl,
+			EventRecorder:    p.recorder,
 		}
 		s := apiserver.New(o)
 		src = s
@@ -339,6 +368,11 @@ func (p *Processing) Stop() {
 		p.runtime = nil
 	}
 
+	if p.recorder != nil {
+		p.recorder.Shutdown()
+		p.recorder = nil
+	}
+
 	p.listenerMutex.Lock()
 	if p.listener != nil {
 		_ = p.listener.Close()
@@ -379,6 +413,18 @@ func (p *Processing) Address() net.Addr {
 	return l.Addr()
 }
 
+func parseSinkAuthConfig(pairs []string) (map[string]string, error) {
+	config := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("sinkAuthConfig not in key=value format, values can't contain commas: %v", p)
+		}
+		config[kv[0]] = kv[1]
+	}
+	return config, nil
+}
+
 func parseSinkMeta(pairs []string, md grpcMetadata.MD) error {
 	for _, p := range pairs {
 		kv := strings.Split(p, "=")
@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
 	k8sRuntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/dynamic/fake"
 
@@ -130,3 +131,25 @@ func TestProcessing_Basic(t *testing.T) {
 
 	g.Expect(p.Address()).To(BeNil())
 }
+
+func TestParseSinkAuthConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// The config is parsed from a comma-separated flag, so the values can't hold commas.
+	var pairs []string
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringSliceVar(&pairs, "sinkAuthConfig", nil, "")
+	err := flags.Parse([]string{"--sinkAuthConfig=issuer=https://foo.com/a=b,clientID=galley,scopes=mcp.read mcp.write"})
+	g.Expect(err).To(BeNil())
+
+	config, err := parseSinkAuthConfig(pairs)
+	g.Expect(err).To(BeNil())
+	g.Expect(config).To(Equal(map[string]string{
+		"issuer":   "https://foo.com/a=b",
+		"clientID": "galley",
+		"scopes":   "mcp.read mcp.write",
+	}))
+
+	_, err = parseSinkAuthConfig([]string{"scopes=mcp.read", "mcp.write"})
+	g.Expect(err).NotTo(BeNil())
+}
@@ -53,6 +53,9 @@ func New(a *settings.Args) *Server {
 		s.host.Add(s.p)
 		t := s.p.ConfigZTopic()
 		topics = append(topics, t)
+		if jt := s.p.JournalTopic(); jt != nil {
+			topics = append(topics, jt)
+		}
 	}
 
 	mon := components.NewMonitoring(a.MonitoringPort)
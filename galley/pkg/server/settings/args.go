@@ -25,6 +25,7 @@ import (
 	"k8s.io/client-go/rest"
 
 	"istio.io/istio/galley/pkg/config/meta/metadata"
+	"istio.io/istio/galley/pkg/config/processing/journal"
 	"istio.io/istio/galley/pkg/config/util/kuberesource"
 	"istio.io/istio/galley/pkg/crd/validation"
 	"istio.io/istio/pkg/keepalive"
@@ -111,6 +112,10 @@ type Args struct { // nolint:maligned
 	// see the istio.io/istio/galley/pkg/autplugins package.
 	SinkAuthMode string
 
+	// SinkAuthConfig list of key=values to configure the authentication
+	// plugin with.
+	SinkAuthConfig []string
+
 	// SinkMeta list of key=values to attach as gRPC stream metadata to
 	// outgoing Sink connections.
 	SinkMeta []string
@@ -136,6 +141,12 @@ type Args struct { // nolint:maligned
 	// DEPRECATED
 	DisableResourceReadyCheck bool
 
+	// EnableEventJournal records the events of the config processing pipeline, and lists them in ctrlz.
+	EnableEventJournal bool
+
+	// EventJournalSize is the number of events kept by the event journal.
+	EventJournalSize int
+
 	// WatchConfigFiles if set to true, enables Fsnotify watcher for watching and signaling config file changes.
 	// Default is false
 	WatchConfigFiles bool
@@ -176,6 +187,7 @@ func DefaultArgs() *Args {
 		DisableResourceReadyCheck:   false,
 		ExcludedResourceKinds:       kuberesource.DefaultExcludedResourceKinds(),
 		SinkMeta:                    make([]string, 0),
+		SinkAuthConfig:              make([]string, 0),
 		KeepAlive:                   keepalive.DefaultOption(),
 		ValidationArgs:              validation.DefaultArgs(),
 		MonitoringPort:              15014,
@@ -183,6 +195,8 @@ func DefaultArgs() *Args {
 		PprofPort:                   9094,
 		WatchConfigFiles:            false,
 		EnableConfigAnalysis:        false,
+		EnableEventJournal:          false,
+		EventJournalSize:            journal.DefaultSize,
 		Liveness: probe.Options{
 			Path:           defaultLivenessProbeFilePath,
 			UpdateInterval: defaultProbeCheckInterval,
@@ -222,7 +236,10 @@ func (a *Args) String() string {
 	_, _ = fmt.Fprintf(buf, "ExcludedResourceKinds: %v\n", a.ExcludedResourceKinds)
 	_, _ = fmt.Fprintf(buf, "SinkAddress: %v\n", a.SinkAddress)
 	_, _ = fmt.Fprintf(buf, "SinkAuthMode: %v\n", a.SinkAuthMode)
+	_, _ = fmt.Fprintf(buf, "SinkAuthConfig: %v\n", a.SinkAuthConfig)
 	_, _ = fmt.Fprintf(buf, "SinkMeta: %v\n", a.SinkMeta)
+	_, _ = fmt.Fprintf(buf, "EnableEventJournal: %v\n", a.EnableEventJournal)
+	_, _ = fmt.Fprintf(buf, "EventJournalSize: %d\n", a.EventJournalSize)
 	_, _ = fmt.Fprintf(buf, "KeepAlive.MaxServerConnectionAge: %v\n", a.KeepAlive.MaxServerConnectionAge)
 	_, _ = fmt.Fprintf(buf, "KeepAlive.MaxServerConnectionAgeGrace: %v\n", a.KeepAlive.MaxServerConnectionAgeGrace)
 	_, _ = fmt.Fprintf(buf, "KeepAlive.Time: %v\n", a.KeepAlive.Time)
@@ -0,0 +1,221 @@
+// Copyright 2019 Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"istio.io/istio/istioctl/pkg/certreport"
+	istioctl_kubernetes "istio.io/istio/istioctl/pkg/kubernetes"
+	"istio.io/istio/security/pkg/k8s/configmap"
+)
+
+func certReportCmd() *cobra.Command {
+	var (
+		rootCertFile    string
+		outputFormat    string
+		expiryThreshold time.Duration
+		rotationRatio   float64
+		timeout         time.Duration
+		concurrency     int
+	)
+	cmd := &cobra.Command{
+		Use:   "cert-report",
+		Short: "Report the state of the workload certificates of all the proxies of the mesh",
+		Long: `Retrieves the workload certificate of each proxy from the /certs endpoint of its Envoy, and checks it against
+the current root certificate of the mesh. The chains of the certificates delivered through SDS are verified from the
+config dump of the Envoy. The proxies are listed by urgency: those with expired certificates, with certificates not
+signed by the current root or not trusting it, with certificates expiring within the threshold, and then with
+certificates whose rotation failed.
+
+The current root certificate is the one published by Citadel in the istio-security configmap, unless --root-cert
+is set.
+`,
+		Example: `# Report the certificates of all the proxies of the mesh
+istioctl experimental cert-report
+
+# Report the certificates of the proxies of the default namespace, expiring within a day, in JSON
+istioctl experimental cert-report -n default --threshold 24h -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputFormat != summaryOutput && outputFormat != jsonOutput {
+				return fmt.Errorf("output format %q not supported", outputFormat)
+			}
+			if concurrency < 1 {
+				return fmt.Errorf("concurrency must be at least 1, got %d", concurrency)
+			}
+			if timeout <= 0 {
+				return fmt.Errorf("timeout must be positive, got %v", timeout)
+			}
+			client, err := interfaceFactory(kubeconfig)
+			if err != nil {
+				return err
+			}
+			roots, err := currentRootCerts(client, rootCertFile)
+			if err != nil {
+				return err
+			}
+			if roots == nil {
+				_, _ = fmt.Fprintf(cmd.OutOrStderr(),
+					"Warning: no root certificate found in %s, the certificate chains are not verified\n", istioNamespace)
+			}
+			pods, err := client.CoreV1().Pods(namespace).List(metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+			execClient, err := clientExecFactory(kubeconfig, configContext)
+			if err != nil {
+				return err
+			}
+
+			options := certreport.Options{
+				Roots:           roots,
+				ExpiryThreshold: expiryThreshold,
+				RotationRatio:   rotationRatio,
+				Now:             time.Now(),
+			}
+			var proxies []*v1.Pod
+			for i := range pods.Items {
+				pod := &pods.Items[i]
+				if pod.Status.Phase == v1.PodRunning && hasProxyContainer(pod) {
+					proxies = append(proxies, pod)
+				}
+			}
+			reports := make([]certreport.ProxyReport, len(proxies))
+			slots := make(chan struct{}, concurrency)
+			var wg sync.WaitGroup
+			for i, pod := range proxies {
+				wg.Add(1)
+				slots <- struct{}{}
+				go func(i int, pod *v1.Pod) {
+					defer wg.Done()
+					reports[i] = proxyCertReport(execClient, pod, timeout, options)
+					<-slots
+				}(i, pod)
+			}
+			wg.Wait()
+			certreport.Sort(reports)
+
+			if outputFormat == jsonOutput {
+				return certreport.PrintJSON(cmd.OutOrStdout(), reports)
+			}
+			return certreport.PrintTabular(cmd.OutOrStdout(), reports)
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&rootCertFile, "root-cert", "",
+		"File of the PEM encoded root certificates of the mesh, instead of the root certificate published by Citadel.")
+	cmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", summaryOutput, "Output format: one of json|short")
+	cmd.PersistentFlags().DurationVar(&expiryThreshold, "threshold", 6*time.Hour,
+		"Report the certificates expiring within this duration.")
+	cmd.PersistentFlags().Float64Var(&rotationRatio, "rotation-ratio", 0.25,
+		"Report a failed rotation for the certificates with less than this ratio of their lifetime remaining. "+
+			"The agents rotate the certificates with half of their lifetime remaining by default.")
+	cmd.PersistentFlags().DurationVar(&timeout, "timeout", 30*time.Second,
+		"Report the certificate of a proxy as unknown if its Envoy doesn't answer within this duration.")
+	cmd.PersistentFlags().IntVar(&concurrency, "concurrency", 10,
+		"Number of proxies whose certificates are retrieved in parallel.")
+	return cmd
+}
+
+// proxyCertReport checks the certificate of the proxy of the pod. The config dump of the Envoy is
+// only retrieved for the certificates delivered through SDS.
+func proxyCertReport(execClient istioctl_kubernetes.ExecClient, pod *v1.Pod, timeout time.Duration,
+	options certreport.Options) certreport.ProxyReport {
+	proxy := fmt.Sprintf("%s.%s", pod.Name, pod.Namespace)
+	deadline := time.Now().Add(timeout)
+	certs, err := envoyDoBefore(execClient, pod, "certs", deadline)
+	if err != nil {
+		return certreport.UnknownReport(proxy, err)
+	}
+	return certreport.Analyze(proxy, certs, func() ([]byte, error) {
+		return envoyDoBefore(execClient, pod, "config_dump", deadline)
+	}, options)
+}
+
+// envoyDoBefore gets the path of the Envoy admin API of the pod, and gives up at the deadline. The
+// exec client can't be cancelled, the request is left to complete in the background.
+func envoyDoBefore(execClient istioctl_kubernetes.ExecClient, pod *v1.Pod, path string, deadline time.Time) ([]byte, error) {
+	type result struct {
+		out []byte
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		out, err := execClient.EnvoyDo(pod.Name, pod.Namespace, "GET", path, nil)
+		done <- result{out, err}
+	}()
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.out, r.err
+	case <-timer.C:
+		return nil, fmt.Errorf("no answer from Envoy to GET %s within the timeout", path)
+	}
+}
+
+// currentRootCerts returns the root certificates of the file, or those published by Citadel, or nil
+// if they aren't published.
+func currentRootCerts(client kubernetes.Interface, rootCertFile string) ([]*x509.Certificate, error) {
+	var rootPEM []byte
+	if rootCertFile != "" {
+		var err error
+		if rootPEM, err = ioutil.ReadFile(rootCertFile); err != nil {
+			return nil, err
+		}
+	} else {
+		cm, err := client.CoreV1().ConfigMaps(istioNamespace).Get(configmap.IstioSecurityConfigMapName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		root, ok := cm.Data[configmap.CATLSRootCertName]
+		if !ok {
+			return nil, nil
+		}
+		// Citadel publishes the root certificate base64 encoded.
+		if rootPEM, err = base64.StdEncoding.DecodeString(root); err != nil {
+			return nil, fmt.Errorf("invalid root certificate in configmap %s: %v", configmap.IstioSecurityConfigMapName, err)
+		}
+	}
+	roots, err := certreport.ParseRoots(rootPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root certificate: %v", err)
+	}
+	return roots, nil
+}
+
+func hasProxyContainer(pod *v1.Pod) bool {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == proxyContainerName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+// Copyright 2019 Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/istio/istioctl/pkg/kubernetes"
+	"istio.io/istio/pilot/test/util"
+	"istio.io/istio/security/pkg/k8s/configmap"
+)
+
+func certReportCmdObjects() []runtime.Object {
+	pod := func(namespace, name string, phase coreV1.PodPhase, containers ...string) *coreV1.Pod {
+		p := &coreV1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Status:     coreV1.PodStatus{Phase: phase},
+		}
+		for _, c := range containers {
+			p.Spec.Containers = append(p.Spec.Containers, coreV1.Container{Name: c})
+		}
+		return p
+	}
+	return []runtime.Object{
+		pod("default", "details-v1-5b7f94f9bc-wp5tb", coreV1.PodRunning, "details", proxyContainerName),
+		pod("default", "unreachable", coreV1.PodRunning, "app", proxyContainerName),
+		pod("default", "pending", coreV1.PodPending, "app", proxyContainerName),
+		pod("default", "no-sidecar", coreV1.PodRunning, "app"),
+	}
+}
+
+// certReportExecClient answers the requests to the Envoy admin API by pod and path, and doesn't
+// answer the requests to the pod named hung until it's closed.
+type certReportExecClient struct {
+	mockExecConfig
+	paths map[string]map[string][]byte
+	hung  chan struct{}
+}
+
+func (client certReportExecClient) EnvoyDo(podName, podNamespace, method, path string, body []byte) ([]byte, error) {
+	if podName == "hung" {
+		<-client.hung
+	}
+	results, ok := client.paths[podName][path]
+	if !ok {
+		return nil, fmt.Errorf("unable to retrieve %s of Pod: pods %q not found", path, podName)
+	}
+	return results, nil
+}
+
+func TestCertReport(t *testing.T) {
+	execClient := certReportExecClient{
+		paths: map[string]map[string][]byte{
+			"details-v1-5b7f94f9bc-wp5tb": {
+				"certs":       util.ReadFile("testdata/certreport/certs.json", t),
+				"config_dump": util.ReadFile("../pkg/writer/compare/testdata/envoyconfigdump.json", t),
+			},
+		},
+		hung: make(chan struct{}),
+	}
+	defer close(execClient.hung)
+	hung := &coreV1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "hung"},
+		Spec:       coreV1.PodSpec{Containers: []coreV1.Container{{Name: proxyContainerName}}},
+		Status:     coreV1.PodStatus{Phase: coreV1.PodRunning},
+	}
+	invalidRoot := &coreV1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: configmap.IstioSecurityConfigMapName},
+		Data:       map[string]string{configmap.CATLSRootCertName: "invalid"},
+	}
+	cases := []struct {
+		description       string
+		args              []string
+		k8sConfigs        []runtime.Object
+		expectedStrings   []string
+		expectedException bool
+	}{
+		{
+			description: "all proxies",
+			args:        strings.Split("experimental cert-report", " "),
+			k8sConfigs:  certReportCmdObjects(),
+			expectedStrings: []string{
+				"Warning: no root certificate found in istio-system",
+				"details-v1-5b7f94f9bc-wp5tb.default     EXPIRED",
+				"unreachable.default                     UNKNOWN",
+			},
+		},
+		{
+			description: "json",
+			args:        strings.Split("experimental cert-report -o json", " "),
+			k8sConfigs:  certReportCmdObjects(),
+			expectedStrings: []string{
+				`"proxy": "details-v1-5b7f94f9bc-wp5tb.default",
+    "status": "EXPIRED",`,
+				`"identity": "spiffe://cluster.local/ns/default/sa/bookinfo-details"`,
+			},
+		},
+		{
+			description: "proxy not answering",
+			args:        strings.Split("experimental cert-report --timeout 10ms --concurrency 1", " "),
+			k8sConfigs:  append(certReportCmdObjects(), hung),
+			expectedStrings: []string{
+				"details-v1-5b7f94f9bc-wp5tb.default     EXPIRED",
+				"hung.default                            UNKNOWN",
+				"no answer from Envoy to GET certs within the timeout",
+			},
+		},
+		{
+			description:     "no proxies",
+			args:            strings.Split("experimental cert-report -n other", " "),
+			k8sConfigs:      certReportCmdObjects(),
+			expectedStrings: []string{"No proxies found."},
+		},
+		{
+			description:       "invalid output format",
+			args:              strings.Split("experimental cert-report -o yaml", " "),
+			k8sConfigs:        certReportCmdObjects(),
+			expectedException: true,
+		},
+		{
+			description:       "invalid concurrency",
+			args:              strings.Split("experimental cert-report --concurrency 0", " "),
+			k8sConfigs:        certReportCmdObjects(),
+			expectedException: true,
+		},
+		{
+			description:       "invalid published root",
+			args:              strings.Split("experimental cert-report", " "),
+			k8sConfigs:        append(certReportCmdObjects(), invalidRoot),
+			expectedException: true,
+		},
+		{
+			description:       "missing root file",
+			args:              strings.Split("experimental cert-report --root-cert testdata/nonexistent.pem", " "),
+			k8sConfigs:        certReportCmdObjects(),
+			expectedException: true,
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %d %s", i, c.description), func(t *testing.T) {
+			interfaceFactory = mockInterfaceFactoryGenerator(c.k8sConfigs)
+			clientExecFactory = func(kubeconfig, configContext string) (kubernetes.ExecClient, error) {
+				return execClient, nil
+			}
+			var out bytes.Buffer
+			rootCmd := GetRootCmd(c.args)
+			rootCmd.SetOutput(&out)
+
+			fErr := rootCmd.Execute()
+			output := out.String()
+			namespace = metav1.NamespaceAll
+
+			if c.expectedException {
+				if fErr == nil {
+					t.Fatalf("Wanted an exception, didn't get one, output was %q", output)
+				}
+				return
+			}
+			if fErr != nil {
+				t.Fatalf("Unwanted exception: %v", fErr)
+			}
+			for _, expected := range c.expectedStrings {
+				if !strings.Contains(output, expected) {
+					t.Errorf("Output didn't match for 'istioctl %s'\n got %v\nwant: %v", strings.Join(c.args, " "), output, expected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2019 Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"istio.io/pkg/log"
+
+	"istio.io/istio/istioctl/pkg/langserver"
+	"istio.io/istio/istioctl/pkg/util/handlers"
+)
+
+func languageServerCmd() *cobra.Command {
+	var workspace string
+	cmd := &cobra.Command{
+		Use:   "language-server",
+		Short: "Run a language server for the Istio configuration files",
+		Long: `Runs a Language Server Protocol server on the standard input and output, for editors to report the
+validation and analysis messages of the open Istio configuration files as they are edited, to show the
+documentation of the fields on hover, and to complete the hosts, gateways and subsets referenced by
+VirtualServices and DestinationRules.
+
+The resources of the YAML files of the workspace are analyzed with the open files, once they stop changing.
+The workspace defaults to the root directory of the editor. Its files are read at startup, and read again when
+the editor saves them or notifies their changes.
+`,
+		Example: `# Configure the editor to start the server with
+istioctl experimental language-server --workspace ~/istio-config`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// The standard output carries the protocol messages.
+			loggingOptions.OutputPaths = []string{"stderr"}
+			if err := log.Configure(loggingOptions); err != nil {
+				return err
+			}
+			server := langserver.NewServer(langserver.Options{
+				Workspace:      workspace,
+				Namespace:      handlers.HandleNamespace(namespace, defaultNamespace),
+				IstioNamespace: istioNamespace,
+			})
+			return server.Serve(os.Stdin, os.Stdout)
+		},
+	}
+	cmd.PersistentFlags().StringVar(&workspace, "workspace", "",
+		"Directory of the Istio configuration files. Defaults to the root directory of the editor.")
+	return cmd
+}
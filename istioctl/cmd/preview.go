@@ -0,0 +1,220 @@
+// Copyright 2019 Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+
+	"istio.io/istio/istioctl/pkg/preview"
+	"istio.io/istio/istioctl/pkg/util/handlers"
+	"istio.io/istio/pilot/pkg/config/kube/crd"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/mesh"
+)
+
+func previewCmd() *cobra.Command {
+	var (
+		proposedFiles []string
+		snapshotFile  string
+		meshCfgFile   string
+		meshCfgMap    string
+		outputFormat  string
+		domainSuffix  string
+	)
+	cmd := &cobra.Command{
+		Use:   "preview <pod-name[.namespace]>...",
+		Short: "Preview the changes of proxy configuration caused by proposed Istio configuration",
+		Long: `Generates the listeners, routes and clusters of the proxies of the pods, as Pilot would, from the current
+state of the cluster and from the same state with the proposed Istio configuration applied, and prints the
+differences between them. The proposed resources replace the resources of the same kind, namespace and name.
+
+The state is read from the cluster, or from a snapshot saved with 'istioctl experimental preview snapshot'.
+Nothing is changed in the cluster.
+`,
+		Example: `# Preview how a VirtualService changes the configuration of a proxy
+istioctl experimental preview productpage-v1-7d6cfb7dfd-5mc96.default -f reviews-v2.yaml
+
+# Preview the change against a saved snapshot of the cluster, in JSON
+istioctl experimental preview snapshot > cluster.yaml
+istioctl experimental preview productpage-v1-7d6cfb7dfd-5mc96.default -f reviews-v2.yaml --snapshot cluster.yaml -o json`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				cmd.Println(cmd.UsageString())
+				return fmt.Errorf("preview requires at least one pod name")
+			}
+			if len(proposedFiles) == 0 {
+				cmd.Println(cmd.UsageString())
+				return fmt.Errorf("preview requires the proposed configuration (-f)")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputFormat != summaryOutput && outputFormat != jsonOutput {
+				return fmt.Errorf("output format %q not supported", outputFormat)
+			}
+			proposed, err := readProposedConfigs(proposedFiles)
+			if err != nil {
+				return err
+			}
+			snapshot, err := readSnapshot(snapshotFile, meshCfgMap)
+			if err != nil {
+				return err
+			}
+			meshConfig, err := previewMeshConfig(cmd.ErrOrStderr(), meshCfgFile, snapshot)
+			if err != nil {
+				return err
+			}
+
+			current, err := preview.NewGenerator(snapshot, meshConfig, domainSuffix)
+			if err != nil {
+				return err
+			}
+			defer current.Close()
+			next, err := preview.NewGenerator(snapshot.WithConfigs(proposed), meshConfig, domainSuffix)
+			if err != nil {
+				return err
+			}
+			defer next.Close()
+
+			var diffs []*preview.ProxyDiff
+			for _, arg := range args {
+				podName, ns := handlers.InferPodInfo(arg, handlers.HandleNamespace(namespace, defaultNamespace))
+				before, err := current.Generate(podName, ns)
+				if err != nil {
+					return err
+				}
+				after, err := next.Generate(podName, ns)
+				if err != nil {
+					return err
+				}
+				diff, err := preview.Diff(fmt.Sprintf("%s.%s", podName, ns), before, after)
+				if err != nil {
+					return err
+				}
+				diffs = append(diffs, diff)
+			}
+
+			if outputFormat == jsonOutput {
+				return preview.PrintJSON(cmd.OutOrStdout(), diffs)
+			}
+			return preview.PrintText(cmd.OutOrStdout(), diffs)
+		},
+	}
+
+	cmd.PersistentFlags().StringSliceVarP(&proposedFiles, "filename", "f", nil,
+		"Files of the proposed Istio configuration.")
+	cmd.PersistentFlags().StringVar(&snapshotFile, "snapshot", "",
+		"File of a snapshot of the cluster to generate the configuration from, instead of the cluster.")
+	cmd.PersistentFlags().StringVar(&meshCfgFile, "meshConfigFile", "",
+		"Mesh configuration filename. Defaults to the mesh configuration of the cluster, or of the snapshot "+
+			"with --snapshot.")
+	cmd.PersistentFlags().StringVar(&meshCfgMap, "meshConfigMapName", defaultMeshConfigMapName,
+		fmt.Sprintf("ConfigMap name for the mesh configuration, key should be %q", preview.MeshConfigKey))
+	cmd.PersistentFlags().StringVar(&domainSuffix, "domain", "cluster.local", "DNS domain suffix of the cluster.")
+	cmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", summaryOutput, "Output format: one of json|short")
+	cmd.AddCommand(previewSnapshotCmd(&meshCfgMap))
+	return cmd
+}
+
+func previewSnapshotCmd(meshCfgMap *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "snapshot",
+		Short: "Save the state of the cluster used to preview configuration changes",
+		Long: `Prints the namespaces, nodes, pods, services, endpoints, Istio configuration and mesh configuration of the
+cluster as YAML documents, which 'istioctl experimental preview --snapshot' reads.
+`,
+		Example: `istioctl experimental preview snapshot > cluster.yaml`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := interfaceFactory(kubeconfig)
+			if err != nil {
+				return err
+			}
+			configClient, err := clientFactory()
+			if err != nil {
+				return err
+			}
+			snapshot, err := preview.SnapshotFromCluster(client, configClient, istioNamespace, *meshCfgMap)
+			if err != nil {
+				return err
+			}
+			return snapshot.Write(cmd.OutOrStdout())
+		},
+	}
+}
+
+// readProposedConfigs reads the Istio configuration of the files, in the default namespace unless set.
+func readProposedConfigs(files []string) ([]model.Config, error) {
+	var out []model.Config
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		configs, _, err := crd.ParseInputs(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid configuration in %s: %v", f, err)
+		}
+		for i := range configs {
+			if configs[i].Namespace, err = handlers.HandleNamespaces(configs[i].Namespace, namespace, defaultNamespace); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, configs...)
+	}
+	return out, nil
+}
+
+// readSnapshot reads the snapshot of the file, or of the cluster if unset.
+func readSnapshot(file, meshCfgMap string) (*preview.Snapshot, error) {
+	if file != "" {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		return preview.ParseSnapshot(data)
+	}
+	client, err := interfaceFactory(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	configClient, err := clientFactory()
+	if err != nil {
+		return nil, err
+	}
+	return preview.SnapshotFromCluster(client, configClient, istioNamespace, meshCfgMap)
+}
+
+// previewMeshConfig reads the mesh configuration of the file, or of the snapshot if unset. The
+// snapshots saved without the mesh configuration fall back to the default mesh configuration.
+func previewMeshConfig(w io.Writer, meshCfgFile string, snapshot *preview.Snapshot) (*meshconfig.MeshConfig, error) {
+	if meshCfgFile != "" {
+		return mesh.ReadMeshConfig(meshCfgFile)
+	}
+	m, err := snapshot.Mesh()
+	if err != nil || m != nil {
+		return m, err
+	}
+	fmt.Fprintln(w, "Warning: the snapshot has no mesh configuration, using the default mesh configuration. "+
+		"Use --meshConfigFile or save the snapshot again.")
+	defaultConfig := mesh.DefaultMeshConfig()
+	return &defaultConfig, nil
+}
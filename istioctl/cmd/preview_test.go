@@ -0,0 +1,105 @@
+// Copyright 2019 Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPreview(t *testing.T) {
+	const (
+		pod      = "productpage-v1-7d6cfb7dfd-5mc96.default"
+		snapshot = "--snapshot ../pkg/preview/testdata/snapshot.yaml"
+		proposed = "-f ../pkg/preview/testdata/reviews-v2.yaml"
+	)
+	cases := []struct {
+		description       string
+		args              []string
+		expectedStrings   []string
+		expectedException bool
+	}{
+		{
+			description: "route change",
+			args:        strings.Split(fmt.Sprintf("experimental preview %s %s %s", pod, proposed, snapshot), " "),
+			expectedStrings: []string{
+				pod + ":",
+				"~ Route 9080",
+				`"outbound|9080|v1|reviews.default.svc.cluster.local" -> "outbound|9080|v2|reviews.default.svc.cluster.local"`,
+			},
+		},
+		{
+			description: "json",
+			args:        strings.Split(fmt.Sprintf("experimental preview %s %s %s -o json", pod, proposed, snapshot), " "),
+			expectedStrings: []string{
+				`"proxy": "productpage-v1-7d6cfb7dfd-5mc96.default"`,
+				`"type": "Route",
+        "name": "9080",
+        "change": "Modified"`,
+			},
+		},
+		{
+			description:       "no proposed configuration",
+			args:              strings.Split(fmt.Sprintf("experimental preview %s %s", pod, snapshot), " "),
+			expectedException: true,
+		},
+		{
+			description:       "no pod",
+			args:              strings.Split(fmt.Sprintf("experimental preview %s %s", proposed, snapshot), " "),
+			expectedException: true,
+		},
+		{
+			description:       "unknown pod",
+			args:              strings.Split(fmt.Sprintf("experimental preview unknown.default %s %s", proposed, snapshot), " "),
+			expectedException: true,
+		},
+		{
+			description:       "invalid output format",
+			args:              strings.Split(fmt.Sprintf("experimental preview %s %s %s -o yaml", pod, proposed, snapshot), " "),
+			expectedException: true,
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %d %s", i, c.description), func(t *testing.T) {
+			var out bytes.Buffer
+			rootCmd := GetRootCmd(c.args)
+			rootCmd.SetOutput(&out)
+
+			fErr := rootCmd.Execute()
+			output := out.String()
+			namespace = metav1.NamespaceAll
+
+			if c.expectedException {
+				if fErr == nil {
+					t.Fatalf("Wanted an exception, didn't get one, output was %q", output)
+				}
+				return
+			}
+			if fErr != nil {
+				t.Fatalf("Unwanted exception: %v", fErr)
+			}
+			for _, expected := range c.expectedStrings {
+				if !strings.Contains(output, expected) {
+					t.Errorf("Output didn't match for 'istioctl %s'\n got %v\nwant: %v", strings.Join(c.args, " "), output, expected)
+				}
+			}
+		})
+	}
+}
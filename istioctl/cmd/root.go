@@ -150,6 +150,11 @@ debug and diagnose their Istio mesh.
 	experimentalCmd.AddCommand(removeFromMeshCmd())
 	experimentalCmd.AddCommand(softGraduatedCmd(Analyze()))
 	experimentalCmd.AddCommand(waitCmd())
+	experimentalCmd.AddCommand(uninjectedPodsCmd())
+	experimentalCmd.AddCommand(certReportCmd())
+	experimentalCmd.AddCommand(previewCmd())
+	experimentalCmd.AddCommand(languageServerCmd())
+	experimentalCmd.AddCommand(usageCmd())
 
 	postInstallCmd.AddCommand(Webhook())
 	experimentalCmd.AddCommand(postInstallCmd)
@@ -0,0 +1,138 @@
+// Copyright 2019 Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"istio.io/istio/pkg/kube/inject"
+)
+
+func uninjectedPodsCmd() *cobra.Command {
+	var (
+		webhookConfigName string
+		webhookName       string
+		restart           bool
+	)
+	cmd := &cobra.Command{
+		Use:   "uninjected-pods",
+		Short: "List the pods without sidecar in namespaces with injection enabled",
+		Long: `Lists the pods that the sidecar injection webhook applies to, but that have no sidecar, such as the pods
+created while the injector was unavailable. With --restart, the deployments, statefulsets and daemonsets of
+these pods are restarted so that their pods are recreated with a sidecar, provided the injector is healthy.
+`,
+		Example: `# List the uninjected pods of all namespaces
+istioctl experimental uninjected-pods
+
+# Restart the workloads of the uninjected pods of the default namespace
+istioctl experimental uninjected-pods -n default --restart`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := interfaceFactory(kubeconfig)
+			if err != nil {
+				return err
+			}
+			config, err := injectConfigFromConfigMap(client)
+			if err != nil {
+				return err
+			}
+			selector, err := inject.WebhookInjectionSelector(client, webhookConfigName, webhookName)
+			if err != nil {
+				return err
+			}
+			pods, err := inject.FindUninjectedPods(client, config, selector, namespace)
+			if err != nil {
+				return err
+			}
+
+			writer := cmd.OutOrStdout()
+			if len(pods) == 0 {
+				_, _ = fmt.Fprintln(writer, "No uninjected pods found.")
+				return nil
+			}
+			printUninjectedPods(writer, pods)
+			if !restart {
+				return nil
+			}
+
+			if err := inject.InjectorHealthy(client, webhookConfigName, webhookName); err != nil {
+				return fmt.Errorf("not restarting, the sidecar injector isn't healthy: %v", err)
+			}
+			restarted := map[string]bool{}
+			for _, pod := range pods {
+				key := pod.Namespace + "/" + pod.Owner()
+				if pod.OwnerKind != "" && restarted[key] {
+					continue
+				}
+				restarted[key] = true
+				owner, err := inject.RestartOwner(client, pod)
+				if err != nil {
+					_, _ = fmt.Fprintf(writer, "Skipping pod %s.%s: %v\n", pod.Name, pod.Namespace, err)
+					continue
+				}
+				_, _ = fmt.Fprintf(writer, "Restarted %s.%s\n", owner, pod.Namespace)
+			}
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&webhookConfigName, "webhookConfigName", "istio-sidecar-injector",
+		"Name of the mutatingwebhookconfiguration of the sidecar injector.")
+	cmd.PersistentFlags().StringVar(&webhookName, "webhookName", "sidecar-injector.istio.io",
+		"Name of the webhook entry in the webhook config.")
+	cmd.PersistentFlags().StringVar(&injectConfigMapName, "injectConfigMapName", defaultInjectConfigMapName,
+		fmt.Sprintf("ConfigMap name for Istio sidecar injection, key should be %q.", injectConfigMapKey))
+	cmd.PersistentFlags().BoolVar(&restart, "restart", false,
+		"Restart the workloads of the uninjected pods if the sidecar injector is healthy.")
+	return cmd
+}
+
+// injectConfigFromConfigMap returns the sidecar injection configuration of the injector.
+func injectConfigFromConfigMap(client kubernetes.Interface) (*inject.Config, error) {
+	cm, err := client.CoreV1().ConfigMaps(istioNamespace).Get(injectConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not find valid configmap %q from namespace %q: %v",
+			injectConfigMapName, istioNamespace, err)
+	}
+	data, exists := cm.Data[injectConfigMapKey]
+	if !exists {
+		return nil, fmt.Errorf("missing configuration map key %q in %q", injectConfigMapKey, injectConfigMapName)
+	}
+	var config inject.Config
+	if err := yaml.Unmarshal([]byte(data), &config); err != nil {
+		return nil, fmt.Errorf("unable to convert data from configmap %q: %v", injectConfigMapName, err)
+	}
+	return &config, nil
+}
+
+func printUninjectedPods(writer io.Writer, pods []inject.UninjectedPod) {
+	w := tabwriter.NewWriter(writer, 0, 8, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAMESPACE\tPOD\tOWNER")
+	for _, pod := range pods {
+		owner := pod.Owner()
+		if owner == "" {
+			owner = "<none>"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", pod.Namespace, pod.Name, owner)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,157 @@
+// Copyright 2019 Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"istio.io/api/annotation"
+)
+
+func uninjectedCmdObjects(readyInjector bool) []runtime.Object {
+	controller := true
+	owner := func(kind, name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+	}
+	pod := func(namespace, name string, owners []metav1.OwnerReference, annotations map[string]string) *coreV1.Pod {
+		return &coreV1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       namespace,
+				Name:            name,
+				OwnerReferences: owners,
+				Annotations:     annotations,
+			},
+			Status: coreV1.PodStatus{Phase: coreV1.PodRunning},
+		}
+	}
+	endpoints := &coreV1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: "istio-sidecar-injector"},
+	}
+	if readyInjector {
+		endpoints.Subsets = []coreV1.EndpointSubset{{Addresses: []coreV1.EndpointAddress{{IP: "10.0.0.1"}}}}
+	}
+
+	return []runtime.Object{
+		&coreV1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: defaultInjectConfigMapName},
+			Data:       map[string]string{injectConfigMapKey: "policy: enabled\n"},
+		},
+		&v1beta1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "istio-sidecar-injector"},
+			Webhooks: []v1beta1.MutatingWebhook{{
+				Name: "sidecar-injector.istio.io",
+				ClientConfig: v1beta1.WebhookClientConfig{
+					Service:  &v1beta1.ServiceReference{Namespace: "istio-system", Name: "istio-sidecar-injector"},
+					CABundle: []byte("ca"),
+				},
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"istio-injection": "enabled"}},
+			}},
+		},
+		endpoints,
+		&coreV1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", Labels: map[string]string{"istio-injection": "enabled"}}},
+		&coreV1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default", Name: "details-v1-1234", OwnerReferences: owner("Deployment", "details-v1"),
+		}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "details-v1"}},
+		pod("default", "details-v1-1234-a", owner("ReplicaSet", "details-v1-1234"), nil),
+		pod("default", "details-v1-1234-b", owner("ReplicaSet", "details-v1-1234"), nil),
+		pod("default", "ratings-v1-5678-a", owner("ReplicaSet", "ratings-v1-5678"),
+			map[string]string{annotation.SidecarStatus.Name: `{"containers":["istio-proxy"]}`}),
+		pod("default", "debug", nil, nil),
+		pod("other", "reviews", nil, nil),
+	}
+}
+
+func TestUninjectedPods(t *testing.T) {
+	list := `NAMESPACE  POD                OWNER
+default    debug              <none>
+default    details-v1-1234-a  ReplicaSet/details-v1-1234
+default    details-v1-1234-b  ReplicaSet/details-v1-1234
+`
+	cases := []testcase{
+		{
+			description:    "all namespaces",
+			args:           strings.Split("experimental uninjected-pods", " "),
+			k8sConfigs:     uninjectedCmdObjects(false),
+			expectedOutput: list,
+		},
+		{
+			description:    "namespace without injection",
+			args:           strings.Split("experimental uninjected-pods -n other", " "),
+			k8sConfigs:     uninjectedCmdObjects(false),
+			expectedOutput: "No uninjected pods found.\n",
+		},
+		{
+			description:    "restart",
+			args:           strings.Split("experimental uninjected-pods -n default --restart", " "),
+			k8sConfigs:     uninjectedCmdObjects(true),
+			expectedOutput: list + "Skipping pod debug.default: pod default/debug has no owner to restart\nRestarted Deployment/details-v1.default\n",
+		},
+		{
+			description:       "restart with unhealthy injector",
+			args:              strings.Split("experimental uninjected-pods --restart", " "),
+			k8sConfigs:        uninjectedCmdObjects(false),
+			expectedException: true,
+		},
+		{
+			description:       "no injection configmap",
+			args:              strings.Split("experimental uninjected-pods", " "),
+			k8sConfigs:        uninjectedCmdObjects(false)[1:],
+			expectedException: true,
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %d %s", i, c.description), func(t *testing.T) {
+			verifyUninjectedPodsOutput(t, c)
+		})
+	}
+}
+
+func verifyUninjectedPodsOutput(t *testing.T, c testcase) {
+	t.Helper()
+
+	interfaceFactory = mockInterfaceFactoryGenerator(c.k8sConfigs)
+	var out bytes.Buffer
+	rootCmd := GetRootCmd(c.args)
+	rootCmd.SetOutput(&out)
+
+	fErr := rootCmd.Execute()
+	output := out.String()
+	namespace = metav1.NamespaceAll
+
+	if c.expectedException {
+		if fErr == nil {
+			t.Fatalf("Wanted an exception, didn't get one, output was %q", output)
+		}
+		return
+	}
+	if fErr != nil {
+		t.Fatalf("Unwanted exception: %v", fErr)
+	}
+	if c.expectedOutput != output {
+		t.Fatalf("Unexpected output for 'istioctl %s'\n got: %q\nwant: %q", strings.Join(c.args, " "), output, c.expectedOutput)
+	}
+}
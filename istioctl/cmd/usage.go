@@ -0,0 +1,148 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/pkg/envoy/usage"
+)
+
+const (
+	usageByIdentities  = "identities"
+	usageBySource      = "source"
+	usageByDestination = "destination"
+)
+
+func usageCmd() *cobra.Command {
+	var (
+		outputFormat string
+		groupBy      string
+	)
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Summarise the traffic between the identities of the mesh",
+		Long: `Retrieves the usage stats of the proxies, and summarises the requests, connections and bytes received by
+the workloads, by principals of the source and of the destination, for chargeback without Mixer.
+
+The traffic is accounted by the proxy of the destination, when Pilot runs with the usage plugin. The counters are
+cumulative since the start of each proxy.
+`,
+		Example: `# Summarise the traffic between the identities of the mesh
+istioctl experimental usage
+
+# Summarise the traffic received by the identities of the default namespace, in JSON
+istioctl experimental usage -n default --by destination -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if outputFormat != summaryOutput && outputFormat != jsonOutput {
+				return fmt.Errorf("output format %q not supported", outputFormat)
+			}
+			if groupBy != usageByIdentities && groupBy != usageBySource && groupBy != usageByDestination {
+				return fmt.Errorf("grouping %q not supported", groupBy)
+			}
+			client, err := interfaceFactory(kubeconfig)
+			if err != nil {
+				return err
+			}
+			pods, err := client.CoreV1().Pods(namespace).List(metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+			execClient, err := clientExecFactory(kubeconfig, configContext)
+			if err != nil {
+				return err
+			}
+
+			report := usage.Report{}
+			for i := range pods.Items {
+				pod := &pods.Items[i]
+				if pod.Status.Phase != v1.PodRunning || !hasProxyContainer(pod) {
+					continue
+				}
+				stats, err := execClient.EnvoyDo(pod.Name, pod.Namespace, "GET", "stats/prometheus?usedonly", nil)
+				if err == nil {
+					var r usage.Report
+					if r, err = usage.Parse(bytes.NewReader(stats)); err == nil {
+						report.Merge(r)
+						continue
+					}
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStderr(), "Warning: unable to retrieve the usage of proxy %s.%s: %v\n",
+					pod.Name, pod.Namespace, err)
+			}
+
+			entries := groupUsage(report, groupBy).Entries()
+			if outputFormat == jsonOutput {
+				return printUsageJSON(cmd.OutOrStdout(), entries)
+			}
+			return printUsageTabular(cmd.OutOrStdout(), entries)
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", summaryOutput, "Output format: one of json|short")
+	cmd.PersistentFlags().StringVar(&groupBy, "by", usageByIdentities,
+		"Group the usage by identities of the source and destination, or only by source or by destination: "+
+			"one of identities|source|destination")
+	return cmd
+}
+
+// groupUsage returns the usage grouped by the identities, or only by source or by destination.
+func groupUsage(report usage.Report, groupBy string) usage.Report {
+	if groupBy == usageByIdentities {
+		return report
+	}
+	out := usage.Report{}
+	for ids, c := range report {
+		if groupBy == usageBySource {
+			ids.Destination = ""
+		} else {
+			ids.Source = ""
+		}
+		out.Merge(usage.Report{ids: c})
+	}
+	return out
+}
+
+func printUsageTabular(w io.Writer, entries []usage.Entry) error {
+	if len(entries) == 0 {
+		_, _ = fmt.Fprintln(w, "No usage found.")
+		return nil
+	}
+	tw := new(tabwriter.Writer).Init(w, 0, 5, 5, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SOURCE\tDESTINATION\tREQUESTS\tCONNECTIONS\tRECEIVED BYTES\tSENT BYTES")
+	for _, e := range entries {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\n",
+			e.Source, e.Destination, e.Requests, e.Connections, e.ReceivedBytes, e.SentBytes)
+	}
+	return tw.Flush()
+}
+
+func printUsageJSON(w io.Writer, entries []usage.Entry) error {
+	out, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
@@ -0,0 +1,135 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	coreV1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	productpagePrincipal = "spiffe://cluster.local/ns/default/sa/bookinfo-productpage"
+	reviewsPrincipal     = "spiffe://cluster.local/ns/default/sa/bookinfo-reviews"
+	ratingsPrincipal     = "spiffe://cluster.local/ns/default/sa/bookinfo-ratings"
+)
+
+func usageStats(source, destination string, requests, receivedBytes int) []byte {
+	labels := fmt.Sprintf(`reporter="destination",source_principal=%q,destination_principal=%q`, source, destination)
+	return []byte(fmt.Sprintf(`# TYPE istio_usage_requests_total counter
+istio_usage_requests_total{%s} %d
+# TYPE istio_usage_tcp_received_bytes_total counter
+istio_usage_tcp_received_bytes_total{%s} %d
+`, labels, requests, labels, receivedBytes))
+}
+
+func usageCmdObjects() []runtime.Object {
+	pod := func(name string) *coreV1.Pod {
+		return &coreV1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       coreV1.PodSpec{Containers: []coreV1.Container{{Name: "app"}, {Name: proxyContainerName}}},
+			Status:     coreV1.PodStatus{Phase: coreV1.PodRunning},
+		}
+	}
+	return []runtime.Object{pod("reviews-v1"), pod("reviews-v2"), pod("ratings-v1"), pod("unreachable")}
+}
+
+func TestUsage(t *testing.T) {
+	execConfig := map[string][]byte{
+		"reviews-v1": usageStats(productpagePrincipal, reviewsPrincipal, 10, 1000),
+		"reviews-v2": usageStats(productpagePrincipal, reviewsPrincipal, 5, 500),
+		"ratings-v1": usageStats(reviewsPrincipal, ratingsPrincipal, 20, 200),
+	}
+	cases := []struct {
+		description       string
+		args              []string
+		k8sConfigs        []runtime.Object
+		expectedStrings   []string
+		expectedException bool
+	}{
+		{
+			description: "by identities",
+			args:        strings.Split("experimental usage", " "),
+			k8sConfigs:  usageCmdObjects(),
+			expectedStrings: []string{
+				"Warning: unable to retrieve the usage of proxy unreachable.default",
+				productpagePrincipal + "     " + reviewsPrincipal + "     15           0               1500",
+				reviewsPrincipal + "         " + ratingsPrincipal + "     20           0               200",
+			},
+		},
+		{
+			description: "by destination in json",
+			args:        strings.Split("experimental usage --by destination -o json", " "),
+			k8sConfigs:  usageCmdObjects(),
+			expectedStrings: []string{
+				`"source": "",
+    "destination": "` + reviewsPrincipal + `",
+    "requests": 15,`,
+			},
+		},
+		{
+			description:     "no proxies",
+			args:            strings.Split("experimental usage -n other", " "),
+			k8sConfigs:      usageCmdObjects(),
+			expectedStrings: []string{"No usage found."},
+		},
+		{
+			description:       "invalid output format",
+			args:              strings.Split("experimental usage -o yaml", " "),
+			k8sConfigs:        usageCmdObjects(),
+			expectedException: true,
+		},
+		{
+			description:       "invalid grouping",
+			args:              strings.Split("experimental usage --by namespace", " "),
+			k8sConfigs:        usageCmdObjects(),
+			expectedException: true,
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %d %s", i, c.description), func(t *testing.T) {
+			interfaceFactory = mockInterfaceFactoryGenerator(c.k8sConfigs)
+			clientExecFactory = mockClientExecFactoryGenerator(execConfig)
+			var out bytes.Buffer
+			rootCmd := GetRootCmd(c.args)
+			rootCmd.SetOutput(&out)
+
+			fErr := rootCmd.Execute()
+			output := out.String()
+			namespace = metav1.NamespaceAll
+
+			if c.expectedException {
+				if fErr == nil {
+					t.Fatalf("Wanted an exception, didn't get one, output was %q", output)
+				}
+				return
+			}
+			if fErr != nil {
+				t.Fatalf("Unwanted exception: %v", fErr)
+			}
+			for _, expected := range c.expectedStrings {
+				if !strings.Contains(output, expected) {
+					t.Errorf("Output didn't match for 'istioctl %s'\n got %v\nwant: %v", strings.Join(c.args, " "), output, expected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certreport
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	adminapi "github.com/envoyproxy/go-control-plane/envoy/admin/v2alpha"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes"
+
+	"istio.io/istio/istioctl/pkg/util/configdump"
+	"istio.io/istio/pkg/spiffe"
+)
+
+// Status is the state of the certificate of a proxy. The statuses are ordered by urgency.
+type Status int
+
+const (
+	// Expired certificates are rejected by the peers of the proxy.
+	Expired Status = iota
+	// RootMismatch certificates don't chain to the current root of the mesh, or the proxy doesn't
+	// trust the current root.
+	RootMismatch
+	// Expiring certificates expire within the threshold of the report.
+	Expiring
+	// RotationFailed certificates should have been rotated already, or a rotated certificate
+	// couldn't be applied by the proxy.
+	RotationFailed
+	// Unknown is the status of the proxies whose certificate couldn't be retrieved.
+	Unknown
+	// OK certificates are valid and not expiring soon.
+	OK
+)
+
+var statusNames = map[Status]string{
+	Expired:        "EXPIRED",
+	RootMismatch:   "ROOT_MISMATCH",
+	Expiring:       "EXPIRING",
+	RotationFailed: "ROTATION_FAILED",
+	Unknown:        "UNKNOWN",
+	OK:             "OK",
+}
+
+// String returns the name of the status.
+func (s Status) String() string {
+	return statusNames[s]
+}
+
+// MarshalJSON marshals the status as its name.
+func (s Status) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+const (
+	// workloadSecretName is the SDS resource of the workload certificate chain.
+	workloadSecretName = "default"
+
+	// sdsPath is the path Envoy reports for the certificates delivered inline, through SDS.
+	sdsPath = "<inline>"
+)
+
+// Options configures how the certificates are checked.
+type Options struct {
+	// Roots are the current root certificates of the mesh. The chains are not verified if nil.
+	Roots []*x509.Certificate
+	// ExpiryThreshold is the remaining lifetime below which a certificate is reported as expiring.
+	ExpiryThreshold time.Duration
+	// RotationRatio is the ratio of the lifetime of a certificate below which its remaining
+	// lifetime shows that its rotation failed.
+	RotationRatio float64
+	// Now is the time at which the certificates are checked.
+	Now time.Time
+}
+
+// ProxyReport is the state of the workload certificate of a proxy.
+type ProxyReport struct {
+	Proxy        string        `json:"proxy"`
+	Status       Status        `json:"status"`
+	Identity     string        `json:"identity,omitempty"`
+	SerialNumber string        `json:"serial_number,omitempty"`
+	NotBefore    *time.Time    `json:"not_before,omitempty"`
+	NotAfter     *time.Time    `json:"not_after,omitempty"`
+	TimeToExpiry time.Duration `json:"-"`
+	Message      string        `json:"message,omitempty"`
+}
+
+// MarshalJSON marshals the report, with the time to expiry in seconds.
+func (r ProxyReport) MarshalJSON() ([]byte, error) {
+	type report ProxyReport
+	return json.Marshal(struct {
+		report
+		TimeToExpiry int64 `json:"time_to_expiry_seconds"`
+	}{report(r), int64(r.TimeToExpiry / time.Second)})
+}
+
+// UnknownReport returns the report of a proxy whose configuration couldn't be retrieved.
+func UnknownReport(proxy string, err error) ProxyReport {
+	return ProxyReport{Proxy: proxy, Status: Unknown, Message: err.Error()}
+}
+
+// Analyze checks the workload certificate of the proxy from the certificates reported by the /certs
+// endpoint of its Envoy. The config dump is only retrieved when the certificates are delivered
+// through SDS, to verify their chain and to find the rotated certificates the proxy couldn't apply.
+func Analyze(proxy string, certs []byte, configDump func() ([]byte, error), options Options) ProxyReport {
+	certificates := &adminapi.Certificates{}
+	if err := (&jsonpb.Unmarshaler{AllowUnknownFields: true}).Unmarshal(bytes.NewReader(certs), certificates); err != nil {
+		return UnknownReport(proxy, fmt.Errorf("invalid certificates: %v", err))
+	}
+
+	// The workload certificate is the leaf of the chains with a SPIFFE identity. The TLS contexts
+	// all use the same certificate, unless some of them weren't updated with the rotated one.
+	var leaf *adminapi.CertificateDetails
+	var identity string
+	serials := map[string]bool{}
+	trusted := map[string]bool{}
+	for _, c := range certificates.Certificates {
+		for _, ca := range c.CaCert {
+			trusted[normalizeSerial(ca.SerialNumber)] = true
+		}
+		if len(c.CertChain) == 0 {
+			continue
+		}
+		id := spiffeIdentity(c.CertChain[0])
+		if id == "" {
+			continue
+		}
+		serials[normalizeSerial(c.CertChain[0].SerialNumber)] = true
+		if leaf == nil || expiration(c.CertChain[0]).Before(expiration(leaf)) {
+			leaf, identity = c.CertChain[0], id
+		}
+	}
+	if leaf == nil {
+		return UnknownReport(proxy, fmt.Errorf("no workload certificate"))
+	}
+
+	report := ProxyReport{
+		Proxy:        proxy,
+		Status:       OK,
+		Identity:     identity,
+		SerialNumber: normalizeSerial(leaf.SerialNumber),
+	}
+	notBefore, err := ptypes.Timestamp(leaf.ValidFrom)
+	if err != nil {
+		return UnknownReport(proxy, fmt.Errorf("invalid workload certificate: %v", err))
+	}
+	notAfter, err := ptypes.Timestamp(leaf.ExpirationTime)
+	if err != nil {
+		return UnknownReport(proxy, fmt.Errorf("invalid workload certificate: %v", err))
+	}
+	report.NotBefore, report.NotAfter = &notBefore, &notAfter
+	report.TimeToExpiry = notAfter.Sub(options.Now)
+
+	// Envoy only reports the details of the leaf certificate, the chain is verified from the SDS
+	// secrets of the config dump.
+	var chain []*x509.Certificate
+	var warming bool
+	var dumpErr error
+	if leaf.Path == sdsPath && configDump != nil {
+		chain, warming, dumpErr = sdsSecrets(configDump)
+	}
+
+	lifetime := notAfter.Sub(notBefore)
+	switch {
+	case report.TimeToExpiry <= 0:
+		report.Status = Expired
+		report.Message = fmt.Sprintf("expired %v ago", -report.TimeToExpiry.Round(time.Second))
+	case options.Roots != nil && chain != nil && !verifyChain(chain, options.Roots, options.Now):
+		report.Status = RootMismatch
+		report.Message = "certificate chain not signed by the current root"
+	case options.Roots != nil && len(trusted) > 0 && !trustsRoots(trusted, options.Roots):
+		report.Status = RootMismatch
+		report.Message = "proxy doesn't trust the current root"
+	case report.TimeToExpiry < options.ExpiryThreshold:
+		report.Status = Expiring
+		report.Message = fmt.Sprintf("expires in %v", report.TimeToExpiry.Round(time.Second))
+	case warming:
+		report.Status = RotationFailed
+		report.Message = "rotated certificate not applied by the proxy"
+	case len(serials) > 1:
+		report.Status = RotationFailed
+		report.Message = "TLS contexts still use a previous certificate"
+	case float64(report.TimeToExpiry) < options.RotationRatio*float64(lifetime):
+		report.Status = RotationFailed
+		report.Message = fmt.Sprintf("certificate not rotated, %.0f%% of its lifetime remaining",
+			100*float64(report.TimeToExpiry)/float64(lifetime))
+	case dumpErr != nil:
+		report.Message = fmt.Sprintf("certificate chain not verified: %v", dumpErr)
+	}
+	return report
+}
+
+// sdsSecrets returns the active workload certificate chain of the config dump, and whether a
+// rotated chain is warming.
+func sdsSecrets(configDump func() ([]byte, error)) ([]*x509.Certificate, bool, error) {
+	dump, err := configDump()
+	if err != nil {
+		return nil, false, err
+	}
+	wrapper := &configdump.Wrapper{}
+	if err := json.Unmarshal(dump, wrapper); err != nil {
+		return nil, false, fmt.Errorf("invalid config dump: %v", err)
+	}
+	secrets, err := wrapper.GetSecretConfigDump()
+	if err != nil {
+		return nil, false, fmt.Errorf("no secrets in config dump: %v", err)
+	}
+
+	var chainPEM []byte
+	for _, s := range secrets.DynamicActiveSecrets {
+		if s.Name == workloadSecretName {
+			chainPEM = s.GetSecret().GetTlsCertificate().GetCertificateChain().GetInlineBytes()
+		}
+	}
+	warming := false
+	for _, s := range secrets.DynamicWarmingSecrets {
+		if s.Name == workloadSecretName {
+			warming = true
+		}
+	}
+	if len(chainPEM) == 0 {
+		return nil, warming, fmt.Errorf("no active workload certificate in config dump")
+	}
+	chain, err := parseCertificates(chainPEM)
+	if err != nil {
+		return nil, warming, fmt.Errorf("invalid workload certificate: %v", err)
+	}
+	return chain, warming, nil
+}
+
+// spiffeIdentity returns the SPIFFE identity of the certificate, or an empty string.
+func spiffeIdentity(cert *adminapi.CertificateDetails) string {
+	for _, san := range cert.SubjectAltNames {
+		if uri := san.GetUri(); strings.HasPrefix(uri, spiffe.URIPrefix) {
+			return uri
+		}
+	}
+	return ""
+}
+
+func expiration(cert *adminapi.CertificateDetails) time.Time {
+	t, _ := ptypes.Timestamp(cert.ExpirationTime)
+	return t
+}
+
+// normalizeSerial returns the hexadecimal serial number reported by Envoy in lower case, without
+// leading zeros.
+func normalizeSerial(serial string) string {
+	n, ok := new(big.Int).SetString(serial, 16)
+	if !ok {
+		return strings.ToLower(serial)
+	}
+	return n.Text(16)
+}
+
+// Sort sorts the reports by urgency: by status, then by expiry.
+func Sort(reports []ProxyReport) {
+	sort.SliceStable(reports, func(i, j int) bool {
+		if reports[i].Status != reports[j].Status {
+			return reports[i].Status < reports[j].Status
+		}
+		if reports[i].TimeToExpiry != reports[j].TimeToExpiry {
+			return reports[i].TimeToExpiry < reports[j].TimeToExpiry
+		}
+		return reports[i].Proxy < reports[j].Proxy
+	})
+}
+
+// ParseRoots parses the PEM encoded root certificates.
+func ParseRoots(rootPEM []byte) ([]*x509.Certificate, error) {
+	return parseCertificates(rootPEM)
+}
+
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM encoded certificate found")
+	}
+	return certs, nil
+}
+
+// verifyChain returns true if the leaf certificate of the chain is signed by one of the roots,
+// through the intermediate certificates of the chain.
+func verifyChain(chain, roots []*x509.Certificate, now time.Time) bool {
+	options := x509.VerifyOptions{
+		Roots:         x509.NewCertPool(),
+		Intermediates: x509.NewCertPool(),
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	for _, root := range roots {
+		options.Roots.AddCert(root)
+	}
+	for _, cert := range chain[1:] {
+		options.Intermediates.AddCert(cert)
+	}
+	_, err := chain[0].Verify(options)
+	return err == nil
+}
+
+// trustsRoots returns true if the serial numbers of the root certificates of the proxy include
+// one of the roots.
+func trustsRoots(trusted map[string]bool, roots []*x509.Certificate) bool {
+	for _, root := range roots {
+		if trusted[root.SerialNumber.Text(16)] {
+			return true
+		}
+	}
+	return false
+}
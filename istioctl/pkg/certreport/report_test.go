@@ -0,0 +1,371 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certreport
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	adminapi "github.com/envoyproxy/go-control-plane/envoy/admin/v2alpha"
+	auth "github.com/envoyproxy/go-control-plane/envoy/api/v2/auth"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+const testIdentity = "spiffe://cluster.local/ns/default/sa/foo"
+
+type testCA struct {
+	certPEM []byte
+	keyPEM  []byte
+	cert    *x509.Certificate
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	certPEM, keyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		Org:          "Istio Test",
+		NotBefore:    time.Now().Add(-time.Hour),
+		TTL:          24 * time.Hour,
+		IsCA:         true,
+		IsSelfSigned: true,
+		RSAKeySize:   2048,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := util.ParsePemEncodedCertificate(certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{certPEM: certPEM, keyPEM: keyPEM, cert: cert}
+}
+
+// issue returns a workload certificate chain valid from notBefore for ttl.
+func (ca *testCA) issue(t *testing.T, notBefore time.Time, ttl time.Duration) []byte {
+	t.Helper()
+	key, err := util.ParsePemEncodedKey(ca.keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, _, err := util.GenCertKeyFromOptions(util.CertOptions{
+		Host:       testIdentity,
+		NotBefore:  notBefore,
+		TTL:        ttl,
+		SignerCert: ca.cert,
+		SignerPriv: key,
+		IsClient:   true,
+		IsServer:   true,
+		RSAKeySize: 2048,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certPEM
+}
+
+func tlsSecret(chain []byte) *auth.Secret {
+	return &auth.Secret{
+		Name: workloadSecretName,
+		Type: &auth.Secret_TlsCertificate{TlsCertificate: &auth.TlsCertificate{
+			CertificateChain: &core.DataSource{Specifier: &core.DataSource_InlineBytes{InlineBytes: chain}},
+		}},
+	}
+}
+
+// configDump returns the Envoy config dump of the active and warming secrets.
+func configDump(t *testing.T, active, warming []*auth.Secret) func() ([]byte, error) {
+	t.Helper()
+	secrets := &adminapi.SecretsConfigDump{}
+	for _, s := range active {
+		secrets.DynamicActiveSecrets = append(secrets.DynamicActiveSecrets,
+			&adminapi.SecretsConfigDump_DynamicSecret{Name: s.Name, Secret: s})
+	}
+	for _, s := range warming {
+		secrets.DynamicWarmingSecrets = append(secrets.DynamicWarmingSecrets,
+			&adminapi.SecretsConfigDump_DynamicSecret{Name: s.Name, Secret: s})
+	}
+	section, err := ptypes.MarshalAny(secrets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := (&jsonpb.Marshaler{}).Marshal(&out, &adminapi.ConfigDump{Configs: []*any.Any{section}}); err != nil {
+		t.Fatal(err)
+	}
+	return func() ([]byte, error) {
+		return out.Bytes(), nil
+	}
+}
+
+// noConfigDump fails the test if the config dump is retrieved.
+func noConfigDump(t *testing.T) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		t.Errorf("config dump retrieved")
+		return nil, fmt.Errorf("no config dump")
+	}
+}
+
+func certificateDetails(t *testing.T, path string, certPEM []byte) *adminapi.CertificateDetails {
+	t.Helper()
+	cert, err := util.ParsePemEncodedCertificate(certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	details := &adminapi.CertificateDetails{
+		Path:         path,
+		SerialNumber: strings.ToUpper(cert.SerialNumber.Text(16)),
+	}
+	for _, uri := range cert.URIs {
+		details.SubjectAltNames = append(details.SubjectAltNames,
+			&adminapi.SubjectAlternateName{Name: &adminapi.SubjectAlternateName_Uri{Uri: uri.String()}})
+	}
+	if details.ValidFrom, err = ptypes.TimestampProto(cert.NotBefore); err != nil {
+		t.Fatal(err)
+	}
+	if details.ExpirationTime, err = ptypes.TimestampProto(cert.NotAfter); err != nil {
+		t.Fatal(err)
+	}
+	return details
+}
+
+// proxyCerts returns the output of the /certs endpoint of a proxy with a TLS context per
+// workload certificate chain, loaded from path, each trusting the root.
+func proxyCerts(t *testing.T, path string, root []byte, chains ...[]byte) []byte {
+	t.Helper()
+	certs := &adminapi.Certificates{}
+	for _, chain := range chains {
+		c := &adminapi.Certificate{CertChain: []*adminapi.CertificateDetails{certificateDetails(t, path, chain)}}
+		if root != nil {
+			c.CaCert = []*adminapi.CertificateDetails{certificateDetails(t, path, root)}
+		}
+		certs.Certificates = append(certs.Certificates, c)
+	}
+	var out bytes.Buffer
+	if err := (&jsonpb.Marshaler{OrigName: true}).Marshal(&out, certs); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func TestAnalyze(t *testing.T) {
+	ca := newTestCA(t)
+	otherCA := newTestCA(t)
+	now := time.Now()
+	fresh := ca.issue(t, now.Add(-time.Hour), 24*time.Hour)
+	expired := ca.issue(t, now.Add(-2*time.Hour), time.Hour)
+	expiring := ca.issue(t, now.Add(-22*time.Hour), 24*time.Hour)
+	notRotated := ca.issue(t, now.Add(-80*time.Hour), 100*time.Hour)
+	const files = "/etc/certs/cert-chain.pem"
+
+	cases := []struct {
+		name       string
+		certs      []byte
+		configDump func() ([]byte, error)
+		roots      []*x509.Certificate
+		status     Status
+		message    string
+	}{
+		{
+			name:       "valid",
+			certs:      proxyCerts(t, sdsPath, ca.certPEM, fresh),
+			configDump: configDump(t, []*auth.Secret{tlsSecret(fresh)}, nil),
+			roots:      []*x509.Certificate{ca.cert},
+			status:     OK,
+		},
+		{
+			name:       "no roots",
+			certs:      proxyCerts(t, sdsPath, nil, fresh),
+			configDump: configDump(t, []*auth.Secret{tlsSecret(fresh)}, nil),
+			status:     OK,
+		},
+		{
+			name:       "mounted files",
+			certs:      proxyCerts(t, files, ca.certPEM, fresh),
+			configDump: noConfigDump(t),
+			roots:      []*x509.Certificate{ca.cert},
+			status:     OK,
+		},
+		{
+			name:       "expired",
+			certs:      proxyCerts(t, sdsPath, ca.certPEM, expired),
+			configDump: configDump(t, []*auth.Secret{tlsSecret(expired)}, nil),
+			roots:      []*x509.Certificate{ca.cert},
+			status:     Expired,
+			message:    "expired 1h",
+		},
+		{
+			name:       "chain not signed by the root",
+			certs:      proxyCerts(t, sdsPath, otherCA.certPEM, fresh),
+			configDump: configDump(t, []*auth.Secret{tlsSecret(fresh)}, nil),
+			roots:      []*x509.Certificate{otherCA.cert},
+			status:     RootMismatch,
+			message:    "not signed by the current root",
+		},
+		{
+			name:       "several current roots",
+			certs:      proxyCerts(t, sdsPath, otherCA.certPEM, fresh),
+			configDump: configDump(t, []*auth.Secret{tlsSecret(fresh)}, nil),
+			roots:      []*x509.Certificate{otherCA.cert, ca.cert},
+			status:     OK,
+		},
+		{
+			name:       "root not trusted",
+			certs:      proxyCerts(t, sdsPath, otherCA.certPEM, fresh),
+			configDump: configDump(t, []*auth.Secret{tlsSecret(fresh)}, nil),
+			roots:      []*x509.Certificate{ca.cert},
+			status:     RootMismatch,
+			message:    "doesn't trust the current root",
+		},
+		{
+			name:       "root of mounted files not trusted",
+			certs:      proxyCerts(t, files, otherCA.certPEM, fresh),
+			configDump: noConfigDump(t),
+			roots:      []*x509.Certificate{ca.cert},
+			status:     RootMismatch,
+			message:    "doesn't trust the current root",
+		},
+		{
+			name:       "expiring",
+			certs:      proxyCerts(t, sdsPath, ca.certPEM, expiring),
+			configDump: configDump(t, []*auth.Secret{tlsSecret(expiring)}, nil),
+			roots:      []*x509.Certificate{ca.cert},
+			status:     Expiring,
+			message:    "expires in",
+		},
+		{
+			name:       "not rotated",
+			certs:      proxyCerts(t, sdsPath, ca.certPEM, notRotated),
+			configDump: configDump(t, []*auth.Secret{tlsSecret(notRotated)}, nil),
+			roots:      []*x509.Certificate{ca.cert},
+			status:     RotationFailed,
+			message:    "20% of its lifetime remaining",
+		},
+		{
+			name:  "rotated certificate warming",
+			certs: proxyCerts(t, sdsPath, ca.certPEM, fresh),
+			configDump: configDump(t, []*auth.Secret{tlsSecret(fresh)},
+				[]*auth.Secret{tlsSecret(ca.issue(t, now, 24*time.Hour))}),
+			roots:   []*x509.Certificate{ca.cert},
+			status:  RotationFailed,
+			message: "not applied",
+		},
+		{
+			name:       "previous certificate still used",
+			certs:      proxyCerts(t, files, ca.certPEM, fresh, ca.issue(t, now.Add(-2*time.Hour), 24*time.Hour)),
+			configDump: noConfigDump(t),
+			roots:      []*x509.Certificate{ca.cert},
+			status:     RotationFailed,
+			message:    "previous certificate",
+		},
+		{
+			name:  "config dump not retrieved",
+			certs: proxyCerts(t, sdsPath, ca.certPEM, fresh),
+			configDump: func() ([]byte, error) {
+				return nil, fmt.Errorf("timed out")
+			},
+			roots:   []*x509.Certificate{ca.cert},
+			status:  OK,
+			message: "certificate chain not verified: timed out",
+		},
+		{
+			name:       "no workload certificate",
+			certs:      proxyCerts(t, sdsPath, ca.certPEM),
+			configDump: noConfigDump(t),
+			status:     Unknown,
+			message:    "no workload certificate",
+		},
+		{
+			name:       "invalid certificates",
+			certs:      []byte("{"),
+			configDump: noConfigDump(t),
+			status:     Unknown,
+			message:    "invalid certificates",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			report := Analyze("foo.default", c.certs, c.configDump, Options{
+				Roots:           c.roots,
+				ExpiryThreshold: 6 * time.Hour,
+				RotationRatio:   0.25,
+				Now:             now,
+			})
+			if report.Status != c.status {
+				t.Fatalf("got status %v (%s), want %v", report.Status, report.Message, c.status)
+			}
+			if !strings.Contains(report.Message, c.message) {
+				t.Errorf("got message %q, want %q", report.Message, c.message)
+			}
+			if c.status != Unknown && report.Identity != testIdentity {
+				t.Errorf("got identity %q, want %q", report.Identity, testIdentity)
+			}
+		})
+	}
+}
+
+func TestSortAndPrint(t *testing.T) {
+	notAfter := time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)
+	reports := []ProxyReport{
+		{Proxy: "ok.default", Status: OK, NotAfter: &notAfter, TimeToExpiry: 10 * time.Hour},
+		{Proxy: "unknown.default", Status: Unknown, Message: "unreachable"},
+		{Proxy: "expiring-later.default", Status: Expiring, TimeToExpiry: 2 * time.Hour},
+		{Proxy: "expired.default", Status: Expired, TimeToExpiry: -time.Hour},
+		{Proxy: "expiring.default", Status: Expiring, TimeToExpiry: time.Hour},
+		{Proxy: "mismatch.default", Status: RootMismatch, TimeToExpiry: 5 * time.Hour},
+	}
+	Sort(reports)
+	var got []string
+	for _, r := range reports {
+		got = append(got, r.Proxy)
+	}
+	want := []string{"expired.default", "mismatch.default", "expiring.default", "expiring-later.default",
+		"unknown.default", "ok.default"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got order %v, want %v", got, want)
+	}
+
+	var out bytes.Buffer
+	if err := PrintTabular(&out, reports[len(reports)-1:]); err != nil {
+		t.Fatal(err)
+	}
+	wantTable := `PROXY          STATUS     IDENTITY     SERIAL NUMBER     NOT AFTER                MESSAGE
+ok.default     OK                                        2019-10-01T00:00:00Z     
+`
+	if out.String() != wantTable {
+		t.Errorf("got table\n%q\nwant\n%q", out.String(), wantTable)
+	}
+
+	out.Reset()
+	if err := PrintJSON(&out, reports[:1]); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded[0]["status"] != "EXPIRED" || decoded[0]["time_to_expiry_seconds"] != float64(-3600) {
+		t.Errorf("unexpected JSON report %v", decoded[0])
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var reportColumns = []string{"PROXY", "STATUS", "IDENTITY", "SERIAL NUMBER", "NOT AFTER", "MESSAGE"}
+
+// PrintTabular prints the reports as a table.
+func PrintTabular(w io.Writer, reports []ProxyReport) error {
+	if len(reports) == 0 {
+		_, _ = fmt.Fprintln(w, "No proxies found.")
+		return nil
+	}
+	tw := new(tabwriter.Writer).Init(w, 0, 5, 5, ' ', 0)
+	_, _ = fmt.Fprintln(tw, strings.Join(reportColumns, "\t"))
+	for _, r := range reports {
+		notAfter := ""
+		if r.NotAfter != nil {
+			notAfter = r.NotAfter.UTC().Format(time.RFC3339)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Proxy, r.Status, r.Identity, r.SerialNumber, notAfter, r.Message)
+	}
+	return tw.Flush()
+}
+
+// PrintJSON prints the reports in JSON format.
+func PrintJSON(w io.Writer, reports []ProxyReport) error {
+	if reports == nil {
+		reports = []ProxyReport{}
+	}
+	out, err := json.MarshalIndent(reports, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
@@ -0,0 +1,73 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package langserver
+
+import (
+	"strings"
+)
+
+// completion returns the hosts, gateways or subsets of the workspace which can be the value at the
+// position, or nil if the value at the position isn't one of them.
+func completion(d *yamlDocument, pos Position, idx *index, defaultNamespace string) []CompletionItem {
+	n := d.root.at(pos.Line)
+	if n == nil || n.line != pos.Line || pos.Character < n.col {
+		return nil
+	}
+	field := n
+	if n.index >= 0 {
+		// The values of the lists are completed as the list.
+		field = n.parent
+	} else if pos.Character <= n.col+len(n.key) {
+		// The key is being typed.
+		return nil
+	}
+	if field == nil || field.index >= 0 {
+		return nil
+	}
+
+	namespace := d.metadata("namespace")
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	keys := strings.Join(field.keys(), ".")
+	var values []string
+	var detail string
+	switch d.kind() {
+	case "VirtualService":
+		switch {
+		case keys == "spec.hosts":
+			values, detail = idx.hosts(namespace), "host"
+		case keys == "spec.gateways" || keys == "spec.http.match.gateways" ||
+			keys == "spec.tls.match.gateways" || keys == "spec.tcp.match.gateways":
+			values, detail = idx.gatewayNames(namespace), "gateway"
+		case strings.HasSuffix(keys, ".destination.host") || strings.HasSuffix(keys, ".mirror.host"):
+			values, detail = idx.hosts(namespace), "host"
+		case strings.HasSuffix(keys, ".destination.subset") || strings.HasSuffix(keys, ".mirror.subset"):
+			if host := field.parent.child("host"); host != nil && host.value != "" {
+				values, detail = idx.subsetNames(strings.Trim(host.value, `"'`), namespace), "subset"
+			}
+		}
+	case "DestinationRule":
+		if keys == "spec.host" {
+			values, detail = idx.hosts(namespace), "host"
+		}
+	}
+
+	items := make([]CompletionItem, 0, len(values))
+	for _, v := range values {
+		items = append(items, CompletionItem{Label: v, Kind: CompletionKindReference, Detail: detail})
+	}
+	return items
+}
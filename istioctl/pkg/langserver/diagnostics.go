@@ -0,0 +1,253 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package langserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/hashicorp/go-multierror"
+
+	"istio.io/istio/galley/pkg/config/analysis/diag"
+	"istio.io/istio/galley/pkg/config/analysis/msg"
+	"istio.io/istio/galley/pkg/config/source/kube/rt"
+	"istio.io/istio/pilot/pkg/config/kube/crd"
+	"istio.io/istio/pkg/config/schema"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+// diagnosticSource is the source of the diagnostics shown by the editors.
+const diagnosticSource = "istio"
+
+// unknownField matches the errors of the unknown fields of the protos.
+var unknownField = regexp.MustCompile(`unknown field "([^"]+)"`)
+
+// validate returns the syntax and schema errors of the documents.
+func validate(docs []*yamlDocument) []Diagnostic {
+	var out []Diagnostic
+	for _, d := range docs {
+		if d.parseErr != nil {
+			line := d.errorPosition()
+			out = append(out, Diagnostic{
+				Range:    Range{Start: Position{Line: line}, End: Position{Line: line + 1}},
+				Severity: SeverityError,
+				Source:   diagnosticSource,
+				Message:  strings.TrimPrefix(d.parseErr.Error(), "error converting YAML to JSON: "),
+			})
+			continue
+		}
+		if d.object != nil {
+			out = append(out, validateResource(d)...)
+		}
+	}
+	return out
+}
+
+// validateResource returns the errors of the Istio configuration resource, using the validation
+// of the resources in Pilot and Galley.
+func validateResource(d *yamlDocument) []Diagnostic {
+	s, ok := schemas.Istio.GetByType(crd.CamelCaseToKebabCase(d.kind()))
+	if !ok {
+		return nil
+	}
+	apiVersion, _ := d.object["apiVersion"].(string)
+	if !strings.HasPrefix(apiVersion, crd.ResourceGroup(&s)+"/") {
+		return nil
+	}
+
+	specRange := d.root.keyRange()
+	specNode := d.root.child("spec")
+	if specNode != nil {
+		specRange = specNode.keyRange()
+	} else if kind := d.root.child("kind"); kind != nil {
+		specRange = kind.valueRange()
+	}
+	diagnostic := func(r Range, err error) Diagnostic {
+		return Diagnostic{Range: r, Severity: SeverityError, Source: diagnosticSource, Message: err.Error()}
+	}
+
+	// The spec is decoded strictly, as the unknown fields, which are mostly typos, are ignored by Pilot.
+	spec, err := decodeSpec(s, d.object["spec"])
+	if err != nil {
+		if m := unknownField.FindStringSubmatch(err.Error()); m != nil {
+			r := specRange
+			if specNode != nil {
+				if field := specNode.findKey(m[1]); field != nil {
+					r = field.keyRange()
+				}
+			}
+			return []Diagnostic{diagnostic(r, fmt.Errorf("unknown field %q", m[1]))}
+		}
+		return []Diagnostic{diagnostic(errorRange(specNode, specRange, err), err)}
+	}
+
+	err = s.Validate(d.metadata("name"), d.metadata("namespace"), spec)
+	if err == nil {
+		return nil
+	}
+	var out []Diagnostic
+	if merr, ok := err.(*multierror.Error); ok {
+		for _, e := range merr.Errors {
+			out = append(out, diagnostic(errorRange(specNode, specRange, e), e))
+		}
+	} else {
+		out = append(out, diagnostic(errorRange(specNode, specRange, err), err))
+	}
+	return out
+}
+
+// errorRange returns the range of the field of the spec which the error is about, as the errors of
+// the validation don't have the path of their field: the value the error quotes, else the value it
+// mentions, else the outermost field it names, else the spec.
+func errorRange(specNode *yamlNode, specRange Range, err error) Range {
+	if specNode == nil {
+		return specRange
+	}
+	message := err.Error()
+	words := make(map[string]bool)
+	for _, w := range nonWord.Split(strings.ToLower(message), -1) {
+		words[w] = true
+	}
+
+	var quoted, mentioned, named *yamlNode
+	var quotedLen, mentionedLen int
+	// The fields are visited breadth first, so that the outermost named field is found first.
+	for level := specNode.children; len(level) > 0; {
+		var next []*yamlNode
+		for _, n := range level {
+			next = append(next, n.children...)
+			if v := strings.Trim(n.value, `"'`); v != "" {
+				if len(v) > quotedLen && (strings.Contains(message, `"`+v+`"`) || strings.Contains(message, "'"+v+"'")) {
+					quoted, quotedLen = n, len(v)
+				}
+				if len(v) > mentionedLen && len(v) > 1 && mentions(message, v) {
+					mentioned, mentionedLen = n, len(v)
+				}
+			}
+			if key := strings.ToLower(n.key); named == nil && n.index < 0 && (words[key] || words[strings.TrimSuffix(key, "s")]) {
+				named = n
+			}
+		}
+		level = next
+	}
+	switch {
+	case quoted != nil:
+		return quoted.valueRange()
+	case mentioned != nil:
+		return mentioned.valueRange()
+	case named != nil:
+		return named.keyRange()
+	}
+	return specRange
+}
+
+// nonWord matches the separators of the words of the errors.
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// mentions returns true if the message contains the value, not as part of a longer word, number or
+// host.
+func mentions(message, value string) bool {
+	for i := 0; ; {
+		j := strings.Index(message[i:], value)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(value)
+		if (start == 0 || !isValueChar(message[start-1])) && (end == len(message) || !isValueChar(message[end])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isValueChar(c byte) bool {
+	return c == '.' || c == '-' || c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// decodeSpec decodes the spec of a resource, failing on the unknown fields.
+func decodeSpec(s schema.Instance, spec interface{}) (proto.Message, error) {
+	pb, err := s.Make()
+	if err != nil {
+		return nil, err
+	}
+	if spec == nil {
+		return pb, nil
+	}
+	js, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := (&jsonpb.Unmarshaler{}).Unmarshal(bytes.NewReader(js), pb); err != nil {
+		return nil, err
+	}
+	return pb, nil
+}
+
+// analysisDiagnostics returns the analysis messages of the resources of the documents, at the name of
+// the resources.
+func analysisDiagnostics(docs []*yamlDocument, messages diag.Messages, defaultNamespace string) []Diagnostic {
+	var out []Diagnostic
+	for _, m := range messages {
+		if m.Type == msg.SchemaValidationError {
+			// The schema errors are reported by the validation, at their field.
+			continue
+		}
+		origin, ok := m.Origin.(*rt.Origin)
+		if !ok {
+			continue
+		}
+		ns, name := origin.Name.InterpretAsNamespaceAndName()
+		for _, d := range docs {
+			if d.object == nil || d.kind() != origin.Kind || d.metadata("name") != name {
+				continue
+			}
+			docNamespace := d.metadata("namespace")
+			if docNamespace == "" {
+				docNamespace = defaultNamespace
+			}
+			if ns != "" && docNamespace != ns {
+				continue
+			}
+			r := d.root.keyRange()
+			if n := d.root.find("metadata", "name"); n != nil {
+				r = n.valueRange()
+			}
+			out = append(out, Diagnostic{
+				Range:    r,
+				Severity: severity(m.Type.Level()),
+				Code:     m.Type.Code(),
+				Source:   diagnosticSource,
+				Message:  fmt.Sprintf(m.Type.Template(), m.Parameters...),
+			})
+		}
+	}
+	return out
+}
+
+func severity(l diag.Level) DiagnosticSeverity {
+	switch l {
+	case diag.Error:
+		return SeverityError
+	case diag.Warning:
+		return SeverityWarning
+	default:
+		return SeverityInformation
+	}
+}
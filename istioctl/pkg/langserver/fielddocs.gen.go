@@ -0,0 +1,563 @@
+// GENERATED FILE -- DO NOT EDIT
+//
+
+package langserver
+
+// fieldDocs holds the documentation of the proto messages of the collections, keyed by message name, and of
+// their fields, keyed by message name and JSON field name.
+var fieldDocs = map[string]string{
+	"istio.authentication.v1alpha1.Jwt":                                                         "JSON Web Token (JWT) token format for authentication as defined by [RFC 7519](https://tools.ietf.org/html/rfc7519). See [OAuth 2.0](https://tools.ietf.org/html/rfc6749) and [OIDC 1.0](http://openid.net/connect) for how this is used in the whole authentication flow.",
+	"istio.authentication.v1alpha1.Jwt.TriggerRule":                                             "Trigger rule to match against a request. The trigger rule is satisfied if and only if both rules, excluded_paths and include_paths are satisfied.",
+	"istio.authentication.v1alpha1.Jwt.TriggerRule.excludedPaths":                               "List of paths to be excluded from the request. The rule is satisfied if request path does not match to any of the path in this list.",
+	"istio.authentication.v1alpha1.Jwt.TriggerRule.includedPaths":                               "List of paths that the request must include. If the list is not empty, the rule is satisfied if request path matches at least one of the path in the list. If the list is empty, the rule is ignored, in other words the rule is always satisfied.",
+	"istio.authentication.v1alpha1.Jwt.audiences":                                               "The list of JWT [audiences](https://tools.ietf.org/html/rfc7519#section-4.1.3). that are allowed to access. A JWT containing any of these audiences will be accepted.",
+	"istio.authentication.v1alpha1.Jwt.issuer":                                                  "Identifies the issuer that issued the JWT. See [issuer](https://tools.ietf.org/html/rfc7519#section-4.1.1) Usually a URL or an email address.",
+	"istio.authentication.v1alpha1.Jwt.jwks":                                                    "JSON Web Key Set of public keys to validate signature of the JWT. See https://auth0.com/docs/jwks.",
+	"istio.authentication.v1alpha1.Jwt.jwksUri":                                                 "URL of the provider's public key set to validate signature of the JWT. See [OpenID Discovery](https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata).",
+	"istio.authentication.v1alpha1.Jwt.jwtHeaders":                                              "JWT is sent in a request header. `header` represents the header name.",
+	"istio.authentication.v1alpha1.Jwt.jwtParams":                                               "JWT is sent in a query parameter. `query` represents the query parameter name.",
+	"istio.authentication.v1alpha1.Jwt.triggerRules":                                            "List of trigger rules to decide if this JWT should be used to validate the request. The JWT validation happens if any one of the rules matched. If the list is not empty and none of the rules matched, authentication will skip the JWT validation. Leave this empty to always trigger the JWT validation.",
+	"istio.authentication.v1alpha1.MutualTls":                                                   "TLS authentication params.",
+	"istio.authentication.v1alpha1.MutualTls.Mode":                                              "Defines the acceptable connection TLS mode.",
+	"istio.authentication.v1alpha1.MutualTls.allowTls":                                          "WILL BE DEPRECATED, if set, will translates to `TLS_PERMISSIVE` mode. Set this flag to true to allow regular TLS (i.e without client x509 certificate). If request carries client certificate, identity will be extracted and used (set to peer identity). Otherwise, peer identity will be left unset. When the flag is false (default), request must have client certificate.",
+	"istio.authentication.v1alpha1.OriginAuthenticationMethod":                                  "OriginAuthenticationMethod defines authentication method/params for origin authentication. Origin could be end-user, device, delegate service etc. Currently, only JWT is supported for origin authentication.",
+	"istio.authentication.v1alpha1.PeerAuthenticationMethod":                                    "PeerAuthenticationMethod defines one particular type of authentication, e.g mutual TLS, JWT etc, (no authentication is one type by itself) that can be used for peer authentication. The type can be progammatically determine by checking the type of the \"params\" field.",
+	"istio.authentication.v1alpha1.Policy":                                                      "Policy defines what authentication methods can be accepted on workload(s), and if authenticated, which method/certificate will set the request principal (i.e request.auth.principal attribute).",
+	"istio.authentication.v1alpha1.Policy.originIsOptional":                                     "Set this flag to true to accept request (for origin authentication perspective), even when none of the origin authentication methods defined above satisfied. Typically, this is used to delay the rejection decision to next layer (e.g authorization). This flag is ignored if no authentication defined for origin (origins field is empty).",
+	"istio.authentication.v1alpha1.Policy.origins":                                              "List of authentication methods that can be used for origin authentication. Similar to peers, these will be evaluated in order; the first validate one will be used to set origin identity and attributes (i.e request.auth.user, request.auth.issuer etc). If none of these methods pass, request will be rejected with authentication failed error (401). A method may be skipped, depends on its trigger rule. If all of these methods are skipped, origin authentication will be ignored, as if it is not defined. Leave the list empty if origin authentication is not required.",
+	"istio.authentication.v1alpha1.Policy.peerIsOptional":                                       "Set this flag to true to accept request (for peer authentication perspective), even when none of the peer authentication methods defined above satisfied. Typically, this is used to delay the rejection decision to next layer (e.g authorization). This flag is ignored if no authentication defined for peer (peers field is empty).",
+	"istio.authentication.v1alpha1.Policy.peers":                                                "List of authentication methods that can be used for peer authentication. They will be evaluated in order; the first validate one will be used to set peer identity (source.user) and other peer attributes. If none of these methods pass, request will be rejected with authentication failed error (401). Leave the list empty if peer authentication is not required",
+	"istio.authentication.v1alpha1.Policy.targets":                                              "List rules to select workloads that the policy should be applied on. If empty, policy will be used on all workloads in the same namespace.",
+	"istio.authentication.v1alpha1.PortSelector":                                                "PortSelector specifies the name or number of a port to be used for matching targets for authentication policy. This is copied from networking API to avoid dependency.",
+	"istio.authentication.v1alpha1.PortSelector.name":                                           "Port name",
+	"istio.authentication.v1alpha1.PortSelector.number":                                         "Valid port number",
+	"istio.authentication.v1alpha1.PrincipalBinding":                                            "Associates authentication with request principal.",
+	"istio.authentication.v1alpha1.StringMatch":                                                 "Describes how to match a given string. Match is case-sensitive.",
+	"istio.authentication.v1alpha1.StringMatch.exact":                                           "exact string match.",
+	"istio.authentication.v1alpha1.StringMatch.prefix":                                          "prefix-based match.",
+	"istio.authentication.v1alpha1.StringMatch.regex":                                           "ECMAscript style regex-based match as defined by [EDCA-262](http://en.cppreference.com/w/cpp/regex/ecmascript). Example: \"^/pets/(.*?)?\"",
+	"istio.authentication.v1alpha1.StringMatch.suffix":                                          "suffix-based match.",
+	"istio.authentication.v1alpha1.TargetSelector":                                              "TargetSelector defines a matching rule to a workload. A workload is selected if it is associated with the service name and service port(s) specified in the selector rule.",
+	"istio.authentication.v1alpha1.TargetSelector.name":                                         "The name must be a short name from the service registry. The fully qualified domain name will be resolved in a platform specific manner.",
+	"istio.authentication.v1alpha1.TargetSelector.ports":                                        "Specifies the ports. Note that this is the port(s) exposed by the service, not workload instance ports. For example, if a service is defined as below, then `8000` should be used, not `9000`. ```yaml kind: Service metadata: ... spec: ports: - name: http port: 8000 targetPort: 9000 selector: app: backend ``` Leave empty to match all ports that are exposed.",
+	"istio.mesh.v1alpha1.AuthenticationPolicy":                                                  "AuthenticationPolicy defines authentication policy. It can be set for different scopes (mesh, service …), and the most narrow scope with non-INHERIT value will be used. Mesh policy cannot be INHERIT.",
+	"istio.mesh.v1alpha1.Certificate":                                                           "Certificate configures the provision of a certificate and its key. Example 1: key and cert stored in a secret { secretName: galley-cert secretNamespace: istio-system dnsNames: - galley.istio-system.svc - galley.mydomain.com } Example 2: key and cert stored in a directory { dnsNames: - pilot.istio-system - pilot.istio-system.svc - pilot.mydomain.com }",
+	"istio.mesh.v1alpha1.Certificate.dnsNames":                                                  "The DNS names for the certificate. A certificate may contain multiple DNS names.",
+	"istio.mesh.v1alpha1.Certificate.secretName":                                                "Name of the secret the certificate and its key will be stored into. If it is empty, it will not be stored into a secret. Instead, the certificate and its key will be stored into a hard-coded directory.",
+	"istio.mesh.v1alpha1.ConfigSource":                                                          "ConfigSource describes information about a configuration store inside a mesh. A single control plane instance can interact with one or more data sources.",
+	"istio.mesh.v1alpha1.ConfigSource.address":                                                  "Address of the server implementing the Istio Mesh Configuration protocol (MCP). Can be IP address or a fully qualified DNS name. Use fs:/// to specify a file-based backend with absolute path to the directory.",
+	"istio.mesh.v1alpha1.ConfigSource.subscribedResources":                                      "Describes the source of configuration, if nothing is specified default is MCP",
+	"istio.mesh.v1alpha1.MeshConfig":                                                            "MeshConfig defines mesh-wide variables shared by all Envoy instances in the Istio service mesh.",
+	"istio.mesh.v1alpha1.MeshConfig.H2UpgradePolicy":                                            "Default Policy for upgrading http1.1 connections to http2.",
+	"istio.mesh.v1alpha1.MeshConfig.accessLogFile":                                              "File address for the proxy access log (e.g. /dev/stdout). Empty value disables access logging.",
+	"istio.mesh.v1alpha1.MeshConfig.accessLogFormat":                                            "Format for the proxy access log Empty value results in proxy's default access log format",
+	"istio.mesh.v1alpha1.MeshConfig.certificates":                                               "Configure the provision of certificates.",
+	"istio.mesh.v1alpha1.MeshConfig.configSources":                                              "ConfigSource describes a source of configuration data for networking rules, and other Istio configuration artifacts. Multiple data sources can be configured for a single control plane.",
+	"istio.mesh.v1alpha1.MeshConfig.connectTimeout":                                             "Connection timeout used by Envoy. (MUST BE >=1ms)",
+	"istio.mesh.v1alpha1.MeshConfig.defaultDestinationRuleExportTo":                             "The default value for the DestinationRule.export_to field. Has the same syntax as 'default_service_export_to'.",
+	"istio.mesh.v1alpha1.MeshConfig.defaultServiceExportTo":                                     "The default value for the ServiceEntry.export_to field and services imported through container registry integrations, e.g. this applies to Kubernetes Service resources. The value is a list of namespace names and reserved namespace aliases. The allowed namespace aliases are: * - All Namespaces . - Current Namespace ~ - No Namespace",
+	"istio.mesh.v1alpha1.MeshConfig.defaultVirtualServiceExportTo":                              "The default value for the VirtualService.export_to field. Has the same syntax as 'default_service_export_to'.",
+	"istio.mesh.v1alpha1.MeshConfig.disableMixerHttpReports":                                    "Disable telemetry reporting by the Mixer service for HTTP traffic. Default is false (telemetry reporting via Mixer is enabled). This option provides a transition path for Istio extensibility v2.",
+	"istio.mesh.v1alpha1.MeshConfig.disablePolicyChecks":                                        "Disable policy checks by the Mixer service. Default is false, i.e. Mixer policy check is enabled by default.",
+	"istio.mesh.v1alpha1.MeshConfig.disableReportBatch":                                         "The flag to disable report batch.",
+	"istio.mesh.v1alpha1.MeshConfig.dnsRefreshRate":                                             "Configures DNS refresh rate for Envoy clusters of type STRICT_DNS",
+	"istio.mesh.v1alpha1.MeshConfig.enableAutoMtls":                                             "This flag is used to enable mutual TLS automatically for service to service communication within the mesh, default false. If set to true, and a given service does not have a corresponding DestinationRule configured, or its DestinationRule does not have TLSSettings specified, Istio configures client side TLS configuration appropriately. More specifically, If the upstream authentication policy is in STRICT mode, use Istio provisioned certificate for mutual TLS to connect to upstream. If upstream service is in plain text mode, use plain text. If the upstream authentication policy is in PERMISSIVE mode, Istio configures clients to use mutual TLS when server sides are capable of accepting mutual TLS traffic. If service DestinationRule exists and has TLSSettings specified, that is always used instead.",
+	"istio.mesh.v1alpha1.MeshConfig.enableClientSidePolicyCheck":                                "Enables client side policy checks.",
+	"istio.mesh.v1alpha1.MeshConfig.enableEnvoyAccessLogService":                                "This flag enables Envoy's gRPC Access Log Service. See [Access Log Service](https://www.envoyproxy.io/docs/envoy/latest/api-v2/config/accesslog/v2/als.proto) for details about Envoy's gRPC Access Log Service API.",
+	"istio.mesh.v1alpha1.MeshConfig.enableSdsTokenMount":                                        "This flag is used by secret discovery service(SDS). If set to true ([prerequisite](https://kubernetes.io/docs/concepts/storage/volumes/#projected)), Istio will inject volumes mount for Kubernetes service account trustworthy JWT(which is available with Kubernetes 1.12 or higher), so that the Kubernetes API server mounts Kubernetes service account trustworthy JWT to the Envoy container, which will be used to request key/cert eventually. This isn't supported for non-Kubernetes cases.",
+	"istio.mesh.v1alpha1.MeshConfig.enableTracing":                                              "Flag to control generation of trace spans and request IDs. Requires a trace span collector defined in the proxy configuration.",
+	"istio.mesh.v1alpha1.MeshConfig.inboundClusterStatName":                                     "Name to be used while emitting statistics for inbound clusters. By default, Istio emits statistics with the pattern inbound|<port>|<port-name>|<service-FQDN>. For example inbound|7443|grpc-reviews|reviews.prod.svc.cluster.local. This can be used to override that pattern.",
+	"istio.mesh.v1alpha1.MeshConfig.ingressClass":                                               "Class of ingress resources to be processed by Istio ingress controller. This corresponds to the value of \"kubernetes.io/ingress.class\" annotation.",
+	"istio.mesh.v1alpha1.MeshConfig.ingressService":                                             "Name of theKubernetes service used for the istio ingress controller.",
+	"istio.mesh.v1alpha1.MeshConfig.mixerCheckServer":                                           "Address of the server that will be used by the proxies for policy check calls. By using different names for mixerCheckServer and mixerReportServer, it is possible to have one set of Mixer servers handle policy check calls while another set of Mixer servers handle telemetry calls.",
+	"istio.mesh.v1alpha1.MeshConfig.mixerReportServer":                                          "Address of the server that will be used by the proxies for policy report calls.",
+	"istio.mesh.v1alpha1.MeshConfig.outboundClusterStatName":                                    "Name to be used while emitting statistics for outbound clusters. By default, Istio emits statistics with the pattern outbound|<port>|<subsetname>|<service-FQDN>. For example outbound|8080|v2|reviews.prod.svc.cluster.local. This can be used to override that pattern.",
+	"istio.mesh.v1alpha1.MeshConfig.policyCheckFailOpen":                                        "Allow all traffic in cases when the Mixer policy service cannot be reached. Default is false which means the traffic is denied when the client is unable to connect to Mixer.",
+	"istio.mesh.v1alpha1.MeshConfig.protocolDetectionTimeout":                                   "Automatic protocol detection uses a set of heuristics to determine whether the connection is using TLS or not (on the server side), as well as the application protocol being used (e.g., http vs tcp). These heuristics rely on the client sending the first bits of data. For server first protocols like MySQL, MongoDB, etc., Envoy will timeout on the protocol detection after the specified period, defaulting to non mTLS plain TCP traffic. Set this field to tweak the period that Envoy will wait for the client to send the first bits of data. (MUST BE >=1ms)",
+	"istio.mesh.v1alpha1.MeshConfig.proxyHttpPort":                                              "Port on which Envoy should listen for HTTP PROXY requests if set.",
+	"istio.mesh.v1alpha1.MeshConfig.proxyListenPort":                                            "Port on which Envoy should listen for incoming connections from other services.",
+	"istio.mesh.v1alpha1.MeshConfig.reportBatchMaxEntries":                                      "When disable_report_batch is false, this value specifies the maximum number of requests that are batched in report. If left unspecified, the default value of report_batch_max_entries == 0 will use the hardcoded defaults of istio::mixerclient::ReportOptions.",
+	"istio.mesh.v1alpha1.MeshConfig.reportBatchMaxTime":                                         "When disable_report_batch is false, this value specifies the maximum elapsed time a batched report will be sent after a user request is processed. If left unspecified, the default report_batch_max_time == 0 will use the hardcoded defaults of istio::mixerclient::ReportOptions.",
+	"istio.mesh.v1alpha1.MeshConfig.rootNamespace":                                              "The namespace to treat as the administrative root namespace for Istio configuration. When processing a leaf namespace Istio will search for declarations in that namespace first and if none are found it will search in the root namespace. Any matching declaration found in the root namespace is processed as if it were declared in the leaf namespace.",
+	"istio.mesh.v1alpha1.MeshConfig.sdsUdsPath":                                                 "Unix Domain Socket through which Envoy communicates with NodeAgent SDS to get key/cert for mTLS. Use secret-mount files instead of SDS if set to empty.",
+	"istio.mesh.v1alpha1.MeshConfig.sdsUseK8sSaJwt":                                             "This flag is used by secret discovery service(SDS). If set to true, Envoy will fetch a normal Kubernetes service account JWT from '/var/run/secrets/kubernetes.io/serviceaccount/token' (https://kubernetes.io/docs/tasks/access-application-cluster/access-cluster/#accessing-the-api-from-a-pod) and pass to sds server, which will be used to request key/cert eventually. If both enable_sds_token_mount and sds_use_k8s_sa_jwt are set to true, enable_sds_token_mount(trustworthy jwt) takes precedence. This isn't supported for non-k8s case.",
+	"istio.mesh.v1alpha1.MeshConfig.sidecarToTelemetrySessionAffinity":                          "Enable session affinity for Envoy Mixer reports so that calls from a proxy will always target the same Mixer instance.",
+	"istio.mesh.v1alpha1.MeshConfig.trustDomain":                                                "The trust domain corresponds to the trust root of a system. Refer to [SPIFFE-ID](https://github.com/spiffe/spiffe/blob/master/standards/SPIFFE-ID.md#21-trust-domain)",
+	"istio.mesh.v1alpha1.MeshConfig.trustDomainAliases":                                         "The trust domain aliases represent the aliases of `trust_domain`. For example, if we have ```yaml trustDomain: td1 trustDomainAliases: [\"td2\", \"td3\"] ``` Any service with the identity `td1/ns/foo/sa/a-service-account`, `td2/ns/foo/sa/a-service-account`, or `td3/ns/foo/sa/a-service-account` will be treated the same in the Istio mesh.",
+	"istio.mesh.v1alpha1.ProxyConfig":                                                           "ProxyConfig defines variables for individual Envoy instances.",
+	"istio.mesh.v1alpha1.ProxyConfig.InboundInterceptionMode":                                   "The mode used to redirect inbound traffic to Envoy. This setting has no effect on outbound traffic: iptables REDIRECT is always used for outbound connections.",
+	"istio.mesh.v1alpha1.ProxyConfig.binaryPath":                                                "Path to the proxy binary",
+	"istio.mesh.v1alpha1.ProxyConfig.concurrency":                                               "The number of worker threads to run. Default value is number of cores on the machine.",
+	"istio.mesh.v1alpha1.ProxyConfig.configPath":                                                "Path to the generated configuration file directory. Proxy agent generates the actual configuration and stores it in this directory.",
+	"istio.mesh.v1alpha1.ProxyConfig.connectTimeout":                                            "Connection timeout used by Envoy for supporting services. (MUST BE >=1ms)",
+	"istio.mesh.v1alpha1.ProxyConfig.customConfigFile":                                          "File path of custom proxy configuration, currently used by proxies in front of Mixer and Pilot.",
+	"istio.mesh.v1alpha1.ProxyConfig.discoveryAddress":                                          "Address of the discovery service exposing xDS with mTLS connection.",
+	"istio.mesh.v1alpha1.ProxyConfig.drainDuration":                                             "The time in seconds that Envoy will drain connections during a hot restart. MUST be >=1s (e.g., _1s/1m/1h_)",
+	"istio.mesh.v1alpha1.ProxyConfig.parentShutdownDuration":                                    "The time in seconds that Envoy will wait before shutting down the parent process during a hot restart. MUST be >=1s (e.g., _1s/1m/1h_). MUST BE greater than _drain_duration_ parameter.",
+	"istio.mesh.v1alpha1.ProxyConfig.proxyAdminPort":                                            "Port on which Envoy should listen for administrative commands.",
+	"istio.mesh.v1alpha1.ProxyConfig.proxyBootstrapTemplatePath":                                "Path to the proxy bootstrap template file",
+	"istio.mesh.v1alpha1.ProxyConfig.serviceCluster":                                            "Service cluster defines the name for the service_cluster that is shared by all Envoy instances. This setting corresponds to _--service-cluster_ flag in Envoy. In a typical Envoy deployment, the _service-cluster_ flag is used to identify the caller, for source-based routing scenarios.",
+	"istio.mesh.v1alpha1.ProxyConfig.statNameLength":                                            "Maximum length of name field in Envoy's metrics. The length of the name field is determined by the length of a name field in a service and the set of labels that comprise a particular version of the service. The default value is set to 189 characters. Envoy's internal metrics take up 67 characters, for a total of 256 character name per metric. Increase the value of this field if you find that the metrics from Envoys are truncated.",
+	"istio.mesh.v1alpha1.ProxyConfig.statsdUdpAddress":                                          "IP Address and Port of a statsd UDP listener (e.g. _10.75.241.127:9125_).",
+	"istio.mesh.v1alpha1.ProxyConfig.zipkinAddress":                                             "Address of the Zipkin service (e.g. _zipkin:9411_). DEPRECATED: Use [tracing][istio.mesh.v1alpha1.ProxyConfig.tracing] instead.",
+	"istio.mesh.v1alpha1.RemoteService.address":                                                 "Address of a remove service used for various purposes (access log receiver, metrics receiver, etc.). Can be IP address or a fully qualified DNS name.",
+	"istio.mesh.v1alpha1.Resource":                                                              "Resource describes the source of configuration",
+	"istio.mesh.v1alpha1.SDS":                                                                   "SDS defines secret discovery service(SDS) configuration to be used by the proxy. For workload, its values are set in sidecar injector(passed as arguments to istio-proxy container). For pilot/mixer, it's passed as arguments to istio-proxy container in pilot/mixer deployment yaml files directly.",
+	"istio.mesh.v1alpha1.SDS.enabled":                                                           "True if SDS is enabled.",
+	"istio.mesh.v1alpha1.SDS.k8sSaJwtPath":                                                      "Path of k8s service account JWT path.",
+	"istio.mesh.v1alpha1.Tracing":                                                               "Tracing defines configuration for the tracing performed by Envoy instances.",
+	"istio.mesh.v1alpha1.Tracing.Datadog":                                                       "Datadog defines configuration for a Datadog tracer.",
+	"istio.mesh.v1alpha1.Tracing.Datadog.address":                                               "Address of the Datadog Agent.",
+	"istio.mesh.v1alpha1.Tracing.Lightstep":                                                     "Defines configuration for a LightStep tracer.",
+	"istio.mesh.v1alpha1.Tracing.Lightstep.accessToken":                                         "The LightStep access token.",
+	"istio.mesh.v1alpha1.Tracing.Lightstep.address":                                             "Address of the LightStep Satellite pool.",
+	"istio.mesh.v1alpha1.Tracing.Lightstep.cacertPath":                                          "Path to the trusted cacert used to authenticate the pool.",
+	"istio.mesh.v1alpha1.Tracing.Lightstep.secure":                                              "True if a secure connection should be used when communicating with the pool.",
+	"istio.mesh.v1alpha1.Tracing.Stackdriver":                                                   "Stackdriver defines configuration for a Stackdriver tracer. See [Opencensus trace config](https://github.com/census-instrumentation/opencensus-proto/blob/master/src/opencensus/proto/trace/v1/trace_config.proto) for details.",
+	"istio.mesh.v1alpha1.Tracing.Stackdriver.debug":                                             "debug enables trace output to stdout. $hide_from_docs",
+	"istio.mesh.v1alpha1.Tracing.Stackdriver.maxNumberOfAnnotations":                            "The global default max number of annotation events per span. default is 200. $hide_from_docs",
+	"istio.mesh.v1alpha1.Tracing.Stackdriver.maxNumberOfAttributes":                             "The global default max number of attributes per span. default is 200. $hide_from_docs",
+	"istio.mesh.v1alpha1.Tracing.Stackdriver.maxNumberOfMessageEvents":                          "The global default max number of message events per span. default is 200. $hide_from_docs",
+	"istio.mesh.v1alpha1.Tracing.Zipkin":                                                        "Zipkin defines configuration for a Zipkin tracer.",
+	"istio.mesh.v1alpha1.Tracing.Zipkin.address":                                                "Address of the Zipkin service (e.g. _zipkin:9411_).",
+	"istio.mixer.v1.Attributes":                                                                 "Attributes represents a set of typed name/value pairs. Many of Mixer's API either consume and/or return attributes.",
+	"istio.mixer.v1.Attributes.AttributeValue":                                                  "Specifies one attribute value with different type.",
+	"istio.mixer.v1.Attributes.AttributeValue.boolValue":                                        "Used for values of type BOOL",
+	"istio.mixer.v1.Attributes.AttributeValue.bytesValue":                                       "Used for values of type BYTES",
+	"istio.mixer.v1.Attributes.AttributeValue.doubleValue":                                      "Used for values of type DOUBLE",
+	"istio.mixer.v1.Attributes.AttributeValue.durationValue":                                    "Used for values of type DURATION",
+	"istio.mixer.v1.Attributes.AttributeValue.int64Value":                                       "Used for values of type INT64",
+	"istio.mixer.v1.Attributes.AttributeValue.stringValue":                                      "Used for values of type STRING, DNS_NAME, EMAIL_ADDRESS, and URI",
+	"istio.mixer.v1.Attributes.AttributeValue.timestampValue":                                   "Used for values of type TIMESTAMP",
+	"istio.mixer.v1.Attributes.StringMap":                                                       "Defines a string map.",
+	"istio.mixer.v1.Attributes.StringMap.entries":                                               "Holds a set of name/value pairs.",
+	"istio.mixer.v1.Attributes.attributes":                                                      "A map of attribute name to its value.",
+	"istio.mixer.v1.config.client.APIKey":                                                       "APIKey defines the explicit configuration for generating the `request.api_key` attribute from HTTP requests.",
+	"istio.mixer.v1.config.client.APIKey.cookie":                                                "API key is sent in a [cookie](https://swagger.io/docs/specification/authentication/cookie-authentication),",
+	"istio.mixer.v1.config.client.APIKey.header":                                                "API key is sent in a request header. `header` represents the header name.",
+	"istio.mixer.v1.config.client.APIKey.query":                                                 "API Key is sent as a query parameter. `query` represents the query string parameter name.",
+	"istio.mixer.v1.config.client.AttributeMatch":                                               "Specifies a match clause to match Istio attributes",
+	"istio.mixer.v1.config.client.AttributeMatch.clause":                                        "Map of attribute names to StringMatch type. Each map element specifies one condition to match.",
+	"istio.mixer.v1.config.client.HTTPAPISpec":                                                  "HTTPAPISpec defines the canonical configuration for generating API-related attributes from HTTP requests based on the method and uri templated path matches. It is sufficient for defining the API surface of a service for the purposes of API attribute generation. It is not intended to represent auth, quota, documentation, or other information commonly found in other API specifications, e.g. OpenAPI.",
+	"istio.mixer.v1.config.client.HTTPAPISpec.apiKeys":                                          "List of APIKey that describes how to extract an API-KEY from an HTTP request. The first API-Key match found in the list is used, i.e. 'OR' semantics.",
+	"istio.mixer.v1.config.client.HTTPAPISpec.patterns":                                         "List of HTTP patterns to match.",
+	"istio.mixer.v1.config.client.HTTPAPISpecBinding":                                           "HTTPAPISpecBinding defines the binding between HTTPAPISpecs and one or more IstioService. For example, the following establishes a binding between the HTTPAPISpec `petstore` and service `foo` in namespace `bar`.",
+	"istio.mixer.v1.config.client.HTTPAPISpecBinding.apiSpecs":                                  "One or more HTTPAPISpec references that should be mapped to the specified service(s). The aggregate collection of match conditions defined in the HTTPAPISpecs should not overlap.",
+	"istio.mixer.v1.config.client.HTTPAPISpecBinding.services":                                  "One or more services to map the listed HTTPAPISpec onto.",
+	"istio.mixer.v1.config.client.HTTPAPISpecPattern":                                           "HTTPAPISpecPattern defines a single pattern to match against incoming HTTP requests. The per-pattern list of attributes is generated if both the http_method and uri_template match. In addition, the top-level list of attributes in the HTTPAPISpec is also generated.",
+	"istio.mixer.v1.config.client.HTTPAPISpecPattern.httpMethod":                                "HTTP request method to match against as defined by [rfc7231](https://tools.ietf.org/html/rfc7231#page-21). For example: GET, HEAD, POST, PUT, DELETE.",
+	"istio.mixer.v1.config.client.HTTPAPISpecPattern.regex":                                     "EXPERIMENTAL: ecmascript style regex-based match as defined by [EDCA-262](http://en.cppreference.com/w/cpp/regex/ecmascript). For example,",
+	"istio.mixer.v1.config.client.HTTPAPISpecPattern.uriTemplate":                               "URI template to match against as defined by [rfc6570](https://tools.ietf.org/html/rfc6570). For example, the following are valid URI templates: /pets /pets/{id} /dictionary/{term:1}/{term} /search{?q*,lang}",
+	"istio.mixer.v1.config.client.HTTPAPISpecReference":                                         "HTTPAPISpecReference defines a reference to an HTTPAPISpec. This is typically used for establishing bindings between an HTTPAPISpec and an IstioService. For example, the following defines an HTTPAPISpecReference for service `foo` in namespace `bar`.",
+	"istio.mixer.v1.config.client.HTTPAPISpecReference.name":                                    "The short name of the HTTPAPISpec. This is the resource name defined by the metadata name field.",
+	"istio.mixer.v1.config.client.HTTPAPISpecReference.namespace":                               "Optional namespace of the HTTPAPISpec. Defaults to the encompassing HTTPAPISpecBinding's metadata namespace field.",
+	"istio.mixer.v1.config.client.IstioService":                                                 "IstioService identifies a service and optionally service version. The FQDN of the service is composed from the name, namespace, and implementation-specific domain suffix (e.g. on Kubernetes, \"reviews\" + \"default\" + \"svc.cluster.local\" -> \"reviews.default.svc.cluster.local\").",
+	"istio.mixer.v1.config.client.IstioService.domain":                                          "Domain suffix used to construct the service FQDN in implementations that support such specification.",
+	"istio.mixer.v1.config.client.IstioService.labels":                                          "Optional one or more labels that uniquely identify the service version.",
+	"istio.mixer.v1.config.client.IstioService.name":                                            "The short name of the service such as \"foo\".",
+	"istio.mixer.v1.config.client.IstioService.namespace":                                       "Optional namespace of the service. Defaults to value of metadata namespace field.",
+	"istio.mixer.v1.config.client.IstioService.service":                                         "The service FQDN.",
+	"istio.mixer.v1.config.client.Quota":                                                        "Specifies a quota to use with quota name and amount.",
+	"istio.mixer.v1.config.client.Quota.charge":                                                 "The quota amount to charge",
+	"istio.mixer.v1.config.client.Quota.quota":                                                  "The quota name to charge",
+	"istio.mixer.v1.config.client.QuotaRule":                                                    "Specifies a rule with list of matches and list of quotas. If any clause matched, the list of quotas will be used.",
+	"istio.mixer.v1.config.client.QuotaRule.match":                                              "If empty, match all request. If any of match is true, it is matched.",
+	"istio.mixer.v1.config.client.QuotaRule.quotas":                                             "The list of quotas to charge.",
+	"istio.mixer.v1.config.client.QuotaSpec":                                                    "Determines the quotas used for individual requests.",
+	"istio.mixer.v1.config.client.QuotaSpec.rules":                                              "A list of Quota rules.",
+	"istio.mixer.v1.config.client.QuotaSpecBinding":                                             "QuotaSpecBinding defines the binding between QuotaSpecs and one or more IstioService.",
+	"istio.mixer.v1.config.client.QuotaSpecBinding.QuotaSpecReference":                          "QuotaSpecReference uniquely identifies the QuotaSpec used in the Binding.",
+	"istio.mixer.v1.config.client.QuotaSpecBinding.QuotaSpecReference.name":                     "The short name of the QuotaSpec. This is the resource name defined by the metadata name field.",
+	"istio.mixer.v1.config.client.QuotaSpecBinding.QuotaSpecReference.namespace":                "Optional namespace of the QuotaSpec. Defaults to the value of the metadata namespace field.",
+	"istio.mixer.v1.config.client.QuotaSpecBinding.quotaSpecs":                                  "One or more QuotaSpec references that should be mapped to the specified service(s). The aggregate collection of match conditions defined in the QuotaSpecs should not overlap.",
+	"istio.mixer.v1.config.client.QuotaSpecBinding.services":                                    "One or more services to map the listed QuotaSpec onto.",
+	"istio.mixer.v1.config.client.StringMatch":                                                  "Describes how to match a given string in HTTP headers. Match is case-sensitive.",
+	"istio.mixer.v1.config.client.StringMatch.exact":                                            "exact string match",
+	"istio.mixer.v1.config.client.StringMatch.prefix":                                           "prefix-based match",
+	"istio.mixer.v1.config.client.StringMatch.regex":                                            "ECMAscript style regex-based match",
+	"istio.networking.v1alpha3.CaptureMode":                                                     "`CaptureMode` describes how traffic to a listener is expected to be captured. Applicable only when the listener is bound to an IP.",
+	"istio.networking.v1alpha3.ConnectionPoolSettings":                                          "Connection pool settings for an upstream host. The settings apply to each individual host in the upstream service. See Envoy's [circuit breaker](https://www.envoyproxy.io/docs/envoy/latest/intro/arch_overview/upstream/circuit_breaking) for more details. Connection pool settings can be applied at the TCP level as well as at HTTP level.",
+	"istio.networking.v1alpha3.ConnectionPoolSettings.HTTPSettings":                             "Settings applicable to HTTP1.1/HTTP2/GRPC connections.",
+	"istio.networking.v1alpha3.ConnectionPoolSettings.HTTPSettings.H2UpgradePolicy":             "Policy for upgrading http1.1 connections to http2.",
+	"istio.networking.v1alpha3.ConnectionPoolSettings.HTTPSettings.http1MaxPendingRequests":     "Maximum number of pending HTTP requests to a destination. Default 2^32-1.",
+	"istio.networking.v1alpha3.ConnectionPoolSettings.HTTPSettings.http2MaxRequests":            "Maximum number of requests to a backend. Default 2^32-1.",
+	"istio.networking.v1alpha3.ConnectionPoolSettings.HTTPSettings.idleTimeout":                 "The idle timeout for upstream connection pool connections. The idle timeout is defined as the period in which there are no active requests. If not set, the default is 1 hour. When the idle timeout is reached the connection will be closed. Note that request based timeouts mean that HTTP/2 PINGs will not keep the connection alive. Applies to both HTTP1.1 and HTTP2 connections.",
+	"istio.networking.v1alpha3.ConnectionPoolSettings.HTTPSettings.maxRequestsPerConnection":    "Maximum number of requests per connection to a backend. Setting this parameter to 1 disables keep alive. Default 0, meaning \"unlimited\", up to 2^29.",
+	"istio.networking.v1alpha3.ConnectionPoolSettings.HTTPSettings.maxRetries":                  "Maximum number of retries that can be outstanding to all hosts in a cluster at a given time. Defaults to 2^32-1.",
+	"istio.networking.v1alpha3.ConnectionPoolSettings.TCPSettings":                              "Settings common to both HTTP and TCP upstream connections.",
+	"istio.networking.v1alpha3.ConnectionPoolSettings.TCPSettings.TcpKeepalive":                 "TCP keepalive.",
+	"istio.networking.v1alpha3.ConnectionPoolSettings.TCPSettings.TcpKeepalive.interval":        "The time duration between keep-alive probes. Default is to use the OS level configuration (unless overridden, Linux defaults to 75s.)",
+	"istio.networking.v1alpha3.ConnectionPoolSettings.TCPSettings.TcpKeepalive.probes":          "Maximum number of keepalive probes to send without response before deciding the connection is dead. Default is to use the OS level configuration (unless overridden, Linux defaults to 9.)",
+	"istio.networking.v1alpha3.ConnectionPoolSettings.TCPSettings.TcpKeepalive.time":            "The time duration a connection needs to be idle before keep-alive probes start being sent. Default is to use the OS level configuration (unless overridden, Linux defaults to 7200s (ie 2 hours.)",
+	"istio.networking.v1alpha3.ConnectionPoolSettings.TCPSettings.connectTimeout":               "TCP connection timeout.",
+	"istio.networking.v1alpha3.ConnectionPoolSettings.TCPSettings.maxConnections":               "Maximum number of HTTP1 /TCP connections to a destination host. Default 2^32-1.",
+	"istio.networking.v1alpha3.CorsPolicy":                                                      "Describes the Cross-Origin Resource Sharing (CORS) policy, for a given service. Refer to [CORS](https://developer.mozilla.org/en-US/docs/Web/HTTP/Access_control_CORS) for further details about cross origin resource sharing. For example, the following rule restricts cross origin requests to those originating from example.com domain using HTTP POST/GET, and sets the `Access-Control-Allow-Credentials` header to false. In addition, it only exposes `X-Foo-bar` header and sets an expiry period of 1 day.",
+	"istio.networking.v1alpha3.CorsPolicy.allowCredentials":                                     "Indicates whether the caller is allowed to send the actual request (not the preflight) using credentials. Translates to `Access-Control-Allow-Credentials` header.",
+	"istio.networking.v1alpha3.CorsPolicy.allowHeaders":                                         "List of HTTP headers that can be used when requesting the resource. Serialized to Access-Control-Allow-Headers header.",
+	"istio.networking.v1alpha3.CorsPolicy.allowMethods":                                         "List of HTTP methods allowed to access the resource. The content will be serialized into the Access-Control-Allow-Methods header.",
+	"istio.networking.v1alpha3.CorsPolicy.allowOrigin":                                          "The list of origins that are allowed to perform CORS requests. The content will be serialized into the Access-Control-Allow-Origin header. Wildcard * will allow all origins.",
+	"istio.networking.v1alpha3.CorsPolicy.exposeHeaders":                                        "A white list of HTTP headers that the browsers are allowed to access. Serialized into Access-Control-Expose-Headers header.",
+	"istio.networking.v1alpha3.CorsPolicy.maxAge":                                               "Specifies how long the results of a preflight request can be cached. Translates to the `Access-Control-Max-Age` header.",
+	"istio.networking.v1alpha3.Destination":                                                     "Destination indicates the network addressable service to which the request/connection will be sent after processing a routing rule. The destination.host should unambiguously refer to a service in the service registry. Istio's service registry is composed of all the services found in the platform's service registry (e.g., Kubernetes services, Consul services), as well as services declared through the [ServiceEntry](https://istio.io/docs/reference/config/networking/service-entry/#ServiceEntry) resource.",
+	"istio.networking.v1alpha3.Destination.host":                                                "The name of a service from the service registry. Service names are looked up from the platform's service registry (e.g., Kubernetes services, Consul services, etc.) and from the hosts declared by [ServiceEntry](https://istio.io/docs/reference/config/networking/service-entry/#ServiceEntry). Traffic forwarded to destinations that are not found in either of the two, will be dropped.",
+	"istio.networking.v1alpha3.Destination.subset":                                              "The name of a subset within the service. Applicable only to services within the mesh. The subset must be defined in a corresponding DestinationRule.",
+	"istio.networking.v1alpha3.DestinationRule":                                                 "DestinationRule defines policies that apply to traffic intended for a service after routing has occurred.",
+	"istio.networking.v1alpha3.DestinationRule.exportTo":                                        "A list of namespaces to which this destination rule is exported. The resolution of a destination rule to apply to a service occurs in the context of a hierarchy of namespaces. Exporting a destination rule allows it to be included in the resolution hierarchy for services in other namespaces. This feature provides a mechanism for service owners and mesh administrators to control the visibility of destination rules across namespace boundaries.",
+	"istio.networking.v1alpha3.DestinationRule.host":                                            "The name of a service from the service registry. Service names are looked up from the platform's service registry (e.g., Kubernetes services, Consul services, etc.) and from the hosts declared by [ServiceEntries](https://istio.io/docs/reference/config/networking/service-entry/#ServiceEntry). Rules defined for services that do not exist in the service registry will be ignored.",
+	"istio.networking.v1alpha3.DestinationRule.subsets":                                         "One or more named sets that represent individual versions of a service. Traffic policies can be overridden at subset level.",
+	"istio.networking.v1alpha3.EnvoyFilter":                                                     "EnvoyFilter provides a mechanism to customize the Envoy configuration generated by Istio Pilot.",
+	"istio.networking.v1alpha3.EnvoyFilter.ApplyTo":                                             "ApplyTo specifies where in the Envoy configuration, the given patch should be applied.",
+	"istio.networking.v1alpha3.EnvoyFilter.ClusterMatch":                                        "Conditions specified in ClusterMatch must be met for the patch to be applied to a cluster.",
+	"istio.networking.v1alpha3.EnvoyFilter.ClusterMatch.name":                                   "The exact name of the cluster to match. To match a specific cluster by name, such as the internally generated \"Passthrough\" cluster, leave all fields in clusterMatch empty, except the name.",
+	"istio.networking.v1alpha3.EnvoyFilter.ClusterMatch.portNumber":                             "The service port for which this cluster was generated. If omitted, applies to clusters for any port.",
+	"istio.networking.v1alpha3.EnvoyFilter.ClusterMatch.service":                                "The fully qualified service name for this cluster. If omitted, applies to clusters for any service. For services defined through service entries, the service name is same as the hosts defined in the service entry.",
+	"istio.networking.v1alpha3.EnvoyFilter.ClusterMatch.subset":                                 "The subset associated with the service. If omitted, applies to clusters for any subset of a service.",
+	"istio.networking.v1alpha3.EnvoyFilter.DeprecatedListenerMatch":                             "Deprecated. Select a listener to add the filter to based on the match conditions. All conditions specified in the ListenerMatch must be met for the filter to be applied to a listener. $hide_from_docs",
+	"istio.networking.v1alpha3.EnvoyFilter.DeprecatedListenerMatch.address":                     "One or more IP addresses to which the listener is bound. If specified, should match at least one address in the list.",
+	"istio.networking.v1alpha3.EnvoyFilter.DeprecatedListenerMatch.portNamePrefix":              "Instead of using specific port numbers, a set of ports matching a given port name prefix can be selected. E.g., \"mongo\" selects ports named mongo-port, mongo, mongoDB, MONGO, etc. Matching is case insensitive.",
+	"istio.networking.v1alpha3.EnvoyFilter.DeprecatedListenerMatch.portNumber":                  "The service port/gateway port to which traffic is being sent/received. If not specified, matches all listeners. Even though inbound listeners are generated for the instance/pod ports, only service ports should be used to match listeners.",
+	"istio.networking.v1alpha3.EnvoyFilter.EnvoyConfigObjectMatch":                              "One or more match conditions to be met before a patch is applied to the generated configuration for a given proxy.",
+	"istio.networking.v1alpha3.EnvoyFilter.EnvoyConfigObjectPatch":                              "Changes to be made to various envoy config objects.",
+	"istio.networking.v1alpha3.EnvoyFilter.Filter":                                              "Deprecated. Envoy filters to be added to a network or http filter chain. $hide_from_docs",
+	"istio.networking.v1alpha3.EnvoyFilter.Filter.filterConfig":                                 "Filter specific configuration which depends on the filter being instantiated.",
+	"istio.networking.v1alpha3.EnvoyFilter.Filter.filterName":                                   "The name of the filter to instantiate. The name must match a supported filter _compiled into_ Envoy.",
+	"istio.networking.v1alpha3.EnvoyFilter.InsertPosition":                                      "Deprecated. Indicates the relative index in the filter chain where the filter should be inserted. $hide_from_docs",
+	"istio.networking.v1alpha3.EnvoyFilter.InsertPosition.Index":                                "Index/position in the filter chain.",
+	"istio.networking.v1alpha3.EnvoyFilter.InsertPosition.relativeTo":                           "If BEFORE or AFTER position is specified, specify the name of the filter relative to which this filter should be inserted.",
+	"istio.networking.v1alpha3.EnvoyFilter.ListenerMatch":                                       "Conditions specified in a listener match must be met for the patch to be applied to a specific listener across all filter chains, or a specific filter chain inside the listener.",
+	"istio.networking.v1alpha3.EnvoyFilter.ListenerMatch.FilterChainMatch":                      "For listeners with multiple filter chains (e.g., inbound listeners on sidecars with permissive mTLS, gateway listeners with multiple SNI matches), the filter chain match can be used to select a specific filter chain to patch.",
+	"istio.networking.v1alpha3.EnvoyFilter.ListenerMatch.FilterChainMatch.applicationProtocols": "Applies only to sidecars. If non-empty, a comma separated set of application protocols to consider when determining a filter chain match. This value will be compared against the application protocols of a new connection, when it's detected by one of the listener filters such as the http_inspector.",
+	"istio.networking.v1alpha3.EnvoyFilter.ListenerMatch.FilterChainMatch.name":                 "The name assigned to the filter chain.",
+	"istio.networking.v1alpha3.EnvoyFilter.ListenerMatch.FilterChainMatch.sni":                  "The SNI value used by a filter chain's match condition. This condition will evaluate to false if the filter chain has no sni match.",
+	"istio.networking.v1alpha3.EnvoyFilter.ListenerMatch.FilterChainMatch.transportProtocol":    "Applies only to SIDECAR_INBOUND context. If non-empty, a transport protocol to consider when determining a filter chain match. This value will be compared against the transport protocol of a new connection, when it's detected by the tls_inspector listener filter.",
+	"istio.networking.v1alpha3.EnvoyFilter.ListenerMatch.FilterMatch":                           "Conditions to match a specific filter within a filter chain.",
+	"istio.networking.v1alpha3.EnvoyFilter.ListenerMatch.FilterMatch.name":                      "The filter name to match on.",
+	"istio.networking.v1alpha3.EnvoyFilter.ListenerMatch.SubFilterMatch":                        "Conditions to match a specific filter within another filter. This field is typically useful to match a HTTP filter inside the envoy.http_connection_manager network filter. This could also be applicable for thrift filters.",
+	"istio.networking.v1alpha3.EnvoyFilter.ListenerMatch.SubFilterMatch.name":                   "The filter name to match on.",
+	"istio.networking.v1alpha3.EnvoyFilter.ListenerMatch.name":                                  "Match a specific listener by its name. The listeners generated by Pilot are typically named as IP:Port.",
+	"istio.networking.v1alpha3.EnvoyFilter.ListenerMatch.portName":                              "Instead of using specific port numbers, a set of ports matching a given service's port name can be selected. Matching is case insensitive. Not implemented. $hide_from_docs",
+	"istio.networking.v1alpha3.EnvoyFilter.ListenerMatch.portNumber":                            "The service port/gateway port to which traffic is being sent/received. If not specified, matches all listeners. Even though inbound listeners are generated for the instance/pod ports, only service ports should be used to match listeners.",
+	"istio.networking.v1alpha3.EnvoyFilter.Patch":                                               "Patch specifies how the selected object should be modified.",
+	"istio.networking.v1alpha3.EnvoyFilter.Patch.Operation":                                     "Operation denotes how the patch should be applied to the selected configuration.",
+	"istio.networking.v1alpha3.EnvoyFilter.Patch.value":                                         "The JSON config of the object being patched. This will be merged using json merge semantics with the existing proto in the path.",
+	"istio.networking.v1alpha3.EnvoyFilter.PatchContext":                                        "PatchContext selects a class of configurations based on the traffic flow direction and workload type.",
+	"istio.networking.v1alpha3.EnvoyFilter.ProxyMatch":                                          "One or more properties of the proxy to match on.",
+	"istio.networking.v1alpha3.EnvoyFilter.ProxyMatch.metadata":                                 "Match on the node metadata supplied by a proxy when connecting to Istio Pilot. Note that while Envoy's node metadata is of type Struct, only string key-value pairs are processed by Pilot. All keys specified in the metadata must match with exact values. The match will fail if any of the specified keys are absent or the values fail to match.",
+	"istio.networking.v1alpha3.EnvoyFilter.ProxyMatch.proxyVersion":                             "A regular expression in golang regex format (RE2) that can be used to select proxies using a specific version of istio proxy. The Istio version for a given proxy is obtained from the node metadata field ISTIO_VERSION supplied by the proxy when connecting to Pilot. This value is embedded as an environment variable (ISTIO_META_ISTIO_VERSION) in the Istio proxy docker image. Custom proxy implementations should provide this metadata variable to take advantage of the Istio version check option.",
+	"istio.networking.v1alpha3.EnvoyFilter.RouteConfigurationMatch":                             "Conditions specified in RouteConfigurationMatch must be met for the patch to be applied to a route configuration object or a specific virtual host within the route configuration.",
+	"istio.networking.v1alpha3.EnvoyFilter.RouteConfigurationMatch.RouteMatch":                  "Match a specific route inside a virtual host in a route configuration.",
+	"istio.networking.v1alpha3.EnvoyFilter.RouteConfigurationMatch.RouteMatch.Action":           "Action refers to the route action taken by Envoy when a http route matches.",
+	"istio.networking.v1alpha3.EnvoyFilter.RouteConfigurationMatch.RouteMatch.name":             "The Route objects generated by default are named as \"default\". Route objects generated using a virtual service will carry the name used in the virtual service's HTTP routes.",
+	"istio.networking.v1alpha3.EnvoyFilter.RouteConfigurationMatch.VirtualHostMatch":            "Match a specific virtual host inside a route configuration.",
+	"istio.networking.v1alpha3.EnvoyFilter.RouteConfigurationMatch.VirtualHostMatch.name":       "The VirtualHosts objects generated by Istio are named as host:port, where the host typically corresponds to the VirtualService's host field or the hostname of a service in the registry.",
+	"istio.networking.v1alpha3.EnvoyFilter.RouteConfigurationMatch.gateway":                     "The Istio gateway config's namespace/name for which this route configuration was generated. Applies only if the context is GATEWAY. Should be in the namespace/name format. Use this field in conjunction with the portNumber and portName to accurately select the Envoy route configuration for a specific HTTPS server within a gateway config object.",
+	"istio.networking.v1alpha3.EnvoyFilter.RouteConfigurationMatch.name":                        "Route configuration name to match on. Can be used to match a specific route configuration by name, such as the internally generated \"http_proxy\" route configuration for all sidecars.",
+	"istio.networking.v1alpha3.EnvoyFilter.RouteConfigurationMatch.portName":                    "Applicable only for GATEWAY context. The gateway server port name for which this route configuration was generated.",
+	"istio.networking.v1alpha3.EnvoyFilter.RouteConfigurationMatch.portNumber":                  "The service port number or gateway server port number for which this route configuration was generated. If omitted, applies to route configurations for all ports.",
+	"istio.networking.v1alpha3.EnvoyFilter.configPatches":                                       "One or more patches with match conditions.",
+	"istio.networking.v1alpha3.EnvoyFilter.workloadLabels":                                      "Deprecated. Use workload_selector instead. $hide_from_docs",
+	"istio.networking.v1alpha3.Gateway":                                                         "Gateway describes a load balancer operating at the edge of the mesh receiving incoming or outgoing HTTP/TCP connections.",
+	"istio.networking.v1alpha3.Gateway.selector":                                                "One or more labels that indicate a specific set of pods/VMs on which this gateway configuration should be applied. The scope of label search is restricted to the configuration namespace in which the the resource is present. In other words, the Gateway resource must reside in the same namespace as the gateway workload instance.",
+	"istio.networking.v1alpha3.Gateway.servers":                                                 "A list of server specifications.",
+	"istio.networking.v1alpha3.HTTPFaultInjection":                                              "HTTPFaultInjection can be used to specify one or more faults to inject while forwarding http requests to the destination specified in a route. Fault specification is part of a VirtualService rule. Faults include aborting the Http request from downstream service, and/or delaying proxying of requests. A fault rule MUST HAVE delay or abort or both.",
+	"istio.networking.v1alpha3.HTTPFaultInjection.Abort":                                        "Abort specification is used to prematurely abort a request with a pre-specified error code. The following example will return an HTTP 400 error code for 1 out of every 1000 requests to the \"ratings\" service \"v1\".",
+	"istio.networking.v1alpha3.HTTPFaultInjection.Abort.httpStatus":                             "HTTP status code to use to abort the Http request.",
+	"istio.networking.v1alpha3.HTTPFaultInjection.Abort.percent":                                "Percentage of requests to be aborted with the error code provided (0-100). Use of integer `percent` value is deprecated. Use the double `percentage` field instead.",
+	"istio.networking.v1alpha3.HTTPFaultInjection.Delay":                                        "Delay specification is used to inject latency into the request forwarding path. The following example will introduce a 5 second delay in 1 out of every 1000 requests to the \"v1\" version of the \"reviews\" service from all pods with label env: prod",
+	"istio.networking.v1alpha3.HTTPFaultInjection.Delay.fixedDelay":                             "Add a fixed delay before forwarding the request. Format: 1h/1m/1s/1ms. MUST be >=1ms.",
+	"istio.networking.v1alpha3.HTTPFaultInjection.Delay.percent":                                "Percentage of requests on which the delay will be injected (0-100). Use of integer `percent` value is deprecated. Use the double `percentage` field instead.",
+	"istio.networking.v1alpha3.HTTPMatchRequest":                                                "HttpMatchRequest specifies a set of criterion to be met in order for the rule to be applied to the HTTP request. For example, the following restricts the rule to match only requests where the URL path starts with /ratings/v2/ and the request contains a custom `end-user` header with value `jason`.",
+	"istio.networking.v1alpha3.HTTPMatchRequest.headers":                                        "The header keys must be lowercase and use hyphen as the separator, e.g. _x-request-id_.",
+	"istio.networking.v1alpha3.HTTPMatchRequest.ignoreUriCase":                                  "Flag to specify whether the URI matching should be case-insensitive.",
+	"istio.networking.v1alpha3.HTTPMatchRequest.name":                                           "The name assigned to a match. The match's name will be concatenated with the parent route's name and will be logged in the access logs for requests matching this route.",
+	"istio.networking.v1alpha3.HTTPMatchRequest.port":                                           "Specifies the ports on the host that is being addressed. Many services only expose a single port or label ports with the protocols they support, in these cases it is not required to explicitly select the port.",
+	"istio.networking.v1alpha3.HTTPMatchRequest.queryParams":                                    "Query parameters for matching.",
+	"istio.networking.v1alpha3.HTTPMatchRequest.sourceLabels":                                   "One or more labels that constrain the applicability of a rule to workloads with the given labels. If the VirtualService has a list of gateways specified at the top, it must include the reserved gateway `mesh` for this field to be applicable.",
+	"istio.networking.v1alpha3.HTTPRedirect":                                                    "HTTPRedirect can be used to send a 301 redirect response to the caller, where the Authority/Host and the URI in the response can be swapped with the specified values. For example, the following rule redirects requests for /v1/getProductRatings API on the ratings service to /v1/bookRatings provided by the bookratings service.",
+	"istio.networking.v1alpha3.HTTPRedirect.authority":                                          "On a redirect, overwrite the Authority/Host portion of the URL with this value.",
+	"istio.networking.v1alpha3.HTTPRedirect.redirectCode":                                       "On a redirect, Specifies the HTTP status code to use in the redirect response. The default response code is MOVED_PERMANENTLY (301).",
+	"istio.networking.v1alpha3.HTTPRedirect.uri":                                                "On a redirect, overwrite the Path portion of the URL with this value. Note that the entire path will be replaced, irrespective of the request URI being matched as an exact path or prefix.",
+	"istio.networking.v1alpha3.HTTPRetry":                                                       "Describes the retry policy to use when a HTTP request fails. For example, the following rule sets the maximum number of retries to 3 when calling ratings:v1 service, with a 2s timeout per retry attempt.",
+	"istio.networking.v1alpha3.HTTPRetry.attempts":                                              "Number of retries for a given request. The interval between retries will be determined automatically (25ms+). Actual number of retries attempted depends on the httpReqTimeout.",
+	"istio.networking.v1alpha3.HTTPRetry.perTryTimeout":                                         "Timeout per retry attempt for a given request. format: 1h/1m/1s/1ms. MUST BE >=1ms.",
+	"istio.networking.v1alpha3.HTTPRetry.retryOn":                                               "Specifies the conditions under which retry takes place. One or more policies can be specified using a ‘,’ delimited list. See the [retry policies](https://www.envoyproxy.io/docs/envoy/latest/configuration/http/http_filters/router_filter#x-envoy-retry-on) and [gRPC retry policies](https://www.envoyproxy.io/docs/envoy/latest/configuration/http/http_filters/router_filter#x-envoy-retry-grpc-on) for more details.",
+	"istio.networking.v1alpha3.HTTPRewrite":                                                     "HTTPRewrite can be used to rewrite specific parts of a HTTP request before forwarding the request to the destination. Rewrite primitive can be used only with HTTPRouteDestination. The following example demonstrates how to rewrite the URL prefix for api call (/ratings) to ratings service before making the actual API call.",
+	"istio.networking.v1alpha3.HTTPRewrite.authority":                                           "rewrite the Authority/Host header with this value.",
+	"istio.networking.v1alpha3.HTTPRewrite.uri":                                                 "rewrite the path (or the prefix) portion of the URI with this value. If the original URI was matched based on prefix, the value provided in this field will replace the corresponding matched prefix.",
+	"istio.networking.v1alpha3.HTTPRoute":                                                       "Describes match conditions and actions for routing HTTP/1.1, HTTP2, and gRPC traffic. See VirtualService for usage examples.",
+	"istio.networking.v1alpha3.HTTPRoute.match":                                                 "Match conditions to be satisfied for the rule to be activated. All conditions inside a single match block have AND semantics, while the list of match blocks have OR semantics. The rule is matched if any one of the match blocks succeed.",
+	"istio.networking.v1alpha3.HTTPRoute.mirrorPercent":                                         "Percentage of the traffic to be mirrored by the `mirror` field. If this field is absent, all the traffic (100%) will be mirrored. Max value is 100.",
+	"istio.networking.v1alpha3.HTTPRoute.name":                                                  "The name assigned to the route for debugging purposes. The route's name will be concatenated with the match's name and will be logged in the access logs for requests matching this route/match.",
+	"istio.networking.v1alpha3.HTTPRoute.route":                                                 "A http rule can either redirect or forward (default) traffic. The forwarding target can be one of several versions of a service (see glossary in beginning of document). Weights associated with the service version determine the proportion of traffic it receives.",
+	"istio.networking.v1alpha3.HTTPRoute.timeout":                                               "Timeout for HTTP requests.",
+	"istio.networking.v1alpha3.HTTPRoute.websocketUpgrade":                                      "Deprecated. Websocket upgrades are done automatically starting from Istio 1.0. $hide_from_docs",
+	"istio.networking.v1alpha3.HTTPRouteDestination":                                            "Each routing rule is associated with one or more service versions (see glossary in beginning of document). Weights associated with the version determine the proportion of traffic it receives. For example, the following rule will route 25% of traffic for the \"reviews\" service to instances with the \"v2\" tag and the remaining traffic (i.e., 75%) to \"v1\".",
+	"istio.networking.v1alpha3.HTTPRouteDestination.appendRequestHeaders":                       "Use of `append_request_headers` is deprecated. Use the `headers` field instead.",
+	"istio.networking.v1alpha3.HTTPRouteDestination.appendResponseHeaders":                      "Use of `append_response_headers` is deprecated. Use the `headers` field instead.",
+	"istio.networking.v1alpha3.HTTPRouteDestination.removeRequestHeaders":                       "Use of `remove_request_headers` is deprecated. Use the `headers` field instead.",
+	"istio.networking.v1alpha3.HTTPRouteDestination.removeResponseHeaders":                      "Use of `remove_response_header` is deprecated. Use the `headers` field instead.",
+	"istio.networking.v1alpha3.HTTPRouteDestination.weight":                                     "The proportion of traffic to be forwarded to the service version. (0-100). Sum of weights across destinations SHOULD BE == 100. If there is only one destination in a rule, the weight value is assumed to be 100.",
+	"istio.networking.v1alpha3.Headers":                                                         "Message headers can be manipulated when Envoy forwards requests to, or responses from, a destination service. Header manipulation rules can be specified for a specific route destination or for all destinations. The following VirtualService adds a `test` header with the value `true` to requests that are routed to any `reviews` service destination. It also romoves the `foo` response header, but only from responses coming from the `v1` subset (version) of the `reviews` service.",
+	"istio.networking.v1alpha3.Headers.HeaderOperations":                                        "HeaderOperations Describes the header manipulations to apply",
+	"istio.networking.v1alpha3.Headers.HeaderOperations.add":                                    "Append the given values to the headers specified by keys (will create a comma-separated list of values)",
+	"istio.networking.v1alpha3.Headers.HeaderOperations.remove":                                 "Remove a the specified headers",
+	"istio.networking.v1alpha3.Headers.HeaderOperations.set":                                    "Overwrite the headers specified by key with the given values",
+	"istio.networking.v1alpha3.IstioEgressListener":                                             "`IstioEgressListener` specifies the properties of an outbound traffic listener on the sidecar proxy attached to a workload instance.",
+	"istio.networking.v1alpha3.IstioEgressListener.bind":                                        "The IP or the Unix domain socket to which the listener should be bound to. Port MUST be specified if bind is not empty. Format: `x.x.x.x` or `unix:///path/to/uds` or `unix://@foobar` (Linux abstract namespace). If omitted, Istio will automatically configure the defaults based on imported services, the workload instances to which this configuration is applied to and the captureMode. If captureMode is `NONE`, bind will default to 127.0.0.1.",
+	"istio.networking.v1alpha3.IstioEgressListener.hosts":                                       "One or more service hosts exposed by the listener in `namespace/dnsName` format. Services in the specified namespace matching `dnsName` will be exposed. The corresponding service can be a service in the service registry (e.g., a Kubernetes or cloud foundry service) or a service specified using a `ServiceEntry` or `VirtualService` configuration. Any associated `DestinationRule` in the same namespace will also be used.",
+	"istio.networking.v1alpha3.IstioIngressListener":                                            "`IstioIngressListener` specifies the properties of an inbound traffic listener on the sidecar proxy attached to a workload instance.",
+	"istio.networking.v1alpha3.IstioIngressListener.bind":                                       "The IP to which the listener should be bound. Must be in the format `x.x.x.x`. Unix domain socket addresses are not allowed in the bind field for ingress listeners. If omitted, Istio will automatically configure the defaults based on imported services and the workload instances to which this configuration is applied to.",
+	"istio.networking.v1alpha3.IstioIngressListener.defaultEndpoint":                            "The loopback IP endpoint or Unix domain socket to which traffic should be forwarded to. This configuration can be used to redirect traffic arriving at the bind `IP:Port` on the sidecar to a `localhost:port` or Unix domain socket where the application workload instance is listening for connections. Format should be `127.0.0.1:PORT` or `unix:///path/to/socket`",
+	"istio.networking.v1alpha3.L4MatchAttributes":                                               "L4 connection match attributes. Note that L4 connection matching support is incomplete.",
+	"istio.networking.v1alpha3.L4MatchAttributes.destinationSubnets":                            "IPv4 or IPv6 ip addresses of destination with optional subnet. E.g., a.b.c.d/xx form or just a.b.c.d.",
+	"istio.networking.v1alpha3.L4MatchAttributes.gateways":                                      "Names of gateways where the rule should be applied to. Gateway names at the top of the VirtualService (if any) are overridden. The gateway match is independent of sourceLabels.",
+	"istio.networking.v1alpha3.L4MatchAttributes.port":                                          "Specifies the port on the host that is being addressed. Many services only expose a single port or label ports with the protocols they support, in these cases it is not required to explicitly select the port.",
+	"istio.networking.v1alpha3.L4MatchAttributes.sourceLabels":                                  "One or more labels that constrain the applicability of a rule to workloads with the given labels. If the VirtualService has a list of gateways specified at the top, it should include the reserved gateway `mesh` in order for this field to be applicable.",
+	"istio.networking.v1alpha3.L4MatchAttributes.sourceSubnet":                                  "IPv4 or IPv6 ip address of source with optional subnet. E.g., a.b.c.d/xx form or just a.b.c.d $hide_from_docs",
+	"istio.networking.v1alpha3.LoadBalancerSettings":                                            "Load balancing policies to apply for a specific destination. See Envoy's load balancing [documentation](https://www.envoyproxy.io/docs/envoy/latest/intro/arch_overview/upstream/load_balancing/load_balancing) for more details.",
+	"istio.networking.v1alpha3.LoadBalancerSettings.ConsistentHashLB":                           "Consistent Hash-based load balancing can be used to provide soft session affinity based on HTTP headers, cookies or other properties. This load balancing policy is applicable only for HTTP connections. The affinity to a particular destination host will be lost when one or more hosts are added/removed from the destination service.",
+	"istio.networking.v1alpha3.LoadBalancerSettings.ConsistentHashLB.HTTPCookie":                "Describes a HTTP cookie that will be used as the hash key for the Consistent Hash load balancer. If the cookie is not present, it will be generated.",
+	"istio.networking.v1alpha3.LoadBalancerSettings.ConsistentHashLB.HTTPCookie.name":           "Name of the cookie.",
+	"istio.networking.v1alpha3.LoadBalancerSettings.ConsistentHashLB.HTTPCookie.path":           "Path to set for the cookie.",
+	"istio.networking.v1alpha3.LoadBalancerSettings.ConsistentHashLB.HTTPCookie.ttl":            "Lifetime of the cookie.",
+	"istio.networking.v1alpha3.LoadBalancerSettings.ConsistentHashLB.httpHeaderName":            "Hash based on a specific HTTP header.",
+	"istio.networking.v1alpha3.LoadBalancerSettings.ConsistentHashLB.minimumRingSize":           "The minimum number of virtual nodes to use for the hash ring. Defaults to 1024. Larger ring sizes result in more granular load distributions. If the number of hosts in the load balancing pool is larger than the ring size, each host will be assigned a single virtual node.",
+	"istio.networking.v1alpha3.LoadBalancerSettings.ConsistentHashLB.useSourceIp":               "Hash based on the source IP address.",
+	"istio.networking.v1alpha3.LoadBalancerSettings.SimpleLB":                                   "Standard load balancing algorithms that require no tuning.",
+	"istio.networking.v1alpha3.LocalityLoadBalancerSetting":                                     "Locality-weighted load balancing allows administrators to control the distribution of traffic to endpoints based on the localities of where the traffic originates and where it will terminate. These localities are specified using arbitrary labels that designate a hierarchy of localities in {region}/{zone}/{sub-zone} form. For additional detail refer to [Locality Weight](https://www.envoyproxy.io/docs/envoy/latest/intro/arch_overview/upstream/load_balancing/locality_weight) The following example shows how to setup locality weights mesh-wide.",
+	"istio.networking.v1alpha3.LocalityLoadBalancerSetting.Distribute":                          "Describes how traffic originating in the 'from' zone or sub-zone is distributed over a set of 'to' zones. Syntax for specifying a zone is {region}/{zone}/{sub-zone} and terminal wildcards are allowed on any segment of the specification. Examples: * - matches all localities us-west/* - all zones and sub-zones within the us-west region us-west/zone-1/* - all sub-zones within us-west/zone-1",
+	"istio.networking.v1alpha3.LocalityLoadBalancerSetting.Distribute.from":                     "Originating locality, '/' separated, e.g. 'region/zone/sub_zone'.",
+	"istio.networking.v1alpha3.LocalityLoadBalancerSetting.Distribute.to":                       "Map of upstream localities to traffic distribution weights. The sum of all weights should be == 100. Any locality not assigned a weight will receive no traffic.",
+	"istio.networking.v1alpha3.LocalityLoadBalancerSetting.Failover":                            "Specify the traffic failover policy across regions. Since zone and sub-zone failover is supported by default this only needs to be specified for regions when the operator needs to constrain traffic failover so that the default behavior of failing over to any endpoint globally does not apply. This is useful when failing over traffic across regions would not improve service health or may need to be restricted for other reasons like regulatory controls.",
+	"istio.networking.v1alpha3.LocalityLoadBalancerSetting.Failover.from":                       "Originating region.",
+	"istio.networking.v1alpha3.LocalityLoadBalancerSetting.Failover.to":                         "Destination region the traffic will fail over to when endpoints in the 'from' region becomes unhealthy.",
+	"istio.networking.v1alpha3.LocalityLoadBalancerSetting.distribute":                          "Optional: only one of distribute or failover can be set. Explicitly specify loadbalancing weight across different zones and geographical locations. Refer to [Locality weighted load balancing](https://www.envoyproxy.io/docs/envoy/latest/intro/arch_overview/upstream/load_balancing/locality_weight) If empty, the locality weight is set according to the endpoints number within it.",
+	"istio.networking.v1alpha3.LocalityLoadBalancerSetting.failover":                            "Optional: only failover or distribute can be set. Explicitly specify the region traffic will land on when endpoints in local region becomes unhealthy. Should be used together with OutlierDetection to detect unhealthy endpoints. Note: if no OutlierDetection specified, this will not take effect.",
+	"istio.networking.v1alpha3.OutboundTrafficPolicy":                                           "`OutboundTrafficPolicy` sets the default behavior of the sidecar for handling outbound traffic from the application. If your application uses one or more external services that are not known apriori, setting the policy to `ALLOW_ANY` will cause the sidecars to route any unknown traffic originating from the application to its requested destination. Users are strongly encouraged to use `ServiceEntry` configurations to explicitly declare any external dependencies, instead of using `ALLOW_ANY`, so that traffic to these services can be monitored.",
+	"istio.networking.v1alpha3.OutlierDetection":                                                "A Circuit breaker implementation that tracks the status of each individual host in the upstream service. Applicable to both HTTP and TCP services. For HTTP services, hosts that continually return 5xx errors for API calls are ejected from the pool for a pre-defined period of time. For TCP services, connection timeouts or connection failures to a given host counts as an error when measuring the consecutive errors metric. See Envoy's [outlier detection](https://www.envoyproxy.io/docs/envoy/latest/intro/arch_overview/upstream/outlier) for more details.",
+	"istio.networking.v1alpha3.OutlierDetection.baseEjectionTime":                               "Minimum ejection duration. A host will remain ejected for a period equal to the product of minimum ejection duration and the number of times the host has been ejected. This technique allows the system to automatically increase the ejection period for unhealthy upstream servers. format: 1h/1m/1s/1ms. MUST BE >=1ms. Default is 30s.",
+	"istio.networking.v1alpha3.OutlierDetection.consecutiveErrors":                              "Number of errors before a host is ejected from the connection pool. Defaults to 5. When the upstream host is accessed over HTTP, a 502, 503, or 504 return code qualifies as an error. When the upstream host is accessed over an opaque TCP connection, connect timeouts and connection error/failure events qualify as an error.",
+	"istio.networking.v1alpha3.OutlierDetection.interval":                                       "Time interval between ejection sweep analysis. format: 1h/1m/1s/1ms. MUST BE >=1ms. Default is 10s.",
+	"istio.networking.v1alpha3.OutlierDetection.maxEjectionPercent":                             "Maximum % of hosts in the load balancing pool for the upstream service that can be ejected. Defaults to 10%.",
+	"istio.networking.v1alpha3.OutlierDetection.minHealthPercent":                               "Outlier detection will be enabled as long as the associated load balancing pool has at least min_health_percent hosts in healthy mode. When the percentage of healthy hosts in the load balancing pool drops below this threshold, outlier detection will be disabled and the proxy will load balance across all hosts in the pool (healthy and unhealthy). The threshold can be disabled by setting it to 0%. The default is 0% as it's not typically applicable in k8s environments with few pods per service.",
+	"istio.networking.v1alpha3.Percent":                                                         "Percent specifies a percentage in the range of [0.0, 100.0].",
+	"istio.networking.v1alpha3.Port":                                                            "Port describes the properties of a specific port of a service.",
+	"istio.networking.v1alpha3.Port.name":                                                       "Label assigned to the port.",
+	"istio.networking.v1alpha3.Port.number":                                                     "A valid non-negative integer port number.",
+	"istio.networking.v1alpha3.Port.protocol":                                                   "The protocol exposed on the port. MUST BE one of HTTP|HTTPS|GRPC|HTTP2|MONGO|TCP|TLS. TLS implies the connection will be routed based on the SNI header to the destination without terminating the TLS connection.",
+	"istio.networking.v1alpha3.PortSelector":                                                    "PortSelector specifies the number of a port to be used for matching or selection for final routing.",
+	"istio.networking.v1alpha3.PortSelector.number":                                             "Valid port number",
+	"istio.networking.v1alpha3.RouteDestination":                                                "L4 routing rule weighted destination.",
+	"istio.networking.v1alpha3.RouteDestination.weight":                                         "The proportion of traffic to be forwarded to the service version. If there is only one destination in a rule, all traffic will be routed to it irrespective of the weight.",
+	"istio.networking.v1alpha3.Server":                                                          "`Server` describes the properties of the proxy on a given load balancer port. For example,",
+	"istio.networking.v1alpha3.Server.TLSOptions.TLSProtocol":                                   "TLS protocol versions.",
+	"istio.networking.v1alpha3.Server.TLSOptions.TLSmode":                                       "TLS modes enforced by the proxy",
+	"istio.networking.v1alpha3.Server.TLSOptions.caCertificates":                                "REQUIRED if mode is `MUTUAL`. The path to a file containing certificate authority certificates to use in verifying a presented client side certificate.",
+	"istio.networking.v1alpha3.Server.TLSOptions.cipherSuites":                                  "Optional: If specified, only support the specified cipher list. Otherwise default to the default cipher list supported by Envoy.",
+	"istio.networking.v1alpha3.Server.TLSOptions.credentialName":                                "The credentialName stands for a unique identifier that can be used to identify the serverCertificate and the privateKey. The credentialName appended with suffix \"-cacert\" is used to identify the CaCertificates associated with this server. Gateway workloads capable of fetching credentials from a remote credential store such as Kubernetes secrets, will be configured to retrieve the serverCertificate and the privateKey using credentialName, instead of using the file system paths specified above. If using mutual TLS, gateway workload instances will retrieve the CaCertificates using credentialName-cacert. The semantics of the name are platform dependent. In Kubernetes, the default Istio supplied credential server expects the credentialName to match the name of the Kubernetes secret that holds the server certificate, the private key, and the CA certificate (if using mutual TLS). Set the `ISTIO_META_USER_SDS` metadata variable in the gateway's proxy to enable the dynamic credential fetching feature.",
+	"istio.networking.v1alpha3.Server.TLSOptions.httpsRedirect":                                 "If set to true, the load balancer will send a 301 redirect for all http connections, asking the clients to use HTTPS.",
+	"istio.networking.v1alpha3.Server.TLSOptions.privateKey":                                    "REQUIRED if mode is `SIMPLE` or `MUTUAL`. The path to the file holding the server's private key.",
+	"istio.networking.v1alpha3.Server.TLSOptions.serverCertificate":                             "REQUIRED if mode is `SIMPLE` or `MUTUAL`. The path to the file holding the server-side TLS certificate to use.",
+	"istio.networking.v1alpha3.Server.TLSOptions.subjectAltNames":                               "A list of alternate names to verify the subject identity in the certificate presented by the client.",
+	"istio.networking.v1alpha3.Server.TLSOptions.verifyCertificateHash":                         "An optional list of hex-encoded SHA-256 hashes of the authorized client certificates. Both simple and colon separated formats are acceptable. Note: When both verify_certificate_hash and verify_certificate_spki are specified, a hash matching either value will result in the certificate being accepted.",
+	"istio.networking.v1alpha3.Server.TLSOptions.verifyCertificateSpki":                         "An optional list of base64-encoded SHA-256 hashes of the SKPIs of authorized client certificates. Note: When both verify_certificate_hash and verify_certificate_spki are specified, a hash matching either value will result in the certificate being accepted.",
+	"istio.networking.v1alpha3.Server.bind":                                                     "The ip or the Unix domain socket to which the listener should be bound to. Format: `x.x.x.x` or `unix:///path/to/uds` or `unix://@foobar` (Linux abstract namespace). When using Unix domain sockets, the port number should be 0.",
+	"istio.networking.v1alpha3.Server.defaultEndpoint":                                          "The loopback IP endpoint or Unix domain socket to which traffic should be forwarded to by default. Format should be `127.0.0.1:PORT` or `unix:///path/to/socket` or `unix://@foobar` (Linux abstract namespace).",
+	"istio.networking.v1alpha3.Server.hosts":                                                    "One or more hosts exposed by this gateway. While typically applicable to HTTP services, it can also be used for TCP services using TLS with SNI. A host is specified as a `dnsName` with an optional `namespace/` prefix. The `dnsName` should be specified using FQDN format, optionally including a wildcard character in the left-most component (e.g., `prod/*.example.com`). Set the `dnsName` to `*` to select all `VirtualService` hosts from the specified namespace (e.g.,`prod/*`).",
+	"istio.networking.v1alpha3.ServiceEntry":                                                    "ServiceEntry enables adding additional entries into Istio's internal service registry.",
+	"istio.networking.v1alpha3.ServiceEntry.Endpoint":                                           "Endpoint defines a network address (IP or hostname) associated with the mesh service.",
+	"istio.networking.v1alpha3.ServiceEntry.Endpoint.address":                                   "Address associated with the network endpoint without the port. Domain names can be used if and only if the resolution is set to DNS, and must be fully-qualified without wildcards. Use the form unix:///absolute/path/to/socket for Unix domain socket endpoints.",
+	"istio.networking.v1alpha3.ServiceEntry.Endpoint.labels":                                    "One or more labels associated with the endpoint.",
+	"istio.networking.v1alpha3.ServiceEntry.Endpoint.locality":                                  "The locality associated with the endpoint. A locality corresponds to a failure domain (e.g., country/region/zone). Arbitrary failure domain hierarchies can be represented by separating each encapsulating failure domain by /. For example, the locality of an an endpoint in US, in US-East-1 region, within availability zone az-1, in data center rack r11 can be represented as us/us-east-1/az-1/r11. Istio will configure the sidecar to route to endpoints within the same locality as the sidecar. If none of the endpoints in the locality are available, endpoints parent locality (but within the same network ID) will be chosen. For example, if there are two endpoints in same network (networkID \"n1\"), say e1 with locality us/us-east-1/az-1/r11 and e2 with locality us/us-east-1/az-2/r12, a sidecar from us/us-east-1/az-1/r11 locality will prefer e1 from the same locality over e2 from a different locality. Endpoint e2 could be the IP associated with a gateway (that bridges networks n1 and n2), or the IP associated with a standard service endpoint.",
+	"istio.networking.v1alpha3.ServiceEntry.Endpoint.network":                                   "Network enables Istio to group endpoints resident in the same L3 domain/network. All endpoints in the same network are assumed to be directly reachable from one another. When endpoints in different networks cannot reach each other directly, an Istio Gateway can be used to establish connectivity (usually using the AUTO_PASSTHROUGH mode in a Gateway Server). This is an advanced configuration used typically for spanning an Istio mesh over multiple clusters.",
+	"istio.networking.v1alpha3.ServiceEntry.Endpoint.ports":                                     "Set of ports associated with the endpoint. The ports must be associated with a port name that was declared as part of the service. Do not use for `unix://` addresses.",
+	"istio.networking.v1alpha3.ServiceEntry.Endpoint.weight":                                    "The load balancing weight associated with the endpoint. Endpoints with higher weights will receive proportionally higher traffic.",
+	"istio.networking.v1alpha3.ServiceEntry.Location":                                           "Location specifies whether the service is part of Istio mesh or outside the mesh. Location determines the behavior of several features, such as service-to-service mTLS authentication, policy enforcement, etc. When communicating with services outside the mesh, Istio's mTLS authentication is disabled, and policy enforcement is performed on the client-side as opposed to server-side.",
+	"istio.networking.v1alpha3.ServiceEntry.Resolution":                                         "Resolution determines how the proxy will resolve the IP addresses of the network endpoints associated with the service, so that it can route to one of them. The resolution mode specified here has no impact on how the application resolves the IP address associated with the service. The application may still have to use DNS to resolve the service to an IP so that the outbound traffic can be captured by the Proxy. Alternatively, for HTTP services, the application could directly communicate with the proxy (e.g., by setting HTTP_PROXY) to talk to these services.",
+	"istio.networking.v1alpha3.ServiceEntry.addresses":                                          "The virtual IP addresses associated with the service. Could be CIDR prefix. For HTTP traffic, generated route configurations will include http route domains for both the `addresses` and `hosts` field values and the destination will be identified based on the HTTP Host/Authority header. If one or more IP addresses are specified, the incoming traffic will be identified as belonging to this service if the destination IP matches the IP/CIDRs specified in the addresses field. If the Addresses field is empty, traffic will be identified solely based on the destination port. In such scenarios, the port on which the service is being accessed must not be shared by any other service in the mesh. In other words, the sidecar will behave as a simple TCP proxy, forwarding incoming traffic on a specified port to the specified destination endpoint IP/host. Unix domain socket addresses are not supported in this field.",
+	"istio.networking.v1alpha3.ServiceEntry.endpoints":                                          "One or more endpoints associated with the service.",
+	"istio.networking.v1alpha3.ServiceEntry.exportTo":                                           "A list of namespaces to which this service is exported. Exporting a service allows it to be used by sidecars, gateways and virtual services defined in other namespaces. This feature provides a mechanism for service owners and mesh administrators to control the visibility of services across namespace boundaries.",
+	"istio.networking.v1alpha3.ServiceEntry.hosts":                                              "The hosts associated with the ServiceEntry. Could be a DNS name with wildcard prefix.",
+	"istio.networking.v1alpha3.ServiceEntry.ports":                                              "The ports associated with the external service. If the Endpoints are Unix domain socket addresses, there must be exactly one port.",
+	"istio.networking.v1alpha3.ServiceEntry.subjectAltNames":                                    "The list of subject alternate names allowed for workload instances that implement this service. This information is used to enforce [secure-naming](https://istio.io/docs/concepts/security/#secure-naming). If specified, the proxy will verify that the server certificate's subject alternate name matches one of the specified values.",
+	"istio.networking.v1alpha3.Sidecar":                                                         "`Sidecar` describes the configuration of the sidecar proxy that mediates inbound and outbound communication of the workload instance to which it is attached.",
+	"istio.networking.v1alpha3.Sidecar.egress":                                                  "Egress specifies the configuration of the sidecar for processing outbound traffic from the attached workload instance to other services in the mesh.",
+	"istio.networking.v1alpha3.Sidecar.ingress":                                                 "Ingress specifies the configuration of the sidecar for processing inbound traffic to the attached workload instance. If omitted, Istio will automatically configure the sidecar based on the information about the workload obtained from the orchestration platform (e.g., exposed ports, services, etc.). If specified, inbound ports are configured if and only if the workload instance is associated with a service.",
+	"istio.networking.v1alpha3.StringMatch":                                                     "Describes how to match a given string in HTTP headers. Match is case-sensitive.",
+	"istio.networking.v1alpha3.StringMatch.exact":                                               "exact string match",
+	"istio.networking.v1alpha3.StringMatch.prefix":                                              "prefix-based match",
+	"istio.networking.v1alpha3.StringMatch.regex":                                               "ECMAscript style regex-based match",
+	"istio.networking.v1alpha3.Subset":                                                          "A subset of endpoints of a service. Subsets can be used for scenarios like A/B testing, or routing to a specific version of a service. Refer to [VirtualService](https://istio.io/docs/reference/config/networking/virtual-service/#VirtualService) documentation for examples of using subsets in these scenarios. In addition, traffic policies defined at the service-level can be overridden at a subset-level. The following rule uses a round robin load balancing policy for all traffic going to a subset named testversion that is composed of endpoints (e.g., pods) with labels (version:v3).",
+	"istio.networking.v1alpha3.Subset.labels":                                                   "Labels apply a filter over the endpoints of a service in the service registry. See route rules for examples of usage.",
+	"istio.networking.v1alpha3.Subset.name":                                                     "Name of the subset. The service name and the subset name can be used for traffic splitting in a route rule.",
+	"istio.networking.v1alpha3.TCPRoute":                                                        "Describes match conditions and actions for routing TCP traffic. The following routing rule forwards traffic arriving at port 27017 for mongo.prod.svc.cluster.local to another Mongo server on port 5555.",
+	"istio.networking.v1alpha3.TCPRoute.match":                                                  "Match conditions to be satisfied for the rule to be activated. All conditions inside a single match block have AND semantics, while the list of match blocks have OR semantics. The rule is matched if any one of the match blocks succeed.",
+	"istio.networking.v1alpha3.TCPRoute.route":                                                  "The destination to which the connection should be forwarded to.",
+	"istio.networking.v1alpha3.TLSMatchAttributes":                                              "TLS connection match attributes.",
+	"istio.networking.v1alpha3.TLSMatchAttributes.destinationSubnets":                           "IPv4 or IPv6 ip addresses of destination with optional subnet. E.g., a.b.c.d/xx form or just a.b.c.d.",
+	"istio.networking.v1alpha3.TLSMatchAttributes.gateways":                                     "Names of gateways where the rule should be applied to. Gateway names at the top of the VirtualService (if any) are overridden. The gateway match is independent of sourceLabels.",
+	"istio.networking.v1alpha3.TLSMatchAttributes.port":                                         "Specifies the port on the host that is being addressed. Many services only expose a single port or label ports with the protocols they support, in these cases it is not required to explicitly select the port.",
+	"istio.networking.v1alpha3.TLSMatchAttributes.sniHosts":                                     "SNI (server name indicator) to match on. Wildcard prefixes can be used in the SNI value, e.g., *.com will match foo.example.com as well as example.com. An SNI value must be a subset (i.e., fall within the domain) of the corresponding virtual serivce's hosts.",
+	"istio.networking.v1alpha3.TLSMatchAttributes.sourceLabels":                                 "One or more labels that constrain the applicability of a rule to workloads with the given labels. If the VirtualService has a list of gateways specified at the top, it should include the reserved gateway `mesh` in order for this field to be applicable.",
+	"istio.networking.v1alpha3.TLSMatchAttributes.sourceSubnet":                                 "IPv4 or IPv6 ip address of source with optional subnet. E.g., a.b.c.d/xx form or just a.b.c.d $hide_from_docs",
+	"istio.networking.v1alpha3.TLSRoute":                                                        "Describes match conditions and actions for routing unterminated TLS traffic (TLS/HTTPS) The following routing rule forwards unterminated TLS traffic arriving at port 443 of gateway called \"mygateway\" to internal services in the mesh based on the SNI value.",
+	"istio.networking.v1alpha3.TLSRoute.match":                                                  "Match conditions to be satisfied for the rule to be activated. All conditions inside a single match block have AND semantics, while the list of match blocks have OR semantics. The rule is matched if any one of the match blocks succeed.",
+	"istio.networking.v1alpha3.TLSRoute.route":                                                  "The destination to which the connection should be forwarded to.",
+	"istio.networking.v1alpha3.TLSSettings":                                                     "SSL/TLS related settings for upstream connections. See Envoy's [TLS context](https://www.envoyproxy.io/docs/envoy/latest/api-v2/api/v2/auth/cert.proto.html) for more details. These settings are common to both HTTP and TCP upstreams.",
+	"istio.networking.v1alpha3.TLSSettings.TLSmode":                                             "TLS connection mode",
+	"istio.networking.v1alpha3.TLSSettings.caCertificates":                                      "OPTIONAL: The path to the file containing certificate authority certificates to use in verifying a presented server certificate. If omitted, the proxy will not verify the server's certificate. Should be empty if mode is `ISTIO_MUTUAL`.",
+	"istio.networking.v1alpha3.TLSSettings.clientCertificate":                                   "REQUIRED if mode is `MUTUAL`. The path to the file holding the client-side TLS certificate to use. Should be empty if mode is `ISTIO_MUTUAL`.",
+	"istio.networking.v1alpha3.TLSSettings.privateKey":                                          "REQUIRED if mode is `MUTUAL`. The path to the file holding the client's private key. Should be empty if mode is `ISTIO_MUTUAL`.",
+	"istio.networking.v1alpha3.TLSSettings.sni":                                                 "SNI string to present to the server during TLS handshake.",
+	"istio.networking.v1alpha3.TLSSettings.subjectAltNames":                                     "A list of alternate names to verify the subject identity in the certificate. If specified, the proxy will verify that the server certificate's subject alt name matches one of the specified values. If specified, this list overrides the value of subject_alt_names from the ServiceEntry.",
+	"istio.networking.v1alpha3.TrafficPolicy":                                                   "Traffic policies to apply for a specific destination, across all destination ports. See DestinationRule for examples.",
+	"istio.networking.v1alpha3.TrafficPolicy.PortTrafficPolicy":                                 "Traffic policies that apply to specific ports of the service",
+	"istio.networking.v1alpha3.TrafficPolicy.portLevelSettings":                                 "Traffic policies specific to individual ports. Note that port level settings will override the destination-level settings. Traffic settings specified at the destination-level will not be inherited when overridden by port-level settings, i.e. default values will be applied to fields omitted in port-level traffic policies.",
+	"istio.networking.v1alpha3.VirtualService":                                                  "Configuration affecting traffic routing.",
+	"istio.networking.v1alpha3.VirtualService.exportTo":                                         "A list of namespaces to which this virtual service is exported. Exporting a virtual service allows it to be used by sidecars and gateways defined in other namespaces. This feature provides a mechanism for service owners and mesh administrators to control the visibility of virtual services across namespace boundaries.",
+	"istio.networking.v1alpha3.VirtualService.gateways":                                         "The names of gateways and sidecars that should apply these routes. A single VirtualService is used for sidecars inside the mesh as well as for one or more gateways. The selection condition imposed by this field can be overridden using the source field in the match conditions of protocol-specific routes. The reserved word `mesh` is used to imply all the sidecars in the mesh. When this field is omitted, the default gateway (`mesh`) will be used, which would apply the rule to all sidecars in the mesh. If a list of gateway names is provided, the rules will apply only to the gateways. To apply the rules to both gateways and sidecars, specify `mesh` as one of the gateway names.",
+	"istio.networking.v1alpha3.VirtualService.hosts":                                            "The destination hosts to which traffic is being sent. Could be a DNS name with wildcard prefix or an IP address. Depending on the platform, short-names can also be used instead of a FQDN (i.e. has no dots in the name). In such a scenario, the FQDN of the host would be derived based on the underlying platform.",
+	"istio.networking.v1alpha3.VirtualService.http":                                             "An ordered list of route rules for HTTP traffic. HTTP routes will be applied to platform service ports named 'http-*'/'http2-*'/'grpc-*', gateway ports with protocol HTTP/HTTP2/GRPC/ TLS-terminated-HTTPS and service entry ports using HTTP/HTTP2/GRPC protocols. The first rule matching an incoming request is used.",
+	"istio.networking.v1alpha3.VirtualService.tcp":                                              "An ordered list of route rules for opaque TCP traffic. TCP routes will be applied to any port that is not a HTTP or TLS port. The first rule matching an incoming request is used.",
+	"istio.networking.v1alpha3.VirtualService.tls":                                              "An ordered list of route rule for non-terminated TLS & HTTPS traffic. Routing is typically performed using the SNI value presented by the ClientHello message. TLS routes will be applied to platform service ports named 'https-*', 'tls-*', unterminated gateway ports using HTTPS/TLS protocols (i.e. with \"passthrough\" TLS mode) and service entry ports using HTTPS/TLS protocols. The first rule matching an incoming request is used. NOTE: Traffic 'https-*' or 'tls-*' ports without associated virtual service will be treated as opaque TCP traffic.",
+	"istio.networking.v1alpha3.WorkloadSelector":                                                "`WorkloadSelector` specifies the criteria used to determine if the `Gateway`, `Sidecar`, or `EnvoyFilter` configuration can be applied to a proxy. The matching criteria includes the metadata associated with a proxy, workload instance info such as labels attached to the pod/VM, or any other info that the proxy provides to Istio during the initial handshake. If multiple conditions are specified, all conditions need to match in order for the workload instance to be selected. Currently, only label based selection mechanism is supported.",
+	"istio.networking.v1alpha3.WorkloadSelector.labels":                                         "One or more labels that indicate a specific set of pods/VMs on which this `Sidecar` configuration should be applied. The scope of label search is restricted to the configuration namespace in which the the resource is present.",
+	"istio.policy.v1beta1.Action":                                                               "Action describes which [Handler][istio.policy.v1beta1.Handler] to invoke and what data to pass to it for processing.",
+	"istio.policy.v1beta1.Action.handler":                                                       "Fully qualified name of the handler to invoke. Must match the `name` of a [Handler][istio.policy.v1beta1.Handler.name].",
+	"istio.policy.v1beta1.Action.instances":                                                     "Each value must match the fully qualified name of the [Instance][istio.policy.v1beta1.Instance.name]s. Referenced instances are evaluated by resolving the attributes/literals for all the fields. The constructed objects are then passed to the `handler` referenced within this action.",
+	"istio.policy.v1beta1.Action.name":                                                          "A handle to refer to the results of the action.",
+	"istio.policy.v1beta1.AttributeManifest":                                                    "AttributeManifest describes a set of Attributes produced by some component of an Istio deployment.",
+	"istio.policy.v1beta1.AttributeManifest.AttributeInfo":                                      "AttributeInfo describes the schema of an Istio `Attribute`.",
+	"istio.policy.v1beta1.AttributeManifest.AttributeInfo.description":                          "A human-readable description of the attribute's purpose.",
+	"istio.policy.v1beta1.AttributeManifest.attributes":                                         "The set of attributes this Istio component will be responsible for producing at runtime. We map from attribute name to the attribute's specification. The name of an attribute, which is how attributes are referred to in aspect configuration, must conform to: Name = IDENT { SEPARATOR IDENT };",
+	"istio.policy.v1beta1.AttributeManifest.name":                                               "Name of the component producing these attributes. This can be the proxy (with the canonical name `istio-proxy`) or the name of an `attributes` kind adapter in Mixer.",
+	"istio.policy.v1beta1.AttributeManifest.revision":                                           "The revision of this document. Assigned by server.",
+	"istio.policy.v1beta1.Authentication":                                                       "Authentication allows the operator to specify the authentication of connections to out-of-process infrastructure backend.",
+	"istio.policy.v1beta1.Connection":                                                           "Connection allows the operator to specify the endpoint for out-of-process infrastructure backend. Connection is part of the handler custom resource and is specified alongside adapter specific configuration.",
+	"istio.policy.v1beta1.Connection.address":                                                   "The address of the backend.",
+	"istio.policy.v1beta1.Connection.timeout":                                                   "Timeout for remote calls to the backend.",
+	"istio.policy.v1beta1.FractionalPercent":                                                    "A fractional percentage is used in cases in which for performance reasons performing floating point to integer conversions during randomness calculations is undesirable. The message includes both a numerator and denominator that together determine the final fractional value.",
+	"istio.policy.v1beta1.FractionalPercent.DenominatorType":                                    "Fraction percentages support several fixed denominator values.",
+	"istio.policy.v1beta1.FractionalPercent.numerator":                                          "Specifies the numerator. Defaults to 0.",
+	"istio.policy.v1beta1.Handler":                                                              "Handler allows the operator to configure a specific adapter implementation. Each adapter implementation defines its own `params` proto.",
+	"istio.policy.v1beta1.Handler.adapter":                                                      "The name of a specific adapter implementation. For referencing compiled-in adapters, use the `compiled_adapter` field instead.",
+	"istio.policy.v1beta1.Handler.compiledAdapter":                                              "The name of the compiled in adapter this handler instantiates. For referencing non compiled-in adapters, use the `adapter` field instead.",
+	"istio.policy.v1beta1.Handler.name":                                                         "Must be unique in the entire Mixer configuration. Used by [Actions][istio.policy.v1beta1.Action.handler] to refer to this handler.",
+	"istio.policy.v1beta1.Handler.params":                                                       "Depends on adapter implementation. Struct representation of a proto defined by the adapter implementation; this varies depending on the value of field `adapter`.",
+	"istio.policy.v1beta1.Instance":                                                             "An Instance tells Mixer how to create instances for particular template.",
+	"istio.policy.v1beta1.Instance.attributeBindings":                                           "Defines attribute bindings to map the output of attribute-producing adapters back into the attribute space. The variable `output` refers to the output template instance produced by the adapter. The following example derives `source.namespace` from `source.uid` in the context of Kubernetes: ```yaml params: # Pass the required attribute data to the adapter source_uid: source.uid | \"\" attribute_bindings: # Fill the new attributes from the adapter produced output source.namespace: output.source_namespace ```",
+	"istio.policy.v1beta1.Instance.compiledTemplate":                                            "The name of the compiled in template this instance creates instances for. For referencing non compiled-in templates, use the `template` field instead.",
+	"istio.policy.v1beta1.Instance.name":                                                        "The name of this instance",
+	"istio.policy.v1beta1.Instance.params":                                                      "Depends on referenced template. Struct representation of a proto defined by the template; this varies depending on the value of field `template`.",
+	"istio.policy.v1beta1.Instance.template":                                                    "The name of the template this instance creates instances for. For referencing compiled-in templates, use the `compiled_template` field instead.",
+	"istio.policy.v1beta1.Mutual":                                                               "Mutual let operator specify TLS configuration for Mixer as client if mutual TLS is used to secure connection to adapter backend.",
+	"istio.policy.v1beta1.Mutual.caCertificates":                                                "The path to the file holding additional CA certificates that are needed to verify the presented adapter certificates. By default Mixer should already include Istio CA certificates and system certificates in cert pool.",
+	"istio.policy.v1beta1.Mutual.clientCertificate":                                             "The path to the file holding client certificate for mutual TLS. If omitted, the default Mixer certificates will be used.",
+	"istio.policy.v1beta1.Mutual.privateKey":                                                    "The path to the file holding the private key for mutual TLS. If omitted, the default Mixer private key will be used.",
+	"istio.policy.v1beta1.Mutual.serverName":                                                    "Used to configure mixer mutual TLS client to supply server name for SNI. It is not used to verify the hostname of the peer certificate, since Istio verifies whitelisted SAN fields in mutual TLS.",
+	"istio.policy.v1beta1.OAuth":                                                                "OAuth let operator specify config to fetch access token via oauth when using TLS for connection to the backend.",
+	"istio.policy.v1beta1.OAuth.clientId":                                                       "OAuth client id for mixer.",
+	"istio.policy.v1beta1.OAuth.clientSecret":                                                   "The path to the file holding the client secret for oauth.",
+	"istio.policy.v1beta1.OAuth.endpointParams":                                                 "Additional parameters for requests to the token endpoint.",
+	"istio.policy.v1beta1.OAuth.scopes":                                                         "List of requested permissions.",
+	"istio.policy.v1beta1.OAuth.tokenUrl":                                                       "The Resource server's token endpoint URL.",
+	"istio.policy.v1beta1.RandomSampling":                                                       "RandomSampling will filter based on the comparison of a randomly-generated value against the threshold provided.",
+	"istio.policy.v1beta1.RandomSampling.attributeExpression":                                   "Specifies an attribute expression to use to override the numerator in the `percent_sampled` field. If this value is set, but no value is found OR if that value is not a numeric value, then the derived sampling rate will be 0 (meaning no `Action`s are executed for a `Rule`).",
+	"istio.policy.v1beta1.RandomSampling.useIndependentRandomness":                              "By default sampling will be based on the value of the request header `x-request-id`. This behavior will cause consistent sampling across `Rule`s and for the full trace of a request through a mesh (across hosts). If that value is not present and/or `use_independent_randomness` is set to true, the sampling will be done based on the value of attribute specified in `attribute_epxression`. If that attribute does not exist, the system will behave as if the sampling rate was 0 (meaning no `Action`s are executed for a `Rule`).",
+	"istio.policy.v1beta1.RateLimitSampling":                                                    "RateLimitSampling provides the ability to limit the number of Rule action executions that occur over a period of time.",
+	"istio.policy.v1beta1.RateLimitSampling.maxUnsampledEntries":                                "Number of entries to allow during the `sampling_duration` before sampling is enforced.",
+	"istio.policy.v1beta1.RateLimitSampling.samplingDuration":                                   "Window in which to enforce the sampling rate.",
+	"istio.policy.v1beta1.RateLimitSampling.samplingRate":                                       "The rate at which to sample entries once the unsampled limit has been reached. Sampling will be enforced as 1 per every `sampling_rate` entries allowed.",
+	"istio.policy.v1beta1.Rule":                                                                 "A Rule is a selector and a set of intentions to be executed when the selector is `true`",
+	"istio.policy.v1beta1.Rule.HeaderOperationTemplate":                                         "A template for an HTTP header manipulation. Values in the template are expressions that may reference action outputs by name. For example, if an action `x` produces an output with a field `f`, then the header value expressions may use attribute `x.output.f` to reference the field value: ```yaml request_header_operations: - name: x-istio-header values: - x.output.f ```",
+	"istio.policy.v1beta1.Rule.HeaderOperationTemplate.Operation":                               "Header operation type.",
+	"istio.policy.v1beta1.Rule.HeaderOperationTemplate.name":                                    "Header name literal value.",
+	"istio.policy.v1beta1.Rule.HeaderOperationTemplate.values":                                  "Header value expressions.",
+	"istio.policy.v1beta1.Rule.actions":                                                         "The actions that will be executed when match evaluates to `true`.",
+	"istio.policy.v1beta1.Rule.match":                                                           "Match is an attribute based predicate. When Mixer receives a request it evaluates the match expression and executes all the associated `actions` if the match evaluates to true.",
+	"istio.policy.v1beta1.Rule.requestHeaderOperations":                                         "Templatized operations on the request headers using values produced by the rule actions. Require the check action result to be OK.",
+	"istio.policy.v1beta1.Rule.responseHeaderOperations":                                        "Templatized operations on the response headers using values produced by the rule actions. Require the check action result to be OK.",
+	"istio.policy.v1beta1.Sampling":                                                             "Sampling provides configuration of sampling strategies for Rule actions. Multiple sampling strategies are supported. When multiple strategies are configured, a request must be selected by all configured sampling strategies.",
+	"istio.policy.v1beta1.Tls":                                                                  "Tls let operator specify client authentication setting when TLS is used for connection to the backend.",
+	"istio.policy.v1beta1.Tls.AuthHeader":                                                       "AuthHeader specifies how to pass access token with authorization header.",
+	"istio.policy.v1beta1.Tls.caCertificates":                                                   "The path to the file holding additional CA certificates to well known public certs.",
+	"istio.policy.v1beta1.Tls.customHeader":                                                     "Customized header key to hold access token, e.g. x-api-key. Token will be passed as what it is.",
+	"istio.policy.v1beta1.Tls.serverName":                                                       "Used to configure mixer TLS client to verify the hostname on the returned certificates. It is also included in the client's handshake to support SNI.",
+	"istio.policy.v1beta1.Tls.tokenPath":                                                        "The path to the file holding the auth token (password, jwt token, api key, etc).",
+	"istio.policy.v1beta1.ValueType":                                                            "ValueType describes the types that values in the Istio system can take. These are used to describe the type of Attributes at run time, describe the type of the result of evaluating an expression, and to describe the runtime type of fields of other descriptors.",
+	"istio.rbac.v1alpha1.AccessRule":                                                            "AccessRule defines a permission to access a list of services.",
+	"istio.rbac.v1alpha1.AccessRule.Constraint":                                                 "Definition of a custom constraint. The supported keys are listed in the \"constraint and properties\" page.",
+	"istio.rbac.v1alpha1.AccessRule.Constraint.key":                                             "Key of the constraint.",
+	"istio.rbac.v1alpha1.AccessRule.Constraint.values":                                          "List of valid values for the constraint. Exact match, prefix match, and suffix match are supported. For example, the value \"v1alpha2\" matches \"v1alpha2\" (exact match), or \"v1\\*\" (prefix match), or \"\\*alpha2\" (suffix match).",
+	"istio.rbac.v1alpha1.AccessRule.constraints":                                                "Optional. Extra constraints in the ServiceRole specification.",
+	"istio.rbac.v1alpha1.AccessRule.hosts":                                                      "Optional. A list of HTTP hosts. This is matched against the HOST header in a HTTP request. Exact match, prefix match and suffix match are supported. For example, the host \"test.abc.com\" matches \"test.abc.com\" (exact match), or \"\\*.abc.com\" (prefix match), or \"test.abc.\\*\" (suffix match). If not specified, it matches to any host. This field should not be set for TCP services. The policy will be ignored.",
+	"istio.rbac.v1alpha1.AccessRule.methods":                                                    "Optional. A list of HTTP methods (e.g., \"GET\", \"POST\"). If not specified or specified as \"\\*\", it matches to any methods. This field should not be set for TCP services. The policy will be ignored. For gRPC services, only `POST` is allowed; other methods will result in denying services.",
+	"istio.rbac.v1alpha1.AccessRule.notHosts":                                                   "Optional. A list of HTTP hosts that must not be matched.",
+	"istio.rbac.v1alpha1.AccessRule.notMethods":                                                 "Optional. A list of HTTP methods that must not be matched. Note: It's an error to set methods and not_methods at the same time.",
+	"istio.rbac.v1alpha1.AccessRule.notPaths":                                                   "Optional. A list of HTTP paths or gRPC methods that must not be matched.",
+	"istio.rbac.v1alpha1.AccessRule.notPorts":                                                   "Optional. A list of port numbers that must not be matched. Note: It's an error to set ports and not_ports at the same time.",
+	"istio.rbac.v1alpha1.AccessRule.paths":                                                      "Optional. A list of HTTP paths or gRPC methods. gRPC methods must be presented as fully-qualified name in the form of \"/packageName.serviceName/methodName\" and are case sensitive. Exact match, prefix match, and suffix match are supported. For example, the path \"/books/review\" matches \"/books/review\" (exact match), or \"/books/\\*\" (prefix match), or \"\\*/review\" (suffix match). If not specified, it matches to any path. This field should not be set for TCP services. The policy will be ignored.",
+	"istio.rbac.v1alpha1.AccessRule.ports":                                                      "Optional. A list of port numbers of the request. If not specified, it matches to any port number. Note: It's an error to set ports and not_ports at the same time.",
+	"istio.rbac.v1alpha1.AccessRule.services":                                                   "A list of service names. Exact match, prefix match, and suffix match are supported for service names. For example, the service name \"bookstore.mtv.cluster.local\" matches \"bookstore.mtv.cluster.local\" (exact match), or \"bookstore\\*\" (prefix match), or \"\\*.mtv.cluster.local\" (suffix match). If set to [\"\\*\"], it refers to all services in the namespace.",
+	"istio.rbac.v1alpha1.EnforcementMode":                                                       "RBAC ServiceRoleBinding enforcement mode, used to verify new ServiceRoleBinding configs work as expected before rolling to production. RBAC engine only logs results from configs that are in permissive mode, and discards result before returning to the user.",
+	"istio.rbac.v1alpha1.RbacConfig":                                                            "RbacConfig implements the ClusterRbacConfig Custom Resource Definition for controlling Istio RBAC behavior. The ClusterRbacConfig Custom Resource is a singleton where only one ClusterRbacConfig should be created globally in the mesh and the namespace should be the same to other Istio components, which usually is `istio-system`.",
+	"istio.rbac.v1alpha1.RbacConfig.Target":                                                     "Target defines a list of services or namespaces.",
+	"istio.rbac.v1alpha1.RbacConfig.Target.namespaces":                                          "A list of namespaces.",
+	"istio.rbac.v1alpha1.RbacConfig.Target.services":                                            "A list of services.",
+	"istio.rbac.v1alpha1.RoleRef":                                                               "RoleRef refers to a role object.",
+	"istio.rbac.v1alpha1.RoleRef.kind":                                                          "The type of the role being referenced. Currently, \"ServiceRole\" is the only supported value for \"kind\".",
+	"istio.rbac.v1alpha1.RoleRef.name":                                                          "The name of the ServiceRole object being referenced. The ServiceRole object must be in the same namespace as the ServiceRoleBinding object.",
+	"istio.rbac.v1alpha1.ServiceRole":                                                           "ServiceRole specification contains a list of access rules (permissions).",
+	"istio.rbac.v1alpha1.ServiceRole.rules":                                                     "The set of access rules (permissions) that the role has.",
+	"istio.rbac.v1alpha1.ServiceRoleBinding":                                                    "ServiceRoleBinding assigns a ServiceRole to a list of subjects.",
+	"istio.rbac.v1alpha1.ServiceRoleBinding.actions":                                            "Inline role definition. An inline role is a role that is defined inside an authorization policy, instead of explicitly defined in a ServiceRole object. Inline roles can be used for the role definitions that are not intended to be reused in other bindings, while explicit roles are reusable. Both inline roles (defined in \"actions\" field) and explicit roles (defined in ServiceRole) are supported. Users should use only one of them in a single binding. For example, the following \"product-frontend\" AuthorizationPolicy allows \"frontend\" service to view \"product\" service on \"/info\" path. ```yaml apiVersion: \"rbac.istio.io/v1alpha1\" kind: AuthorizationPolicy metadata: name: product-frontend namespace: ns1 spec: selector: labels: app: product allow: - subjects: - names: [\"cluster.local/ns/default/sa/frontend\"] actions: - paths: [\"/info\"] methods: [\"GET\"] The set of access rules (permissions) that the role has.",
+	"istio.rbac.v1alpha1.ServiceRoleBinding.role":                                               "A `role` inside a ServiceRoleBinding refers to the ServiceRole that this ServiceRoleBinding binds to. A ServiceRoleBinding can bind to a ServiceRole in the same namespace or the root namespace. A ServiceRole in the root namespace represents a mesh global ServiceRole. The value of `role` is the name of the ServiceRole, and it can start with or without a forward slash (\"/\"). When a `role` starts with \"/\", e.g. \"/service-viewer\", it means that this ServiceRoleBinding refers to the ServiceRole in the configurable Istio root namespace. When a `role` starts without \"/\", this ServiceRoleBinding refers to the ServiceRole in the same namespace as the AuthorizationPolicy's, which contains said ServiceRoleBinding.",
+	"istio.rbac.v1alpha1.ServiceRoleBinding.subjects":                                           "List of subjects that are assigned the ServiceRole object.",
+	"istio.rbac.v1alpha1.Subject":                                                               "Subject defines an identity. The identity is either a user or identified by a set of `properties`. The supported keys in `properties` are listed in \"constraint and properties\" page.",
+	"istio.rbac.v1alpha1.Subject.group":                                                         "Optional. The group that the subject belongs to. Deprecated. Use groups and not_groups instead.",
+	"istio.rbac.v1alpha1.Subject.groups":                                                        "Optional. A list of groups that the subject represents. This is matched to the `request.auth.claims[groups]` attribute. If not specified, it applies to any groups.",
+	"istio.rbac.v1alpha1.Subject.ips":                                                           "Optional. A list of IP address or CIDR ranges that the subject represents. E.g. 192.168.100.2 or 10.1.0.0/16. If not specified, it applies to any IP addresses.",
+	"istio.rbac.v1alpha1.Subject.names":                                                         "Optional. A list of subject names. This is matched to the `source.principal` attribute. If one of subject names is \"\\*\", it matches to a subject with any name. Prefix and suffix matches are supported.",
+	"istio.rbac.v1alpha1.Subject.namespaces":                                                    "Optional. A list of namespaces that the subject represents. This is matched to the `source.namespace` attribute. If not specified, it applies to any namespaces.",
+	"istio.rbac.v1alpha1.Subject.notGroups":                                                     "Optional. A list of groups that must not be matched.",
+	"istio.rbac.v1alpha1.Subject.notIps":                                                        "Optional. A list of IP addresses or CIDR ranges that must not be matched.",
+	"istio.rbac.v1alpha1.Subject.notNames":                                                      "Optional. A list of subject names that must not be matched.",
+	"istio.rbac.v1alpha1.Subject.notNamespaces":                                                 "Optional. A list of namespaces that must not be matched.",
+	"istio.rbac.v1alpha1.Subject.properties":                                                    "Optional. The set of properties that identify the subject.",
+	"istio.rbac.v1alpha1.Subject.user":                                                          "Optional. The user name/ID that the subject represents.",
+	"istio.security.v1beta1.AuthorizationPolicy":                                                "AuthorizationPolicy enables access control on workloads.",
+	"istio.security.v1beta1.AuthorizationPolicy.rules":                                          "Optional. A list of rules to specify the allowed access to the workload.",
+	"istio.security.v1beta1.Condition":                                                          "Condition specifies additional required attributes.",
+	"istio.security.v1beta1.Condition.key":                                                      "The name of an Istio attribute. See the [full list of supported attributes](https://istio.io/docs/reference/config/).",
+	"istio.security.v1beta1.Condition.values":                                                   "The allowed values for the attribute.",
+	"istio.security.v1beta1.JWT":                                                                "JSON Web Token (JWT) token format for authentication as defined by [RFC 7519](https://tools.ietf.org/html/rfc7519). See [OAuth 2.0](https://tools.ietf.org/html/rfc6749) and [OIDC 1.0](http://openid.net/connect) for how this is used in the whole authentication flow.",
+	"istio.security.v1beta1.JWT.audiences":                                                      "The list of JWT [audiences](https://tools.ietf.org/html/rfc7519#section-4.1.3). that are allowed to access. A JWT containing any of these audiences will be accepted.",
+	"istio.security.v1beta1.JWT.fromHeaders":                                                    "List of header locations from which JWT is expected. For example, below is the location spec if JWT is expected to be found in `x-goog-iap-jwt-assertion` header, and have \"Bearer \" prefix: ``` fromHeaders: - name: x-goog-iap-jwt-assertion prefix: \"Bearer \" ```",
+	"istio.security.v1beta1.JWT.fromParams":                                                     "List of query parameters from which JWT is expected. For example, if JWT is provided via query parameter `my_token` (e.g /path?my_token=<JWT>), the config is: ``` fromParams: - \"my_token\" ```",
+	"istio.security.v1beta1.JWT.issuer":                                                         "Identifies the issuer that issued the JWT. See [issuer](https://tools.ietf.org/html/rfc7519#section-4.1.1) A JWT with different `iss` claim will be rejected.",
+	"istio.security.v1beta1.JWT.jwks":                                                           "JSON Web Key Set of public keys to validate signature of the JWT. See https://auth0.com/docs/jwks.",
+	"istio.security.v1beta1.JWT.jwksUri":                                                        "URL of the provider's public key set to validate signature of the JWT. See [OpenID Discovery](https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata).",
+	"istio.security.v1beta1.JWTHeader":                                                          "This message specifies a header location to extract JWT token.",
+	"istio.security.v1beta1.JWTHeader.name":                                                     "The HTTP header name.",
+	"istio.security.v1beta1.JWTHeader.prefix":                                                   "The prefix that should be stripped before decoding the token. For example, for \"Authorization: Bearer <token>\", prefix=\"Bearer \" with a space at the end. If the header doesn't have this exact prefix, it is considerred invalid.",
+	"istio.security.v1beta1.Operation":                                                          "Operation specifies the operations of a request.",
+	"istio.security.v1beta1.Operation.hosts":                                                    "Optional. A list of hosts, which matches to the \"request.host\" attribute.",
+	"istio.security.v1beta1.Operation.methods":                                                  "Optional. A list of methods, which matches to the \"request.method\" attribute. For gRPC service, this should be the fully-qualified name in the form of \"/package.service/method\"",
+	"istio.security.v1beta1.Operation.paths":                                                    "Optional. A list of paths, which matches to the \"request.url_path\" attribute.",
+	"istio.security.v1beta1.Operation.ports":                                                    "Optional. A list of ports, which matches to the \"destination.port\" attribute.",
+	"istio.security.v1beta1.RequestAuthentication":                                              "RequestAuthentication defines what request authentication methods are supported by a workload. If will reject a request if the request contains invalid authentication information, based on the configured authentication rules. A request that does not contain any authentication credentials will be accepted but will not have any authenticated identity. To restrict access to authenticated requests only, this should be accompanied by an authorization rule. Examples: - Require JWT for all request for workloads that have label `app:httpbin`",
+	"istio.security.v1beta1.RequestAuthentication.jwtRules":                                     "Define the list of JWTs that can be validated at the selected workloads' proxy. A valid token will be used to extract the authenticated identity. Each rule will be activated only when a token is presented at the location recorgnized by the rule. The token will be validated based on the JWT rule config. If validation fails, the request will be rejected. Note: if more than one token is presented (at different locations), the output principal is nondeterministic.",
+	"istio.security.v1beta1.Rule":                                                               "Rule allows access from a list of sources to perform a list of operations when the condition is matched.",
+	"istio.security.v1beta1.Rule.From":                                                          "From includes a list or sources.",
+	"istio.security.v1beta1.Rule.To":                                                            "To includes a list or operations.",
+	"istio.security.v1beta1.Rule.from":                                                          "Optional. from specifies the source of a request.",
+	"istio.security.v1beta1.Rule.to":                                                            "Optional. to specifies the operation of a request.",
+	"istio.security.v1beta1.Rule.when":                                                          "Optional. when specifies a list of additional conditions of a request.",
+	"istio.security.v1beta1.Source":                                                             "Source specifies the source identities of a request.",
+	"istio.security.v1beta1.Source.ipBlocks":                                                    "Optional. A list of IP blocks, which matches to the \"source.ip\" attribute. Single IP (e.g. \"1.2.3.4\") and CIDR (e.g. \"1.2.3.0/24\") are supported.",
+	"istio.security.v1beta1.Source.namespaces":                                                  "Optional. A list of namespaces, which matches to the \"source.namespace\" attribute.",
+	"istio.security.v1beta1.Source.principals":                                                  "Optional. A list of source peer identities (i.e. service account), which matches to the \"source.principal\" attribute.",
+	"istio.security.v1beta1.Source.requestPrincipals":                                           "Optional. A list of request identities (i.e. \"iss/sub\" claims), which matches to the \"request.auth.principal\" attribute.",
+	"istio.type.v1beta1.WorkloadSelector":                                                       "WorkloadSelector specifies the criteria used to determine if a policy can be applied to a proxy. The matching criteria includes the metadata associated with a proxy, workload instance info such as labels attached to the pod/VM, or any other info that the proxy provides to Istio during the initial handshake. If multiple conditions are specified, all conditions need to match in order for the workload instance to be selected. Currently, only label based selection mechanism is supported.",
+	"istio.type.v1beta1.WorkloadSelector.matchLabels":                                           "One or more labels that indicate a specific set of pods/VMs on which a policy should be applied. The scope of label search is restricted to the configuration namespace in which the resource is present.",
+}
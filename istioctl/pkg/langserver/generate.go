@@ -0,0 +1,20 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package langserver
+
+// Create the documentation of the fields of the collections, shown on hover
+//go:generate sh -c "go run $REPO_ROOT/galley/pkg/config/meta/schema/codegen/tools/fielddocs.main.go langserver $REPO_ROOT/galley/pkg/config/meta/metadata/metadata.yaml $(go list -m -f {{.Dir}} istio.io/api) fielddocs.gen.go"
+
+//go:generate gofmt -w fielddocs.gen.go
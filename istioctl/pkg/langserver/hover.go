@@ -0,0 +1,149 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package langserver
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+
+	"istio.io/istio/pilot/pkg/config/kube/crd"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+// hover returns the documentation of the field at the position, or nil if unknown.
+func hover(d *yamlDocument, pos Position) *Hover {
+	n := d.root.at(pos.Line)
+	for n != nil && n.index >= 0 {
+		// The items of a list are documented by the list.
+		n = n.parent
+	}
+	if n == nil || n.parent == nil {
+		return nil
+	}
+
+	s, ok := schemas.Istio.GetByType(crd.CamelCaseToKebabCase(d.kind()))
+	if !ok {
+		return nil
+	}
+	keys := n.keys()
+	var doc string
+	switch {
+	case len(keys) == 1 && keys[0] == "kind":
+		doc = fieldDocs[s.MessageName]
+	case len(keys) > 1 && keys[0] == "spec":
+		doc = fieldDoc(s.MessageName, keys[1:])
+	}
+	if doc == "" {
+		return nil
+	}
+	r := n.keyRange()
+	return &Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: "**" + n.path() + "**\n\n" + doc},
+		Range:    &r,
+	}
+}
+
+// fieldDoc returns the documentation of the field at the path of JSON keys in the message, or "" if
+// unknown. The keys of the map fields are part of the path.
+func fieldDoc(messageName string, keys []string) string {
+	t := proto.MessageType(messageName)
+	if t == nil {
+		return ""
+	}
+	t = t.Elem()
+	for i := 0; i < len(keys); i++ {
+		name, ft, ok := jsonField(t, keys[i])
+		if !ok {
+			return ""
+		}
+		if i == len(keys)-1 {
+			return fieldDocs[messageName+"."+name]
+		}
+
+		for ft.Kind() == reflect.Ptr || ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Map {
+			// The next key is a key of the map.
+			if i++; i == len(keys)-1 {
+				return fieldDocs[messageName+"."+name]
+			}
+			ft = ft.Elem()
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+		}
+		if ft.Kind() != reflect.Struct {
+			return ""
+		}
+		msg, ok := reflect.New(ft).Interface().(proto.Message)
+		if !ok {
+			return ""
+		}
+		messageName = proto.MessageName(msg)
+		t = ft
+	}
+	return ""
+}
+
+// jsonField returns the JSON name and the type of the field of the message with the JSON or proto
+// name, including the fields of the oneofs.
+func jsonField(t reflect.Type, key string) (string, reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if name, ok := matchField(f, key); ok {
+			return name, f.Type, true
+		}
+	}
+
+	// The fields of the oneofs are fields of their wrapper types.
+	wrappers, ok := reflect.New(t).Interface().(interface{ XXX_OneofWrappers() []interface{} })
+	if !ok {
+		return "", nil, false
+	}
+	for _, w := range wrappers.XXX_OneofWrappers() {
+		wt := reflect.TypeOf(w).Elem()
+		if wt.NumField() != 1 {
+			continue
+		}
+		if name, ok := matchField(wt.Field(0), key); ok {
+			return name, wt.Field(0).Type, true
+		}
+	}
+	return "", nil, false
+}
+
+// matchField returns the JSON name of the field if the key is its JSON or proto name.
+func matchField(f reflect.StructField, key string) (string, bool) {
+	tag := f.Tag.Get("protobuf")
+	if tag == "" {
+		return "", false
+	}
+	var name, jsonName string
+	for _, part := range strings.Split(tag, ",") {
+		switch {
+		case strings.HasPrefix(part, "name="):
+			name = strings.TrimPrefix(part, "name=")
+		case strings.HasPrefix(part, "json="):
+			jsonName = strings.TrimPrefix(part, "json=")
+		}
+	}
+	if jsonName == "" {
+		jsonName = name
+	}
+	return jsonName, key == name || key == jsonName
+}
@@ -0,0 +1,371 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package langserver
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"istio.io/istio/galley/pkg/config/analysis/analyzers"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
+	"istio.io/istio/galley/pkg/config/analysis/local"
+	"istio.io/istio/galley/pkg/config/meta/metadata"
+	"istio.io/pkg/log"
+)
+
+// domainSuffix is the domain of the hosts of the Kubernetes services.
+const domainSuffix = "cluster.local"
+
+// Options are the options of the language server.
+type Options struct {
+	// Workspace is the directory of the configuration, whose resources are analyzed with the open
+	// documents and proposed as completions. Defaults to the root of the workspace of the client.
+	Workspace string
+
+	// Namespace is the namespace of the resources which don't set one, and which is analyzed.
+	Namespace string
+
+	// IstioNamespace is the namespace of the Istio control plane.
+	IstioNamespace string
+
+	// DiagnosticsDelay is the delay without changes after which the documents are analyzed.
+	// Defaults to defaultDiagnosticsDelay.
+	DiagnosticsDelay time.Duration
+}
+
+// defaultDiagnosticsDelay lets the analysis wait for a pause in the typing.
+const defaultDiagnosticsDelay = 300 * time.Millisecond
+
+// Server is a Language Server Protocol server for the Istio configuration, which reports the
+// validation and analysis messages of the open documents, documents their fields on hover, and
+// completes the hosts, gateways and subsets of the workspace.
+type Server struct {
+	options Options
+	conn    *conn
+
+	// mu guards the state of the server, which the messages are handled with and which is analyzed in
+	// the background.
+	mu sync.Mutex
+	// documents are the contents of the open documents, by URI.
+	documents map[string]string
+	workspace *workspace
+	// index is the completion index of the workspace and the open documents, nil until it is needed
+	// after they change.
+	index *index
+	// timer publishes the diagnostics once the documents stop changing, and closed stops it.
+	timer  *time.Timer
+	closed bool
+
+	// analysisMu serializes the analyses, so that the diagnostics of the latest documents are
+	// published last.
+	analysisMu sync.Mutex
+}
+
+// NewServer creates a language server.
+func NewServer(options Options) *Server {
+	if options.DiagnosticsDelay == 0 {
+		options.DiagnosticsDelay = defaultDiagnosticsDelay
+	}
+	return &Server{
+		options:   options,
+		documents: make(map[string]string),
+		workspace: newWorkspace(""),
+	}
+}
+
+// Serve serves a client on the streams, until it exits or closes the input.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.conn = newConn(in, out)
+	defer s.close()
+	for {
+		m, err := s.conn.read()
+		if err == io.EOF {
+			return nil
+		}
+		if rerr, ok := err.(*responseError); ok {
+			if err := s.conn.reply(nil, nil, rerr); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if m.Method == "exit" {
+			return nil
+		}
+
+		s.mu.Lock()
+		result, err := s.handle(m)
+		s.mu.Unlock()
+		if m.ID == nil {
+			if err != nil {
+				log.Warnf("Failed to handle notification %s: %v", m.Method, err)
+			}
+			continue
+		}
+		if err := s.conn.reply(m.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(m *message) (interface{}, error) {
+	switch m.Method {
+	case "initialize":
+		var params InitializeParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		if s.options.Workspace == "" {
+			if params.RootURI != "" {
+				s.options.Workspace = uriToPath(params.RootURI)
+			} else {
+				s.options.Workspace = params.RootPath
+			}
+		}
+		s.workspace = newWorkspace(s.options.Workspace)
+		s.index = nil
+		return &InitializeResult{Capabilities: ServerCapabilities{
+			TextDocumentSync:   textDocumentSyncFull,
+			HoverProvider:      true,
+			CompletionProvider: &CompletionOptions{TriggerCharacters: []string{" ", "/"}},
+		}}, nil
+
+	case "shutdown":
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		s.documents[params.TextDocument.URI] = params.TextDocument.Text
+		s.scheduleDiagnostics()
+		return nil, nil
+
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			s.documents[params.TextDocument.URI] = params.ContentChanges[n-1].Text
+		}
+		s.scheduleDiagnostics()
+		return nil, nil
+
+	case "textDocument/didSave":
+		var params DidSaveTextDocumentParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		// The workspace keeps the saved content, which is analyzed once the document is closed.
+		if s.workspace.refresh(params.TextDocument.URI) {
+			s.scheduleDiagnostics()
+		}
+		return nil, nil
+
+	case "workspace/didChangeWatchedFiles":
+		var params DidChangeWatchedFilesParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		changed := false
+		for _, c := range params.Changes {
+			changed = s.workspace.refresh(c.URI) || changed
+		}
+		if changed {
+			s.scheduleDiagnostics()
+		}
+		return nil, nil
+
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		delete(s.documents, params.TextDocument.URI)
+		if err := s.conn.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		}); err != nil {
+			return nil, err
+		}
+		s.scheduleDiagnostics()
+		return nil, nil
+
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		d := s.documentAt(params)
+		if d == nil {
+			return nil, nil
+		}
+		if h := hover(d, params.Position); h != nil {
+			return h, nil
+		}
+		return nil, nil
+
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		list := &CompletionList{Items: []CompletionItem{}}
+		if d := s.documentAt(params); d != nil {
+			if s.index == nil {
+				s.index = newIndex(s.workspace.withDocuments(s.documents), s.options.Namespace)
+			}
+			if items := completion(d, params.Position, s.index, s.options.Namespace); items != nil {
+				list.Items = items
+			}
+		}
+		return list, nil
+	}
+
+	if m.ID != nil {
+		return nil, &responseError{Code: codeMethodNotFound, Message: "method not supported: " + m.Method}
+	}
+	// Other notifications, such as initialized or $/cancelRequest, are ignored.
+	return nil, nil
+}
+
+func unmarshalParams(m *message, params interface{}) error {
+	if err := json.Unmarshal(m.Params, params); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// documentAt returns the YAML document of the open document at the position, or nil.
+func (s *Server) documentAt(params TextDocumentPositionParams) *yamlDocument {
+	text, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return nil
+	}
+	var out *yamlDocument
+	for _, d := range splitDocuments(text) {
+		if d.startLine > params.Position.Line {
+			break
+		}
+		out = d
+	}
+	return out
+}
+
+// scheduleDiagnostics publishes the diagnostics once the documents haven't changed for the delay of
+// the options, as the analysis of the workspace is too slow to run on each keystroke.
+func (s *Server) scheduleDiagnostics() {
+	s.index = nil
+	if s.closed {
+		return
+	}
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.timer = time.AfterFunc(s.options.DiagnosticsDelay, func() {
+		if err := s.publishDiagnostics(); err != nil {
+			log.Warnf("Failed to publish the diagnostics: %v", err)
+		}
+	})
+}
+
+// close stops publishing the diagnostics, and waits for the analysis in progress.
+func (s *Server) close() {
+	s.analysisMu.Lock()
+	defer s.analysisMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+}
+
+// publishDiagnostics analyzes the workspace, and publishes the diagnostics of all the open documents,
+// as a change of a document may affect the analysis of the others.
+func (s *Server) publishDiagnostics() error {
+	s.analysisMu.Lock()
+	defer s.analysisMu.Unlock()
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	documents := make(map[string]string, len(s.documents))
+	for uri, text := range s.documents {
+		documents[uri] = text
+	}
+	files := s.workspace.withDocuments(documents)
+	s.mu.Unlock()
+
+	messages, err := s.analyze(files)
+	if err != nil {
+		log.Warnf("Failed to analyze the workspace: %v", err)
+	}
+
+	uris := make([]string, 0, len(documents))
+	for uri := range documents {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	for _, uri := range uris {
+		docs := splitDocuments(documents[uri])
+		diagnostics := validate(docs)
+		diagnostics = append(diagnostics, analysisDiagnostics(docs, messages, s.options.Namespace)...)
+		if diagnostics == nil {
+			diagnostics = []Diagnostic{}
+		}
+		if err := s.conn.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+			URI:         uri,
+			Diagnostics: diagnostics,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// analyze runs the analyzers of Galley on the files.
+func (s *Server) analyze(files map[string]string) (diag.Messages, error) {
+	sa := local.NewSourceAnalyzer(metadata.MustGet(), analyzers.AllCombined(), s.options.Namespace,
+		s.options.IstioNamespace, nil, true)
+
+	uris := make([]string, 0, len(files))
+	for uri := range files {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	readers := make([]io.Reader, 0, len(uris))
+	for _, uri := range uris {
+		readers = append(readers, strings.NewReader(files[uri]))
+	}
+	// The errors of the invalid files are reported by the validation.
+	_ = sa.AddReaderKubeSource(readers)
+
+	result, err := sa.Analyze(make(chan struct{}))
+	if err != nil {
+		return nil, err
+	}
+	return result.Messages, nil
+}
@@ -0,0 +1,489 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package langserver
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testURI = "file:///tmp/virtualservice.yaml"
+
+// testClient is a language client of a server serving on pipes.
+type testClient struct {
+	t      *testing.T
+	conn   *conn
+	nextID int
+
+	// messages are the messages sent by the server, read as they are sent so that the server never
+	// blocks writing them while the client writes.
+	messages chan []byte
+
+	// diagnostics are the last diagnostics published for each document, and published counts them.
+	diagnostics map[string][]Diagnostic
+	published   int
+}
+
+func newTestClient(t *testing.T, options Options) (*testClient, func()) {
+	t.Helper()
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	done := make(chan error)
+	go func() {
+		err := NewServer(options).Serve(serverIn, serverOut)
+		_ = serverOut.Close()
+		done <- err
+	}()
+	c := &testClient{
+		t:           t,
+		conn:        newConn(clientIn, clientOut),
+		messages:    make(chan []byte, 100),
+		diagnostics: make(map[string][]Diagnostic),
+	}
+	go func() {
+		defer close(c.messages)
+		for {
+			body, err := c.conn.readBody()
+			if err != nil {
+				return
+			}
+			c.messages <- body
+		}
+	}()
+	return c, func() {
+		c.notify("exit", nil)
+		if err := <-done; err != nil {
+			t.Errorf("server failed: %v", err)
+		}
+	}
+}
+
+func (c *testClient) notify(method string, params interface{}) {
+	c.t.Helper()
+	if err := c.conn.notify(method, params); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// call sends the request, and reads the messages until its response, recording the diagnostics
+// published in the meantime.
+func (c *testClient) call(method string, params, result interface{}) *responseError {
+	c.t.Helper()
+	c.nextID++
+	p, err := json.Marshal(params)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	id := json.RawMessage(strings.TrimSpace(string(mustMarshal(c.t, c.nextID))))
+	if err := c.conn.write(&message{JSONRPC: "2.0", ID: &id, Method: method, Params: p}); err != nil {
+		c.t.Fatal(err)
+	}
+	for {
+		body, ok := <-c.messages
+		if !ok {
+			c.t.Fatal("server closed the connection")
+		}
+		var m struct {
+			ID     *json.RawMessage `json:"id"`
+			Method string           `json:"method"`
+			Params json.RawMessage  `json:"params"`
+			Result json.RawMessage  `json:"result"`
+			Error  *responseError   `json:"error"`
+		}
+		if err := json.Unmarshal(body, &m); err != nil {
+			c.t.Fatal(err)
+		}
+		if m.Method == "textDocument/publishDiagnostics" {
+			var d PublishDiagnosticsParams
+			if err := json.Unmarshal(m.Params, &d); err != nil {
+				c.t.Fatal(err)
+			}
+			c.diagnostics[d.URI] = d.Diagnostics
+			c.published++
+			continue
+		}
+		if m.ID == nil || string(*m.ID) != string(id) {
+			c.t.Fatalf("unexpected message %s", body)
+		}
+		if m.Error != nil {
+			return m.Error
+		}
+		if result != nil {
+			if err := json.Unmarshal(m.Result, result); err != nil {
+				c.t.Fatal(err)
+			}
+		}
+		return nil
+	}
+}
+
+// awaitDiagnostics reads the messages until the diagnostics of the document are published.
+func (c *testClient) awaitDiagnostics(uri string) {
+	c.t.Helper()
+	timeout := time.After(10 * time.Second)
+	for {
+		var body []byte
+		var ok bool
+		select {
+		case body, ok = <-c.messages:
+			if !ok {
+				c.t.Fatal("server closed the connection")
+			}
+		case <-timeout:
+			c.t.Fatalf("no diagnostics published for %s", uri)
+		}
+		var m struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal(body, &m); err != nil {
+			c.t.Fatal(err)
+		}
+		if m.Method != "textDocument/publishDiagnostics" {
+			c.t.Fatalf("unexpected message %s", body)
+		}
+		var d PublishDiagnosticsParams
+		if err := json.Unmarshal(m.Params, &d); err != nil {
+			c.t.Fatal(err)
+		}
+		c.diagnostics[d.URI] = d.Diagnostics
+		c.published++
+		if d.URI == uri {
+			return
+		}
+	}
+}
+
+// sync waits for the notifications sent before to be handled.
+func (c *testClient) sync() {
+	c.t.Helper()
+	if err := c.call("shutdown", nil, nil); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func startTestServer(t *testing.T) (*testClient, func()) {
+	t.Helper()
+	workspace, err := filepath.Abs("testdata/workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, stop := newTestClient(t, Options{Namespace: "default", IstioNamespace: "istio-system", DiagnosticsDelay: time.Millisecond})
+	var result InitializeResult
+	if err := c.call("initialize", &InitializeParams{RootURI: pathToURI(workspace)}, &result); err != nil {
+		t.Fatal(err)
+	}
+	if !result.Capabilities.HoverProvider || result.Capabilities.CompletionProvider == nil {
+		t.Fatalf("got capabilities %+v, want hover and completion", result.Capabilities)
+	}
+	c.notify("initialized", struct{}{})
+
+	text, err := ioutil.ReadFile("testdata/virtualservice.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.notify("textDocument/didOpen", &DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: testURI, LanguageID: "yaml", Version: 1, Text: string(text)},
+	})
+	c.awaitDiagnostics(testURI)
+	return c, stop
+}
+
+func TestDiagnostics(t *testing.T) {
+	c, stop := startTestServer(t)
+	defer stop()
+
+	type result struct {
+		line, character int
+		code            string
+		message         string
+	}
+	var got []result
+	for _, d := range c.diagnostics[testURI] {
+		got = append(got, result{d.Range.Start.Line, d.Range.Start.Character, d.Code, d.Message})
+	}
+	sort.SliceStable(got, func(i, j int) bool { return got[i].line < got[j].line })
+
+	want := []result{
+		{3, 8, "IST0101", "Referenced gateway not found: \"missing-gateway\""},
+		{29, 4, "", `unknown field "loadBalancr"`},
+		{37, 0, "", "http, tcp or tls must be provided in virtual service"},
+		{38, 2, "", "virtual service must have at least one host"},
+		{43, 8, "IST0104", "The gateway refers to a port that is not exposed on the workload (pod selector istio=ingressgateway; port 99999)"},
+		{45, 0, "", "short names (non FQDN) are not allowed"},
+		{50, 14, "", "port number 99999 must be in the range 1..65535"},
+		{54, 6, "", `domain name "bad_host" invalid (label "bad_host" invalid)`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got diagnostics %+v, want %+v", got, want)
+	}
+
+	// Fixing the document clears its diagnostics, and closing it clears them.
+	c.notify("textDocument/didChange", &DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: testURI},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: test\n"}},
+	})
+	c.awaitDiagnostics(testURI)
+	if d := c.diagnostics[testURI]; len(d) != 0 {
+		t.Fatalf("got diagnostics %+v, want none", d)
+	}
+
+	c.notify("textDocument/didChange", &DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: testURI},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: "kind: Namespace\n  name: : test\n"}},
+	})
+	c.awaitDiagnostics(testURI)
+	if d := c.diagnostics[testURI]; len(d) != 1 || d[0].Range.Start.Line != 1 {
+		t.Fatalf("got diagnostics %+v, want a syntax error on line 1", d)
+	}
+
+	c.notify("textDocument/didClose", &DidCloseTextDocumentParams{TextDocument: TextDocumentIdentifier{URI: testURI}})
+	c.sync()
+	if d, ok := c.diagnostics[testURI]; !ok || len(d) != 0 {
+		t.Fatalf("got diagnostics %+v after close, want none", d)
+	}
+}
+
+func TestHover(t *testing.T) {
+	c, stop := startTestServer(t)
+	defer stop()
+
+	cases := []struct {
+		name     string
+		position Position
+		want     string
+	}{
+		{"kind", Position{Line: 1, Character: 2}, fieldDocs["istio.networking.v1alpha3.VirtualService"]},
+		{"field", Position{Line: 19, Character: 6}, fieldDocs["istio.networking.v1alpha3.HTTPRoute.timeout"]},
+		{"list item", Position{Line: 7, Character: 5}, fieldDocs["istio.networking.v1alpha3.VirtualService.hosts"]},
+		{"oneof field in map", Position{Line: 14, Character: 11}, fieldDocs["istio.networking.v1alpha3.StringMatch.exact"]},
+		{"metadata", Position{Line: 3, Character: 3}, ""},
+		{"unknown field", Position{Line: 29, Character: 6}, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var h *Hover
+			if err := c.call("textDocument/hover", &TextDocumentPositionParams{
+				TextDocument: TextDocumentIdentifier{URI: testURI},
+				Position:     tc.position,
+			}, &h); err != nil {
+				t.Fatal(err)
+			}
+			if tc.want == "" {
+				if h != nil {
+					t.Fatalf("got hover %+v, want none", h)
+				}
+				return
+			}
+			if h == nil || !strings.HasSuffix(h.Contents.Value, "\n\n"+tc.want) {
+				t.Fatalf("got hover %+v, want %q", h, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	c, stop := startTestServer(t)
+	defer stop()
+
+	cases := []struct {
+		name     string
+		position Position
+		want     []string
+	}{
+		{"destination host", Position{Line: 17, Character: 14},
+			[]string{"ratings.bookinfo", "ratings.bookinfo.svc.cluster.local", "reviews", "reviews.default.svc.cluster.local"}},
+		{"virtual service hosts", Position{Line: 7, Character: 4},
+			[]string{"ratings.bookinfo", "ratings.bookinfo.svc.cluster.local", "reviews", "reviews.default.svc.cluster.local"}},
+		{"subset", Position{Line: 18, Character: 16}, []string{"v1", "v2"}},
+		{"gateways", Position{Line: 9, Character: 4}, []string{"bookinfo-gateway", "invalid", "mesh"}},
+		{"destination rule host", Position{Line: 27, Character: 8},
+			[]string{"ratings.bookinfo", "ratings.bookinfo.svc.cluster.local", "reviews", "reviews.default.svc.cluster.local"}},
+		{"key", Position{Line: 17, Character: 9}, nil},
+		{"other field", Position{Line: 19, Character: 14}, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var list CompletionList
+			if err := c.call("textDocument/completion", &TextDocumentPositionParams{
+				TextDocument: TextDocumentIdentifier{URI: testURI},
+				Position:     tc.position,
+			}, &list); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, item := range list.Items {
+				got = append(got, item.Label)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got completions %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUnsupportedMethod(t *testing.T) {
+	c, stop := newTestClient(t, Options{})
+	defer stop()
+
+	err := c.call("textDocument/definition", &TextDocumentPositionParams{}, nil)
+	if err == nil || err.Code != codeMethodNotFound {
+		t.Fatalf("got error %v, want method not found", err)
+	}
+}
+
+func TestWorkspaceChanges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "langserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	c, stop := newTestClient(t, Options{Namespace: "default", IstioNamespace: "istio-system", DiagnosticsDelay: time.Millisecond})
+	defer stop()
+	if err := c.call("initialize", &InitializeParams{RootURI: pathToURI(dir)}, nil); err != nil {
+		t.Fatal(err)
+	}
+	c.notify("textDocument/didOpen", &DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: testURI, LanguageID: "yaml", Version: 1, Text: `apiVersion: networking.istio.io/v1alpha3
+kind: VirtualService
+metadata:
+  name: reviews
+  namespace: default
+spec:
+  hosts:
+  - reviews
+  gateways:
+  - new-gateway
+  http:
+  - route:
+    - destination:
+        host: reviews
+`},
+	})
+	c.awaitDiagnostics(testURI)
+
+	gatewayNotFound := func() bool {
+		for _, d := range c.diagnostics[testURI] {
+			if strings.Contains(d.Message, `"new-gateway"`) {
+				return true
+			}
+		}
+		return false
+	}
+	gateways := func() []string {
+		var list CompletionList
+		if err := c.call("textDocument/completion", &TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: testURI},
+			Position:     Position{Line: 9, Character: 4},
+		}, &list); err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, item := range list.Items {
+			out = append(out, item.Label)
+		}
+		return out
+	}
+	if !gatewayNotFound() {
+		t.Fatalf("got diagnostics %+v, want the gateway not found", c.diagnostics[testURI])
+	}
+
+	// The workspace is only read again for the files notified by the client.
+	gatewayPath := filepath.Join(dir, "gateway.yaml")
+	if err := ioutil.WriteFile(gatewayPath, []byte(`apiVersion: networking.istio.io/v1alpha3
+kind: Gateway
+metadata:
+  name: new-gateway
+  namespace: default
+spec:
+  selector:
+    istio: ingressgateway
+  servers:
+  - port:
+      number: 80
+      name: http
+      protocol: HTTP
+    hosts:
+    - "*"
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := gateways(); !reflect.DeepEqual(got, []string{"mesh"}) {
+		t.Fatalf("got gateways %v before the notification, want [mesh]", got)
+	}
+
+	c.notify("workspace/didChangeWatchedFiles", &DidChangeWatchedFilesParams{
+		Changes: []FileEvent{{URI: pathToURI(gatewayPath), Type: 1}},
+	})
+	c.awaitDiagnostics(testURI)
+	if gatewayNotFound() {
+		t.Fatalf("got diagnostics %+v, want the gateway found", c.diagnostics[testURI])
+	}
+	if got := gateways(); !reflect.DeepEqual(got, []string{"mesh", "new-gateway"}) {
+		t.Fatalf("got gateways %v, want [mesh new-gateway]", got)
+	}
+
+	if err := os.Remove(gatewayPath); err != nil {
+		t.Fatal(err)
+	}
+	c.notify("workspace/didChangeWatchedFiles", &DidChangeWatchedFilesParams{
+		Changes: []FileEvent{{URI: pathToURI(gatewayPath), Type: 3}},
+	})
+	c.awaitDiagnostics(testURI)
+	if !gatewayNotFound() {
+		t.Fatalf("got diagnostics %+v, want the deleted gateway not found", c.diagnostics[testURI])
+	}
+}
+
+func TestDiagnosticsDebounced(t *testing.T) {
+	c, stop := newTestClient(t, Options{Namespace: "default", DiagnosticsDelay: 200 * time.Millisecond})
+	defer stop()
+
+	text := "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: test\n"
+	c.notify("textDocument/didOpen", &DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: testURI, LanguageID: "yaml", Version: 1, Text: text},
+	})
+	for i := 0; i < 5; i++ {
+		text += "#\n"
+		c.notify("textDocument/didChange", &DidChangeTextDocumentParams{
+			TextDocument:   TextDocumentIdentifier{URI: testURI},
+			ContentChanges: []TextDocumentContentChangeEvent{{Text: text}},
+		})
+	}
+	c.awaitDiagnostics(testURI)
+	time.Sleep(400 * time.Millisecond)
+	c.sync()
+	if c.published != 1 {
+		t.Fatalf("got the diagnostics published %d times, want once after the changes", c.published)
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package langserver
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"istio.io/istio/pilot/pkg/model"
+)
+
+// uriToPath returns the path of a file URI.
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// pathToURI returns the file URI of a path.
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// workspace is the YAML files of the directory of the configuration, by URI. The directory is read
+// once, and the files are then reread as the client notifies their changes.
+type workspace struct {
+	dir   string
+	files map[string]string
+}
+
+// newWorkspace reads the YAML files of the directory. Hidden directories, such as .git, are skipped.
+func newWorkspace(dir string) *workspace {
+	w := &workspace{dir: dir, files: make(map[string]string)}
+	if dir == "" {
+		return w
+	}
+	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != dir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if w.contains(path) {
+			if b, err := ioutil.ReadFile(path); err == nil {
+				w.files[pathToURI(path)] = string(b)
+			}
+		}
+		return nil
+	})
+	return w
+}
+
+// contains returns true if the path is a YAML file of the workspace, outside of its hidden
+// directories.
+func (w *workspace) contains(path string) bool {
+	if w.dir == "" {
+		return false
+	}
+	if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+		return false
+	}
+	rel, err := filepath.Rel(w.dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	dirs := strings.Split(filepath.Dir(rel), string(filepath.Separator))
+	for _, d := range dirs {
+		if d != "." && strings.HasPrefix(d, ".") {
+			return false
+		}
+	}
+	return true
+}
+
+// refresh rereads the file of the URI, or forgets it if it was deleted. It returns true if the
+// file is part of the workspace.
+func (w *workspace) refresh(uri string) bool {
+	path := uriToPath(uri)
+	if !w.contains(path) {
+		return false
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		delete(w.files, uri)
+	} else {
+		w.files[uri] = string(b)
+	}
+	return true
+}
+
+// withDocuments returns the files of the workspace, replaced by the content of the open documents.
+func (w *workspace) withDocuments(open map[string]string) map[string]string {
+	files := make(map[string]string, len(w.files)+len(open))
+	for uri, text := range w.files {
+		files[uri] = text
+	}
+	for uri, text := range open {
+		files[uri] = text
+	}
+	return files
+}
+
+// index is the hosts, gateways and subsets defined in the workspace, which are proposed as completions.
+type index struct {
+	// services are the Kubernetes services, by namespace and name.
+	services map[string][]string
+	// serviceEntryHosts are the hosts of the service entries.
+	serviceEntryHosts []string
+	// gateways are the gateways, by namespace and name.
+	gateways map[string][]string
+	// subsets are the subsets of the destination rules, by FQDN of their host.
+	subsets map[string][]string
+}
+
+func newIndex(files map[string]string, defaultNamespace string) *index {
+	idx := &index{
+		services: make(map[string][]string),
+		gateways: make(map[string][]string),
+		subsets:  make(map[string][]string),
+	}
+	uris := make([]string, 0, len(files))
+	for uri := range files {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	for _, uri := range uris {
+		for _, d := range splitDocuments(files[uri]) {
+			if d.object == nil {
+				continue
+			}
+			name := d.metadata("name")
+			ns := d.metadata("namespace")
+			if ns == "" {
+				ns = defaultNamespace
+			}
+			spec, _ := d.object["spec"].(map[string]interface{})
+			switch d.kind() {
+			case "Service":
+				idx.services[ns] = append(idx.services[ns], name)
+			case "Gateway":
+				idx.gateways[ns] = append(idx.gateways[ns], name)
+			case "ServiceEntry":
+				idx.serviceEntryHosts = append(idx.serviceEntryHosts, stringList(spec["hosts"])...)
+			case "DestinationRule":
+				host, _ := spec["host"].(string)
+				if host == "" {
+					continue
+				}
+				fqdn := resolveHost(host, ns)
+				subsets, _ := spec["subsets"].([]interface{})
+				for _, s := range subsets {
+					if m, ok := s.(map[string]interface{}); ok {
+						if subset, ok := m["name"].(string); ok {
+							idx.subsets[fqdn] = append(idx.subsets[fqdn], subset)
+						}
+					}
+				}
+			}
+		}
+	}
+	return idx
+}
+
+// hosts returns the hosts of the services, as referenced from the namespace.
+func (idx *index) hosts(namespace string) []string {
+	var out []string
+	for ns, names := range idx.services {
+		for _, name := range names {
+			if ns == namespace {
+				out = append(out, name)
+			} else {
+				out = append(out, name+"."+ns)
+			}
+			out = append(out, resolveHost(name, ns))
+		}
+	}
+	out = append(out, idx.serviceEntryHosts...)
+	return unique(out)
+}
+
+// gatewayNames returns the names of the gateways, as referenced from the namespace, and the mesh.
+func (idx *index) gatewayNames(namespace string) []string {
+	out := []string{"mesh"}
+	for ns, names := range idx.gateways {
+		for _, name := range names {
+			if ns == namespace {
+				out = append(out, name)
+			} else {
+				out = append(out, ns+"/"+name)
+			}
+		}
+	}
+	return unique(out)
+}
+
+// subsetNames returns the subsets of the host, as referenced from the namespace.
+func (idx *index) subsetNames(host, namespace string) []string {
+	return unique(idx.subsets[resolveHost(host, namespace)])
+}
+
+// resolveHost returns the FQDN of the host referenced from the namespace, as Pilot resolves it.
+func resolveHost(host, namespace string) string {
+	return string(model.ResolveShortnameToFQDN(host, model.ConfigMeta{Namespace: namespace, Domain: domainSuffix}))
+}
+
+func stringList(v interface{}) []string {
+	list, _ := v.([]interface{})
+	var out []string
+	for _, e := range list {
+		if s, ok := e.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func unique(in []string) []string {
+	seen := make(map[string]bool, len(in))
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
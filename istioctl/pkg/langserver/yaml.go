@@ -0,0 +1,345 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package langserver
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// yamlDocument is a YAML document of a text document, the documents being separated by "---" lines.
+type yamlDocument struct {
+	// startLine is the zero-based line of the text document where the YAML document starts.
+	startLine int
+	text      string
+
+	// root is the outline of the block structure of the document, used to find the fields at the
+	// positions of the text document and back.
+	root *yamlNode
+
+	// object is the parsed document, nil if it is empty or invalid.
+	object   map[string]interface{}
+	parseErr error
+}
+
+func (d *yamlDocument) kind() string {
+	kind, _ := d.object["kind"].(string)
+	return kind
+}
+
+func (d *yamlDocument) metadata(field string) string {
+	meta, _ := d.object["metadata"].(map[string]interface{})
+	value, _ := meta[field].(string)
+	return value
+}
+
+// errorLine matches the line of the errors of the YAML parser.
+var errorLine = regexp.MustCompile(`line (\d+)`)
+
+// errorPosition returns the line of the text document of the parse error of the document.
+func (d *yamlDocument) errorPosition() int {
+	if m := errorLine.FindStringSubmatch(d.parseErr.Error()); m != nil {
+		if n, err := strconv.Atoi(m[1]); err == nil {
+			return d.startLine + n - 1
+		}
+	}
+	return d.startLine
+}
+
+// splitDocuments splits the text document into its YAML documents, and parses them.
+func splitDocuments(text string) []*yamlDocument {
+	lines := strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n")
+	var docs []*yamlDocument
+	start := 0
+	flush := func(end int) {
+		d := &yamlDocument{startLine: start, text: strings.Join(lines[start:end], "\n")}
+		d.root = parseOutline(lines[start:end], start)
+		if strings.TrimSpace(d.text) != "" {
+			d.parseErr = yaml.Unmarshal([]byte(d.text), &d.object)
+		}
+		docs = append(docs, d)
+	}
+	for i, l := range lines {
+		if strings.HasPrefix(l, "---") && strings.TrimSpace(strings.TrimPrefix(l, "---")) == "" {
+			flush(i)
+			start = i + 1
+		}
+	}
+	flush(len(lines))
+	return docs
+}
+
+// yamlNode is a node of the outline of a YAML document: an entry of a mapping, or an item of a
+// sequence. Flow collections and multi-line scalars are not outlined.
+type yamlNode struct {
+	// key is the key of the entries, and index is the index of the items, or -1 for the entries.
+	key   string
+	index int
+
+	// line and col are the position of the key of the entries, and of the value of the items.
+	line    int
+	col     int
+	endLine int
+
+	// value is the value of the nodes whose value is on their line, and valueCol its column.
+	value    string
+	valueCol int
+
+	children []*yamlNode
+	parent   *yamlNode
+}
+
+// path returns the path of the node from the root, such as spec.http[0].route.
+func (n *yamlNode) path() string {
+	var parts []string
+	for ; n != nil && n.parent != nil; n = n.parent {
+		if n.index >= 0 {
+			parts = append(parts, "["+strconv.Itoa(n.index)+"]")
+		} else {
+			parts = append(parts, "."+n.key)
+		}
+	}
+	var b strings.Builder
+	for i := len(parts) - 1; i >= 0; i-- {
+		b.WriteString(parts[i])
+	}
+	return strings.TrimPrefix(b.String(), ".")
+}
+
+// keys returns the keys of the path of the node from the root, without the indexes of the items.
+func (n *yamlNode) keys() []string {
+	var out []string
+	for ; n != nil && n.parent != nil; n = n.parent {
+		if n.index < 0 {
+			out = append([]string{n.key}, out...)
+		}
+	}
+	return out
+}
+
+// child returns the entry of the node with the key, or nil.
+func (n *yamlNode) child(key string) *yamlNode {
+	for _, c := range n.children {
+		if c.index < 0 && c.key == key {
+			return c
+		}
+	}
+	return nil
+}
+
+// find returns the entry at the path of keys from the node, or nil.
+func (n *yamlNode) find(keys ...string) *yamlNode {
+	for _, k := range keys {
+		if n = n.child(k); n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// findKey returns the first entry with the key under the node, depth first, or nil.
+func (n *yamlNode) findKey(key string) *yamlNode {
+	for _, c := range n.children {
+		if c.index < 0 && c.key == key {
+			return c
+		}
+		if found := c.findKey(key); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// at returns the deepest node of the line, or nil.
+func (n *yamlNode) at(line int) *yamlNode {
+	for _, c := range n.children {
+		if c.line <= line && line <= c.endLine {
+			if deeper := c.at(line); deeper != nil {
+				return deeper
+			}
+			return c
+		}
+	}
+	return nil
+}
+
+// keyRange returns the range of the key of the entries, or of the value of the items.
+func (n *yamlNode) keyRange() Range {
+	length := len(n.key)
+	if n.index >= 0 {
+		length = len(n.value)
+	}
+	return Range{
+		Start: Position{Line: n.line, Character: n.col},
+		End:   Position{Line: n.line, Character: n.col + length},
+	}
+}
+
+// valueRange returns the range of the value of the node on its line, or of its key if the value
+// isn't on the line.
+func (n *yamlNode) valueRange() Range {
+	if n.value == "" {
+		return n.keyRange()
+	}
+	return Range{
+		Start: Position{Line: n.line, Character: n.valueCol},
+		End:   Position{Line: n.line, Character: n.valueCol + len(n.value)},
+	}
+}
+
+type outlineLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+type outlineParser struct {
+	lines []outlineLine
+	i     int
+}
+
+// parseOutline returns the root of the outline of the lines, the first line being at the offset in
+// the text document.
+func parseOutline(lines []string, offset int) *yamlNode {
+	p := &outlineParser{}
+	for i, l := range lines {
+		text := strings.TrimLeft(l, " ")
+		text = strings.TrimRight(text, " \t\r")
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		p.lines = append(p.lines, outlineLine{num: offset + i, indent: len(l) - len(strings.TrimLeft(l, " ")), text: text})
+	}
+	root := &yamlNode{index: -1, line: offset, endLine: offset + len(lines)}
+	if len(p.lines) > 0 {
+		p.parseBlock(root, p.lines[0].indent)
+	}
+	return root
+}
+
+// parseBlock parses the lines of the block at the indentation into the children of the node.
+func (p *outlineParser) parseBlock(n *yamlNode, indent int) {
+	for p.i < len(p.lines) {
+		l := &p.lines[p.i]
+		if l.indent < indent {
+			return
+		}
+		if l.indent > indent {
+			// Misindented line, which the YAML parser reports.
+			p.i++
+			continue
+		}
+
+		if isItem(l.text) {
+			if len(n.children) > 0 && n.children[0].index < 0 {
+				return
+			}
+			item := &yamlNode{index: len(n.children), line: l.num, col: indent + 1, parent: n}
+			rest := strings.TrimLeft(l.text[1:], " ")
+			if rest == "" {
+				p.i++
+				if p.i < len(p.lines) && p.lines[p.i].indent > indent {
+					p.parseBlock(item, p.lines[p.i].indent)
+				}
+			} else {
+				// The content of the item is parsed as if it were on its own line.
+				l.indent += len(l.text) - len(rest)
+				l.text = rest
+				item.col = l.indent
+				if _, _, ok := splitEntry(rest); ok || isItem(rest) {
+					p.parseBlock(item, l.indent)
+				} else {
+					item.value, item.valueCol = scalarValue(rest), l.indent
+					p.i++
+				}
+			}
+			item.endLine = p.lines[p.i-1].num
+			n.children = append(n.children, item)
+			continue
+		}
+
+		key, rest, ok := splitEntry(l.text)
+		if !ok {
+			// Scalar in a block, such as a key being typed.
+			p.i++
+			continue
+		}
+		if len(n.children) > 0 && n.children[0].index >= 0 {
+			return
+		}
+		entry := &yamlNode{key: key, index: -1, line: l.num, col: indent, parent: n}
+		p.i++
+		switch {
+		case rest == "":
+			if p.i < len(p.lines) {
+				next := p.lines[p.i]
+				if next.indent > indent || (next.indent == indent && isItem(next.text)) {
+					p.parseBlock(entry, next.indent)
+				}
+			}
+		case strings.HasPrefix(rest, "|") || strings.HasPrefix(rest, ">"):
+			for p.i < len(p.lines) && p.lines[p.i].indent > indent {
+				p.i++
+			}
+		default:
+			entry.value, entry.valueCol = scalarValue(rest), l.indent+len(l.text)-len(rest)
+		}
+		entry.endLine = p.lines[p.i-1].num
+		n.children = append(n.children, entry)
+	}
+}
+
+func isItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitEntry splits the key and the value of the entries of block mappings.
+func splitEntry(text string) (string, string, bool) {
+	if strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[") {
+		return "", "", false
+	}
+	end := -1
+	if q := text[0]; q == '"' || q == '\'' {
+		if close := strings.IndexByte(text[1:], q); close >= 0 && strings.HasPrefix(text[close+2:], ":") {
+			end = close + 2
+		}
+	} else if i := strings.Index(text, ": "); i > 0 {
+		end = i
+	} else if strings.HasSuffix(text, ":") {
+		end = len(text) - 1
+	}
+	if end <= 0 {
+		return "", "", false
+	}
+	key := strings.Trim(text[:end], `"'`)
+	rest := strings.TrimLeft(text[end+1:], " ")
+	if strings.HasPrefix(rest, "#") {
+		rest = ""
+	}
+	return key, rest, true
+}
+
+// scalarValue returns the scalar without its comment.
+func scalarValue(text string) string {
+	if !strings.HasPrefix(text, `"`) && !strings.HasPrefix(text, "'") {
+		if i := strings.Index(text, " #"); i >= 0 {
+			text = strings.TrimRight(text[:i], " ")
+		}
+	}
+	return text
+}
@@ -0,0 +1,285 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preview
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	gogoproto "github.com/gogo/protobuf/proto"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+)
+
+// ChangeType is the type of change of a resource.
+type ChangeType string
+
+const (
+	// Added is a resource only in the proposed configuration.
+	Added ChangeType = "Added"
+	// Removed is a resource only in the current configuration.
+	Removed ChangeType = "Removed"
+	// Modified is a resource whose fields differ.
+	Modified ChangeType = "Modified"
+)
+
+// FieldDiff is a difference of a field of a resource. A missing value is nil.
+type FieldDiff struct {
+	// Path is the path of the field, such as virtual_hosts[reviews.default.svc.cluster.local:9080].routes[0].
+	// The elements of the lists whose elements are all named are identified by their name.
+	Path   string      `json:"path"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// ResourceDiff is a change of a listener, route or cluster.
+type ResourceDiff struct {
+	Type   string      `json:"type"`
+	Name   string      `json:"name"`
+	Change ChangeType  `json:"change"`
+	Fields []FieldDiff `json:"fields,omitempty"`
+}
+
+// ProxyDiff is the change of the configuration of a proxy.
+type ProxyDiff struct {
+	Proxy     string         `json:"proxy"`
+	Resources []ResourceDiff `json:"resources"`
+}
+
+// Diff returns the changes of the resources of the proxy from the current to the proposed
+// configuration, ordered by type and name.
+func Diff(proxy string, current, proposed *ProxyConfig) (*ProxyDiff, error) {
+	out := &ProxyDiff{Proxy: proxy, Resources: []ResourceDiff{}}
+	types := []struct {
+		name              string
+		current, proposed map[string]proto.Message
+	}{
+		{"Listener", listenersByName(current), listenersByName(proposed)},
+		{"Route", routesByName(current), routesByName(proposed)},
+		{"Cluster", clustersByName(current), clustersByName(proposed)},
+	}
+	for _, t := range types {
+		diffs, err := diffResources(t.name, t.current, t.proposed)
+		if err != nil {
+			return nil, err
+		}
+		out.Resources = append(out.Resources, diffs...)
+	}
+	return out, nil
+}
+
+func listenersByName(c *ProxyConfig) map[string]proto.Message {
+	out := make(map[string]proto.Message, len(c.Listeners))
+	for _, l := range c.Listeners {
+		out[l.Name] = l
+	}
+	return out
+}
+
+func routesByName(c *ProxyConfig) map[string]proto.Message {
+	out := make(map[string]proto.Message, len(c.Routes))
+	for _, r := range c.Routes {
+		out[r.Name] = r
+	}
+	return out
+}
+
+func clustersByName(c *ProxyConfig) map[string]proto.Message {
+	out := make(map[string]proto.Message, len(c.Clusters))
+	for _, cl := range c.Clusters {
+		out[cl.Name] = cl
+	}
+	return out
+}
+
+func diffResources(typ string, current, proposed map[string]proto.Message) ([]ResourceDiff, error) {
+	names := make([]string, 0, len(current)+len(proposed))
+	for name := range current {
+		names = append(names, name)
+	}
+	for name := range proposed {
+		if _, ok := current[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var out []ResourceDiff
+	for _, name := range names {
+		before, inCurrent := current[name]
+		after, inProposed := proposed[name]
+		switch {
+		case !inCurrent:
+			out = append(out, ResourceDiff{Type: typ, Name: name, Change: Added})
+		case !inProposed:
+			out = append(out, ResourceDiff{Type: typ, Name: name, Change: Removed})
+		default:
+			b, err := toJSONValue(before)
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert %s %s: %v", typ, name, err)
+			}
+			a, err := toJSONValue(after)
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert %s %s: %v", typ, name, err)
+			}
+			if fields := diffValues("", b, a); len(fields) > 0 {
+				out = append(out, ResourceDiff{Type: typ, Name: name, Change: Modified, Fields: fields})
+			}
+		}
+	}
+	return out, nil
+}
+
+// toJSONValue converts the message to its generic JSON representation, in which the typed
+// configurations of the filters are expanded so that their fields are compared.
+func toJSONValue(msg proto.Message) (interface{}, error) {
+	buf := &bytes.Buffer{}
+	m := &jsonpb.Marshaler{OrigName: true, AnyResolver: resolver{}}
+	if err := m.Marshal(buf, msg); err != nil {
+		return nil, err
+	}
+	var out interface{}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// resolver resolves the types of the Any messages from both the golang and the gogo registries,
+// as some of the filter configurations generated by Pilot are gogo messages.
+type resolver struct{}
+
+func (resolver) Resolve(typeURL string) (proto.Message, error) {
+	name := typeURL
+	for i := len(typeURL) - 1; i >= 0; i-- {
+		if typeURL[i] == '/' {
+			name = typeURL[i+1:]
+			break
+		}
+	}
+	mt := proto.MessageType(name)
+	if mt == nil {
+		mt = gogoproto.MessageType(name)
+	}
+	if mt == nil {
+		return nil, fmt.Errorf("unknown message type %q", name)
+	}
+	return reflect.New(mt.Elem()).Interface().(proto.Message), nil
+}
+
+// diffValues returns the differences between the generic JSON values at the path.
+func diffValues(path string, before, after interface{}) []FieldDiff {
+	switch b := before.(type) {
+	case map[string]interface{}:
+		if a, ok := after.(map[string]interface{}); ok {
+			return diffObjects(path, b, a)
+		}
+	case []interface{}:
+		if a, ok := after.([]interface{}); ok {
+			return diffLists(path, b, a)
+		}
+	}
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	return []FieldDiff{{Path: path, Before: before, After: after}}
+}
+
+func diffObjects(path string, before, after map[string]interface{}) []FieldDiff {
+	keys := make([]string, 0, len(before)+len(after))
+	for k := range before {
+		keys = append(keys, k)
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var out []FieldDiff
+	for _, k := range keys {
+		p := k
+		if path != "" {
+			p = path + "." + k
+		}
+		out = append(out, diffValues(p, before[k], after[k])...)
+	}
+	return out
+}
+
+// diffLists compares the elements of the lists by name when they are all named, so that
+// inserting a virtual host or a route doesn't show as a change of all the following ones.
+func diffLists(path string, before, after []interface{}) []FieldDiff {
+	beforeNames, namedBefore := elementNames(before)
+	afterNames, namedAfter := elementNames(after)
+	if !namedBefore || !namedAfter {
+		var out []FieldDiff
+		for i := 0; i < len(before) || i < len(after); i++ {
+			var b, a interface{}
+			if i < len(before) {
+				b = before[i]
+			}
+			if i < len(after) {
+				a = after[i]
+			}
+			out = append(out, diffValues(path+"["+strconv.Itoa(i)+"]", b, a)...)
+		}
+		return out
+	}
+
+	beforeByName := make(map[string]interface{}, len(before))
+	for i, name := range beforeNames {
+		beforeByName[name] = before[i]
+	}
+	afterByName := make(map[string]interface{}, len(after))
+	for i, name := range afterNames {
+		afterByName[name] = after[i]
+	}
+	var out []FieldDiff
+	for i, name := range beforeNames {
+		out = append(out, diffValues(path+"["+name+"]", before[i], afterByName[name])...)
+	}
+	for i, name := range afterNames {
+		if _, ok := beforeByName[name]; !ok {
+			out = append(out, diffValues(path+"["+name+"]", nil, after[i])...)
+		}
+	}
+	return out
+}
+
+// elementNames returns the names of the elements of the list, and whether they are all objects
+// with a distinct name.
+func elementNames(list []interface{}) ([]string, bool) {
+	names := make([]string, 0, len(list))
+	seen := make(map[string]bool, len(list))
+	for _, e := range list {
+		obj, ok := e.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, ok := obj["name"].(string)
+		if !ok || seen[name] {
+			return nil, false
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names, len(list) > 0
+}
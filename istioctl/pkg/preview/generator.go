@@ -0,0 +1,300 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preview
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	"github.com/envoyproxy/go-control-plane/pkg/conversion"
+	"github.com/golang/protobuf/ptypes"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"istio.io/api/annotation"
+	meshconfig "istio.io/api/mesh/v1alpha1"
+
+	"istio.io/istio/pilot/pkg/config/memory"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/core"
+	"istio.io/istio/pilot/pkg/networking/plugin"
+	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pilot/pkg/serviceregistry/aggregate"
+	"istio.io/istio/pilot/pkg/serviceregistry/external"
+	kubecontroller "istio.io/istio/pilot/pkg/serviceregistry/kube/controller"
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+var (
+	// plugins are the default plugins of Pilot.
+	plugins = []string{plugin.Authn, plugin.Authz, plugin.Health, plugin.Mixer}
+
+	// syncTimeout is how long the registry is given to process the snapshot.
+	syncTimeout = 30 * time.Second
+)
+
+// ProxyConfig is the xDS configuration generated for a proxy.
+type ProxyConfig struct {
+	Listeners []*xdsapi.Listener
+	Routes    []*xdsapi.RouteConfiguration
+	Clusters  []*xdsapi.Cluster
+}
+
+// Generator generates the configuration of the proxies of a snapshot, as Pilot would, without
+// connecting to the cluster.
+type Generator struct {
+	env          *model.Environment
+	controller   *kubecontroller.Controller
+	configGen    core.ConfigGenerator
+	domainSuffix string
+	pods         map[string]*v1.Pod
+	stop         chan struct{}
+}
+
+// NewGenerator creates a generator for the snapshot, once the service registry has processed it.
+// The generator must be closed once done.
+func NewGenerator(snapshot *Snapshot, meshConfig *meshconfig.MeshConfig, domainSuffix string) (*Generator, error) {
+	store := memory.Make(schemas.Istio)
+	for _, config := range snapshot.Configs {
+		// Short hosts are resolved with the domain, which the Kubernetes config controller sets.
+		if config.Domain == "" {
+			config.Domain = domainSuffix
+		}
+		if _, err := store.Create(config); err != nil {
+			return nil, fmt.Errorf("invalid %s %s/%s: %v", config.Type, config.Namespace, config.Name, err)
+		}
+	}
+	configStore := model.MakeIstioStore(store)
+
+	g := &Generator{
+		configGen:    core.NewConfigGenerator(plugins),
+		domainSuffix: domainSuffix,
+		pods:         make(map[string]*v1.Pod),
+		stop:         make(chan struct{}),
+	}
+	services := 0
+	for _, obj := range snapshot.Kubernetes {
+		switch o := obj.(type) {
+		case *v1.Pod:
+			g.pods[o.Name+"."+o.Namespace] = o
+		case *v1.Service:
+			services++
+		}
+	}
+
+	g.controller = kubecontroller.NewController(fake.NewSimpleClientset(snapshot.Kubernetes...), kubecontroller.Options{
+		DomainSuffix: domainSuffix,
+		XDSUpdater:   noopXDSUpdater{},
+	})
+	serviceEntries := external.NewServiceDiscovery(nil, configStore, noopXDSUpdater{})
+	registry := aggregate.NewController()
+	registry.AddRegistry(g.controller)
+	registry.AddRegistry(serviceEntries)
+	go g.controller.Run(g.stop)
+
+	// The registry processes the objects asynchronously, once its informers are synced.
+	err := wait.PollImmediate(100*time.Millisecond, syncTimeout, func() (bool, error) {
+		if !g.controller.HasSynced() {
+			return false, nil
+		}
+		svcs, err := g.controller.Services()
+		return err == nil && len(svcs) == services, nil
+	})
+	if err != nil {
+		g.Close()
+		return nil, fmt.Errorf("failed to load the services of the snapshot: %v", err)
+	}
+
+	g.env = &model.Environment{
+		ServiceDiscovery: registry,
+		IstioConfigStore: configStore,
+		Watcher:          mesh.NewFixedWatcher(meshConfig),
+		NetworksWatcher:  mesh.NewFixedNetworksWatcher(nil),
+	}
+	push := model.NewPushContext()
+	if err := push.InitContext(g.env, nil, nil); err != nil {
+		g.Close()
+		return nil, err
+	}
+	g.env.PushContext = push
+	return g, nil
+}
+
+// Close stops the service registry of the generator.
+func (g *Generator) Close() {
+	close(g.stop)
+}
+
+// Generate generates the listeners, routes and clusters of the proxy of the pod.
+func (g *Generator) Generate(podName, namespace string) (*ProxyConfig, error) {
+	pod, ok := g.pods[podName+"."+namespace]
+	if !ok {
+		return nil, fmt.Errorf("pod %s.%s not found", podName, namespace)
+	}
+	proxy, err := g.proxyForPod(pod)
+	if err != nil {
+		return nil, err
+	}
+
+	push := g.env.PushContext
+	config := &ProxyConfig{
+		Listeners: g.configGen.BuildListeners(proxy, push),
+		Clusters:  g.configGen.BuildClusters(proxy, push),
+	}
+	config.Routes = g.configGen.BuildHTTPRoutes(proxy, push, routeNames(config.Listeners))
+	return config, nil
+}
+
+// proxyForPod returns the proxy of the pod, as it would connect to Pilot.
+func (g *Generator) proxyForPod(pod *v1.Pod) (*model.Proxy, error) {
+	if pod.Status.PodIP == "" {
+		return nil, fmt.Errorf("pod %s.%s has no IP", pod.Name, pod.Namespace)
+	}
+	nodeType := model.SidecarProxy
+	if isRouter(pod) {
+		nodeType = model.Router
+	}
+	meta := &model.NodeMetadata{
+		Labels:          pod.Labels,
+		InstanceIPs:     []string{pod.Status.PodIP},
+		Namespace:       pod.Namespace,
+		ConfigNamespace: pod.Namespace,
+		ServiceAccount:  pod.Spec.ServiceAccountName,
+		NodeName:        pod.Spec.NodeName,
+		IstioVersion:    proxyVersion(pod),
+	}
+	if mode, ok := pod.Annotations[annotation.SidecarInterceptionMode.Name]; ok {
+		meta.InterceptionMode = model.TrafficInterceptionMode(mode)
+	}
+	id := fmt.Sprintf("%s~%s~%s.%s~%s.svc.%s", nodeType, pod.Status.PodIP, pod.Name, pod.Namespace,
+		pod.Namespace, g.domainSuffix)
+	proxy, err := model.ParseServiceNodeWithMetadata(id, meta)
+	if err != nil {
+		return nil, err
+	}
+	proxy.ConfigNamespace = model.GetProxyConfigNamespace(proxy)
+
+	// The pod must be known to the registry for its service instances to be found.
+	err = wait.PollImmediate(100*time.Millisecond, syncTimeout, func() (bool, error) {
+		l, err := g.controller.GetProxyWorkloadLabels(proxy)
+		return err == nil && l != nil, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pod %s.%s: %v", pod.Name, pod.Namespace, err)
+	}
+	if err := proxy.SetServiceInstances(g.env); err != nil {
+		return nil, err
+	}
+	if len(proxy.ServiceInstances) > 0 {
+		proxy.Locality = util.ConvertLocality(proxy.ServiceInstances[0].GetLocality())
+	}
+	if err := proxy.SetWorkloadLabels(g.env); err != nil {
+		return nil, err
+	}
+	proxy.SetSidecarScope(g.env.PushContext)
+	proxy.SetGatewaysForProxy(g.env.PushContext)
+	return proxy, nil
+}
+
+// isRouter returns true if the proxy of the pod runs as a gateway.
+func isRouter(pod *v1.Pod) bool {
+	for _, c := range pod.Spec.Containers {
+		if c.Name != proxyContainerName {
+			continue
+		}
+		for _, arg := range c.Args {
+			if arg == string(model.Router) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// proxyVersion returns the version of the image of the proxy of the pod, or "" if unknown.
+func proxyVersion(pod *v1.Pod) string {
+	for _, c := range pod.Spec.Containers {
+		if c.Name != proxyContainerName {
+			continue
+		}
+		if i := strings.LastIndex(c.Image, ":"); i >= 0 && !strings.Contains(c.Image[i:], "/") {
+			return c.Image[i+1:]
+		}
+	}
+	return ""
+}
+
+const proxyContainerName = "istio-proxy"
+
+// routeNames returns the names of the routes of the HTTP connection managers of the listeners,
+// which the proxy would request with RDS.
+func routeNames(listeners []*xdsapi.Listener) []string {
+	names := make(map[string]bool)
+	for _, l := range listeners {
+		for _, chain := range l.FilterChains {
+			for _, filter := range chain.Filters {
+				if name := rdsRouteName(filter); name != "" {
+					names[name] = true
+				}
+			}
+		}
+	}
+	out := make([]string, 0, len(names))
+	for name := range names {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func rdsRouteName(filter *listener.Filter) string {
+	if filter.Name != "envoy.http_connection_manager" {
+		return ""
+	}
+	manager := &hcm.HttpConnectionManager{}
+	switch c := filter.ConfigType.(type) {
+	case *listener.Filter_TypedConfig:
+		if err := ptypes.UnmarshalAny(c.TypedConfig, manager); err != nil {
+			return ""
+		}
+	case *listener.Filter_Config:
+		if err := conversion.StructToMessage(c.Config, manager); err != nil {
+			return ""
+		}
+	default:
+		return ""
+	}
+	return manager.GetRds().GetRouteConfigName()
+}
+
+// noopXDSUpdater ignores the updates of the registries, as the configuration is generated once.
+type noopXDSUpdater struct{}
+
+var _ model.XDSUpdater = noopXDSUpdater{}
+
+func (noopXDSUpdater) EDSUpdate(_, _, _ string, _ []*model.IstioEndpoint) error { return nil }
+
+func (noopXDSUpdater) ConfigUpdate(*model.PushRequest) {}
+
+func (noopXDSUpdater) ProxyUpdate(_, _ string) {}
+
+func (noopXDSUpdater) SvcUpdate(_, _, _ string, _ model.Event) {}
@@ -0,0 +1,248 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preview
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+
+	"istio.io/istio/pilot/pkg/config/kube/crd"
+	"istio.io/istio/pilot/pkg/config/memory"
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+const productpage = "productpage-v1-7d6cfb7dfd-5mc96"
+
+func readSnapshot(t *testing.T) *Snapshot {
+	t.Helper()
+	data, err := ioutil.ReadFile("testdata/snapshot.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := ParseSnapshot(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return snapshot
+}
+
+func generate(t *testing.T, snapshot *Snapshot) *ProxyConfig {
+	t.Helper()
+	meshConfig, err := snapshot.Mesh()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGenerator(snapshot, meshConfig, "cluster.local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Close()
+	config, err := g.Generate(productpage, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	snapshot := readSnapshot(t)
+	if len(snapshot.Kubernetes) != 7 || len(snapshot.Configs) != 2 {
+		t.Fatalf("got %d Kubernetes resources and %d configs, want 7 and 2", len(snapshot.Kubernetes), len(snapshot.Configs))
+	}
+	buf := &bytes.Buffer{}
+	if err := snapshot.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseSnapshot(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Kubernetes) != len(snapshot.Kubernetes) || len(parsed.Configs) != len(snapshot.Configs) {
+		t.Fatalf("got %d Kubernetes resources and %d configs after round trip, want %d and %d",
+			len(parsed.Kubernetes), len(parsed.Configs), len(snapshot.Kubernetes), len(snapshot.Configs))
+	}
+	if !reflect.DeepEqual(parsed.MeshConfig.Data, snapshot.MeshConfig.Data) {
+		t.Fatalf("got mesh configuration %v after round trip, want %v", parsed.MeshConfig.Data, snapshot.MeshConfig.Data)
+	}
+}
+
+func TestSnapshotMesh(t *testing.T) {
+	m, err := readSnapshot(t).Mesh()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults := mesh.DefaultMeshConfig()
+	if m.AccessLogFile != "/dev/stdout" || m.OutboundTrafficPolicy.Mode != meshconfig.MeshConfig_OutboundTrafficPolicy_REGISTRY_ONLY {
+		t.Errorf("got mesh configuration %v, want the one of the snapshot", m)
+	}
+	if m.ProxyListenPort != defaults.ProxyListenPort {
+		t.Errorf("got proxy listen port %d, want the default %d", m.ProxyListenPort, defaults.ProxyListenPort)
+	}
+
+	if m, err := (&Snapshot{}).Mesh(); m != nil || err != nil {
+		t.Errorf("got %v, %v for a snapshot without mesh configuration, want none", m, err)
+	}
+}
+
+func TestSnapshotFromCluster(t *testing.T) {
+	store := memory.Make(schemas.Istio)
+	client := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "istio", Namespace: "istio-system"},
+		Data:       map[string]string{MeshConfigKey: "accessLogFile: /dev/stdout"},
+	})
+	snapshot, err := SnapshotFromCluster(client, store, "istio-system", "istio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, err := snapshot.Mesh(); err != nil || m.AccessLogFile != "/dev/stdout" {
+		t.Fatalf("got mesh configuration %v, %v, want the one of the cluster", m, err)
+	}
+
+	if _, err := SnapshotFromCluster(client, store, "istio-system", "unknown"); err == nil {
+		t.Fatal("expected an error for a missing mesh configuration")
+	}
+}
+
+func TestPreview(t *testing.T) {
+	snapshot := readSnapshot(t)
+	data, err := ioutil.ReadFile("testdata/reviews-v2.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	proposed, _, err := crd.ParseInputs(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current := generate(t, snapshot)
+	if len(current.Listeners) == 0 || len(current.Routes) == 0 || len(current.Clusters) == 0 {
+		t.Fatalf("got %d listeners, %d routes and %d clusters, want some of each",
+			len(current.Listeners), len(current.Routes), len(current.Clusters))
+	}
+
+	unchanged, err := Diff(productpage+".default", current, generate(t, snapshot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unchanged.Resources) != 0 {
+		t.Fatalf("got changes %+v for the same configuration, want none", unchanged.Resources)
+	}
+
+	diff, err := Diff(productpage+".default", current, generate(t, snapshot.WithConfigs(proposed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Resources) != 1 {
+		t.Fatalf("got changes %+v, want a single route change", diff.Resources)
+	}
+	r := diff.Resources[0]
+	if r.Type != "Route" || r.Name != "9080" || r.Change != Modified {
+		t.Fatalf("got %s %s %s, want Modified Route 9080", r.Change, r.Type, r.Name)
+	}
+	found := false
+	for _, f := range r.Fields {
+		if f.Before == "outbound|9080|v1|reviews.default.svc.cluster.local" &&
+			f.After == "outbound|9080|v2|reviews.default.svc.cluster.local" {
+			found = true
+			if !strings.HasPrefix(f.Path, "virtual_hosts[reviews.default.svc.cluster.local:9080].routes[0].route.cluster") {
+				t.Errorf("got path %q of the cluster change", f.Path)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("got fields %+v, want the cluster of the route changed from v1 to v2", r.Fields)
+	}
+
+	out := &bytes.Buffer{}
+	if err := PrintText(out, []*ProxyDiff{diff}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "~ Route 9080") {
+		t.Fatalf("got output %q, want the route change", out.String())
+	}
+}
+
+func TestDiffResources(t *testing.T) {
+	current := &ProxyConfig{Clusters: []*xdsapi.Cluster{{Name: "a"}, {Name: "b"}}}
+	proposed := &ProxyConfig{Clusters: []*xdsapi.Cluster{{Name: "b", AltStatName: "b"}, {Name: "c"}}}
+	diff, err := Diff("proxy", current, proposed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ResourceDiff{
+		{Type: "Cluster", Name: "a", Change: Removed},
+		{Type: "Cluster", Name: "b", Change: Modified, Fields: []FieldDiff{{Path: "alt_stat_name", After: "b"}}},
+		{Type: "Cluster", Name: "c", Change: Added},
+	}
+	if !reflect.DeepEqual(diff.Resources, want) {
+		t.Fatalf("got %+v, want %+v", diff.Resources, want)
+	}
+}
+
+func TestDiffLists(t *testing.T) {
+	named := func(names ...string) []interface{} {
+		var out []interface{}
+		for _, n := range names {
+			out = append(out, map[string]interface{}{"name": n})
+		}
+		return out
+	}
+	cases := []struct {
+		name           string
+		before, after  interface{}
+		wantFieldPaths []string
+	}{
+		{
+			name:           "inserted named element",
+			before:         named("a", "b"),
+			after:          named("x", "a", "b"),
+			wantFieldPaths: []string{"[x]"},
+		},
+		{
+			name:           "unnamed elements",
+			before:         []interface{}{"a", "b"},
+			after:          []interface{}{"x", "a", "b"},
+			wantFieldPaths: []string{"[0]", "[1]", "[2]"},
+		},
+		{
+			name:           "duplicate names",
+			before:         named("a", "a"),
+			after:          named("a", "b"),
+			wantFieldPaths: []string{"[1].name"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var paths []string
+			for _, f := range diffValues("", c.before, c.after) {
+				paths = append(paths, f.Path)
+			}
+			if !reflect.DeepEqual(paths, c.wantFieldPaths) {
+				t.Fatalf("got paths %v, want %v", paths, c.wantFieldPaths)
+			}
+		})
+	}
+}
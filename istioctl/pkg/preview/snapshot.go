@@ -0,0 +1,245 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preview
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ghodss/yaml"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+
+	"istio.io/istio/pilot/pkg/config/kube/crd"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/config/schemas"
+	"istio.io/pkg/log"
+)
+
+// MeshConfigKey is the key of the mesh configuration in the data of its ConfigMap.
+const MeshConfigKey = "mesh"
+
+// Snapshot is the state of a cluster which the configuration of the proxies is generated from.
+type Snapshot struct {
+	// Kubernetes are the namespaces, nodes, pods, services and endpoints of the cluster.
+	Kubernetes []runtime.Object
+
+	// Configs are the Istio configuration resources.
+	Configs []model.Config
+
+	// MeshConfig is the ConfigMap of the mesh configuration of the cluster, if saved.
+	MeshConfig *v1.ConfigMap
+}
+
+// SnapshotFromCluster reads the snapshot of the cluster, with the mesh configuration of the
+// ConfigMap of the Istio namespace. The Istio configuration types which can't be listed, such as
+// those whose CRD isn't installed, are skipped.
+func SnapshotFromCluster(client kubernetes.Interface, configStore model.ConfigStore,
+	istioNamespace, meshConfigMapName string) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	core := client.CoreV1()
+	meshConfig, err := core.ConfigMaps(istioNamespace).Get(meshConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not read the mesh configuration %q from namespace %q: %v",
+			meshConfigMapName, istioNamespace, err)
+	}
+	if _, ok := meshConfig.Data[MeshConfigKey]; !ok {
+		return nil, fmt.Errorf("missing key %q in the mesh configuration %q", MeshConfigKey, meshConfigMapName)
+	}
+	snapshot.MeshConfig = meshConfig
+
+	namespaces, err := core.Namespaces().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range namespaces.Items {
+		snapshot.Kubernetes = append(snapshot.Kubernetes, &namespaces.Items[i])
+	}
+	nodes, err := core.Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range nodes.Items {
+		snapshot.Kubernetes = append(snapshot.Kubernetes, &nodes.Items[i])
+	}
+	pods, err := core.Pods(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range pods.Items {
+		snapshot.Kubernetes = append(snapshot.Kubernetes, &pods.Items[i])
+	}
+	services, err := core.Services(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range services.Items {
+		snapshot.Kubernetes = append(snapshot.Kubernetes, &services.Items[i])
+	}
+	endpoints, err := core.Endpoints(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range endpoints.Items {
+		snapshot.Kubernetes = append(snapshot.Kubernetes, &endpoints.Items[i])
+	}
+
+	for _, typ := range configStore.ConfigDescriptor().Types() {
+		configs, err := configStore.List(typ, metav1.NamespaceAll)
+		if err != nil {
+			log.Debugf("Skipping the %s configuration, which can't be listed: %v", typ, err)
+			continue
+		}
+		snapshot.Configs = append(snapshot.Configs, configs...)
+	}
+	return snapshot, nil
+}
+
+// ParseSnapshot parses a snapshot from YAML documents, as written by Snapshot.Write. The
+// Kubernetes resources other than namespaces, nodes, pods, services, endpoints and the ConfigMap
+// of the mesh configuration are ignored.
+func ParseSnapshot(data []byte) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	decoder := kubeyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 512*1024)
+	for {
+		var raw json.RawMessage
+		err := decoder.Decode(&raw)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse snapshot: %v", err)
+		}
+		if len(bytes.TrimSpace(raw)) == 0 || bytes.Equal(raw, []byte("null")) {
+			continue
+		}
+
+		var typeMeta metav1.TypeMeta
+		if err := json.Unmarshal(raw, &typeMeta); err != nil {
+			return nil, fmt.Errorf("cannot parse snapshot: %v", err)
+		}
+		if _, ok := schemas.Istio.GetByType(crd.CamelCaseToKebabCase(typeMeta.Kind)); ok {
+			configs, _, err := crd.ParseInputsWithoutValidation(string(raw))
+			if err != nil {
+				return nil, err
+			}
+			snapshot.Configs = append(snapshot.Configs, configs...)
+			continue
+		}
+		obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(raw, nil, nil)
+		if err != nil {
+			log.Debugf("Skipping unknown resource %s in snapshot: %v", typeMeta.Kind, err)
+			continue
+		}
+		switch o := obj.(type) {
+		case *v1.Namespace, *v1.Node, *v1.Pod, *v1.Service, *v1.Endpoints:
+			snapshot.Kubernetes = append(snapshot.Kubernetes, obj)
+		case *v1.ConfigMap:
+			if _, ok := o.Data[MeshConfigKey]; ok {
+				snapshot.MeshConfig = o
+				continue
+			}
+			log.Debugf("Skipping ConfigMap %s in snapshot", o.Name)
+		default:
+			log.Debugf("Skipping resource %s in snapshot", typeMeta.Kind)
+		}
+	}
+	return snapshot, nil
+}
+
+// Write writes the snapshot as YAML documents.
+func (s *Snapshot) Write(w io.Writer) error {
+	objects := s.Kubernetes
+	if s.MeshConfig != nil {
+		objects = append([]runtime.Object{s.MeshConfig}, objects...)
+	}
+	for _, obj := range objects {
+		// The objects listed from the API server have no kind.
+		obj = obj.DeepCopyObject()
+		gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+		if err != nil {
+			return err
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+		if err := writeYAML(w, obj); err != nil {
+			return err
+		}
+	}
+	for _, config := range s.Configs {
+		schema, ok := schemas.Istio.GetByType(config.Type)
+		if !ok {
+			continue
+		}
+		obj, err := crd.ConvertConfig(schema, config)
+		if err != nil {
+			return err
+		}
+		if err := writeYAML(w, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeYAML(w io.Writer, obj interface{}) error {
+	out, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s---\n", out)
+	return err
+}
+
+// WithConfigs returns a copy of the snapshot with the configuration resources added, replacing
+// the resources of the same type, namespace and name.
+func (s *Snapshot) WithConfigs(configs []model.Config) *Snapshot {
+	key := func(c model.Config) string {
+		return c.Type + "/" + c.Namespace + "/" + c.Name
+	}
+	replaced := make(map[string]bool, len(configs))
+	for _, c := range configs {
+		replaced[key(c)] = true
+	}
+	out := &Snapshot{Kubernetes: s.Kubernetes, MeshConfig: s.MeshConfig}
+	for _, c := range s.Configs {
+		if !replaced[key(c)] {
+			out.Configs = append(out.Configs, c)
+		}
+	}
+	out.Configs = append(out.Configs, configs...)
+	return out
+}
+
+// Mesh returns the mesh configuration of the snapshot, with the defaults applied, or nil if the
+// snapshot has none.
+func (s *Snapshot) Mesh() (*meshconfig.MeshConfig, error) {
+	if s.MeshConfig == nil {
+		return nil, nil
+	}
+	m, err := mesh.ApplyMeshConfigDefaults(s.MeshConfig.Data[MeshConfigKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid mesh configuration in snapshot: %v", err)
+	}
+	return m, nil
+}
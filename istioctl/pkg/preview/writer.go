@@ -0,0 +1,69 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preview
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+var changeSymbols = map[ChangeType]string{
+	Added:    "+",
+	Removed:  "-",
+	Modified: "~",
+}
+
+// PrintText prints the changes of the proxies, with a line per changed field.
+func PrintText(w io.Writer, diffs []*ProxyDiff) error {
+	for _, d := range diffs {
+		if len(d.Resources) == 0 {
+			_, _ = fmt.Fprintf(w, "%s: no changes\n", d.Proxy)
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "%s:\n", d.Proxy)
+		for _, r := range d.Resources {
+			_, _ = fmt.Fprintf(w, "  %s %s %s\n", changeSymbols[r.Change], r.Type, r.Name)
+			for _, f := range r.Fields {
+				_, _ = fmt.Fprintf(w, "      %s: %s -> %s\n", f.Path, formatValue(f.Before), formatValue(f.After))
+			}
+		}
+	}
+	return nil
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "<none>"
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(out)
+}
+
+// PrintJSON prints the changes of the proxies in JSON format.
+func PrintJSON(w io.Writer, diffs []*ProxyDiff) error {
+	if diffs == nil {
+		diffs = []*ProxyDiff{}
+	}
+	out, err := json.MarshalIndent(diffs, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
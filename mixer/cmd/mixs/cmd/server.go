@@ -82,6 +82,27 @@ func serverCmd(info map[string]template.Info, adapters []adapter.InfoFn, printf,
 	serverCmd.PersistentFlags().BoolVar(&sa.UseTemplateCRDs, "useTemplateCRDs", sa.UseTemplateCRDs,
 		"Whether or not to allow configuration of Mixer via template-specific CRDs")
 
+	serverCmd.PersistentFlags().DurationVar(&sa.HandlerIsolationOptions.HandlerTimeout, "handlerTimeout",
+		sa.HandlerIsolationOptions.HandlerTimeout,
+		"Deadline budget of a single dispatch to a handler. Zero means the handler shares the request deadline. "+
+			"A handler overrides it with the policy.istio.io/dispatch-timeout annotation.")
+	serverCmd.PersistentFlags().IntVar(&sa.HandlerIsolationOptions.MaxConcurrentDispatches, "maxConcurrentHandlerDispatches",
+		sa.HandlerIsolationOptions.MaxConcurrentDispatches,
+		"Max number of dispatches in flight to a single handler. Zero means unlimited. "+
+			"A handler overrides it with the policy.istio.io/max-concurrent-dispatches annotation.")
+	serverCmd.PersistentFlags().IntVar(&sa.HandlerIsolationOptions.QuarantineErrors, "handlerQuarantineErrors",
+		sa.HandlerIsolationOptions.QuarantineErrors,
+		"Number of consecutive failed or slow dispatches after which a handler is quarantined. Zero disables the quarantine. "+
+			"A handler overrides it with the policy.istio.io/quarantine-errors annotation.")
+	serverCmd.PersistentFlags().DurationVar(&sa.HandlerIsolationOptions.QuarantineLatency, "handlerQuarantineLatency",
+		sa.HandlerIsolationOptions.QuarantineLatency,
+		"Dispatch duration above which a dispatch counts as failed towards the handler quarantine. Zero means only errors count. "+
+			"A handler overrides it with the policy.istio.io/quarantine-latency annotation.")
+	serverCmd.PersistentFlags().DurationVar(&sa.HandlerIsolationOptions.QuarantineDuration, "handlerQuarantineDuration",
+		sa.HandlerIsolationOptions.QuarantineDuration,
+		"Cool-down after which a quarantined handler is restored. "+
+			"A handler overrides it with the policy.istio.io/quarantine-duration annotation.")
+
 	sa.CredentialOptions.AttachCobraFlags(serverCmd)
 	sa.LoggingOptions.AttachCobraFlags(serverCmd)
 	sa.TracingOptions.AttachCobraFlags(serverCmd)
@@ -25,7 +25,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/gogo/protobuf/jsonpb"
 	"github.com/gogo/protobuf/proto"
@@ -285,6 +287,7 @@ func (e *Ephemeral) processStaticAdapterHandlerConfigs() map[string]*HandlerStat
 				}
 				staticConfig.Params = c
 			}
+			staticConfig.Isolation = handlerIsolation(key.String(), resource.Metadata.Annotations)
 			handlers[key.String()] = staticConfig
 			continue
 		}
@@ -299,9 +302,10 @@ func (e *Ephemeral) processStaticAdapterHandlerConfigs() map[string]*HandlerStat
 		log.Debugf("Processing incoming handler config: name='%s'\n%s", adapterName, resource.Spec)
 
 		cfg := &HandlerStatic{
-			Name:    adapterName,
-			Adapter: info,
-			Params:  resource.Spec,
+			Name:      adapterName,
+			Adapter:   info,
+			Params:    resource.Spec,
+			Isolation: handlerIsolation(adapterName, resource.Metadata.Annotations),
 		}
 
 		handlers[cfg.Name] = cfg
@@ -368,6 +372,7 @@ func (e *Ephemeral) processDynamicHandlerConfigs(ctx context.Context, adapters m
 			Adapter:       adapter,
 			Connection:    hdl.Connection,
 			AdapterConfig: adapterCfg,
+			Isolation:     handlerIsolation(handlerName, resource.Metadata.Annotations),
 		}
 
 		handlers[cfg.Name] = cfg
@@ -380,6 +385,54 @@ func (e *Ephemeral) processDynamicHandlerConfigs(ctx context.Context, adapters m
 
 const googleApis = "type.googleapis.com/"
 
+// failOpenAnnotation is the rule annotation that selects the fail-open mode for the handlers of the rule.
+const failOpenAnnotation = "policy.istio.io/fail-open"
+
+// The handler annotations that override the isolation limits of the dispatcher for the handler.
+const (
+	dispatchTimeoutAnnotation         = "policy.istio.io/dispatch-timeout"
+	maxConcurrentDispatchesAnnotation = "policy.istio.io/max-concurrent-dispatches"
+	quarantineErrorsAnnotation        = "policy.istio.io/quarantine-errors"
+	quarantineLatencyAnnotation       = "policy.istio.io/quarantine-latency"
+	quarantineDurationAnnotation      = "policy.istio.io/quarantine-duration"
+)
+
+// handlerIsolation returns the isolation limits set by the annotations of a handler. Invalid values are
+// ignored, keeping the limits of the dispatcher.
+func handlerIsolation(handlerName string, annotations map[string]string) HandlerIsolation {
+	var isolation HandlerIsolation
+	durations := map[string]*time.Duration{
+		dispatchTimeoutAnnotation:    &isolation.Timeout,
+		quarantineLatencyAnnotation:  &isolation.QuarantineLatency,
+		quarantineDurationAnnotation: &isolation.QuarantineDuration,
+	}
+	for name, d := range durations {
+		if v, ok := annotations[name]; ok {
+			parsed, err := time.ParseDuration(v)
+			if err != nil || parsed < 0 {
+				log.Warnf("Ignoring invalid annotation %s=%q of handler %s", name, v, handlerName)
+				continue
+			}
+			*d = parsed
+		}
+	}
+	counts := map[string]*int{
+		maxConcurrentDispatchesAnnotation: &isolation.MaxConcurrentDispatches,
+		quarantineErrorsAnnotation:        &isolation.QuarantineErrors,
+	}
+	for name, n := range counts {
+		if v, ok := annotations[name]; ok {
+			parsed, err := strconv.Atoi(v)
+			if err != nil || parsed < 0 {
+				log.Warnf("Ignoring invalid annotation %s=%q of handler %s", name, v, handlerName)
+				continue
+			}
+			*n = parsed
+		}
+	}
+	return isolation
+}
+
 func asAny(msgFQN string, bytes []byte) *types.Any {
 	return &types.Any{
 		TypeUrl: googleApis + msgFQN,
@@ -840,6 +893,7 @@ func (e *Ephemeral) processRuleConfigs(
 			RequestHeaderOperations:  cfg.RequestHeaderOperations,
 			ResponseHeaderOperations: cfg.ResponseHeaderOperations,
 			Language:                 mode,
+			FailOpen:                 resource.Metadata.Annotations[failOpenAnnotation] == "true",
 		}
 
 		rules = append(rules, rule)
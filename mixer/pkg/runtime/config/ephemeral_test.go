@@ -16,6 +16,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"istio.io/istio/mixer/adapter/list/config"
 	"istio.io/istio/mixer/pkg/runtime/testing/data"
@@ -47,3 +48,25 @@ func TestDynamicHandlerCorruption(t *testing.T) {
 		}
 	}
 }
+
+func TestHandlerIsolation(t *testing.T) {
+	got := handlerIsolation("h1.istio-system", map[string]string{
+		"policy.istio.io/dispatch-timeout":          "100ms",
+		"policy.istio.io/max-concurrent-dispatches": "10",
+		"policy.istio.io/quarantine-errors":         "invalid",
+		"policy.istio.io/quarantine-latency":        "-1s",
+		"policy.istio.io/quarantine-duration":       "1m",
+	})
+	want := HandlerIsolation{
+		Timeout:                 100 * time.Millisecond,
+		MaxConcurrentDispatches: 10,
+		QuarantineDuration:      time.Minute,
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if got := handlerIsolation("h1.istio-system", nil); got != (HandlerIsolation{}) {
+		t.Errorf("got %+v, want no overrides", got)
+	}
+}
@@ -16,6 +16,7 @@ package config
 
 import (
 	"context"
+	"time"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
@@ -70,6 +71,9 @@ type (
 
 		// Connection information for the handler.
 		Connection *v1beta1.Connection
+
+		// Isolation limits of the dispatches to the handler.
+		Isolation HandlerIsolation
 	}
 
 	// HandlerStatic configuration for compiled in adapters. Fully resolved.
@@ -83,6 +87,28 @@ type (
 
 		// parameters used to construct the Handler.
 		Params proto.Message
+
+		// Isolation limits of the dispatches to the handler.
+		Isolation HandlerIsolation
+	}
+
+	// HandlerIsolation overrides the limits that isolate a handler from the others during dispatch. A zero
+	// value keeps the limit configured for all the handlers.
+	HandlerIsolation struct {
+		// Deadline budget of a single dispatch to the handler.
+		Timeout time.Duration
+
+		// Maximum number of dispatches in flight to the handler.
+		MaxConcurrentDispatches int
+
+		// Number of consecutive failed or slow dispatches after which the handler is quarantined.
+		QuarantineErrors int
+
+		// Duration above which a dispatch counts as failed towards the quarantine.
+		QuarantineLatency time.Duration
+
+		// Cool-down after which the quarantined handler is restored.
+		QuarantineDuration time.Duration
 	}
 
 	// InstanceDynamic configuration for dynamically loaded templates. Fully resolved.
@@ -143,6 +169,10 @@ type (
 
 		// Language runtime to use for expressions
 		Language lang.LanguageRuntime
+
+		// FailOpen indicates that the handlers of the rule are skipped, rather than failing the request,
+		// while they are quarantined or overloaded.
+		FailOpen bool
 	}
 
 	// ActionDynamic configuration. Fully resolved.
@@ -88,6 +88,9 @@ type Impl struct {
 	// pool of goroutines
 	gp *pool.GoroutinePool
 
+	// isolation of the handlers from each other
+	isolator *isolator
+
 	enableTracing bool
 }
 
@@ -97,6 +100,7 @@ var _ Dispatcher = &Impl{}
 func New(handlerGP *pool.GoroutinePool, enableTracing bool) *Impl {
 	d := &Impl{
 		gp:            handlerGP,
+		isolator:      newIsolator(IsolationOptions{}),
 		enableTracing: enableTracing,
 		rc: &RoutingContext{
 			Routes: routing.Empty(),
@@ -110,6 +114,17 @@ func New(handlerGP *pool.GoroutinePool, enableTracing bool) *Impl {
 	return d
 }
 
+// SetIsolation sets the limits that isolate the handlers from each other. It must be called before the
+// Impl dispatches any request.
+func (d *Impl) SetIsolation(o IsolationOptions) {
+	d.isolator = newIsolator(o)
+}
+
+// Quarantines returns the handlers currently quarantined.
+func (d *Impl) Quarantines() []HandlerQuarantine {
+	return d.isolator.quarantines()
+}
+
 const (
 	defaultValidDuration = 1 * time.Minute
 	defaultValidUseCount = 10000
@@ -249,5 +264,7 @@ func (d *Impl) ChangeRoute(newTable *routing.Table) *RoutingContext {
 	d.rc = newRC
 	d.rcLock.Unlock()
 
+	d.isolator.prune(newTable.HandlerNames())
+
 	return old
 }
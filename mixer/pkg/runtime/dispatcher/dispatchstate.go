@@ -55,6 +55,9 @@ type dispatchState struct {
 
 	// attribute prefix for the output bag
 	outputPrefix string
+
+	// whether the dispatch is skipped, rather than failed, when the handler is quarantined or overloaded.
+	failOpen bool
 }
 
 func (ds *dispatchState) clear() {
@@ -67,6 +70,7 @@ func (ds *dispatchState) clear() {
 	ds.err = nil
 	ds.outputBag = nil
 	ds.outputPrefix = ""
+	ds.failOpen = false
 	ds.checkResult = adapter.CheckResult{}
 	ds.quotaResult = adapter.QuotaResult{}
 
@@ -95,6 +99,18 @@ func (ds *dispatchState) logQuotaResultToDispatchSpan(span opentracing.Span) {
 	}
 }
 
+// logResultToDispatchSpan logs the output of the handler to the span of the dispatch.
+func (ds *dispatchState) logResultToDispatchSpan(span opentracing.Span) {
+	switch ds.destination.Template.Variety {
+	case tpb.TEMPLATE_VARIETY_CHECK, tpb.TEMPLATE_VARIETY_CHECK_WITH_OUTPUT:
+		ds.logCheckResultToDispatchSpan(span)
+	case tpb.TEMPLATE_VARIETY_QUOTA:
+		ds.logQuotaResultToDispatchSpan(span)
+	default:
+		ds.logErrToDispatchSpan(span)
+	}
+}
+
 func (ds *dispatchState) logErrToDispatchSpan(span opentracing.Span) {
 	if ds.session.impl.enableTracing {
 		logErrorToDispatchSpan(span, ds.destination.Template.Name, ds.destination.HandlerName, ds.destination.AdapterName, ds.err)
@@ -109,8 +125,33 @@ func (ds *dispatchState) completeSpan(ctx context.Context, span opentracing.Span
 	stats.Record(newCtx, monitoring.DispatchesTotal.M(1), monitoring.DispatchDurationsSeconds.M(duration.Seconds()))
 }
 
+// reject completes a dispatch that was not sent to its handler because the handler is quarantined or
+// overloaded. The dispatch fails, unless its rule fails open.
+func (ds *dispatchState) reject(err error) {
+	ctx, _ := tag.New(ds.ctx,
+		tag.Insert(monitoring.HandlerTag, ds.destination.HandlerName),
+		tag.Insert(monitoring.ReasonTag, rejectReason(err)))
+	stats.Record(ctx, monitoring.RejectedDispatchesTotal.M(1))
+
+	if !ds.failOpen {
+		ds.err = fmt.Errorf("unable to dispatch to handler %s: %v", ds.destination.HandlerName, err)
+		return
+	}
+
+	log.Debugf("skipped dispatch: destination='%s' {err:%v}", ds.destination.FriendlyName, err)
+	if ds.destination.Template.Variety == tpb.TEMPLATE_VARIETY_QUOTA {
+		// grant what was requested, as for a quota that does not apply to the requester.
+		ds.quotaResult = adapter.QuotaResult{
+			Amount:        ds.quotaArgs.QuotaAmount,
+			ValidDuration: defaultValidDuration,
+		}
+	}
+}
+
 func (ds *dispatchState) invokeHandler(interface{}) {
 	reachedEnd := false
+	isolator := ds.session.impl.isolator
+	abandoned := false
 
 	defer func() {
 		if reachedEnd {
@@ -125,6 +166,11 @@ func (ds *dispatchState) invokeHandler(interface{}) {
 			log.Debugf("stack dump for handler dispatch panic:\n%s", debug.Stack())
 		}
 
+		if abandoned {
+			isolator.record(ds.destination, 0, ds.err)
+		} else {
+			isolator.release(ds.destination, 0, ds.err)
+		}
 		ds.session.completed <- ds
 	}()
 
@@ -138,11 +184,37 @@ func (ds *dispatchState) invokeHandler(interface{}) {
 
 	log.Debugf("begin dispatch: destination='%s'", ds.destination.FriendlyName)
 
+	if timeout := isolator.options(ds.destination).HandlerTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+		abandoned = ds.callHandlerWithDeadline(ctx, timeout, isolator)
+	} else {
+		ds.callHandler(ctx)
+	}
+	ds.logResultToDispatchSpan(span)
+
+	log.Debugf("complete dispatch: destination='%s' {err:%v}", ds.destination.FriendlyName, ds.err)
+
+	duration := time.Since(start)
+	ds.completeSpan(ctx, span, duration, ds.err)
+	if abandoned {
+		// the dispatch slot is freed once the handler returns.
+		isolator.record(ds.destination, duration, ds.err)
+	} else {
+		isolator.release(ds.destination, duration, ds.err)
+	}
+	ds.session.completed <- ds
+
+	reachedEnd = true
+}
+
+// callHandler dispatches the instances to the handler, and collects its output.
+func (ds *dispatchState) callHandler(ctx context.Context) {
 	switch ds.destination.Template.Variety {
 	case tpb.TEMPLATE_VARIETY_ATTRIBUTE_GENERATOR:
 		ds.outputBag, ds.err = ds.destination.Template.DispatchGenAttrs(
 			ctx, ds.destination.Handler, ds.instances[0], ds.inputBag, ds.mapper)
-		ds.logErrToDispatchSpan(span)
 
 	case tpb.TEMPLATE_VARIETY_CHECK, tpb.TEMPLATE_VARIETY_CHECK_WITH_OUTPUT:
 		// allocate a bag to store check output results
@@ -151,26 +223,76 @@ func (ds *dispatchState) invokeHandler(interface{}) {
 
 		ds.checkResult, ds.err = ds.destination.Template.DispatchCheck(
 			ctx, ds.destination.Handler, ds.instances[0], ds.outputBag, ds.outputPrefix)
-		ds.logCheckResultToDispatchSpan(span)
 
 	case tpb.TEMPLATE_VARIETY_REPORT:
 		ds.err = ds.destination.Template.DispatchReport(
 			ctx, ds.destination.Handler, ds.instances)
-		ds.logErrToDispatchSpan(span)
 
 	case tpb.TEMPLATE_VARIETY_QUOTA:
 		ds.quotaResult, ds.err = ds.destination.Template.DispatchQuota(
 			ctx, ds.destination.Handler, ds.instances[0], ds.quotaArgs)
-		ds.logQuotaResultToDispatchSpan(span)
 
 	default:
 		panic(fmt.Sprintf("unknown variety type: '%v'", ds.destination.Template.Variety))
 	}
+}
 
-	log.Debugf("complete dispatch: destination='%s' {err:%v}", ds.destination.FriendlyName, ds.err)
+// callHandlerWithDeadline dispatches to the handler like callHandler, but stops waiting for the handler
+// once the deadline of the context passes, even if the handler ignores the context. The handler then
+// works on a copy of the dispatch state, as the state is recycled when the session completes. It returns
+// true if the dispatch is abandoned, in which case the dispatch slot of the handler is freed in the
+// isolator once the handler returns.
+func (ds *dispatchState) callHandlerWithDeadline(ctx context.Context, timeout time.Duration, isolator *isolator) bool {
+	call := &dispatchState{
+		destination:  ds.destination,
+		mapper:       ds.mapper,
+		quotaArgs:    ds.quotaArgs,
+		instances:    append([]interface{}(nil), ds.instances...),
+		outputPrefix: ds.outputPrefix,
+	}
+	// the input bag is released with the request, possibly before the handler returns.
+	var inputBag *attribute.MutableBag
+	if ds.inputBag != nil {
+		inputBag = attribute.CopyBag(ds.inputBag)
+		call.inputBag = inputBag
+	}
 
-	ds.completeSpan(ctx, span, time.Since(start), ds.err)
-	ds.session.completed <- ds
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if inputBag != nil {
+				inputBag.Done()
+			}
+		}()
+		defer func() {
+			if r := recover(); r != nil {
+				call.err = fmt.Errorf("panic during handler dispatch: %v", r)
+				log.Errorf("%v\n%s", call.err, debug.Stack())
+			}
+		}()
+		call.callHandler(ctx)
+	}()
 
-	reachedEnd = true
+	select {
+	case <-done:
+		ds.err = call.err
+		ds.outputBag = call.outputBag
+		ds.checkResult = call.checkResult
+		ds.quotaResult = call.quotaResult
+		return false
+	case <-ctx.Done():
+		ds.err = fmt.Errorf("handler %s did not complete the dispatch within %v: %v",
+			ds.destination.HandlerName, timeout, ctx.Err())
+		destination := ds.destination
+		go func() {
+			// release the slot and the output of the abandoned dispatch once the handler returns.
+			<-done
+			isolator.free(destination)
+			if call.outputBag != nil {
+				call.outputBag.Done()
+			}
+		}()
+		return true
+	}
 }
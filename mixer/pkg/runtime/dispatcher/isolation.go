@@ -0,0 +1,313 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+
+	"istio.io/istio/mixer/pkg/runtime/monitoring"
+	"istio.io/istio/mixer/pkg/runtime/routing"
+	"istio.io/pkg/log"
+)
+
+// defaultQuarantineDuration is the cool-down of a quarantined handler, if none is configured.
+const defaultQuarantineDuration = 30 * time.Second
+
+var (
+	errHandlerQuarantined = errors.New("handler is quarantined")
+	errHandlerOverloaded  = errors.New("too many concurrent dispatches to handler")
+)
+
+// IsolationOptions holds the limits that keep a misbehaving handler from affecting the requests beyond its
+// own dispatches. A zero value disables the corresponding limit. The handlers can override the limits with
+// annotations, see config.HandlerIsolation.
+type IsolationOptions struct {
+	// HandlerTimeout is the deadline budget of a single dispatch to a handler. It is set on the context
+	// passed to the handler, and the dispatch fails once it expires, even if the handler doesn't return.
+	HandlerTimeout time.Duration
+
+	// MaxConcurrentDispatches is the maximum number of dispatches in flight to a single handler. Dispatches
+	// beyond the limit are rejected.
+	MaxConcurrentDispatches int
+
+	// QuarantineErrors is the number of consecutive failed or slow dispatches after which a handler is
+	// quarantined. Dispatches to a quarantined handler are rejected.
+	QuarantineErrors int
+
+	// QuarantineLatency is the duration above which a dispatch counts as failed towards the quarantine.
+	QuarantineLatency time.Duration
+
+	// QuarantineDuration is the cool-down after which a quarantined handler is restored.
+	QuarantineDuration time.Duration
+}
+
+// HandlerQuarantine describes a quarantined handler.
+type HandlerQuarantine struct {
+	Handler string    `json:"handler"`
+	Reason  string    `json:"reason"`
+	Since   time.Time `json:"since"`
+	Until   time.Time `json:"until"`
+}
+
+// isolator tracks the health of the handlers, and rejects the dispatches to the handlers that are quarantined
+// or overloaded.
+type isolator struct {
+	opts IsolationOptions
+	now  func() time.Time
+
+	mu       sync.RWMutex
+	handlers map[string]*handlerHealth
+
+	// number of handlers currently quarantined.
+	quarantined int64
+}
+
+// handlerHealth is the isolation state of a single handler.
+type handlerHealth struct {
+	// number of dispatches in flight, accessed atomically.
+	inflight int32
+
+	mu       sync.Mutex
+	failures int
+	reason   string
+	since    time.Time
+	until    time.Time
+}
+
+func newIsolator(opts IsolationOptions) *isolator {
+	return &isolator{
+		opts:     opts,
+		now:      time.Now,
+		handlers: make(map[string]*handlerHealth),
+	}
+}
+
+// options returns the limits of the handler of the destination: the ones set on the handler override the
+// ones of the dispatcher.
+func (i *isolator) options(d *routing.Destination) IsolationOptions {
+	o := i.opts
+	h := d.Isolation
+	if h.Timeout > 0 {
+		o.HandlerTimeout = h.Timeout
+	}
+	if h.MaxConcurrentDispatches > 0 {
+		o.MaxConcurrentDispatches = h.MaxConcurrentDispatches
+	}
+	if h.QuarantineErrors > 0 {
+		o.QuarantineErrors = h.QuarantineErrors
+	}
+	if h.QuarantineLatency > 0 {
+		o.QuarantineLatency = h.QuarantineLatency
+	}
+	if h.QuarantineDuration > 0 {
+		o.QuarantineDuration = h.QuarantineDuration
+	}
+	if o.QuarantineErrors > 0 && o.QuarantineDuration <= 0 {
+		o.QuarantineDuration = defaultQuarantineDuration
+	}
+	return o
+}
+
+// enabled returns whether the dispatches are tracked.
+func (o IsolationOptions) enabled() bool {
+	return o.MaxConcurrentDispatches > 0 || o.QuarantineErrors > 0
+}
+
+func (i *isolator) health(handler string) *handlerHealth {
+	i.mu.RLock()
+	h := i.handlers[handler]
+	i.mu.RUnlock()
+	if h != nil {
+		return h
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if h = i.handlers[handler]; h == nil {
+		h = &handlerHealth{}
+		i.handlers[handler] = h
+	}
+	return h
+}
+
+// acquire reserves a dispatch to the handler of the destination. Each successful acquire must be followed
+// by a release, or by a record and a free once the handler returns if the dispatch is abandoned.
+func (i *isolator) acquire(d *routing.Destination) error {
+	opts := i.options(d)
+	if !opts.enabled() {
+		return nil
+	}
+	handler := d.HandlerName
+	h := i.health(handler)
+
+	if opts.QuarantineErrors > 0 {
+		h.mu.Lock()
+		if !h.until.IsZero() {
+			if i.now().Before(h.until) {
+				h.mu.Unlock()
+				return errHandlerQuarantined
+			}
+			// The cool-down is over, give the handler another chance.
+			h.until = time.Time{}
+			h.failures = 0
+			log.Infof("Handler %s is restored after quarantine", handler)
+			i.recordQuarantined(-1)
+		}
+		h.mu.Unlock()
+	}
+
+	if max := int32(opts.MaxConcurrentDispatches); max > 0 && !i.reserve(handler, max) {
+		return errHandlerOverloaded
+	}
+	return nil
+}
+
+// reserve takes one of the max dispatch slots of the handler. The slot is taken with the handlers locked for
+// reading, so that prune doesn't drop a handler having a dispatch in flight.
+func (i *isolator) reserve(handler string, max int32) bool {
+	for {
+		h := i.health(handler)
+		i.mu.RLock()
+		if i.handlers[handler] != h {
+			// pruned in the meantime.
+			i.mu.RUnlock()
+			continue
+		}
+		ok := atomic.AddInt32(&h.inflight, 1) <= max
+		if !ok {
+			atomic.AddInt32(&h.inflight, -1)
+		}
+		i.mu.RUnlock()
+		return ok
+	}
+}
+
+// release completes a dispatch to the handler of the destination, and quarantines the handler if it keeps
+// failing or being slow.
+func (i *isolator) release(d *routing.Destination, duration time.Duration, err error) {
+	i.record(d, duration, err)
+	i.free(d)
+}
+
+// free releases the dispatch slot reserved by acquire, once the handler has returned. The slot of a dispatch
+// abandoned after the timeout is freed when the handler eventually returns, so that a handler ignoring its
+// deadline can't have more calls running than MaxConcurrentDispatches.
+func (i *isolator) free(d *routing.Destination) {
+	if i.options(d).MaxConcurrentDispatches > 0 {
+		atomic.AddInt32(&i.health(d.HandlerName).inflight, -1)
+	}
+}
+
+// record accounts the outcome of a dispatch to the handler of the destination, and quarantines the handler
+// if it keeps failing or being slow.
+func (i *isolator) record(d *routing.Destination, duration time.Duration, err error) {
+	opts := i.options(d)
+	if opts.QuarantineErrors <= 0 {
+		return
+	}
+	handler := d.HandlerName
+	h := i.health(handler)
+
+	var reason string
+	if err != nil {
+		reason = err.Error()
+	} else if opts.QuarantineLatency > 0 && duration > opts.QuarantineLatency {
+		reason = fmt.Sprintf("dispatch took %v", duration)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if reason == "" {
+		h.failures = 0
+		return
+	}
+	h.failures++
+	if h.failures < opts.QuarantineErrors || !h.until.IsZero() {
+		return
+	}
+
+	now := i.now()
+	h.failures = 0
+	h.reason = reason
+	h.since = now
+	h.until = now.Add(opts.QuarantineDuration)
+	log.Warnf("Handler %s is quarantined until %v after %d consecutive failures: %s",
+		handler, h.until.Format(time.RFC3339), opts.QuarantineErrors, reason)
+
+	ctx, _ := tag.New(context.Background(), tag.Insert(monitoring.HandlerTag, handler))
+	stats.Record(ctx, monitoring.HandlerQuarantinesTotal.M(1))
+	i.recordQuarantined(1)
+}
+
+// prune stops tracking the handlers which are not in the routing table anymore, once they have no dispatch
+// in flight.
+func (i *isolator) prune(handlers map[string]struct{}) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for name, h := range i.handlers {
+		if _, f := handlers[name]; f || atomic.LoadInt32(&h.inflight) > 0 {
+			continue
+		}
+		h.mu.Lock()
+		if !h.until.IsZero() {
+			i.recordQuarantined(-1)
+		}
+		h.mu.Unlock()
+		delete(i.handlers, name)
+	}
+}
+
+func (i *isolator) recordQuarantined(delta int64) {
+	stats.Record(context.Background(), monitoring.QuarantinedHandlersTotal.M(atomic.AddInt64(&i.quarantined, delta)))
+}
+
+// quarantines returns the handlers currently quarantined, sorted by name.
+func (i *isolator) quarantines() []HandlerQuarantine {
+	now := i.now()
+	var out []HandlerQuarantine
+
+	i.mu.RLock()
+	for name, h := range i.handlers {
+		h.mu.Lock()
+		if now.Before(h.until) {
+			out = append(out, HandlerQuarantine{Handler: name, Reason: h.reason, Since: h.since, Until: h.until})
+		}
+		h.mu.Unlock()
+	}
+	i.mu.RUnlock()
+
+	sort.Slice(out, func(a, b int) bool {
+		return out[a].Handler < out[b].Handler
+	})
+	return out
+}
+
+// rejectReason returns the value of the reason tag of a rejected dispatch.
+func rejectReason(err error) string {
+	if err == errHandlerQuarantined {
+		return "quarantined"
+	}
+	return "overloaded"
+}
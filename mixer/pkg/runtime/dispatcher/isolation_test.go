@@ -0,0 +1,360 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"istio.io/istio/mixer/pkg/runtime/config"
+	"istio.io/istio/mixer/pkg/runtime/handler"
+	"istio.io/istio/mixer/pkg/runtime/routing"
+	"istio.io/istio/mixer/pkg/runtime/testing/data"
+	"istio.io/pkg/attribute"
+	"istio.io/pkg/pool"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+var (
+	h1 = &routing.Destination{HandlerName: "h1"}
+	h2 = &routing.Destination{HandlerName: "h2"}
+)
+
+func newTestIsolator(opts IsolationOptions) (*isolator, *fakeClock) {
+	c := &fakeClock{t: time.Unix(1000, 0)}
+	i := newIsolator(opts)
+	i.now = c.now
+	return i, c
+}
+
+func TestIsolator_Quarantine(t *testing.T) {
+	i, c := newTestIsolator(IsolationOptions{
+		QuarantineErrors:   2,
+		QuarantineLatency:  time.Second,
+		QuarantineDuration: time.Minute,
+	})
+	errDispatch := errors.New("dispatch failed")
+
+	dispatch := func(duration time.Duration, err error) error {
+		if e := i.acquire(h1); e != nil {
+			return e
+		}
+		i.release(h1, duration, err)
+		return nil
+	}
+
+	// A success resets the count of consecutive failures.
+	_ = dispatch(0, errDispatch)
+	_ = dispatch(0, nil)
+	_ = dispatch(0, errDispatch)
+	if q := i.quarantines(); len(q) != 0 {
+		t.Fatalf("handler quarantined after non-consecutive failures: %v", q)
+	}
+
+	// A slow dispatch counts as a failure.
+	_ = dispatch(2*time.Second, nil)
+	q := i.quarantines()
+	if len(q) != 1 || q[0].Handler != "h1" || !strings.Contains(q[0].Reason, "dispatch took 2s") {
+		t.Fatalf("unexpected quarantines: %v", q)
+	}
+	if err := dispatch(0, nil); err != errHandlerQuarantined {
+		t.Fatalf("got %v, want %v", err, errHandlerQuarantined)
+	}
+
+	// Other handlers are not affected.
+	if err := i.acquire(h2); err != nil {
+		t.Fatalf("unexpected error for another handler: %v", err)
+	}
+	i.release(h2, 0, nil)
+
+	// The handler is restored after the cool-down.
+	c.t = c.t.Add(time.Minute)
+	if err := dispatch(0, nil); err != nil {
+		t.Fatalf("handler not restored after the cool-down: %v", err)
+	}
+	if q := i.quarantines(); len(q) != 0 {
+		t.Fatalf("unexpected quarantines: %v", q)
+	}
+}
+
+func TestIsolator_MaxConcurrentDispatches(t *testing.T) {
+	i, _ := newTestIsolator(IsolationOptions{MaxConcurrentDispatches: 2})
+
+	for n := 0; n < 2; n++ {
+		if err := i.acquire(h1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := i.acquire(h1); err != errHandlerOverloaded {
+		t.Fatalf("got %v, want %v", err, errHandlerOverloaded)
+	}
+
+	i.release(h1, 0, nil)
+	if err := i.acquire(h1); err != nil {
+		t.Fatalf("unexpected error after a release: %v", err)
+	}
+}
+
+func TestIsolator_Disabled(t *testing.T) {
+	i, _ := newTestIsolator(IsolationOptions{})
+
+	for n := 0; n < 10; n++ {
+		if err := i.acquire(h1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		i.release(h1, time.Hour, errors.New("dispatch failed"))
+	}
+	if len(i.handlers) != 0 {
+		t.Fatalf("handlers tracked while isolation is disabled: %v", i.handlers)
+	}
+}
+
+func TestIsolator_HandlerOverrides(t *testing.T) {
+	i, _ := newTestIsolator(IsolationOptions{MaxConcurrentDispatches: 1, QuarantineErrors: 1})
+	overridden := &routing.Destination{
+		HandlerName: "h3",
+		Isolation:   config.HandlerIsolation{MaxConcurrentDispatches: 2, QuarantineErrors: 3},
+	}
+
+	for n := 0; n < 2; n++ {
+		if err := i.acquire(overridden); err != nil {
+			t.Fatalf("unexpected error below the limit of the handler: %v", err)
+		}
+	}
+	if err := i.acquire(overridden); err != errHandlerOverloaded {
+		t.Fatalf("got %v, want %v", err, errHandlerOverloaded)
+	}
+	i.release(overridden, 0, errors.New("dispatch failed"))
+	i.release(overridden, 0, errors.New("dispatch failed"))
+	if q := i.quarantines(); len(q) != 0 {
+		t.Fatalf("handler quarantined below its threshold: %v", q)
+	}
+
+	// The other handlers keep the limits of the dispatcher.
+	if err := i.acquire(h1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := i.acquire(h1); err != errHandlerOverloaded {
+		t.Fatalf("got %v, want %v", err, errHandlerOverloaded)
+	}
+	i.release(h1, 0, errors.New("dispatch failed"))
+	if q := i.quarantines(); len(q) != 1 || q[0].Handler != "h1" {
+		t.Fatalf("unexpected quarantines: %v", q)
+	}
+	if d := i.options(h1).QuarantineDuration; d != defaultQuarantineDuration {
+		t.Fatalf("got quarantine duration %v, want %v", d, defaultQuarantineDuration)
+	}
+}
+
+func TestDispatcher_Quarantine(t *testing.T) {
+	var cases = []struct {
+		name string
+		rule string
+		err  string
+	}{
+		{
+			name: "fail closed",
+			rule: data.RuleCheck1,
+			err:  "unable to dispatch to handler hcheck1.acheck.istio-system: handler is quarantined",
+		},
+		{
+			name: "fail open",
+			rule: data.RuleCheck1FailOpen,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := New(gp, false)
+			d.SetIsolation(IsolationOptions{QuarantineErrors: 2, QuarantineDuration: time.Hour})
+
+			l := &data.Logger{}
+			templates := data.BuildTemplates(l, data.FakeTemplateSettings{Name: "tcheck", ErrorOnDispatchCheck: true})
+			adapters := data.BuildAdapters(l)
+			cfg := data.JoinConfigs(data.HandlerACheck1, data.InstanceCheck1, c.rule)
+
+			s, _ := config.GetSnapshotForTest(templates, adapters, data.ServiceConfig, cfg)
+			h := handler.NewTable(handler.Empty(), s, pool.NewGoroutinePool(1, false))
+			_ = d.ChangeRoute(routing.BuildTable(h, s, "istio-system", true))
+
+			bag := attribute.GetMutableBagForTesting(map[string]interface{}{"ident": "dest.istio-system"})
+
+			// The handler fails until it is quarantined.
+			for n := 0; n < 2; n++ {
+				if _, err := d.Check(context.TODO(), bag); err == nil || !strings.Contains(err.Error(), "error at dispatch check") {
+					t.Fatalf("unexpected error from the handler: %v", err)
+				}
+			}
+			if q := d.Quarantines(); len(q) != 1 || q[0].Handler != "hcheck1.acheck.istio-system" {
+				t.Fatalf("unexpected quarantines: %v", q)
+			}
+
+			l.Clear()
+			_, err := d.Check(context.TODO(), bag)
+			if c.err == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+				t.Fatalf("got error %v, want %q", err, c.err)
+			}
+			if strings.Contains(l.String(), "DispatchCheck") {
+				t.Fatalf("quarantined handler was dispatched to:\n%s", l.String())
+			}
+		})
+	}
+}
+
+func TestDispatcher_HandlerTimeout(t *testing.T) {
+	d := New(gp, false)
+	d.SetIsolation(IsolationOptions{HandlerTimeout: time.Millisecond})
+
+	l := &data.Logger{}
+	templates := data.BuildTemplates(l)
+	adapters := data.BuildAdapters(l)
+	cfg := data.JoinConfigs(data.HandlerACheck1, data.InstanceCheck1, data.RuleCheck1)
+
+	s, _ := config.GetSnapshotForTest(templates, adapters, data.ServiceConfig, cfg)
+	h := handler.NewTable(handler.Empty(), s, pool.NewGoroutinePool(1, false))
+	_ = d.ChangeRoute(routing.BuildTable(h, s, "istio-system", true))
+
+	bag := attribute.GetMutableBagForTesting(map[string]interface{}{"ident": "dest.istio-system"})
+	if _, err := d.Check(context.TODO(), bag); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(l.String(), "context exists: 'true'") {
+		t.Fatalf("handler was not dispatched to:\n%s", l.String())
+	}
+}
+
+func TestDispatcher_HandlerDeadline(t *testing.T) {
+	d := New(gp, false)
+	d.SetIsolation(IsolationOptions{HandlerTimeout: 10 * time.Millisecond, QuarantineErrors: 1})
+
+	// The handler ignores the context, and doesn't return before the test completes.
+	commence := make(chan struct{})
+	defer close(commence)
+
+	l := &data.Logger{}
+	templates := data.BuildTemplates(l, data.FakeTemplateSettings{Name: "tcheck", CommenceSignalChannel: commence})
+	adapters := data.BuildAdapters(l)
+	cfg := data.JoinConfigs(data.HandlerACheck1, data.InstanceCheck1, data.RuleCheck1)
+
+	s, _ := config.GetSnapshotForTest(templates, adapters, data.ServiceConfig, cfg)
+	h := handler.NewTable(handler.Empty(), s, pool.NewGoroutinePool(1, false))
+	_ = d.ChangeRoute(routing.BuildTable(h, s, "istio-system", true))
+
+	bag := attribute.GetMutableBagForTesting(map[string]interface{}{"ident": "dest.istio-system"})
+	result := make(chan error, 1)
+	go func() {
+		_, err := d.Check(context.TODO(), bag)
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		if err == nil || !strings.Contains(err.Error(), "did not complete the dispatch within 10ms") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the dispatch waited for the handler past its deadline")
+	}
+
+	// The expired dispatch counts as a failure of the handler.
+	if q := d.Quarantines(); len(q) != 1 || q[0].Handler != "hcheck1.acheck.istio-system" {
+		t.Fatalf("unexpected quarantines: %v", q)
+	}
+}
+
+func TestDispatcher_AbandonedDispatchHoldsSlot(t *testing.T) {
+	d := New(gp, false)
+	d.SetIsolation(IsolationOptions{HandlerTimeout: 10 * time.Millisecond, MaxConcurrentDispatches: 1})
+
+	// The handler ignores the context, and only returns once commence is closed.
+	commence := make(chan struct{})
+
+	// No logger, as the abandoned handler runs concurrently with the next dispatches.
+	templates := data.BuildTemplates(nil, data.FakeTemplateSettings{Name: "tcheck", CommenceSignalChannel: commence})
+	adapters := data.BuildAdapters(nil)
+	cfg := data.JoinConfigs(data.HandlerACheck1, data.InstanceCheck1, data.RuleCheck1)
+
+	s, _ := config.GetSnapshotForTest(templates, adapters, data.ServiceConfig, cfg)
+	h := handler.NewTable(handler.Empty(), s, pool.NewGoroutinePool(1, false))
+	_ = d.ChangeRoute(routing.BuildTable(h, s, "istio-system", true))
+
+	bag := attribute.GetMutableBagForTesting(map[string]interface{}{"ident": "dest.istio-system"})
+	if _, err := d.Check(context.TODO(), bag); err == nil || !strings.Contains(err.Error(), "did not complete the dispatch") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The abandoned dispatch keeps its slot while the handler runs.
+	if _, err := d.Check(context.TODO(), bag); err == nil || !strings.Contains(err.Error(), errHandlerOverloaded.Error()) {
+		t.Fatalf("got error %v, want %v", err, errHandlerOverloaded)
+	}
+
+	close(commence)
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		_, err := d.Check(context.TODO(), bag)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the slot of the abandoned dispatch was not freed: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestIsolator_Prune(t *testing.T) {
+	i, _ := newTestIsolator(IsolationOptions{
+		MaxConcurrentDispatches: 1,
+		QuarantineErrors:        1,
+		QuarantineDuration:      time.Minute,
+	})
+
+	// h1 is quarantined, and h2 has a dispatch in flight.
+	if err := i.acquire(h1); err != nil {
+		t.Fatal(err)
+	}
+	i.release(h1, 0, errors.New("dispatch failed"))
+	if err := i.acquire(h2); err != nil {
+		t.Fatal(err)
+	}
+
+	i.prune(map[string]struct{}{})
+	if _, f := i.handlers["h1"]; f {
+		t.Error("h1 is still tracked after it was removed")
+	}
+	if _, f := i.handlers["h2"]; !f {
+		t.Error("h2 was pruned while it has a dispatch in flight")
+	}
+	if i.quarantined != 0 {
+		t.Errorf("got %d quarantined handlers, want 0", i.quarantined)
+	}
+
+	i.release(h2, 0, nil)
+	i.prune(map[string]struct{}{"h1": {}})
+	if len(i.handlers) != 0 {
+		t.Errorf("handlers still tracked: %v", i.handlers)
+	}
+}
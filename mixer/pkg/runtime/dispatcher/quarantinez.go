@@ -0,0 +1,90 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dispatcher
+
+import (
+	"html/template"
+	"net/http"
+
+	"istio.io/pkg/ctrlz/fw"
+)
+
+const quarantineTemplate = `{{ define "content" }}
+
+<p>
+    The handlers that Mixer currently quarantines, because they exceeded their error or latency thresholds.
+    Dispatches to these handlers are rejected until the end of their cool-down.
+</p>
+
+<table>
+    <thead>
+        <tr>
+            <th>Handler</th>
+            <th>Reason</th>
+            <th>Since</th>
+            <th>Until</th>
+        </tr>
+    </thead>
+
+    <tbody>
+        {{ range . }}
+        <tr>
+            <td>{{.Handler}}</td>
+            <td>{{.Reason}}</td>
+            <td>{{.Since}}</td>
+            <td>{{.Until}}</td>
+        </tr>
+        {{ end }}
+    </tbody>
+</table>
+
+{{ end }}
+`
+
+// quarantineTopic is a ctrlz topic that lists the quarantined handlers.
+type quarantineTopic struct {
+	d *Impl
+}
+
+var _ fw.Topic = &quarantineTopic{}
+
+// QuarantineTopic returns a ctrlz topic that lists the handlers quarantined by the dispatcher.
+func QuarantineTopic(d *Impl) fw.Topic {
+	return &quarantineTopic{d: d}
+}
+
+// Title is implementation of Topic.Title.
+func (q *quarantineTopic) Title() string {
+	return "Handler Quarantine"
+}
+
+// Prefix is implementation of Topic.Prefix.
+func (q *quarantineTopic) Prefix() string {
+	return "quarantine"
+}
+
+// Activate is implementation of Topic.Activate.
+func (q *quarantineTopic) Activate(context fw.TopicContext) {
+	l := template.Must(context.Layout().Clone())
+	tmpl := template.Must(l.Parse(quarantineTemplate))
+
+	_ = context.HTMLRouter().StrictSlash(true).NewRoute().Path("/").HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fw.RenderHTML(w, tmpl, q.d.Quarantines())
+	})
+
+	_ = context.JSONRouter().StrictSlash(true).NewRoute().Methods("GET").Path("/").HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fw.RenderJSON(w, http.StatusOK, q.d.Quarantines())
+	})
+}
@@ -166,6 +166,8 @@ func (s *session) dispatch() error { //nolint: unparam
 
 				// For report templates, accumulate instances as much as possible before commencing dispatch.
 				if s.variety == tpb.TEMPLATE_VARIETY_REPORT {
+					// The buffered report fails open only if all of its rules do.
+					state.failOpen = group.FailOpen && (len(state.instances) == 0 || state.failOpen)
 					state.instances = append(state.instances, instance)
 					continue
 				}
@@ -173,6 +175,7 @@ func (s *session) dispatch() error { //nolint: unparam
 				// for other templates, dispatch for each instance individually.
 				state = s.impl.getDispatchState(s.ctx, destination)
 				state.instances = append(state.instances, instance)
+				state.failOpen = group.FailOpen
 				if s.variety == tpb.TEMPLATE_VARIETY_ATTRIBUTE_GENERATOR {
 					state.mapper = group.Mappers[j]
 					state.inputBag = s.bag
@@ -284,6 +287,11 @@ func (s *session) dispatchBufferedReports() {
 func (s *session) dispatchToHandler(ds *dispatchState) {
 	s.activeDispatches++
 	ds.session = s
+	if err := s.impl.isolator.acquire(ds.destination); err != nil {
+		ds.reject(err)
+		s.completed <- ds
+		return
+	}
 	s.impl.gp.ScheduleWork(ds.invokeHandler, nil)
 }
 
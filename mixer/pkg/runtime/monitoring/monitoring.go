@@ -27,6 +27,7 @@ const (
 	adapterName  = "adapter"
 	errorStr     = "error"
 	varietyStr   = "variety"
+	reasonStr    = "reason"
 )
 
 var (
@@ -40,6 +41,8 @@ var (
 	ErrorTag tag.Key
 	// VarietyTag holds the template variety
 	VarietyTag tag.Key
+	// ReasonTag holds the reason a dispatch was rejected.
+	ReasonTag tag.Key
 
 	// distribution buckets
 	durationBuckets = []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
@@ -193,6 +196,24 @@ var (
 		"mixer/dispatcher/destinations_per_variety_total",
 		"Number of Mixer adapter destinations by template variety type",
 		stats.UnitDimensionless)
+
+	// RejectedDispatchesTotal is a measure of the number of dispatches rejected by handler isolation.
+	RejectedDispatchesTotal = stats.Int64(
+		"mixer/dispatcher/rejected_dispatches_total",
+		"Number of dispatches to quarantined or overloaded handlers that were rejected by Mixer",
+		stats.UnitDimensionless)
+
+	// HandlerQuarantinesTotal is a measure of the number of times handlers were quarantined.
+	HandlerQuarantinesTotal = stats.Int64(
+		"mixer/dispatcher/handler_quarantines_total",
+		"Number of times Mixer quarantined a handler that exceeded its error or latency thresholds",
+		stats.UnitDimensionless)
+
+	// QuarantinedHandlersTotal is a measure of the number of handlers currently quarantined.
+	QuarantinedHandlersTotal = stats.Int64(
+		"mixer/dispatcher/quarantined_handlers_total",
+		"Number of handlers currently quarantined by Mixer",
+		stats.UnitDimensionless)
 )
 
 func newView(measure stats.Measure, keys []tag.Key, aggregation *view.Aggregation) *view.View {
@@ -222,6 +243,9 @@ func init() {
 	if VarietyTag, err = tag.NewKey(varietyStr); err != nil {
 		panic(err)
 	}
+	if ReasonTag, err = tag.NewKey(reasonStr); err != nil {
+		panic(err)
+	}
 
 	envConfigKeys := []tag.Key{HandlerTag}
 	dispatchKeys := []tag.Key{MeshFunctionTag, HandlerTag, AdapterTag, ErrorTag}
@@ -257,6 +281,11 @@ func init() {
 		newView(DispatchesTotal, dispatchKeys, view.Count()),
 		newView(DispatchDurationsSeconds, dispatchKeys, view.Distribution(durationBuckets...)),
 
+		// isolation views
+		newView(RejectedDispatchesTotal, []tag.Key{HandlerTag, ReasonTag}, view.Count()),
+		newView(HandlerQuarantinesTotal, []tag.Key{HandlerTag}, view.Count()),
+		newView(QuarantinedHandlersTotal, []tag.Key{}, view.LastValue()),
+
 		// others
 		newView(DestinationsPerRequest, []tag.Key{}, view.Distribution(countBuckets...)),
 		newView(InstancesPerRequest, []tag.Key{}, view.Distribution(countBuckets...)),
@@ -194,7 +194,7 @@ func (b *builder) build(snapshot *config.Snapshot) {
 				}
 
 				b.add(rule.Namespace, buildTemplateInfo(instance.Template), entry, condition, builder, mapper,
-					entry.Name, instance.Name, rule.Match, action.Name)
+					entry.Name, instance.Name, rule.Match, action.Name, rule.FailOpen, action.Handler.Isolation)
 			}
 		}
 
@@ -220,7 +220,7 @@ func (b *builder) build(snapshot *config.Snapshot) {
 				builder, mapper := b.getBuilderAndMapperDynamic(instance)
 
 				b.add(rule.Namespace, b.templateInfo(instance.Template), entry, condition, builder, mapper,
-					entry.Name, instance.Name, rule.Match, action.Name)
+					entry.Name, instance.Name, rule.Match, action.Name, rule.FailOpen, action.Handler.Isolation)
 			}
 		}
 
@@ -364,7 +364,9 @@ func (b *builder) add(
 	handlerName string,
 	instanceName string,
 	matchText string,
-	actionName string) {
+	actionName string,
+	failOpen bool,
+	isolation config.HandlerIsolation) {
 
 	// CHECK_WITH_OUTPUT is grouped into CHECK variety table
 	variety := t.Variety
@@ -408,6 +410,7 @@ func (b *builder) add(
 			AdapterName:    entry.AdapterName,
 			Template:       t,
 			InstanceGroups: []*InstanceGroup{},
+			Isolation:      isolation,
 		}
 		byNamespace.entries = append(byNamespace.entries, byHandler)
 	}
@@ -419,8 +422,8 @@ func (b *builder) add(
 	for _, set := range byHandler.InstanceGroups {
 		// Try to find an input set to place the entry by comparing the compiled expression and resource type.
 		// This doesn't flatten across all actions, but only for actions coming from the same rule. We can
-		// flatten based on the expression text as well.
-		if set.Condition == condition {
+		// flatten based on the expression text as well. Rules with different fail modes are not flattened.
+		if set.Condition == condition && set.FailOpen == failOpen {
 			instanceGroup = set
 			break
 		}
@@ -432,6 +435,7 @@ func (b *builder) add(
 			Condition: condition,
 			Builders:  []NamedBuilder{},
 			Mappers:   []template.OutputMapperFn{},
+			FailOpen:  failOpen,
 		}
 		byHandler.InstanceGroups = append(byHandler.InstanceGroups, instanceGroup)
 
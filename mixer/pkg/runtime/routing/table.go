@@ -20,6 +20,7 @@ import (
 	"istio.io/istio/mixer/pkg/adapter"
 	"istio.io/istio/mixer/pkg/attribute"
 	"istio.io/istio/mixer/pkg/lang/compiled"
+	"istio.io/istio/mixer/pkg/runtime/config"
 	"istio.io/istio/mixer/pkg/template"
 	"istio.io/pkg/log"
 )
@@ -82,6 +83,9 @@ type Destination struct {
 
 	// FriendlyName is the friendly name of this configured handler entry. Used for monitoring/logging purposes.
 	FriendlyName string
+
+	// Isolation limits of the handler, overriding the ones of the dispatcher.
+	Isolation config.HandlerIsolation
 }
 
 // DirectiveGroup is a group of route directive expressions with a condition.
@@ -153,6 +157,10 @@ type InstanceGroup struct {
 
 	// Mappers for attribute-generating adapters that map output attributes into the main attribute set.
 	Mappers []template.OutputMapperFn
+
+	// FailOpen indicates that the instances of this group are not dispatched, rather than failing the
+	// request, while the handler is quarantined or overloaded.
+	FailOpen bool
 }
 
 var emptyTable = &Table{id: -1}
@@ -186,6 +194,24 @@ func (t *Table) GetDestinations(variety tpb.TemplateVariety, namespace string) *
 	return destinationSet
 }
 
+// HandlerNames returns the names of the handlers of all the destinations of the table.
+func (t *Table) HandlerNames() map[string]struct{} {
+	names := make(map[string]struct{})
+	for _, vt := range t.entries {
+		for _, nt := range vt.entries {
+			for _, d := range nt.entries {
+				names[d.HandlerName] = struct{}{}
+			}
+		}
+		if vt.defaultSet != nil {
+			for _, d := range vt.defaultSet.entries {
+				names[d.HandlerName] = struct{}{}
+			}
+		}
+	}
+	return names
+}
+
 // Count returns the number of entries contained.
 func (d *NamespaceTable) Count() int {
 	return len(d.entries)
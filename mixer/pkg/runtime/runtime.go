@@ -26,6 +26,7 @@ import (
 	"istio.io/istio/mixer/pkg/runtime/handler"
 	"istio.io/istio/mixer/pkg/runtime/routing"
 	"istio.io/istio/mixer/pkg/template"
+	"istio.io/pkg/ctrlz/fw"
 	"istio.io/pkg/log"
 	"istio.io/pkg/pool"
 	"istio.io/pkg/probe"
@@ -96,6 +97,17 @@ func (c *Runtime) Dispatcher() dispatcher.Dispatcher {
 	return c.dispatcher
 }
 
+// SetHandlerIsolation sets the limits that isolate the handlers from each other during dispatch. It must be
+// called before the dispatcher serves requests.
+func (c *Runtime) SetHandlerIsolation(o dispatcher.IsolationOptions) {
+	c.dispatcher.SetIsolation(o)
+}
+
+// QuarantineTopic returns a ctrlz topic that lists the handlers quarantined by the dispatcher.
+func (c *Runtime) QuarantineTopic() fw.Topic {
+	return dispatcher.QuarantineTopic(c.dispatcher)
+}
+
 // StartListening directs Runtime to start listening to configuration changes. As config changes, runtime processes
 // the confguration and creates a dispatcher.
 func (c *Runtime) StartListening() error {
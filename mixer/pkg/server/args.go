@@ -23,6 +23,7 @@ import (
 	"istio.io/istio/mixer/pkg/config/store"
 	"istio.io/istio/mixer/pkg/loadshedding"
 	"istio.io/istio/mixer/pkg/runtime/config/constant"
+	"istio.io/istio/mixer/pkg/runtime/dispatcher"
 	"istio.io/istio/mixer/pkg/template"
 	"istio.io/istio/pkg/mcp/creds"
 	"istio.io/istio/pkg/tracing"
@@ -112,6 +113,9 @@ type Args struct {
 	UseTemplateCRDs bool
 
 	LoadSheddingOptions loadshedding.Options
+
+	// The limits that isolate the handlers from each other during dispatch
+	HandlerIsolationOptions dispatcher.IsolationOptions
 }
 
 // DefaultArgs allocates an Args struct initialized with Mixer's default configuration.
@@ -160,6 +164,14 @@ func (a *Args) validate() error {
 		return fmt.Errorf("# check cache entries must be >= 0 and <= 2^31-1, got %d", a.NumCheckCacheEntries)
 	}
 
+	if a.HandlerIsolationOptions.MaxConcurrentDispatches < 0 {
+		return fmt.Errorf("max concurrent handler dispatches must be >= 0, got %d", a.HandlerIsolationOptions.MaxConcurrentDispatches)
+	}
+
+	if a.HandlerIsolationOptions.QuarantineErrors < 0 {
+		return fmt.Errorf("handler quarantine errors must be >= 0, got %d", a.HandlerIsolationOptions.QuarantineErrors)
+	}
+
 	if a.ConfigStore != nil && a.ConfigStoreURL != "" {
 		return fmt.Errorf("invalid arguments: both ConfigStore and ConfigStoreURL are specified")
 	}
@@ -193,6 +205,7 @@ func (a *Args) String() string {
 	fmt.Fprintf(buf, "UseTemplateCRDs: %#v\n", a.UseTemplateCRDs)
 	fmt.Fprintf(buf, "LoadSheddingOptions: %#v\n", a.LoadSheddingOptions)
 	fmt.Fprintf(buf, "UseAdapterCRDs: %#v\n", a.UseAdapterCRDs)
+	fmt.Fprintf(buf, "HandlerIsolationOptions: %#v\n", a.HandlerIsolationOptions)
 
 	return buf.String()
 }
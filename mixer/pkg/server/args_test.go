@@ -57,6 +57,18 @@ func TestValidation(t *testing.T) {
 		t.Errorf("Got unexpected success")
 	}
 
+	a = DefaultArgs()
+	a.HandlerIsolationOptions.MaxConcurrentDispatches = -1
+	if err := a.validate(); err == nil {
+		t.Errorf("Got unexpected success")
+	}
+
+	a = DefaultArgs()
+	a.HandlerIsolationOptions.QuarantineErrors = -1
+	if err := a.validate(); err == nil {
+		t.Errorf("Got unexpected success")
+	}
+
 	a = DefaultArgs()
 	a.ConfigStore = store.WithBackend(nil)
 	a.ConfigStoreURL = "k8s://"
@@ -43,6 +43,7 @@ import (
 	"istio.io/istio/mixer/pkg/template"
 	"istio.io/istio/pkg/tracing"
 	"istio.io/pkg/ctrlz"
+	"istio.io/pkg/ctrlz/fw"
 	"istio.io/pkg/log"
 	"istio.io/pkg/pool"
 	"istio.io/pkg/probe"
@@ -216,6 +217,7 @@ func newServer(a *Args, p *patchTable) (server *Server, err error) {
 	log.Info("Starting runtime config watch...")
 	rt := p.newRuntime(st, templateMap, adapterMap, a.ConfigDefaultNamespace,
 		s.gp, s.adapterGP, a.TracingOptions.TracingEnabled())
+	rt.SetHandlerIsolation(a.HandlerIsolationOptions)
 
 	if err = p.runtimeListen(rt); err != nil {
 		return nil, fmt.Errorf("unable to listen: %v", err)
@@ -266,7 +268,7 @@ func newServer(a *Args, p *patchTable) (server *Server, err error) {
 		return nil, fmt.Errorf("unable to setup monitoring: %v", err)
 	}
 
-	s.controlZ, _ = ctrlz.Run(a.IntrospectionOptions, nil)
+	s.controlZ, _ = ctrlz.Run(a.IntrospectionOptions, []fw.Topic{rt.QuarantineTopic()})
 
 	return s, nil
 }
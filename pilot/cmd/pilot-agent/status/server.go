@@ -32,6 +32,8 @@ import (
 	"istio.io/istio/pilot/pkg/model"
 
 	"istio.io/istio/pilot/cmd/pilot-agent/status/ready"
+	"istio.io/istio/pilot/cmd/pilot-agent/status/util"
+	"istio.io/istio/pkg/envoy/usage"
 	"istio.io/pkg/log"
 
 	corev1 "k8s.io/api/core/v1"
@@ -43,6 +45,8 @@ const (
 	readyPath = "/healthz/ready"
 	// quitPath is to notify the pilot agent to quit.
 	quitPath = "/quitquitquit"
+	// usageStatsPath exposes the usage of the proxy by identities, in Prometheus format.
+	usageStatsPath = "/stats/usage"
 	// KubeAppProberEnvName is the name of the command line flag for pilot agent to pass app prober config.
 	// The json encoded string to pass app HTTP probe information from injector(istioctl or webhook).
 	// For example, ISTIO_KUBE_APP_PROBERS='{"/app-health/httpbin/livez":{"path": "/hello", "port": 8080}.
@@ -77,13 +81,17 @@ type Server struct {
 	mutex               sync.RWMutex
 	appKubeProbers      KubeAppProbers
 	statusPort          uint16
+	localHostAddr       string
+	adminPort           uint16
 	lastProbeSuccessful bool
 }
 
 // NewServer creates a new status server.
 func NewServer(config Config) (*Server, error) {
 	s := &Server{
-		statusPort: config.StatusPort,
+		statusPort:    config.StatusPort,
+		localHostAddr: config.LocalHostAddr,
+		adminPort:     config.AdminPort,
 		ready: &ready.Probe{
 			LocalHostAddr: config.LocalHostAddr,
 			AdminPort:     config.AdminPort,
@@ -125,6 +133,7 @@ func (s *Server) Run(ctx context.Context) {
 	mux.HandleFunc(readyPath, s.handleReadyProbe)
 	mux.HandleFunc(quitPath, s.handleQuit)
 	mux.HandleFunc("/app-health/", s.handleAppProbe)
+	mux.HandleFunc(usageStatsPath, s.handleUsageStats)
 
 	l, err := net.Listen("tcp", fmt.Sprintf(":%d", s.statusPort))
 	if err != nil {
@@ -175,6 +184,17 @@ func (s *Server) handleReadyProbe(w http.ResponseWriter, _ *http.Request) {
 	s.mutex.Unlock()
 }
 
+func (s *Server) handleUsageStats(w http.ResponseWriter, _ *http.Request) {
+	report, err := util.GetUsageStats(s.localHostAddr, s.adminPort)
+	if err != nil {
+		log.Warnf("Failed to retrieve usage stats from Envoy: %v", err)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_ = usage.WritePrometheus(w, report)
+}
+
 func isRequestFromLocalhost(r *http.Request) bool {
 	ip, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
@@ -303,3 +303,36 @@ func TestHandleQuit(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleUsageStats(t *testing.T) {
+	envoy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stats/prometheus" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`# TYPE istio_usage_requests_total counter
+istio_usage_requests_total{reporter="destination",source_principal="spiffe://cluster.local/ns/default/sa/a",destination_principal="spiffe://cluster.local/ns/default/sa/b"} 4
+`))
+	}))
+	defer envoy.Close()
+	adminPort := envoy.Listener.Addr().(*net.TCPAddr).Port
+	server := &Server{localHostAddr: "127.0.0.1", adminPort: uint16(adminPort)}
+
+	w := httptest.NewRecorder()
+	server.handleUsageStats(w, httptest.NewRequest("GET", usageStatsPath, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	expected := `istio_agent_usage_requests_total{source_principal="spiffe://cluster.local/ns/default/sa/a",` +
+		`destination_principal="spiffe://cluster.local/ns/default/sa/b"} 4`
+	if !strings.Contains(w.Body.String(), expected) {
+		t.Fatalf("expected %q in:\n%s", expected, w.Body.String())
+	}
+
+	envoy.Close()
+	w = httptest.NewRecorder()
+	server.handleUsageStats(w, httptest.NewRequest("GET", usageStatsPath, nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+}
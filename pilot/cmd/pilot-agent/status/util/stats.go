@@ -21,6 +21,8 @@ import (
 	"strings"
 
 	multierror "github.com/hashicorp/go-multierror"
+
+	"istio.io/istio/pkg/envoy/usage"
 )
 
 const (
@@ -100,6 +102,15 @@ func GetUpdateStatusStats(localHostAddr string, adminPort uint16) (*Stats, error
 	return s, nil
 }
 
+// GetUsageStats returns the usage of the proxy, by identities of the source and the destination.
+func GetUsageStats(localHostAddr string, adminPort uint16) (usage.Report, error) {
+	stats, err := doHTTPGet(fmt.Sprintf("http://%s:%d/stats/prometheus?usedonly", localHostAddr, adminPort))
+	if err != nil {
+		return nil, err
+	}
+	return usage.Parse(stats)
+}
+
 func parseStats(input *bytes.Buffer, stats []*stat) (err error) {
 	for input.Len() > 0 {
 		line, _ := input.ReadString('\n')
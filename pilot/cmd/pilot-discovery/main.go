@@ -26,6 +26,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"istio.io/istio/pilot/pkg/bootstrap"
+	"istio.io/istio/pilot/pkg/config/release"
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/serviceregistry"
 	"istio.io/istio/pkg/cmd"
@@ -120,7 +121,8 @@ func init() {
 	discoveryCmd.PersistentFlags().StringVarP(&serverArgs.Namespace, "namespace", "n", "",
 		"Select a namespace where the controller resides. If not set, uses ${POD_NAMESPACE} environment variable")
 	discoveryCmd.PersistentFlags().StringSliceVar(&serverArgs.Plugins, "plugins", bootstrap.DefaultPlugins,
-		"comma separated list of networking plugins to enable")
+		"comma separated list of networking plugins to enable. The usage plugin accounts the traffic received "+
+			"by the sidecars by identities, without Mixer")
 
 	// MCP client flags
 	discoveryCmd.PersistentFlags().IntVar(&serverArgs.MCPMaxMessageSize, "mcpMaxMsgSize", serverArgs.MCPMaxMessageSize,
@@ -136,6 +138,19 @@ func init() {
 			"It is recommended to be disable for highly available setups.")
 	discoveryCmd.PersistentFlags().StringVar(&serverArgs.Config.FileDir, "configDir", "",
 		"Directory to watch for updates to config yaml files. If specified, the files will be used as the source of config, rather than a CRD client.")
+	discoveryCmd.PersistentFlags().StringVar(&serverArgs.Config.ReleaseFile, "releaseFile", "",
+		"File holding the name of the active config release. If specified, configs labeled with "+release.Label+
+			" are only used once their release is written to the file.")
+	discoveryCmd.PersistentFlags().BoolVar(&serverArgs.Config.ReleaseFromConfigStore, "releaseFromConfigStore", false,
+		"If enabled, configs labeled with "+release.Label+" are only used once their release is named by the "+
+			release.ActiveAnnotation+" annotation of a config in the root namespace.")
+	discoveryCmd.PersistentFlags().StringSliceVar(&serverArgs.Config.ScopeNamespaces, "scopeNamespaces", nil,
+		"Comma-separated namespaces served by this Pilot. If set, the configs and proxies of the other namespaces are "+
+			"ignored, and their services are only imported if exported to all namespaces with the exportTo annotation "+
+			"or field. Several scoped Pilots can run in a cluster, each proxy connecting to the Pilot of its namespace.")
+	discoveryCmd.PersistentFlags().StringSliceVar(&serverArgs.Config.ExcludeNamespaces, "excludeNamespaces", nil,
+		"Comma-separated namespaces not served by this Pilot, typically the namespaces of the scoped Pilots. "+
+			"Their configs and proxies are ignored, and their services are only imported if exported to all namespaces.")
 	discoveryCmd.PersistentFlags().StringVarP(&serverArgs.Config.ControllerOptions.WatchedNamespace, "appNamespace",
 		"a", metav1.NamespaceAll,
 		"Restrict the applications namespace the controller manages; if not set, controller watches all namespaces")
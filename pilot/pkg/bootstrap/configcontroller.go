@@ -32,6 +32,7 @@ import (
 	mcpapi "istio.io/api/mcp/v1alpha1"
 	meshconfig "istio.io/api/mesh/v1alpha1"
 	networkingapi "istio.io/api/networking/v1alpha3"
+	"istio.io/pkg/filewatcher"
 	"istio.io/pkg/log"
 
 	configaggregate "istio.io/istio/pilot/pkg/config/aggregate"
@@ -39,9 +40,12 @@ import (
 	"istio.io/istio/pilot/pkg/config/kube/ingress"
 	"istio.io/istio/pilot/pkg/config/memory"
 	configmonitor "istio.io/istio/pilot/pkg/config/monitor"
+	"istio.io/istio/pilot/pkg/config/release"
+	"istio.io/istio/pilot/pkg/config/scoped"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/serviceregistry/mcp"
 	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/schema"
 	"istio.io/istio/pkg/config/schemas"
 	configz "istio.io/istio/pkg/mcp/configz/client"
 	"istio.io/istio/pkg/mcp/creds"
@@ -58,7 +62,7 @@ const (
 )
 
 // initConfigController creates the config controller in the pilotConfig.
-func (s *Server) initConfigController(args *PilotArgs) error {
+func (s *Server) initConfigController(args *PilotArgs, fileWatcher filewatcher.FileWatcher) error {
 	meshConfig := s.environment.Mesh()
 	if len(meshConfig.ConfigSources) > 0 {
 		// Using MCP for config.
@@ -106,6 +110,34 @@ func (s *Server) initConfigController(args *PilotArgs) error {
 	}
 	s.configController = aggregateMcpController
 
+	// Hide configs of staged releases until the release named in the release file, or in the
+	// config store, is active.
+	if args.Config.ReleaseFile != "" && args.Config.ReleaseFromConfigStore {
+		return fmt.Errorf("the release file and the config store can't both hold the active release")
+	}
+	if args.Config.ReleaseFile != "" || args.Config.ReleaseFromConfigStore {
+		releaseController := release.NewController(s.configController)
+		if args.Config.ReleaseFromConfigStore {
+			releaseController.WatchStore(meshConfig.RootNamespace)
+		} else {
+			// Added before the start function of the config controller, so that the release is
+			// active before the caches sync.
+			s.addStartFunc(func(stop <-chan struct{}) error {
+				if err := releaseController.WatchFile(stop, fileWatcher, args.Config.ReleaseFile); err != nil {
+					return fmt.Errorf("release file: %v", err)
+				}
+				return nil
+			})
+		}
+		s.releaseController = releaseController
+		s.configController = releaseController
+	}
+
+	// Hide the configs of the namespaces which aren't served.
+	if s.environment.NamespaceScope != nil {
+		s.configController = scoped.NewController(s.configController, s.environment.NamespaceScope, meshConfig.RootNamespace)
+	}
+
 	// Create the config store.
 	s.environment.IstioConfigStore = model.MakeIstioStore(s.configController)
 
@@ -361,9 +393,52 @@ func (s *Server) makeKubeConfigController(args *PilotArgs) (model.ConfigStoreCac
 		}
 	}
 
+	if s.environment.NamespaceScope.Namespaces() != nil {
+		return s.makeScopedKubeConfigController(args)
+	}
 	return controller.NewController(configClient, args.Config.ControllerOptions), nil
 }
 
+// makeScopedKubeConfigController watches the namespaced configs only in the namespaces of the scope
+// and in the root namespace, with a controller per namespace. The ServiceEntries, which are
+// imported from all the namespaces when exported, and the cluster scoped configs are watched in
+// all the namespaces. A scope excluding namespaces watches all the namespaces, whose configs are
+// filtered by the scoped controller.
+func (s *Server) makeScopedKubeConfigController(args *PilotArgs) (model.ConfigStoreCache, error) {
+	var clusterWide, namespaced schema.Set
+	for _, desc := range schemas.Istio {
+		if desc.ClusterScoped || desc.Type == schemas.ServiceEntry.Type {
+			clusterWide = append(clusterWide, desc)
+		} else {
+			namespaced = append(namespaced, desc)
+		}
+	}
+
+	namespaces := s.environment.NamespaceScope.Namespaces()
+	if root := s.environment.Mesh().RootNamespace; !s.environment.NamespaceScope.Contains(root) {
+		namespaces = append(namespaces, root)
+	}
+
+	configLedger := buildLedger(args.Config)
+	clusterWideClient, err := controller.NewClient(args.Config.KubeConfig, "", clusterWide,
+		args.Config.ControllerOptions.DomainSuffix, configLedger)
+	if err != nil {
+		return nil, multierror.Prefix(err, "failed to open a config client.")
+	}
+	caches := []model.ConfigStoreCache{controller.NewController(clusterWideClient, args.Config.ControllerOptions)}
+	for _, ns := range namespaces {
+		client, err := controller.NewClient(args.Config.KubeConfig, "", namespaced,
+			args.Config.ControllerOptions.DomainSuffix, configLedger)
+		if err != nil {
+			return nil, multierror.Prefix(err, "failed to open a config client.")
+		}
+		options := args.Config.ControllerOptions
+		options.WatchedNamespace = ns
+		caches = append(caches, controller.NewController(client, options))
+	}
+	return configaggregate.MakeCache(caches)
+}
+
 func (s *Server) makeFileMonitor(fileDir string, configController model.ConfigStore) error {
 	fileSnapshot := configmonitor.NewFileSnapshot(fileDir, schemas.Istio)
 	fileMonitor := configmonitor.NewMonitor("file-monitor", configController, FilepathWalkInterval, fileSnapshot.ReadConfigFiles)
@@ -184,6 +184,14 @@ func (s *Server) RunCA(grpc *grpc.Server, opts *CAOptions) {
 		}
 	}
 
+	// In an external control plane, the tokens of the workloads of the remote clusters are reviewed by
+	// their own API server.
+	if externalControlPlane.Get() && s.multicluster != nil {
+		caServer.Authenticators = append(caServer.Authenticators,
+			authenticate.NewRemoteKubeJWTAuthenticator(s.multicluster.GetRemoteKubeClient, opts.TrustDomain))
+		log.Infoa("Using remote cluster JWT authentication")
+	}
+
 	if serverErr := caServer.Run(); serverErr != nil {
 		// stop the registry-related controllers
 		ch <- struct{}{}
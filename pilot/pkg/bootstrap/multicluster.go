@@ -15,11 +15,31 @@
 package bootstrap
 
 import (
+	"fmt"
+	"io/ioutil"
+
 	"istio.io/istio/pilot/pkg/serviceregistry/kube/controller"
+	"istio.io/istio/pkg/kube/inject"
+	"istio.io/pkg/env"
 
 	"istio.io/pkg/log"
 )
 
+var (
+	externalControlPlane = env.RegisterBoolVar("EXTERNAL_CONTROL_PLANE", false,
+		"If enabled, istiod is the control plane of the remote clusters of the mesh: in addition to their "+
+			"endpoints, it injects their pods and signs certificates for their workloads.")
+
+	remoteInjectionURL = env.RegisterStringVar("REMOTE_INJECTION_URL", "",
+		"Base URL of the injector reachable from the API servers of the remote clusters, e.g. "+
+			"https://istiod.example.com:15017. The injection webhook is registered in the remote clusters if set.")
+)
+
+const (
+	// Name of the webhook config created in the remote clusters, the same as the installed one.
+	remoteWebhookConfigName = "istio-sidecar-injector"
+)
+
 // initClusterRegistries starts the secret controller to watch for remote
 // clusters and initialize the multicluster structures.
 func (s *Server) initClusterRegistries(args *PilotArgs) (err error) {
@@ -31,7 +51,8 @@ func (s *Server) initClusterRegistries(args *PilotArgs) (err error) {
 			args.Config.ControllerOptions.ResyncPeriod,
 			s.ServiceController(),
 			s.EnvoyXdsServer,
-			s.environment)
+			s.environment,
+			s.environment.NamespaceScope)
 
 		if err != nil {
 			log.Info("Unable to create new Multicluster object")
@@ -42,3 +63,30 @@ func (s *Server) initClusterRegistries(args *PilotArgs) (err error) {
 	}
 	return nil
 }
+
+// initRemoteWebhooks registers the injection webhook in the remote clusters, calling back the
+// injector of this control plane.
+func (s *Server) initRemoteWebhooks() error {
+	if remoteInjectionURL.Get() == "" {
+		log.Warn("REMOTE_INJECTION_URL is not set, the pods of the remote clusters are not injected")
+		return nil
+	}
+	// The certificate of the injector is signed by the K8S CA of this cluster.
+	caBundle, err := ioutil.ReadFile(defaultCACertPath)
+	if err != nil {
+		return fmt.Errorf("failed to read the CA bundle of the injector: %v", err)
+	}
+	// The remote clusters get the rules of the injector installed with this control plane.
+	localConfigName := webhookConfigName.Get()
+	if localConfigName == "" {
+		localConfigName = remoteWebhookConfigName
+	}
+	s.multicluster.AddClusterHandler(&inject.RemoteWebhook{
+		ConfigName:      remoteWebhookConfigName,
+		URL:             remoteInjectionURL.Get(),
+		CABundle:        caBundle,
+		LocalConfigs:    s.kubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations(),
+		LocalConfigName: localConfigName,
+	})
+	return nil
+}
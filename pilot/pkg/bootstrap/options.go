@@ -44,6 +44,22 @@ type ConfigArgs struct {
 	KubeConfig                 string
 	FileDir                    string
 
+	// ReleaseFile holds the name of the active config release. If set, configs labeled
+	// with another release are staged until their release is written to the file.
+	ReleaseFile string
+
+	// ReleaseFromConfigStore reads the active config release from the release.ActiveAnnotation of
+	// the configs of the root namespace instead of a file.
+	ReleaseFromConfigStore bool
+
+	// ScopeNamespaces are the namespaces served by Pilot. If set, the configs and proxies of the
+	// other namespaces are ignored, and their services are only imported if explicitly exported.
+	ScopeNamespaces []string
+
+	// ExcludeNamespaces are the namespaces not served by Pilot, such as the namespaces of the scoped
+	// Pilots for the default Pilot. Their services are only imported if explicitly exported.
+	ExcludeNamespaces []string
+
 	// DistributionTracking control
 	DistributionCacheRetention time.Duration
 
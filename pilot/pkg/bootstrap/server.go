@@ -42,12 +42,14 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	kubelib "istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/events"
 
 	"istio.io/pkg/ctrlz"
 	"istio.io/pkg/filewatcher"
 	"istio.io/pkg/log"
 	"istio.io/pkg/version"
 
+	"istio.io/istio/pilot/pkg/config/release"
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/plugin"
@@ -107,7 +109,9 @@ type Server struct {
 	clusterID             string
 	environment           *model.Environment
 	configController      model.ConfigStoreCache
+	releaseController     *release.Controller
 	kubeClient            kubernetes.Interface
+	eventRecorder         *events.Recorder
 	startFuncs            []startFunc
 	multicluster          *kubecontroller.Multicluster
 	httpServer            *http.Server
@@ -143,6 +147,7 @@ func NewServer(args *PilotArgs) (*Server, error) {
 	args.Default()
 	e := &model.Environment{
 		ServiceDiscovery: aggregate.NewController(),
+		NamespaceScope:   model.NewNamespaceScope(args.Config.ScopeNamespaces, args.Config.ExcludeNamespaces),
 		PushContext:      model.NewPushContext(),
 	}
 
@@ -173,7 +178,7 @@ func NewServer(args *PilotArgs) (*Server, error) {
 	if err := s.initCertController(args); err != nil {
 		return nil, fmt.Errorf("certificate controller: %v", err)
 	}
-	if err := s.initConfigController(args); err != nil {
+	if err := s.initConfigController(args, fileWatcher); err != nil {
 		return nil, fmt.Errorf("config controller: %v", err)
 	}
 	if err := s.initServiceControllers(args); err != nil {
@@ -267,6 +272,19 @@ func (s *Server) initKubeClient(args *PilotArgs) error {
 		if err != nil {
 			return fmt.Errorf("failed creating kube client: %v", err)
 		}
+
+		// The decisions of Pilot, such as the configs it rejects, and the configs rejected by the
+		// proxies, are emitted as events on the affected objects.
+		s.eventRecorder = events.NewRecorder(s.kubeClient.CoreV1(), "pilot")
+		s.EnvoyXdsServer.EventRecorder = s.eventRecorder
+		args.Config.ControllerOptions.EventRecorder = s.eventRecorder
+		s.addStartFunc(func(stop <-chan struct{}) error {
+			go func() {
+				<-stop
+				s.eventRecorder.Shutdown()
+			}()
+			return nil
+		})
 	}
 
 	return nil
@@ -598,6 +616,9 @@ func (s *Server) waitForCacheSync(stop <-chan struct{}) bool {
 func (s *Server) initEventHandlers() error {
 	// Flush cached discovery responses whenever services configuration change.
 	serviceHandler := func(svc *model.Service, _ model.Event) {
+		if !s.importsService(svc) {
+			return
+		}
 		pushReq := &model.PushRequest{
 			Full:               true,
 			NamespacesUpdated:  map[string]struct{}{svc.Attributes.Namespace: {}},
@@ -610,6 +631,9 @@ func (s *Server) initEventHandlers() error {
 	}
 
 	instanceHandler := func(si *model.ServiceInstance, _ model.Event) {
+		if !s.importsService(si.Service) {
+			return
+		}
 		// TODO: This is an incomplete code. This code path is called for consul, etc.
 		// In all cases, this is simply an instance update and not a config update. So, we need to update
 		// EDS in all proxies, and do a full config push for the instance that just changed (add/update only).
@@ -640,9 +664,33 @@ func (s *Server) initEventHandlers() error {
 		}
 	}
 
+	if s.releaseController != nil {
+		// Push all the configs of a release in a single request, so proxies never see
+		// a partially applied release.
+		s.releaseController.RegisterReleaseHandler(func(typesUpdated map[string]struct{}) {
+			s.EnvoyXdsServer.ConfigUpdate(&model.PushRequest{
+				Full:               true,
+				ConfigTypesUpdated: typesUpdated,
+			})
+		})
+	}
+
 	return nil
 }
 
+// importsService returns true if the service is imported in the namespace scope of Pilot, or was
+// imported at the last push. The changes of the other services don't affect the proxies.
+func (s *Server) importsService(svc *model.Service) bool {
+	if s.environment.NamespaceScope.ImportsService(svc) {
+		return true
+	}
+	push := s.environment.PushContext
+	push.Mutex.Lock()
+	defer push.Mutex.Unlock()
+	_, f := push.ServiceByHostnameAndNamespace[svc.Hostname][svc.Attributes.Namespace]
+	return f
+}
+
 // add a GRPC listener using DNS-based certificates. Will be used for Galley, injection and CA signing.
 func (s *Server) initDNSListener(args *PilotArgs) error {
 	istiodAddr := features.IstiodService.Get()
@@ -68,6 +68,11 @@ func (s *Server) initServiceControllers(args *PilotArgs) error {
 
 	s.serviceEntryStore = external.NewServiceDiscovery(s.configController, s.environment.IstioConfigStore, s.EnvoyXdsServer)
 	serviceControllers.AddRegistry(s.serviceEntryStore)
+	if s.kubeRegistry != nil {
+		// ServiceEntries with a workload selector may select pods. Only the pods of this cluster are
+		// selected, not the pods of the remote clusters added by the multicluster controller.
+		s.serviceEntryStore.AppendWorkloadSource(s.kubeRegistry)
+	}
 
 	// Defer running of the service controllers.
 	s.addStartFunc(func(stop <-chan struct{}) error {
@@ -84,6 +89,7 @@ func (s *Server) initKubeRegistry(serviceControllers *aggregate.Controller, args
 	args.Config.ControllerOptions.Metrics = s.environment
 	args.Config.ControllerOptions.XDSUpdater = s.EnvoyXdsServer
 	args.Config.ControllerOptions.NetworksWatcher = s.environment.NetworksWatcher
+	args.Config.ControllerOptions.NamespaceScope = s.environment.NamespaceScope
 	if features.EnableEndpointSliceController {
 		args.Config.ControllerOptions.EndpointMode = kubecontroller.EndpointSliceOnly
 	} else {
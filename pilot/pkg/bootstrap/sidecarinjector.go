@@ -68,12 +68,23 @@ func (s *Server) initSidecarInjector(args *PilotArgs) error {
 			HealthCheckFile:     "",
 			HealthCheckInterval: 0,
 			MonitoringPort:      s.basePort + 16, // TODO: disable the second monitoring port
+			EventRecorder:       s.eventRecorder,
+		}
+		if externalControlPlane.Get() && s.multicluster != nil {
+			parameters.IsRemoteCluster = func(clusterID string) bool {
+				return s.multicluster.GetRemoteKubeClient(clusterID) != nil
+			}
 		}
 
 		wh, err := inject.NewWebhook(parameters)
 		if err != nil {
 			return fmt.Errorf("failed to create injection webhook: %v", err)
 		}
+		if externalControlPlane.Get() && s.multicluster != nil {
+			if err := s.initRemoteWebhooks(); err != nil {
+				return err
+			}
+		}
 		if webhookConfigName.Get() != "" {
 			s.addStartFunc(func(stop <-chan struct{}) error {
 				if err := patchCertLoop(s.kubeClient, stop); err != nil {
@@ -34,15 +34,17 @@ import (
 	"istio.io/istio/pilot/pkg/serviceregistry/kube"
 	controller2 "istio.io/istio/pilot/pkg/serviceregistry/kube/controller"
 	"istio.io/istio/pkg/config/schema"
+	"istio.io/istio/pkg/kube/events"
 	"istio.io/istio/pkg/queue"
 )
 
 // controller is a collection of synchronized resource watchers.
 // Caches are thread-safe
 type controller struct {
-	client *Client
-	queue  queue.Instance
-	kinds  map[string]*cacheHandler
+	client   *Client
+	queue    queue.Instance
+	kinds    map[string]*cacheHandler
+	recorder *events.Recorder
 }
 
 type cacheHandler struct {
@@ -88,9 +90,10 @@ func NewController(client *Client, options controller2.Options) model.ConfigStor
 
 	// The queue requires a time duration for a retry delay after a handler error
 	out := &controller{
-		client: client,
-		queue:  queue.NewQueue(1 * time.Second),
-		kinds:  make(map[string]*cacheHandler),
+		client:   client,
+		queue:    queue.NewQueue(1 * time.Second),
+		kinds:    make(map[string]*cacheHandler),
+		recorder: options.EventRecorder,
 	}
 
 	// add stores for CRD kinds
@@ -194,12 +197,18 @@ func (c *controller) newCacheHandler(
 	return h
 }
 
-func handleValidationFailure(obj interface{}, err error) {
+// handleValidationFailure records the rejection of the config, and emits it as an event on the
+// config, which is ignored until it is fixed.
+func (c *controller) handleValidationFailure(s schema.Instance, obj interface{}, err error) {
 	if obj, ok := obj.(crd.IstioObject); ok {
 		key := obj.GetObjectMeta().Namespace + "/" + obj.GetObjectMeta().Name
 		log.Debugf("CRD validation failed: %s %s %v", obj.GetObjectKind().GroupVersionKind().GroupKind().Kind,
 			key, err)
 		k8sErrors.With(nameTag.Value(key)).Record(1)
+		ref := events.ObjectReference(crd.APIVersion(&s), crd.KebabCaseToCamelCase(s.Type),
+			obj.GetObjectMeta().Namespace, obj.GetObjectMeta().Name)
+		ref.UID = obj.GetObjectMeta().UID
+		c.recorder.Eventf(ref, events.Warning, "ConfigRejected", "Config rejected by Pilot and ignored: %v", err)
 	} else {
 		log.Debugf("CRD validation failed for unknown Kind: %s", err)
 		k8sErrors.With(nameTag.Value("unknown")).Record(1)
@@ -315,7 +324,7 @@ func (c *controller) Get(typ, name, namespace string) *model.Config {
 	config, err := crd.ConvertObject(s, obj, c.client.domainSuffix)
 	if err == nil && features.EnableCRDValidation.Get() {
 		if err = s.Validate(config.Name, config.Namespace, config.Spec); err != nil {
-			handleValidationFailure(obj, err)
+			c.handleValidationFailure(s, obj, err)
 			return nil
 		}
 	}
@@ -361,7 +370,7 @@ func (c *controller) List(typ, namespace string) ([]model.Config, error) {
 		if err != nil {
 			// DO NOT RETURN ERROR: if a single object is bad, it'll be ignored (with a log message), but
 			// the rest should still be processed.
-			handleValidationFailure(item, err)
+			c.handleValidationFailure(s, item, err)
 		} else {
 			out = append(out, *config)
 		}
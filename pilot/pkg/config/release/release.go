@@ -0,0 +1,306 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package release implements staged config releases on top of a config store.
+//
+// Configs labeled with a release are hidden until their release is activated. Activating a
+// release swaps the visible configs in one step, so that proxies never see a half applied
+// set of related VirtualServices and DestinationRules. Configs without the label are always visible.
+//
+// The active release is read either from a file, or from the ActiveAnnotation of a config in the
+// config store. Rollback reactivates the previously active releases.
+package release
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"istio.io/pkg/filewatcher"
+	"istio.io/pkg/log"
+
+	"istio.io/istio/pilot/pkg/model"
+)
+
+// Label is the config label holding the release a config belongs to.
+const Label = "networking.istio.io/release"
+
+// ActiveAnnotation is the config annotation naming the active release, when the release is driven
+// from the config store. It can be set on a config of any type in the watched namespace.
+const ActiveAnnotation = "networking.istio.io/activeRelease"
+
+// maxHistory is the number of previously active releases kept for Rollback.
+const maxHistory = 10
+
+var errNoPreviousRelease = errors.New("no previous release to roll back to")
+
+// Handler is called once each time the active release changes, with the config types that
+// were added or removed by the change.
+type Handler func(typesUpdated map[string]struct{})
+
+// Controller hides the configs of inactive releases from the wrapped config store.
+type Controller struct {
+	model.ConfigStoreCache
+
+	mu       sync.RWMutex
+	active   string
+	history  []string
+	handlers []Handler
+}
+
+var _ model.ConfigStoreCache = &Controller{}
+
+// NewController wraps the store, with no release active.
+func NewController(store model.ConfigStoreCache) *Controller {
+	return &Controller{ConfigStoreCache: store}
+}
+
+// Active returns the active release.
+func (c *Controller) Active() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.active
+}
+
+// History returns the previously active releases, most recent last.
+func (c *Controller) History() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]string{}, c.history...)
+}
+
+// RegisterReleaseHandler registers a handler for changes to the active release.
+func (c *Controller) RegisterReleaseHandler(handler Handler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers = append(c.handlers, handler)
+}
+
+// Activate makes the configs of the release visible, and hides the configs of the
+// previously active release.
+func (c *Controller) Activate(release string) {
+	c.mu.Lock()
+	if release == c.active {
+		c.mu.Unlock()
+		return
+	}
+	previous := c.active
+	c.history = append(c.history, previous)
+	if len(c.history) > maxHistory {
+		c.history = c.history[len(c.history)-maxHistory:]
+	}
+	c.active = release
+	c.mu.Unlock()
+
+	log.Infof("release %q activated, replacing %q", release, previous)
+	c.notify(previous, release)
+}
+
+// Rollback reactivates the release that was active before the current one. The release
+// file or the config store activate their release again the next time it changes.
+func (c *Controller) Rollback() error {
+	c.mu.Lock()
+	if len(c.history) == 0 {
+		c.mu.Unlock()
+		return errNoPreviousRelease
+	}
+	previous := c.active
+	c.active = c.history[len(c.history)-1]
+	c.history = c.history[:len(c.history)-1]
+	release := c.active
+	c.mu.Unlock()
+
+	log.Infof("release %q rolled back to %q", previous, release)
+	c.notify(previous, release)
+	return nil
+}
+
+// notify calls the handlers with the types of the configs of both releases.
+func (c *Controller) notify(previous, current string) {
+	typesUpdated := map[string]struct{}{}
+	for _, descriptor := range c.ConfigDescriptor() {
+		configs, err := c.ConfigStoreCache.List(descriptor.Type, model.NamespaceAll)
+		if err != nil {
+			log.Warnf("failed to list %s configs for release change: %v", descriptor.Type, err)
+			// Be conservative and push the type anyway.
+			typesUpdated[descriptor.Type] = struct{}{}
+			continue
+		}
+		for _, cfg := range configs {
+			if r, f := cfg.Labels[Label]; f && (r == previous || r == current) {
+				typesUpdated[descriptor.Type] = struct{}{}
+				break
+			}
+		}
+	}
+
+	c.mu.RLock()
+	handlers := append([]Handler{}, c.handlers...)
+	c.mu.RUnlock()
+	for _, h := range handlers {
+		h(typesUpdated)
+	}
+}
+
+// visible returns true if the config belongs to no release or to the active release.
+func (c *Controller) visible(cfg *model.Config) bool {
+	r, f := cfg.Labels[Label]
+	if !f {
+		return true
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return r == c.active
+}
+
+// Get implements model.ConfigStore, hiding configs of inactive releases.
+func (c *Controller) Get(typ, name, namespace string) *model.Config {
+	cfg := c.ConfigStoreCache.Get(typ, name, namespace)
+	if cfg == nil || !c.visible(cfg) {
+		return nil
+	}
+	return cfg
+}
+
+// List implements model.ConfigStore, hiding configs of inactive releases.
+func (c *Controller) List(typ, namespace string) ([]model.Config, error) {
+	configs, err := c.ConfigStoreCache.List(typ, namespace)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.Config, 0, len(configs))
+	for i := range configs {
+		if c.visible(&configs[i]) {
+			out = append(out, configs[i])
+		}
+	}
+	return out, nil
+}
+
+// RegisterEventHandler implements model.ConfigStoreCache. Changes to staged configs are not
+// forwarded, they are pushed together when their release is activated.
+func (c *Controller) RegisterEventHandler(typ string, handler func(model.Config, model.Config, model.Event)) {
+	c.ConfigStoreCache.RegisterEventHandler(typ, func(old, curr model.Config, event model.Event) {
+		// Add and delete events have no old config.
+		if !c.visible(&curr) && (old.Name == "" || !c.visible(&old)) {
+			log.Debugf("ignoring %s event for staged config %s", event, curr.Key())
+			return
+		}
+		handler(old, curr, event)
+	})
+}
+
+// WatchFile activates the release named in the file, and the releases written to it later.
+// The file is typically mounted from a ConfigMap, so that all Pilot replicas agree on the
+// active release. Rolling back is done by writing the previous release to the file.
+// The file stops being watched when the stop channel is closed.
+func (c *Controller) WatchFile(stop <-chan struct{}, fileWatcher filewatcher.FileWatcher, filename string) error {
+	release, err := readReleaseFile(filename)
+	if err != nil {
+		return err
+	}
+	c.Activate(release)
+
+	if err := fileWatcher.Add(filename); err != nil {
+		return err
+	}
+	go func() {
+		var timerC <-chan time.Time
+		for {
+			select {
+			case <-stop:
+				if err := fileWatcher.Remove(filename); err != nil {
+					log.Warnf("failed to stop watching release file %s: %v", filename, err)
+				}
+				return
+			case <-timerC:
+				timerC = nil
+				release, err := readReleaseFile(filename)
+				if err != nil {
+					log.Warnf("failed to read release file %s: %v", filename, err)
+					continue
+				}
+				c.Activate(release)
+			case <-fileWatcher.Events(filename):
+				// Use a timer to debounce configuration updates
+				if timerC == nil {
+					timerC = time.After(100 * time.Millisecond)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// WatchStore activates the release named by the ActiveAnnotation of the configs in the namespace,
+// typically the root namespace of the mesh, and the releases it is later changed to. If several
+// configs have the annotation, the most recently created one wins. Removing the annotation
+// deactivates all the releases. It must be called before the controller runs.
+func (c *Controller) WatchStore(namespace string) {
+	handler := func(old, curr model.Config, _ model.Event) {
+		if !isReleaseMarker(&old, namespace) && !isReleaseMarker(&curr, namespace) {
+			return
+		}
+		c.Activate(c.storeRelease(namespace))
+	}
+	for _, descriptor := range c.ConfigDescriptor() {
+		c.ConfigStoreCache.RegisterEventHandler(descriptor.Type, handler)
+	}
+}
+
+// storeRelease returns the release named by the most recently created config of the namespace
+// with the ActiveAnnotation.
+func (c *Controller) storeRelease(namespace string) string {
+	var marker *model.Config
+	for _, descriptor := range c.ConfigDescriptor() {
+		configs, err := c.ConfigStoreCache.List(descriptor.Type, namespace)
+		if err != nil {
+			log.Warnf("failed to list %s configs for the active release: %v", descriptor.Type, err)
+			continue
+		}
+		for i := range configs {
+			cfg := &configs[i]
+			if !isReleaseMarker(cfg, namespace) {
+				continue
+			}
+			if marker == nil || cfg.CreationTimestamp.After(marker.CreationTimestamp) ||
+				(cfg.CreationTimestamp.Equal(marker.CreationTimestamp) && cfg.Key() < marker.Key()) {
+				marker = cfg
+			}
+		}
+	}
+	if marker == nil {
+		return ""
+	}
+	return strings.TrimSpace(marker.Annotations[ActiveAnnotation])
+}
+
+// isReleaseMarker returns true if the config of the namespace names the active release.
+func isReleaseMarker(cfg *model.Config, namespace string) bool {
+	if cfg.Namespace != namespace {
+		return false
+	}
+	_, f := cfg.Annotations[ActiveAnnotation]
+	return f
+}
+
+func readReleaseFile(filename string) (string, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
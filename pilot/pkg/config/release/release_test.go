@@ -0,0 +1,249 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/pkg/filewatcher"
+
+	"istio.io/istio/pilot/pkg/config/memory"
+	"istio.io/istio/pilot/pkg/config/release"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+func destinationRule(name, rel string) model.Config {
+	cfg := model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:      schemas.DestinationRule.Type,
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: &networking.DestinationRule{Host: "reviews"},
+	}
+	if rel != "" {
+		cfg.Labels = map[string]string{release.Label: rel}
+	}
+	return cfg
+}
+
+func names(configs []model.Config) []string {
+	out := make([]string, 0, len(configs))
+	for _, cfg := range configs {
+		out = append(out, cfg.Name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func newController(t *testing.T) (*release.Controller, chan struct{}) {
+	t.Helper()
+	store := memory.NewController(memory.Make(schemas.Istio))
+	c := release.NewController(store)
+	stop := make(chan struct{})
+	go c.Run(stop)
+	for _, cfg := range []model.Config{
+		destinationRule("ratings", ""),
+		destinationRule("reviews-blue", "blue"),
+		destinationRule("reviews-green", "green"),
+	} {
+		if _, err := c.Create(cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c, stop
+}
+
+func TestActivate(t *testing.T) {
+	g := NewGomegaWithT(t)
+	c, stop := newController(t)
+	defer close(stop)
+
+	var pushes []map[string]struct{}
+	c.RegisterReleaseHandler(func(typesUpdated map[string]struct{}) {
+		pushes = append(pushes, typesUpdated)
+	})
+
+	list := func() []string {
+		configs, err := c.List(schemas.DestinationRule.Type, "default")
+		g.Expect(err).To(BeNil())
+		return names(configs)
+	}
+
+	g.Expect(list()).To(Equal([]string{"ratings"}))
+	g.Expect(c.Get(schemas.DestinationRule.Type, "reviews-blue", "default")).To(BeNil())
+
+	c.Activate("blue")
+	g.Expect(list()).To(Equal([]string{"ratings", "reviews-blue"}))
+	g.Expect(c.Get(schemas.DestinationRule.Type, "reviews-blue", "default")).ToNot(BeNil())
+
+	c.Activate("green")
+	g.Expect(list()).To(Equal([]string{"ratings", "reviews-green"}))
+
+	c.Activate("blue")
+	g.Expect(c.Active()).To(Equal("blue"))
+	g.Expect(list()).To(Equal([]string{"ratings", "reviews-blue"}))
+
+	// Activating the active release is a no-op.
+	c.Activate("blue")
+
+	// Each release change results in exactly one push for the affected types.
+	g.Expect(pushes).To(HaveLen(3))
+	for _, p := range pushes {
+		g.Expect(p).To(Equal(map[string]struct{}{schemas.DestinationRule.Type: {}}))
+	}
+
+}
+
+func TestStagedEventsAreNotForwarded(t *testing.T) {
+	g := NewGomegaWithT(t)
+	c, stop := newController(t)
+	defer close(stop)
+	c.Activate("blue")
+
+	events := make(chan string, 10)
+	c.RegisterEventHandler(schemas.DestinationRule.Type, func(_, curr model.Config, _ model.Event) {
+		events <- curr.Name
+	})
+
+	for _, name := range []string{"reviews-green", "reviews-blue"} {
+		cfg := c.ConfigStoreCache.Get(schemas.DestinationRule.Type, name, "default")
+		g.Expect(cfg).ToNot(BeNil())
+		cfg.Spec = &networking.DestinationRule{Host: "reviews.default"}
+		if _, err := c.Update(*cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Events of the initial configs may still be queued, so collect everything.
+	seen := map[string]bool{}
+	timeout := time.After(time.Second)
+	for !seen["reviews-blue"] {
+		select {
+		case name := <-events:
+			seen[name] = true
+		case <-timeout:
+			t.Fatal("timed out waiting for event")
+		}
+	}
+	g.Consistently(events, 100*time.Millisecond).ShouldNot(Receive(Equal("reviews-green")))
+	g.Expect(seen).ToNot(HaveKey("reviews-green"))
+}
+
+func TestWatchFile(t *testing.T) {
+	g := NewGomegaWithT(t)
+	c, stop := newController(t)
+	defer close(stop)
+
+	dir, err := ioutil.TempDir("", "release")
+	g.Expect(err).To(BeNil())
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "release")
+
+	g.Expect(c.WatchFile(stop, filewatcher.NewWatcher(), path)).ToNot(BeNil())
+
+	g.Expect(ioutil.WriteFile(path, []byte("blue\n"), 0644)).To(Succeed())
+	g.Expect(c.WatchFile(stop, filewatcher.NewWatcher(), path)).To(Succeed())
+	g.Expect(c.Active()).To(Equal("blue"))
+
+	g.Expect(ioutil.WriteFile(path, []byte("green\n"), 0644)).To(Succeed())
+	g.Eventually(c.Active, 5*time.Second).Should(Equal("green"))
+}
+
+func TestRollback(t *testing.T) {
+	g := NewGomegaWithT(t)
+	c, stop := newController(t)
+	defer close(stop)
+
+	var pushes int
+	c.RegisterReleaseHandler(func(map[string]struct{}) {
+		pushes++
+	})
+
+	g.Expect(c.Rollback()).ToNot(Succeed())
+
+	c.Activate("blue")
+	c.Activate("green")
+	g.Expect(c.History()).To(Equal([]string{"", "blue"}))
+
+	g.Expect(c.Rollback()).To(Succeed())
+	g.Expect(c.Active()).To(Equal("blue"))
+	configs, err := c.List(schemas.DestinationRule.Type, "default")
+	g.Expect(err).To(BeNil())
+	g.Expect(names(configs)).To(Equal([]string{"ratings", "reviews-blue"}))
+
+	g.Expect(c.Rollback()).To(Succeed())
+	g.Expect(c.Active()).To(Equal(""))
+	g.Expect(c.History()).To(BeEmpty())
+	g.Expect(c.Rollback()).ToNot(Succeed())
+
+	// Each activation and rollback results in one push.
+	g.Expect(pushes).To(Equal(4))
+}
+
+func TestWatchStore(t *testing.T) {
+	g := NewGomegaWithT(t)
+	store := memory.NewController(memory.Make(schemas.Istio))
+	c := release.NewController(store)
+	c.WatchStore("istio-system")
+	stop := make(chan struct{})
+	defer close(stop)
+	go c.Run(stop)
+
+	g.Expect(c.Create(destinationRule("reviews-blue", "blue"))).ToNot(BeEmpty())
+
+	marker := destinationRule("release", "")
+	marker.Namespace = "istio-system"
+	marker.Annotations = map[string]string{release.ActiveAnnotation: "blue"}
+	marker.CreationTimestamp = time.Now()
+	_, err := c.Create(marker)
+	g.Expect(err).To(BeNil())
+	g.Eventually(c.Active, 5*time.Second).Should(Equal("blue"))
+
+	// The annotation of the most recently created config wins.
+	older := destinationRule("older-release", "")
+	older.Namespace = "istio-system"
+	older.Annotations = map[string]string{release.ActiveAnnotation: "red"}
+	older.CreationTimestamp = marker.CreationTimestamp.Add(-time.Hour)
+	_, err = c.Create(older)
+	g.Expect(err).To(BeNil())
+
+	// The annotation in other namespaces is ignored.
+	other := destinationRule("other-release", "")
+	other.Annotations = map[string]string{release.ActiveAnnotation: "yellow"}
+	other.CreationTimestamp = marker.CreationTimestamp.Add(time.Hour)
+	_, err = c.Create(other)
+	g.Expect(err).To(BeNil())
+
+	updated := c.ConfigStoreCache.Get(schemas.DestinationRule.Type, "release", "istio-system")
+	g.Expect(updated).ToNot(BeNil())
+	updated.Annotations = map[string]string{release.ActiveAnnotation: "green"}
+	_, err = c.Update(*updated)
+	g.Expect(err).To(BeNil())
+	g.Eventually(c.Active, 5*time.Second).Should(Equal("green"))
+	g.Consistently(c.Active, 100*time.Millisecond).Should(Equal("green"))
+
+	g.Expect(c.Delete(schemas.DestinationRule.Type, "release", "istio-system")).To(Succeed())
+	g.Eventually(c.Active, 5*time.Second).Should(Equal("red"))
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scoped restricts a config store to the namespaces served by a scoped Pilot.
+package scoped
+
+import (
+	"istio.io/pkg/log"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+// Controller hides the configs of the namespaces outside of a scope from the wrapped config store.
+//
+// The configs of the root namespace of the mesh and the cluster scoped configs apply to all the
+// namespaces, and remain visible. The ServiceEntries remain visible too: they define services,
+// which are imported by the push context of a scoped Pilot when they are exported.
+type Controller struct {
+	model.ConfigStoreCache
+
+	scope         *model.NamespaceScope
+	rootNamespace string
+}
+
+var _ model.ConfigStoreCache = &Controller{}
+
+// NewController wraps the store, restricted to the namespaces of the scope and to the root namespace.
+func NewController(store model.ConfigStoreCache, scope *model.NamespaceScope, rootNamespace string) *Controller {
+	return &Controller{
+		ConfigStoreCache: store,
+		scope:            scope,
+		rootNamespace:    rootNamespace,
+	}
+}
+
+// visible returns true if the config applies to the namespaces of the scope.
+func (c *Controller) visible(cfg *model.Config) bool {
+	return cfg.Type == schemas.ServiceEntry.Type ||
+		cfg.Namespace == "" ||
+		cfg.Namespace == c.rootNamespace ||
+		c.scope.Contains(cfg.Namespace)
+}
+
+// Get implements model.ConfigStore, hiding configs outside of the scope.
+func (c *Controller) Get(typ, name, namespace string) *model.Config {
+	cfg := c.ConfigStoreCache.Get(typ, name, namespace)
+	if cfg == nil || !c.visible(cfg) {
+		return nil
+	}
+	return cfg
+}
+
+// List implements model.ConfigStore, hiding configs outside of the scope.
+func (c *Controller) List(typ, namespace string) ([]model.Config, error) {
+	configs, err := c.ConfigStoreCache.List(typ, namespace)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.Config, 0, len(configs))
+	for i := range configs {
+		if c.visible(&configs[i]) {
+			out = append(out, configs[i])
+		}
+	}
+	return out, nil
+}
+
+// RegisterEventHandler implements model.ConfigStoreCache. Changes to configs outside of the
+// scope are not forwarded, so that they don't cause pushes.
+func (c *Controller) RegisterEventHandler(typ string, handler func(model.Config, model.Config, model.Event)) {
+	c.ConfigStoreCache.RegisterEventHandler(typ, func(old, curr model.Config, event model.Event) {
+		if !c.visible(&curr) {
+			log.Debugf("ignoring %s event for config %s outside of the namespace scope", event, curr.Key())
+			return
+		}
+		handler(old, curr, event)
+	})
+}
@@ -0,0 +1,130 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scoped_test
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pilot/pkg/config/memory"
+	"istio.io/istio/pilot/pkg/config/scoped"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+func destinationRule(name, namespace string) model.Config {
+	return model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:      schemas.DestinationRule.Type,
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: &networking.DestinationRule{Host: "reviews"},
+	}
+}
+
+func keys(configs []model.Config) []string {
+	out := make([]string, 0, len(configs))
+	for _, cfg := range configs {
+		out = append(out, cfg.Namespace+"/"+cfg.Name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func newController(t *testing.T) (*scoped.Controller, chan struct{}) {
+	t.Helper()
+	store := memory.NewController(memory.Make(schemas.Istio))
+	c := scoped.NewController(store, model.NewNamespaceScope([]string{"tenant-a"}, nil), "istio-system")
+	stop := make(chan struct{})
+	go c.Run(stop)
+	for _, cfg := range []model.Config{
+		destinationRule("a", "tenant-a"),
+		destinationRule("b", "tenant-b"),
+		destinationRule("mesh", "istio-system"),
+		{
+			ConfigMeta: model.ConfigMeta{Type: schemas.ServiceEntry.Type, Name: "b", Namespace: "tenant-b"},
+			Spec: &networking.ServiceEntry{
+				Hosts:    []string{"b.example.com"},
+				Ports:    []*networking.Port{{Number: 80, Name: "http", Protocol: "http"}},
+				ExportTo: []string{"*"},
+			},
+		},
+	} {
+		if _, err := store.Create(cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c, stop
+}
+
+func TestScopedConfigs(t *testing.T) {
+	g := NewGomegaWithT(t)
+	c, stop := newController(t)
+	defer close(stop)
+
+	configs, err := c.List(schemas.DestinationRule.Type, model.NamespaceAll)
+	g.Expect(err).To(BeNil())
+	g.Expect(keys(configs)).To(Equal([]string{"istio-system/mesh", "tenant-a/a"}))
+
+	configs, err = c.List(schemas.DestinationRule.Type, "tenant-b")
+	g.Expect(err).To(BeNil())
+	g.Expect(configs).To(BeEmpty())
+
+	g.Expect(c.Get(schemas.DestinationRule.Type, "a", "tenant-a")).ToNot(BeNil())
+	g.Expect(c.Get(schemas.DestinationRule.Type, "b", "tenant-b")).To(BeNil())
+
+	// The service entries define services, which are filtered by the push context.
+	configs, err = c.List(schemas.ServiceEntry.Type, model.NamespaceAll)
+	g.Expect(err).To(BeNil())
+	g.Expect(keys(configs)).To(Equal([]string{"tenant-b/b"}))
+}
+
+func TestScopedEvents(t *testing.T) {
+	g := NewGomegaWithT(t)
+	c, stop := newController(t)
+	defer close(stop)
+
+	events := make(chan string, 10)
+	c.RegisterEventHandler(schemas.DestinationRule.Type, func(_, curr model.Config, _ model.Event) {
+		events <- curr.Namespace + "/" + curr.Name
+	})
+
+	for _, cfg := range []model.Config{destinationRule("b2", "tenant-b"), destinationRule("a2", "tenant-a")} {
+		if _, err := c.Create(cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Events of the initial configs may still be queued, so collect everything.
+	seen := map[string]bool{}
+	timeout := time.After(time.Second)
+	for !seen["tenant-a/a2"] {
+		select {
+		case key := <-events:
+			seen[key] = true
+		case <-timeout:
+			t.Fatal("timed out waiting for event")
+		}
+	}
+	g.Consistently(events, 100*time.Millisecond).ShouldNot(Receive(HavePrefix("tenant-b/")))
+	g.Expect(seen).ToNot(HaveKey("tenant-b/b"))
+	g.Expect(seen).ToNot(HaveKey("tenant-b/b2"))
+}
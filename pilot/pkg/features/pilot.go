@@ -254,6 +254,55 @@ var (
 			"legacy deployments that have not yet been migrated to the new safe regular expressions.",
 	)
 
+	EnableHTTPCompression = env.RegisterBoolVar(
+		"PILOT_ENABLE_HTTP_COMPRESSION",
+		false,
+		"If enabled, pilot will add the gzip filter to all HTTP connection managers, so that responses "+
+			"are compressed for clients that send an Accept-Encoding header allowing gzip. Virtual services can "+
+			"turn the compression on or off for their routes with the networking.istio.io/compression annotation, "+
+			"set to \"on\" or \"off\".",
+	)
+
+	HTTPCompressionContentTypes = env.RegisterStringVar(
+		"PILOT_HTTP_COMPRESSION_CONTENT_TYPES",
+		"",
+		"Comma separated list of response content types that will be compressed when PILOT_ENABLE_HTTP_COMPRESSION "+
+			"is set. If empty, Envoy's default list of text, JSON and JavaScript types is used.",
+	)
+
+	HTTPCompressionMinContentLength = env.RegisterIntVar(
+		"PILOT_HTTP_COMPRESSION_MIN_CONTENT_LENGTH",
+		0,
+		"Minimum response size in bytes that will be compressed when PILOT_ENABLE_HTTP_COMPRESSION is set. "+
+			"If zero, Envoy's default of 30 bytes is used.",
+	)
+
+	HTTPMaxRequestBytes = env.RegisterIntVar(
+		"PILOT_HTTP_MAX_REQUEST_BYTES",
+		0,
+		"If set, pilot will add the buffer filter to all HTTP connection managers, so that complete requests up "+
+			"to this size are buffered before being forwarded upstream. Larger requests are rejected with a 413. "+
+			"Virtual services can override the limit for their routes with the networking.istio.io/maxRequestBytes "+
+			"annotation, where a value of 0 disables buffering. If unset, the filter is only added, disabled by "+
+			"default, when virtual services set the annotation.",
+	)
+
+	EnableVHDS = env.RegisterBoolVar(
+		"PILOT_ENABLE_ON_DEMAND_VHDS",
+		false,
+		"If enabled, pilot will send sidecars route configurations without virtual hosts and serve the "+
+			"virtual hosts on demand over VHDS, as the proxy first sends a request for a host. Clusters "+
+			"are still pushed eagerly, since Envoy cannot yet fetch clusters on demand.",
+	)
+
+	EnableDynamicForwardProxy = env.RegisterBoolVar(
+		"PILOT_ENABLE_DYNAMIC_FORWARD_PROXY",
+		false,
+		"If enabled, HTTP and TLS ports of ServiceEntries with wildcard hosts and resolution NONE are served by "+
+			"Envoy's dynamic forward proxy, which resolves the requested host or SNI with DNS instead of relying on "+
+			"the original destination IP of the connection.",
+	)
+
 	EnableEndpointSliceController = env.RegisterBoolVar(
 		"PILOT_USE_ENDPOINT_SLICE",
 		false,
@@ -56,6 +56,9 @@ type Environment struct {
 	// service registries.
 	mesh.NetworksWatcher
 
+	// NamespaceScope is the set of namespaces served by Pilot, or nil for all namespaces.
+	NamespaceScope *NamespaceScope
+
 	// PushContext holds informations during push generation. It is reset on config change, at the beginning
 	// of the pushAll. It will hold all errors and stats and possibly caches needed during the entire cache computation.
 	// DO NOT USE EXCEPT FOR TESTS AND HANDLING OF NEW CONNECTIONS.
@@ -295,6 +298,10 @@ type NodeMetadata struct {
 	// LocalityLabel defines the locality specified for the pod
 	LocalityLabel string `json:"istio-locality,omitempty"`
 
+	// NodeName is the name of the node where the proxy is running. If not set, Pilot uses the node
+	// of the proxy's service instances.
+	NodeName string `json:"NODE_NAME,omitempty"`
+
 	PolicyCheck                  string `json:"policy.istio.io/check,omitempty"`
 	PolicyCheckRetries           string `json:"policy.istio.io/checkRetries,omitempty"`
 	PolicyCheckBaseRetryWaitTime string `json:"policy.istio.io/checkBaseRetryWaitTime,omitempty"`
@@ -338,6 +345,18 @@ type NodeMetadata struct {
 	// Alpha in 1.1, based on feedback may be turned into an API or change. Set to "1" to enable.
 	HTTP10 string `json:"HTTP10,omitempty"`
 
+	// ProtocolSniffing overrides the mesh wide protocol sniffing settings for the workload when
+	// set to "true" or "false". It is populated from the sidecar.istio.io/protocolSniffing annotation.
+	ProtocolSniffing string `json:"sidecar.istio.io/protocolSniffing,omitempty"`
+
+	// ProtocolDetectionTimeout overrides the protocol detection timeout for the workload, in duration
+	// format (500ms). It is populated from the sidecar.istio.io/protocolDetectionTimeout annotation.
+	ProtocolDetectionTimeout string `json:"sidecar.istio.io/protocolDetectionTimeout,omitempty"`
+
+	// ProtocolSniffingExcludePorts is a comma separated list of ports for which protocol sniffing is
+	// disabled. It is populated from the sidecar.istio.io/protocolSniffingExcludePorts annotation.
+	ProtocolSniffingExcludePorts string `json:"sidecar.istio.io/protocolSniffingExcludePorts,omitempty"`
+
 	// Contains a copy of the raw metadata. This is needed to lookup arbitrary values.
 	// If a value is known ahead of time it should be added to the struct rather than reading from here,
 	Raw map[string]interface{} `json:"-"`
@@ -527,6 +546,14 @@ func (node *Proxy) SetServiceInstances(serviceDiscovery ServiceDiscovery) error
 	return nil
 }
 
+// SetNodeName sets the node name of the proxy from its service instances, if the proxy didn't
+// send it. It is needed to apply the node-local traffic policies of the services.
+func (node *Proxy) SetNodeName() {
+	if node.Metadata.NodeName == "" && len(node.ServiceInstances) > 0 {
+		node.Metadata.NodeName = node.ServiceInstances[0].Endpoint.NodeName
+	}
+}
+
 // SetWorkloadLabels will reset the proxy.WorkloadLabels if `force` = true,
 // otherwise only set it when it is nil.
 func (node *Proxy) SetWorkloadLabels(env *Environment) error {
@@ -14,20 +14,28 @@
 package model
 
 import (
+	"bytes"
+	"fmt"
 	"regexp"
 
+	"github.com/gogo/protobuf/jsonpb"
 	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
 
 	networking "istio.io/api/networking/v1alpha3"
 
 	"istio.io/istio/pkg/config/labels"
 	"istio.io/istio/pkg/config/xds"
+	"istio.io/istio/pkg/envoy/extensions"
 )
 
 // EnvoyFilterWrapper is a wrapper for the EnvoyFilter api object with pre-processed data
 type EnvoyFilterWrapper struct {
 	workloadSelector labels.Instance
 	Patches          map[networking.EnvoyFilter_ApplyTo][]*EnvoyFilterConfigPatchWrapper
+	// Extensions are the hosts of the extension modules referenced by the patches, whose clusters
+	// Envoy fetches the modules from.
+	Extensions []extensions.Module
 }
 
 // EnvoyFilterConfigPatchWrapper is a wrapper over the EnvoyFilter ConfigPatch api object
@@ -41,8 +49,10 @@ type EnvoyFilterConfigPatchWrapper struct {
 	ProxyVersionRegex *regexp.Regexp
 }
 
-// convertToEnvoyFilterWrapper converts from EnvoyFilter config to EnvoyFilterWrapper object
-func convertToEnvoyFilterWrapper(local *Config) *EnvoyFilterWrapper {
+// convertToEnvoyFilterWrapper converts from EnvoyFilter config to EnvoyFilterWrapper object.
+// Patches referencing extension modules which Envoy can't fetch are returned as errors, with their
+// index, instead of being pushed.
+func convertToEnvoyFilterWrapper(local *Config) (*EnvoyFilterWrapper, []error) {
 	localEnvoyFilter := local.Spec.(*networking.EnvoyFilter)
 
 	out := &EnvoyFilterWrapper{}
@@ -50,14 +60,22 @@ func convertToEnvoyFilterWrapper(local *Config) *EnvoyFilterWrapper {
 		out.workloadSelector = localEnvoyFilter.WorkloadSelector.Labels
 	}
 	out.Patches = make(map[networking.EnvoyFilter_ApplyTo][]*EnvoyFilterConfigPatchWrapper)
-	for _, cp := range localEnvoyFilter.ConfigPatches {
+	var errs []error
+	for i, cp := range localEnvoyFilter.ConfigPatches {
 		cpw := &EnvoyFilterConfigPatchWrapper{
 			ApplyTo:   cp.ApplyTo,
 			Match:     cp.Match,
 			Operation: cp.Patch.Operation,
 		}
+		// Extension modules are pushed by URL and digest, Envoy fetches them.
+		value, modules, err := pinExtensions(cp.Patch.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("patch %d: %v", i, err))
+			continue
+		}
+		out.Extensions = append(out.Extensions, modules...)
 		// there won't be an error here because validation catches mismatched types
-		cpw.Value, _ = xds.BuildXDSObjectFromStruct(cp.ApplyTo, cp.Patch.Value)
+		cpw.Value, _ = xds.BuildXDSObjectFromStruct(cp.ApplyTo, value)
 		if cp.Match == nil {
 			// create a match all object
 			cpw.Match = &networking.EnvoyFilter_EnvoyConfigObjectMatch{Context: networking.EnvoyFilter_ANY}
@@ -81,5 +99,26 @@ func convertToEnvoyFilterWrapper(local *Config) *EnvoyFilterWrapper {
 		}
 		out.Patches[cp.ApplyTo] = append(out.Patches[cp.ApplyTo], cpw)
 	}
-	return out
+	return out, errs
+}
+
+// pinExtensions returns the patch value with the extension modules referenced by URL pinned to their
+// digest and cluster, and the hosts of the modules.
+func pinExtensions(value *types.Struct) (*types.Struct, []extensions.Module, error) {
+	if value == nil {
+		return nil, nil, nil
+	}
+	js, err := (&jsonpb.Marshaler{OrigName: true}).MarshalToString(value)
+	if err != nil {
+		return nil, nil, err
+	}
+	pinned, modules, err := extensions.Pin([]byte(js))
+	if err != nil {
+		return nil, nil, err
+	}
+	out := &types.Struct{}
+	if err := jsonpb.Unmarshal(bytes.NewReader(pinned), out); err != nil {
+		return nil, nil, err
+	}
+	return out, modules, nil
 }
@@ -0,0 +1,93 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"sort"
+
+	"istio.io/istio/pkg/config/visibility"
+)
+
+// NamespaceScope is the set of namespaces served by a Pilot scoped to a tenant. A scoped Pilot
+// only uses the configs of its namespaces, only serves the proxies of its namespaces, and only
+// imports the services of other namespaces which are explicitly exported to all namespaces,
+// with the networking.istio.io/exportTo annotation or the exportTo field of ServiceEntries.
+// The default Pilot excludes the namespaces of the scoped Pilots instead, with a scope of all
+// the other namespaces.
+//
+// A nil NamespaceScope contains all the namespaces.
+type NamespaceScope struct {
+	// namespaces are the namespaces of the scope, or nil for all the namespaces which aren't excluded.
+	namespaces map[string]struct{}
+	excluded   map[string]struct{}
+}
+
+// NewNamespaceScope returns the scope of the namespaces, or of all the namespaces if there are none,
+// without the excluded namespaces. It returns nil if there are neither namespaces nor excluded ones.
+func NewNamespaceScope(namespaces, excluded []string) *NamespaceScope {
+	if len(namespaces) == 0 && len(excluded) == 0 {
+		return nil
+	}
+	s := &NamespaceScope{excluded: make(map[string]struct{}, len(excluded))}
+	if len(namespaces) > 0 {
+		s.namespaces = make(map[string]struct{}, len(namespaces))
+		for _, ns := range namespaces {
+			s.namespaces[ns] = struct{}{}
+		}
+	}
+	for _, ns := range excluded {
+		s.excluded[ns] = struct{}{}
+		delete(s.namespaces, ns)
+	}
+	return s
+}
+
+// Contains returns true if the namespace is in the scope.
+func (s *NamespaceScope) Contains(namespace string) bool {
+	if s == nil {
+		return true
+	}
+	if _, f := s.excluded[namespace]; f {
+		return false
+	}
+	if s.namespaces == nil {
+		return true
+	}
+	_, f := s.namespaces[namespace]
+	return f
+}
+
+// Namespaces returns the sorted namespaces of the scope, or nil if it is made of all the namespaces
+// which aren't excluded.
+func (s *NamespaceScope) Namespaces() []string {
+	if s == nil || s.namespaces == nil {
+		return nil
+	}
+	out := make([]string, 0, len(s.namespaces))
+	for ns := range s.namespaces {
+		out = append(out, ns)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ImportsService returns true if the service is in the scope, or is explicitly exported to all
+// namespaces. The default visibility of the services of the mesh config doesn't export them.
+func (s *NamespaceScope) ImportsService(svc *Service) bool {
+	if s.Contains(svc.Attributes.Namespace) {
+		return true
+	}
+	return svc.Attributes.ExportTo[visibility.Public]
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	"istio.io/istio/pkg/config/visibility"
+)
+
+func TestNamespaceScope(t *testing.T) {
+	if NewNamespaceScope(nil, nil) != nil {
+		t.Fatal("expected no scope for no namespaces")
+	}
+
+	var all *NamespaceScope
+	if !all.Contains("any") || all.Namespaces() != nil {
+		t.Error("expected the nil scope to contain all the namespaces")
+	}
+
+	s := NewNamespaceScope([]string{"tenant-b", "tenant-a", "tenant-c"}, []string{"tenant-c"})
+	if !s.Contains("tenant-a") || !s.Contains("tenant-b") || s.Contains("tenant-c") || s.Contains("tenant-d") {
+		t.Errorf("unexpected namespaces in scope %v", s.Namespaces())
+	}
+	if got, want := s.Namespaces(), []string{"tenant-a", "tenant-b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Namespaces() => got %v, want %v", got, want)
+	}
+
+	// The default Pilot excludes the namespaces of the scoped Pilots.
+	excluding := NewNamespaceScope(nil, []string{"tenant-a", "tenant-b"})
+	if excluding.Contains("tenant-a") || excluding.Contains("tenant-b") || !excluding.Contains("default") {
+		t.Error("expected the scope to contain all the namespaces but the excluded ones")
+	}
+	if excluding.Namespaces() != nil {
+		t.Errorf("expected no namespaces for a scope excluding namespaces, got %v", excluding.Namespaces())
+	}
+}
+
+func TestNamespaceScopeImportsService(t *testing.T) {
+	service := func(namespace string, exportTo ...visibility.Instance) *Service {
+		svc := &Service{Attributes: ServiceAttributes{Namespace: namespace, ExportTo: map[visibility.Instance]bool{}}}
+		for _, e := range exportTo {
+			svc.Attributes.ExportTo[e] = true
+		}
+		return svc
+	}
+
+	s := NewNamespaceScope([]string{"tenant-a"}, nil)
+	excluding := NewNamespaceScope(nil, []string{"tenant-b"})
+	cases := []struct {
+		name     string
+		scope    *NamespaceScope
+		service  *Service
+		expected bool
+	}{
+		{"in scope", s, service("tenant-a"), true},
+		{"in scope and private", s, service("tenant-a", visibility.Private), true},
+		{"out of scope", s, service("tenant-b"), false},
+		{"out of scope and private", s, service("tenant-b", visibility.Private), false},
+		{"out of scope and exported", s, service("tenant-b", visibility.Public), true},
+		{"no scope", nil, service("tenant-b"), true},
+		{"not excluded", excluding, service("default"), true},
+		{"excluded", excluding, service("tenant-b"), false},
+		{"excluded and exported", excluding, service("tenant-b", visibility.Public), true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.scope.ImportsService(c.service); got != c.expected {
+				t.Errorf("ImportsService() => got %v, want %v", got, c.expected)
+			}
+		})
+	}
+}
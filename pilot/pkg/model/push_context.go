@@ -21,12 +21,15 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hashicorp/go-multierror"
+
 	authn "istio.io/api/authentication/v1alpha1"
 	meshconfig "istio.io/api/mesh/v1alpha1"
 	networking "istio.io/api/networking/v1alpha3"
 	"istio.io/pkg/monitoring"
 
 	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pkg/config/annotation"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/labels"
@@ -79,6 +82,12 @@ type PushContext struct {
 	// VirtualService related
 	privateVirtualServicesByNamespace map[string][]Config
 	publicVirtualServices             []Config
+	// maxRequestBytesOverride is the largest request buffering limit set by a virtual service for its
+	// routes with the NetworkingMaxRequestBytes annotation, or 0 if there is none.
+	maxRequestBytesOverride uint32
+	// compressionOverrides is true if a virtual service turns the mesh wide response compression on
+	// or off for its routes with the NetworkingCompression annotation.
+	compressionOverrides bool
 
 	// destination rules are of three types:
 	//  namespaceLocalDestRules: all public/private dest rules pertaining to a service defined in a given namespace
@@ -418,6 +427,12 @@ var (
 		"Duplicate subsets across destination rules for same host",
 	)
 
+	// InvalidEnvoyFilterPatches tracks the EnvoyFilter patches not pushed because of invalid extension references.
+	InvalidEnvoyFilterPatches = monitoring.NewGauge(
+		"pilot_envoy_filter_invalid_patches",
+		"EnvoyFilter patches not pushed because their extension modules can't be fetched by the proxies.",
+	)
+
 	// totalVirtualServices tracks the total number of virtual service
 	totalVirtualServices = monitoring.NewGauge(
 		"pilot_virt_services",
@@ -445,6 +460,7 @@ var (
 		ProxyStatusClusterNoInstances,
 		DuplicatedDomains,
 		DuplicatedSubsets,
+		InvalidEnvoyFilterPatches,
 	}
 )
 
@@ -861,6 +877,8 @@ func (ps *PushContext) updateContext(
 	} else {
 		ps.privateVirtualServicesByNamespace = oldPushContext.privateVirtualServicesByNamespace
 		ps.publicVirtualServices = oldPushContext.publicVirtualServices
+		ps.maxRequestBytesOverride = oldPushContext.maxRequestBytesOverride
+		ps.compressionOverrides = oldPushContext.compressionOverrides
 	}
 
 	if destinationRulesChanged {
@@ -930,6 +948,15 @@ func (ps *PushContext) initServiceRegistry(env *Environment) error {
 	}
 	// Sort the services in order of creation.
 	allServices := sortServicesByCreationTime(services)
+	if env.NamespaceScope != nil {
+		imported := make([]*Service, 0, len(allServices))
+		for _, s := range allServices {
+			if env.NamespaceScope.ImportsService(s) {
+				imported = append(imported, s)
+			}
+		}
+		allServices = imported
+	}
 	for _, s := range allServices {
 		ns := s.Attributes.Namespace
 		if len(s.Attributes.ExportTo) == 0 {
@@ -1135,6 +1162,16 @@ func (ps *PushContext) initVirtualServices(env *Environment) error {
 	for _, virtualService := range vservices {
 		ns := virtualService.Namespace
 		rule := virtualService.Spec.(*networking.VirtualService)
+		if value, f := virtualService.Annotations[annotation.NetworkingMaxRequestBytes.Name]; f {
+			if maxRequestBytes, err := annotation.ParseMaxRequestBytes(value); err == nil && maxRequestBytes > ps.maxRequestBytesOverride {
+				ps.maxRequestBytesOverride = maxRequestBytes
+			}
+		}
+		if value, f := virtualService.Annotations[annotation.NetworkingCompression.Name]; f {
+			if enabled, err := annotation.ParseCompression(value); err == nil && enabled != features.EnableHTTPCompression.Get() {
+				ps.compressionOverrides = true
+			}
+		}
 		if len(rule.ExportTo) == 0 {
 			// No exportTo in virtualService. Use the global default
 			// TODO: We currently only honor ., * and ~
@@ -1161,6 +1198,18 @@ func (ps *PushContext) initVirtualServices(env *Environment) error {
 	return nil
 }
 
+// MaxRequestBytesOverride returns the largest request buffering limit a virtual service sets for its
+// routes, or 0 if there is none. The buffer filter is needed even when it is disabled mesh wide.
+func (ps *PushContext) MaxRequestBytesOverride() uint32 {
+	return ps.maxRequestBytesOverride
+}
+
+// HasCompressionOverrides returns true if a virtual service turns the mesh wide response compression
+// on or off for its routes.
+func (ps *PushContext) HasCompressionOverrides() bool {
+	return ps.compressionOverrides
+}
+
 func (ps *PushContext) initDefaultExportMaps() {
 	ps.defaultDestinationRuleExportTo = make(map[visibility.Instance]bool)
 	if ps.Mesh.DefaultDestinationRuleExportTo != nil {
@@ -1427,7 +1476,13 @@ func (ps *PushContext) initEnvoyFilters(env *Environment) error {
 
 	ps.envoyFiltersByNamespace = make(map[string][]*EnvoyFilterWrapper)
 	for _, envoyFilterConfig := range envoyFilterConfigs {
-		efw := convertToEnvoyFilterWrapper(&envoyFilterConfig)
+		efw, errs := convertToEnvoyFilterWrapper(&envoyFilterConfig)
+		if len(errs) > 0 {
+			key := envoyFilterConfig.Namespace + "/" + envoyFilterConfig.Name
+			msg := multierror.Append(nil, errs...).Error()
+			log.Warnf("envoy filter %s has invalid patches: %s", key, msg)
+			ps.AddMetric(InvalidEnvoyFilterPatches, key, nil, msg)
+		}
 		if _, exists := ps.envoyFiltersByNamespace[envoyFilterConfig.Namespace]; !exists {
 			ps.envoyFiltersByNamespace[envoyFilterConfig.Namespace] = make([]*EnvoyFilterWrapper, 0)
 		}
@@ -17,9 +17,14 @@ package model
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/types"
+	"github.com/golang/protobuf/proto"
+
 	authn "istio.io/api/authentication/v1alpha1"
 	meshconfig "istio.io/api/mesh/v1alpha1"
 	networking "istio.io/api/networking/v1alpha3"
@@ -31,6 +36,7 @@ import (
 	"istio.io/istio/pkg/config/mesh"
 	"istio.io/istio/pkg/config/schema"
 	"istio.io/istio/pkg/config/schemas"
+	"istio.io/istio/pkg/envoy/extensions"
 )
 
 func TestMergeUpdateRequest(t *testing.T) {
@@ -490,6 +496,70 @@ func TestEnvoyFilters(t *testing.T) {
 
 }
 
+func TestEnvoyFilterInvalidExtension(t *testing.T) {
+	patch := func(js string) *networking.EnvoyFilter_EnvoyConfigObjectPatch {
+		value := &types.Struct{}
+		if err := jsonpb.UnmarshalString(js, value); err != nil {
+			t.Fatal(err)
+		}
+		return &networking.EnvoyFilter_EnvoyConfigObjectPatch{
+			ApplyTo: networking.EnvoyFilter_HTTP_FILTER,
+			Patch: &networking.EnvoyFilter_Patch{
+				Operation: networking.EnvoyFilter_Patch_INSERT_BEFORE,
+				Value:     value,
+			},
+		}
+	}
+	remote := `{"name": "envoy.wasm", "config": {"config": {"vm_config": {"code": {"remote": ` +
+		`{"http_uri": {"uri": "https://example.com/filter.wasm"}, "sha256": "%s"}}}}}}`
+	digest := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	config := &Config{
+		ConfigMeta: ConfigMeta{Name: "wasm", Namespace: "test-ns"},
+		Spec: &networking.EnvoyFilter{
+			ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{
+				patch(fmt.Sprintf(remote, digest)),
+				patch(fmt.Sprintf(remote, "")),
+			},
+		},
+	}
+
+	efw, errs := convertToEnvoyFilterWrapper(config)
+	patches := efw.Patches[networking.EnvoyFilter_HTTP_FILTER]
+	if len(patches) != 1 {
+		t.Fatalf("expected the valid patch only, got %d patches", len(patches))
+	}
+	// The reference is pinned to the cluster of its host, for Envoy to fetch the module.
+	got := proto.CompactTextString(patches[0].Value)
+	for _, want := range []string{digest, "https://example.com/filter.wasm", "extension|example.com|443", "60s"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected the patch to contain %s, got %s", want, got)
+		}
+	}
+	if want := []extensions.Module{{Host: "example.com", Port: 443, TLS: true}}; !reflect.DeepEqual(efw.Extensions, want) {
+		t.Errorf("got extensions %v, want %v", efw.Extensions, want)
+	}
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "patch 1: ") ||
+		!strings.Contains(errs[0].Error(), "has no SHA-256 digest") {
+		t.Fatalf("expected the invalid patch to be reported, got %v", errs)
+	}
+
+	// The invalid patch is reported in the push status.
+	store := newFakeStore()
+	config.Type = schemas.EnvoyFilter.Type
+	if _, err := store.Create(*config); err != nil {
+		t.Fatal(err)
+	}
+	ps := NewPushContext()
+	env := &Environment{IstioConfigStore: MakeIstioStore(store)}
+	if err := ps.initEnvoyFilters(env); err != nil {
+		t.Fatal(err)
+	}
+	status := ps.ProxyStatus[InvalidEnvoyFilterPatches.Name()]
+	if msg := status["test-ns/wasm"].Message; !strings.Contains(msg, "patch 1: ") {
+		t.Errorf("expected the invalid patch in the push status, got %v", status)
+	}
+}
+
 func TestSidecarScope(t *testing.T) {
 	ps := NewPushContext()
 	env := &Environment{Watcher: mesh.NewFixedWatcher(&meshconfig.MeshConfig{RootNamespace: "istio-system"})}
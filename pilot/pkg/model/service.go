@@ -330,6 +330,9 @@ type IstioEndpoint struct {
 
 	// TLSMode endpoint is injected with istio sidecar and ready to configure Istio mTLS
 	TLSMode string
+
+	// NodeName is the name of the node where the endpoint is running, if known.
+	NodeName string
 }
 
 // ServiceAttributes represents a group of custom attributes of the service.
@@ -355,6 +358,14 @@ type ServiceAttributes struct {
 	// Used by the aggregator to aggregate the Attributes.ClusterExternalAddresses
 	// for clusters where the service resides
 	ClusterExternalAddresses map[string][]string
+
+	// InternalTrafficLocal restricts the endpoints of the service to the ones on the node of the
+	// calling proxy, as the "Local" internal traffic policy of Kubernetes.
+	InternalTrafficLocal bool
+
+	// TopologyKeys is the ordered list of the Kubernetes service topology keys. Proxies are sent the
+	// endpoints matching their own value of the first key that any endpoint matches.
+	TopologyKeys []string
 }
 
 // ServiceDiscovery enumerates Istio service instances.
@@ -25,6 +25,8 @@ import (
 	v2Cluster "github.com/envoyproxy/go-control-plane/envoy/api/v2/cluster"
 	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	endpoint "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
+	dfpcluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/dynamic_forward_proxy/v2alpha"
+	dnscache "github.com/envoyproxy/go-control-plane/envoy/config/common/dynamic_forward_proxy/v2alpha"
 	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type"
 	"github.com/gogo/protobuf/types"
 	"github.com/golang/protobuf/ptypes"
@@ -59,6 +61,12 @@ const (
 	// ManagementClusterHostname indicates the hostname used for building inbound clusters for management ports
 	ManagementClusterHostname = "mgmtCluster"
 
+	// DynamicForwardProxyClusterType is the cluster type resolving the requested host with DNS.
+	DynamicForwardProxyClusterType = "envoy.clusters.dynamic_forward_proxy"
+
+	// DynamicForwardProxyCacheName is the DNS cache shared by the dynamic forward proxy filter and clusters.
+	DynamicForwardProxyCacheName = "dynamic_forward_proxy_cache"
+
 	// StatName patterns
 	serviceStatPattern         = "%SERVICE%"
 	serviceFQDNStatPattern     = "%SERVICE_FQDN%"
@@ -110,6 +118,7 @@ func (configgen *ConfigGeneratorImpl) BuildClusters(proxy *model.Proxy, push *mo
 	instances := proxy.ServiceInstances
 
 	outboundClusters := configgen.buildOutboundClusters(proxy, push)
+	outboundClusters = append(outboundClusters, buildExtensionClusters(push, proxy)...)
 
 	switch proxy.Type {
 	case model.SidecarProxy:
@@ -192,6 +201,13 @@ func (configgen *ConfigGeneratorImpl) buildOutboundClusters(proxy *model.Proxy,
 
 			lbEndpoints := buildLocalityLbEndpoints(push, networkView, service, port.Port, nil)
 
+			// The dynamic forward proxy sets the SNI of upstream TLS connections to the requested host.
+			dynamicForwardProxy := isDynamicForwardProxyService(proxy, service, port)
+			simpleTLSSni := string(service.Hostname)
+			if dynamicForwardProxy {
+				simpleTLSSni = ""
+			}
+
 			// create default cluster
 			discoveryType := convertResolution(proxy, service.Resolution)
 			clusterName := model.BuildSubsetKey(model.TrafficDirectionOutbound, "", service.Hostname, port.Port)
@@ -225,7 +241,7 @@ func (configgen *ConfigGeneratorImpl) buildOutboundClusters(proxy *model.Proxy,
 				port:            port,
 				serviceAccounts: serviceAccounts,
 				istioMtlsSni:    defaultSni,
-				simpleTLSSni:    string(service.Hostname),
+				simpleTLSSni:    simpleTLSSni,
 				clusterMode:     DefaultClusterMode,
 				direction:       model.TrafficDirectionOutbound,
 				proxy:           proxy,
@@ -234,6 +250,9 @@ func (configgen *ConfigGeneratorImpl) buildOutboundClusters(proxy *model.Proxy,
 			}
 
 			applyTrafficPolicy(opts, proxy)
+			if dynamicForwardProxy {
+				applyDynamicForwardProxy(push, defaultCluster)
+			}
 			defaultCluster.Metadata = clusterMetadata
 			for _, subset := range destinationRule.Subsets {
 				subsetClusterName := model.BuildSubsetKey(model.TrafficDirectionOutbound, subset.Name, service.Hostname, port.Port)
@@ -257,7 +276,7 @@ func (configgen *ConfigGeneratorImpl) buildOutboundClusters(proxy *model.Proxy,
 					port:            port,
 					serviceAccounts: serviceAccounts,
 					istioMtlsSni:    defaultSni,
-					simpleTLSSni:    string(service.Hostname),
+					simpleTLSSni:    simpleTLSSni,
 					clusterMode:     DefaultClusterMode,
 					direction:       model.TrafficDirectionOutbound,
 					proxy:           proxy,
@@ -273,7 +292,7 @@ func (configgen *ConfigGeneratorImpl) buildOutboundClusters(proxy *model.Proxy,
 					port:            port,
 					serviceAccounts: serviceAccounts,
 					istioMtlsSni:    defaultSni,
-					simpleTLSSni:    string(service.Hostname),
+					simpleTLSSni:    simpleTLSSni,
 					clusterMode:     DefaultClusterMode,
 					direction:       model.TrafficDirectionOutbound,
 					proxy:           proxy,
@@ -281,6 +300,9 @@ func (configgen *ConfigGeneratorImpl) buildOutboundClusters(proxy *model.Proxy,
 					serviceMTLSMode: serviceMTLSMode,
 				}
 				applyTrafficPolicy(opts, proxy)
+				if dynamicForwardProxy {
+					applyDynamicForwardProxy(push, subsetCluster)
+				}
 
 				updateEds(subsetCluster)
 
@@ -1249,6 +1271,89 @@ func buildBlackHoleCluster(push *model.PushContext) *apiv2.Cluster {
 	return cluster
 }
 
+// buildExtensionClusters returns the clusters Envoy fetches the extension modules referenced by the
+// EnvoyFilters of the proxy from. Envoy verifies the SHA-256 digest of the modules, the certificates
+// of the hosts are not.
+func buildExtensionClusters(push *model.PushContext, proxy *model.Proxy) []*apiv2.Cluster {
+	var clusters []*apiv2.Cluster
+	seen := make(map[string]bool)
+	for _, efw := range push.EnvoyFilters(proxy) {
+		for _, m := range efw.Extensions {
+			name := m.ClusterName()
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			cluster := &apiv2.Cluster{
+				Name:                 name,
+				ClusterDiscoveryType: &apiv2.Cluster_Type{Type: apiv2.Cluster_STRICT_DNS},
+				ConnectTimeout:       gogo.DurationToProtoDuration(push.Mesh.ConnectTimeout),
+				LbPolicy:             apiv2.Cluster_ROUND_ROBIN,
+				DnsLookupFamily:      apiv2.Cluster_V4_ONLY,
+				LoadAssignment: &apiv2.ClusterLoadAssignment{
+					ClusterName: name,
+					Endpoints:   buildInboundLocalityLbEndpoints(m.Host, uint32(m.Port)),
+				},
+			}
+			if m.TLS {
+				cluster.TlsContext = &auth.UpstreamTlsContext{Sni: m.Host}
+			}
+			clusters = append(clusters, cluster)
+		}
+	}
+	return clusters
+}
+
+// isDynamicForwardProxyService checks whether the port of the service is served by the dynamic forward proxy.
+// Only HTTP and TLS ports of wildcard ServiceEntries without resolution qualify, as the dynamic forward proxy
+// resolves the host of the request or the SNI of the connection. TCP ports keep using the original destination.
+func isDynamicForwardProxyService(proxy *model.Proxy, service *model.Service, port *model.Port) bool {
+	return util.IsDynamicForwardProxyEnabled(proxy) && service.MeshExternal && service.Resolution == model.Passthrough &&
+		service.Hostname.IsWildCarded() && (port.Protocol.IsHTTP() || port.Protocol.IsTLS())
+}
+
+// hasDynamicForwardProxyService checks whether any service visible to the proxy is served by the dynamic
+// forward proxy on the given port, or on any port if the port is 0.
+func hasDynamicForwardProxyService(proxy *model.Proxy, push *model.PushContext, port int) bool {
+	if !util.IsDynamicForwardProxyEnabled(proxy) {
+		return false
+	}
+	for _, service := range push.Services(proxy) {
+		for _, p := range service.Ports {
+			if (port == 0 || p.Port == port) && isDynamicForwardProxyService(proxy, service, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyDynamicForwardProxy turns the cluster into a dynamic forward proxy cluster, whose hosts are
+// resolved with DNS from the host of the request rather than the original destination of the connection.
+func applyDynamicForwardProxy(push *model.PushContext, cluster *apiv2.Cluster) {
+	cluster.ClusterDiscoveryType = &apiv2.Cluster_ClusterType{
+		ClusterType: &apiv2.Cluster_CustomClusterType{
+			Name: DynamicForwardProxyClusterType,
+			TypedConfig: util.MessageToAny(&dfpcluster.ClusterConfig{
+				DnsCacheConfig: buildDynamicForwardProxyDNSCache(push),
+			}),
+		},
+	}
+	cluster.LbPolicy = apiv2.Cluster_CLUSTER_PROVIDED
+	cluster.LoadAssignment = nil
+	cluster.EdsClusterConfig = nil
+}
+
+// buildDynamicForwardProxyDNSCache builds the DNS cache of the dynamic forward proxy. Envoy requires the
+// filter and all clusters sharing the cache to use the same configuration.
+func buildDynamicForwardProxyDNSCache(push *model.PushContext) *dnscache.DnsCacheConfig {
+	return &dnscache.DnsCacheConfig{
+		Name:            DynamicForwardProxyCacheName,
+		DnsLookupFamily: apiv2.Cluster_V4_ONLY,
+		DnsRefreshRate:  gogo.DurationToProtoDuration(push.Mesh.DnsRefreshRate),
+	}
+}
+
 // generates a cluster that sends traffic to the original destination.
 // This cluster is used to catch all traffic to unknown listener ports
 func buildDefaultPassthroughCluster(push *model.PushContext, proxy *model.Proxy) *apiv2.Cluster {
@@ -26,6 +26,7 @@ import (
 
 	apiv2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
 	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	"github.com/gogo/protobuf/jsonpb"
 	"github.com/gogo/protobuf/proto"
 	"github.com/gogo/protobuf/types"
 	. "github.com/onsi/gomega"
@@ -1412,6 +1413,101 @@ func TestClusterDiscoveryTypeAndLbPolicyPassthroughIstioVersion12(t *testing.T)
 	g.Expect(clusters[0].EdsClusterConfig).To(BeNil())
 }
 
+func TestClusterDynamicForwardProxy(t *testing.T) {
+	_ = os.Setenv(features.EnableDynamicForwardProxy.Name, "true")
+	defer func() { _ = os.Unsetenv(features.EnableDynamicForwardProxy.Name) }()
+
+	findCluster := func(clusters []*apiv2.Cluster, name string) *apiv2.Cluster {
+		for _, c := range clusters {
+			if c.Name == name {
+				return c
+			}
+		}
+		return nil
+	}
+
+	cases := []struct {
+		name     string
+		host     string
+		external bool
+		destRule *networking.DestinationRule
+		dfp      bool
+		sni      string
+	}{
+		{
+			name:     "http egress",
+			host:     "*.example.org",
+			external: true,
+			destRule: &networking.DestinationRule{Host: "*.example.org"},
+			dfp:      true,
+		},
+		{
+			name:     "tls origination uses the requested host as sni",
+			host:     "*.example.org",
+			external: true,
+			destRule: &networking.DestinationRule{
+				Host: "*.example.org",
+				TrafficPolicy: &networking.TrafficPolicy{
+					Tls: &networking.TLSSettings{Mode: networking.TLSSettings_SIMPLE},
+				},
+			},
+			dfp: true,
+		},
+		{
+			name:     "tls origination with explicit sni",
+			host:     "*.example.org",
+			external: true,
+			destRule: &networking.DestinationRule{
+				Host: "*.example.org",
+				TrafficPolicy: &networking.TrafficPolicy{
+					Tls: &networking.TLSSettings{Mode: networking.TLSSettings_SIMPLE, Sni: "api.example.org"},
+				},
+			},
+			dfp: true,
+			sni: "api.example.org",
+		},
+		{
+			name:     "non wildcard host",
+			host:     "api.example.org",
+			external: true,
+			destRule: &networking.DestinationRule{Host: "api.example.org"},
+		},
+		{
+			name:     "mesh internal",
+			host:     "*.example.org",
+			destRule: &networking.DestinationRule{Host: "*.example.org"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+
+			clusters, err := buildTestClustersWithAuthnPolicy(tt.host, model.Passthrough, tt.external, model.SidecarProxy, nil,
+				testMesh, tt.destRule, nil)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			httpCluster := findCluster(clusters, "outbound|8080||"+tt.host)
+			g.Expect(httpCluster).NotTo(BeNil())
+			if !tt.dfp {
+				g.Expect(httpCluster.GetType()).To(Equal(apiv2.Cluster_ORIGINAL_DST))
+				return
+			}
+			g.Expect(httpCluster.GetClusterType().GetName()).To(Equal(DynamicForwardProxyClusterType))
+			g.Expect(httpCluster.LbPolicy).To(Equal(apiv2.Cluster_CLUSTER_PROVIDED))
+			g.Expect(httpCluster.LoadAssignment).To(BeNil())
+			if tt.destRule.GetTrafficPolicy().GetTls() != nil {
+				g.Expect(httpCluster.TlsContext).NotTo(BeNil())
+				g.Expect(httpCluster.TlsContext.Sni).To(Equal(tt.sni))
+			}
+
+			// Ports which are neither HTTP nor TLS have no host to resolve, they keep the original destination.
+			autoCluster := findCluster(clusters, "outbound|9090||"+tt.host)
+			g.Expect(autoCluster).NotTo(BeNil())
+			g.Expect(autoCluster.GetType()).To(Equal(apiv2.Cluster_ORIGINAL_DST))
+		})
+	}
+}
+
 func TestBuildClustersDefaultCircuitBreakerThresholds(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -2047,3 +2143,43 @@ func TestApplyLoadBalancer(t *testing.T) {
 	}
 
 }
+
+func TestBuildExtensionClusters(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	value := &types.Struct{}
+	g.Expect(jsonpb.UnmarshalString(`{"name": "envoy.filters.http.wasm", "config": {"config": {"vm_config": {"code": {"remote": {
+		"http_uri": {"uri": "https://example.com/filter.wasm"},
+		"sha256": "b7a5b2b9b0fc2d7ebc64b1b5d4fad5ba8db1aa6d8a3cb0d3f48f7e6df0e1d7a2"}}}}}}`, value)).To(Succeed())
+	patch := &networking.EnvoyFilter_EnvoyConfigObjectPatch{
+		ApplyTo: networking.EnvoyFilter_HTTP_FILTER,
+		Patch:   &networking.EnvoyFilter_Patch{Operation: networking.EnvoyFilter_Patch_INSERT_BEFORE, Value: value},
+	}
+	configStore := &fakes.IstioConfigStore{
+		ListStub: func(typ, namespace string) (configs []model.Config, e error) {
+			if typ != schemas.EnvoyFilter.Type {
+				return nil, nil
+			}
+			return []model.Config{{
+				ConfigMeta: model.ConfigMeta{Name: "wasm", Namespace: TestServiceNamespace},
+				// Both patches refer to the same host, which is served by a single cluster.
+				Spec: &networking.EnvoyFilter{ConfigPatches: []*networking.EnvoyFilter_EnvoyConfigObjectPatch{patch, patch}},
+			}}, nil
+		},
+	}
+	env := newTestEnvironment(&fakes.ServiceDiscovery{}, testMesh, configStore)
+	proxy := &model.Proxy{Type: model.SidecarProxy, ConfigNamespace: TestServiceNamespace}
+
+	clusters := buildExtensionClusters(env.PushContext, proxy)
+	g.Expect(clusters).To(HaveLen(1))
+	cluster := clusters[0]
+	g.Expect(cluster.Name).To(Equal("extension|example.com|443"))
+	g.Expect(cluster.GetType()).To(Equal(apiv2.Cluster_STRICT_DNS))
+	g.Expect(cluster.TlsContext.GetSni()).To(Equal("example.com"))
+	address := cluster.LoadAssignment.Endpoints[0].LbEndpoints[0].GetEndpoint().Address.GetSocketAddress()
+	g.Expect(address.Address).To(Equal("example.com"))
+	g.Expect(address.GetPortValue()).To(Equal(uint32(443)))
+
+	proxy.ConfigNamespace = "other"
+	g.Expect(buildExtensionClusters(env.PushContext, proxy)).To(BeEmpty())
+}
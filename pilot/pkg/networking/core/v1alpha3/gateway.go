@@ -302,6 +302,7 @@ func (configgen *ConfigGeneratorImpl) buildGatewayHTTPRouteConfig(node *model.Pr
 	}
 
 	util.SortVirtualHosts(virtualHosts)
+	istio_route.DisableBufferByDefault(push, virtualHosts)
 
 	routeCfg := &xdsapi.RouteConfiguration{
 		// Retain the routeName as its used by EnvoyFilter patching logic
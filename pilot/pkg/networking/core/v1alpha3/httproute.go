@@ -91,6 +91,8 @@ func (configgen *ConfigGeneratorImpl) buildSidecarInboundHTTPRouteConfig(
 		Routes:  []*route.Route{defaultRoute},
 	}
 
+	istio_route.DisableBufferByDefault(push, []*route.VirtualHost{inboundVHost})
+
 	r := &xdsapi.RouteConfiguration{
 		Name:             clusterName,
 		VirtualHosts:     []*route.VirtualHost{inboundVHost},
@@ -218,6 +220,8 @@ func (configgen *ConfigGeneratorImpl) buildSidecarOutboundHTTPRouteConfig(node *
 		}
 	}
 
+	istio_route.DisableBufferByDefault(push, virtualHosts)
+
 	out := &xdsapi.RouteConfiguration{
 		Name:             routeName,
 		VirtualHosts:     virtualHosts,
@@ -29,12 +29,17 @@ import (
 	listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
 	accesslogconfig "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v2"
 	accesslog "github.com/envoyproxy/go-control-plane/envoy/config/filter/accesslog/v2"
+	buffer "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/buffer/v2"
+	dfpfilter "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/dynamic_forward_proxy/v2alpha"
 	grpc_stats "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/grpc_stats/v2alpha"
+	gzip "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/gzip/v2"
+	lua "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/lua/v2"
 	http_conn "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
 	envoy_type "github.com/envoyproxy/go-control-plane/envoy/type"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/golang/protobuf/ptypes"
 	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/golang/protobuf/ptypes/wrappers"
 
 	meshconfig "istio.io/api/mesh/v1alpha1"
 	networking "istio.io/api/networking/v1alpha3"
@@ -43,6 +48,7 @@ import (
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/core/v1alpha3/envoyfilter"
+	istio_route "istio.io/istio/pilot/pkg/networking/core/v1alpha3/route"
 	"istio.io/istio/pilot/pkg/networking/plugin"
 	"istio.io/istio/pilot/pkg/networking/util"
 	authn_model "istio.io/istio/pilot/pkg/security/model"
@@ -145,6 +151,13 @@ const (
 
 	// Alpn HTTP filter name which will override the ALPN for upstream TLS connection.
 	AlpnFilterName = "istio.alpn"
+
+	// OnDemandFilterName is the HTTP filter which makes Envoy request unknown virtual hosts over VHDS.
+	OnDemandFilterName = "envoy.filters.http.on_demand"
+
+	// DynamicForwardProxyFilterName is the HTTP filter which resolves the host of requests routed to
+	// dynamic forward proxy clusters.
+	DynamicForwardProxyFilterName = "envoy.filters.http.dynamic_forward_proxy"
 )
 
 type FilterChainMatchOptions struct {
@@ -474,7 +487,7 @@ func (configgen *ConfigGeneratorImpl) buildSidecarInboundListeners(
 			}
 
 			pluginParams := &plugin.InputParams{
-				ListenerProtocol: plugin.ModelProtocolToListenerProtocol(node, instance.ServicePort.Protocol,
+				ListenerProtocol: plugin.ModelPortToListenerProtocol(node, int(endpoint.EndpointPort), instance.ServicePort.Protocol,
 					core.TrafficDirection_INBOUND),
 				DeprecatedListenerCategory: networking.EnvoyFilter_DeprecatedListenerMatch_SIDECAR_INBOUND,
 				Node:                       node,
@@ -560,7 +573,7 @@ func (configgen *ConfigGeneratorImpl) buildSidecarInboundListeners(
 			// Validation ensures that the protocol specified in Sidecar.ingress
 			// is always a valid known protocol
 			pluginParams := &plugin.InputParams{
-				ListenerProtocol: plugin.ModelProtocolToListenerProtocol(node, listenPort.Protocol,
+				ListenerProtocol: plugin.ModelPortToListenerProtocol(node, listenPort.Port, listenPort.Protocol,
 					core.TrafficDirection_INBOUND),
 				DeprecatedListenerCategory: networking.EnvoyFilter_DeprecatedListenerMatch_SIDECAR_INBOUND,
 				Node:                       node,
@@ -924,7 +937,7 @@ func (configgen *ConfigGeneratorImpl) buildSidecarOutboundListeners(node *model.
 
 				// The listener protocol is determined by the protocol of egress listener port.
 				pluginParams := &plugin.InputParams{
-					ListenerProtocol: plugin.ModelProtocolToListenerProtocol(node, listenPort.Protocol,
+					ListenerProtocol: plugin.ModelPortToListenerProtocol(node, listenPort.Port, listenPort.Protocol,
 						core.TrafficDirection_OUTBOUND),
 					DeprecatedListenerCategory: networking.EnvoyFilter_DeprecatedListenerMatch_SIDECAR_OUTBOUND,
 					Node:                       node,
@@ -983,7 +996,7 @@ func (configgen *ConfigGeneratorImpl) buildSidecarOutboundListeners(node *model.
 
 					// The listener protocol is determined by the protocol of service port.
 					pluginParams := &plugin.InputParams{
-						ListenerProtocol: plugin.ModelProtocolToListenerProtocol(node, servicePort.Protocol,
+						ListenerProtocol: plugin.ModelPortToListenerProtocol(node, servicePort.Port, servicePort.Protocol,
 							core.TrafficDirection_OUTBOUND),
 						DeprecatedListenerCategory: networking.EnvoyFilter_DeprecatedListenerMatch_SIDECAR_OUTBOUND,
 						Node:                       node,
@@ -1087,6 +1100,8 @@ func (configgen *ConfigGeneratorImpl) buildHTTPProxy(node *model.Proxy,
 			httpOpts: &httpListenerOpts{
 				rds:              RDSHttpProxy,
 				useRemoteAddress: false,
+				// The HTTP proxy routes to all services, whatever their port.
+				dynamicForwardProxy: hasDynamicForwardProxyService(node, push, 0),
 				connectionManager: &http_conn.HttpConnectionManager{
 					HttpProtocolOptions: httpOpts,
 				},
@@ -1179,22 +1194,27 @@ func (configgen *ConfigGeneratorImpl) buildSidecarOutboundHTTPListenerOptsForPor
 
 	// No conflicts. Add a http filter chain option to the listenerOpts
 	var rdsName string
+	var dynamicForwardProxy bool
 	if pluginParams.Port.Port == 0 {
 		rdsName = listenerOpts.bind // use the UDS as a rds name
 	} else {
 		if pluginParams.ListenerProtocol == plugin.ListenerProtocolAuto &&
 			util.IsProtocolSniffingEnabledForOutbound(node) && listenerOpts.bind != actualWildcard && pluginParams.Service != nil {
 			rdsName = fmt.Sprintf("%s:%d", pluginParams.Service.Hostname, pluginParams.Port.Port)
+			dynamicForwardProxy = isDynamicForwardProxyService(node, pluginParams.Service, pluginParams.Port)
 		} else {
 			rdsName = fmt.Sprintf("%d", pluginParams.Port.Port)
+			// The route config of the port holds the routes of all services on the port.
+			dynamicForwardProxy = hasDynamicForwardProxyService(node, pluginParams.Push, pluginParams.Port.Port)
 		}
 	}
 	httpOpts := &httpListenerOpts{
 		// Set useRemoteAddress to true for side car outbound listeners so that it picks up the localhost address of the sender,
 		// which is an internal address, so that trusted headers are not sanitized. This helps to retain the timeout headers
 		// such as "x-envoy-upstream-rq-timeout-ms" set by the calling application.
-		useRemoteAddress: features.UseRemoteAddress.Get(),
-		rds:              rdsName,
+		useRemoteAddress:    features.UseRemoteAddress.Get(),
+		rds:                 rdsName,
+		dynamicForwardProxy: dynamicForwardProxy,
 	}
 
 	if features.HTTP10 || pluginParams.Node.Metadata.HTTP10 == "1" {
@@ -1714,6 +1734,86 @@ func buildSidecarInboundMgmtListeners(node *model.Proxy, push *model.PushContext
 	return listeners
 }
 
+// compressionScript runs before the gzip filter when virtual services turn the compression on or off
+// for their routes. The gzip filter has no per route configuration, so the script drops the
+// Accept-Encoding header of the requests to the routes which don't compress their responses, in which
+// case neither the gzip filter nor the upstream compresses them. The routes without the compression
+// metadata use the mesh wide setting.
+const compressionScript = `function envoy_on_request(request_handle)
+  local compression = request_handle:metadata():get("%s")
+  if compression == nil then
+    compression = "%s"
+  end
+  if compression == "off" then
+    request_handle:headers():remove("accept-encoding")
+  end
+end
+`
+
+// buildCompressionAndBufferFilters returns the gzip and buffer HTTP filters enabled by the
+// mesh wide defaults. The gzip filter is also installed, along with the Lua filter selecting the
+// routes to compress, when virtual services turn the compression on or off for their routes.
+// The buffer filter is also installed, disabled on the virtual hosts, when virtual services
+// override the request buffering limit of their routes.
+func buildCompressionAndBufferFilters(push *model.PushContext) []*http_conn.HttpFilter {
+	filters := make([]*http_conn.HttpFilter, 0, 3)
+
+	compression := features.EnableHTTPCompression.Get()
+	if push.HasCompressionOverrides() {
+		defaultCompression := "off"
+		if compression {
+			defaultCompression = "on"
+		}
+		filters = append(filters, &http_conn.HttpFilter{
+			Name: wellknown.Lua,
+			ConfigType: &http_conn.HttpFilter_TypedConfig{
+				TypedConfig: util.MessageToAny(&lua.Lua{
+					InlineCode: fmt.Sprintf(compressionScript, istio_route.CompressionMetadataKey, defaultCompression),
+				}),
+			},
+		})
+		compression = true
+	}
+	if compression {
+		gzipConfig := &gzip.Gzip{}
+		if contentTypes := features.HTTPCompressionContentTypes.Get(); contentTypes != "" {
+			for _, contentType := range strings.Split(contentTypes, ",") {
+				if contentType = strings.TrimSpace(contentType); contentType != "" {
+					gzipConfig.ContentType = append(gzipConfig.ContentType, contentType)
+				}
+			}
+		}
+		if minLength := features.HTTPCompressionMinContentLength.Get(); minLength > 0 {
+			gzipConfig.ContentLength = &wrappers.UInt32Value{Value: uint32(minLength)}
+		}
+		filters = append(filters, &http_conn.HttpFilter{
+			Name:       wellknown.Gzip,
+			ConfigType: &http_conn.HttpFilter_TypedConfig{TypedConfig: util.MessageToAny(gzipConfig)},
+		})
+	}
+
+	var maxRequestBytes uint32
+	if limit := features.HTTPMaxRequestBytes.Get(); limit > 0 {
+		maxRequestBytes = uint32(limit)
+	} else {
+		// The filter requires a limit, although every virtual host disables it and the routes
+		// enabling it set their own limit, so use the largest of them.
+		maxRequestBytes = push.MaxRequestBytesOverride()
+	}
+	if maxRequestBytes > 0 {
+		filters = append(filters, &http_conn.HttpFilter{
+			Name: wellknown.Buffer,
+			ConfigType: &http_conn.HttpFilter_TypedConfig{
+				TypedConfig: util.MessageToAny(&buffer.Buffer{
+					MaxRequestBytes: &wrappers.UInt32Value{Value: maxRequestBytes},
+				}),
+			},
+		})
+	}
+
+	return filters
+}
+
 // httpListenerOpts are options for an HTTP listener
 type httpListenerOpts struct {
 	routeConfig *xdsapi.RouteConfiguration
@@ -1727,6 +1827,9 @@ type httpListenerOpts struct {
 	// should be added.
 	addGRPCWebFilter bool
 	useRemoteAddress bool
+	// dynamicForwardProxy specifies whether the routes include dynamic forward proxy hosts, which
+	// need the dynamic forward proxy HTTP filter.
+	dynamicForwardProxy bool
 }
 
 // filterChainOpts describes a filter chain: a set of filters with the same TLS context
@@ -1812,8 +1915,28 @@ func buildHTTPConnectionManager(pluginParams *plugin.InputParams, httpOpts *http
 	filters = append(filters,
 		&http_conn.HttpFilter{Name: wellknown.CORS},
 		&http_conn.HttpFilter{Name: wellknown.Fault},
-		&http_conn.HttpFilter{Name: wellknown.Router},
 	)
+	filters = append(filters, buildCompressionAndBufferFilters(pluginParams.Push)...)
+
+	// The on demand filter must run right before the router, so that a missing virtual host is
+	// fetched over VHDS before the route is selected.
+	if httpOpts.rds != "" && util.IsVHDSEnabled(pluginParams.Node) &&
+		(pluginParams.ListenerCategory == networking.EnvoyFilter_SIDECAR_OUTBOUND ||
+			pluginParams.DeprecatedListenerCategory == networking.EnvoyFilter_DeprecatedListenerMatch_SIDECAR_OUTBOUND) {
+		filters = append(filters, &http_conn.HttpFilter{Name: OnDemandFilterName})
+	}
+	// The dynamic forward proxy filter only acts on requests routed to dynamic forward proxy clusters.
+	if httpOpts.dynamicForwardProxy {
+		filters = append(filters, &http_conn.HttpFilter{
+			Name: DynamicForwardProxyFilterName,
+			ConfigType: &http_conn.HttpFilter_TypedConfig{
+				TypedConfig: util.MessageToAny(&dfpfilter.FilterConfig{
+					DnsCacheConfig: buildDynamicForwardProxyDNSCache(pluginParams.Push),
+				}),
+			},
+		})
+	}
+	filters = append(filters, &http_conn.HttpFilter{Name: wellknown.Router})
 
 	if httpOpts.connectionManager == nil {
 		httpOpts.connectionManager = &http_conn.HttpConnectionManager{}
@@ -2018,7 +2141,8 @@ func buildListener(opts buildListenerOpts) *xdsapi.Listener {
 	}
 
 	if util.IsIstioVersionGE13(opts.proxy) && opts.proxy.Type != model.Router {
-		listener.ListenerFiltersTimeout = gogo.DurationToProtoDuration(opts.push.Mesh.ProtocolDetectionTimeout)
+		listener.ListenerFiltersTimeout = util.ProtocolDetectionTimeout(opts.proxy,
+			gogo.DurationToProtoDuration(opts.push.Mesh.ProtocolDetectionTimeout))
 
 		if listener.ListenerFiltersTimeout != nil {
 			listener.ContinueOnListenerFiltersTimeout = true
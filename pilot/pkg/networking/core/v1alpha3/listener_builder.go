@@ -173,8 +173,8 @@ func (builder *ListenerBuilder) aggregateVirtualInboundListener() *ListenerBuild
 			})
 	}
 
-	timeout := features.InboundProtocolDetectionTimeout
-	builder.virtualInboundListener.ListenerFiltersTimeout = ptypes.DurationProto(timeout)
+	timeout := ptypes.DurationProto(features.InboundProtocolDetectionTimeout)
+	builder.virtualInboundListener.ListenerFiltersTimeout = util.ProtocolDetectionTimeout(builder.node, timeout)
 	builder.virtualInboundListener.ContinueOnListenerFiltersTimeout = true
 
 	return builder
@@ -24,6 +24,11 @@ import (
 
 	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
 	listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	buffer "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/buffer/v2"
+	dfpfilter "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/dynamic_forward_proxy/v2alpha"
+	gzip "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/gzip/v2"
+	lua "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/lua/v2"
 	http_filter "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
 	tcp_proxy "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/tcp_proxy/v2"
 	"github.com/envoyproxy/go-control-plane/pkg/conversion"
@@ -38,9 +43,11 @@ import (
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/core/v1alpha3/fakes"
+	istio_route "istio.io/istio/pilot/pkg/networking/core/v1alpha3/route"
 	"istio.io/istio/pilot/pkg/networking/plugin"
 	"istio.io/istio/pilot/pkg/networking/util"
 	"istio.io/istio/pilot/pkg/serviceregistry"
+	"istio.io/istio/pkg/config/annotation"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/labels"
 	"istio.io/istio/pkg/config/mesh"
@@ -1262,6 +1269,301 @@ func testOutboundListenerConfigWithSidecarWithCaptureModeNone(t *testing.T, serv
 	}
 }
 
+func TestBuildCompressionAndBufferFilters(t *testing.T) {
+	push := model.NewPushContext()
+	if filters := buildCompressionAndBufferFilters(push); len(filters) != 0 {
+		t.Fatalf("expected no filters by default, found %v", filters)
+	}
+
+	_ = os.Setenv(features.EnableHTTPCompression.Name, "true")
+	_ = os.Setenv(features.HTTPCompressionContentTypes.Name, "application/json, text/html")
+	_ = os.Setenv(features.HTTPCompressionMinContentLength.Name, "1024")
+	_ = os.Setenv(features.HTTPMaxRequestBytes.Name, "65536")
+	defer func() {
+		_ = os.Unsetenv(features.EnableHTTPCompression.Name)
+		_ = os.Unsetenv(features.HTTPCompressionContentTypes.Name)
+		_ = os.Unsetenv(features.HTTPCompressionMinContentLength.Name)
+		_ = os.Unsetenv(features.HTTPMaxRequestBytes.Name)
+	}()
+
+	filters := buildCompressionAndBufferFilters(push)
+	if len(filters) != 2 || filters[0].Name != xdsutil.Gzip || filters[1].Name != xdsutil.Buffer {
+		t.Fatalf("expected gzip and buffer filters, found %v", filters)
+	}
+
+	gzipConfig := &gzip.Gzip{}
+	if err := ptypes.UnmarshalAny(filters[0].GetTypedConfig(), gzipConfig); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gzipConfig.ContentType, []string{"application/json", "text/html"}) {
+		t.Fatalf("unexpected content types %v", gzipConfig.ContentType)
+	}
+	if gzipConfig.ContentLength.GetValue() != 1024 {
+		t.Fatalf("expected content length 1024, found %v", gzipConfig.ContentLength)
+	}
+
+	bufferConfig := &buffer.Buffer{}
+	if err := ptypes.UnmarshalAny(filters[1].GetTypedConfig(), bufferConfig); err != nil {
+		t.Fatal(err)
+	}
+	if bufferConfig.MaxRequestBytes.GetValue() != 65536 {
+		t.Fatalf("expected max request bytes 65536, found %v", bufferConfig.MaxRequestBytes)
+	}
+}
+
+func TestBuildBufferFilterForOverrides(t *testing.T) {
+	virtualService := &model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:        schemas.VirtualService.Type,
+			Version:     schemas.VirtualService.Version,
+			Name:        "buffered",
+			Namespace:   "default",
+			Annotations: map[string]string{annotation.NetworkingMaxRequestBytes.Name: "4096"},
+		},
+		Spec: &networking.VirtualService{
+			Hosts: []string{"test.com"},
+			Http: []*networking.HTTPRoute{{
+				Route: []*networking.HTTPRouteDestination{{
+					Destination: &networking.Destination{Host: "test.com"},
+				}},
+			}},
+		},
+	}
+	env := buildListenerEnvWithVirtualServices(nil, []*model.Config{virtualService})
+	if err := env.PushContext.InitContext(&env, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	filters := buildCompressionAndBufferFilters(env.PushContext)
+	if len(filters) != 1 || filters[0].Name != xdsutil.Buffer {
+		t.Fatalf("expected the buffer filter for the virtual service override, found %v", filters)
+	}
+	bufferConfig := &buffer.Buffer{}
+	if err := ptypes.UnmarshalAny(filters[0].GetTypedConfig(), bufferConfig); err != nil {
+		t.Fatal(err)
+	}
+	if bufferConfig.MaxRequestBytes.GetValue() != 4096 {
+		t.Fatalf("expected the largest override as the limit, found %v", bufferConfig.MaxRequestBytes)
+	}
+
+	vhosts := []*route.VirtualHost{{Name: "test.com:80"}}
+	istio_route.DisableBufferByDefault(env.PushContext, vhosts)
+	perRoute := &buffer.BufferPerRoute{}
+	if err := ptypes.UnmarshalAny(vhosts[0].TypedPerFilterConfig[xdsutil.Buffer], perRoute); err != nil {
+		t.Fatal(err)
+	}
+	if !perRoute.GetDisabled() {
+		t.Fatalf("expected the buffer filter to be disabled on the virtual host, found %v", perRoute)
+	}
+}
+
+func TestBuildCompressionFiltersForOverrides(t *testing.T) {
+	virtualService := &model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:        schemas.VirtualService.Type,
+			Version:     schemas.VirtualService.Version,
+			Name:        "compressed",
+			Namespace:   "default",
+			Annotations: map[string]string{annotation.NetworkingCompression.Name: "on"},
+		},
+		Spec: &networking.VirtualService{
+			Hosts: []string{"test.com"},
+			Http: []*networking.HTTPRoute{{
+				Route: []*networking.HTTPRouteDestination{{
+					Destination: &networking.Destination{Host: "test.com"},
+				}},
+			}},
+		},
+	}
+	env := buildListenerEnvWithVirtualServices(nil, []*model.Config{virtualService})
+	if err := env.PushContext.InitContext(&env, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	filters := buildCompressionAndBufferFilters(env.PushContext)
+	if len(filters) != 2 || filters[0].Name != xdsutil.Lua || filters[1].Name != xdsutil.Gzip {
+		t.Fatalf("expected the lua and gzip filters for the virtual service override, found %v", filters)
+	}
+	luaConfig := &lua.Lua{}
+	if err := ptypes.UnmarshalAny(filters[0].GetTypedConfig(), luaConfig); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(luaConfig.InlineCode, `compression = "off"`) {
+		t.Fatalf("expected the routes without override to use the mesh default, found %v", luaConfig.InlineCode)
+	}
+
+	// The mesh default matches the override, so there is nothing to select per route.
+	_ = os.Setenv(features.EnableHTTPCompression.Name, "true")
+	defer func() { _ = os.Unsetenv(features.EnableHTTPCompression.Name) }()
+	env = buildListenerEnvWithVirtualServices(nil, []*model.Config{virtualService})
+	if err := env.PushContext.InitContext(&env, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	filters = buildCompressionAndBufferFilters(env.PushContext)
+	if len(filters) != 1 || filters[0].Name != xdsutil.Gzip {
+		t.Fatalf("expected the gzip filter only, found %v", filters)
+	}
+}
+
+func TestOutboundListenerOnDemandFilter(t *testing.T) {
+	_ = os.Setenv(features.EnableVHDS.Name, "true")
+	defer func() { _ = os.Unsetenv(features.EnableVHDS.Name) }()
+
+	p := &fakePlugin{}
+	listeners := buildOutboundListeners(p, &proxy14, nil, nil,
+		buildService("test.com", wildcardIP, protocol.HTTP, tnow))
+	l := findListenerByAddress(listeners, wildcardIP)
+	if l == nil {
+		t.Fatalf("expect listener %s", "0.0.0.0_8080")
+	}
+
+	for _, fc := range l.FilterChains {
+		if !isHTTPFilterChain(fc) {
+			continue
+		}
+		hcm := &http_filter.HttpConnectionManager{}
+		if err := getFilterConfig(fc.Filters[0], hcm); err != nil {
+			t.Fatalf("failed to get HCM, config %v", hcm)
+		}
+		filters := hcm.HttpFilters
+		if len(filters) < 2 || filters[len(filters)-2].Name != OnDemandFilterName {
+			t.Fatalf("expected on demand filter before the router, found %v", filters)
+		}
+		return
+	}
+	t.Fatal("expected an HTTP filter chain")
+}
+
+func TestOutboundListenerDynamicForwardProxyFilter(t *testing.T) {
+	_ = os.Setenv(features.EnableDynamicForwardProxy.Name, "true")
+	defer func() { _ = os.Unsetenv(features.EnableDynamicForwardProxy.Name) }()
+
+	service := buildService("*.test.com", wildcardIP, protocol.HTTP, tnow)
+	service.MeshExternal = true
+	p := &fakePlugin{}
+	listeners := buildOutboundListeners(p, &proxy14, nil, nil, service)
+	l := findListenerByAddress(listeners, wildcardIP)
+	if l == nil {
+		t.Fatalf("expect listener %s", "0.0.0.0_8080")
+	}
+
+	for _, fc := range l.FilterChains {
+		if !isHTTPFilterChain(fc) {
+			continue
+		}
+		hcm := &http_filter.HttpConnectionManager{}
+		if err := getFilterConfig(fc.Filters[0], hcm); err != nil {
+			t.Fatalf("failed to get HCM, config %v", hcm)
+		}
+		filters := hcm.HttpFilters
+		if len(filters) < 2 || filters[len(filters)-2].Name != DynamicForwardProxyFilterName {
+			t.Fatalf("expected dynamic forward proxy filter before the router, found %v", filters)
+		}
+		dfp := &dfpfilter.FilterConfig{}
+		if err := ptypes.UnmarshalAny(filters[len(filters)-2].GetTypedConfig(), dfp); err != nil {
+			t.Fatalf("failed to get dynamic forward proxy config: %v", err)
+		}
+		if dfp.DnsCacheConfig.GetName() != DynamicForwardProxyCacheName {
+			t.Fatalf("expected DNS cache %s, found %v", DynamicForwardProxyCacheName, dfp.DnsCacheConfig)
+		}
+		return
+	}
+	t.Fatal("expected an HTTP filter chain")
+}
+
+func TestOutboundListenerDynamicForwardProxyFilterScope(t *testing.T) {
+	_ = os.Setenv(features.EnableDynamicForwardProxy.Name, "true")
+	defer func() { _ = os.Unsetenv(features.EnableDynamicForwardProxy.Name) }()
+
+	// Only the route config of the port serving the wildcard ServiceEntry needs the filter.
+	service := buildService("*.test.com", wildcardIP, protocol.HTTP, tnow)
+	service.MeshExternal = true
+	p := &fakePlugin{}
+	listeners := buildOutboundListeners(p, &proxy14, nil, nil,
+		service, buildServiceWithPort("test.com", 9090, protocol.HTTP, tnow))
+	for _, tc := range []struct {
+		name     string
+		expected bool
+	}{
+		{"0.0.0.0_8080", true},
+		{"0.0.0.0_9090", false},
+	} {
+		var l *xdsapi.Listener
+		for _, listener := range listeners {
+			if listener.Name == tc.name {
+				l = listener
+			}
+		}
+		if l == nil {
+			t.Fatalf("expect listener %s", tc.name)
+		}
+		for _, fc := range l.FilterChains {
+			if !isHTTPFilterChain(fc) {
+				continue
+			}
+			hcm := &http_filter.HttpConnectionManager{}
+			if err := getFilterConfig(fc.Filters[0], hcm); err != nil {
+				t.Fatalf("failed to get HCM, config %v", hcm)
+			}
+			found := false
+			for _, filter := range hcm.HttpFilters {
+				if filter.Name == DynamicForwardProxyFilterName {
+					found = true
+				}
+			}
+			if found != tc.expected {
+				t.Fatalf("listener %s: expected dynamic forward proxy filter %v, found %v", tc.name, tc.expected, hcm.HttpFilters)
+			}
+		}
+	}
+}
+
+func TestOutboundListenerSniDynamicForwardProxyFilter(t *testing.T) {
+	_ = os.Setenv(features.EnableDynamicForwardProxy.Name, "true")
+	defer func() { _ = os.Unsetenv(features.EnableDynamicForwardProxy.Name) }()
+
+	for _, external := range []bool{true, false} {
+		service := buildService("*.test.com", wildcardIP, protocol.TLS, tnow)
+		service.MeshExternal = external
+		p := &fakePlugin{}
+		listeners := buildOutboundListeners(p, &proxy14, nil, nil, service)
+		l := findListenerByAddress(listeners, wildcardIP)
+		if l == nil {
+			t.Fatalf("expect listener %s", "0.0.0.0_8080")
+		}
+
+		var fc *listener.FilterChain
+		for _, chain := range l.FilterChains {
+			if len(chain.FilterChainMatch.GetServerNames()) > 0 {
+				fc = chain
+			}
+		}
+		if fc == nil {
+			t.Fatalf("expected a TLS filter chain matching the SNI, found %v", l.FilterChains)
+		}
+		if !external {
+			// Mesh internal services are not served by the dynamic forward proxy.
+			if fc.Filters[0].Name == SniDynamicForwardProxyFilterName {
+				t.Fatalf("unexpected SNI dynamic forward proxy filter for mesh internal service")
+			}
+			continue
+		}
+		if len(fc.Filters) < 2 || fc.Filters[0].Name != SniDynamicForwardProxyFilterName {
+			t.Fatalf("expected SNI dynamic forward proxy filter before the TCP proxy, found %v", fc.Filters)
+		}
+		config := &sniDynamicForwardProxyConfig{}
+		if err := getFilterConfig(fc.Filters[0], config); err != nil {
+			t.Fatalf("failed to get SNI dynamic forward proxy config: %v", err)
+		}
+		if config.PortValue != 8080 {
+			t.Fatalf("expected upstream port 8080, found %v", config.PortValue)
+		}
+		if got := config.DNSCacheConfig.GetName(); got != DynamicForwardProxyCacheName {
+			t.Fatalf("expected DNS cache %s, found %v", DynamicForwardProxyCacheName, got)
+		}
+	}
+}
+
 func TestOutboundListenerAccessLogs(t *testing.T) {
 	t.Helper()
 	p := &fakePlugin{}
@@ -21,12 +21,14 @@ import (
 	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
 	accesslogconfig "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v2"
+	dnscache "github.com/envoyproxy/go-control-plane/envoy/config/common/dynamic_forward_proxy/v2alpha"
 	accesslog "github.com/envoyproxy/go-control-plane/envoy/config/filter/accesslog/v2"
 	mongo_proxy "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/mongo_proxy/v2"
 	mysql_proxy "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/mysql_proxy/v1alpha1"
 	redis_proxy "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/redis_proxy/v2"
 	tcp_proxy "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/tcp_proxy/v2"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 
 	networking "istio.io/api/networking/v1alpha3"
@@ -39,6 +41,10 @@ import (
 	"istio.io/istio/pkg/config/protocol"
 )
 
+// SniDynamicForwardProxyFilterName is the network filter which resolves the SNI of TLS connections
+// routed to dynamic forward proxy clusters.
+const SniDynamicForwardProxyFilterName = "envoy.filters.network.sni_dynamic_forward_proxy"
+
 // redisOpTimeout is the default operation timeout for the Redis proxy filter.
 var redisOpTimeout = 5 * time.Second
 
@@ -205,11 +211,53 @@ func buildOutboundNetworkFilters(node *model.Proxy,
 	if len(routes) == 1 {
 		service := node.SidecarScope.ServiceForHostname(host.Name(routes[0].Destination.Host), push.ServiceByHostnameAndNamespace)
 		clusterName := istio_route.GetDestinationCluster(routes[0].Destination, service, port.Port)
-		return buildOutboundNetworkFiltersWithSingleDestination(push, node, clusterName, port)
+		filters := buildOutboundNetworkFiltersWithSingleDestination(push, node, clusterName, port)
+		if port.Protocol.IsTLS() && service != nil && isDynamicForwardProxyService(node, service, port) {
+			upstreamPort := port.Port
+			if routes[0].Destination.Port != nil && routes[0].Destination.Port.Number > 0 {
+				upstreamPort = int(routes[0].Destination.Port.Number)
+			}
+			filters = append([]*listener.Filter{buildSniDynamicForwardProxyFilter(push, upstreamPort)}, filters...)
+		}
+		return filters
 	}
 	return buildOutboundNetworkFiltersWithWeightedClusters(node, routes, push, port, configMeta)
 }
 
+// buildSniDynamicForwardProxyFilter builds the filter resolving the SNI of TLS connections, which the
+// dynamic forward proxy cluster then connects to on the given port.
+func buildSniDynamicForwardProxyFilter(push *model.PushContext, port int) *listener.Filter {
+	return &listener.Filter{
+		Name: SniDynamicForwardProxyFilterName,
+		ConfigType: &listener.Filter_TypedConfig{TypedConfig: util.MessageToAny(&sniDynamicForwardProxyConfig{
+			DNSCacheConfig: buildDynamicForwardProxyDNSCache(push),
+			PortValue:      uint32(port),
+		})},
+	}
+}
+
+// sniDynamicForwardProxyConfigType is the type of the SNI dynamic forward proxy filter config in Envoy.
+const sniDynamicForwardProxyConfigType = "envoy.config.filter.network.sni_dynamic_forward_proxy.v2alpha.FilterConfig"
+
+// sniDynamicForwardProxyConfig is the config of the SNI dynamic forward proxy filter. The go-control-plane
+// version in use predates the filter, so the message is declared here with the field numbers of the Envoy
+// proto and registered under its name, which lets it be sent as a typed config.
+type sniDynamicForwardProxyConfig struct {
+	DNSCacheConfig       *dnscache.DnsCacheConfig `protobuf:"bytes,1,opt,name=dns_cache_config,json=dnsCacheConfig,proto3" json:"dns_cache_config,omitempty"`
+	PortValue            uint32                   `protobuf:"varint,2,opt,name=port_value,json=portValue,proto3" json:"port_value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
+	XXX_unrecognized     []byte                   `json:"-"`
+	XXX_sizecache        int32                    `json:"-"`
+}
+
+func (m *sniDynamicForwardProxyConfig) Reset()         { *m = sniDynamicForwardProxyConfig{} }
+func (m *sniDynamicForwardProxyConfig) String() string { return proto.CompactTextString(m) }
+func (*sniDynamicForwardProxyConfig) ProtoMessage()    {}
+
+func init() {
+	proto.RegisterType((*sniDynamicForwardProxyConfig)(nil), sniDynamicForwardProxyConfigType)
+}
+
 // buildMongoFilter builds an outbound Envoy MongoProxy filter.
 func buildMongoFilter(statPrefix string) *listener.Filter {
 	// TODO: add a watcher for /var/lib/istio/mongo/certs
@@ -24,6 +24,7 @@ import (
 	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
 	xdsfault "github.com/envoyproxy/go-control-plane/envoy/config/filter/fault/v2"
+	xdsbuffer "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/buffer/v2"
 	xdshttpfault "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/fault/v2"
 	xdstype "github.com/envoyproxy/go-control-plane/envoy/type"
 	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher"
@@ -44,6 +45,7 @@ import (
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/core/v1alpha3/route/retry"
 	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pkg/config/annotation"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/labels"
@@ -523,10 +525,87 @@ func translateRoute(push *model.PushContext, node *model.Proxy, in *networking.H
 	if fault := in.Fault; fault != nil {
 		out.TypedPerFilterConfig[xdsutil.Fault] = util.MessageToAny(translateFault(in.Fault))
 	}
+	if buffer := translateBufferPolicy(virtualService); buffer != nil {
+		out.TypedPerFilterConfig[xdsutil.Buffer] = util.MessageToAny(buffer)
+	}
+	if compression := translateCompressionPolicy(virtualService); compression != nil {
+		out.Metadata.FilterMetadata[xdsutil.Lua] = compression
+	}
 
 	return out
 }
 
+// CompressionMetadataKey is the field of the envoy.lua metadata of a route which turns the response
+// compression on or off, overriding the mesh wide setting. The gzip filter has no per route
+// configuration, so the compression Lua filter reads it to keep or drop the Accept-Encoding header
+// of the request before the gzip filter.
+const CompressionMetadataKey = "compression"
+
+// translateCompressionPolicy returns the envoy.lua metadata of the routes of the virtual service
+// turning the response compression on or off with the NetworkingCompression annotation, or nil if
+// the routes use the mesh wide setting.
+func translateCompressionPolicy(virtualService model.Config) *structpb.Struct {
+	value, f := virtualService.Annotations[annotation.NetworkingCompression.Name]
+	if !f {
+		return nil
+	}
+	if _, err := annotation.ParseCompression(value); err != nil {
+		log.Warnf("Ignoring invalid %s annotation %q on virtual service %s/%s: %v",
+			annotation.NetworkingCompression.Name, value, virtualService.Namespace, virtualService.Name, err)
+		return nil
+	}
+	return &structpb.Struct{
+		Fields: map[string]*structpb.Value{
+			CompressionMetadataKey: {Kind: &structpb.Value_StringValue{StringValue: value}},
+		},
+	}
+}
+
+// DisableBufferByDefault disables the buffer filter on the virtual hosts, when it is only installed
+// for the virtual services overriding the request buffering limit of their routes. The route
+// level overrides take precedence over the virtual host.
+func DisableBufferByDefault(push *model.PushContext, virtualHosts []*route.VirtualHost) {
+	if features.HTTPMaxRequestBytes.Get() > 0 || push.MaxRequestBytesOverride() == 0 {
+		return
+	}
+	disabled := util.MessageToAny(&xdsbuffer.BufferPerRoute{
+		Override: &xdsbuffer.BufferPerRoute_Disabled{Disabled: true},
+	})
+	for _, vh := range virtualHosts {
+		if vh.TypedPerFilterConfig == nil {
+			vh.TypedPerFilterConfig = make(map[string]*any.Any)
+		}
+		vh.TypedPerFilterConfig[xdsutil.Buffer] = disabled
+	}
+}
+
+// translateBufferPolicy returns the buffer filter override requested through the
+// NetworkingMaxRequestBytes annotation of the virtual service, or nil if there is none.
+func translateBufferPolicy(virtualService model.Config) *xdsbuffer.BufferPerRoute {
+	value, f := virtualService.Annotations[annotation.NetworkingMaxRequestBytes.Name]
+	if !f {
+		return nil
+	}
+	maxRequestBytes, err := annotation.ParseMaxRequestBytes(value)
+	if err != nil {
+		log.Warnf("Ignoring invalid %s annotation %q on virtual service %s/%s: %v",
+			annotation.NetworkingMaxRequestBytes.Name, value, virtualService.Namespace, virtualService.Name, err)
+		return nil
+	}
+	if maxRequestBytes == 0 {
+		return &xdsbuffer.BufferPerRoute{
+			Override: &xdsbuffer.BufferPerRoute_Disabled{Disabled: true},
+		}
+	}
+	return &xdsbuffer.BufferPerRoute{
+		Override: &xdsbuffer.BufferPerRoute_Buffer{
+			Buffer: &xdsbuffer.Buffer{
+				MaxRequestBytes: &wrappers.UInt32Value{Value: maxRequestBytes},
+			},
+		},
+	}
+}
+
 // SortHeaderValueOption type and the functions below (Len, Less and Swap) are for sort.Stable for type HeaderValueOption
 type SortHeaderValueOption []*core.HeaderValueOption
 
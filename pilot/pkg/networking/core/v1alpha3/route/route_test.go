@@ -21,6 +21,8 @@ import (
 	"time"
 
 	envoyroute "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	xdsbuffer "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/buffer/v2"
+	xdsutil "github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/onsi/gomega"
 
@@ -29,6 +31,7 @@ import (
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/core/v1alpha3/route"
+	"istio.io/istio/pkg/config/annotation"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/mesh"
 	"istio.io/istio/pkg/config/protocol"
@@ -364,6 +367,55 @@ func TestBuildHTTPRoutes(t *testing.T) {
 		g.Expect(ok).NotTo(gomega.BeFalse())
 		g.Expect(redirectAction.Redirect.ResponseCode).To(gomega.Equal(envoyroute.RedirectAction_PERMANENT_REDIRECT))
 	})
+	t.Run("for virtual service with max request bytes annotation", func(t *testing.T) {
+		g := gomega.NewGomegaWithT(t)
+
+		vs := virtualServicePlain
+		vs.Annotations = map[string]string{annotation.NetworkingMaxRequestBytes.Name: "4096"}
+		routes, err := route.BuildHTTPRoutesForVirtualService(node, nil, vs, serviceRegistry, 8080, gatewayNames)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(len(routes)).To(gomega.Equal(1))
+
+		buffer := &xdsbuffer.BufferPerRoute{}
+		g.Expect(ptypes.UnmarshalAny(routes[0].TypedPerFilterConfig[xdsutil.Buffer], buffer)).To(gomega.Succeed())
+		g.Expect(buffer.GetBuffer().GetMaxRequestBytes().GetValue()).To(gomega.Equal(uint32(4096)))
+
+		vs.Annotations = map[string]string{annotation.NetworkingMaxRequestBytes.Name: "0"}
+		routes, err = route.BuildHTTPRoutesForVirtualService(node, nil, vs, serviceRegistry, 8080, gatewayNames)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		buffer = &xdsbuffer.BufferPerRoute{}
+		g.Expect(ptypes.UnmarshalAny(routes[0].TypedPerFilterConfig[xdsutil.Buffer], buffer)).To(gomega.Succeed())
+		g.Expect(buffer.GetDisabled()).To(gomega.BeTrue())
+
+		vs.Annotations = map[string]string{annotation.NetworkingMaxRequestBytes.Name: "lots"}
+		routes, err = route.BuildHTTPRoutesForVirtualService(node, nil, vs, serviceRegistry, 8080, gatewayNames)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(routes[0].TypedPerFilterConfig).NotTo(gomega.HaveKey(xdsutil.Buffer))
+	})
+	t.Run("for virtual service with compression annotation", func(t *testing.T) {
+		g := gomega.NewGomegaWithT(t)
+
+		vs := virtualServicePlain
+		for _, value := range []string{"on", "off"} {
+			vs.Annotations = map[string]string{annotation.NetworkingCompression.Name: value}
+			routes, err := route.BuildHTTPRoutesForVirtualService(node, nil, vs, serviceRegistry, 8080, gatewayNames)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(len(routes)).To(gomega.Equal(1))
+			g.Expect(routes[0].ResponseHeadersToAdd).To(gomega.BeEmpty())
+			metadata := routes[0].Metadata.FilterMetadata[xdsutil.Lua]
+			g.Expect(metadata.GetFields()[route.CompressionMetadataKey].GetStringValue()).To(gomega.Equal(value))
+		}
+
+		vs.Annotations = map[string]string{annotation.NetworkingCompression.Name: "maybe"}
+		routes, err := route.BuildHTTPRoutesForVirtualService(node, nil, vs, serviceRegistry, 8080, gatewayNames)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(routes[0].Metadata.FilterMetadata).NotTo(gomega.HaveKey(xdsutil.Lua))
+
+		routes, err = route.BuildHTTPRoutesForVirtualService(node, nil, virtualServicePlain, serviceRegistry, 8080, gatewayNames)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(routes[0].Metadata.FilterMetadata).NotTo(gomega.HaveKey(xdsutil.Lua))
+	})
+
 	t.Run("for no virtualservice but has destinationrule with consistentHash loadbalancer", func(t *testing.T) {
 		g := gomega.NewGomegaWithT(t)
 		meshConfig := mesh.DefaultMeshConfig()
@@ -19,6 +19,8 @@ import (
 	"sort"
 	"strings"
 
+	listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+
 	"istio.io/api/networking/v1alpha3"
 
 	"istio.io/istio/pilot/pkg/model"
@@ -184,10 +186,15 @@ func buildSidecarOutboundTLSFilterChainOpts(node *model.Proxy, push *model.PushC
 			sniHosts = []string{string(service.Hostname)}
 		}
 
+		networkFilters := buildOutboundNetworkFiltersWithSingleDestination(push, node, clusterName, listenPort)
+		// The dynamic forward proxy cluster connects to the host resolved from the SNI.
+		if isDynamicForwardProxyService(node, service, listenPort) {
+			networkFilters = append([]*listener.Filter{buildSniDynamicForwardProxyFilter(push, port)}, networkFilters...)
+		}
 		out = append(out, &filterChainOpts{
 			sniHosts:         sniHosts,
 			destinationCIDRs: []string{destinationCIDR},
-			networkFilters:   buildOutboundNetworkFiltersWithSingleDestination(push, node, clusterName, listenPort),
+			networkFilters:   networkFilters,
 		})
 	}
 
@@ -49,8 +49,20 @@ const (
 	Health = "health"
 	// Mixer is the name of the mixer plugin passed through the command line
 	Mixer = "mixer"
+	// Usage is the name of the usage accounting plugin passed through the command line
+	Usage = "usage"
 )
 
+// ModelPortToListenerProtocol converts the protocol of a port of the proxy to its corresponding
+// plugin.ListenerProtocol, handling the ports the workload excludes from protocol sniffing as TCP.
+func ModelPortToListenerProtocol(node *model.Proxy, port int, p protocol.Instance,
+	trafficDirection core.TrafficDirection) ListenerProtocol {
+	if p == protocol.Unsupported && util.IsProtocolSniffingExcludedForPort(node, port) {
+		p = protocol.TCP
+	}
+	return ModelProtocolToListenerProtocol(node, p, trafficDirection)
+}
+
 // ModelProtocolToListenerProtocol converts from a config.Protocol to its corresponding plugin.ListenerProtocol
 func ModelProtocolToListenerProtocol(node *model.Proxy, p protocol.Instance,
 	trafficDirection core.TrafficDirection) ListenerProtocol {
@@ -147,3 +147,22 @@ func TestModelProtocolToListenerProtocol(t *testing.T) {
 		})
 	}
 }
+
+func TestModelPortToListenerProtocol(t *testing.T) {
+	node := &model.Proxy{
+		Type:         model.SidecarProxy,
+		Metadata:     &model.NodeMetadata{ProtocolSniffingExcludePorts: "3306"},
+		IstioVersion: &model.IstioVersion{Major: 1, Minor: 4},
+	}
+	for _, direction := range []core.TrafficDirection{core.TrafficDirection_INBOUND, core.TrafficDirection_OUTBOUND} {
+		if got := ModelPortToListenerProtocol(node, 3306, protocol.Unsupported, direction); got != ListenerProtocolTCP {
+			t.Errorf("got %v for an excluded %v port, want TCP", got, direction)
+		}
+		if got := ModelPortToListenerProtocol(node, 8080, protocol.Unsupported, direction); got != ListenerProtocolAuto {
+			t.Errorf("got %v for a %v port, want Auto", got, direction)
+		}
+		if got := ModelPortToListenerProtocol(node, 3306, protocol.HTTP, direction); got != ListenerProtocolHTTP {
+			t.Errorf("got %v for an excluded %v HTTP port, want HTTP", got, direction)
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"istio.io/istio/pilot/pkg/networking/plugin/authz"
 	"istio.io/istio/pilot/pkg/networking/plugin/health"
 	"istio.io/istio/pilot/pkg/networking/plugin/mixer"
+	"istio.io/istio/pilot/pkg/networking/plugin/usage"
 )
 
 var availablePlugins = map[string]plugin.Plugin{
@@ -30,6 +31,7 @@ var availablePlugins = map[string]plugin.Plugin{
 	plugin.Authz:  authz.NewPlugin(),
 	plugin.Health: health.NewPlugin(),
 	plugin.Mixer:  mixer.NewPlugin(),
+	plugin.Usage:  usage.NewPlugin(),
 }
 
 // NewPlugins returns a slice of default Plugins.
@@ -0,0 +1,147 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package usage adds the stats filters which account the traffic received by the sidecars by
+// identities of the source and the destination, for the chargeback of the traffic between
+// service accounts without Mixer.
+package usage
+
+import (
+	"fmt"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+	http_conn "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	"github.com/golang/protobuf/jsonpb"
+	pstruct "github.com/golang/protobuf/ptypes/struct"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/plugin"
+	envoyusage "istio.io/istio/pkg/envoy/usage"
+)
+
+const (
+	// HTTPStatsFilter is the name of the filter accounting the HTTP requests.
+	HTTPStatsFilter = "envoy.filters.http.wasm"
+	// NetworkStatsFilter is the name of the filter accounting the connections and bytes.
+	NetworkStatsFilter = "envoy.filters.network.wasm"
+
+	// statsFilterConfig runs the Istio stats extension, built in the proxy, on the inbound traffic.
+	// The stats are keyed by the principals of the source and of the destination, which are
+	// aggregated into per-identity counters by pilot-agent. The root, the VM and the prefix of the
+	// stats are distinct from the ones of the telemetry stats filter, so that the requests aren't
+	// accounted twice in the istio_* metrics when both filters are installed.
+	statsFilterConfig = `{
+  "config": {
+    "root_id": "usage_inbound",
+    "configuration": "{\"stat_prefix\": \"` + envoyusage.StatPrefix + `\"}",
+    "vm_config": {
+      "vm_id": "usage_inbound",
+      "runtime": "envoy.wasm.runtime.null",
+      "code": {"local": {"inline_string": "envoy.wasm.stats"}}
+    }
+  }
+}`
+)
+
+// Plugin adds the usage stats filters to the inbound listeners of the sidecars.
+type Plugin struct {
+	config *pstruct.Struct
+}
+
+// NewPlugin returns an instance of the usage plugin
+func NewPlugin() plugin.Plugin {
+	config := &pstruct.Struct{}
+	if err := jsonpb.UnmarshalString(statsFilterConfig, config); err != nil {
+		panic(fmt.Sprintf("invalid stats filter config: %v", err))
+	}
+	return Plugin{config: config}
+}
+
+func (p Plugin) buildHTTPFilter() *http_conn.HttpFilter {
+	return &http_conn.HttpFilter{
+		Name:       HTTPStatsFilter,
+		ConfigType: &http_conn.HttpFilter_Config{Config: p.config},
+	}
+}
+
+func (p Plugin) buildNetworkFilter() *listener.Filter {
+	return &listener.Filter{
+		Name:       NetworkStatsFilter,
+		ConfigType: &listener.Filter_Config{Config: p.config},
+	}
+}
+
+// OnOutboundListener implements the Plugin interface method. The traffic is accounted by the
+// destination, which authenticates the source.
+func (Plugin) OnOutboundListener(in *plugin.InputParams, mutable *plugin.MutableObjects) error {
+	return nil
+}
+
+// OnInboundListener adds the stats filters to the inbound listeners of the sidecars: the
+// connections and bytes are accounted for all the protocols, and the requests for HTTP.
+func (p Plugin) OnInboundListener(in *plugin.InputParams, mutable *plugin.MutableObjects) error {
+	if in.Node == nil || in.Node.Type != model.SidecarProxy {
+		return nil
+	}
+
+	if mutable.Listener == nil {
+		return fmt.Errorf("listener not defined in mutable %v", mutable)
+	}
+
+	p.addStatsFilters(mutable.FilterChains)
+	return nil
+}
+
+func (p Plugin) addStatsFilters(chains []plugin.FilterChain) {
+	for i := range chains {
+		chains[i].TCP = append(chains[i].TCP, p.buildNetworkFilter())
+		if chains[i].ListenerProtocol == plugin.ListenerProtocolHTTP {
+			chains[i].HTTP = append(chains[i].HTTP, p.buildHTTPFilter())
+		}
+	}
+}
+
+// OnVirtualListener implements the Plugin interface method.
+func (Plugin) OnVirtualListener(in *plugin.InputParams, mutable *plugin.MutableObjects) error {
+	return nil
+}
+
+// OnInboundCluster implements the Plugin interface method.
+func (Plugin) OnInboundCluster(in *plugin.InputParams, cluster *xdsapi.Cluster) {
+}
+
+// OnOutboundRouteConfiguration implements the Plugin interface method.
+func (Plugin) OnOutboundRouteConfiguration(in *plugin.InputParams, route *xdsapi.RouteConfiguration) {
+}
+
+// OnInboundRouteConfiguration implements the Plugin interface method.
+func (Plugin) OnInboundRouteConfiguration(in *plugin.InputParams, route *xdsapi.RouteConfiguration) {
+}
+
+// OnOutboundCluster implements the Plugin interface method.
+func (Plugin) OnOutboundCluster(in *plugin.InputParams, cluster *xdsapi.Cluster) {
+}
+
+// OnInboundFilterChains implements the Plugin interface method.
+func (Plugin) OnInboundFilterChains(in *plugin.InputParams) []plugin.FilterChain {
+	return nil
+}
+
+// OnInboundPassthrough adds the stats filters to the passthrough filter chains, which receive
+// the traffic to the ports of the workload which aren't declared by its services.
+func (p Plugin) OnInboundPassthrough(in *plugin.InputParams, mutable *plugin.MutableObjects) error {
+	p.addStatsFilters(mutable.FilterChains)
+	return nil
+}
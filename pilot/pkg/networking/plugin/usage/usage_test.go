@@ -0,0 +1,95 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"testing"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/plugin"
+)
+
+func filterNames(chain plugin.FilterChain) ([]string, []string) {
+	var tcp, http []string
+	for _, f := range chain.TCP {
+		tcp = append(tcp, f.Name)
+	}
+	for _, f := range chain.HTTP {
+		http = append(http, f.Name)
+	}
+	return tcp, http
+}
+
+func TestOnInboundListener(t *testing.T) {
+	p := NewPlugin()
+	newMutable := func() *plugin.MutableObjects {
+		return &plugin.MutableObjects{
+			Listener: &xdsapi.Listener{},
+			FilterChains: []plugin.FilterChain{
+				{ListenerProtocol: plugin.ListenerProtocolHTTP},
+				{ListenerProtocol: plugin.ListenerProtocolTCP},
+			},
+		}
+	}
+
+	mutable := newMutable()
+	if err := p.OnInboundListener(&plugin.InputParams{Node: &model.Proxy{Type: model.SidecarProxy}}, mutable); err != nil {
+		t.Fatal(err)
+	}
+	tcp, http := filterNames(mutable.FilterChains[0])
+	if len(tcp) != 1 || tcp[0] != NetworkStatsFilter || len(http) != 1 || http[0] != HTTPStatsFilter {
+		t.Errorf("unexpected filters of the HTTP filter chain: network %v, http %v", tcp, http)
+	}
+	tcp, http = filterNames(mutable.FilterChains[1])
+	if len(tcp) != 1 || tcp[0] != NetworkStatsFilter || len(http) != 0 {
+		t.Errorf("unexpected filters of the TCP filter chain: network %v, http %v", tcp, http)
+	}
+	config := mutable.FilterChains[0].HTTP[0].GetConfig().Fields["config"].GetStructValue()
+	if got := config.Fields["root_id"].GetStringValue(); got != "usage_inbound" {
+		t.Errorf("unexpected root id of the stats filter %q", got)
+	}
+	if got := config.Fields["configuration"].GetStringValue(); got != `{"stat_prefix": "istio_usage"}` {
+		t.Errorf("unexpected configuration of the stats filter %q", got)
+	}
+
+	mutable = newMutable()
+	if err := p.OnInboundListener(&plugin.InputParams{Node: &model.Proxy{Type: model.Router}}, mutable); err != nil {
+		t.Fatal(err)
+	}
+	for _, chain := range mutable.FilterChains {
+		if len(chain.TCP) != 0 || len(chain.HTTP) != 0 {
+			t.Errorf("unexpected filters for a gateway: %v", chain)
+		}
+	}
+
+	if err := p.OnInboundListener(&plugin.InputParams{Node: &model.Proxy{Type: model.SidecarProxy}},
+		&plugin.MutableObjects{}); err == nil {
+		t.Error("expected error for undefined listener")
+	}
+}
+
+func TestOnInboundPassthrough(t *testing.T) {
+	mutable := &plugin.MutableObjects{
+		FilterChains: []plugin.FilterChain{{ListenerProtocol: plugin.ListenerProtocolTCP}},
+	}
+	if err := NewPlugin().OnInboundPassthrough(&plugin.InputParams{}, mutable); err != nil {
+		t.Fatal(err)
+	}
+	if tcp, _ := filterNames(mutable.FilterChains[0]); len(tcp) != 1 || tcp[0] != NetworkStatsFilter {
+		t.Errorf("unexpected filters of the passthrough filter chain: %v", tcp)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
 	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
@@ -41,6 +42,7 @@ import (
 
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/annotation"
 	"istio.io/istio/pkg/config/host"
 )
 
@@ -263,6 +265,18 @@ func IsIstioVersionGE14(node *model.Proxy) bool {
 		node.IstioVersion.Compare(&model.IstioVersion{Major: 1, Minor: 4, Patch: -1}) >= 0
 }
 
+// IsVHDSEnabled checks whether virtual hosts should be served to the proxy on demand.
+// Only sidecars are supported; gateways always receive their virtual hosts inline.
+func IsVHDSEnabled(node *model.Proxy) bool {
+	return features.EnableVHDS.Get() && node.Type == model.SidecarProxy && IsIstioVersionGE14(node)
+}
+
+// IsDynamicForwardProxyEnabled checks whether wildcard ServiceEntries are served to the proxy by the
+// dynamic forward proxy. Only sidecars are supported, gateways do not route to passthrough services.
+func IsDynamicForwardProxyEnabled(node *model.Proxy) bool {
+	return features.EnableDynamicForwardProxy.Get() && node.Type == model.SidecarProxy && IsIstioVersionGE14(node)
+}
+
 // IsXDSMarshalingToAnyEnabled controls whether "marshaling to Any" feature is enabled.
 func IsXDSMarshalingToAnyEnabled(node *model.Proxy) bool {
 	return !features.DisableXDSMarshalingToAny
@@ -270,23 +284,57 @@ func IsXDSMarshalingToAnyEnabled(node *model.Proxy) bool {
 
 // IsProtocolSniffingEnabled checks whether protocol sniffing is enabled.
 func IsProtocolSniffingEnabledForOutbound(node *model.Proxy) bool {
-	return features.EnableProtocolSniffingForOutbound.Get() && IsIstioVersionGE13(node)
+	return isProtocolSniffingEnabled(node, features.EnableProtocolSniffingForOutbound.Get()) && IsIstioVersionGE13(node)
 }
 
 func IsProtocolSniffingEnabledForInbound(node *model.Proxy) bool {
-	return features.EnableProtocolSniffingForInbound.Get() && IsIstioVersionGE14(node)
+	return isProtocolSniffingEnabled(node, features.EnableProtocolSniffingForInbound.Get()) && IsIstioVersionGE14(node)
+}
+
+// isProtocolSniffingEnabled returns the protocol sniffing setting requested by the workload,
+// falling back to the mesh wide setting if the workload does not override it.
+func isProtocolSniffingEnabled(node *model.Proxy, meshDefault bool) bool {
+	if node.Metadata == nil || node.Metadata.ProtocolSniffing == "" {
+		return meshDefault
+	}
+	enabled, err := strconv.ParseBool(node.Metadata.ProtocolSniffing)
+	if err != nil {
+		return meshDefault
+	}
+	return enabled
+}
+
+// ProtocolDetectionTimeout returns the protocol detection timeout requested by the workload, or
+// defaultTimeout if the workload does not override it.
+func ProtocolDetectionTimeout(node *model.Proxy, defaultTimeout *duration.Duration) *duration.Duration {
+	if node.Metadata == nil || node.Metadata.ProtocolDetectionTimeout == "" {
+		return defaultTimeout
+	}
+	timeout, err := time.ParseDuration(node.Metadata.ProtocolDetectionTimeout)
+	if err != nil || timeout <= 0 {
+		return defaultTimeout
+	}
+	return ptypes.DurationProto(timeout)
+}
+
+// IsProtocolSniffingExcludedForPort checks whether the workload disables protocol sniffing for the port.
+func IsProtocolSniffingExcludedForPort(node *model.Proxy, port int) bool {
+	return node.Metadata != nil && node.Metadata.ProtocolSniffingExcludePorts != "" &&
+		annotation.ProtocolSniffingExcludesPort(node.Metadata.ProtocolSniffingExcludePorts, port)
 }
 
 func IsProtocolSniffingEnabledForPort(node *model.Proxy, port *model.Port) bool {
-	return IsProtocolSniffingEnabledForOutbound(node) && port.Protocol.IsUnsupported()
+	return IsProtocolSniffingEnabledForOutboundPort(node, port)
 }
 
 func IsProtocolSniffingEnabledForInboundPort(node *model.Proxy, port *model.Port) bool {
-	return IsProtocolSniffingEnabledForInbound(node) && port.Protocol.IsUnsupported()
+	return IsProtocolSniffingEnabledForInbound(node) && port.Protocol.IsUnsupported() &&
+		!IsProtocolSniffingExcludedForPort(node, port.Port)
 }
 
 func IsProtocolSniffingEnabledForOutboundPort(node *model.Proxy, port *model.Port) bool {
-	return IsProtocolSniffingEnabledForOutbound(node) && port.Protocol.IsUnsupported()
+	return IsProtocolSniffingEnabledForOutbound(node) && port.Protocol.IsUnsupported() &&
+		!IsProtocolSniffingExcludedForPort(node, port.Port)
 }
 
 // ConvertLocality converts '/' separated locality string to Locality struct.
@@ -36,6 +36,7 @@ import (
 	networking "istio.io/api/networking/v1alpha3"
 
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/protocol"
 	proto2 "istio.io/istio/pkg/proto"
 )
 
@@ -622,3 +623,123 @@ func TestIsAllowAnyOutbound(t *testing.T) {
 		})
 	}
 }
+
+func TestIsProtocolSniffingEnabledForInbound(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata *model.NodeMetadata
+		result   bool
+	}{
+		{
+			name:     "MeshDefault",
+			metadata: &model.NodeMetadata{},
+			result:   true,
+		},
+		{
+			name:     "DisabledByWorkload",
+			metadata: &model.NodeMetadata{ProtocolSniffing: "false"},
+			result:   false,
+		},
+		{
+			name:     "InvalidOverride",
+			metadata: &model.NodeMetadata{ProtocolSniffing: "sometimes"},
+			result:   true,
+		},
+	}
+	for i := range tests {
+		t.Run(tests[i].name, func(t *testing.T) {
+			node := &model.Proxy{
+				Metadata:     tests[i].metadata,
+				IstioVersion: &model.IstioVersion{Major: 1, Minor: 4},
+			}
+			out := IsProtocolSniffingEnabledForInbound(node)
+			if out != tests[i].result {
+				t.Errorf("Expected %t but got %t for test case: %v\n", tests[i].result, out, tests[i].metadata)
+			}
+		})
+	}
+}
+
+func TestIsProtocolSniffingEnabledForPort(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata *model.NodeMetadata
+		port     *model.Port
+		result   bool
+	}{
+		{
+			name:     "MeshDefault",
+			metadata: &model.NodeMetadata{},
+			port:     &model.Port{Port: 3306, Protocol: protocol.Unsupported},
+			result:   true,
+		},
+		{
+			name:     "ExcludedPort",
+			metadata: &model.NodeMetadata{ProtocolSniffingExcludePorts: "25, 3306"},
+			port:     &model.Port{Port: 3306, Protocol: protocol.Unsupported},
+			result:   false,
+		},
+		{
+			name:     "OtherPortExcluded",
+			metadata: &model.NodeMetadata{ProtocolSniffingExcludePorts: "25"},
+			port:     &model.Port{Port: 3306, Protocol: protocol.Unsupported},
+			result:   true,
+		},
+		{
+			name:     "KnownProtocol",
+			metadata: &model.NodeMetadata{},
+			port:     &model.Port{Port: 8080, Protocol: protocol.HTTP},
+			result:   false,
+		},
+	}
+	for i := range tests {
+		t.Run(tests[i].name, func(t *testing.T) {
+			node := &model.Proxy{
+				Metadata:     tests[i].metadata,
+				IstioVersion: &model.IstioVersion{Major: 1, Minor: 4},
+			}
+			if out := IsProtocolSniffingEnabledForOutboundPort(node, tests[i].port); out != tests[i].result {
+				t.Errorf("Expected %t but got %t for outbound test case: %v\n", tests[i].result, out, tests[i].metadata)
+			}
+			if out := IsProtocolSniffingEnabledForInboundPort(node, tests[i].port); out != tests[i].result {
+				t.Errorf("Expected %t but got %t for inbound test case: %v\n", tests[i].result, out, tests[i].metadata)
+			}
+		})
+	}
+}
+
+func TestProtocolDetectionTimeout(t *testing.T) {
+	defaultTimeout := ptypes.DurationProto(time.Second)
+	tests := []struct {
+		name     string
+		metadata *model.NodeMetadata
+		result   time.Duration
+	}{
+		{
+			name:     "MeshDefault",
+			metadata: &model.NodeMetadata{},
+			result:   time.Second,
+		},
+		{
+			name:     "WorkloadOverride",
+			metadata: &model.NodeMetadata{ProtocolDetectionTimeout: "250ms"},
+			result:   250 * time.Millisecond,
+		},
+		{
+			name:     "InvalidOverride",
+			metadata: &model.NodeMetadata{ProtocolDetectionTimeout: "-1s"},
+			result:   time.Second,
+		},
+	}
+	for i := range tests {
+		t.Run(tests[i].name, func(t *testing.T) {
+			out, err := ptypes.Duration(ProtocolDetectionTimeout(&model.Proxy{Metadata: tests[i].metadata}, defaultTimeout))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != tests[i].result {
+				t.Errorf("Expected %v but got %v for test case: %v\n", tests[i].result, out, tests[i].metadata)
+			}
+		})
+	}
+}
@@ -16,7 +16,9 @@ package v2
 
 import (
 	"errors"
+	"fmt"
 	"io"
+	"strings"
 	"sync"
 	"time"
 
@@ -30,9 +32,11 @@ import (
 
 	istiolog "istio.io/pkg/log"
 
+	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/util"
 	"istio.io/istio/pilot/pkg/util/sets"
+	"istio.io/istio/pkg/kube/events"
 )
 
 var (
@@ -42,6 +46,11 @@ var (
 	adsClients      = map[string]*XdsConnection{}
 	adsClientsMutex sync.RWMutex
 
+	// vhdsClients are the delta streams serving on demand virtual hosts. They are kept apart from
+	// adsClients, since they are not proxies on their own but a second stream of an ADS client.
+	vhdsClients      = map[string]*XdsConnection{}
+	vhdsClientsMutex sync.RWMutex
+
 	// Map of sidecar IDs to XdsConnections, first key is sidecarID, second key is connID
 	// This is a map due to an edge case during envoy restart whereby the 'old' envoy
 	// reconnects after the 'new/restarted' envoy
@@ -99,6 +108,16 @@ type XdsConnection struct {
 	// Both ADS and EDS streams implement this interface
 	stream DiscoveryStream
 
+	// deltaStream is set instead of stream for connections serving on demand virtual hosts.
+	deltaStream DeltaDiscoveryStream
+
+	// VirtualHosts is the list of virtual hosts the client subscribed to over VHDS, in the
+	// <route config name>/<host> form used by Envoy.
+	VirtualHosts []string
+
+	// Last VHDS nonce sent and ack'd, used for debugging
+	VirtualHostNonceSent, VirtualHostNonceAcked string
+
 	// Routes is the list of watched Routes.
 	Routes []string
 
@@ -232,6 +251,7 @@ func (s *DiscoveryServer) StreamAggregatedResources(stream ads.AggregatedDiscove
 						errCode := codes.Code(discReq.ErrorDetail.Code)
 						adsLog.Warnf("ADS:CDS: ACK ERROR %v %s %s:%s", peerAddr, con.ConID, errCode.String(), discReq.ErrorDetail.GetMessage())
 						incrementXDSRejects(cdsReject, con.node.ID, errCode.String())
+						s.recordNACK(con, "CDS", discReq.ErrorDetail.GetMessage())
 					} else if discReq.ResponseNonce != "" {
 						con.ClusterNonceAcked = discReq.ResponseNonce
 					}
@@ -255,6 +275,7 @@ func (s *DiscoveryServer) StreamAggregatedResources(stream ads.AggregatedDiscove
 						errCode := codes.Code(discReq.ErrorDetail.Code)
 						adsLog.Warnf("ADS:LDS: ACK ERROR %v %s %s:%s", peerAddr, con.ConID, errCode.String(), discReq.ErrorDetail.GetMessage())
 						incrementXDSRejects(ldsReject, con.node.ID, errCode.String())
+						s.recordNACK(con, "LDS", discReq.ErrorDetail.GetMessage())
 					} else if discReq.ResponseNonce != "" {
 						con.ListenerNonceAcked = discReq.ResponseNonce
 					}
@@ -273,6 +294,7 @@ func (s *DiscoveryServer) StreamAggregatedResources(stream ads.AggregatedDiscove
 					errCode := codes.Code(discReq.ErrorDetail.Code)
 					adsLog.Warnf("ADS:RDS: ACK ERROR %v %s %s:%s", peerAddr, con.ConID, errCode.String(), discReq.ErrorDetail.GetMessage())
 					incrementXDSRejects(rdsReject, con.node.ID, errCode.String())
+					s.recordNACK(con, "RDS", discReq.ErrorDetail.GetMessage())
 					continue
 				}
 				routes := discReq.GetResourceNames()
@@ -314,6 +336,7 @@ func (s *DiscoveryServer) StreamAggregatedResources(stream ads.AggregatedDiscove
 					errCode := codes.Code(discReq.ErrorDetail.Code)
 					adsLog.Warnf("ADS:EDS: ACK ERROR %v %s %s:%s", peerAddr, con.ConID, errCode.String(), discReq.ErrorDetail.GetMessage())
 					incrementXDSRejects(edsReject, con.node.ID, errCode.String())
+					s.recordNACK(con, "EDS", discReq.ErrorDetail.GetMessage())
 					continue
 				}
 				clusters := discReq.GetResourceNames()
@@ -431,6 +454,9 @@ func (s *DiscoveryServer) initConnectionNode(node *core.Node, con *XdsConnection
 	}
 	// Update the config namespace associated with this proxy
 	nt.ConfigNamespace = model.GetProxyConfigNamespace(nt)
+	if !s.Env.NamespaceScope.Contains(nt.ConfigNamespace) {
+		return fmt.Errorf("proxy %s is in namespace %q, which is not served by this Pilot", nt.ID, nt.ConfigNamespace)
+	}
 
 	if err := nt.SetServiceInstances(s.Env); err != nil {
 		return err
@@ -449,6 +475,8 @@ func (s *DiscoveryServer) initConnectionNode(node *core.Node, con *XdsConnection
 		nt.Locality = node.Locality
 	}
 
+	nt.SetNodeName()
+
 	if err := nt.SetWorkloadLabels(s.Env); err != nil {
 		return err
 	}
@@ -468,9 +496,13 @@ func (s *DiscoveryServer) initConnectionNode(node *core.Node, con *XdsConnection
 	return nil
 }
 
-// DeltaAggregatedResources is not implemented.
+// DeltaAggregatedResources implements the incremental ADS interface. It is only used to
+// serve virtual hosts on demand, and is not implemented unless VHDS is enabled.
 func (s *DiscoveryServer) DeltaAggregatedResources(stream ads.AggregatedDiscoveryService_DeltaAggregatedResourcesServer) error {
-	return status.Errorf(codes.Unimplemented, "not implemented")
+	if !features.EnableVHDS.Get() {
+		return status.Errorf(codes.Unimplemented, "not implemented")
+	}
+	return s.streamVirtualHosts(stream)
 }
 
 // Compute and send the new configuration for a connection. This is blocking and may be slow
@@ -508,6 +540,7 @@ func (s *DiscoveryServer) pushConnection(con *XdsConnection, pushEv *XdsEvent) e
 			con.node.Locality = util.ConvertLocality(con.node.ServiceInstances[0].GetLocality())
 		}
 	}
+	con.node.SetNodeName()
 
 	// Precompute the sidecar scope and merged gateways associated with this proxy.
 	// Saves compute cycles in networking code. Though this might be redundant sometimes, we still
@@ -553,6 +586,12 @@ func (s *DiscoveryServer) pushConnection(con *XdsConnection, pushEv *XdsEvent) e
 			return err
 		}
 	}
+	if len(con.VirtualHosts) > 0 && pushTypes[RDS] {
+		err := s.pushVhds(con, pushEv.push, currentVersion, con.VirtualHosts)
+		if err != nil {
+			return err
+		}
+	}
 	proxiesConvergeDelay.Record(time.Since(pushEv.start).Seconds())
 	return nil
 }
@@ -652,6 +691,11 @@ func (s *DiscoveryServer) startPush(req *model.PushRequest) {
 		pending = append(pending, v)
 	}
 	adsClientsMutex.RUnlock()
+	vhdsClientsMutex.RLock()
+	for _, v := range vhdsClients {
+		pending = append(pending, v)
+	}
+	vhdsClientsMutex.RUnlock()
 
 	if adsLog.DebugEnabled() {
 		currentlyPending := s.pushQueue.Pending()
@@ -707,6 +751,14 @@ func (s *DiscoveryServer) removeCon(conID string, con *XdsConnection) {
 	}
 }
 
+// streamDone returns a channel which is closed when the connection's stream is closed.
+func (conn *XdsConnection) streamDone() <-chan struct{} {
+	if conn.deltaStream != nil {
+		return conn.deltaStream.Context().Done()
+	}
+	return conn.stream.Context().Done()
+}
+
 // Send with timeout
 func (conn *XdsConnection) send(res *xdsapi.DiscoveryResponse) error {
 	done := make(chan error, 1)
@@ -744,3 +796,17 @@ func (conn *XdsConnection) send(res *xdsapi.DiscoveryResponse) error {
 		return err
 	}
 }
+
+// recordNACK emits the config rejected by the proxy as an event on its pod, when the proxy runs in
+// a pod named by its ID.
+func (s *DiscoveryServer) recordNACK(con *XdsConnection, typ, message string) {
+	if s.EventRecorder == nil || con.node.ConfigNamespace == "" {
+		return
+	}
+	name := strings.TrimSuffix(con.node.ID, "."+con.node.ConfigNamespace)
+	if name == con.node.ID || name == "" {
+		return
+	}
+	s.EventRecorder.Eventf(events.ObjectReference("v1", "Pod", con.node.ConfigNamespace, name), events.Warning,
+		"ConfigNACKed", "%s config rejected by the proxy: %s", typ, message)
+}
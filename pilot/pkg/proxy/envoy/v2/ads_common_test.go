@@ -21,6 +21,7 @@ import (
 
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pkg/config/schemas"
+	"istio.io/istio/pkg/kube/events"
 )
 
 func TestProxyNeedsPush(t *testing.T) {
@@ -193,3 +194,28 @@ func BenchmarkListEquals(b *testing.B) {
 		listEqualUnordered(l, notEqual)
 	}
 }
+
+func TestRecordNACK(t *testing.T) {
+	recorder, emitted := events.NewFakeRecorder(1)
+	s := &DiscoveryServer{EventRecorder: recorder}
+
+	s.recordNACK(&XdsConnection{node: &model.Proxy{ID: "productpage-v1-84f77f8747-k5z8n.default", ConfigNamespace: "default"}},
+		"LDS", "invalid listener")
+	select {
+	case event := <-emitted:
+		if event.Ref.Kind != "Pod" || event.Ref.Namespace != "default" || event.Ref.Name != "productpage-v1-84f77f8747-k5z8n" ||
+			event.Type != events.Warning || event.Reason != "ConfigNACKed" || event.Message != "LDS config rejected by the proxy: invalid listener" {
+			t.Errorf("unexpected event %v", event)
+		}
+	default:
+		t.Error("expected an event for the NACK")
+	}
+
+	// The proxies which don't run in a pod named by their ID have no object for the event.
+	s.recordNACK(&XdsConnection{node: &model.Proxy{ID: "vm-1", ConfigNamespace: "default"}}, "LDS", "invalid listener")
+	select {
+	case event := <-emitted:
+		t.Errorf("unexpected event %v", event)
+	default:
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/core"
+	"istio.io/istio/pkg/kube/events"
 )
 
 var (
@@ -69,6 +70,8 @@ const (
 	ListenerType = typePrefix + "Listener"
 	// RouteType is sent after listeners.
 	RouteType = typePrefix + "RouteConfiguration"
+	// VirtualHostType is requested over the delta stream by proxies using on demand virtual hosts.
+	VirtualHostType = typePrefix + "route.VirtualHost"
 )
 
 func init() {
@@ -94,6 +97,10 @@ type DiscoveryServer struct {
 	// Defaults to false, can be enabled with PILOT_DEBUG_ADSZ_CONFIG=1
 	DebugConfigs bool
 
+	// EventRecorder emits the configs rejected by the proxies as events on their pods. The events
+	// are not emitted if nil.
+	EventRecorder *events.Recorder
+
 	// mutex protecting global structs updated or read by ADS service, including EDSUpdates and
 	// shards.
 	mutex sync.RWMutex
@@ -385,7 +392,7 @@ func doSendPushes(stopCh <-chan struct{}, semaphore chan struct{}, queue *PushQu
 					noncePrefix:        info.Push.Version,
 				}:
 					return
-				case <-client.stream.Context().Done(): // grpc stream was closed
+				case <-client.streamDone(): // grpc stream was closed
 					doneFunc()
 					adsLog.Infof("Client closed connection %v", client.ConID)
 				}
@@ -224,7 +224,7 @@ func (s *DiscoveryServer) updateClusterInc(push *model.PushContext, clusterName
 		return s.updateCluster(push, clusterName, edsCluster)
 	}
 
-	locEps := buildLocalityLbEndpointsFromShards(se, svcPort, subsetLabels, clusterName, push)
+	locEps := buildLocalityLbEndpointsFromShards(se, svcPort, subsetLabels, nil, clusterName, push)
 	// There is a chance multiple goroutines will update the cluster at the same time.
 	// This could be prevented by a lock - but because the update may be slow, it may be
 	// better to accept the extra computations.
@@ -411,10 +411,32 @@ func (s *DiscoveryServer) edsIncremental(version string, push *model.PushContext
 func (s *DiscoveryServer) EDSUpdate(clusterID, serviceName string, namespace string,
 	istioEndpoints []*model.IstioEndpoint) error {
 	inboundEDSUpdates.Increment()
+	if !s.importsEndpoints(serviceName, namespace) {
+		// The registries update the endpoints again when the service is imported.
+		adsLog.Debugf("dropping the endpoints of service %s/%s, which is not imported in the namespace scope",
+			namespace, serviceName)
+		s.mutex.Lock()
+		s.deleteService(clusterID, serviceName, namespace)
+		s.mutex.Unlock()
+		return nil
+	}
 	s.edsUpdate(clusterID, serviceName, namespace, istioEndpoints, false)
 	return nil
 }
 
+// importsEndpoints returns true if the service of the endpoints is imported in the namespace scope
+// of Pilot. The endpoints of the other services are never pushed, and aren't kept in the shards.
+func (s *DiscoveryServer) importsEndpoints(serviceName string, namespace string) bool {
+	if s.Env.NamespaceScope.Contains(namespace) {
+		return true
+	}
+	svc, err := s.Env.GetService(host.Name(serviceName))
+	if err != nil || svc == nil || svc.Attributes.Namespace != namespace {
+		return false
+	}
+	return s.Env.NamespaceScope.ImportsService(svc)
+}
+
 // edsUpdate updates edsUpdates by clusterID, serviceName, IstioEndpoints,
 // and requests a full/eds push.
 func (s *DiscoveryServer) edsUpdate(clusterID, serviceName string, namespace string,
@@ -617,6 +639,10 @@ func (s *DiscoveryServer) loadAssignmentsForClusterIsolated(proxy *model.Proxy,
 	svc := proxy.SidecarScope.ServiceForHostname(hostname, push.ServiceByHostnameAndNamespace)
 	push.Mutex.Unlock()
 	if svc == nil {
+		if s.Env.NamespaceScope != nil {
+			// The services not imported in the namespace scope have no endpoints.
+			return nil
+		}
 		// Shouldn't happen here - but just in case fallback
 		return s.loadAssignmentsForClusterLegacy(push, clusterName)
 	}
@@ -648,7 +674,7 @@ func (s *DiscoveryServer) loadAssignmentsForClusterIsolated(proxy *model.Proxy,
 		return s.loadAssignmentsForClusterLegacy(push, clusterName)
 	}
 
-	locEps := buildLocalityLbEndpointsFromShards(se, svcPort, subsetLabels, clusterName, push)
+	locEps := buildLocalityLbEndpointsFromShards(se, svcPort, subsetLabels, newTopologyFilter(proxy, svc), clusterName, push)
 
 	return &xdsapi.ClusterLoadAssignment{
 		ClusterName: clusterName,
@@ -695,6 +721,16 @@ func (s *DiscoveryServer) pushEds(push *model.PushContext, con *XdsConnection, v
 			l = filteredCLA
 		}
 
+		// If the DestinationRule asks for endpoint subsetting, only send this proxy its share
+		// of the endpoints of each locality.
+		if subsetSize := endpointSubsetSize(push, con.node, clusterName); subsetSize > 0 {
+			l = &xdsapi.ClusterLoadAssignment{
+				ClusterName: l.ClusterName,
+				Endpoints:   EndpointsBySubset(con.node.ID, subsetSize, l.Endpoints),
+				Policy:      l.Policy,
+			}
+		}
+
 		// If locality aware routing is enabled, prioritize endpoints or set their lb weight.
 		if push.Mesh.LocalityLbSetting != nil {
 			// Make a shallow copy of the cla as we are mutating the endpoints with priorities/weights relative to the calling proxy
@@ -876,11 +912,13 @@ func endpointDiscoveryResponse(loadAssignments []*xdsapi.ClusterLoadAssignment,
 	return out
 }
 
-// build LocalityLbEndpoints for a cluster from existing EndpointShards.
+// build LocalityLbEndpoints for a cluster from existing EndpointShards. If a topology filter is
+// set, only the endpoints it selects are included.
 func buildLocalityLbEndpointsFromShards(
 	shards *EndpointShards,
 	svcPort *model.Port,
 	epLabels labels.Collection,
+	topology *topologyFilter,
 	clusterName string,
 	push *model.PushContext) []*endpoint.LocalityLbEndpoints {
 	localityEpMap := make(map[string]*endpoint.LocalityLbEndpoints)
@@ -888,6 +926,7 @@ func buildLocalityLbEndpointsFromShards(
 	shards.mutex.Lock()
 	// The shards are updated independently, now need to filter and merge
 	// for this cluster
+	var selected []*model.IstioEndpoint
 	for _, endpoints := range shards.Shards {
 		for _, ep := range endpoints {
 			if svcPort.Name != ep.ServicePortName {
@@ -897,22 +936,23 @@ func buildLocalityLbEndpointsFromShards(
 			if !epLabels.HasSubsetOf(ep.Labels) {
 				continue
 			}
+			selected = append(selected, ep)
+		}
+	}
 
-			locLbEps, found := localityEpMap[ep.Locality]
-			if !found {
-				locLbEps = &endpoint.LocalityLbEndpoints{
-					Locality:    util.ConvertLocality(ep.Locality),
-					LbEndpoints: make([]*endpoint.LbEndpoint, 0, len(endpoints)),
-				}
-				localityEpMap[ep.Locality] = locLbEps
-			}
-			if ep.EnvoyEndpoint == nil {
-				ep.EnvoyEndpoint = buildEnvoyLbEndpoint(ep.UID, ep.Family, ep.Address, ep.EndpointPort, ep.Network,
-					ep.LbWeight, ep.TLSMode, push)
+	for _, ep := range topology.filter(selected) {
+		locLbEps, found := localityEpMap[ep.Locality]
+		if !found {
+			locLbEps = &endpoint.LocalityLbEndpoints{
+				Locality: util.ConvertLocality(ep.Locality),
 			}
-			locLbEps.LbEndpoints = append(locLbEps.LbEndpoints, ep.EnvoyEndpoint)
-
+			localityEpMap[ep.Locality] = locLbEps
+		}
+		if ep.EnvoyEndpoint == nil {
+			ep.EnvoyEndpoint = buildEnvoyLbEndpoint(ep.UID, ep.Family, ep.Address, ep.EndpointPort, ep.Network,
+				ep.LbWeight, ep.TLSMode, push)
 		}
+		locLbEps.LbEndpoints = append(locLbEps.LbEndpoints, ep.EnvoyEndpoint)
 	}
 	shards.mutex.Unlock()
 
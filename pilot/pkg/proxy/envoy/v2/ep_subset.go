@@ -0,0 +1,118 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+
+	endpoint "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
+
+	"istio.io/istio/pilot/pkg/model"
+)
+
+// EndpointSubsetSizeAnnotation can be set on a DestinationRule to limit the number of endpoints
+// of each locality sent to a proxy. Each proxy receives a deterministic subset of the endpoints,
+// chosen so that the load is spread evenly across all endpoints of the service.
+const EndpointSubsetSizeAnnotation = "networking.istio.io/endpointSubsetSize"
+
+// endpointSubsetSize returns the subset size configured by the DestinationRule of the cluster,
+// or 0 if endpoints should not be subset. The service is resolved as seen from the namespace of
+// the proxy; clusters of services the proxy cannot see are not subset.
+func endpointSubsetSize(push *model.PushContext, proxy *model.Proxy, clusterName string) int {
+	if proxy.SidecarScope == nil {
+		return 0
+	}
+	_, _, hostname, _ := model.ParseSubsetKey(clusterName)
+	service := proxy.SidecarScope.ServiceForHostname(hostname, push.ServiceByHostnameAndNamespace)
+	if service == nil {
+		return 0
+	}
+	cfg := push.DestinationRule(proxy, service)
+	if cfg == nil {
+		return 0
+	}
+	value, f := cfg.Annotations[EndpointSubsetSizeAnnotation]
+	if !f {
+		return 0
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil || size < 0 {
+		adsLog.Warnf("EDS: ignoring invalid %s annotation %q on destination rule %s/%s",
+			EndpointSubsetSizeAnnotation, value, cfg.Namespace, cfg.Name)
+		return 0
+	}
+	return size
+}
+
+// EndpointsBySubset returns the subset of the endpoints of each locality assigned to the proxy.
+// Localities are subset independently, so locality failover still has local endpoints to use.
+func EndpointsBySubset(proxyID string, subsetSize int, endpoints []*endpoint.LocalityLbEndpoints) []*endpoint.LocalityLbEndpoints {
+	if subsetSize <= 0 {
+		return endpoints
+	}
+	clientID := subsetClientID(proxyID)
+	filtered := make([]*endpoint.LocalityLbEndpoints, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if len(ep.LbEndpoints) <= subsetSize {
+			filtered = append(filtered, ep)
+			continue
+		}
+		filtered = append(filtered, createLocalityLbEndpoints(ep, subsetLbEndpoints(ep.LbEndpoints, clientID, subsetSize)))
+	}
+	return filtered
+}
+
+// subsetLbEndpoints implements deterministic subsetting, as described in the "Load Balancing in
+// the Datacenter" chapter of the Google SRE book. Clients are grouped in rounds, each round
+// shuffling the endpoints with a different seed and handing out disjoint subsets, so that
+// consecutive client IDs spread their connections evenly across all endpoints.
+func subsetLbEndpoints(lbEndpoints []*endpoint.LbEndpoint, clientID uint64, subsetSize int) []*endpoint.LbEndpoint {
+	// Sort first, the order of endpoints in the shards is not stable.
+	sorted := make([]*endpoint.LbEndpoint, len(lbEndpoints))
+	copy(sorted, lbEndpoints)
+	sort.Slice(sorted, func(i, j int) bool {
+		return lbEndpointKey(sorted[i]) < lbEndpointKey(sorted[j])
+	})
+
+	subsetCount := uint64(len(sorted) / subsetSize)
+	round := clientID / subsetCount
+	r := rand.New(rand.NewSource(int64(round)))
+	r.Shuffle(len(sorted), func(i, j int) {
+		sorted[i], sorted[j] = sorted[j], sorted[i]
+	})
+
+	start := int(clientID%subsetCount) * subsetSize
+	return sorted[start : start+subsetSize]
+}
+
+// subsetClientID maps the proxy ID to the client ID used to select its subset.
+func subsetClientID(proxyID string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(proxyID))
+	// Keep the ID positive when converted to the shuffle seed.
+	return h.Sum64() >> 1
+}
+
+func lbEndpointKey(ep *endpoint.LbEndpoint) string {
+	addr := ep.GetEndpoint().GetAddress()
+	if sa := addr.GetSocketAddress(); sa != nil {
+		return fmt.Sprintf("%s:%d", sa.GetAddress(), sa.GetPortValue())
+	}
+	return addr.GetPipe().GetPath()
+}
@@ -0,0 +1,159 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	endpoint "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+func buildSubsetTestEndpoints(localities, endpointsPerLocality int) []*endpoint.LocalityLbEndpoints {
+	out := make([]*endpoint.LocalityLbEndpoints, 0, localities)
+	for l := 0; l < localities; l++ {
+		lbEps := make([]*endpoint.LbEndpoint, 0, endpointsPerLocality)
+		for e := 0; e < endpointsPerLocality; e++ {
+			lbEps = append(lbEps, buildEnvoyLbEndpoint("", model.AddressFamilyTCP, fmt.Sprintf("10.%d.%d.%d", l, e/256, e%256),
+				8080, "", 1, "", nil))
+		}
+		out = append(out, createLocalityLbEndpoints(&endpoint.LocalityLbEndpoints{
+			Locality: &core.Locality{Region: fmt.Sprintf("region%d", l)},
+		}, lbEps))
+	}
+	return out
+}
+
+func TestEndpointsBySubset(t *testing.T) {
+	endpoints := buildSubsetTestEndpoints(2, 20)
+
+	if got := EndpointsBySubset("proxy", 0, endpoints); !reflect.DeepEqual(got, endpoints) {
+		t.Fatalf("expected endpoints to be unchanged without a subset size")
+	}
+
+	subset := EndpointsBySubset("proxy", 5, endpoints)
+	if len(subset) != 2 {
+		t.Fatalf("expected both localities to be kept, got %d", len(subset))
+	}
+	for i, ep := range subset {
+		if len(ep.LbEndpoints) != 5 || ep.LoadBalancingWeight.GetValue() != 5 {
+			t.Fatalf("expected 5 endpoints of weight 1, got %v", ep)
+		}
+		if !reflect.DeepEqual(ep.Locality, endpoints[i].Locality) {
+			t.Fatalf("expected locality %v, got %v", endpoints[i].Locality, ep.Locality)
+		}
+	}
+
+	// The subset must not depend on the order of the endpoints.
+	reversed := buildSubsetTestEndpoints(2, 20)
+	for _, ep := range reversed {
+		for i, j := 0, len(ep.LbEndpoints)-1; i < j; i, j = i+1, j-1 {
+			ep.LbEndpoints[i], ep.LbEndpoints[j] = ep.LbEndpoints[j], ep.LbEndpoints[i]
+		}
+	}
+	if again := EndpointsBySubset("proxy", 5, reversed); !reflect.DeepEqual(again, subset) {
+		t.Fatalf("expected deterministic subset, got %v and %v", subset, again)
+	}
+}
+
+func TestSubsetLoadDistribution(t *testing.T) {
+	endpoints := buildSubsetTestEndpoints(1, 100)[0].LbEndpoints
+	subsetSize := 10
+
+	// Consecutive client IDs spread connections perfectly evenly across the endpoints.
+	counts := map[string]int{}
+	for clientID := uint64(0); clientID < 1000; clientID++ {
+		for _, ep := range subsetLbEndpoints(endpoints, clientID, subsetSize) {
+			counts[lbEndpointKey(ep)]++
+		}
+	}
+	if len(counts) != len(endpoints) {
+		t.Fatalf("expected every endpoint to be used, got %d", len(counts))
+	}
+	for key, count := range counts {
+		if count != 100 {
+			t.Fatalf("expected endpoint %s to be used by 100 clients, got %d", key, count)
+		}
+	}
+
+	// Client IDs derived from proxy IDs are not consecutive, so allow some variance.
+	counts = map[string]int{}
+	for i := 0; i < 1000; i++ {
+		proxyID := fmt.Sprintf("sidecar~10.1.%d.%d~app-%d.default~default.svc.cluster.local", i/256, i%256, i)
+		for _, ep := range EndpointsBySubset(proxyID, subsetSize, buildSubsetTestEndpoints(1, 100))[0].LbEndpoints {
+			counts[lbEndpointKey(ep)]++
+		}
+	}
+	for key, count := range counts {
+		if count < 50 || count > 150 {
+			t.Fatalf("expected endpoint %s to be used by about 100 clients, got %d", key, count)
+		}
+	}
+}
+
+func TestEndpointSubsetSize(t *testing.T) {
+	dr := func(name, host, size string) model.Config {
+		return model.Config{
+			ConfigMeta: model.ConfigMeta{
+				Type:              schemas.DestinationRule.Type,
+				Name:              name,
+				Namespace:         "default",
+				Annotations:       map[string]string{EndpointSubsetSizeAnnotation: size},
+				CreationTimestamp: time.Now(),
+			},
+			Spec: &networking.DestinationRule{Host: host},
+		}
+	}
+	cfgs := createEndpoints(1, 3)
+	cfgs = append(cfgs, dr("foo-0", "foo-0.com", "10"), dr("foo-1", "foo-1.com", "ten"))
+	s := SetupDiscoveryServer(t, cfgs...)
+	proxy := &model.Proxy{
+		Type:            model.SidecarProxy,
+		IPAddresses:     []string{"10.3.3.3"},
+		ID:              "random",
+		ConfigNamespace: "default",
+		Metadata:        &model.NodeMetadata{},
+	}
+	push := s.globalPushContext()
+	proxy.SetSidecarScope(push)
+
+	tests := []struct {
+		cluster string
+		want    int
+	}{
+		{"outbound|80||foo-0.com", 10},
+		{"outbound|80||foo-1.com", 0},
+		{"outbound|80||foo-2.com", 0},
+	}
+	for _, tt := range tests {
+		if got := endpointSubsetSize(push, proxy, tt.cluster); got != tt.want {
+			t.Errorf("%s: expected subset size %d, got %d", tt.cluster, tt.want, got)
+		}
+	}
+
+	// Without a sidecar scope the service cannot be resolved for the proxy, so nothing is subset.
+	proxy.SidecarScope = nil
+	if got := endpointSubsetSize(push, proxy, "outbound|80||foo-0.com"); got != 0 {
+		t.Errorf("expected no subset without a sidecar scope, got %d", got)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"strings"
+
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+
+	"istio.io/istio/pilot/pkg/model"
+)
+
+// The Kubernetes service topology keys that Pilot evaluates. Other keys never match, as Pilot
+// doesn't know the labels of the nodes.
+const (
+	topologyKeyAny        = "*"
+	topologyKeyHostname   = "kubernetes.io/hostname"
+	topologyKeyZone       = "topology.kubernetes.io/zone"
+	topologyKeyZoneBeta   = "failure-domain.beta.kubernetes.io/zone"
+	topologyKeyRegion     = "topology.kubernetes.io/region"
+	topologyKeyRegionBeta = "failure-domain.beta.kubernetes.io/region"
+)
+
+// topologyFilter selects the endpoints of a service that a proxy may reach, according to the
+// traffic policies and the topology keys of the service, with the semantics of kube-proxy.
+type topologyFilter struct {
+	nodeName  string
+	locality  *core.Locality
+	nodeLocal bool
+	keys      []string
+}
+
+// newTopologyFilter returns the filter of the endpoints of the service for the proxy, or nil if
+// the service doesn't restrict its endpoints. The external traffic policy is not applied: it only
+// concerns the traffic that kube-proxy receives on the node ports and load balancers of the service,
+// while gateways reach the endpoints from inside the cluster.
+func newTopologyFilter(proxy *model.Proxy, svc *model.Service) *topologyFilter {
+	nodeLocal := svc.Attributes.InternalTrafficLocal
+	if nodeLocal && proxy.Metadata.NodeName == "" {
+		// The proxy doesn't run on a Kubernetes node, such as a VM, so there is no local endpoint
+		// to restrict the traffic to.
+		adsLog.Debugf("EDS: proxy %s has no node, ignoring the local traffic policy of %s", proxy.ID, svc.Hostname)
+		nodeLocal = false
+	}
+	if !nodeLocal && len(svc.Attributes.TopologyKeys) == 0 {
+		return nil
+	}
+	return &topologyFilter{
+		nodeName:  proxy.Metadata.NodeName,
+		locality:  proxy.Locality,
+		nodeLocal: nodeLocal,
+		keys:      svc.Attributes.TopologyKeys,
+	}
+}
+
+// filter returns the endpoints that the proxy may reach. If the service has topology keys, these
+// are the endpoints matching the proxy on the first key that any endpoint matches, and none if no
+// key matches.
+func (f *topologyFilter) filter(endpoints []*model.IstioEndpoint) []*model.IstioEndpoint {
+	if f == nil {
+		return endpoints
+	}
+	if f.nodeLocal {
+		endpoints = filterEndpoints(endpoints, func(ep *model.IstioEndpoint) bool {
+			return ep.NodeName == f.nodeName
+		})
+	}
+	if len(f.keys) == 0 {
+		return endpoints
+	}
+
+	for _, key := range f.keys {
+		if key == topologyKeyAny {
+			return endpoints
+		}
+		value := f.proxyTopologyValue(key)
+		if value == "" {
+			continue
+		}
+		matched := filterEndpoints(endpoints, func(ep *model.IstioEndpoint) bool {
+			return endpointTopologyValue(ep, key) == value
+		})
+		if len(matched) > 0 {
+			return matched
+		}
+	}
+	return nil
+}
+
+// proxyTopologyValue returns the value of the topology key for the proxy, or "" if unknown.
+func (f *topologyFilter) proxyTopologyValue(key string) string {
+	switch key {
+	case topologyKeyHostname:
+		return f.nodeName
+	case topologyKeyZone, topologyKeyZoneBeta:
+		return f.locality.GetZone()
+	case topologyKeyRegion, topologyKeyRegionBeta:
+		return f.locality.GetRegion()
+	default:
+		return ""
+	}
+}
+
+// endpointTopologyValue returns the value of the topology key for the endpoint, or "" if unknown.
+func endpointTopologyValue(ep *model.IstioEndpoint, key string) string {
+	switch key {
+	case topologyKeyHostname:
+		return ep.NodeName
+	case topologyKeyZone, topologyKeyZoneBeta:
+		return localityPart(ep.Locality, 1)
+	case topologyKeyRegion, topologyKeyRegionBeta:
+		return localityPart(ep.Locality, 0)
+	default:
+		return ""
+	}
+}
+
+// localityPart returns the region (0), zone (1) or subzone (2) of a / separated locality.
+func localityPart(locality string, i int) string {
+	parts := strings.Split(locality, "/")
+	if i < len(parts) {
+		return parts[i]
+	}
+	return ""
+}
+
+func filterEndpoints(endpoints []*model.IstioEndpoint, keep func(*model.IstioEndpoint) bool) []*model.IstioEndpoint {
+	filtered := make([]*model.IstioEndpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if keep(ep) {
+			filtered = append(filtered, ep)
+		}
+	}
+	return filtered
+}
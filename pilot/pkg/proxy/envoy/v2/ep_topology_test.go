@@ -0,0 +1,175 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pkg/config/labels"
+)
+
+// buildTopologyTestShards returns the endpoints of a service spread over two zones of a region:
+// node1 and node2 in zone1, node3 in zone2.
+func buildTopologyTestShards() *EndpointShards {
+	ep := func(address, node, locality string) *model.IstioEndpoint {
+		return &model.IstioEndpoint{
+			Address:         address,
+			EndpointPort:    8080,
+			ServicePortName: "http",
+			Family:          model.AddressFamilyTCP,
+			NodeName:        node,
+			Locality:        locality,
+			LbWeight:        1,
+		}
+	}
+	return &EndpointShards{
+		Shards: map[string][]*model.IstioEndpoint{
+			"Kubernetes": {
+				ep("10.0.0.1", "node1", "region1/zone1"),
+				ep("10.0.0.2", "node2", "region1/zone1"),
+				ep("10.0.0.3", "node3", "region1/zone2"),
+			},
+		},
+	}
+}
+
+func topologyTestProxy(nodeType model.NodeType, node, region, zone string) *model.Proxy {
+	return &model.Proxy{
+		Type:     nodeType,
+		ID:       "proxy",
+		Metadata: &model.NodeMetadata{NodeName: node},
+		Locality: &core.Locality{Region: region, Zone: zone},
+	}
+}
+
+func TestEdsTopology(t *testing.T) {
+	sidecar := func(node, zone string) *model.Proxy {
+		return topologyTestProxy(model.SidecarProxy, node, "region1", zone)
+	}
+	gateway := func(node, zone string) *model.Proxy {
+		return topologyTestProxy(model.Router, node, "region1", zone)
+	}
+
+	tests := []struct {
+		name       string
+		attributes model.ServiceAttributes
+		proxy      *model.Proxy
+		want       []string
+	}{
+		{
+			name:  "no policy",
+			proxy: sidecar("node1", "zone1"),
+			want:  []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+		},
+		{
+			name:       "internal traffic local",
+			attributes: model.ServiceAttributes{InternalTrafficLocal: true},
+			proxy:      sidecar("node1", "zone1"),
+			want:       []string{"10.0.0.1"},
+		},
+		{
+			name:       "internal traffic local without local endpoint",
+			attributes: model.ServiceAttributes{InternalTrafficLocal: true},
+			proxy:      sidecar("node4", "zone1"),
+			want:       []string{},
+		},
+		{
+			name:       "internal traffic local for proxy without node",
+			attributes: model.ServiceAttributes{InternalTrafficLocal: true},
+			proxy:      sidecar("", "zone1"),
+			want:       []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+		},
+		{
+			name:       "internal traffic local for gateway",
+			attributes: model.ServiceAttributes{InternalTrafficLocal: true},
+			proxy:      gateway("node3", "zone2"),
+			want:       []string{"10.0.0.3"},
+		},
+		{
+			name:       "topology hostname",
+			attributes: model.ServiceAttributes{TopologyKeys: []string{"kubernetes.io/hostname"}},
+			proxy:      sidecar("node2", "zone1"),
+			want:       []string{"10.0.0.2"},
+		},
+		{
+			name:       "topology hostname without match",
+			attributes: model.ServiceAttributes{TopologyKeys: []string{"kubernetes.io/hostname"}},
+			proxy:      sidecar("node4", "zone1"),
+			want:       []string{},
+		},
+		{
+			name: "topology falls back to zone",
+			attributes: model.ServiceAttributes{
+				TopologyKeys: []string{"kubernetes.io/hostname", "topology.kubernetes.io/zone", "*"},
+			},
+			proxy: sidecar("node4", "zone1"),
+			want:  []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			name: "topology beta zone",
+			attributes: model.ServiceAttributes{
+				TopologyKeys: []string{"failure-domain.beta.kubernetes.io/zone"},
+			},
+			proxy: sidecar("node4", "zone2"),
+			want:  []string{"10.0.0.3"},
+		},
+		{
+			name: "topology falls back to region",
+			attributes: model.ServiceAttributes{
+				TopologyKeys: []string{"topology.kubernetes.io/zone", "topology.kubernetes.io/region"},
+			},
+			proxy: sidecar("node4", "zone3"),
+			want:  []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+		},
+		{
+			name: "topology falls back to any",
+			attributes: model.ServiceAttributes{
+				TopologyKeys: []string{"example.com/rack", "kubernetes.io/hostname", "*"},
+			},
+			proxy: sidecar("node4", "zone3"),
+			want:  []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &model.Service{Hostname: "foo.default.svc.cluster.local", Attributes: tt.attributes}
+			port := &model.Port{Name: "http", Port: 80}
+			locEps := buildLocalityLbEndpointsFromShards(buildTopologyTestShards(), port, labels.Collection{},
+				newTopologyFilter(tt.proxy, svc), "outbound|80||foo.default.svc.cluster.local", model.NewPushContext())
+
+			got := []string{}
+			for _, locEp := range locEps {
+				for _, lbEp := range locEp.LbEndpoints {
+					got = append(got, lbEp.GetEndpoint().Address.GetSocketAddress().Address)
+				}
+				if locEp.LoadBalancingWeight.GetValue() != uint32(len(locEp.LbEndpoints)) {
+					t.Errorf("expected locality %v weight %d, got %d", util.LocalityToString(locEp.Locality),
+						len(locEp.LbEndpoints), locEp.LoadBalancingWeight.GetValue())
+				}
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected endpoints %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -62,6 +62,12 @@ var (
 		monitoring.WithLabels(nodeTag, errTag),
 	)
 
+	vhdsReject = monitoring.NewGauge(
+		"pilot_xds_vhds_reject",
+		"Pilot rejected VHDS.",
+		monitoring.WithLabels(nodeTag, errTag),
+	)
+
 	rdsExpiredNonce = monitoring.NewSum(
 		"pilot_rds_expired_nonce",
 		"Total number of RDS messages with an expired nonce.",
@@ -107,6 +113,8 @@ var (
 	rdsPushes         = pushes.With(typeTag.Value("rds"))
 	rdsSendErrPushes  = pushes.With(typeTag.Value("rds_senderr"))
 	rdsBuildErrPushes = pushes.With(typeTag.Value("rds_builderr"))
+	vhdsPushes        = pushes.With(typeTag.Value("vhds"))
+	vhdsSendErrPushes = pushes.With(typeTag.Value("vhds_senderr"))
 
 	pushTime = monitoring.NewDistribution(
 		"pilot_xds_push_time",
@@ -115,10 +123,11 @@ var (
 		monitoring.WithLabels(typeTag),
 	)
 
-	cdsPushTime = pushTime.With(typeTag.Value("cds"))
-	edsPushTime = pushTime.With(typeTag.Value("eds"))
-	ldsPushTime = pushTime.With(typeTag.Value("lds"))
-	rdsPushTime = pushTime.With(typeTag.Value("rds"))
+	cdsPushTime  = pushTime.With(typeTag.Value("cds"))
+	edsPushTime  = pushTime.With(typeTag.Value("eds"))
+	ldsPushTime  = pushTime.With(typeTag.Value("lds"))
+	rdsPushTime  = pushTime.With(typeTag.Value("rds"))
+	vhdsPushTime = pushTime.With(typeTag.Value("vhds"))
 
 	// only supported dimension is millis, unfortunately. default to unitdimensionless.
 	proxiesQueueTime = monitoring.NewDistribution(
@@ -177,6 +186,7 @@ func init() {
 		edsReject,
 		ldsReject,
 		rdsReject,
+		vhdsReject,
 		edsInstances,
 		edsAllLocalityEndpoints,
 		rdsExpiredNonce,
@@ -63,6 +63,9 @@ func (s *DiscoveryServer) generateRawRoutes(con *XdsConnection, push *model.Push
 			// Instead of panic, which will break down the whole cluster. Just ignore it here, let envoy process it.
 		}
 	}
+	if util.IsVHDSEnabled(con.node) {
+		rawRoutes = withOnDemandVirtualHosts(rawRoutes)
+	}
 	return rawRoutes
 }
 
@@ -0,0 +1,235 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pilot/pkg/config/memory"
+	"istio.io/istio/pilot/pkg/config/scoped"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/serviceregistry"
+	"istio.io/istio/pilot/pkg/serviceregistry/aggregate"
+	"istio.io/istio/pilot/pkg/serviceregistry/external"
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+// setupScopedDiscoveryServer creates a DiscoveryServer scoped to the namespaces, with the provided
+// configs, wired like a scoped Pilot. Closing the returned channel stops the config controller.
+func setupScopedDiscoveryServer(t *testing.T, namespaces []string, cfgs ...model.Config) (*DiscoveryServer, chan struct{}) {
+	t.Helper()
+	m := mesh.DefaultMeshConfig()
+	env := &model.Environment{
+		Watcher:         mesh.NewFixedWatcher(&m),
+		NetworksWatcher: mesh.NewFixedNetworksWatcher(nil),
+		NamespaceScope:  model.NewNamespaceScope(namespaces, nil),
+		PushContext:     model.NewPushContext(),
+	}
+	s := NewDiscoveryServer(env, []string{})
+	store := memory.NewController(memory.Make(schemas.Istio))
+	configController := scoped.NewController(store, env.NamespaceScope, m.RootNamespace)
+	istioConfigStore := model.MakeIstioStore(configController)
+	serviceControllers := aggregate.NewController()
+	serviceEntryStore := external.NewServiceDiscovery(configController, istioConfigStore, s)
+	stop := make(chan struct{})
+	go configController.Run(stop)
+	serviceControllers.AddRegistry(serviceregistry.Simple{
+		ProviderID:       "ServiceEntries",
+		Controller:       serviceEntryStore,
+		ServiceDiscovery: serviceEntryStore,
+	})
+
+	env.IstioConfigStore = istioConfigStore
+	env.ServiceDiscovery = serviceControllers
+
+	for _, cfg := range cfgs {
+		if _, err := store.Create(cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := env.PushContext.InitContext(env, env.PushContext, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.updateServiceShards(s.globalPushContext()); err != nil {
+		t.Fatalf("Failed to update service shards: %v", err)
+	}
+	return s, stop
+}
+
+func tenantServiceEntry(namespace, host, address string, exportTo ...string) model.Config {
+	return model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:              schemas.ServiceEntry.Type,
+			Name:              strings.Split(host, ".")[0],
+			Namespace:         namespace,
+			CreationTimestamp: time.Now(),
+		},
+		Spec: &networking.ServiceEntry{
+			Hosts:      []string{host},
+			Ports:      []*networking.Port{{Number: 80, Name: "http", Protocol: "http"}},
+			Endpoints:  []*networking.ServiceEntry_Endpoint{{Address: address}},
+			Resolution: networking.ServiceEntry_STATIC,
+			ExportTo:   exportTo,
+		},
+	}
+}
+
+func TestNamespaceScopeNoLeaks(t *testing.T) {
+	s, stop := setupScopedDiscoveryServer(t, []string{"tenant-a"},
+		tenantServiceEntry("tenant-a", "a.example.com", "10.0.0.1"),
+		tenantServiceEntry("tenant-b", "private-b.example.com", "10.0.0.2"),
+		tenantServiceEntry("tenant-b", "public-b.example.com", "10.0.0.3", "*"),
+		// A virtual service of the other tenant, which would apply to the host of tenant-a.
+		model.Config{
+			ConfigMeta: model.ConfigMeta{
+				Type:              schemas.VirtualService.Type,
+				Name:              "hijack",
+				Namespace:         "tenant-b",
+				CreationTimestamp: time.Now(),
+			},
+			Spec: &networking.VirtualService{
+				Hosts: []string{"a.example.com"},
+				Http: []*networking.HTTPRoute{{
+					Name: "hijack-route",
+					Route: []*networking.HTTPRouteDestination{{
+						Destination: &networking.Destination{Host: "private-b.example.com"},
+					}},
+				}},
+			},
+		},
+	)
+	defer close(stop)
+
+	// The proxies of the other tenant are rejected.
+	err := s.initConnectionNode(&core.Node{Id: "sidecar~10.1.0.2~pod.tenant-b~tenant-b.svc.cluster.local"},
+		newXdsConnection("10.1.0.2", nil))
+	if err == nil || !strings.Contains(err.Error(), "not served by this Pilot") {
+		t.Fatalf("expected the proxy of tenant-b to be rejected, got %v", err)
+	}
+
+	con := newXdsConnection("10.1.0.1", nil)
+	if err := s.initConnectionNode(&core.Node{Id: "sidecar~10.1.0.1~pod.tenant-a~tenant-a.svc.cluster.local"},
+		con); err != nil {
+		t.Fatal(err)
+	}
+	proxy := con.node
+	push := s.globalPushContext()
+
+	var generated []string
+	clusters := map[string]bool{}
+	for _, c := range s.ConfigGenerator.BuildClusters(proxy, push) {
+		clusters[c.Name] = true
+		generated = append(generated, c.String())
+	}
+	for _, l := range s.ConfigGenerator.BuildListeners(proxy, push) {
+		generated = append(generated, l.String())
+	}
+	for _, r := range s.ConfigGenerator.BuildHTTPRoutes(proxy, push, []string{"80"}) {
+		generated = append(generated, r.String())
+	}
+
+	for _, name := range []string{"outbound|80||a.example.com", "outbound|80||public-b.example.com"} {
+		if !clusters[name] {
+			t.Errorf("missing cluster %s of an imported service", name)
+		}
+	}
+	for _, leak := range []string{"private-b.example.com", "hijack-route"} {
+		for _, g := range generated {
+			if strings.Contains(g, leak) {
+				t.Fatalf("%s of tenant-b leaked into the generated config: %s", leak, g)
+			}
+		}
+	}
+
+	for cluster, expected := range map[string]bool{
+		"outbound|80||a.example.com":         true,
+		"outbound|80||public-b.example.com":  true,
+		"outbound|80||private-b.example.com": false,
+	} {
+		l := s.loadAssignmentsForClusterIsolated(proxy, push, cluster)
+		if got := l != nil && len(l.Endpoints) > 0; got != expected {
+			t.Errorf("%s: got endpoints %v, want %v", cluster, got, expected)
+		}
+	}
+	if got := fmt.Sprint(push.ServiceByHostnameAndNamespace); strings.Contains(got, "private-b") {
+		t.Errorf("the push context imported the service of tenant-b: %s", got)
+	}
+}
+
+func TestNamespaceScopeEDSUpdates(t *testing.T) {
+	s, stop := setupScopedDiscoveryServer(t, []string{"tenant-a"},
+		tenantServiceEntry("tenant-a", "a.example.com", "10.0.0.1"),
+		tenantServiceEntry("tenant-b", "private-b.example.com", "10.0.0.2"),
+		tenantServiceEntry("tenant-b", "public-b.example.com", "10.0.0.3", "*"),
+	)
+	defer close(stop)
+
+	// Only the endpoints of the imported services are kept. The registries update the endpoints of
+	// the services which aren't known yet again once they are.
+	for hostname, expected := range map[string]struct {
+		namespace string
+		kept      bool
+	}{
+		"a.example.com":         {"tenant-a", true},
+		"public-b.example.com":  {"tenant-b", true},
+		"private-b.example.com": {"tenant-b", false},
+		"unknown.example.com":   {"tenant-b", false},
+	} {
+		if err := s.EDSUpdate("remote", hostname, expected.namespace, []*model.IstioEndpoint{{
+			Address:         "10.2.0.1",
+			EndpointPort:    80,
+			ServicePortName: "http",
+		}}); err != nil {
+			t.Fatal(err)
+		}
+		s.mutex.RLock()
+		_, f := s.EndpointShardsByService[hostname][expected.namespace]
+		s.mutex.RUnlock()
+		if f != expected.kept {
+			t.Errorf("%s/%s: got endpoint shards %v, want %v", expected.namespace, hostname, f, expected.kept)
+		}
+	}
+
+	push := s.globalPushContext()
+	con := newXdsConnection("10.1.0.1", nil)
+	if err := s.initConnectionNode(&core.Node{Id: "sidecar~10.1.0.1~pod.tenant-a~tenant-a.svc.cluster.local"},
+		con); err != nil {
+		t.Fatal(err)
+	}
+	for hostname, expected := range map[string]bool{
+		"a.example.com":         true,
+		"public-b.example.com":  true,
+		"private-b.example.com": false,
+	} {
+		l := s.loadAssignmentsForClusterIsolated(con.node, push, "outbound|80||"+hostname)
+		got := false
+		for _, eps := range l.GetEndpoints() {
+			for _, ep := range eps.LbEndpoints {
+				got = got || ep.GetEndpoint().GetAddress().GetSocketAddress().GetAddress() == "10.2.0.1"
+			}
+		}
+		if got != expected {
+			t.Errorf("%s: got the EDS endpoint %v, want %v", hostname, got, expected)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pilot/pkg/util/sets"
+)
+
+// vhdsClusterName is the bootstrap cluster pointing to Pilot, used by Envoy to open the
+// delta stream for virtual hosts.
+const vhdsClusterName = "xds-grpc"
+
+// DeltaDiscoveryStream is the interface of the incremental ADS stream.
+type DeltaDiscoveryStream interface {
+	Send(*xdsapi.DeltaDiscoveryResponse) error
+	Recv() (*xdsapi.DeltaDiscoveryRequest, error)
+	grpc.ServerStream
+}
+
+// withOnDemandVirtualHosts strips the virtual hosts from the route configurations, and
+// configures the proxy to fetch them over VHDS instead. Inline virtual hosts can not be
+// combined with VHDS, since Envoy would reject the duplicates.
+func withOnDemandVirtualHosts(in []*xdsapi.RouteConfiguration) []*xdsapi.RouteConfiguration {
+	out := make([]*xdsapi.RouteConfiguration, 0, len(in))
+	for _, rc := range in {
+		stripped := proto.Clone(rc).(*xdsapi.RouteConfiguration)
+		stripped.VirtualHosts = nil
+		stripped.Vhds = &xdsapi.Vhds{
+			ConfigSource: &core.ConfigSource{
+				ConfigSourceSpecifier: &core.ConfigSource_ApiConfigSource{
+					ApiConfigSource: &core.ApiConfigSource{
+						ApiType: core.ApiConfigSource_DELTA_GRPC,
+						GrpcServices: []*core.GrpcService{{
+							TargetSpecifier: &core.GrpcService_EnvoyGrpc_{
+								EnvoyGrpc: &core.GrpcService_EnvoyGrpc{ClusterName: vhdsClusterName},
+							},
+						}},
+					},
+				},
+				InitialFetchTimeout: features.InitialFetchTimeout,
+			},
+		}
+		out = append(out, stripped)
+	}
+	return out
+}
+
+func receiveDeltaThread(con *XdsConnection, reqChannel chan *xdsapi.DeltaDiscoveryRequest, errP *error) {
+	defer close(reqChannel) // indicates close of the remote side.
+	for {
+		req, err := con.deltaStream.Recv()
+		if err != nil {
+			if status.Code(err) == codes.Canceled || err == io.EOF {
+				adsLog.Infof("ADS:VHDS: %q %s terminated %v", con.PeerAddr, con.ConID, err)
+				return
+			}
+			*errP = err
+			adsLog.Errorf("ADS:VHDS: %q %s terminated with error: %v", con.PeerAddr, con.ConID, err)
+			totalXDSInternalErrors.Increment()
+			return
+		}
+		select {
+		case reqChannel <- req:
+		case <-con.deltaStream.Context().Done():
+			adsLog.Infof("ADS:VHDS: %q %s terminated with stream closed", con.PeerAddr, con.ConID)
+			return
+		}
+	}
+}
+
+// streamVirtualHosts serves virtual hosts on demand. Each proxy opens a separate delta stream,
+// which is tracked in vhdsClients once the proxy is known, so full pushes resend the subscribed
+// virtual hosts.
+// Only virtual hosts are served on demand: clusters are still pushed in full over CDS, since
+// Envoy has no on demand cluster discovery and a lazily pushed cluster would fail the first
+// request routed to it.
+func (s *DiscoveryServer) streamVirtualHosts(stream DeltaDiscoveryStream) error {
+	peerInfo, ok := peer.FromContext(stream.Context())
+	peerAddr := "0.0.0.0"
+	if ok {
+		peerAddr = peerInfo.Addr.String()
+	}
+
+	err := s.globalPushContext().InitContext(s.Env, nil, nil)
+	if err != nil {
+		adsLog.Warnf("Error reading config %v", err)
+		return err
+	}
+	con := newXdsConnection(peerAddr, nil)
+	con.deltaStream = stream
+
+	var receiveError error
+	reqChannel := make(chan *xdsapi.DeltaDiscoveryRequest, 1)
+	go receiveDeltaThread(con, reqChannel, &receiveError)
+
+	for {
+		select {
+		case req, ok := <-reqChannel:
+			if !ok {
+				// Remote side closed connection.
+				return receiveError
+			}
+			// This should be only set for the first request. Guard with ID check regardless.
+			if req.Node != nil && req.Node.Id != "" {
+				if err := s.initConnectionNode(req.Node, con); err != nil {
+					return err
+				}
+			}
+			if con.node == nil {
+				return errors.New("missing node id")
+			}
+			// Registered before the first response, so that a push computed meanwhile is not missed.
+			if !con.added {
+				con.added = true
+				s.addVhdsCon(con.ConID, con)
+				defer s.removeVhdsCon(con.ConID)
+			}
+			if req.TypeUrl != VirtualHostType {
+				adsLog.Warnf("ADS:VHDS: Unknown watched resources %s", req.String())
+				continue
+			}
+			if req.ErrorDetail != nil {
+				errCode := codes.Code(req.ErrorDetail.Code)
+				adsLog.Warnf("ADS:VHDS: ACK ERROR %v %s %s:%s", peerAddr, con.ConID, errCode.String(), req.ErrorDetail.GetMessage())
+				incrementXDSRejects(vhdsReject, con.node.ID, errCode.String())
+				s.recordNACK(con, "VHDS", req.ErrorDetail.GetMessage())
+				continue
+			}
+			if len(req.ResourceNamesSubscribe) == 0 && len(req.ResourceNamesUnsubscribe) == 0 {
+				adsLog.Debugf("ADS:VHDS: ACK %s %s %s", peerAddr, con.ConID, req.ResponseNonce)
+				if req.ResponseNonce != "" {
+					con.mu.Lock()
+					con.VirtualHostNonceAcked = req.ResponseNonce
+					con.mu.Unlock()
+				}
+				continue
+			}
+
+			subscribed := sets.NewSet(con.VirtualHosts...).Insert(req.ResourceNamesSubscribe...).
+				Difference(sets.NewSet(req.ResourceNamesUnsubscribe...))
+			con.mu.Lock()
+			con.VirtualHosts = subscribed.UnsortedList()
+			con.mu.Unlock()
+
+			adsLog.Debugf("ADS:VHDS: REQ %s %s subscribe:%v unsubscribe:%v",
+				peerAddr, con.ConID, req.ResourceNamesSubscribe, req.ResourceNamesUnsubscribe)
+			if len(req.ResourceNamesSubscribe) > 0 {
+				if err := s.pushVhds(con, s.globalPushContext(), versionInfo(), req.ResourceNamesSubscribe); err != nil {
+					return err
+				}
+			}
+		case pushEv := <-con.pushChannel:
+			err := s.pushConnection(con, pushEv)
+			pushEv.done()
+			if err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+func (s *DiscoveryServer) addVhdsCon(conID string, con *XdsConnection) {
+	vhdsClientsMutex.Lock()
+	defer vhdsClientsMutex.Unlock()
+	vhdsClients[conID] = con
+}
+
+func (s *DiscoveryServer) removeVhdsCon(conID string) {
+	vhdsClientsMutex.Lock()
+	defer vhdsClientsMutex.Unlock()
+	delete(vhdsClients, conID)
+}
+
+func (s *DiscoveryServer) pushVhds(con *XdsConnection, push *model.PushContext, version string, names []string) error {
+	pushStart := time.Now()
+	response := s.generateVirtualHosts(con, push, version, names)
+	err := con.sendDelta(response)
+	vhdsPushTime.Record(time.Since(pushStart).Seconds())
+	if err != nil {
+		adsLog.Warnf("VHDS: Send failure for node:%v: %v", con.node.ID, err)
+		recordSendError(vhdsSendErrPushes, err)
+		return err
+	}
+	vhdsPushes.Increment()
+
+	adsLog.Infof("VHDS: PUSH for node:%s virtual hosts:%d", con.node.ID, len(response.Resources))
+	return nil
+}
+
+// generateVirtualHosts builds the VHDS response for the requested names. Envoy names
+// on demand virtual hosts <route config name>/<host>, where host is the authority of the request.
+// Hosts without a virtual host are returned as resources without a body, so that Envoy
+// stops waiting for them.
+func (s *DiscoveryServer) generateVirtualHosts(con *XdsConnection, push *model.PushContext, version string,
+	names []string) *xdsapi.DeltaDiscoveryResponse {
+	routeNames := sets.NewSet()
+	for _, name := range names {
+		if i := strings.LastIndex(name, "/"); i > 0 {
+			routeNames.Insert(name[:i])
+		}
+	}
+	routeConfigs := make(map[string]*xdsapi.RouteConfiguration, len(routeNames))
+	for _, rc := range s.ConfigGenerator.BuildHTTPRoutes(con.node, push, routeNames.UnsortedList()) {
+		routeConfigs[rc.Name] = rc
+	}
+
+	resp := &xdsapi.DeltaDiscoveryResponse{
+		TypeUrl:           VirtualHostType,
+		SystemVersionInfo: version,
+		Nonce:             nonce(push.Version),
+	}
+	resources := map[string]*xdsapi.Resource{}
+	for _, name := range names {
+		var vh *route.VirtualHost
+		i := strings.LastIndex(name, "/")
+		if i > 0 {
+			vh = lookupVirtualHost(routeConfigs[name[:i]], name[i+1:])
+		}
+		if vh == nil {
+			resp.Resources = append(resp.Resources, &xdsapi.Resource{Name: name, Aliases: []string{name}, Version: version})
+			continue
+		}
+		resourceName := name[:i] + "/" + vh.Name
+		if r, f := resources[resourceName]; f {
+			r.Aliases = append(r.Aliases, name)
+			continue
+		}
+		r := &xdsapi.Resource{
+			Name:     resourceName,
+			Aliases:  []string{name},
+			Version:  version,
+			Resource: util.MessageToAny(vh),
+		}
+		resources[resourceName] = r
+		resp.Resources = append(resp.Resources, r)
+	}
+	return resp
+}
+
+// lookupVirtualHost finds the virtual host Envoy would select for the host, following Envoy's
+// precedence of exact domains over wildcard suffixes over the catch all. Wildcard matches are
+// narrowed to the requested host, so that they do not shadow virtual hosts not fetched yet.
+func lookupVirtualHost(rc *xdsapi.RouteConfiguration, host string) *route.VirtualHost {
+	if rc == nil || host == "" {
+		return nil
+	}
+	var suffixMatch, catchAll *route.VirtualHost
+	longestSuffix := 0
+	for _, vh := range rc.VirtualHosts {
+		for _, domain := range vh.Domains {
+			switch {
+			case domain == host:
+				return vh
+			case domain == "*":
+				catchAll = vh
+			case strings.HasPrefix(domain, "*") && strings.HasSuffix(host, domain[1:]) && len(domain) > longestSuffix:
+				suffixMatch = vh
+				longestSuffix = len(domain)
+			}
+		}
+	}
+	match := suffixMatch
+	if match == nil {
+		match = catchAll
+	}
+	if match == nil {
+		return nil
+	}
+	narrowed := proto.Clone(match).(*route.VirtualHost)
+	narrowed.Name = host
+	narrowed.Domains = []string{host}
+	return narrowed
+}
+
+// sendDelta sends a delta response with timeout.
+func (conn *XdsConnection) sendDelta(res *xdsapi.DeltaDiscoveryResponse) error {
+	done := make(chan error, 1)
+	t := time.NewTimer(SendTimeout)
+	go func() {
+		err := conn.deltaStream.Send(res)
+		done <- err
+		conn.mu.Lock()
+		conn.VirtualHostNonceSent = res.Nonce
+		conn.mu.Unlock()
+	}()
+	select {
+	case <-t.C:
+		adsLog.Infof("Timeout writing %s", conn.ConID)
+		xdsResponseWriteTimeouts.Increment()
+		return errors.New("timeout sending")
+	case err := <-done:
+		t.Stop()
+		return err
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/kube/events"
+)
+
+func vhdsTestConnection(s *DiscoveryServer) *XdsConnection {
+	proxy := &model.Proxy{
+		Type:            model.SidecarProxy,
+		IPAddresses:     []string{"10.3.3.3"},
+		ID:              "random",
+		ConfigNamespace: "default",
+		Metadata:        &model.NodeMetadata{},
+	}
+	proxy.SetSidecarScope(s.globalPushContext())
+	con := newXdsConnection("10.3.3.3", nil)
+	con.node = proxy
+	con.Routes = []string{"80"}
+	return con
+}
+
+// blockingDeltaStream serves the requests sent to it until it is closed, and records the responses.
+type blockingDeltaStream struct {
+	grpc.ServerStream
+	requests  chan *xdsapi.DeltaDiscoveryRequest
+	responses chan *xdsapi.DeltaDiscoveryResponse
+}
+
+func (f *blockingDeltaStream) Send(resp *xdsapi.DeltaDiscoveryResponse) error {
+	f.responses <- resp
+	return nil
+}
+
+func (f *blockingDeltaStream) Recv() (*xdsapi.DeltaDiscoveryRequest, error) {
+	req, ok := <-f.requests
+	if !ok {
+		return nil, io.EOF
+	}
+	return req, nil
+}
+
+func (f *blockingDeltaStream) Context() context.Context {
+	return context.Background()
+}
+
+func TestVHDSPush(t *testing.T) {
+	s := SetupDiscoveryServer(t, createEndpoints(1, 10)...)
+	stop := make(chan struct{})
+	defer close(stop)
+	go s.sendPushes(stop)
+
+	stream := &blockingDeltaStream{
+		requests:  make(chan *xdsapi.DeltaDiscoveryRequest),
+		responses: make(chan *xdsapi.DeltaDiscoveryResponse, 10),
+	}
+	done := make(chan error)
+	go func() {
+		done <- s.streamVirtualHosts(stream)
+	}()
+
+	receive := func() *xdsapi.DeltaDiscoveryResponse {
+		t.Helper()
+		select {
+		case resp := <-stream.responses:
+			return resp
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for virtual hosts")
+			return nil
+		}
+	}
+	stream.requests <- &xdsapi.DeltaDiscoveryRequest{
+		Node:                   &core.Node{Id: "sidecar~10.3.3.3~productpage-v1-84f77f8747-k5z8n.default~default.svc.cluster.local"},
+		TypeUrl:                VirtualHostType,
+		ResourceNamesSubscribe: []string{"80/foo-3.com"},
+	}
+	if resp := receive(); len(resp.Resources) != 1 || resp.Resources[0].Aliases[0] != "80/foo-3.com" {
+		t.Fatalf("expected the subscribed virtual host, got %v", resp.Resources)
+	}
+
+	vhdsClientsMutex.RLock()
+	registered := len(vhdsClients)
+	vhdsClientsMutex.RUnlock()
+	if registered != 1 {
+		t.Fatalf("expected the delta stream to be registered, got %d streams", registered)
+	}
+	if adsClientCount() != 0 {
+		t.Fatalf("expected the delta stream not to be counted as an ADS client, got %d", adsClientCount())
+	}
+
+	// A full push resends the subscribed virtual hosts.
+	s.AdsPushAll(versionInfo(), &model.PushRequest{Full: true, Push: s.globalPushContext()})
+	if resp := receive(); len(resp.Resources) != 1 || resp.Resources[0].Aliases[0] != "80/foo-3.com" {
+		t.Fatalf("expected the subscribed virtual host to be pushed, got %v", resp.Resources)
+	}
+
+	close(stream.requests)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	vhdsClientsMutex.RLock()
+	registered = len(vhdsClients)
+	vhdsClientsMutex.RUnlock()
+	if registered != 0 {
+		t.Fatalf("expected the delta stream to be removed, got %d streams", registered)
+	}
+}
+
+func TestGenerateVirtualHosts(t *testing.T) {
+	s := SetupDiscoveryServer(t, createEndpoints(1, 10)...)
+	con := vhdsTestConnection(s)
+	push := s.globalPushContext()
+
+	resp := s.generateVirtualHosts(con, push, "v1", []string{"80/foo-3.com", "80/foo-3.com:80", "80/unknown.example.com", "foo-3.com"})
+	if resp.TypeUrl != VirtualHostType {
+		t.Fatalf("unexpected type %v", resp.TypeUrl)
+	}
+	if len(resp.Resources) != 3 {
+		t.Fatalf("expected 3 resources, got %v", resp.Resources)
+	}
+
+	known := resp.Resources[0]
+	if known.Name != "80/foo-3.com:80" || len(known.Aliases) != 2 {
+		t.Fatalf("expected both aliases to resolve to the foo-3.com virtual host, got %v", known)
+	}
+	vh := &route.VirtualHost{}
+	if err := ptypes.UnmarshalAny(known.Resource, vh); err != nil {
+		t.Fatal(err)
+	}
+	if len(vh.Routes) == 0 || vh.Routes[0].GetRoute().GetCluster() != "outbound|80||foo-3.com" {
+		t.Fatalf("unexpected virtual host %v", vh)
+	}
+
+	// The catch all virtual host is narrowed, so it does not shadow hosts not yet fetched.
+	passthrough := &route.VirtualHost{}
+	if err := ptypes.UnmarshalAny(resp.Resources[1].Resource, passthrough); err != nil {
+		t.Fatal(err)
+	}
+	if len(passthrough.Domains) != 1 || passthrough.Domains[0] != "unknown.example.com" {
+		t.Fatalf("expected catch all virtual host narrowed to the host, got %v", passthrough)
+	}
+
+	if missing := resp.Resources[2]; missing.Resource != nil || missing.Aliases[0] != "foo-3.com" {
+		t.Fatalf("expected empty resource for invalid name, got %v", missing)
+	}
+}
+
+// fakeDeltaStream replays the requests, and then reports the stream as closed.
+type fakeDeltaStream struct {
+	grpc.ServerStream
+	requests []*xdsapi.DeltaDiscoveryRequest
+}
+
+func (f *fakeDeltaStream) Send(*xdsapi.DeltaDiscoveryResponse) error {
+	return nil
+}
+
+func (f *fakeDeltaStream) Recv() (*xdsapi.DeltaDiscoveryRequest, error) {
+	if len(f.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := f.requests[0]
+	f.requests = f.requests[1:]
+	return req, nil
+}
+
+func (f *fakeDeltaStream) Context() context.Context {
+	return context.Background()
+}
+
+func TestVHDSRecordNACK(t *testing.T) {
+	s := SetupDiscoveryServer(t, createEndpoints(1, 10)...)
+	recorder, emitted := events.NewFakeRecorder(1)
+	s.EventRecorder = recorder
+
+	stream := &fakeDeltaStream{requests: []*xdsapi.DeltaDiscoveryRequest{{
+		Node:        &core.Node{Id: "sidecar~10.3.3.3~productpage-v1-84f77f8747-k5z8n.default~default.svc.cluster.local"},
+		TypeUrl:     VirtualHostType,
+		ErrorDetail: &status.Status{Code: 3, Message: "invalid virtual host"},
+	}}}
+	if err := s.streamVirtualHosts(stream); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case event := <-emitted:
+		if event.Ref.Name != "productpage-v1-84f77f8747-k5z8n" ||
+			event.Message != "VHDS config rejected by the proxy: invalid virtual host" {
+			t.Errorf("unexpected event %v", event)
+		}
+	default:
+		t.Error("expected an event for the VHDS NACK")
+	}
+}
@@ -24,10 +24,27 @@ import (
 	"istio.io/istio/pilot/pkg/serviceregistry"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/labels"
 	"istio.io/istio/pkg/config/protocol"
 	"istio.io/istio/pkg/config/visibility"
 )
 
+// WorkloadSelectorAnnotation selects the workloads of a ServiceEntry by labels, in the form
+// k1=v1,k2=v2. Kubernetes pods and the endpoints of other ServiceEntries in the same namespace,
+// typically VMs, with matching labels become the endpoints of the ServiceEntry, along with its own
+// endpoints. Only the pods of the primary Kubernetes registry are selected, not the pods of the
+// remote clusters.
+const WorkloadSelectorAnnotation = "networking.istio.io/workloadSelector"
+
+// workloadSelector returns the workload selector of the ServiceEntry, or nil if it has none.
+func workloadSelector(cfg model.Config) labels.Instance {
+	selector, f := cfg.Annotations[WorkloadSelectorAnnotation]
+	if !f || selector == "" {
+		return nil
+	}
+	return labels.Parse(selector)
+}
+
 func convertPort(port *networking.Port) *model.Port {
 	return &model.Port{
 		Name:     port.Name,
@@ -205,3 +222,71 @@ func convertInstances(cfg model.Config, services []*model.Service) []*model.Serv
 	}
 	return out
 }
+
+// convertSelectedInstances builds the instances of a ServiceEntry with a workload selector from its
+// own endpoints, the selected workloads, and the endpoints of the other ServiceEntries in the
+// namespace matching the selector. Workloads listen on the ServiceEntry port, unless the endpoint
+// overrides it.
+func convertSelectedInstances(cfg model.Config, services []*model.Service, selector labels.Instance,
+	workloads []*model.IstioEndpoint, serviceEntries []model.Config) []*model.ServiceInstance {
+	serviceEntry := cfg.Spec.(*networking.ServiceEntry)
+	if services == nil {
+		services = convertServices(cfg)
+	}
+
+	// The same VM may be registered by several ServiceEntries, only use it once.
+	endpoints := make([]*networking.ServiceEntry_Endpoint, 0, len(serviceEntry.Endpoints))
+	seen := map[string]bool{}
+	for _, endpoint := range serviceEntry.Endpoints {
+		seen[endpoint.Address] = true
+		endpoints = append(endpoints, endpoint)
+	}
+	for _, other := range serviceEntries {
+		if other.Namespace != cfg.Namespace || other.Name == cfg.Name || workloadSelector(other) != nil {
+			continue
+		}
+		for _, endpoint := range other.Spec.(*networking.ServiceEntry).Endpoints {
+			if seen[endpoint.Address] || strings.HasPrefix(endpoint.Address, model.UnixAddressPrefix) ||
+				!selector.SubsetOf(endpoint.Labels) {
+				continue
+			}
+			seen[endpoint.Address] = true
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+
+	out := make([]*model.ServiceInstance, 0)
+	for _, service := range services {
+		for _, serviceEntryPort := range serviceEntry.Ports {
+			for _, workload := range workloads {
+				if seen[workload.Address] {
+					continue
+				}
+				out = append(out, &model.ServiceInstance{
+					Endpoint: &model.IstioEndpoint{
+						Address:         workload.Address,
+						Family:          model.AddressFamilyTCP,
+						EndpointPort:    serviceEntryPort.Number,
+						ServicePortName: serviceEntryPort.Name,
+						Labels:          workload.Labels,
+						UID:             workload.UID,
+						ServiceAccount:  workload.ServiceAccount,
+						Network:         workload.Network,
+						Locality:        workload.Locality,
+						TLSMode:         workload.TLSMode,
+						Attributes: model.ServiceAttributes{
+							Name:      service.Attributes.Name,
+							Namespace: service.Attributes.Namespace,
+						},
+					},
+					Service:     service,
+					ServicePort: convertPort(serviceEntryPort),
+				})
+			}
+			for _, endpoint := range endpoints {
+				out = append(out, convertEndpoint(service, serviceEntryPort, endpoint))
+			}
+		}
+	}
+	return out
+}
@@ -48,6 +48,26 @@ type ServiceEntryStore struct {
 	changeMutex  sync.RWMutex
 	lastChange   time.Time
 	updateNeeded bool
+
+	workloadSources []WorkloadSource
+	// trackingWorkloads is true while some ServiceEntries have a workload selector.
+	trackingWorkloads bool
+}
+
+// WorkloadSource provides workloads, such as Kubernetes pods, which ServiceEntries can select
+// with a workload selector.
+type WorkloadSource interface {
+	// WorkloadsByLabels returns the workloads in the namespace matching the selector. The
+	// endpoints describe the workloads only, ports are taken from the ServiceEntry.
+	WorkloadsByLabels(namespace string, selector labels.Instance) []*model.IstioEndpoint
+
+	// AppendWorkloadHandler registers a handler called with the namespace and the labels, before
+	// and after the change, of a workload that was added, removed or changed.
+	AppendWorkloadHandler(func(namespace string, workloadLabels labels.Collection))
+
+	// TrackWorkloads starts or stops tracking the changes to the workloads. The workload handlers
+	// are only called while tracking, which is only needed while ServiceEntries select workloads.
+	TrackWorkloads(enabled bool)
 }
 
 // NewServiceDiscovery creates a new ServiceEntry discovery service
@@ -66,6 +86,7 @@ func NewServiceDiscovery(configController model.ConfigStoreCache, store model.Is
 			c.lastChange = time.Now()
 			c.updateNeeded = true
 			c.changeMutex.Unlock()
+			c.trackWorkloads()
 
 			cs := convertServices(curr)
 
@@ -90,34 +111,131 @@ func NewServiceDiscovery(configController model.ConfigStoreCache, store model.Is
 				}
 				c.XdsUpdater.ConfigUpdate(pushReq)
 			} else {
-				instances := convertInstances(curr, cs)
-				endpoints := make([]*model.IstioEndpoint, 0)
-				for _, instance := range instances {
-					for _, port := range instance.Service.Ports {
-						endpoints = append(endpoints, &model.IstioEndpoint{
-							Address:         instance.Endpoint.Address,
-							EndpointPort:    uint32(port.Port),
-							ServicePortName: port.Name,
-							Labels:          instance.Endpoint.Labels,
-							UID:             instance.Endpoint.UID,
-							ServiceAccount:  instance.Endpoint.ServiceAccount,
-							Network:         instance.Endpoint.Network,
-							Locality:        instance.Endpoint.Locality,
-							Attributes: model.ServiceAttributes{
-								Name:      instance.Service.Attributes.Name,
-								Namespace: instance.Service.Attributes.Namespace,
-							},
-							TLSMode: instance.Endpoint.TLSMode,
-						})
-					}
-				}
-				_ = c.XdsUpdater.EDSUpdate(c.Cluster(), curr.Name, curr.Namespace, endpoints)
+				c.edsUpdate(curr, c.convertInstances(curr, cs, c.store.ServiceEntries()))
+			}
+
+			// The endpoints of this ServiceEntry may be selected by ServiceEntries with a workload selector.
+			if workloadSelector(curr) == nil {
+				c.updateSelectedEndpoints(curr.Namespace, nil)
 			}
 		})
 	}
 	return c
 }
 
+// AppendWorkloadSource adds a source of workloads for ServiceEntries with a workload selector.
+func (d *ServiceEntryStore) AppendWorkloadSource(source WorkloadSource) {
+	d.changeMutex.Lock()
+	d.workloadSources = append(d.workloadSources, source)
+	d.updateNeeded = true
+	tracking := d.trackingWorkloads
+	d.changeMutex.Unlock()
+
+	source.AppendWorkloadHandler(d.updateSelectedEndpoints)
+	source.TrackWorkloads(tracking)
+}
+
+// trackWorkloads has the workload sources track the changes to the workloads only while some
+// ServiceEntries have a workload selector.
+func (d *ServiceEntryStore) trackWorkloads() {
+	tracking := false
+	for _, cfg := range d.store.ServiceEntries() {
+		if workloadSelector(cfg) != nil {
+			tracking = true
+			break
+		}
+	}
+
+	d.changeMutex.Lock()
+	changed := tracking != d.trackingWorkloads
+	d.trackingWorkloads = tracking
+	sources := d.workloadSources
+	d.changeMutex.Unlock()
+	if !changed {
+		return
+	}
+	for _, source := range sources {
+		source.TrackWorkloads(tracking)
+	}
+}
+
+// updateSelectedEndpoints sends EDS updates for the ServiceEntries with a workload selector in the
+// namespace that select any of the workload labels, or for all of them if the labels are nil.
+func (d *ServiceEntryStore) updateSelectedEndpoints(namespace string, workloadLabels labels.Collection) {
+	serviceEntries := d.store.ServiceEntries()
+	selected := make([]model.Config, 0)
+	for _, cfg := range serviceEntries {
+		if cfg.Namespace != namespace {
+			continue
+		}
+		selector := workloadSelector(cfg)
+		if selector == nil || (workloadLabels != nil && !workloadLabels.IsSupersetOf(selector)) {
+			continue
+		}
+		selected = append(selected, cfg)
+	}
+	if len(selected) == 0 {
+		return
+	}
+
+	d.changeMutex.Lock()
+	d.lastChange = time.Now()
+	d.updateNeeded = true
+	d.changeMutex.Unlock()
+	for _, cfg := range selected {
+		d.edsUpdate(cfg, d.convertInstances(cfg, nil, serviceEntries))
+	}
+}
+
+// edsUpdate sends the instances of the ServiceEntry as an incremental EDS update of each of its
+// hosts. The instances are already per service port.
+func (d *ServiceEntryStore) edsUpdate(cfg model.Config, instances []*model.ServiceInstance) {
+	endpoints := make(map[host.Name][]*model.IstioEndpoint)
+	for _, service := range convertServices(cfg) {
+		endpoints[service.Hostname] = make([]*model.IstioEndpoint, 0)
+	}
+	for _, instance := range instances {
+		endpoints[instance.Service.Hostname] = append(endpoints[instance.Service.Hostname], &model.IstioEndpoint{
+			Address:         instance.Endpoint.Address,
+			EndpointPort:    instance.Endpoint.EndpointPort,
+			ServicePortName: instance.ServicePort.Name,
+			Labels:          instance.Endpoint.Labels,
+			UID:             instance.Endpoint.UID,
+			ServiceAccount:  instance.Endpoint.ServiceAccount,
+			Network:         instance.Endpoint.Network,
+			Locality:        instance.Endpoint.Locality,
+			Attributes: model.ServiceAttributes{
+				Name:      instance.Service.Attributes.Name,
+				Namespace: instance.Service.Attributes.Namespace,
+			},
+			TLSMode: instance.Endpoint.TLSMode,
+		})
+	}
+	for hostname, hostEndpoints := range endpoints {
+		_ = d.XdsUpdater.EDSUpdate(d.Cluster(), string(hostname), cfg.Namespace, hostEndpoints)
+	}
+}
+
+// convertInstances converts the ServiceEntry to instances, resolving its workload selector
+// against the workload sources and the endpoints of the other ServiceEntries.
+func (d *ServiceEntryStore) convertInstances(cfg model.Config, services []*model.Service,
+	serviceEntries []model.Config) []*model.ServiceInstance {
+	selector := workloadSelector(cfg)
+	if selector == nil {
+		return convertInstances(cfg, services)
+	}
+
+	d.changeMutex.RLock()
+	sources := d.workloadSources
+	d.changeMutex.RUnlock()
+
+	workloads := make([]*model.IstioEndpoint, 0)
+	for _, source := range sources {
+		workloads = append(workloads, source.WorkloadsByLabels(cfg.Namespace, selector)...)
+	}
+	return convertSelectedInstances(cfg, services, selector, workloads, serviceEntries)
+}
+
 func (d *ServiceEntryStore) Provider() serviceregistry.ProviderID {
 	return serviceregistry.External
 }
@@ -221,8 +339,9 @@ func (d *ServiceEntryStore) update() {
 	di := map[host.Name]map[string][]*model.ServiceInstance{}
 	dip := map[string][]*model.ServiceInstance{}
 
-	for _, cfg := range d.store.ServiceEntries() {
-		for _, instance := range convertInstances(cfg, nil) {
+	serviceEntries := d.store.ServiceEntries()
+	for _, cfg := range serviceEntries {
+		for _, instance := range d.convertInstances(cfg, nil, serviceEntries) {
 
 			out, found := di[instance.Service.Hostname][instance.Service.Attributes.Namespace]
 			if !found {
@@ -17,7 +17,9 @@ package external
 import (
 	"fmt"
 	"sort"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	networking "istio.io/api/networking/v1alpha3"
 
@@ -459,3 +461,198 @@ func sortPorts(ports []*model.Port) {
 		return ports[i].Port < ports[j].Port
 	})
 }
+
+type fakeWorkloadSource struct {
+	workloads []*model.IstioEndpoint
+	handlers  []func(namespace string, workloadLabels labels.Collection)
+	tracking  int32
+}
+
+func (f *fakeWorkloadSource) WorkloadsByLabels(namespace string, selector labels.Instance) []*model.IstioEndpoint {
+	out := make([]*model.IstioEndpoint, 0)
+	for _, workload := range f.workloads {
+		if selector.SubsetOf(workload.Labels) {
+			out = append(out, workload)
+		}
+	}
+	return out
+}
+
+func (f *fakeWorkloadSource) AppendWorkloadHandler(h func(namespace string, workloadLabels labels.Collection)) {
+	f.handlers = append(f.handlers, h)
+}
+
+func (f *fakeWorkloadSource) TrackWorkloads(enabled bool) {
+	var tracking int32
+	if enabled {
+		tracking = 1
+	}
+	atomic.StoreInt32(&f.tracking, tracking)
+}
+
+func (f *fakeWorkloadSource) isTracking() bool {
+	return atomic.LoadInt32(&f.tracking) == 1
+}
+
+func TestServiceDiscoveryWorkloadSelector(t *testing.T) {
+	store, sd, stopFn := initServiceDiscovery()
+	defer stopFn()
+
+	source := &fakeWorkloadSource{
+		workloads: []*model.IstioEndpoint{
+			{Address: "10.0.0.1", Labels: labels.Instance{"app": "foo"}},
+			{Address: "10.0.0.2", Labels: labels.Instance{"app": "bar"}},
+		},
+	}
+	sd.AppendWorkloadSource(source)
+	if source.isTracking() {
+		t.Fatal("expected the workloads not to be tracked without a workload selector")
+	}
+
+	vms := &model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:              schemas.ServiceEntry.Type,
+			Name:              "vms",
+			Namespace:         "selector",
+			CreationTimestamp: GlobalTime,
+		},
+		Spec: &networking.ServiceEntry{
+			Hosts: []string{"vms.selector.svc"},
+			Ports: []*networking.Port{{Number: 8080, Name: "http", Protocol: "http"}},
+			Endpoints: []*networking.ServiceEntry_Endpoint{
+				{Address: "2.2.2.2", Labels: map[string]string{"app": "foo"}},
+				{Address: "3.3.3.3", Labels: map[string]string{"app": "bar"}},
+			},
+			Location:   networking.ServiceEntry_MESH_INTERNAL,
+			Resolution: networking.ServiceEntry_STATIC,
+		},
+	}
+	selected := &model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:              schemas.ServiceEntry.Type,
+			Name:              "selected",
+			Namespace:         "selector",
+			CreationTimestamp: GlobalTime,
+			Annotations:       map[string]string{WorkloadSelectorAnnotation: "app=foo"},
+		},
+		Spec: &networking.ServiceEntry{
+			Hosts: []string{"foo.selector.svc"},
+			Ports: []*networking.Port{{Number: 80, Name: "http", Protocol: "http"}},
+			Endpoints: []*networking.ServiceEntry_Endpoint{
+				{Address: "4.4.4.4", Labels: map[string]string{"app": "foo"}},
+			},
+			Location:   networking.ServiceEntry_MESH_INTERNAL,
+			Resolution: networking.ServiceEntry_STATIC,
+		},
+	}
+	createServiceEntries([]*model.Config{vms, selected}, store, t)
+	waitFor(t, source.isTracking, "expected the workloads to be tracked for the workload selector")
+
+	instances, err := sd.InstancesByPort(convertServices(*selected)[0], 80, nil)
+	if err != nil {
+		t.Fatalf("InstancesByPort() encountered unexpected error: %v", err)
+	}
+	addresses := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		if instance.Endpoint.EndpointPort != 80 {
+			t.Errorf("unexpected endpoint port %d for %s", instance.Endpoint.EndpointPort, instance.Endpoint.Address)
+		}
+		addresses = append(addresses, instance.Endpoint.Address)
+	}
+	sort.Strings(addresses)
+	if fmt.Sprint(addresses) != "[10.0.0.1 2.2.2.2 4.4.4.4]" {
+		t.Errorf("unexpected selected addresses %v", addresses)
+	}
+
+	// A workload change is picked up on the next update.
+	source.workloads = append(source.workloads, &model.IstioEndpoint{Address: "10.0.0.3", Labels: labels.Instance{"app": "foo"}})
+	for _, h := range source.handlers {
+		h("selector", labels.Collection{{"app": "foo"}})
+	}
+	instances, err = sd.InstancesByPort(convertServices(*selected)[0], 80, nil)
+	if err != nil {
+		t.Fatalf("InstancesByPort() encountered unexpected error: %v", err)
+	}
+	if len(instances) != 4 {
+		t.Errorf("expected 4 instances after workload update, got %d", len(instances))
+	}
+
+	// A change to workloads the ServiceEntry doesn't select doesn't recompute its instances.
+	source.workloads = append(source.workloads, &model.IstioEndpoint{Address: "10.0.0.4", Labels: labels.Instance{"app": "foo"}})
+	for _, h := range source.handlers {
+		h("selector", labels.Collection{{"app": "baz"}})
+	}
+	instances, err = sd.InstancesByPort(convertServices(*selected)[0], 80, nil)
+	if err != nil {
+		t.Fatalf("InstancesByPort() encountered unexpected error: %v", err)
+	}
+	if len(instances) != 4 {
+		t.Errorf("expected 4 instances after an unselected workload update, got %d", len(instances))
+	}
+
+	if err := store.Delete(schemas.ServiceEntry.Type, selected.Name, selected.Namespace); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return !source.isTracking() }, "expected the workloads not to be tracked anymore")
+}
+
+func waitFor(t *testing.T, condition func() bool, message string) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if condition() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal(message)
+}
+
+type edsRecorder struct {
+	FakeXdsUpdater
+	updates map[string][]*model.IstioEndpoint
+}
+
+func (r *edsRecorder) EDSUpdate(shard, hostname string, namespace string, entry []*model.IstioEndpoint) error {
+	r.updates[hostname] = entry
+	return nil
+}
+
+func TestServiceDiscoveryEDSUpdate(t *testing.T) {
+	recorder := &edsRecorder{updates: map[string][]*model.IstioEndpoint{}}
+	sd := NewServiceDiscovery(nil, nil, recorder)
+	cfg := model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:              schemas.ServiceEntry.Type,
+			Name:              "multi",
+			Namespace:         "eds",
+			CreationTimestamp: GlobalTime,
+		},
+		Spec: &networking.ServiceEntry{
+			Hosts: []string{"a.eds.svc", "b.eds.svc"},
+			Ports: []*networking.Port{
+				{Number: 80, Name: "http", Protocol: "http"},
+				{Number: 443, Name: "https", Protocol: "https"},
+			},
+			Endpoints: []*networking.ServiceEntry_Endpoint{
+				{Address: "2.2.2.2", Ports: map[string]uint32{"http": 8080}},
+			},
+			Location:   networking.ServiceEntry_MESH_INTERNAL,
+			Resolution: networking.ServiceEntry_STATIC,
+		},
+	}
+	sd.edsUpdate(cfg, convertInstances(cfg, nil))
+
+	if len(recorder.updates) != 2 {
+		t.Fatalf("expected an update per host, got %v", recorder.updates)
+	}
+	for _, hostname := range []string{"a.eds.svc", "b.eds.svc"} {
+		ports := make([]string, 0)
+		for _, endpoint := range recorder.updates[hostname] {
+			ports = append(ports, fmt.Sprintf("%s:%d", endpoint.ServicePortName, endpoint.EndpointPort))
+		}
+		sort.Strings(ports)
+		if fmt.Sprint(ports) != "[http:8080 https:443]" {
+			t.Errorf("unexpected endpoints %v for %s", ports, hostname)
+		}
+	}
+}
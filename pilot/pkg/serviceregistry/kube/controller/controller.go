@@ -44,6 +44,7 @@ import (
 	configKube "istio.io/istio/pkg/config/kube"
 	"istio.io/istio/pkg/config/labels"
 	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/kube/events"
 	"istio.io/istio/pkg/queue"
 )
 
@@ -56,6 +57,8 @@ const (
 	NodeRegionLabelGA = "topology.kubernetes.io/region"
 	// NodeZoneLabelGA is the well-known label for kubernetes node zone in ga
 	NodeZoneLabelGA = "topology.kubernetes.io/zone"
+	// NodeHostnameLabel is the well-known label for kubernetes node name
+	NodeHostnameLabel = "kubernetes.io/hostname"
 	// IstioSubzoneLabel is custom subzone label for locality-based routing in Kubernetes see: https://github.com/istio/istio/issues/19114
 	IstioSubzoneLabel = "topology.istio.io/subzone"
 	// IstioNamespace used by default for Istio cluster-wide installation
@@ -120,6 +123,14 @@ type Options struct {
 
 	// EndpointMode decides what source to use to get endpoint information
 	EndpointMode EndpointMode
+
+	// EventRecorder emits the configs rejected by the CRD controller as events. The events are
+	// not emitted if nil.
+	EventRecorder *events.Recorder
+
+	// NamespaceScope limits the services of the controller, and their endpoints, to the ones
+	// imported in the namespace scope of Pilot. All the services are kept if nil.
+	NamespaceScope *model.NamespaceScope
 }
 
 // EndpointMode decides what source to use to get endpoint information
@@ -155,8 +166,10 @@ type Controller struct {
 	xdsUpdater      model.XDSUpdater
 	domainSuffix    string
 	clusterID       string
+	namespaceScope  *model.NamespaceScope
 
-	serviceHandlers []func(*model.Service, model.Event)
+	serviceHandlers  []func(*model.Service, model.Event)
+	workloadHandlers []func(namespace string, podLabels labels.Collection)
 
 	stop chan struct{}
 
@@ -189,6 +202,7 @@ func NewController(client kubernetes.Interface, options Options) *Controller {
 		servicesMap:                make(map[host.Name]*model.Service),
 		externalNameSvcInstanceMap: make(map[host.Name][]*model.ServiceInstance),
 		networksWatcher:            options.NetworksWatcher,
+		namespaceScope:             options.NamespaceScope,
 	}
 
 	sharedInformers := informers.NewSharedInformerFactoryWithOptions(client, options.ResyncPeriod, informers.WithNamespace(options.WatchedNamespace))
@@ -250,6 +264,17 @@ func (c *Controller) onServiceEvent(curr interface{}, event model.Event) error {
 	log.Debugf("Handle event %s for service %s in namespace %s", event, svc.Name, svc.Namespace)
 
 	svcConv := kube.ConvertService(*svc, c.domainSuffix, c.clusterID)
+	c.RLock()
+	_, known := c.servicesMap[svcConv.Hostname]
+	c.RUnlock()
+	if event != model.EventDelete && !c.namespaceScope.ImportsService(svcConv) {
+		// The services which aren't imported in the namespace scope are left out, with their endpoints.
+		if !known {
+			return nil
+		}
+		event = model.EventDelete
+	}
+
 	switch event {
 	case model.EventDelete:
 		c.Lock()
@@ -270,6 +295,10 @@ func (c *Controller) onServiceEvent(curr interface{}, event model.Event) error {
 		}
 		c.Unlock()
 		c.xdsUpdater.SvcUpdate(c.clusterID, svc.Name, svc.Namespace, event)
+		if !known && c.namespaceScope != nil {
+			// The endpoints of the service were left out until it was imported.
+			c.endpoints.SyncEndpoints(c, svc.Name, svc.Namespace)
+		}
 	}
 
 	// Notify service handlers.
@@ -638,6 +667,7 @@ func (c *Controller) getProxyServiceInstancesFromMetadata(proxy *model.Proxy) ([
 					Network:        c.endpointNetwork(proxy.IPAddresses[0]),
 					Locality:       util.LocalityToString(proxy.Locality),
 					Attributes:     model.ServiceAttributes{Name: svc.Name, Namespace: svc.Namespace},
+					NodeName:       proxy.Metadata.NodeName,
 				},
 			})
 		}
@@ -713,11 +743,12 @@ func (c *Controller) GetProxyWorkloadLabels(proxy *model.Proxy) (labels.Collecti
 func (c *Controller) getEndpoints(podIP, address string, endpointPort int32, svcPort *model.Port, svc *model.Service) *model.ServiceInstance {
 	podLabels, _ := c.pods.labelsByIP(podIP)
 	pod := c.pods.getPodByIP(podIP)
-	locality, sa, uid := "", "", ""
+	locality, sa, uid, nodeName := "", "", "", ""
 	if pod != nil {
 		locality = c.GetPodLocality(pod)
 		sa = kube.SecureNamingSAN(pod)
 		uid = createUID(pod.Name, pod.Namespace)
+		nodeName = pod.Spec.NodeName
 	}
 	return &model.ServiceInstance{
 		Service:     svc,
@@ -733,6 +764,7 @@ func (c *Controller) getEndpoints(podIP, address string, endpointPort int32, svc
 			Locality:        locality,
 			Attributes:      model.ServiceAttributes{Name: svc.Attributes.Name, Namespace: svc.Attributes.Namespace},
 			TLSMode:         kube.PodTLSMode(pod),
+			NodeName:        nodeName,
 		},
 	}
 }
@@ -786,8 +818,69 @@ func (c *Controller) AppendInstanceHandler(func(*model.ServiceInstance, model.Ev
 	return nil
 }
 
+// AppendWorkloadHandler registers a handler called with the namespace and the labels, before and
+// after the change, of a pod that was added, removed or relabeled, so that ServiceEntries selecting
+// pods can update their endpoints.
+func (c *Controller) AppendWorkloadHandler(f func(namespace string, podLabels labels.Collection)) {
+	c.workloadHandlers = append(c.workloadHandlers, f)
+}
+
+// TrackWorkloads starts or stops tracking the changes to the pods for the workload handlers, which
+// is only needed while ServiceEntries select pods.
+func (c *Controller) TrackWorkloads(enabled bool) {
+	c.pods.trackWorkloads(enabled)
+}
+
+// WorkloadsByLabels returns the running and pending pods in the namespace matching the selector.
+// The endpoints describe the workloads only, without ports.
+func (c *Controller) WorkloadsByLabels(namespace string, selector labels.Instance) []*model.IstioEndpoint {
+	out := make([]*model.IstioEndpoint, 0)
+	pods, err := c.pods.informer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+	if err != nil {
+		log.Warnf("failed to list the pods of namespace %s: %v", namespace, err)
+		return out
+	}
+	for _, obj := range pods {
+		pod, ok := obj.(*v1.Pod)
+		if !ok || !isWorkload(pod) {
+			continue
+		}
+		podLabels := configKube.ConvertLabels(pod.ObjectMeta)
+		if !selector.SubsetOf(podLabels) {
+			continue
+		}
+		out = append(out, &model.IstioEndpoint{
+			Address:        pod.Status.PodIP,
+			Family:         model.AddressFamilyTCP,
+			Labels:         podLabels,
+			UID:            createUID(pod.Name, pod.Namespace),
+			ServiceAccount: kube.SecureNamingSAN(pod),
+			Network:        c.endpointNetwork(pod.Status.PodIP),
+			Locality:       c.GetPodLocality(pod),
+			TLSMode:        kube.PodTLSMode(pod),
+			NodeName:       pod.Spec.NodeName,
+		})
+	}
+	return out
+}
+
+// importsEndpoints returns true if the service of the endpoints is kept by the controller, which
+// only keeps the services imported in its namespace scope.
+func (c *Controller) importsEndpoints(hostname host.Name) bool {
+	if c.namespaceScope == nil {
+		return true
+	}
+	c.RLock()
+	defer c.RUnlock()
+	_, f := c.servicesMap[hostname]
+	return f
+}
+
 func (c *Controller) updateEDS(ep *v1.Endpoints, event model.Event) {
 	hostname := kube.ServiceHostname(ep.Name, ep.Namespace, c.domainSuffix)
+	if !c.importsEndpoints(hostname) {
+		return
+	}
 
 	endpoints := make([]*model.IstioEndpoint, 0)
 	if event != model.EventDelete {
@@ -821,6 +914,7 @@ func (c *Controller) updateEDS(ep *v1.Endpoints, event model.Event) {
 				}
 
 				tlsMode := kube.PodTLSMode(pod)
+				nodeName := endpointNodeName(ea, pod)
 
 				// EDS and ServiceEntry use name for service port - ADS will need to
 				// map to numbers.
@@ -836,6 +930,7 @@ func (c *Controller) updateEDS(ep *v1.Endpoints, event model.Event) {
 						Locality:        locality,
 						Attributes:      model.ServiceAttributes{Name: ep.Name, Namespace: ep.Namespace},
 						TLSMode:         tlsMode,
+						NodeName:        nodeName,
 					})
 				}
 			}
@@ -143,6 +143,7 @@ type fakeControllerOptions struct {
 	networksWatcher mesh.NetworksWatcher
 	serviceHandler  func(service *model.Service, event model.Event)
 	instanceHandler func(instance *model.ServiceInstance, event model.Event)
+	namespaceScope  *model.NamespaceScope
 }
 
 func newFakeControllerWithOptions(opts fakeControllerOptions) (*Controller, *FakeXdsUpdater) {
@@ -156,6 +157,7 @@ func newFakeControllerWithOptions(opts fakeControllerOptions) (*Controller, *Fak
 		XDSUpdater:       fx,
 		Metrics:          &model.Environment{},
 		NetworksWatcher:  opts.networksWatcher,
+		NamespaceScope:   opts.namespaceScope,
 	})
 
 	if opts.instanceHandler != nil {
@@ -580,6 +582,7 @@ func TestGetProxyServiceInstances(t *testing.T) {
 			ServiceAccount:  "spiffe://cluster.local/ns/nsa/sa/svcaccount",
 			TLSMode:         model.DisabledTLSModeLabel,
 			UID:             "kubernetes://pod2.nsa",
+			NodeName:        "node1",
 			Attributes: model.ServiceAttributes{
 				Name:      "svc1",
 				Namespace: "nsa",
@@ -636,6 +639,7 @@ func TestGetProxyServiceInstances(t *testing.T) {
 			ServiceAccount:  "spiffe://cluster.local/ns/nsa/sa/svcaccount",
 			TLSMode:         model.DisabledTLSModeLabel,
 			UID:             "kubernetes://pod3.nsa",
+			NodeName:        "node1",
 			Attributes: model.ServiceAttributes{
 				Name:      "svc1",
 				Namespace: "nsa",
@@ -1614,6 +1618,69 @@ func TestEndpointUpdate(t *testing.T) {
 	}
 }
 
+func TestNamespaceScope(t *testing.T) {
+	controller, fx := newFakeControllerWithOptions(fakeControllerOptions{
+		namespaceScope: model.NewNamespaceScope([]string{"tenant-a"}, nil),
+	})
+	defer controller.Stop()
+
+	pod := generatePod("128.0.0.1", "pod1", "tenant-b", "", "node1", map[string]string{"app": "prod-app"}, map[string]string{})
+	addPods(t, controller, pod)
+	if err := waitForPod(controller, pod.Status.PodIP); err != nil {
+		t.Fatalf("wait for pod err: %v", err)
+	}
+
+	// The service of the other namespace and its endpoints are left out until the service is exported.
+	createService(controller, "svc1", "tenant-b", nil, []int32{8080}, map[string]string{"app": "prod-app"}, t)
+	createEndpoints(controller, "svc1", "tenant-b", []string{"tcp-port"}, []string{"128.0.0.1"}, t)
+	createService(controller, "svc2", "tenant-a", nil, []int32{8080}, nil, t)
+	for {
+		// The service events are identified by the name of the service.
+		ev := fx.Wait("service")
+		if ev == nil {
+			t.Fatal("Timeout creating service")
+		}
+		if ev.ID == "svc1" {
+			t.Fatal("unexpected event of the service which is not imported")
+		}
+		if ev.ID == "svc2" {
+			break
+		}
+	}
+	hostname1 := kube.ServiceHostname("svc1", "tenant-b", domainSuffix)
+	if svc, _ := controller.GetService(hostname1); svc != nil {
+		t.Fatalf("unexpected service %s, which is not imported", hostname1)
+	}
+
+	// Once exported, the service is imported with its endpoints.
+	svc, err := controller.client.CoreV1().Services("tenant-b").Get("svc1", metaV1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.Annotations = map[string]string{annotation.NetworkingExportTo.Name: "*"}
+	if _, err := controller.client.CoreV1().Services("tenant-b").Update(svc); err != nil {
+		t.Fatal(err)
+	}
+	if ev := fx.Wait("eds"); ev == nil || ev.ID != string(hostname1) {
+		t.Fatalf("expected the endpoints of the exported service, got %v", ev)
+	}
+	if svc, _ := controller.GetService(hostname1); svc == nil {
+		t.Fatalf("missing service %s, which is exported", hostname1)
+	}
+
+	// The service is left out again when it is no longer exported.
+	svc.Annotations = nil
+	if _, err := controller.client.CoreV1().Services("tenant-b").Update(svc); err != nil {
+		t.Fatal(err)
+	}
+	if ev := fx.Wait("service"); ev == nil || ev.ID != "svc1" {
+		t.Fatalf("expected the service to be deleted, got %v", ev)
+	}
+	if svc, _ := controller.GetService(hostname1); svc != nil {
+		t.Fatalf("unexpected service %s, which is no longer exported", hostname1)
+	}
+}
+
 // Validates that when Pilot sees Endpoint before the corresponding Pod, it loads Pod from K8S and proceed.
 func TestEndpointUpdateBeforePodUpdate(t *testing.T) {
 	// Setup kube caches
@@ -181,6 +181,7 @@ func (e *endpointsController) InstancesByPort(c *Controller, svc *model.Service,
 				uid = createUID(pod.Name, pod.Namespace)
 			}
 			tlsMode := kube.PodTLSMode(pod)
+			nodeName := endpointNodeName(ea, pod)
 
 			// identify the port by name. K8S EndpointPort uses the service port name
 			for _, port := range ss.Ports {
@@ -198,6 +199,7 @@ func (e *endpointsController) InstancesByPort(c *Controller, svc *model.Service,
 							Labels:          podLabels,
 							ServiceAccount:  sa,
 							TLSMode:         tlsMode,
+							NodeName:        nodeName,
 						},
 						ServicePort: svcPortEntry,
 						Service:     svc,
@@ -253,6 +255,14 @@ func (e *endpointsController) onEvent(curr interface{}, event model.Event) error
 	return nil
 }
 
+func (e *endpointsController) SyncEndpoints(c *Controller, name, namespace string) {
+	obj, exists, err := e.informer.GetIndexer().GetByKey(kube.KeyFunc(name, namespace))
+	if err != nil || !exists {
+		return
+	}
+	c.updateEDS(obj.(*v1.Endpoints), model.EventUpdate)
+}
+
 func (e *endpointsController) HasSynced() bool {
 	return e.informer.HasSynced()
 }
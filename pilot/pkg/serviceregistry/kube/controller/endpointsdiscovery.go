@@ -28,4 +28,6 @@ type kubeEndpointsController interface {
 	InstancesByPort(c *Controller, svc *model.Service, reqSvcPort int,
 		labelsList labels.Collection) ([]*model.ServiceInstance, error)
 	GetProxyServiceInstances(c *Controller, proxy *model.Proxy, proxyNamespace string) []*model.ServiceInstance
+	// SyncEndpoints updates EDS with the current endpoints of the service.
+	SyncEndpoints(c *Controller, name, namespace string)
 }
@@ -55,6 +55,13 @@ func newEndpointSliceController(c *Controller, sharedInformers informers.SharedI
 	return out
 }
 
+func (e *endpointSliceController) SyncEndpoints(c *Controller, name, namespace string) {
+	hostname := kube.ServiceHostname(name, namespace, c.domainSuffix)
+	if endpoints := e.endpointCache.Get(hostname); len(endpoints) > 0 {
+		_ = c.xdsUpdater.EDSUpdate(c.clusterID, string(hostname), namespace, endpoints)
+	}
+}
+
 func (e *endpointSliceController) HasSynced() bool {
 	return e.informer.HasSynced()
 }
@@ -100,6 +107,7 @@ func (e *endpointSliceController) updateEDSSlice(c *Controller, slice *discovery
 				}
 
 				tlsMode := kube.PodTLSMode(pod)
+				nodeName := sliceEndpointNodeName(e.Topology, pod)
 
 				// EDS and ServiceEntry use name for service port - ADS will need to
 				// map to numbers.
@@ -123,6 +131,7 @@ func (e *endpointSliceController) updateEDSSlice(c *Controller, slice *discovery
 						Locality:        locality,
 						Attributes:      model.ServiceAttributes{Name: svcName, Namespace: slice.Namespace},
 						TLSMode:         tlsMode,
+						NodeName:        nodeName,
 					})
 				}
 			}
@@ -130,6 +139,10 @@ func (e *endpointSliceController) updateEDSSlice(c *Controller, slice *discovery
 	}
 
 	e.endpointCache.Update(hostname, slice.Name, endpoints)
+	if !c.importsEndpoints(hostname) {
+		// The cached endpoints are pushed once the service is imported.
+		return
+	}
 
 	log.Infof("Handle EDS endpoint %s in namespace %s", svcName, slice.Namespace)
 
@@ -277,6 +290,7 @@ func (e *endpointSliceController) InstancesByPort(c *Controller, svc *model.Serv
 				}
 				az := getLocalityFromTopology(e.Topology)
 				tlsMode := kube.PodTLSMode(pod)
+				nodeName := sliceEndpointNodeName(e.Topology, pod)
 
 				// identify the port by name. K8S EndpointPort uses the service port name
 				for _, port := range slice.Ports {
@@ -299,6 +313,7 @@ func (e *endpointSliceController) InstancesByPort(c *Controller, svc *model.Serv
 								Labels:          podLabels,
 								ServiceAccount:  sa,
 								TLSMode:         tlsMode,
+								NodeName:        nodeName,
 							},
 							ServicePort: svcPortEntry,
 							Service:     svc,
@@ -322,6 +337,17 @@ func getLocalityFromTopology(topology map[string]string) string {
 	return locality
 }
 
+// sliceEndpointNodeName returns the node of an endpoint of a slice, from its topology or its pod.
+func sliceEndpointNodeName(topology map[string]string, pod *v1.Pod) string {
+	if nodeName := topology[NodeHostnameLabel]; nodeName != "" {
+		return nodeName
+	}
+	if pod != nil {
+		return pod.Spec.NodeName
+	}
+	return ""
+}
+
 type endpointSliceCache struct {
 	mu                         sync.RWMutex
 	endpointsByServiceAndSlice map[host.Name]map[string][]*model.IstioEndpoint
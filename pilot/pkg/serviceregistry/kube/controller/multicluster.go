@@ -31,9 +31,17 @@ import (
 
 type kubeController struct {
 	rc     *Controller
+	client kubernetes.Interface
 	stopCh chan struct{}
 }
 
+// ClusterHandler is notified of the remote clusters added to and removed from the mesh, so that
+// an external control plane can set up the resources it needs in each of them.
+type ClusterHandler interface {
+	AddCluster(clientset kubernetes.Interface, clusterID string) error
+	DeleteCluster(clusterID string) error
+}
+
 // Multicluster structure holds the remote kube Controllers and multicluster specific attributes.
 type Multicluster struct {
 	WatchedNamespace  string
@@ -42,16 +50,19 @@ type Multicluster struct {
 	serviceController *aggregate.Controller
 	XDSUpdater        model.XDSUpdater
 
-	m                     sync.Mutex // protects remoteKubeControllers
+	m                     sync.Mutex // protects remoteKubeControllers and clusterHandlers
 	remoteKubeControllers map[string]*kubeController
+	clusterHandlers       []ClusterHandler
 	networksWatcher       mesh.NetworksWatcher
+	namespaceScope        *model.NamespaceScope
 }
 
 // NewMulticluster initializes data structure to store multicluster information
 // It also starts the secret controller
 func NewMulticluster(kc kubernetes.Interface, secretNamespace string,
 	watchedNamespace string, domainSuffix string, resyncPeriod time.Duration,
-	serviceController *aggregate.Controller, xds model.XDSUpdater, networksWatcher mesh.NetworksWatcher) (*Multicluster, error) {
+	serviceController *aggregate.Controller, xds model.XDSUpdater, networksWatcher mesh.NetworksWatcher,
+	namespaceScope *model.NamespaceScope) (*Multicluster, error) {
 
 	remoteKubeController := make(map[string]*kubeController)
 	if resyncPeriod == 0 {
@@ -67,6 +78,7 @@ func NewMulticluster(kc kubernetes.Interface, secretNamespace string,
 		XDSUpdater:            xds,
 		remoteKubeControllers: remoteKubeController,
 		networksWatcher:       networksWatcher,
+		namespaceScope:        namespaceScope,
 	}
 
 	err := secretcontroller.StartSecretController(kc,
@@ -84,6 +96,7 @@ func (m *Multicluster) AddMemberCluster(clientset kubernetes.Interface, clusterI
 	stopCh := make(chan struct{})
 	var remoteKubeController kubeController
 	remoteKubeController.stopCh = stopCh
+	remoteKubeController.client = clientset
 	m.m.Lock()
 	kubectl := NewController(clientset, Options{
 		WatchedNamespace: m.WatchedNamespace,
@@ -92,14 +105,22 @@ func (m *Multicluster) AddMemberCluster(clientset kubernetes.Interface, clusterI
 		XDSUpdater:       m.XDSUpdater,
 		ClusterID:        clusterID,
 		NetworksWatcher:  m.networksWatcher,
+		NamespaceScope:   m.namespaceScope,
 	})
 
 	remoteKubeController.rc = kubectl
 	m.serviceController.AddRegistry(kubectl)
 
 	m.remoteKubeControllers[clusterID] = &remoteKubeController
+	handlers := m.clusterHandlers
 	m.m.Unlock()
 
+	for _, h := range handlers {
+		if err := h.AddCluster(clientset, clusterID); err != nil {
+			log.Errorf("failed to set up remote cluster %s: %v", clusterID, err)
+		}
+	}
+
 	_ = kubectl.AppendServiceHandler(func(*model.Service, model.Event) { m.updateHandler() })
 	_ = kubectl.AppendInstanceHandler(func(*model.ServiceInstance, model.Event) { m.updateHandler() })
 	go kubectl.Run(stopCh)
@@ -112,14 +133,23 @@ func (m *Multicluster) AddMemberCluster(clientset kubernetes.Interface, clusterI
 func (m *Multicluster) DeleteMemberCluster(clusterID string) error {
 
 	m.m.Lock()
-	defer m.m.Unlock()
+	kc, ok := m.remoteKubeControllers[clusterID]
+	delete(m.remoteKubeControllers, clusterID)
+	handlers := m.clusterHandlers
+	m.m.Unlock()
+
 	m.serviceController.DeleteRegistry(clusterID)
-	if _, ok := m.remoteKubeControllers[clusterID]; !ok {
+	if !ok {
 		log.Infof("cluster %s does not exist, maybe caused by invalid kubeconfig", clusterID)
 		return nil
 	}
-	close(m.remoteKubeControllers[clusterID].stopCh)
-	delete(m.remoteKubeControllers, clusterID)
+	close(kc.stopCh)
+
+	for _, h := range handlers {
+		if err := h.DeleteCluster(clusterID); err != nil {
+			log.Errorf("failed to clean up remote cluster %s: %v", clusterID, err)
+		}
+	}
 	if m.XDSUpdater != nil {
 		m.XDSUpdater.ConfigUpdate(&model.PushRequest{Full: true})
 	}
@@ -127,6 +157,35 @@ func (m *Multicluster) DeleteMemberCluster(clusterID string) error {
 	return nil
 }
 
+// AddClusterHandler registers a handler of the remote clusters. The handler is called for the
+// clusters already added, then for every cluster added or removed.
+func (m *Multicluster) AddClusterHandler(h ClusterHandler) {
+	m.m.Lock()
+	m.clusterHandlers = append(m.clusterHandlers, h)
+	clients := make(map[string]kubernetes.Interface, len(m.remoteKubeControllers))
+	for clusterID, c := range m.remoteKubeControllers {
+		clients[clusterID] = c.client
+	}
+	m.m.Unlock()
+
+	// The handlers call the remote API servers, which must not block the other clusters.
+	for clusterID, client := range clients {
+		if err := h.AddCluster(client, clusterID); err != nil {
+			log.Errorf("failed to set up remote cluster %s: %v", clusterID, err)
+		}
+	}
+}
+
+// GetRemoteKubeClient returns the client of a remote cluster, or nil if the cluster is unknown.
+func (m *Multicluster) GetRemoteKubeClient(clusterID string) kubernetes.Interface {
+	m.m.Lock()
+	defer m.m.Unlock()
+	if c, ok := m.remoteKubeControllers[clusterID]; ok {
+		return c.client
+	}
+	return nil
+}
+
 func (m *Multicluster) updateHandler() {
 	if m.XDSUpdater != nil {
 		req := &model.PushRequest{
@@ -16,6 +16,7 @@ package controller
 
 import (
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -97,7 +98,7 @@ func Test_KubeSecretController(t *testing.T) {
 
 	clientset := fake.NewSimpleClientset()
 
-	mc, err := NewMulticluster(clientset, testSecretNameSpace, WatchedNamespace, DomainSuffix, ResyncPeriod, mockserviceController, nil, nil)
+	mc, err := NewMulticluster(clientset, testSecretNameSpace, WatchedNamespace, DomainSuffix, ResyncPeriod, mockserviceController, nil, nil, nil)
 
 	if err != nil {
 		t.Fatalf("error creating Multicluster object and startign secret controller: %v", err)
@@ -124,3 +125,83 @@ func Test_KubeSecretController(t *testing.T) {
 	verifyControllers(t, mc, 0, "delete remote controller")
 
 }
+
+type fakeClusterHandler struct {
+	mu       sync.Mutex
+	clusters map[string]kubernetes.Interface
+}
+
+func (h *fakeClusterHandler) AddCluster(clientset kubernetes.Interface, clusterID string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clusters == nil {
+		h.clusters = make(map[string]kubernetes.Interface)
+	}
+	h.clusters[clusterID] = clientset
+	return nil
+}
+
+func (h *fakeClusterHandler) DeleteCluster(clusterID string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.clusters, clusterID)
+	return nil
+}
+
+func (h *fakeClusterHandler) hasCluster(clusterID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.clusters[clusterID] != nil
+}
+
+func TestClusterHandlers(t *testing.T) {
+	if len(os.Getenv("RACE_TEST")) > 0 {
+		t.Skip("https://github.com/istio/istio/issues/15610")
+	}
+
+	secretcontroller.LoadKubeConfig = mockLoadKubeConfig
+	secretcontroller.ValidateClientConfig = mockValidateClientConfig
+	secretcontroller.CreateInterfaceFromClusterConfig = mockCreateInterfaceFromClusterConfig
+
+	clientset := fake.NewSimpleClientset()
+	mc, err := NewMulticluster(clientset, testSecretNameSpace, WatchedNamespace, DomainSuffix, ResyncPeriod,
+		aggregate.NewController(), nil, nil, nil)
+	if err != nil {
+		t.Fatalf("error creating Multicluster object and startign secret controller: %v", err)
+	}
+
+	before := &fakeClusterHandler{}
+	mc.AddClusterHandler(before)
+	if err := createMultiClusterSecret(clientset); err != nil {
+		t.Fatalf("Unexpected error on secret create: %v", err)
+	}
+	verifyControllers(t, mc, 1, "create remote controller")
+
+	// A handler registered after the cluster is added is called for it too.
+	after := &fakeClusterHandler{}
+	mc.AddClusterHandler(after)
+	for name, h := range map[string]*fakeClusterHandler{"before": before, "after": after} {
+		pkgtest.NewEventualOpts(10*time.Millisecond, 5*time.Second).Eventually(t, "handler registered "+name, func() bool {
+			return h.hasCluster("testRemoteCluster")
+		})
+	}
+	if mc.GetRemoteKubeClient("testRemoteCluster") == nil {
+		t.Errorf("expected the client of the remote cluster")
+	}
+	if mc.GetRemoteKubeClient("unknown") != nil {
+		t.Errorf("expected no client for an unknown cluster")
+	}
+
+	if err := deleteMultiClusterSecret(clientset); err != nil {
+		t.Fatalf("Unexpected error on secret delete: %v", err)
+	}
+	verifyControllers(t, mc, 0, "delete remote controller")
+	for name, h := range map[string]*fakeClusterHandler{"before": before, "after": after} {
+		if h.hasCluster("testRemoteCluster") {
+			t.Errorf("handler registered %s the cluster was not called on delete", name)
+		}
+	}
+	if mc.GetRemoteKubeClient("testRemoteCluster") != nil {
+		t.Errorf("expected no client for a deleted cluster")
+	}
+}
@@ -39,10 +39,19 @@ type PodCache struct {
 	// this allows us to retrieve the latest status by pod IP.
 	// This should only contain RUNNING or PENDING pods with an allocated IP.
 	podsByIP map[string]string
+	// workloads are the running and pending pods with an IP, which ServiceEntries may select,
+	// by pod key. It is nil unless ServiceEntries select workloads.
+	workloads map[string]workload
 
 	c *Controller
 }
 
+// workload is what the ServiceEntries selecting a pod depend on.
+type workload struct {
+	ip     string
+	labels labels.Instance
+}
+
 func newPodCache(informer cache.SharedIndexInformer, c *Controller) *PodCache {
 	out := &PodCache{
 		informer: informer,
@@ -53,11 +62,8 @@ func newPodCache(informer cache.SharedIndexInformer, c *Controller) *PodCache {
 	return out
 }
 
-// onEvent updates the IP-based index (pc.podsByIP).
+// onEvent updates the IP-based index (pc.podsByIP) and the workloads.
 func (pc *PodCache) onEvent(curr interface{}, ev model.Event) error {
-	pc.Lock()
-	defer pc.Unlock()
-
 	// When a pod is deleted obj could be an *v1.Pod or a DeletionFinalStateUnknown marker item.
 	pod, ok := curr.(*v1.Pod)
 	if !ok {
@@ -71,6 +77,20 @@ func (pc *PodCache) onEvent(curr interface{}, ev model.Event) error {
 		}
 	}
 
+	pc.Lock()
+	pc.update(pod, ev)
+	changed := pc.updateWorkload(pod, ev)
+	pc.Unlock()
+
+	// The workload handlers list the pods, they are notified outside of the lock.
+	if changed != nil {
+		pc.workloadUpdates(pod.Namespace, changed)
+	}
+	return nil
+}
+
+// update updates the IP-based index with the pod.
+func (pc *PodCache) update(pod *v1.Pod, ev model.Event) {
 	ip := pod.Status.PodIP
 	// PodIP will be empty when pod is just created, but before the IP is assigned
 	// via UpdateStatus.
@@ -94,7 +114,7 @@ func (pc *PodCache) onEvent(curr interface{}, ev model.Event) error {
 				if pc.podsByIP[ip] == key {
 					delete(pc.podsByIP, ip)
 				}
-				return nil
+				return
 			}
 			switch pod.Status.Phase {
 			case v1.PodPending, v1.PodRunning:
@@ -117,7 +137,76 @@ func (pc *PodCache) onEvent(curr interface{}, ev model.Event) error {
 			}
 		}
 	}
-	return nil
+}
+
+// isWorkload returns whether the pod is a workload which ServiceEntries may select, like in
+// Controller.WorkloadsByLabels.
+func isWorkload(pod *v1.Pod) bool {
+	if pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+		return false
+	}
+	return pod.Status.Phase == v1.PodPending || pod.Status.Phase == v1.PodRunning
+}
+
+// trackWorkloads starts tracking the workloads from the pods in the informer, or stops tracking them.
+func (pc *PodCache) trackWorkloads(enabled bool) {
+	pc.Lock()
+	defer pc.Unlock()
+	if !enabled {
+		pc.workloads = nil
+		return
+	}
+	if pc.workloads != nil {
+		return
+	}
+	pc.workloads = make(map[string]workload)
+	if pc.informer == nil {
+		return
+	}
+	for _, obj := range pc.informer.GetStore().List() {
+		if pod, ok := obj.(*v1.Pod); ok && isWorkload(pod) {
+			pc.workloads[kube.KeyFunc(pod.Name, pod.Namespace)] = workload{ip: pod.Status.PodIP, labels: configKube.ConvertLabels(pod.ObjectMeta)}
+		}
+	}
+}
+
+// updateWorkload updates the workload of the pod, and returns its labels before and after the
+// event if the ServiceEntries selecting it must be updated, or nil. Nothing is tracked unless
+// ServiceEntries select workloads.
+func (pc *PodCache) updateWorkload(pod *v1.Pod, ev model.Event) labels.Collection {
+	if pc.workloads == nil {
+		return nil
+	}
+	key := kube.KeyFunc(pod.Name, pod.Namespace)
+	prev, existed := pc.workloads[key]
+	if ev == model.EventDelete || !isWorkload(pod) {
+		if !existed {
+			return nil
+		}
+		delete(pc.workloads, key)
+		return labels.Collection{prev.labels}
+	}
+
+	curr := workload{ip: pod.Status.PodIP, labels: configKube.ConvertLabels(pod.ObjectMeta)}
+	pc.workloads[key] = curr
+	if !existed {
+		return labels.Collection{curr.labels}
+	}
+	if prev.ip == curr.ip && prev.labels.Equals(curr.labels) {
+		return nil
+	}
+	return labels.Collection{prev.labels, curr.labels}
+}
+
+// workloadUpdates notifies the workload handlers of a change to a pod, with its labels before
+// and after the change.
+func (pc *PodCache) workloadUpdates(namespace string, podLabels labels.Collection) {
+	if pc.c == nil {
+		return
+	}
+	for _, f := range pc.c.workloadHandlers {
+		f(namespace, podLabels)
+	}
 }
 
 func (pc *PodCache) proxyUpdates(ip string) {
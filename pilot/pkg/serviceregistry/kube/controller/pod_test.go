@@ -218,3 +218,81 @@ func TestPodCacheEvents(t *testing.T) {
 		t.Errorf("getPodKey => got %s, want none", pod)
 	}
 }
+
+// Checks that the workload handlers are only notified when a pod selectable by ServiceEntries changes
+func TestPodCacheWorkloadUpdates(t *testing.T) {
+	c, _ := newFakeController()
+	defer c.Stop()
+	var notified []labels.Collection
+	c.AppendWorkloadHandler(func(namespace string, podLabels labels.Collection) {
+		if namespace != "default" {
+			t.Errorf("unexpected namespace %s", namespace)
+		}
+		notified = append(notified, podLabels)
+	})
+	podCache := newPodCache(nil, c)
+	podCache.trackWorkloads(true)
+
+	meta := func(app string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: "pod1", Namespace: "default", Labels: map[string]string{"app": app}}
+	}
+	running := v1.PodStatus{PodIP: "172.0.3.35", Phase: v1.PodRunning}
+	cases := []struct {
+		name   string
+		pod    *v1.Pod
+		event  model.Event
+		expect labels.Collection
+	}{
+		{
+			name:  "no ip",
+			pod:   &v1.Pod{ObjectMeta: meta("a"), Status: v1.PodStatus{Phase: v1.PodPending}},
+			event: model.EventAdd,
+		},
+		{
+			name:   "running",
+			pod:    &v1.Pod{ObjectMeta: meta("a"), Status: running},
+			event:  model.EventUpdate,
+			expect: labels.Collection{{"app": "a"}},
+		},
+		{
+			name:  "unchanged",
+			pod:   &v1.Pod{ObjectMeta: meta("a"), Status: running},
+			event: model.EventUpdate,
+		},
+		{
+			name:   "relabeled",
+			pod:    &v1.Pod{ObjectMeta: meta("b"), Status: running},
+			event:  model.EventUpdate,
+			expect: labels.Collection{{"app": "a"}, {"app": "b"}},
+		},
+		{
+			name:   "deleted",
+			pod:    &v1.Pod{ObjectMeta: meta("b"), Status: running},
+			event:  model.EventDelete,
+			expect: labels.Collection{{"app": "b"}},
+		},
+	}
+	for _, tc := range cases {
+		notified = nil
+		if err := podCache.onEvent(tc.pod, tc.event); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		var want []labels.Collection
+		if tc.expect != nil {
+			want = []labels.Collection{tc.expect}
+		}
+		if !reflect.DeepEqual(notified, want) {
+			t.Errorf("%s: expected notifications %v, got %v", tc.name, want, notified)
+		}
+	}
+
+	// Without ServiceEntries selecting workloads, the changes are not tracked.
+	podCache.trackWorkloads(false)
+	notified = nil
+	if err := podCache.onEvent(&v1.Pod{ObjectMeta: meta("a"), Status: running}, model.EventAdd); err != nil {
+		t.Fatal(err)
+	}
+	if notified != nil || podCache.workloads != nil {
+		t.Errorf("expected the workloads not to be tracked, got %v", notified)
+	}
+}
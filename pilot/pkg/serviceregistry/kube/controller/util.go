@@ -35,3 +35,14 @@ func getLabelValue(node *v1.Node, label string, fallBackLabel string) string {
 
 	return node.Labels[fallBackLabel]
 }
+
+// endpointNodeName returns the node of an endpoint address, from the address or its pod.
+func endpointNodeName(addr v1.EndpointAddress, pod *v1.Pod) string {
+	if addr.NodeName != nil && *addr.NodeName != "" {
+		return *addr.NodeName
+	}
+	if pod != nil {
+		return pod.Spec.NodeName
+	}
+	return ""
+}
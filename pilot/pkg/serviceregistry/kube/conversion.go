@@ -43,6 +43,19 @@ const (
 	// responsible for it
 	IngressClassAnnotation = "kubernetes.io/ingress.class"
 
+	// InternalTrafficPolicyAnnotation is the annotation on services for their internal traffic policy,
+	// "Cluster" (the default) or "Local". It stands for the internalTrafficPolicy field of the service
+	// spec, which the Kubernetes API used by Pilot doesn't have yet.
+	InternalTrafficPolicyAnnotation = "networking.istio.io/internalTrafficPolicy"
+
+	// TopologyKeysAnnotation is the annotation on services for the comma separated, ordered list of
+	// their topology keys. It stands for the topologyKeys field of the service spec, which the
+	// Kubernetes API used by Pilot doesn't have yet.
+	TopologyKeysAnnotation = "networking.istio.io/topologyKeys"
+
+	// trafficPolicyLocal is the traffic policy restricting the endpoints to the node of the client.
+	trafficPolicyLocal = "Local"
+
 	managementPortPrefix = "mgmt-"
 )
 
@@ -79,6 +92,8 @@ func ConvertService(svc coreV1.Service, domainSuffix string, clusterID string) *
 	}
 
 	var exportTo map[visibility.Instance]bool
+	var topologyKeys []string
+	internalTrafficLocal := false
 	serviceaccounts := make([]string, 0)
 	if svc.Annotations != nil {
 		if svc.Annotations[annotation.AlphaCanonicalServiceAccounts.Name] != "" {
@@ -95,6 +110,12 @@ func ConvertService(svc coreV1.Service, domainSuffix string, clusterID string) *
 				exportTo[visibility.Instance(e)] = true
 			}
 		}
+		internalTrafficLocal = svc.Annotations[InternalTrafficPolicyAnnotation] == trafficPolicyLocal
+		if svc.Annotations[TopologyKeysAnnotation] != "" {
+			for _, key := range strings.Split(svc.Annotations[TopologyKeysAnnotation], ",") {
+				topologyKeys = append(topologyKeys, strings.TrimSpace(key))
+			}
+		}
 	}
 	sort.Strings(serviceaccounts)
 
@@ -112,6 +133,9 @@ func ConvertService(svc coreV1.Service, domainSuffix string, clusterID string) *
 			Namespace:       svc.Namespace,
 			UID:             fmt.Sprintf("istio://%s/services/%s", svc.Namespace, svc.Name),
 			ExportTo:        exportTo,
+
+			InternalTrafficLocal: internalTrafficLocal,
+			TopologyKeys:         topologyKeys,
 		},
 	}
 
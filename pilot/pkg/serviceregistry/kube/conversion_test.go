@@ -249,6 +249,51 @@ func TestServiceConversionWithEmptyServiceAccountsAnnotation(t *testing.T) {
 	}
 }
 
+func TestServiceConversionWithTrafficPolicy(t *testing.T) {
+	localSvc := coreV1.Service{
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      "service1",
+			Namespace: "default",
+			Annotations: map[string]string{
+				InternalTrafficPolicyAnnotation: "Local",
+				TopologyKeysAnnotation:          "kubernetes.io/hostname, topology.kubernetes.io/zone,*",
+			},
+		},
+		Spec: coreV1.ServiceSpec{
+			ClusterIP:             "10.0.0.1",
+			Type:                  coreV1.ServiceTypeLoadBalancer,
+			ExternalTrafficPolicy: coreV1.ServiceExternalTrafficPolicyTypeLocal,
+			Ports: []coreV1.ServicePort{
+				{
+					Name:     "http",
+					Port:     8080,
+					Protocol: coreV1.ProtocolTCP,
+				},
+			},
+		},
+	}
+
+	service := ConvertService(localSvc, domainSuffix, clusterID)
+	if service == nil {
+		t.Fatalf("could not convert service")
+	}
+	if !service.Attributes.InternalTrafficLocal {
+		t.Errorf("expected the internal traffic policy to be local")
+	}
+	expectedKeys := []string{"kubernetes.io/hostname", "topology.kubernetes.io/zone", "*"}
+	if !reflect.DeepEqual(service.Attributes.TopologyKeys, expectedKeys) {
+		t.Errorf("expected topology keys %v, got %v", expectedKeys, service.Attributes.TopologyKeys)
+	}
+
+	localSvc.Annotations = nil
+	localSvc.Spec.ExternalTrafficPolicy = coreV1.ServiceExternalTrafficPolicyTypeCluster
+	service = ConvertService(localSvc, domainSuffix, clusterID)
+	if service.Attributes.InternalTrafficLocal ||
+		len(service.Attributes.TopologyKeys) != 0 {
+		t.Errorf("expected no traffic policy, got %+v", service.Attributes)
+	}
+}
+
 func TestExternalServiceConversion(t *testing.T) {
 	serviceName := "service1"
 	namespace := "default"
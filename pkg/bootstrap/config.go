@@ -46,9 +46,11 @@ const (
 
 	lightstepAccessTokenBase = "lightstep_access_token.txt"
 
-	// required stats are used by readiness checks.
-	requiredEnvoyStatsMatcherInclusionPrefixes = "cluster_manager,listener_manager,http_mixer_filter,tcp_mixer_filter,server,cluster.xds-grpc"
-	requiredEnvoyStatsMatcherInclusionSuffix   = "ssl_context_update_by_sds"
+	// required stats are used by readiness checks, and the http_inspector and
+	// downstream_pre_cx_timeout stats report the outcome of protocol detection.
+	requiredEnvoyStatsMatcherInclusionPrefixes = "cluster_manager,listener_manager,http_mixer_filter,tcp_mixer_filter,server,cluster.xds-grpc," +
+		"http_inspector"
+	requiredEnvoyStatsMatcherInclusionSuffix = "ssl_context_update_by_sds,downstream_pre_cx_timeout"
 
 	// Prefixes of V2 metrics.
 	// "reporter" prefix is for istio standard metrics.
@@ -0,0 +1,113 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package annotation defines alpha annotations that are not yet part of the
+// istio.io/api annotation table. Entries should move to istio.io/api once they
+// are stable.
+package annotation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"istio.io/api/annotation"
+)
+
+var (
+	// SidecarProtocolSniffing overrides the mesh wide protocol sniffing settings for a workload.
+	SidecarProtocolSniffing = annotation.Instance{
+		Name: "sidecar.istio.io/protocolSniffing",
+		Description: "Enables (true) or disables (false) protocol sniffing for the inbound and " +
+			"outbound listeners of the workload, overriding the mesh wide settings.",
+		Resources: []annotation.ResourceTypes{annotation.Pod},
+	}
+
+	// SidecarProtocolDetectionTimeout overrides the protocol detection timeout for a workload.
+	SidecarProtocolDetectionTimeout = annotation.Instance{
+		Name: "sidecar.istio.io/protocolDetectionTimeout",
+		Description: "Specifies how long the sidecar waits for the first bytes of a connection " +
+			"before falling back to plain TCP, in duration format (e.g. 500ms).",
+		Resources: []annotation.ResourceTypes{annotation.Pod},
+	}
+
+	// SidecarProtocolSniffingExcludePorts disables protocol sniffing for some ports of a workload.
+	SidecarProtocolSniffingExcludePorts = annotation.Instance{
+		Name: "sidecar.istio.io/protocolSniffingExcludePorts",
+		Description: "A comma separated list of ports for which protocol sniffing is disabled, so that " +
+			"connections are handled as plain TCP. The ports are matched against the container ports of the " +
+			"workload for inbound traffic, and against the service ports for outbound traffic.",
+		Resources: []annotation.ResourceTypes{annotation.Pod},
+	}
+
+	// NetworkingMaxRequestBytes overrides the mesh wide request buffering limit for the routes of a
+	// virtual service.
+	NetworkingMaxRequestBytes = annotation.Instance{
+		Name: "networking.istio.io/maxRequestBytes",
+		Description: "Set on a virtual service, overrides the mesh wide request buffering limit " +
+			"(PILOT_HTTP_MAX_REQUEST_BYTES) for its routes, in bytes. A value of 0 disables buffering.",
+		Resources: []annotation.ResourceTypes{annotation.Any},
+	}
+
+	// NetworkingCompression overrides the mesh wide response compression for the routes of a virtual
+	// service.
+	NetworkingCompression = annotation.Instance{
+		Name: "networking.istio.io/compression",
+		Description: "Set on a virtual service, enables (on) or disables (off) the compression of the " +
+			"responses of its routes, overriding the mesh wide setting (PILOT_ENABLE_HTTP_COMPRESSION).",
+		Resources: []annotation.ResourceTypes{annotation.Any},
+	}
+)
+
+// ParseMaxRequestBytes returns the limit of a NetworkingMaxRequestBytes annotation value.
+func ParseMaxRequestBytes(value string) (uint32, error) {
+	limit, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(limit), nil
+}
+
+// ParseCompression returns whether a NetworkingCompression annotation value enables compression.
+func ParseCompression(value string) (bool, error) {
+	switch value {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("only \"on\" and \"off\" are supported")
+}
+
+// ProtocolSniffingExcludesPort returns true if the value of the SidecarProtocolSniffingExcludePorts
+// annotation lists the port. Invalid entries are ignored, as the injector rejects them.
+func ProtocolSniffingExcludesPort(value string, port int) bool {
+	for _, p := range strings.Split(value, ",") {
+		if excluded, err := strconv.Atoi(strings.TrimSpace(p)); err == nil && excluded == port {
+			return true
+		}
+	}
+	return false
+}
+
+// All returns the annotations defined in this package.
+func All() []*annotation.Instance {
+	return []*annotation.Instance{
+		&SidecarProtocolSniffing,
+		&SidecarProtocolDetectionTimeout,
+		&SidecarProtocolSniffingExcludePorts,
+		&NetworkingMaxRequestBytes,
+		&NetworkingCompression,
+	}
+}
@@ -33,13 +33,25 @@ const (
 
 var (
 	// Ports be skipped for protocol sniffing. Applications bound to these ports will be broken if
-	// protocol sniffing is enabled.
+	// protocol sniffing is enabled. The ServerFirstPorts are skipped as well.
 	wellKnownPorts = map[int32]struct{}{
 		SMTP:    {},
 		DNS:     {},
 		MySQL:   {},
 		MongoDB: {},
 	}
+
+	// ServerFirstPorts are the well known ports of protocols where the server sends the first bytes,
+	// by protocol name. Protocol detection waits for the client to speak, so connections on these
+	// ports only proceed once detection times out.
+	ServerFirstPorts = map[int32]string{
+		21:    "FTP",
+		SMTP:  "SMTP",
+		110:   "POP3",
+		143:   "IMAP",
+		587:   "SMTP",
+		MySQL: "MySQL",
+	}
 )
 
 func ConvertLabels(obj metaV1.ObjectMeta) labels.Instance {
@@ -77,6 +89,9 @@ func ConvertProtocol(port int32, name string, proto coreV1.Protocol) protocol.In
 		if _, has := wellKnownPorts[port]; has {
 			return protocol.TCP
 		}
+		if _, has := ServerFirstPorts[port]; has {
+			return protocol.TCP
+		}
 	}
 	return p
 }
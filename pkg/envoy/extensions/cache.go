@@ -0,0 +1,410 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package extensions handles the extension modules, such as Wasm filter code, referenced by URL in
+// the Envoy config. pilot-agent downloads the modules of the bootstrap config, verifies their digest
+// and rewrites the config to load them from local files. The modules of the EnvoyFilters are pushed
+// over xDS, which pilot-agent doesn't see: Pilot pins them to their digest and to a cluster of their
+// host, and Envoy fetches them and verifies their digest.
+package extensions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	"istio.io/pkg/log"
+)
+
+const (
+	// extensionFetchTimeout bounds the download of a single extension module.
+	extensionFetchTimeout = time.Minute
+)
+
+// Cache downloads the extension modules, such as Lua or Wasm filter code, referenced by URL in
+// the Envoy config, and keeps them on local disk so that Envoy loads them from a file.
+//
+// Modules are referenced with the remote form of the Envoy AsyncDataSource, which requires a SHA-256
+// digest:
+//
+//   remote:
+//     http_uri:
+//       uri: https://example.com/filter.wasm
+//     sha256: <hex digest>
+//
+// A module is only written to the cache after its digest is verified, and is stored under its digest,
+// so that a module served by several URLs is downloaded once. The least recently used modules are
+// evicted when the cache grows above its maximum size.
+type Cache struct {
+	dir      string
+	maxBytes int64
+	client   *http.Client
+
+	mu sync.Mutex
+}
+
+// NewCache creates a cache of extension modules in the directory, created on first use.
+func NewCache(dir string, maxBytes int64) *Cache {
+	return &Cache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		client:   &http.Client{Timeout: extensionFetchTimeout},
+	}
+}
+
+// Get returns the path of the module with the SHA-256 digest, downloading it from the URL if it is
+// not cached.
+func (c *Cache) Get(url, digest string) (string, error) {
+	digest = strings.ToLower(digest)
+	if err := checkRemote(url, digest); err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p := filepath.Join(c.dir, digest)
+	if _, err := os.Stat(p); err == nil {
+		// Mark the module as recently used.
+		now := time.Now()
+		_ = os.Chtimes(p, now, now)
+		return p, nil
+	}
+	if err := c.download(url, digest, p); err != nil {
+		return "", err
+	}
+	log.Infof("Downloaded extension %s to %s", url, p)
+	return p, nil
+}
+
+// download writes the module to the path, if its digest matches.
+func (c *Cache) download(url, digest, p string) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create the extension cache: %v", err)
+	}
+	resp, err := c.client.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to download extension %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download extension %s: status %d", url, resp.StatusCode)
+	}
+
+	tmp, err := ioutil.TempFile(c.dir, digest+".tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create the extension file: %v", err)
+	}
+	defer os.Remove(tmp.Name()) // nolint: errcheck
+	h := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmp, h), resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download extension %s: %v", url, err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != digest {
+		return fmt.Errorf("extension %s has SHA-256 digest %s, expected %s", url, got, digest)
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return fmt.Errorf("failed to write the extension file: %v", err)
+	}
+	c.evict(p)
+	return nil
+}
+
+// evict removes the least recently used modules until the cache fits its maximum size. The module
+// just added is never evicted.
+func (c *Cache) evict(keep string) {
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		log.Warnf("Failed to list the extension cache: %v", err)
+		return
+	}
+	var size int64
+	for _, f := range files {
+		size += f.Size()
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+	for _, f := range files {
+		if size <= c.maxBytes {
+			return
+		}
+		p := filepath.Join(c.dir, f.Name())
+		if p == keep {
+			continue
+		}
+		if err := os.Remove(p); err != nil {
+			log.Warnf("Failed to evict extension %s: %v", p, err)
+			continue
+		}
+		log.Infof("Evicted extension %s", p)
+		size -= f.Size()
+	}
+}
+
+// RewriteConfig replaces the remote data sources of the JSON or YAML Envoy config with the local files
+// of the cache. The config is returned as is if it has no remote data source. An extension whose
+// module can't be fetched is left out of the config, rather than keeping Envoy from starting: the
+// element of the enclosing list, such as the filter, or else the top level field holding it.
+func (c *Cache) RewriteConfig(config []byte) ([]byte, bool, error) {
+	doc, err := parseConfig(config)
+	if err != nil {
+		return nil, false, err
+	}
+	fetch := func(source map[string]interface{}, url, digest string) error {
+		p, err := c.Get(url, digest)
+		if err != nil {
+			log.Errorf("Leaving out extension %s: %v", url, err)
+			return err
+		}
+		delete(source, "remote")
+		source["local"] = map[string]interface{}{"filename": p}
+		return nil
+	}
+	rewritten := false
+	if fields, ok := doc.(map[string]interface{}); ok {
+		for key, child := range fields {
+			out, found, failed := rewriteRemote(child, fetch)
+			rewritten = rewritten || found
+			if failed {
+				delete(fields, key)
+				continue
+			}
+			fields[key] = out
+		}
+	} else {
+		var failed bool
+		doc, rewritten, failed = rewriteRemote(doc, fetch)
+		if failed {
+			return nil, false, fmt.Errorf("failed to fetch the extensions of the config")
+		}
+	}
+	if !rewritten {
+		return config, false, nil
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	return out, true, nil
+}
+
+// RewriteFile rewrites the Envoy config file, and returns the file Envoy must load: dst if the config
+// references remote modules, src otherwise. dst may be the same as src.
+func (c *Cache) RewriteFile(src, dst string) (string, error) {
+	config, err := ioutil.ReadFile(src)
+	if err != nil {
+		return "", err
+	}
+	out, rewritten, err := c.RewriteConfig(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to rewrite the extensions of %s: %v", src, err)
+	}
+	if !rewritten {
+		return src, nil
+	}
+	if err := ioutil.WriteFile(dst, out, 0644); err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
+// Module is the host serving extension modules to Envoy.
+type Module struct {
+	Host string
+	Port int
+	TLS  bool
+}
+
+// ClusterName returns the name of the cluster Envoy fetches the modules of the host from.
+func (m Module) ClusterName() string {
+	return fmt.Sprintf("extension|%s|%d", m.Host, m.Port)
+}
+
+// Pin checks the remote data sources of the JSON or YAML Envoy config, which Envoy fetches itself,
+// and completes them for Envoy: the HTTP(S) URL is fetched from the cluster of its host unless the
+// source sets one, within extensionFetchTimeout unless it sets a timeout. Envoy verifies the SHA-256
+// digest the sources must have. The config is returned as JSON, with the hosts whose cluster must be
+// pushed with it.
+func Pin(config []byte) ([]byte, []Module, error) {
+	doc, err := parseConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	var modules []Module
+	_, err = visitRemote(doc, func(source map[string]interface{}, uri, digest string) error {
+		if err := checkRemote(uri, strings.ToLower(digest)); err != nil {
+			return err
+		}
+		remote := source["remote"].(map[string]interface{})
+		for _, key := range []string{"http_uri", "httpUri"} {
+			httpURI, ok := remote[key].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if _, ok := httpURI["cluster"]; !ok {
+				m := moduleOf(uri)
+				httpURI["cluster"] = m.ClusterName()
+				modules = append(modules, m)
+			}
+			if _, ok := httpURI["timeout"]; !ok {
+				httpURI["timeout"] = fmt.Sprintf("%.0fs", extensionFetchTimeout.Seconds())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, modules, nil
+}
+
+// moduleOf returns the host of the URL, checked by checkRemote.
+func moduleOf(uri string) Module {
+	u, _ := url.Parse(uri)
+	m := Module{Host: u.Hostname(), TLS: u.Scheme == "https"}
+	m.Port, _ = strconv.Atoi(u.Port())
+	if m.Port == 0 {
+		m.Port = 80
+		if m.TLS {
+			m.Port = 443
+		}
+	}
+	return m
+}
+
+func parseConfig(config []byte) (interface{}, error) {
+	js, err := yaml.YAMLToJSON(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the config: %v", err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(js, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse the config: %v", err)
+	}
+	return doc, nil
+}
+
+// checkRemote checks the URL and the lower case digest of a remote data source.
+func checkRemote(uri, digest string) error {
+	if u, err := url.Parse(uri); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("extension %s has an invalid URL, only HTTP(S) URLs are supported", uri)
+	}
+	if digest == "" {
+		return fmt.Errorf("extension %s has no SHA-256 digest", uri)
+	}
+	if _, err := hex.DecodeString(digest); err != nil || len(digest) != sha256.Size*2 {
+		return fmt.Errorf("extension %s has an invalid SHA-256 digest %q", uri, digest)
+	}
+	return nil
+}
+
+// visitRemote calls fn with the data sources of the decoded JSON document having a remote form, and
+// reports whether any was found.
+func visitRemote(doc interface{}, fn func(source map[string]interface{}, url, digest string) error) (bool, error) {
+	found := false
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		if remote, ok := v["remote"].(map[string]interface{}); ok {
+			if url := remoteURL(remote); url != "" {
+				digest, _ := remote["sha256"].(string)
+				return true, fn(v, url, digest)
+			}
+		}
+		for _, child := range v {
+			f, err := visitRemote(child, fn)
+			if err != nil {
+				return false, err
+			}
+			found = found || f
+		}
+	case []interface{}:
+		for _, child := range v {
+			f, err := visitRemote(child, fn)
+			if err != nil {
+				return false, err
+			}
+			found = found || f
+		}
+	}
+	return found, nil
+}
+
+// rewriteRemote calls fn with the data sources of the decoded JSON document having a remote form, and
+// returns the document without the elements of the lists holding a data source fn failed on. It
+// reports whether any data source was found, and whether fn failed on one outside of any list.
+func rewriteRemote(doc interface{}, fn func(source map[string]interface{}, url, digest string) error) (interface{}, bool, bool) {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		if remote, ok := v["remote"].(map[string]interface{}); ok {
+			if url := remoteURL(remote); url != "" {
+				digest, _ := remote["sha256"].(string)
+				return v, true, fn(v, url, digest) != nil
+			}
+		}
+		found, failed := false, false
+		for key, child := range v {
+			out, f, fl := rewriteRemote(child, fn)
+			v[key] = out
+			found, failed = found || f, failed || fl
+		}
+		return v, found, failed
+	case []interface{}:
+		found := false
+		out := make([]interface{}, 0, len(v))
+		for _, child := range v {
+			c, f, failed := rewriteRemote(child, fn)
+			found = found || f
+			if !failed {
+				out = append(out, c)
+			}
+		}
+		return out, found, false
+	}
+	return doc, false, false
+}
+
+// remoteURL returns the URI of a RemoteDataSource, in either the proto or the JSON field name.
+func remoteURL(remote map[string]interface{}) string {
+	for _, key := range []string{"http_uri", "httpUri"} {
+		if httpURI, ok := remote[key].(map[string]interface{}); ok {
+			if uri, ok := httpURI["uri"].(string); ok {
+				return uri
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,341 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extensions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// extensionServer serves extension modules by path, and counts the downloads.
+type extensionServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	modules   map[string][]byte
+	downloads map[string]int
+}
+
+func newExtensionServer(modules map[string][]byte) *extensionServer {
+	s := &extensionServer{modules: modules, downloads: map[string]int{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		module, ok := s.modules[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.downloads[r.URL.Path]++
+		_, _ = w.Write(module)
+	}))
+	return s
+}
+
+func (s *extensionServer) downloadCount(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.downloads[path]
+}
+
+func digestOf(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestCacheGet(t *testing.T) {
+	filter := []byte("function envoy_on_request(handle) end")
+	server := newExtensionServer(map[string][]byte{"/filter.lua": filter})
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "extensions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cache := NewCache(filepath.Join(dir, "cache"), 1<<20)
+
+	testCases := []struct {
+		name        string
+		url         string
+		digest      string
+		expectedErr string
+	}{
+		{
+			name:   "verified module",
+			url:    server.URL + "/filter.lua",
+			digest: digestOf(filter),
+		},
+		{
+			name:   "upper case digest",
+			url:    server.URL + "/filter.lua",
+			digest: strings.ToUpper(digestOf(filter)),
+		},
+		{
+			name:        "digest mismatch",
+			url:         server.URL + "/filter.lua",
+			digest:      digestOf([]byte("other")),
+			expectedErr: "has SHA-256 digest " + digestOf(filter),
+		},
+		{
+			name:        "invalid digest",
+			url:         server.URL + "/filter.lua",
+			digest:      "abc",
+			expectedErr: "has an invalid SHA-256 digest",
+		},
+		{
+			name:        "not found",
+			url:         server.URL + "/missing.lua",
+			digest:      digestOf([]byte("missing")),
+			expectedErr: "status 404",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := cache.Get(tc.url, tc.digest)
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("want error %q but got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := ioutil.ReadFile(p)
+			if err != nil || string(got) != string(filter) {
+				t.Fatalf("unexpected module %q: %v", got, err)
+			}
+		})
+	}
+
+	// The verified module is downloaded once, and the rejected one is not kept.
+	if n := server.downloadCount("/filter.lua"); n != 2 {
+		t.Errorf("expected a download of the verified module and of the rejected one, got %d", n)
+	}
+	files, _ := ioutil.ReadDir(filepath.Join(dir, "cache"))
+	if len(files) != 1 || files[0].Name() != digestOf(filter) {
+		t.Errorf("expected only the verified module in the cache, got %v", files)
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	modules := map[string][]byte{}
+	for _, name := range []string{"a", "b", "c"} {
+		modules["/"+name] = []byte(strings.Repeat(name, 100))
+	}
+	server := newExtensionServer(modules)
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "extensions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// Room for two modules.
+	cache := NewCache(dir, 250)
+
+	get := func(name string) {
+		t.Helper()
+		if _, err := cache.Get(server.URL+"/"+name, digestOf(modules["/"+name])); err != nil {
+			t.Fatalf("failed to get %s: %v", name, err)
+		}
+	}
+	get("a")
+	// Module times have a limited resolution on some file systems.
+	time.Sleep(10 * time.Millisecond)
+	get("b")
+	time.Sleep(10 * time.Millisecond)
+	// a is now more recently used than b.
+	get("a")
+	time.Sleep(10 * time.Millisecond)
+	get("c")
+
+	for name, cached := range map[string]bool{"a": true, "b": false, "c": true} {
+		_, err := os.Stat(filepath.Join(dir, digestOf(modules["/"+name])))
+		if cached != (err == nil) {
+			t.Errorf("module %s: expected cached %v, got %v", name, cached, err)
+		}
+	}
+	if n := server.downloadCount("/a"); n != 1 {
+		t.Errorf("expected module a to be downloaded once, got %d", n)
+	}
+}
+
+func TestCacheRewriteConfig(t *testing.T) {
+	filter := []byte("wasm")
+	server := newExtensionServer(map[string][]byte{"/filter.wasm": filter})
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "extensions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cache := NewCache(dir, 1<<20)
+
+	config := fmt.Sprintf(`
+static_resources:
+  listeners:
+  - name: inbound
+    filter_chains:
+    - filters:
+      - name: envoy.http_connection_manager
+        config:
+          http_filters:
+          - name: envoy.filters.http.wasm
+            config:
+              config:
+                vm_config:
+                  code:
+                    remote:
+                      http_uri:
+                        uri: %s/filter.wasm
+                      sha256: %s
+`, server.URL, digestOf(filter))
+
+	out, rewritten, err := cache.RewriteConfig([]byte(config))
+	if err != nil || !rewritten {
+		t.Fatalf("failed to rewrite the config: %v %v", rewritten, err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("rewritten config is not JSON: %v", err)
+	}
+	if strings.Contains(string(out), "remote") {
+		t.Errorf("expected no remote data source, got %s", out)
+	}
+	if want := fmt.Sprintf(`"local":{"filename":%q}`, filepath.Join(dir, digestOf(filter))); !strings.Contains(string(out), want) {
+		t.Errorf("expected %s, got %s", want, out)
+	}
+
+	// A config without remote data sources is kept as is.
+	plain := []byte(`{"admin": {"access_log_path": "/dev/null"}}`)
+	if out, rewritten, err := cache.RewriteConfig(plain); err != nil || rewritten || string(out) != string(plain) {
+		t.Errorf("expected the config to be kept, got %s %v %v", out, rewritten, err)
+	}
+
+	// An extension that can't be fetched is left out: the filter of the list, or else the top level field.
+	failing := []byte(fmt.Sprintf(`{
+  "admin": {"access_log_path": "/dev/null"},
+  "static_resources": {"listeners": [{"name": "inbound", "filter_chains": [{"filters": [{
+    "name": "envoy.http_connection_manager",
+    "config": {"http_filters": [
+      {"name": "missing", "config": {"code": {"remote": {"http_uri": {"uri": %q}, "sha256": %q}}}},
+      {"name": "envoy.router"}]}}]}]}]},
+  "stats_sinks": {"code": {"remote": {"http_uri": {"uri": %q}}}}
+}`, server.URL+"/missing.wasm", digestOf([]byte("missing")), server.URL+"/filter.wasm"))
+	out, rewritten, err = cache.RewriteConfig(failing)
+	if err != nil || !rewritten {
+		t.Fatalf("failed to rewrite the config: %v %v", rewritten, err)
+	}
+	for _, want := range []string{`"admin"`, `"inbound"`, `"envoy.router"`} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("expected %s to be kept, got %s", want, out)
+		}
+	}
+	for _, unwanted := range []string{`"missing"`, `"stats_sinks"`} {
+		if strings.Contains(string(out), unwanted) {
+			t.Errorf("expected %s to be left out, got %s", unwanted, out)
+		}
+	}
+}
+
+func TestPin(t *testing.T) {
+	digest := digestOf([]byte("wasm"))
+	testCases := []struct {
+		name            string
+		config          string
+		expected        []string
+		expectedModules []Module
+		expectedErr     string
+	}{
+		{
+			name:   "https reference",
+			config: fmt.Sprintf(`{"code": {"remote": {"http_uri": {"uri": "https://example.com/filter.wasm"}, "sha256": %q}}}`, digest),
+			expected: []string{
+				`"cluster":"extension|example.com|443"`,
+				`"timeout":"60s"`,
+				`"sha256":"` + digest + `"`,
+			},
+			expectedModules: []Module{{Host: "example.com", Port: 443, TLS: true}},
+		},
+		{
+			name:   "http reference with a port and a timeout",
+			config: fmt.Sprintf(`{"code": {"remote": {"httpUri": {"uri": "http://example.com:8080/filter.wasm", "timeout": "5s"}, "sha256": %q}}}`, digest),
+			expected: []string{
+				`"cluster":"extension|example.com|8080"`,
+				`"timeout":"5s"`,
+			},
+			expectedModules: []Module{{Host: "example.com", Port: 8080}},
+		},
+		{
+			name:     "reference with a cluster",
+			config:   fmt.Sprintf(`{"code": {"remote": {"http_uri": {"uri": "http://example.com/filter.wasm", "cluster": "modules"}, "sha256": %q}}}`, digest),
+			expected: []string{`"cluster":"modules"`},
+		},
+		{
+			name:     "no remote data source",
+			config:   `{"inline_code": "function envoy_on_request(handle) end"}`,
+			expected: []string{`"inline_code"`},
+		},
+		{
+			name:        "no digest",
+			config:      `{"code": {"remote": {"httpUri": {"uri": "https://example.com/filter.wasm"}}}}`,
+			expectedErr: "has no SHA-256 digest",
+		},
+		{
+			name:        "invalid digest",
+			config:      `{"code": {"remote": {"http_uri": {"uri": "https://example.com/filter.wasm"}, "sha256": "abc"}}}`,
+			expectedErr: "has an invalid SHA-256 digest",
+		},
+		{
+			name:        "file URL",
+			config:      fmt.Sprintf(`{"code": {"remote": {"http_uri": {"uri": "file:///etc/passwd"}, "sha256": %q}}}`, digest),
+			expectedErr: "only HTTP(S) URLs are supported",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, modules, err := Pin([]byte(tc.config))
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("want error %q but got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, want := range tc.expected {
+				if !strings.Contains(string(out), want) {
+					t.Errorf("expected %s, got %s", want, out)
+				}
+			}
+			if !reflect.DeepEqual(modules, tc.expectedModules) {
+				t.Errorf("expected modules %v, got %v", tc.expectedModules, modules)
+			}
+		})
+	}
+}
@@ -31,16 +31,21 @@ import (
 	"istio.io/pkg/log"
 
 	"istio.io/istio/pkg/bootstrap"
+	"istio.io/istio/pkg/envoy/extensions"
 )
 
 const (
 	// epochFileTemplate is a template for the root config JSON
 	epochFileTemplate = "envoy-rev%d.json"
+
+	// overrideFileTemplate is a template for the bootstrap override, when it references remote extensions
+	overrideFileTemplate = "envoy-override-rev%d.json"
 )
 
 type envoy struct {
 	ProxyConfig
-	extraArgs []string
+	extraArgs  []string
+	extensions *extensions.Cache
 }
 
 type ProxyConfig struct {
@@ -76,6 +81,8 @@ func NewProxy(cfg ProxyConfig) Proxy {
 	return &envoy{
 		ProxyConfig: cfg,
 		extraArgs:   args,
+		extensions: extensions.NewCache(path.Join(cfg.Config.ConfigPath, "extensions"),
+			int64(extensionCacheSizeVar.Get())<<20),
 	}
 }
 
@@ -139,7 +146,12 @@ func (e *envoy) args(fname string, epoch int, bootstrapConfig string) []string {
 	return startupArgs
 }
 
-var istioBootstrapOverrideVar = env.RegisterStringVar("ISTIO_BOOTSTRAP_OVERRIDE", "", "")
+var (
+	istioBootstrapOverrideVar = env.RegisterStringVar("ISTIO_BOOTSTRAP_OVERRIDE", "", "")
+
+	extensionCacheSizeVar = env.RegisterIntVar("ISTIO_EXTENSION_CACHE_SIZE_MB", 100,
+		"Maximum size in MB of the cache of the extension modules downloaded for Envoy.")
+)
 
 func (e *envoy) Run(config interface{}, epoch int, abort <-chan error) error {
 	var fname string
@@ -174,8 +186,26 @@ func (e *envoy) Run(config interface{}, epoch int, abort <-chan error) error {
 		fname = out
 	}
 
+	// Extension modules of the bootstrap config referenced by URL are downloaded, and loaded by Envoy
+	// from local files; the ones that can't be downloaded are left out. The modules of the EnvoyFilters
+	// are pinned to their digest by Pilot, and fetched by Envoy from the clusters Pilot pushes.
+	if out, err := e.extensions.RewriteFile(fname, configFile(e.Config.ConfigPath, epoch)); err != nil {
+		log.Warnf("Starting Envoy without downloading the extensions: %v", err)
+	} else {
+		fname = out
+	}
+	bootstrapOverride := istioBootstrapOverrideVar.Get()
+	if bootstrapOverride != "" {
+		out, err := e.extensions.RewriteFile(bootstrapOverride, path.Join(e.Config.ConfigPath, fmt.Sprintf(overrideFileTemplate, epoch)))
+		if err != nil {
+			log.Warnf("Starting Envoy without downloading the extensions: %v", err)
+		} else {
+			bootstrapOverride = out
+		}
+	}
+
 	// spin up a new Envoy process
-	args := e.args(fname, epoch, istioBootstrapOverrideVar.Get())
+	args := e.args(fname, epoch, bootstrapOverride)
 	log.Infof("Envoy command: %v", args)
 
 	/* #nosec */
@@ -207,6 +237,10 @@ func (e *envoy) Cleanup(epoch int) {
 	if err := os.Remove(filePath); err != nil {
 		log.Warnf("Failed to delete config file %s for %d, %v", filePath, epoch, err)
 	}
+	overridePath := path.Join(e.Config.ConfigPath, fmt.Sprintf(overrideFileTemplate, epoch))
+	if err := os.Remove(overridePath); err != nil && !os.IsNotExist(err) {
+		log.Warnf("Failed to delete bootstrap override %s for %d, %v", overridePath, epoch, err)
+	}
 }
 
 // convertDuration converts to golang duration and logs errors
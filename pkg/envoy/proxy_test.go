@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/envoy/extensions"
 )
 
 func TestEnvoyArgs(t *testing.T) {
@@ -44,6 +45,7 @@ func TestEnvoyArgs(t *testing.T) {
 	test := &envoy{
 		ProxyConfig: cfg,
 		extraArgs:   []string{"-l", "trace", "--component-log-level", "misc:error"},
+		extensions:  extensions.NewCache("/etc/istio/proxy/extensions", 100<<20),
 	}
 
 	testProxy := NewProxy(cfg)
@@ -0,0 +1,192 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package usage aggregates the Istio stats of Envoy into per-identity usage counters, for the
+// accounting of the traffic between service accounts without Mixer.
+//
+// The stats are emitted by the stats filters which Pilot adds to the inbound listeners of the
+// sidecars with the usage plugin. Only the stats reported by the destination proxy are accounted:
+// it authenticates the principal of the source with mTLS, and each connection and request is
+// accounted once in the mesh.
+package usage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	// StatPrefix is the prefix of the stats of the usage filters. It is distinct from the prefix of
+	// the stats of the telemetry filters, so that the traffic isn't accounted twice in the istio_*
+	// metrics when both are installed, and the usage is only parsed from the stats of the usage filters.
+	StatPrefix = "istio_usage"
+
+	// RequestsMetric is the number of HTTP requests.
+	RequestsMetric = StatPrefix + "_requests_total"
+	// ConnectionsMetric is the number of opened connections, of all protocols.
+	ConnectionsMetric = StatPrefix + "_tcp_connections_opened_total"
+	// ReceivedBytesMetric is the number of bytes received from the source, of all protocols.
+	ReceivedBytesMetric = StatPrefix + "_tcp_received_bytes_total"
+	// SentBytesMetric is the number of bytes sent to the source, of all protocols.
+	SentBytesMetric = StatPrefix + "_tcp_sent_bytes_total"
+
+	reporterLabel             = "reporter"
+	destinationReporter       = "destination"
+	sourcePrincipalLabel      = "source_principal"
+	destinationPrincipalLabel = "destination_principal"
+)
+
+// Identities are the principals of the source and the destination of the traffic.
+type Identities struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+}
+
+// Counters are the usage counters of a pair of identities.
+type Counters struct {
+	Requests      uint64 `json:"requests"`
+	Connections   uint64 `json:"connections"`
+	ReceivedBytes uint64 `json:"receivedBytes"`
+	SentBytes     uint64 `json:"sentBytes"`
+}
+
+// Add the counters of o.
+func (c *Counters) Add(o Counters) {
+	c.Requests += o.Requests
+	c.Connections += o.Connections
+	c.ReceivedBytes += o.ReceivedBytes
+	c.SentBytes += o.SentBytes
+}
+
+// Bytes returns the number of bytes in both directions.
+func (c Counters) Bytes() uint64 {
+	return c.ReceivedBytes + c.SentBytes
+}
+
+// Report is the usage of the pairs of identities.
+type Report map[Identities]*Counters
+
+// Merge adds the usage of o to the report.
+func (r Report) Merge(o Report) {
+	for ids, c := range o {
+		r.counters(ids).Add(*c)
+	}
+}
+
+func (r Report) counters(ids Identities) *Counters {
+	c, ok := r[ids]
+	if !ok {
+		c = &Counters{}
+		r[ids] = c
+	}
+	return c
+}
+
+// Entry is the usage of a pair of identities.
+type Entry struct {
+	Identities
+	Counters
+}
+
+// Entries returns the usage of the pairs of identities, by decreasing bytes and then by identities.
+func (r Report) Entries() []Entry {
+	out := make([]Entry, 0, len(r))
+	for ids, c := range r {
+		out = append(out, Entry{Identities: ids, Counters: *c})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if bi, bj := out[i].Bytes(), out[j].Bytes(); bi != bj {
+			return bi > bj
+		}
+		if out[i].Source != out[j].Source {
+			return out[i].Source < out[j].Source
+		}
+		return out[i].Destination < out[j].Destination
+	})
+	return out
+}
+
+// Parse the usage of the stats of Envoy, in the Prometheus text format.
+func Parse(r io.Reader) (Report, error) {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing Envoy stats: %v", err)
+	}
+
+	report := Report{}
+	for name, field := range map[string]func(*Counters) *uint64{
+		RequestsMetric:      func(c *Counters) *uint64 { return &c.Requests },
+		ConnectionsMetric:   func(c *Counters) *uint64 { return &c.Connections },
+		ReceivedBytesMetric: func(c *Counters) *uint64 { return &c.ReceivedBytes },
+		SentBytesMetric:     func(c *Counters) *uint64 { return &c.SentBytes },
+	} {
+		family, ok := families[name]
+		if !ok {
+			continue
+		}
+		for _, m := range family.Metric {
+			labels := labelValues(m)
+			if labels[reporterLabel] != destinationReporter || m.Counter == nil {
+				continue
+			}
+			ids := Identities{Source: labels[sourcePrincipalLabel], Destination: labels[destinationPrincipalLabel]}
+			*field(report.counters(ids)) += uint64(m.Counter.GetValue())
+		}
+	}
+	return report, nil
+}
+
+func labelValues(m *dto.Metric) map[string]string {
+	out := make(map[string]string, len(m.Label))
+	for _, l := range m.Label {
+		out[l.GetName()] = l.GetValue()
+	}
+	return out
+}
+
+// WritePrometheus writes the report in the Prometheus text format, as the compact usage counters
+// of the agent.
+func WritePrometheus(w io.Writer, r Report) error {
+	entries := r.Entries()
+	var b strings.Builder
+	for _, metric := range []struct {
+		name  string
+		help  string
+		value func(Counters) uint64
+	}{
+		{"istio_agent_usage_requests_total", "HTTP requests received, by identities.",
+			func(c Counters) uint64 { return c.Requests }},
+		{"istio_agent_usage_connections_total", "Connections received, by identities.",
+			func(c Counters) uint64 { return c.Connections }},
+		{"istio_agent_usage_received_bytes_total", "Bytes received from the source, by identities.",
+			func(c Counters) uint64 { return c.ReceivedBytes }},
+		{"istio_agent_usage_sent_bytes_total", "Bytes sent to the source, by identities.",
+			func(c Counters) uint64 { return c.SentBytes }},
+	} {
+		fmt.Fprintf(&b, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(&b, "# TYPE %s counter\n", metric.name)
+		for _, e := range entries {
+			fmt.Fprintf(&b, "%s{%s=%q,%s=%q} %d\n", metric.name, sourcePrincipalLabel, e.Source,
+				destinationPrincipalLabel, e.Destination, metric.value(e.Counters))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	productpage = "spiffe://cluster.local/ns/default/sa/bookinfo-productpage"
+	reviews     = "spiffe://cluster.local/ns/default/sa/bookinfo-reviews"
+	ratings     = "spiffe://cluster.local/ns/default/sa/bookinfo-ratings"
+)
+
+const envoyStats = `# TYPE envoy_cluster_upstream_cx_total counter
+envoy_cluster_upstream_cx_total{envoy_cluster_name="xds-grpc"} 1
+# TYPE istio_requests_total counter
+istio_requests_total{reporter="destination",source_principal="spiffe://cluster.local/ns/default/sa/bookinfo-productpage",destination_principal="spiffe://cluster.local/ns/default/sa/bookinfo-reviews",response_code="200"} 10
+# TYPE istio_usage_requests_total counter
+istio_usage_requests_total{reporter="destination",source_principal="spiffe://cluster.local/ns/default/sa/bookinfo-productpage",destination_principal="spiffe://cluster.local/ns/default/sa/bookinfo-reviews",response_code="200"} 10
+istio_usage_requests_total{reporter="destination",source_principal="spiffe://cluster.local/ns/default/sa/bookinfo-productpage",destination_principal="spiffe://cluster.local/ns/default/sa/bookinfo-reviews",response_code="503"} 2
+istio_usage_requests_total{reporter="source",source_principal="spiffe://cluster.local/ns/default/sa/bookinfo-reviews",destination_principal="spiffe://cluster.local/ns/default/sa/bookinfo-ratings",response_code="200"} 7
+# TYPE istio_usage_tcp_connections_opened_total counter
+istio_usage_tcp_connections_opened_total{reporter="destination",source_principal="spiffe://cluster.local/ns/default/sa/bookinfo-productpage",destination_principal="spiffe://cluster.local/ns/default/sa/bookinfo-reviews"} 3
+# TYPE istio_usage_tcp_received_bytes_total counter
+istio_usage_tcp_received_bytes_total{reporter="destination",source_principal="spiffe://cluster.local/ns/default/sa/bookinfo-productpage",destination_principal="spiffe://cluster.local/ns/default/sa/bookinfo-reviews"} 1200
+# TYPE istio_usage_tcp_sent_bytes_total counter
+istio_usage_tcp_sent_bytes_total{reporter="destination",source_principal="spiffe://cluster.local/ns/default/sa/bookinfo-productpage",destination_principal="spiffe://cluster.local/ns/default/sa/bookinfo-reviews"} 34000
+istio_usage_tcp_sent_bytes_total{reporter="destination",source_principal="unknown",destination_principal="spiffe://cluster.local/ns/default/sa/bookinfo-reviews"} 50
+`
+
+func TestParse(t *testing.T) {
+	report, err := Parse(strings.NewReader(envoyStats))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Report{
+		{Source: productpage, Destination: reviews}: {Requests: 12, Connections: 3, ReceivedBytes: 1200, SentBytes: 34000},
+		{Source: "unknown", Destination: reviews}:   {SentBytes: 50},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Fatalf("expected %v, got %v", expected, report)
+	}
+
+	if _, err := Parse(strings.NewReader("istio_usage_requests_total{ 1\n")); err == nil {
+		t.Fatal("expected error for malformed stats")
+	}
+}
+
+func TestMergeAndEntries(t *testing.T) {
+	report := Report{
+		{Source: productpage, Destination: reviews}: {Requests: 1, SentBytes: 10},
+	}
+	report.Merge(Report{
+		{Source: productpage, Destination: reviews}: {Requests: 2, SentBytes: 5},
+		{Source: reviews, Destination: ratings}:     {Connections: 1, ReceivedBytes: 100},
+	})
+	expected := []Entry{
+		{Identities{reviews, ratings}, Counters{Connections: 1, ReceivedBytes: 100}},
+		{Identities{productpage, reviews}, Counters{Requests: 3, SentBytes: 15}},
+	}
+	if got := report.Entries(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	var b strings.Builder
+	report := Report{{Source: productpage, Destination: reviews}: {Requests: 12, Connections: 3, ReceivedBytes: 1200, SentBytes: 34000}}
+	if err := WritePrometheus(&b, report); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"# TYPE istio_agent_usage_requests_total counter\n",
+		`istio_agent_usage_requests_total{source_principal="` + productpage + `",destination_principal="` + reviews + `"} 12`,
+		`istio_agent_usage_connections_total{source_principal="` + productpage + `",destination_principal="` + reviews + `"} 3`,
+		`istio_agent_usage_received_bytes_total{source_principal="` + productpage + `",destination_principal="` + reviews + `"} 1200`,
+		`istio_agent_usage_sent_bytes_total{source_principal="` + productpage + `",destination_principal="` + reviews + `"} 34000`,
+	} {
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, b.String())
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/spiffe"
 	caClientInterface "istio.io/istio/security/pkg/nodeagent/caclient/interface"
 	citadel "istio.io/istio/security/pkg/nodeagent/caclient/providers/citadel"
 	gca "istio.io/istio/security/pkg/nodeagent/caclient/providers/google"
@@ -32,6 +33,7 @@ import (
 	"istio.io/istio/security/pkg/nodeagent/sds"
 	"istio.io/istio/security/pkg/nodeagent/secretfetcher"
 	"istio.io/pkg/env"
+	"istio.io/pkg/filewatcher"
 	"istio.io/pkg/log"
 )
 
@@ -62,12 +64,18 @@ var (
 	enableIngressGatewaySDSEnv = env.RegisterBoolVar(enableIngressGatewaySDS, false, "").Get()
 
 	trustDomainEnv                     = env.RegisterStringVar(trustDomain, "", "").Get()
+	clusterIDEnv                       = env.RegisterStringVar(clusterID, "", "").Get()
 	secretTTLEnv                       = env.RegisterDurationVar(secretTTL, 24*time.Hour, "").Get()
 	secretRefreshGraceDurationEnv      = env.RegisterDurationVar(SecretRefreshGraceDuration, 1*time.Hour, "").Get()
 	secretRotationIntervalEnv          = env.RegisterDurationVar(SecretRotationInterval, 10*time.Minute, "").Get()
 	staledConnectionRecycleIntervalEnv = env.RegisterDurationVar(staledConnectionRecycleInterval, 5*time.Minute, "").Get()
 	initialBackoffEnv                  = env.RegisterIntVar(InitialBackoff, 10, "").Get()
 
+	fileMountedCertChainEnv = env.RegisterStringVar(fileMountedCertChain, "/etc/certs/cert-chain.pem", "").Get()
+	fileMountedKeyEnv       = env.RegisterStringVar(fileMountedKey, "/etc/certs/key.pem", "").Get()
+	fileMountedRootCertEnv  = env.RegisterStringVar(fileMountedRootCert, "/etc/certs/root-cert.pem", "").Get()
+	serviceAccountEnv       = env.RegisterStringVar(serviceAccount, "", "").Get()
+
 	// Location of a custom-mounted root (for example using Secret)
 	mountedRoot = "/etc/certs/root-cert.pem"
 
@@ -89,6 +97,10 @@ const (
 	// Refer to https://github.com/spiffe/spiffe/blob/master/standards/SPIFFE-ID.md#21-trust-domain
 	trustDomain = "TRUST_DOMAIN"
 
+	// The ID of the cluster of the workload, sent to the CA so that an external control plane managing
+	// several clusters reviews the JWT in the right one. It is set by the injector.
+	clusterID = "ISTIO_META_CLUSTER_ID"
+
 	// The ingress gateway SDS mode allows node agent to provision credentials to ingress gateway
 	// proxy by watching kubernetes secrets.
 	enableIngressGatewaySDS = "ENABLE_INGRESS_GATEWAY_SDS"
@@ -114,6 +126,19 @@ const (
 	// The environmental variable name for the initial backoff in milliseconds.
 	// example value format like "10"
 	InitialBackoff = "INITIAL_BACKOFF_MSEC"
+
+	// The CA provider serving the workload certificates from mounted files, for example
+	// managed by cert-manager, instead of requesting them from a CA.
+	fileMountedCAProvider = "FileMounted"
+
+	// The files of the workload certificate chain, private key and root certificates used with
+	// the FileMounted CA provider.
+	fileMountedCertChain = "FILE_MOUNTED_CERT_CHAIN"
+	fileMountedKey       = "FILE_MOUNTED_KEY"
+	fileMountedRootCert  = "FILE_MOUNTED_ROOT_CERT"
+
+	// The service account of the workload, used to check the SPIFFE ID of the mounted certificates.
+	serviceAccount = "SERVICE_ACCOUNT"
 )
 
 var (
@@ -217,7 +242,8 @@ func NewSDSAgent(discAddr string, tlsRequired bool) *SDSAgent {
 //
 // 3. Monitor mode - watching secret in same namespace ( Ingress)
 //
-// 4. TODO: File watching, for backward compat/migration from mounted secrets.
+// 4. File watching: serving certificates mounted as files, for example managed by cert-manager, or
+//    for backward compat/migration from mounted secrets.
 func (conf *SDSAgent) Start(isSidecar bool, podNamespace string) (*sds.Server, error) {
 	applyEnvVars()
 
@@ -227,9 +253,6 @@ func (conf *SDSAgent) Start(isSidecar bool, podNamespace string) (*sds.Server, e
 	serverOptions.WorkloadUDSPath = LocalSDS
 	serverOptions.UseLocalJWT = true
 
-	// TODO: remove the caching, workload has a single cert
-	workloadSecretCache, _ := newSecretCache(serverOptions)
-
 	var gatewaySecretCache *cache.SecretCache
 	if !isSidecar {
 		serverOptions.EnableIngressGatewaySDS = true
@@ -238,6 +261,18 @@ func (conf *SDSAgent) Start(isSidecar bool, podNamespace string) (*sds.Server, e
 		gatewaySecretCache = newIngressSecretCache(podNamespace)
 	}
 
+	// The mounted certificates are served as is, without a CA client.
+	if serverOptions.CAProviderName == fileMountedCAProvider {
+		workloadSecretManager, err := newFileSecretManager(podNamespace)
+		if err != nil {
+			return nil, err
+		}
+		return sds.NewServer(serverOptions, workloadSecretManager, gatewaySecretCache)
+	}
+
+	// TODO: remove the caching, workload has a single cert
+	workloadSecretCache, _ := newSecretCache(serverOptions)
+
 	// For sidecar and ingress we need to first get the certificates for the workload.
 	// We'll also save them in files, for backward compat with servers generating files
 	// TODO: use caClient.CSRSign() directly
@@ -378,7 +413,7 @@ func newSecretCache(serverOptions sds.Options) (workloadSecretCache *cache.Secre
 		// Will use TLS unless the reserved 15010 port is used ( istiod on an ipsec/secure VPC)
 		// rootCert may be nil - in which case the system roots are used, and the CA is expected to have public key
 		// Otherwise assume the injection has mounted /etc/certs/root-cert.pem
-		caClient, err = citadel.NewCitadelClient(serverOptions.CAEndpoint, tls, rootCert)
+		caClient, err = citadel.NewCitadelClient(serverOptions.CAEndpoint, tls, rootCert, clusterIDEnv)
 	}
 
 	if err != nil {
@@ -394,6 +429,26 @@ func newSecretCache(serverOptions sds.Options) (workloadSecretCache *cache.Secre
 	return
 }
 
+// newFileSecretManager creates the workload secret manager serving the mounted certificates. They
+// must be issued for the SPIFFE ID of the workload, when its service account is known.
+func newFileSecretManager(podNamespace string) (*cache.FileSecretManager, error) {
+	options := cache.FileSecretOptions{
+		CertChainPath: fileMountedCertChainEnv,
+		KeyPath:       fileMountedKeyEnv,
+		RootCertPath:  fileMountedRootCertEnv,
+	}
+	if podNamespace != "" && serviceAccountEnv != "" {
+		if trustDomainEnv != "" {
+			spiffe.SetTrustDomain(trustDomainEnv)
+		}
+		options.ExpectedIdentity = spiffe.MustGenSpiffeURI(podNamespace, serviceAccountEnv)
+	} else {
+		log.Warna("Unknown workload identity, accepting any SPIFFE ID in the mounted certificates")
+	}
+	log.Infoa("Using certificates mounted as files ", options.CertChainPath)
+	return cache.NewFileSecretManager(options, sds.NotifyProxy, filewatcher.NewWatcher())
+}
+
 // TODO: use existing 'sidecar/router' config to enable loading Secrets
 func newIngressSecretCache(namespace string) (gatewaySecretCache *cache.SecretCache) {
 	gSecretFetcher := &secretfetcher.SecretFetcher{
@@ -0,0 +1,103 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package events emits the decisions of the control plane, such as a skipped injection or a
+// rejected config, as Kubernetes events on the affected objects, so that they show up in
+// `kubectl get events` and `kubectl describe`.
+package events
+
+import (
+	"fmt"
+	"os"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// Normal is the type of the events reporting an expected decision.
+	Normal = v1.EventTypeNormal
+	// Warning is the type of the events reporting a failure or a rejection.
+	Warning = v1.EventTypeWarning
+)
+
+const (
+	// burstSize and qps bound the rate of the events per object, so that a config rejected on
+	// every push, or a proxy NACKing every update, doesn't flood the API server.
+	burstSize = 10
+	qps       = 1.0 / 60
+
+	// maxEvents similar events on an object within maxIntervalInSeconds are aggregated into
+	// one event, whose count is incremented.
+	maxEvents            = 5
+	maxIntervalInSeconds = 600
+)
+
+// Recorder emits deduplicated and rate-limited events to the API server. A nil Recorder
+// discards the events, for the components running without Kubernetes.
+type Recorder struct {
+	recorder record.EventRecorder
+	watcher  watch.Interface
+}
+
+// NewRecorder returns a Recorder which creates the events through the given client, with the
+// component as their source.
+func NewRecorder(core corev1.CoreV1Interface, component string) *Recorder {
+	broadcaster := record.NewBroadcasterWithCorrelatorOptions(record.CorrelatorOptions{
+		BurstSize:            burstSize,
+		QPS:                  qps,
+		MaxEvents:            maxEvents,
+		MaxIntervalInSeconds: maxIntervalInSeconds,
+	})
+	watcher := broadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: core.Events("")})
+	host, _ := os.Hostname()
+	return &Recorder{
+		recorder: broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: component, Host: host}),
+		watcher:  watcher,
+	}
+}
+
+// Event emits an event of the given type on the object referenced.
+func (r *Recorder) Event(ref *v1.ObjectReference, eventType, reason, message string) {
+	if r == nil || ref == nil {
+		return
+	}
+	r.recorder.Event(ref, eventType, reason, message)
+}
+
+// Eventf is like Event, with Sprintf for the message.
+func (r *Recorder) Eventf(ref *v1.ObjectReference, eventType, reason, format string, args ...interface{}) {
+	r.Event(ref, eventType, reason, fmt.Sprintf(format, args...))
+}
+
+// Shutdown stops sending the events to the API server.
+func (r *Recorder) Shutdown() {
+	if r == nil || r.watcher == nil {
+		return
+	}
+	r.watcher.Stop()
+}
+
+// ObjectReference returns the reference to an object known by its kind and name.
+func ObjectReference(apiVersion, kind, namespace, name string) *v1.ObjectReference {
+	return &v1.ObjectReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Namespace:  namespace,
+		Name:       name,
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"istio.io/istio/pkg/test/util/retry"
+)
+
+// sink records the events created and the patches of the events sent to the fake client, as
+// the fake client doesn't store the events created through the root scoped sink.
+type sink struct {
+	mu      sync.Mutex
+	created []*v1.Event
+	patched int
+}
+
+func newClient(s *sink) *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("*", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		switch a := action.(type) {
+		case k8stesting.CreateAction:
+			event := a.GetObject().(*v1.Event)
+			s.created = append(s.created, event)
+			return true, event, nil
+		case k8stesting.PatchAction:
+			s.patched++
+			return true, s.created[0], nil
+		}
+		return false, nil, nil
+	})
+	return client
+}
+
+func (s *sink) expect(t *testing.T, created, patched int) {
+	t.Helper()
+	retry.UntilSuccessOrFail(t, func() error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if len(s.created) != created || s.patched != patched {
+			return fmt.Errorf("expected %d events created and %d patched, got %d and %d",
+				created, patched, len(s.created), s.patched)
+		}
+		return nil
+	}, retry.Timeout(5*time.Second))
+}
+
+func TestRecorderDeduplicates(t *testing.T) {
+	s := &sink{}
+	r := NewRecorder(newClient(s).CoreV1(), "test")
+	defer r.Shutdown()
+
+	pod := ObjectReference("v1", "Pod", "default", "productpage")
+	for i := 0; i < 3; i++ {
+		r.Eventf(pod, Warning, "ConfigNACKed", "rejected %s", "LDS")
+	}
+
+	s.expect(t, 1, 2)
+	event := s.created[0]
+	if event.InvolvedObject.Kind != "Pod" || event.InvolvedObject.Name != "productpage" || event.Namespace != "default" {
+		t.Errorf("unexpected involved object %v", event.InvolvedObject)
+	}
+	if event.Type != Warning || event.Reason != "ConfigNACKed" || event.Message != "rejected LDS" {
+		t.Errorf("unexpected event %v", event)
+	}
+	if event.Source.Component != "test" {
+		t.Errorf("unexpected source %v", event.Source)
+	}
+}
+
+func TestRecorderRateLimits(t *testing.T) {
+	s := &sink{}
+	r := NewRecorder(newClient(s).CoreV1(), "test")
+	defer r.Shutdown()
+
+	pod := ObjectReference("v1", "Pod", "default", "productpage")
+	for i := 0; i < 2*burstSize; i++ {
+		r.Event(pod, Warning, fmt.Sprintf("Reason%d", i), "message")
+	}
+	s.expect(t, burstSize, 0)
+}
+
+func TestNilRecorder(t *testing.T) {
+	var r *Recorder
+	r.Event(ObjectReference("v1", "Pod", "default", "productpage"), Normal, "Reason", "message")
+	r.Shutdown()
+}
+
+func TestFakeRecorder(t *testing.T) {
+	r, events := NewFakeRecorder(1)
+	defer r.Shutdown()
+
+	r.Eventf(ObjectReference("v1", "Pod", "default", "productpage"), Normal, "InjectionSkipped", "skipped %s", "productpage")
+	r.Event(ObjectReference("v1", "Pod", "default", "reviews"), Normal, "InjectionSkipped", "dropped")
+	event := <-events
+	if event.Ref.Name != "productpage" || event.Type != Normal || event.Reason != "InjectionSkipped" ||
+		event.Message != "skipped productpage" {
+		t.Errorf("unexpected event %v", event)
+	}
+	select {
+	case event := <-events:
+		t.Errorf("unexpected event %v beyond the buffer", event)
+	default:
+	}
+}
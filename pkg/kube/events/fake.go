@@ -0,0 +1,61 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Event is an event emitted by a fake Recorder.
+type Event struct {
+	Ref     *v1.ObjectReference
+	Type    string
+	Reason  string
+	Message string
+}
+
+// NewFakeRecorder returns a Recorder which sends the events to the channel returned, without
+// deduplicating them, for tests. The events are dropped when the buffer of the channel is full.
+func NewFakeRecorder(bufferSize int) (*Recorder, <-chan Event) {
+	events := make(chan Event, bufferSize)
+	return &Recorder{recorder: fakeRecorder(events)}, events
+}
+
+type fakeRecorder chan Event
+
+func (f fakeRecorder) Event(object runtime.Object, eventType, reason, message string) {
+	select {
+	case f <- Event{Ref: object.(*v1.ObjectReference), Type: eventType, Reason: reason, Message: message}:
+	default:
+	}
+}
+
+func (f fakeRecorder) Eventf(object runtime.Object, eventType, reason, format string, args ...interface{}) {
+	f.Event(object, eventType, reason, fmt.Sprintf(format, args...))
+}
+
+func (f fakeRecorder) PastEventf(object runtime.Object, _ metav1.Time, eventType, reason, format string,
+	args ...interface{}) {
+	f.Eventf(object, eventType, reason, format, args...)
+}
+
+func (f fakeRecorder) AnnotatedEventf(object runtime.Object, _ map[string]string, eventType, reason, format string,
+	args ...interface{}) {
+	f.Eventf(object, eventType, reason, format, args...)
+}
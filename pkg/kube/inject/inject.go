@@ -29,6 +29,7 @@ import (
 	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/ghodss/yaml"
 	"github.com/gogo/protobuf/jsonpb"
@@ -39,6 +40,7 @@ import (
 	"istio.io/api/annotation"
 	meshconfig "istio.io/api/mesh/v1alpha1"
 	"istio.io/istio/pilot/pkg/model"
+	configannotation "istio.io/istio/pkg/config/annotation"
 	"istio.io/pkg/log"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -85,6 +87,9 @@ var (
 		annotation.SidecarTrafficExcludeInboundPorts.Name:         ValidateExcludeInboundPorts,
 		annotation.SidecarTrafficExcludeOutboundPorts.Name:        ValidateExcludeOutboundPorts,
 		annotation.SidecarTrafficKubevirtInterfaces.Name:          alwaysValidFunc,
+		configannotation.SidecarProtocolSniffing.Name:             validateBool,
+		configannotation.SidecarProtocolDetectionTimeout.Name:     validateDuration,
+		configannotation.SidecarProtocolSniffingExcludePorts.Name: validateProtocolSniffingExcludePorts,
 	}
 )
 
@@ -373,6 +378,11 @@ func ValidateExcludeOutboundPorts(ports string) error {
 	return validatePortList("excludeOutboundPorts", ports)
 }
 
+// validateProtocolSniffingExcludePorts validates the protocolSniffingExcludePorts annotation
+func validateProtocolSniffingExcludePorts(ports string) error {
+	return validatePortList("protocolSniffingExcludePorts", ports)
+}
+
 // validateStatusPort validates the statusPort parameter
 func validateStatusPort(port string) error {
 	if _, e := parsePort(port); e != nil {
@@ -387,6 +397,24 @@ func validateUInt32(value string) error {
 	return err
 }
 
+// validateBool validates that the given annotation value is a boolean.
+func validateBool(value string) error {
+	_, err := strconv.ParseBool(value)
+	return err
+}
+
+// validateDuration validates that the given annotation value is a positive duration.
+func validateDuration(value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	if d <= 0 {
+		return fmt.Errorf("duration must be positive: %v", value)
+	}
+	return nil
+}
+
 func injectRequired(ignored []string, config *Config, podSpec *corev1.PodSpec, metadata *metav1.ObjectMeta) bool { // nolint: lll
 	// Skip injection when host networking is enabled. The problem is
 	// that the iptable changes are assumed to be within the pod when,
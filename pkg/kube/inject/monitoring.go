@@ -37,6 +37,8 @@ type monitor struct {
 }
 
 var (
+	namespaceTag = monitoring.MustCreateLabel("namespace")
+
 	totalInjections = monitoring.NewSum(
 		"sidecar_injection_requests_total",
 		"Total number of Side car injection requests.",
@@ -56,6 +58,17 @@ var (
 		"sidecar_injection_skip_total",
 		"Total number of skipped injection requests.",
 	)
+
+	uninjectedPods = monitoring.NewGauge(
+		"sidecar_injection_uninjected_pods",
+		"Number of pods without sidecar in namespaces with injection enabled.",
+		monitoring.WithLabels(namespaceTag),
+	)
+
+	totalUninjectedRestarts = monitoring.NewSum(
+		"sidecar_injection_uninjected_restarts_total",
+		"Total number of workloads restarted to inject their uninjected pods.",
+	)
 )
 
 func init() {
@@ -64,6 +77,8 @@ func init() {
 		totalSuccessfulInjections,
 		totalFailedInjections,
 		totalSkippedInjections,
+		uninjectedPods,
+		totalUninjectedRestarts,
 	)
 }
 
@@ -0,0 +1,203 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/api/admissionregistration/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	admissionregistrationv1beta1 "k8s.io/client-go/kubernetes/typed/admissionregistration/v1beta1"
+
+	"istio.io/pkg/log"
+)
+
+const (
+	// remoteInjectPath is the path of the injector for the pods of a remote cluster, followed by the cluster ID.
+	remoteInjectPath = "/inject/cluster/"
+
+	// proxyClusterIDEnv is the cluster of the proxy, also sent by the node agent to the CA.
+	proxyClusterIDEnv = "ISTIO_META_CLUSTER_ID"
+
+	remoteWebhookName = "sidecar-injector.istio.io"
+
+	// remoteWebhookOwnerLabel marks the webhook configurations created in the remote clusters, so that
+	// the configurations installed by other means are neither replaced nor removed.
+	remoteWebhookOwnerLabel = "istio.io/owned-by"
+	remoteWebhookOwner      = "pilot"
+)
+
+// RemoteWebhook registers the injection webhook in the remote clusters of an external control plane,
+// so that their API servers call back the injector of the control plane. It implements the cluster
+// handler of the multicluster registry.
+type RemoteWebhook struct {
+	// ConfigName is the name of the MutatingWebhookConfiguration created in the remote clusters.
+	ConfigName string
+	// URL is the base URL of the injector, reachable from the API servers of the remote clusters,
+	// e.g. https://istiod.example.com:15017.
+	URL string
+	// CABundle verifies the certificate of the injector.
+	CABundle []byte
+	// LocalConfigs and LocalConfigName give the webhook configuration of the injector in the cluster
+	// of the control plane, whose rules and selectors are copied in the remote clusters.
+	LocalConfigs    admissionregistrationv1beta1.MutatingWebhookConfigurationInterface
+	LocalConfigName string
+
+	mu      sync.Mutex
+	clients map[string]kubernetes.Interface
+}
+
+// AddCluster creates or updates the webhook configuration of the remote cluster. A configuration of
+// the same name which was not created by the control plane is left as is.
+func (w *RemoteWebhook) AddCluster(client kubernetes.Interface, clusterID string) error {
+	config, err := w.webhookConfig(clusterID)
+	if err != nil {
+		return err
+	}
+	configs := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+	existing, err := configs.Get(w.ConfigName, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		_, err = configs.Create(config)
+	case err == nil:
+		if !isRemoteWebhookOwner(existing) {
+			return fmt.Errorf("failed to register the injection webhook in cluster %s: %s already exists and "+
+				"is not owned by the control plane", clusterID, w.ConfigName)
+		}
+		config.ResourceVersion = existing.ResourceVersion
+		_, err = configs.Update(config)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to register the injection webhook in cluster %s: %v", clusterID, err)
+	}
+	log.Infof("Registered the injection webhook %s in cluster %s", w.ConfigName, clusterID)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.clients == nil {
+		w.clients = make(map[string]kubernetes.Interface)
+	}
+	w.clients[clusterID] = client
+	return nil
+}
+
+// DeleteCluster removes the webhook configuration from the remote cluster, so that its pods are no
+// longer injected for a control plane which does not serve them anymore.
+func (w *RemoteWebhook) DeleteCluster(clusterID string) error {
+	w.mu.Lock()
+	client := w.clients[clusterID]
+	delete(w.clients, clusterID)
+	w.mu.Unlock()
+	if client == nil {
+		return nil
+	}
+	configs := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+	existing, err := configs.Get(w.ConfigName, metav1.GetOptions{})
+	if err == nil {
+		if !isRemoteWebhookOwner(existing) {
+			return nil
+		}
+		err = configs.Delete(w.ConfigName, &metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{UID: &existing.UID},
+		})
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to unregister the injection webhook in cluster %s: %v", clusterID, err)
+	}
+	return nil
+}
+
+// webhookConfig returns the webhook configuration of a remote cluster: the one of the injector of the
+// control plane, calling the injector by URL.
+func (w *RemoteWebhook) webhookConfig(clusterID string) (*v1beta1.MutatingWebhookConfiguration, error) {
+	var webhooks []v1beta1.MutatingWebhook
+	if w.LocalConfigs != nil {
+		local, err := w.LocalConfigs.Get(w.LocalConfigName, metav1.GetOptions{})
+		switch {
+		case err == nil:
+			webhooks = local.DeepCopy().Webhooks
+		case errors.IsNotFound(err):
+			log.Warnf("Webhook configuration %s not found, using the default injection rules in cluster %s",
+				w.LocalConfigName, clusterID)
+		default:
+			return nil, fmt.Errorf("failed to read the webhook configuration %s: %v", w.LocalConfigName, err)
+		}
+	}
+	if len(webhooks) == 0 {
+		webhooks = defaultRemoteWebhooks()
+	}
+
+	url := strings.TrimSuffix(w.URL, "/") + remoteInjectPath + clusterID
+	for i := range webhooks {
+		webhooks[i].ClientConfig = v1beta1.WebhookClientConfig{
+			URL:      &url,
+			CABundle: w.CABundle,
+		}
+	}
+	return &v1beta1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   w.ConfigName,
+			Labels: map[string]string{remoteWebhookOwnerLabel: remoteWebhookOwner},
+		},
+		Webhooks: webhooks,
+	}, nil
+}
+
+// defaultRemoteWebhooks returns the webhook installed with the injector by default, for the control
+// planes without a local injector configuration.
+func defaultRemoteWebhooks() []v1beta1.MutatingWebhook {
+	failurePolicy := v1beta1.Fail
+	return []v1beta1.MutatingWebhook{{
+		Name: remoteWebhookName,
+		Rules: []v1beta1.RuleWithOperations{{
+			Operations: []v1beta1.OperationType{v1beta1.Create},
+			Rule: v1beta1.Rule{
+				APIGroups:   []string{""},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"pods"},
+			},
+		}},
+		FailurePolicy: &failurePolicy,
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"istio-injection": "enabled"},
+		},
+	}}
+}
+
+func isRemoteWebhookOwner(config *v1beta1.MutatingWebhookConfiguration) bool {
+	return config.Labels[remoteWebhookOwnerLabel] == remoteWebhookOwner
+}
+
+// setProxyClusterID sets the cluster of the injected proxy, overriding the cluster of the template
+// which is the one of the control plane.
+func setProxyClusterID(spec *SidecarInjectionSpec, clusterID string) {
+	sidecar := FindSidecar(spec.Containers)
+	if sidecar == nil {
+		return
+	}
+	for i := range sidecar.Env {
+		if sidecar.Env[i].Name == proxyClusterIDEnv {
+			sidecar.Env[i].Value = clusterID
+			sidecar.Env[i].ValueFrom = nil
+			return
+		}
+	}
+	sidecar.Env = append(sidecar.Env, corev1.EnvVar{Name: proxyClusterIDEnv, Value: clusterID})
+}
@@ -0,0 +1,205 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/api/admission/v1beta1"
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRemoteWebhook(t *testing.T) {
+	sideEffects := admissionregistration.SideEffectClassNone
+	local := fake.NewSimpleClientset(&admissionregistration.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "istio-sidecar-injector"},
+		Webhooks: []admissionregistration.MutatingWebhook{{
+			Name: "sidecar-injector.istio.io",
+			ClientConfig: admissionregistration.WebhookClientConfig{
+				Service: &admissionregistration.ServiceReference{Namespace: "istio-system", Name: "istiod"},
+			},
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"istio-env": "istio-system"}},
+			SideEffects:       &sideEffects,
+		}},
+	})
+	east := fake.NewSimpleClientset()
+	// The webhook previously registered by the control plane is updated.
+	west := fake.NewSimpleClientset(&admissionregistration.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "istio-sidecar-injector",
+			Labels: map[string]string{"istio.io/owned-by": "pilot"},
+		},
+	})
+	w := &RemoteWebhook{
+		ConfigName:      "istio-sidecar-injector",
+		URL:             "https://istiod.example.com:15017/",
+		CABundle:        []byte("ca"),
+		LocalConfigs:    local.AdmissionregistrationV1beta1().MutatingWebhookConfigurations(),
+		LocalConfigName: "istio-sidecar-injector",
+	}
+
+	for clusterID, client := range map[string]*fake.Clientset{"east": east, "west": west} {
+		if err := w.AddCluster(client, clusterID); err != nil {
+			t.Fatalf("AddCluster(%s) failed: %v", clusterID, err)
+		}
+		config, err := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get("istio-sidecar-injector", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("webhook not registered in %s: %v", clusterID, err)
+		}
+		if len(config.Webhooks) != 1 {
+			t.Fatalf("expected a single webhook in %s, got %v", clusterID, config.Webhooks)
+		}
+		clientConfig := config.Webhooks[0].ClientConfig
+		if want := "https://istiod.example.com:15017/inject/cluster/" + clusterID; clientConfig.URL == nil || *clientConfig.URL != want {
+			t.Errorf("expected URL %s in %s, got %v", want, clusterID, clientConfig.URL)
+		}
+		if clientConfig.Service != nil {
+			t.Errorf("unexpected service reference in %s: %v", clusterID, clientConfig.Service)
+		}
+		if string(clientConfig.CABundle) != "ca" {
+			t.Errorf("unexpected CA bundle in %s: %q", clusterID, clientConfig.CABundle)
+		}
+		// The rules of the local injector are kept.
+		if selector := config.Webhooks[0].NamespaceSelector; selector == nil || selector.MatchLabels["istio-env"] != "istio-system" {
+			t.Errorf("unexpected namespace selector in %s: %v", clusterID, selector)
+		}
+		if config.Webhooks[0].SideEffects == nil || *config.Webhooks[0].SideEffects != sideEffects {
+			t.Errorf("unexpected side effects in %s: %v", clusterID, config.Webhooks[0].SideEffects)
+		}
+	}
+
+	if err := w.DeleteCluster("east"); err != nil {
+		t.Fatalf("DeleteCluster failed: %v", err)
+	}
+	if _, err := east.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get("istio-sidecar-injector",
+		metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the webhook to be unregistered, got %v", err)
+	}
+	if _, err := west.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get("istio-sidecar-injector",
+		metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the webhook of another cluster to be kept, got %v", err)
+	}
+	// Unknown clusters are ignored.
+	if err := w.DeleteCluster("north"); err != nil {
+		t.Errorf("DeleteCluster of an unknown cluster failed: %v", err)
+	}
+}
+
+func TestRemoteWebhookNotOwned(t *testing.T) {
+	// The injector installed in the cluster is neither replaced nor removed.
+	installed := &admissionregistration.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "istio-sidecar-injector"},
+		Webhooks:   []admissionregistration.MutatingWebhook{{Name: "sidecar-injector.istio.io"}},
+	}
+	client := fake.NewSimpleClientset(installed)
+	w := &RemoteWebhook{
+		ConfigName: "istio-sidecar-injector",
+		URL:        "https://istiod.example.com:15017",
+	}
+
+	if err := w.AddCluster(client, "east"); err == nil {
+		t.Errorf("expected AddCluster to fail on a webhook not owned by the control plane")
+	}
+	// A webhook installed in place of the one of the control plane is kept too.
+	west := fake.NewSimpleClientset()
+	if err := w.AddCluster(west, "west"); err != nil {
+		t.Fatalf("AddCluster failed: %v", err)
+	}
+	configs := west.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+	if err := configs.Delete("istio-sidecar-injector", &metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete the webhook: %v", err)
+	}
+	if _, err := configs.Create(installed); err != nil {
+		t.Fatalf("failed to install the webhook: %v", err)
+	}
+	if err := w.DeleteCluster("west"); err != nil {
+		t.Fatalf("DeleteCluster failed: %v", err)
+	}
+
+	for clusterID, client := range map[string]*fake.Clientset{"east": client, "west": west} {
+		config, err := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get("istio-sidecar-injector", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected the installed webhook to be kept in %s, got %v", clusterID, err)
+		}
+		if config.Webhooks[0].ClientConfig.URL != nil {
+			t.Errorf("expected the installed webhook to be unchanged in %s, got %v", clusterID, config.Webhooks[0])
+		}
+	}
+}
+
+func TestRemoteWebhookDefault(t *testing.T) {
+	// Without a local configuration, the remote clusters get the default rules of the injector.
+	w := &RemoteWebhook{
+		ConfigName:      "istio-sidecar-injector",
+		URL:             "https://istiod.example.com:15017",
+		LocalConfigs:    fake.NewSimpleClientset().AdmissionregistrationV1beta1().MutatingWebhookConfigurations(),
+		LocalConfigName: "istio-sidecar-injector",
+	}
+	config, err := w.webhookConfig("east")
+	if err != nil {
+		t.Fatalf("webhookConfig failed: %v", err)
+	}
+	if len(config.Webhooks) != 1 || config.Webhooks[0].Name != "sidecar-injector.istio.io" ||
+		config.Webhooks[0].NamespaceSelector.MatchLabels["istio-injection"] != "enabled" {
+		t.Errorf("unexpected default webhooks %v", config.Webhooks)
+	}
+}
+
+func TestRemoteInjectClusterID(t *testing.T) {
+	wh, cleanup := createWebhook(t, `
+containers:
+- name: istio-proxy
+  env:
+  - name: ISTIO_META_CLUSTER_ID
+    value: control-plane
+`)
+	defer cleanup()
+	wh.isRemoteCluster = func(clusterID string) bool { return clusterID == "east" }
+
+	for path, want := range map[string]string{
+		"/inject":              "control-plane",
+		"/inject/cluster/east": "east",
+	} {
+		req := httptest.NewRequest("POST", "http://sidecar-injector"+path, bytes.NewReader(makeTestData(t, false)))
+		req.Header.Add("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		wh.serveInject(w, req)
+
+		var review v1beta1.AdmissionReview
+		if err := json.Unmarshal(w.Body.Bytes(), &review); err != nil {
+			t.Fatalf("could not decode response body: %v", err)
+		}
+		if !strings.Contains(string(review.Response.Patch), `{"name":"ISTIO_META_CLUSTER_ID","value":"`+want+`"}`) {
+			t.Errorf("%s: expected the proxy of cluster %s, got patch %s", path, want, review.Response.Patch)
+		}
+	}
+
+	// The pods of unknown clusters are not injected.
+	req := httptest.NewRequest("POST", "http://sidecar-injector/inject/cluster/west", bytes.NewReader(makeTestData(t, false)))
+	req.Header.Add("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	wh.serveInject(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for an unknown cluster, got %d", http.StatusNotFound, w.Code)
+	}
+}
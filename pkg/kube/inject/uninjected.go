@@ -0,0 +1,266 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"istio.io/api/annotation"
+)
+
+const (
+	// InjectionLabel is the namespace label that enables the injection with the default
+	// configuration of the webhook.
+	InjectionLabel = "istio-injection"
+
+	// restartedAtAnnotation is the pod template annotation set to restart the pods of a
+	// workload, as done by `kubectl rollout restart`.
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+)
+
+// UninjectedPod is a pod that the injection webhook applies to, but that has no sidecar, such as a
+// pod created while the webhook was unavailable.
+type UninjectedPod struct {
+	Namespace string
+	Name      string
+
+	// OwnerKind and OwnerName identify the workload running the pod, such as a Deployment, or
+	// are empty if the pod has no controller.
+	OwnerKind string
+	OwnerName string
+}
+
+// Owner returns the owner of the pod as kind/name, or "" if the pod has no controller.
+func (p UninjectedPod) Owner() string {
+	if p.OwnerKind == "" {
+		return ""
+	}
+	return p.OwnerKind + "/" + p.OwnerName
+}
+
+// InjectionSelector selects the namespaces and the pods that the injection webhook applies to.
+type InjectionSelector struct {
+	Namespace labels.Selector
+	Object    labels.Selector
+}
+
+// DefaultInjectionSelector returns the selector of the default webhook configuration, which applies
+// to the namespaces labeled with istio-injection=enabled.
+func DefaultInjectionSelector() *InjectionSelector {
+	return &InjectionSelector{
+		Namespace: labels.SelectorFromSet(labels.Set{InjectionLabel: "enabled"}),
+		Object:    labels.Everything(),
+	}
+}
+
+// WebhookInjectionSelector returns the selector of the webhook of the mutating webhook
+// configuration, or the default selector if the configuration doesn't exist.
+func WebhookInjectionSelector(client kubernetes.Interface, configName, webhookName string) (*InjectionSelector, error) {
+	config, err := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get(configName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return DefaultInjectionSelector(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, wh := range config.Webhooks {
+		if wh.Name != webhookName {
+			continue
+		}
+		selector := &InjectionSelector{Namespace: labels.Everything(), Object: labels.Everything()}
+		if wh.NamespaceSelector != nil {
+			if selector.Namespace, err = metav1.LabelSelectorAsSelector(wh.NamespaceSelector); err != nil {
+				return nil, fmt.Errorf("invalid namespace selector of webhook %s: %v", webhookName, err)
+			}
+		}
+		if wh.ObjectSelector != nil {
+			if selector.Object, err = metav1.LabelSelectorAsSelector(wh.ObjectSelector); err != nil {
+				return nil, fmt.Errorf("invalid object selector of webhook %s: %v", webhookName, err)
+			}
+		}
+		return selector, nil
+	}
+	return nil, fmt.Errorf("webhook %s not found in %s", webhookName, configName)
+}
+
+// IsUninjected returns true if the webhook applies to the pod, which doesn't have the sidecar
+// injection status annotation. Terminated and terminating pods are ignored.
+func IsUninjected(config *Config, selector *InjectionSelector, namespace *corev1.Namespace, pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	if !selector.Namespace.Matches(labels.Set(namespace.Labels)) || !selector.Object.Matches(labels.Set(pod.Labels)) {
+		return false
+	}
+	if _, ok := pod.Annotations[annotation.SidecarStatus.Name]; ok {
+		return false
+	}
+	return injectRequired(ignoredNamespaces, config, &pod.Spec, &pod.ObjectMeta)
+}
+
+// FindUninjectedPods returns the uninjected pods of the namespace, or of all namespaces if
+// namespace is metav1.NamespaceAll.
+func FindUninjectedPods(client kubernetes.Interface, config *Config, selector *InjectionSelector,
+	namespace string) ([]UninjectedPod, error) {
+	var namespaces []corev1.Namespace
+	if namespace == metav1.NamespaceAll {
+		list, err := client.CoreV1().Namespaces().List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		namespaces = list.Items
+	} else {
+		ns, err := client.CoreV1().Namespaces().Get(namespace, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		namespaces = []corev1.Namespace{*ns}
+	}
+
+	var uninjected []UninjectedPod
+	for i := range namespaces {
+		ns := &namespaces[i]
+		if !selector.Namespace.Matches(labels.Set(ns.Labels)) {
+			continue
+		}
+		pods, err := client.CoreV1().Pods(ns.Name).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for j := range pods.Items {
+			pod := &pods.Items[j]
+			if IsUninjected(config, selector, ns, pod) {
+				uninjected = append(uninjected, newUninjectedPod(pod))
+			}
+		}
+	}
+	sortUninjectedPods(uninjected)
+	return uninjected, nil
+}
+
+func sortUninjectedPods(pods []UninjectedPod) {
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+}
+
+func newUninjectedPod(pod *corev1.Pod) UninjectedPod {
+	p := UninjectedPod{Namespace: pod.Namespace, Name: pod.Name}
+	if ref := metav1.GetControllerOf(pod); ref != nil {
+		p.OwnerKind = ref.Kind
+		p.OwnerName = ref.Name
+	}
+	return p
+}
+
+// InjectorHealthy returns nil if the webhook of the mutating webhook configuration can inject
+// pods: it has a CA bundle, and its service, if any, has ready endpoints.
+func InjectorHealthy(client kubernetes.Interface, configName, webhookName string) error {
+	config, err := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get(configName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	for _, wh := range config.Webhooks {
+		if wh.Name != webhookName {
+			continue
+		}
+		if len(wh.ClientConfig.CABundle) == 0 {
+			return fmt.Errorf("webhook %s has no CA bundle", webhookName)
+		}
+		svc := wh.ClientConfig.Service
+		if svc == nil {
+			return nil
+		}
+		endpoints, err := client.CoreV1().Endpoints(svc.Namespace).Get(svc.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		for _, subset := range endpoints.Subsets {
+			if len(subset.Addresses) > 0 {
+				return nil
+			}
+		}
+		return fmt.Errorf("service %s/%s of webhook %s has no ready endpoints", svc.Namespace, svc.Name, webhookName)
+	}
+	return fmt.Errorf("webhook %s not found in %s", webhookName, configName)
+}
+
+// RestartOwner restarts the pods of the workload owning the uninjected pod, so that they are
+// recreated with a sidecar. It returns the restarted workload as kind/name. Deployments,
+// StatefulSets and DaemonSets are supported.
+func RestartOwner(client kubernetes.Interface, pod UninjectedPod) (string, error) {
+	kind, name, err := restartTarget(client, pod)
+	if err != nil {
+		return "", err
+	}
+	if err := restartWorkload(client, pod.Namespace, kind, name); err != nil {
+		return "", err
+	}
+	return kind + "/" + name, nil
+}
+
+// restartTarget returns the kind and name of the workload to restart for the uninjected pod: the
+// Deployment of the pods owned by a ReplicaSet, the owner of the pod otherwise.
+func restartTarget(client kubernetes.Interface, pod UninjectedPod) (string, string, error) {
+	kind, name := pod.OwnerKind, pod.OwnerName
+	switch kind {
+	case "ReplicaSet":
+		rs, err := client.AppsV1().ReplicaSets(pod.Namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return "", "", err
+		}
+		ref := metav1.GetControllerOf(rs)
+		if ref == nil || ref.Kind != "Deployment" {
+			return "", "", fmt.Errorf("pod %s/%s is not owned by a deployment", pod.Namespace, pod.Name)
+		}
+		return ref.Kind, ref.Name, nil
+	case "Deployment", "StatefulSet", "DaemonSet":
+		return kind, name, nil
+	case "":
+		return "", "", fmt.Errorf("pod %s/%s has no owner to restart", pod.Namespace, pod.Name)
+	default:
+		return "", "", fmt.Errorf("pod %s/%s is owned by %s/%s, which can't be restarted", pod.Namespace, pod.Name, kind, name)
+	}
+}
+
+// restartWorkload restarts the pods of the workload by updating the annotations of its pod template.
+func restartWorkload(client kubernetes.Interface, namespace, kind, name string) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`,
+		restartedAtAnnotation, time.Now().Format(time.RFC3339)))
+	var err error
+	switch kind {
+	case "Deployment":
+		_, err = client.AppsV1().Deployments(namespace).Patch(name, types.StrategicMergePatchType, patch)
+	case "StatefulSet":
+		_, err = client.AppsV1().StatefulSets(namespace).Patch(name, types.StrategicMergePatchType, patch)
+	case "DaemonSet":
+		_, err = client.AppsV1().DaemonSets(namespace).Patch(name, types.StrategicMergePatchType, patch)
+	default:
+		err = fmt.Errorf("%s/%s can't be restarted", kind, name)
+	}
+	return err
+}
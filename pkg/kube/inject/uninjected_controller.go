@@ -0,0 +1,300 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"istio.io/pkg/log"
+)
+
+const (
+	// uninjectedPodsElectionID is the name of the config map electing the injector replica
+	// checking the pods.
+	uninjectedPodsElectionID  = "istio-uninjected-pods-leader"
+	uninjectedPodsElectionTTL = 30 * time.Second
+)
+
+// UninjectedPodsOptions configures an UninjectedPodsController.
+type UninjectedPodsOptions struct {
+	// WebhookConfigName and WebhookName identify the injection webhook, whose selectors decide
+	// which pods should have a sidecar.
+	WebhookConfigName string
+	WebhookName       string
+
+	// CheckInterval is how frequently the pods are checked.
+	CheckInterval time.Duration
+
+	// Restart enables restarting the owners of the uninjected pods, once the injector is
+	// healthy.
+	Restart bool
+
+	// RestartBackoff is the minimum time between two restarts of the same owner, so that
+	// pods that can't be injected don't restart their owner continuously.
+	RestartBackoff time.Duration
+
+	// ElectionNamespace is the namespace of the lock electing the injector replica checking the
+	// pods, so that a single replica restarts the owners. The pods are checked without election
+	// if it is empty.
+	ElectionNamespace string
+
+	// Identity is the name of this replica in the election, usually its pod name.
+	Identity string
+}
+
+// UninjectedPodsController periodically looks for the pods that should have a sidecar but were
+// created without one, such as while the injection webhook was unavailable. The pods are
+// reported in metrics, and their owners are optionally restarted once the injector is healthy.
+type UninjectedPodsController struct {
+	client  kubernetes.Interface
+	config  func() *Config
+	options UninjectedPodsOptions
+
+	informers  informers.SharedInformerFactory
+	namespaces corelisters.NamespaceLister
+	pods       corelisters.PodLister
+	synced     []cache.InformerSynced
+
+	mu         sync.Mutex
+	uninjected []UninjectedPod
+	// restarted is the last time the owners were restarted, by namespace/kind/name.
+	restarted map[string]time.Time
+	// reported are the namespaces whose number of uninjected pods was last reported as
+	// non-zero.
+	reported map[string]bool
+}
+
+// NewUninjectedPodsController creates a controller checking the pods of the cluster against the
+// sidecar injection configuration returned by config.
+func NewUninjectedPodsController(client kubernetes.Interface, config func() *Config,
+	options UninjectedPodsOptions) *UninjectedPodsController {
+	factory := informers.NewSharedInformerFactory(client, 0)
+	namespaces := factory.Core().V1().Namespaces()
+	pods := factory.Core().V1().Pods()
+	return &UninjectedPodsController{
+		client:     client,
+		config:     config,
+		options:    options,
+		informers:  factory,
+		namespaces: namespaces.Lister(),
+		pods:       pods.Lister(),
+		synced:     []cache.InformerSynced{namespaces.Informer().HasSynced, pods.Informer().HasSynced},
+		restarted:  make(map[string]time.Time),
+		reported:   make(map[string]bool),
+	}
+}
+
+// Run checks the pods until stop is closed. With an election namespace, the pods are only
+// checked while this replica is the leader.
+func (c *UninjectedPodsController) Run(stop <-chan struct{}) {
+	if c.options.ElectionNamespace == "" {
+		c.run(stop, stop)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stop
+		cancel()
+	}()
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.ConfigMapLock{
+			ConfigMapMeta: metav1.ObjectMeta{Namespace: c.options.ElectionNamespace, Name: uninjectedPodsElectionID},
+			Client:        c.client.CoreV1(),
+			LockConfig:    resourcelock.ResourceLockConfig{Identity: c.options.Identity},
+		},
+		LeaseDuration:   uninjectedPodsElectionTTL,
+		RenewDeadline:   uninjectedPodsElectionTTL / 2,
+		RetryPeriod:     uninjectedPodsElectionTTL / 4,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leading context.Context) {
+				log.Infof("Checking for uninjected pods as the leader %s", c.options.Identity)
+				c.run(stop, leading.Done())
+			},
+			OnStoppedLeading: func() {
+				log.Infof("Stopped checking for uninjected pods, %s isn't the leader anymore", c.options.Identity)
+			},
+		},
+	})
+	if err != nil {
+		log.Errorf("Failed to start the election of the uninjected pods controller: %v", err)
+		return
+	}
+	// The elector returns when the leadership is lost, run for the next election.
+	for ctx.Err() == nil {
+		elector.Run(ctx)
+	}
+}
+
+// run checks the pods until leading is closed. The informers, which keep their cache across
+// elections, run until stop is closed.
+func (c *UninjectedPodsController) run(stop, leading <-chan struct{}) {
+	c.informers.Start(stop)
+	if !cache.WaitForCacheSync(leading, c.synced...) {
+		return
+	}
+
+	t := time.NewTicker(c.options.CheckInterval)
+	defer t.Stop()
+	for {
+		c.check()
+		select {
+		case <-t.C:
+		case <-leading:
+			return
+		}
+	}
+}
+
+// UninjectedPods returns the uninjected pods found by the last check.
+func (c *UninjectedPodsController) UninjectedPods() []UninjectedPod {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.uninjected
+}
+
+func (c *UninjectedPodsController) check() {
+	selector, err := WebhookInjectionSelector(c.client, c.options.WebhookConfigName, c.options.WebhookName)
+	if err != nil {
+		log.Warnf("Failed to get the selectors of the injection webhook: %v", err)
+		return
+	}
+	uninjected, err := c.findUninjectedPods(selector)
+	if err != nil {
+		log.Warnf("Failed to check for uninjected pods: %v", err)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.uninjected = uninjected
+	c.report()
+	if len(uninjected) == 0 {
+		return
+	}
+	log.Warnf("Found %d pods without sidecar in namespaces with injection enabled", len(uninjected))
+
+	if !c.options.Restart {
+		return
+	}
+	if err := InjectorHealthy(c.client, c.options.WebhookConfigName, c.options.WebhookName); err != nil {
+		log.Infof("Not restarting the owners of the uninjected pods, the injector isn't healthy: %v", err)
+		return
+	}
+	c.restart(time.Now())
+}
+
+func (c *UninjectedPodsController) findUninjectedPods(selector *InjectionSelector) ([]UninjectedPod, error) {
+	namespaces, err := c.namespaces.List(selector.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	config := c.config()
+	var uninjected []UninjectedPod
+	for _, ns := range namespaces {
+		pods, err := c.pods.Pods(ns.Name).List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range pods {
+			if IsUninjected(config, selector, ns, pod) {
+				uninjected = append(uninjected, newUninjectedPod(pod))
+			}
+		}
+	}
+	sortUninjectedPods(uninjected)
+	return uninjected, nil
+}
+
+// report records the number of uninjected pods by namespace, resetting the namespaces which no
+// longer have any.
+func (c *UninjectedPodsController) report() {
+	counts := make(map[string]int)
+	for _, pod := range c.uninjected {
+		counts[pod.Namespace]++
+	}
+	for ns := range c.reported {
+		if counts[ns] == 0 {
+			uninjectedPods.With(namespaceTag.Value(ns)).Record(0)
+		}
+	}
+	c.reported = make(map[string]bool, len(counts))
+	for ns, count := range counts {
+		uninjectedPods.With(namespaceTag.Value(ns)).Record(float64(count))
+		c.reported[ns] = true
+	}
+}
+
+// restart restarts the owners of the uninjected pods that weren't restarted within the backoff.
+func (c *UninjectedPodsController) restart(now time.Time) {
+	for key, t := range c.restarted {
+		if now.Sub(t) >= c.options.RestartBackoff {
+			delete(c.restarted, key)
+		}
+	}
+
+	// The kind/name of the workloads to restart, by namespace/kind/name of the pod owners, so that
+	// the ReplicaSets are resolved once.
+	type workload struct{ kind, name string }
+	targets := make(map[string]workload)
+	for _, pod := range c.uninjected {
+		if pod.OwnerKind == "" {
+			log.Debugf("Not restarting uninjected pod %s/%s, which has no owner", pod.Namespace, pod.Name)
+			continue
+		}
+		ownerKey := pod.Namespace + "/" + pod.Owner()
+		target, ok := targets[ownerKey]
+		if !ok {
+			kind, name, err := restartTarget(c.client, pod)
+			if err != nil {
+				// Back off from the pod owner, which can't be resolved.
+				if _, ok := c.restarted[ownerKey]; !ok {
+					c.restarted[ownerKey] = now
+					log.Warnf("Failed to restart the owner of uninjected pod %s/%s: %v", pod.Namespace, pod.Name, err)
+				}
+				continue
+			}
+			target = workload{kind, name}
+			targets[ownerKey] = target
+		}
+		// The pods of all the ReplicaSets of a Deployment, such as during a rollout, share the
+		// backoff of the Deployment.
+		owner := target.kind + "/" + target.name
+		key := pod.Namespace + "/" + owner
+		if _, ok := c.restarted[key]; ok {
+			continue
+		}
+		// Whatever the outcome, don't retry before the backoff.
+		c.restarted[key] = now
+		if err := restartWorkload(c.client, pod.Namespace, target.kind, target.name); err != nil {
+			log.Warnf("Failed to restart %s in namespace %s: %v", owner, pod.Namespace, err)
+			continue
+		}
+		log.Infof("Restarted %s in namespace %s to inject its uninjected pods", owner, pod.Namespace)
+		totalUninjectedRestarts.Increment()
+	}
+}
@@ -0,0 +1,452 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"istio.io/api/annotation"
+
+	"istio.io/istio/pkg/test/util/retry"
+)
+
+const (
+	testWebhookConfig = "istio-sidecar-injector"
+	testWebhook       = "sidecar-injector.istio.io"
+)
+
+func uninjectedTestNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func uninjectedTestPod(namespace, name string, owner *metav1.OwnerReference, annotations map[string]string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        name,
+			Labels:      map[string]string{"app": name},
+			Annotations: annotations,
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if owner != nil {
+		pod.OwnerReferences = []metav1.OwnerReference{*owner}
+	}
+	return pod
+}
+
+func controllerRef(kind, name string) *metav1.OwnerReference {
+	controller := true
+	return &metav1.OwnerReference{Kind: kind, Name: name, Controller: &controller}
+}
+
+func testWebhookConfiguration(caBundle []byte, selector *metav1.LabelSelector) *v1beta1.MutatingWebhookConfiguration {
+	return &v1beta1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: testWebhookConfig},
+		Webhooks: []v1beta1.MutatingWebhook{{
+			Name: testWebhook,
+			ClientConfig: v1beta1.WebhookClientConfig{
+				Service:  &v1beta1.ServiceReference{Namespace: "istio-system", Name: "istio-sidecar-injector"},
+				CABundle: caBundle,
+			},
+			NamespaceSelector: selector,
+		}},
+	}
+}
+
+func injectorEndpoints(ready bool) *corev1.Endpoints {
+	ep := &corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{Namespace: "istio-system", Name: "istio-sidecar-injector"}}
+	address := []corev1.EndpointAddress{{IP: "10.0.0.1"}}
+	if ready {
+		ep.Subsets = []corev1.EndpointSubset{{Addresses: address}}
+	} else {
+		ep.Subsets = []corev1.EndpointSubset{{NotReadyAddresses: address}}
+	}
+	return ep
+}
+
+// uninjectedTestObjects returns a cluster with injection enabled in the default namespace, where
+// the pods of the foo deployment and a bare pod are uninjected.
+func uninjectedTestObjects() []runtime.Object {
+	injected := map[string]string{annotation.SidecarStatus.Name: `{"containers":["istio-proxy"]}`}
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Namespace:       "default",
+		Name:            "foo-1234",
+		OwnerReferences: []metav1.OwnerReference{*controllerRef("Deployment", "foo")},
+	}}
+	return []runtime.Object{
+		uninjectedTestNamespace("default", map[string]string{InjectionLabel: "enabled"}),
+		uninjectedTestNamespace("other", nil),
+		rs,
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}},
+		uninjectedTestPod("default", "foo-1234-a", controllerRef("ReplicaSet", "foo-1234"), nil),
+		uninjectedTestPod("default", "foo-1234-b", controllerRef("ReplicaSet", "foo-1234"), nil),
+		uninjectedTestPod("default", "bar-5678-a", controllerRef("ReplicaSet", "bar-5678"), injected),
+		uninjectedTestPod("default", "bare", nil, nil),
+		uninjectedTestPod("other", "baz", nil, nil),
+	}
+}
+
+func TestIsUninjected(t *testing.T) {
+	config := &Config{Policy: InjectionPolicyEnabled}
+	enabled := uninjectedTestNamespace("default", map[string]string{InjectionLabel: "enabled"})
+	now := metav1.Now()
+
+	cases := []struct {
+		name      string
+		namespace *corev1.Namespace
+		pod       func(*corev1.Pod)
+		want      bool
+	}{
+		{
+			name:      "uninjected",
+			namespace: enabled,
+			want:      true,
+		},
+		{
+			name:      "injected",
+			namespace: enabled,
+			pod: func(pod *corev1.Pod) {
+				pod.Annotations = map[string]string{annotation.SidecarStatus.Name: "{}"}
+			},
+		},
+		{
+			name:      "injection not enabled",
+			namespace: uninjectedTestNamespace("default", nil),
+		},
+		{
+			name:      "injection disabled by annotation",
+			namespace: enabled,
+			pod: func(pod *corev1.Pod) {
+				pod.Annotations = map[string]string{annotation.SidecarInject.Name: "false"}
+			},
+		},
+		{
+			name:      "host network",
+			namespace: enabled,
+			pod: func(pod *corev1.Pod) {
+				pod.Spec.HostNetwork = true
+			},
+		},
+		{
+			name:      "completed",
+			namespace: enabled,
+			pod: func(pod *corev1.Pod) {
+				pod.Status.Phase = corev1.PodSucceeded
+			},
+		},
+		{
+			name:      "terminating",
+			namespace: enabled,
+			pod: func(pod *corev1.Pod) {
+				pod.DeletionTimestamp = &now
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pod := uninjectedTestPod("default", "foo", nil, nil)
+			if c.pod != nil {
+				c.pod(pod)
+			}
+			if got := IsUninjected(config, DefaultInjectionSelector(), c.namespace, pod); got != c.want {
+				t.Errorf("IsUninjected() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestFindUninjectedPods(t *testing.T) {
+	config := &Config{Policy: InjectionPolicyEnabled}
+	want := []UninjectedPod{
+		{Namespace: "default", Name: "bare"},
+		{Namespace: "default", Name: "foo-1234-a", OwnerKind: "ReplicaSet", OwnerName: "foo-1234"},
+		{Namespace: "default", Name: "foo-1234-b", OwnerKind: "ReplicaSet", OwnerName: "foo-1234"},
+	}
+
+	client := fake.NewSimpleClientset(uninjectedTestObjects()...)
+	for _, ns := range []string{metav1.NamespaceAll, "default"} {
+		got, err := FindUninjectedPods(client, config, DefaultInjectionSelector(), ns)
+		if err != nil {
+			t.Fatalf("FindUninjectedPods(%q) failed: %v", ns, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("FindUninjectedPods(%q) = %v, want %v", ns, got, want)
+		}
+	}
+	got, err := FindUninjectedPods(client, config, DefaultInjectionSelector(), "other")
+	if err != nil || len(got) != 0 {
+		t.Errorf("FindUninjectedPods(other) = %v, %v, want no pods", got, err)
+	}
+}
+
+func TestWebhookInjectionSelector(t *testing.T) {
+	selector := &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      InjectionLabel,
+			Operator: metav1.LabelSelectorOpNotIn,
+			Values:   []string{"disabled"},
+		}},
+	}
+	cases := []struct {
+		name    string
+		objects []runtime.Object
+		labels  map[string]string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:   "default without configuration",
+			labels: map[string]string{InjectionLabel: "enabled"},
+			want:   true,
+		},
+		{
+			name:   "default without label",
+			labels: map[string]string{},
+			want:   false,
+		},
+		{
+			name:    "webhook selector",
+			objects: []runtime.Object{testWebhookConfiguration(nil, selector)},
+			labels:  map[string]string{},
+			want:    true,
+		},
+		{
+			name:    "webhook selector excludes",
+			objects: []runtime.Object{testWebhookConfiguration(nil, selector)},
+			labels:  map[string]string{InjectionLabel: "disabled"},
+			want:    false,
+		},
+		{
+			name: "webhook not found",
+			objects: []runtime.Object{&v1beta1.MutatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: testWebhookConfig},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s, err := WebhookInjectionSelector(fake.NewSimpleClientset(c.objects...), testWebhookConfig, testWebhook)
+			if c.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WebhookInjectionSelector() failed: %v", err)
+			}
+			ns := uninjectedTestNamespace("default", c.labels)
+			pod := uninjectedTestPod("default", "foo", nil, nil)
+			if got := IsUninjected(&Config{Policy: InjectionPolicyEnabled}, s, ns, pod); got != c.want {
+				t.Errorf("IsUninjected() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestInjectorHealthy(t *testing.T) {
+	cases := []struct {
+		name    string
+		objects []runtime.Object
+		healthy bool
+	}{
+		{
+			name:    "healthy",
+			objects: []runtime.Object{testWebhookConfiguration([]byte("ca"), nil), injectorEndpoints(true)},
+			healthy: true,
+		},
+		{
+			name:    "no configuration",
+			objects: []runtime.Object{injectorEndpoints(true)},
+		},
+		{
+			name:    "no CA bundle",
+			objects: []runtime.Object{testWebhookConfiguration(nil, nil), injectorEndpoints(true)},
+		},
+		{
+			name:    "no ready endpoints",
+			objects: []runtime.Object{testWebhookConfiguration([]byte("ca"), nil), injectorEndpoints(false)},
+		},
+		{
+			name:    "no endpoints",
+			objects: []runtime.Object{testWebhookConfiguration([]byte("ca"), nil)},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := InjectorHealthy(fake.NewSimpleClientset(c.objects...), testWebhookConfig, testWebhook)
+			if c.healthy != (err == nil) {
+				t.Errorf("InjectorHealthy() = %v, want healthy %v", err, c.healthy)
+			}
+		})
+	}
+}
+
+func restartedAt(t *testing.T, client kubernetes.Interface, name string) string {
+	t.Helper()
+	d, err := client.AppsV1().Deployments("default").Get(name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d.Spec.Template.Annotations[restartedAtAnnotation]
+}
+
+func TestRestartOwner(t *testing.T) {
+	client := fake.NewSimpleClientset(uninjectedTestObjects()...)
+
+	owner, err := RestartOwner(client, UninjectedPod{
+		Namespace: "default", Name: "foo-1234-a", OwnerKind: "ReplicaSet", OwnerName: "foo-1234",
+	})
+	if err != nil {
+		t.Fatalf("RestartOwner() failed: %v", err)
+	}
+	if owner != "Deployment/foo" {
+		t.Errorf("RestartOwner() = %q, want Deployment/foo", owner)
+	}
+	if restartedAt(t, client, "foo") == "" {
+		t.Error("deployment foo wasn't restarted")
+	}
+
+	for _, pod := range []UninjectedPod{
+		{Namespace: "default", Name: "bare"},
+		{Namespace: "default", Name: "job-a", OwnerKind: "Job", OwnerName: "job"},
+		{Namespace: "default", Name: "bar-5678-a", OwnerKind: "ReplicaSet", OwnerName: "bar-5678"},
+	} {
+		if _, err := RestartOwner(client, pod); err == nil {
+			t.Errorf("expected error restarting the owner of %s", pod.Name)
+		}
+	}
+}
+
+func TestUninjectedPodsController(t *testing.T) {
+	objects := append(uninjectedTestObjects(),
+		testWebhookConfiguration([]byte("ca"), &metav1.LabelSelector{MatchLabels: map[string]string{InjectionLabel: "enabled"}}),
+		injectorEndpoints(false),
+		// A rollout of deployment foo is in progress.
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "foo-9999",
+			OwnerReferences: []metav1.OwnerReference{*controllerRef("Deployment", "foo")},
+		}},
+		uninjectedTestPod("default", "foo-9999-a", controllerRef("ReplicaSet", "foo-9999"), nil))
+	client := fake.NewSimpleClientset(objects...)
+	config := &Config{Policy: InjectionPolicyEnabled}
+	c := NewUninjectedPodsController(client, func() *Config { return config }, UninjectedPodsOptions{
+		WebhookConfigName: testWebhookConfig,
+		WebhookName:       testWebhook,
+		CheckInterval:     time.Hour,
+		Restart:           true,
+		RestartBackoff:    time.Hour,
+	})
+	stop := make(chan struct{})
+	defer close(stop)
+	c.informers.Start(stop)
+	c.informers.WaitForCacheSync(stop)
+
+	// The injector isn't ready, the pods are only reported.
+	c.check()
+	if got := len(c.UninjectedPods()); got != 4 {
+		t.Errorf("got %d uninjected pods, want 4", got)
+	}
+	if restartedAt(t, client, "foo") != "" {
+		t.Fatal("deployment foo was restarted while the injector isn't healthy")
+	}
+
+	if _, err := client.CoreV1().Endpoints("istio-system").Update(injectorEndpoints(true)); err != nil {
+		t.Fatal(err)
+	}
+	c.check()
+	first := restartedAt(t, client, "foo")
+	if first == "" {
+		t.Fatal("deployment foo wasn't restarted once the injector is healthy")
+	}
+	// The pods of both ReplicaSets share the backoff of the deployment.
+	if _, ok := c.restarted["default/Deployment/foo"]; !ok || len(c.restarted) != 1 {
+		t.Errorf("got restarted owners %v, want only deployment foo", c.restarted)
+	}
+	patches := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "patch" {
+			patches++
+		}
+	}
+	if patches != 1 {
+		t.Errorf("deployment foo was restarted %d times, want once", patches)
+	}
+
+	// Within the backoff, the deployment isn't restarted again.
+	c.mu.Lock()
+	c.restart(time.Now().Add(time.Minute))
+	c.mu.Unlock()
+	if len(c.restarted) != 1 {
+		t.Errorf("got restarted owners %v, want only deployment foo", c.restarted)
+	}
+	for key, at := range c.restarted {
+		if time.Since(at) > time.Minute {
+			t.Errorf("owner %s restarted again within the backoff", key)
+		}
+	}
+	if got := restartedAt(t, client, "foo"); got != first {
+		t.Errorf("deployment foo restarted again within the backoff")
+	}
+}
+
+func TestUninjectedPodsControllerElection(t *testing.T) {
+	objects := append(uninjectedTestObjects(),
+		testWebhookConfiguration([]byte("ca"), &metav1.LabelSelector{MatchLabels: map[string]string{InjectionLabel: "enabled"}}),
+		injectorEndpoints(false))
+	client := fake.NewSimpleClientset(objects...)
+	config := &Config{Policy: InjectionPolicyEnabled}
+	c := NewUninjectedPodsController(client, func() *Config { return config }, UninjectedPodsOptions{
+		WebhookConfigName: testWebhookConfig,
+		WebhookName:       testWebhook,
+		CheckInterval:     time.Hour,
+		ElectionNamespace: "istio-system",
+		Identity:          "istio-sidecar-injector-0",
+	})
+	stop := make(chan struct{})
+	defer close(stop)
+	go c.Run(stop)
+
+	// The pods are checked once the replica holds the lock.
+	retry.UntilSuccessOrFail(t, func() error {
+		if got := len(c.UninjectedPods()); got != 3 {
+			return fmt.Errorf("got %d uninjected pods, want 3", got)
+		}
+		return nil
+	}, retry.Timeout(10*time.Second))
+	lock, err := client.CoreV1().ConfigMaps("istio-system").Get(uninjectedPodsElectionID, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get the election lock: %v", err)
+	}
+	if !strings.Contains(lock.Annotations[resourcelock.LeaderElectionRecordAnnotationKey], "istio-sidecar-injector-0") {
+		t.Errorf("expected the replica to hold the lock, got %v", lock.Annotations)
+	}
+}
@@ -35,6 +35,7 @@ import (
 	"istio.io/istio/pilot/cmd/pilot-agent/status"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/kube/events"
 
 	"istio.io/pkg/log"
 
@@ -80,6 +81,9 @@ type Webhook struct {
 	keyFile    string
 	cert       *tls.Certificate
 	mon        *monitor
+	recorder   *events.Recorder
+
+	isRemoteCluster func(clusterID string) bool
 }
 
 func loadConfig(injectFile, meshFile, valuesFile string) (*Config, *meshconfig.MeshConfig, string, error) {
@@ -143,6 +147,15 @@ type WebhookParameters struct {
 	// HealthCheckFile specifies the path to the health check file
 	// that is periodically updated.
 	HealthCheckFile string
+
+	// EventRecorder emits the skipped and failed injections as events on the controllers of the
+	// pods. The events are not emitted if nil.
+	EventRecorder *events.Recorder
+
+	// IsRemoteCluster checks whether a cluster ID is the one of a remote cluster of the mesh, whose
+	// pods are injected through the remote injection path. Requests for unknown clusters are
+	// rejected, all of them if nil.
+	IsRemoteCluster func(clusterID string) bool
 }
 
 // NewWebhook creates a new instance of a mutating webhook for automatic sidecar injection.
@@ -186,11 +199,14 @@ func NewWebhook(p WebhookParameters) (*Webhook, error) {
 		certFile:               p.CertFile,
 		keyFile:                p.KeyFile,
 		cert:                   &pair,
+		recorder:               p.EventRecorder,
+		isRemoteCluster:        p.IsRemoteCluster,
 	}
 	// mtls disabled because apiserver webhook cert usage is still TBD.
 	wh.server.TLSConfig = &tls.Config{GetCertificate: wh.getCert}
 	h := http.NewServeMux()
 	h.HandleFunc("/inject", wh.serveInject)
+	h.HandleFunc(remoteInjectPath, wh.serveInject)
 
 	mon, err := startMonitor(h, p.MonitoringPort)
 
@@ -204,6 +220,13 @@ func NewWebhook(p WebhookParameters) (*Webhook, error) {
 	return wh, nil
 }
 
+// SidecarConfig returns the current sidecar injection configuration.
+func (wh *Webhook) SidecarConfig() *Config {
+	wh.mu.RLock()
+	defer wh.mu.RUnlock()
+	return wh.sidecarConfig
+}
+
 // Run implements the webhook server
 func (wh *Webhook) Run(stop <-chan struct{}) {
 	go func() {
@@ -589,7 +612,7 @@ func toAdmissionResponse(err error) *v1beta1.AdmissionResponse {
 	return &v1beta1.AdmissionResponse{Result: &metav1.Status{Message: err.Error()}}
 }
 
-func (wh *Webhook) inject(ar *v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
+func (wh *Webhook) inject(ar *v1beta1.AdmissionReview, clusterID string) *v1beta1.AdmissionResponse {
 	req := ar.Request
 	var pod corev1.Pod
 	if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
@@ -612,6 +635,8 @@ func (wh *Webhook) inject(ar *v1beta1.AdmissionReview) *v1beta1.AdmissionRespons
 	if !injectRequired(ignoredNamespaces, wh.sidecarConfig, &pod.Spec, &pod.ObjectMeta) {
 		log.Infof("Skipping %s/%s due to policy check", pod.ObjectMeta.Namespace, podName)
 		totalSkippedInjections.Increment()
+		wh.recorder.Eventf(podEventReference(&pod), events.Normal, "InjectionSkipped",
+			"Skipped the sidecar injection of pod %s due to policy check", podName)
 		return &v1beta1.AdmissionResponse{
 			Allowed: true,
 		}
@@ -671,9 +696,15 @@ func (wh *Webhook) inject(ar *v1beta1.AdmissionReview) *v1beta1.AdmissionRespons
 	spec, iStatus, err := InjectionData(wh.sidecarConfig.Template, wh.valuesConfig, wh.sidecarTemplateVersion, typeMetadata, deployMeta, &pod.Spec, &pod.ObjectMeta, wh.meshConfig.DefaultConfig, wh.meshConfig) // nolint: lll
 	if err != nil {
 		handleError(fmt.Sprintf("Injection data: err=%v spec=%v\n", err, iStatus))
+		wh.recorder.Eventf(podEventReference(&pod), events.Warning, "InjectionFailed",
+			"Failed to inject the sidecar of pod %s: %v", podName, err)
 		return toAdmissionResponse(err)
 	}
 
+	if clusterID != "" {
+		setProxyClusterID(spec, clusterID)
+	}
+
 	annotations := map[string]string{annotation.SidecarStatus.Name: iStatus}
 
 	// Add all additional injected annotations
@@ -684,6 +715,8 @@ func (wh *Webhook) inject(ar *v1beta1.AdmissionReview) *v1beta1.AdmissionRespons
 	patchBytes, err := createPatch(&pod, injectionStatus(&pod), annotations, spec)
 	if err != nil {
 		handleError(fmt.Sprintf("AdmissionResponse: err=%v spec=%v\n", err, spec))
+		wh.recorder.Eventf(podEventReference(&pod), events.Warning, "InjectionFailed",
+			"Failed to inject the sidecar of pod %s: %v", podName, err)
 		return toAdmissionResponse(err)
 	}
 
@@ -701,8 +734,39 @@ func (wh *Webhook) inject(ar *v1beta1.AdmissionReview) *v1beta1.AdmissionRespons
 	return &reviewResponse
 }
 
+// podEventReference returns the object on which the events of the injection of the pod are
+// emitted: its controller, as the pod isn't created yet when its name is generated, or the pod.
+func podEventReference(pod *corev1.Pod) *corev1.ObjectReference {
+	for _, ref := range pod.GetOwnerReferences() {
+		if ref.Controller != nil && *ref.Controller {
+			return &corev1.ObjectReference{
+				APIVersion: ref.APIVersion,
+				Kind:       ref.Kind,
+				Namespace:  pod.Namespace,
+				Name:       ref.Name,
+				UID:        ref.UID,
+			}
+		}
+	}
+	if pod.Name == "" {
+		return nil
+	}
+	return events.ObjectReference("v1", "Pod", pod.Namespace, pod.Name)
+}
+
 func (wh *Webhook) serveInject(w http.ResponseWriter, r *http.Request) {
 	totalInjections.Increment()
+	// The pods of the remote clusters of an external control plane are injected through a path
+	// naming their cluster.
+	var clusterID string
+	if strings.HasPrefix(r.URL.Path, remoteInjectPath) {
+		clusterID = strings.TrimPrefix(r.URL.Path, remoteInjectPath)
+		if wh.isRemoteCluster == nil || !wh.isRemoteCluster(clusterID) {
+			handleError(fmt.Sprintf("unknown cluster %q", clusterID))
+			http.Error(w, fmt.Sprintf("unknown cluster %q", clusterID), http.StatusNotFound)
+			return
+		}
+	}
 	var body []byte
 	if r.Body != nil {
 		if data, err := ioutil.ReadAll(r.Body); err == nil {
@@ -729,7 +793,7 @@ func (wh *Webhook) serveInject(w http.ResponseWriter, r *http.Request) {
 		handleError(fmt.Sprintf("Could not decode body: %v", err))
 		reviewResponse = toAdmissionResponse(err)
 	} else {
-		reviewResponse = wh.inject(&ar)
+		reviewResponse = wh.inject(&ar, clusterID)
 	}
 
 	response := v1beta1.AdmissionReview{}
@@ -24,6 +24,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -35,9 +36,11 @@ import (
 	"github.com/onsi/gomega"
 
 	"istio.io/api/annotation"
+	meshconfig "istio.io/api/mesh/v1alpha1"
 
 	"istio.io/istio/pilot/test/util"
 	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/kube/events"
 	"istio.io/istio/pkg/mcp/testing/testcerts"
 
 	"k8s.io/api/admission/v1beta1"
@@ -657,7 +660,7 @@ func TestWebhookInject(t *testing.T) {
 						Raw: podJSON,
 					},
 				},
-			})
+			}, "")
 			var prettyPatch bytes.Buffer
 			if err := json.Indent(&prettyPatch, got.Patch, "", "  "); err != nil {
 				t.Fatalf(err.Error())
@@ -799,7 +802,7 @@ func TestHelmInject(t *testing.T) {
 								Raw: templateJSON,
 							},
 						},
-					})
+					}, "")
 
 					// Apply the generated patch to the template.
 					patch := prettyJSON(got.Patch, t)
@@ -1597,3 +1600,78 @@ func BenchmarkInjectServe(b *testing.B) {
 		wh.serveInject(httptest.NewRecorder(), req)
 	}
 }
+
+func TestPodEventReference(t *testing.T) {
+	controller := true
+	cases := []struct {
+		name     string
+		pod      *corev1.Pod
+		expected *corev1.ObjectReference
+	}{
+		{
+			name: "controller",
+			pod: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "productpage-v1-84f77f8747-",
+				Namespace:    "default",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "apps/v1",
+					Kind:       "ReplicaSet",
+					Name:       "productpage-v1-84f77f8747",
+					UID:        "1234",
+					Controller: &controller,
+				}},
+			}},
+			expected: &corev1.ObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Namespace:  "default",
+				Name:       "productpage-v1-84f77f8747",
+				UID:        "1234",
+			},
+		},
+		{
+			name:     "pod",
+			pod:      &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "productpage", Namespace: "default"}},
+			expected: events.ObjectReference("v1", "Pod", "default", "productpage"),
+		},
+		{
+			name: "generated name without controller",
+			pod:  &corev1.Pod{ObjectMeta: metav1.ObjectMeta{GenerateName: "productpage-", Namespace: "default"}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := podEventReference(c.pod); !reflect.DeepEqual(got, c.expected) {
+				t.Errorf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestInjectionSkippedEvent(t *testing.T) {
+	recorder, emitted := events.NewFakeRecorder(1)
+	wh := &Webhook{
+		sidecarConfig: &Config{Policy: InjectionPolicyEnabled},
+		meshConfig:    &meshconfig.MeshConfig{},
+		recorder:      recorder,
+	}
+
+	var review v1beta1.AdmissionReview
+	if err := json.Unmarshal(makeTestData(t, true), &review); err != nil {
+		t.Fatal(err)
+	}
+	review.Request.Namespace = "default"
+	if got := wh.inject(&review, ""); !got.Allowed || got.Patch != nil {
+		t.Fatalf("expected the injection to be skipped, got %v", got)
+	}
+
+	select {
+	case event := <-emitted:
+		if event.Ref.Kind != "Pod" || event.Ref.Namespace != "default" || event.Ref.Name != "test" ||
+			event.Type != events.Normal || event.Reason != "InjectionSkipped" {
+			t.Errorf("unexpected event %v", event)
+		}
+	default:
+		t.Error("expected an event for the skipped injection")
+	}
+}
@@ -70,3 +70,10 @@ func (c *Instance) ForwardEcho(ctx context.Context, request *proto.ForwardEchoRe
 
 	return parseForwardedResponse(resp), nil
 }
+
+// SetBehavior sets the failure behavior of the server, for the requests that don't select one with headers.
+// A nil behavior restores the default of responding immediately and successfully.
+func (c *Instance) SetBehavior(ctx context.Context, behavior *proto.Behavior) error {
+	_, err := c.client.SetBehavior(ctx, &proto.SetBehaviorRequest{Behavior: behavior})
+	return err
+}
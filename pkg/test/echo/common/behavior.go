@@ -0,0 +1,152 @@
+//  Copyright 2019 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"istio.io/istio/pkg/test/echo/proto"
+)
+
+// Request headers that select the failure behavior of the echo server for a single request. They
+// take precedence over the behavior set on the server with SetBehavior.
+const (
+	// DelayHeader is the delay before responding, as a duration (e.g. "100ms").
+	DelayHeader = "x-echo-delay"
+	// StatusHeader is the HTTP status code of the response.
+	StatusHeader = "x-echo-status"
+	// ResetHeader resets the connection instead of responding, if "true".
+	ResetHeader = "x-echo-reset"
+	// PartialBodyHeader writes only half of the response body, if "true".
+	PartialBodyHeader = "x-echo-partial-body"
+	// FailureRatioHeader is the fraction of the requests that the behavior applies to.
+	FailureRatioHeader = "x-echo-failure-ratio"
+)
+
+var behaviorHeaders = []string{DelayHeader, StatusHeader, ResetHeader, PartialBodyHeader, FailureRatioHeader}
+
+// BehaviorFromHeaders returns the behavior selected by the request headers, read with the given
+// function, or nil if the request doesn't select any.
+func BehaviorFromHeaders(header func(name string) string) (*proto.Behavior, error) {
+	found := false
+	for _, name := range behaviorHeaders {
+		if header(name) != "" {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+
+	b := &proto.Behavior{}
+	if v := header(DelayHeader); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s header %q: %v", DelayHeader, v, err)
+		}
+		b.DelayMicros = DurationToMicros(d)
+	}
+	if v := header(StatusHeader); v != "" {
+		code, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s header %q: %v", StatusHeader, v, err)
+		}
+		b.StatusCode = int32(code)
+	}
+	if v := header(ResetHeader); v != "" {
+		reset, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s header %q: %v", ResetHeader, v, err)
+		}
+		b.ResetConnection = reset
+	}
+	if v := header(PartialBodyHeader); v != "" {
+		partial, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s header %q: %v", PartialBodyHeader, v, err)
+		}
+		b.PartialBody = partial
+	}
+	if v := header(FailureRatioHeader); v != "" {
+		ratio, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s header %q: %v", FailureRatioHeader, v, err)
+		}
+		b.FailureRatio = ratio
+	}
+
+	if err := ValidateBehavior(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// BehaviorHeaders returns the request headers that select the given behavior.
+func BehaviorHeaders(b *proto.Behavior) http.Header {
+	headers := make(http.Header)
+	if b == nil {
+		return headers
+	}
+	if b.DelayMicros > 0 {
+		headers.Set(DelayHeader, MicrosToDuration(b.DelayMicros).String())
+	}
+	if b.StatusCode != 0 {
+		headers.Set(StatusHeader, strconv.Itoa(int(b.StatusCode)))
+	}
+	if b.ResetConnection {
+		headers.Set(ResetHeader, "true")
+	}
+	if b.PartialBody {
+		headers.Set(PartialBodyHeader, "true")
+	}
+	if b.FailureRatio > 0 {
+		headers.Set(FailureRatioHeader, strconv.FormatFloat(b.FailureRatio, 'f', -1, 64))
+	}
+	return headers
+}
+
+// ValidateBehavior checks that the behavior can be applied by the echo server.
+func ValidateBehavior(b *proto.Behavior) error {
+	if b == nil {
+		return nil
+	}
+	if b.DelayMicros < 0 {
+		return fmt.Errorf("invalid delay %v", MicrosToDuration(b.DelayMicros))
+	}
+	if b.StatusCode != 0 && (b.StatusCode < http.StatusOK || b.StatusCode >= 600) {
+		return fmt.Errorf("invalid HTTP response code %v", b.StatusCode)
+	}
+	if b.FailureRatio < 0 || b.FailureRatio > 1 {
+		return fmt.Errorf("invalid failure ratio %v (want between 0 and 1)", b.FailureRatio)
+	}
+	return nil
+}
+
+// ShouldApplyBehavior returns whether the behavior applies to the current request, according to its
+// failure ratio.
+func ShouldApplyBehavior(b *proto.Behavior) bool {
+	if b == nil {
+		return false
+	}
+	if b.DelayMicros == 0 && b.StatusCode == 0 && !b.ResetConnection && !b.PartialBody {
+		return false
+	}
+	return b.FailureRatio <= 0 || rand.Float64() < b.FailureRatio
+}
@@ -4,12 +4,14 @@
 package proto
 
 import (
+	context "context"
 	fmt "fmt"
 	math "math"
 
 	proto "github.com/golang/protobuf/proto"
-	context "golang.org/x/net/context"
 	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
 )
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -21,7 +23,7 @@ var _ = math.Inf
 // is compatible with the proto package it is being compiled against.
 // A compilation error at this line likely means your copy of the
 // proto package needs to be updated.
-const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type EchoRequest struct {
 	Message              string   `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
@@ -266,37 +268,200 @@ func (m *ForwardEchoResponse) GetOutput() []string {
 	return nil
 }
 
+// Behavior is a failure behavior of the echo server, applied to the echo requests.
+type Behavior struct {
+	// Delay before responding.
+	DelayMicros int64 `protobuf:"varint,1,opt,name=delay_micros,json=delayMicros,proto3" json:"delay_micros,omitempty"`
+	// HTTP status code of the response. gRPC responses fail with the matching gRPC status code.
+	StatusCode int32 `protobuf:"varint,2,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	// Reset the connection instead of responding. gRPC responses fail with the UNAVAILABLE status code.
+	ResetConnection bool `protobuf:"varint,3,opt,name=reset_connection,json=resetConnection,proto3" json:"reset_connection,omitempty"`
+	// Write only half of the HTTP response body, then close the connection. Ignored for gRPC.
+	PartialBody bool `protobuf:"varint,4,opt,name=partial_body,json=partialBody,proto3" json:"partial_body,omitempty"`
+	// Fraction of the requests that the behavior applies to, between 0 and 1. The other requests
+	// succeed immediately. The behavior applies to all the requests if unset.
+	FailureRatio         float64  `protobuf:"fixed64,5,opt,name=failure_ratio,json=failureRatio,proto3" json:"failure_ratio,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Behavior) Reset()         { *m = Behavior{} }
+func (m *Behavior) String() string { return proto.CompactTextString(m) }
+func (*Behavior) ProtoMessage()    {}
+func (*Behavior) Descriptor() ([]byte, []int) {
+	return fileDescriptor_08134aea513e0001, []int{5}
+}
+
+func (m *Behavior) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Behavior.Unmarshal(m, b)
+}
+func (m *Behavior) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Behavior.Marshal(b, m, deterministic)
+}
+func (m *Behavior) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Behavior.Merge(m, src)
+}
+func (m *Behavior) XXX_Size() int {
+	return xxx_messageInfo_Behavior.Size(m)
+}
+func (m *Behavior) XXX_DiscardUnknown() {
+	xxx_messageInfo_Behavior.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Behavior proto.InternalMessageInfo
+
+func (m *Behavior) GetDelayMicros() int64 {
+	if m != nil {
+		return m.DelayMicros
+	}
+	return 0
+}
+
+func (m *Behavior) GetStatusCode() int32 {
+	if m != nil {
+		return m.StatusCode
+	}
+	return 0
+}
+
+func (m *Behavior) GetResetConnection() bool {
+	if m != nil {
+		return m.ResetConnection
+	}
+	return false
+}
+
+func (m *Behavior) GetPartialBody() bool {
+	if m != nil {
+		return m.PartialBody
+	}
+	return false
+}
+
+func (m *Behavior) GetFailureRatio() float64 {
+	if m != nil {
+		return m.FailureRatio
+	}
+	return 0
+}
+
+type SetBehaviorRequest struct {
+	// The behavior of the server. An empty behavior restores the default of responding immediately and
+	// successfully.
+	Behavior             *Behavior `protobuf:"bytes,1,opt,name=behavior,proto3" json:"behavior,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *SetBehaviorRequest) Reset()         { *m = SetBehaviorRequest{} }
+func (m *SetBehaviorRequest) String() string { return proto.CompactTextString(m) }
+func (*SetBehaviorRequest) ProtoMessage()    {}
+func (*SetBehaviorRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_08134aea513e0001, []int{6}
+}
+
+func (m *SetBehaviorRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetBehaviorRequest.Unmarshal(m, b)
+}
+func (m *SetBehaviorRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetBehaviorRequest.Marshal(b, m, deterministic)
+}
+func (m *SetBehaviorRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetBehaviorRequest.Merge(m, src)
+}
+func (m *SetBehaviorRequest) XXX_Size() int {
+	return xxx_messageInfo_SetBehaviorRequest.Size(m)
+}
+func (m *SetBehaviorRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetBehaviorRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetBehaviorRequest proto.InternalMessageInfo
+
+func (m *SetBehaviorRequest) GetBehavior() *Behavior {
+	if m != nil {
+		return m.Behavior
+	}
+	return nil
+}
+
+type SetBehaviorResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SetBehaviorResponse) Reset()         { *m = SetBehaviorResponse{} }
+func (m *SetBehaviorResponse) String() string { return proto.CompactTextString(m) }
+func (*SetBehaviorResponse) ProtoMessage()    {}
+func (*SetBehaviorResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_08134aea513e0001, []int{7}
+}
+
+func (m *SetBehaviorResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SetBehaviorResponse.Unmarshal(m, b)
+}
+func (m *SetBehaviorResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SetBehaviorResponse.Marshal(b, m, deterministic)
+}
+func (m *SetBehaviorResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SetBehaviorResponse.Merge(m, src)
+}
+func (m *SetBehaviorResponse) XXX_Size() int {
+	return xxx_messageInfo_SetBehaviorResponse.Size(m)
+}
+func (m *SetBehaviorResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SetBehaviorResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SetBehaviorResponse proto.InternalMessageInfo
+
 func init() {
 	proto.RegisterType((*EchoRequest)(nil), "proto.EchoRequest")
 	proto.RegisterType((*EchoResponse)(nil), "proto.EchoResponse")
 	proto.RegisterType((*Header)(nil), "proto.Header")
 	proto.RegisterType((*ForwardEchoRequest)(nil), "proto.ForwardEchoRequest")
 	proto.RegisterType((*ForwardEchoResponse)(nil), "proto.ForwardEchoResponse")
+	proto.RegisterType((*Behavior)(nil), "proto.Behavior")
+	proto.RegisterType((*SetBehaviorRequest)(nil), "proto.SetBehaviorRequest")
+	proto.RegisterType((*SetBehaviorResponse)(nil), "proto.SetBehaviorResponse")
 }
 
 func init() { proto.RegisterFile("echo.proto", fileDescriptor_08134aea513e0001) }
 
 var fileDescriptor_08134aea513e0001 = []byte{
-	// 301 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x50, 0xcb, 0x4a, 0xf3, 0x40,
-	0x18, 0x25, 0x7f, 0x3a, 0x29, 0xfd, 0xfa, 0x57, 0x65, 0x5a, 0x64, 0xec, 0xaa, 0x04, 0xa4, 0xd9,
-	0x58, 0xa5, 0xbe, 0x82, 0x8a, 0x1b, 0x37, 0xa3, 0x7b, 0x89, 0xe9, 0x87, 0x09, 0x26, 0x9d, 0x74,
-	0x2e, 0x15, 0xdf, 0xc0, 0x47, 0xf2, 0xf1, 0x64, 0x2e, 0x85, 0x04, 0xc5, 0x55, 0xe6, 0x5c, 0x72,
-	0xe6, 0xcc, 0x01, 0xc0, 0xa2, 0x14, 0xab, 0x56, 0x0a, 0x2d, 0x28, 0x71, 0x9f, 0x74, 0x09, 0xe3,
-	0xdb, 0xa2, 0x14, 0x1c, 0x77, 0x06, 0x95, 0xa6, 0x0c, 0x86, 0x0d, 0x2a, 0x95, 0xbf, 0x22, 0x8b,
-	0x16, 0x51, 0x36, 0xe2, 0x07, 0x98, 0x66, 0xf0, 0xdf, 0x1b, 0x55, 0x2b, 0xb6, 0x0a, 0xff, 0x70,
-	0x5e, 0x41, 0x72, 0x8f, 0xf9, 0x06, 0x25, 0x3d, 0x81, 0xf8, 0x0d, 0x3f, 0x82, 0x6e, 0x8f, 0x74,
-	0x06, 0x64, 0x9f, 0xd7, 0x06, 0xd9, 0x3f, 0xc7, 0x79, 0x90, 0x7e, 0x45, 0x40, 0xef, 0x84, 0x7c,
-	0xcf, 0xe5, 0xa6, 0x5b, 0x66, 0x06, 0xa4, 0x10, 0x66, 0xab, 0x5d, 0x00, 0xe1, 0x1e, 0xd8, 0xd0,
-	0x5d, 0xab, 0x5c, 0x00, 0xe1, 0xf6, 0x48, 0xcf, 0xe1, 0x48, 0x57, 0x0d, 0x0a, 0xa3, 0x9f, 0x9b,
-	0xaa, 0x90, 0x42, 0xb1, 0x78, 0x11, 0x65, 0x31, 0x9f, 0x04, 0xf6, 0xc1, 0x91, 0xf6, 0x47, 0x23,
-	0x6b, 0x36, 0xf0, 0x6d, 0x8c, 0xac, 0xe9, 0x12, 0x86, 0xa5, 0x6b, 0xaa, 0x18, 0x59, 0xc4, 0xd9,
-	0x78, 0x3d, 0xf1, 0xe3, 0xac, 0x7c, 0x7f, 0x7e, 0x50, 0xbb, 0x8f, 0x4d, 0xfa, 0x8f, 0xbd, 0x80,
-	0x69, 0xaf, 0x79, 0x58, 0xe7, 0x14, 0x12, 0x61, 0x74, 0x6b, 0x6c, 0xf7, 0x38, 0x1b, 0xf1, 0x80,
-	0xd6, 0x9f, 0x11, 0x1c, 0x5b, 0xe3, 0x13, 0x2a, 0xfd, 0x88, 0x72, 0x5f, 0x15, 0x48, 0x2f, 0x61,
-	0x60, 0x29, 0x4a, 0xc3, 0xe5, 0x9d, 0x09, 0xe6, 0xd3, 0x1e, 0x17, 0xc2, 0x6f, 0x60, 0xdc, 0xb9,
-	0x93, 0x9e, 0x05, 0xcf, 0xcf, 0x05, 0xe7, 0xf3, 0xdf, 0x24, 0x9f, 0xf2, 0x92, 0x38, 0xe9, 0xfa,
-	0x3b, 0x00, 0x00, 0xff, 0xff, 0x11, 0x07, 0xc8, 0x61, 0x15, 0x02, 0x00, 0x00,
+	// 459 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x92, 0xcf, 0x6e, 0xd3, 0x40,
+	0x10, 0xc6, 0x65, 0x5c, 0xa7, 0xe9, 0x38, 0x21, 0xd5, 0xa6, 0x20, 0xe3, 0x0b, 0xc1, 0x08, 0xd5,
+	0x08, 0x51, 0x50, 0x78, 0x02, 0x5a, 0x40, 0x5c, 0xb8, 0x6c, 0xb9, 0x5b, 0x1b, 0x7b, 0x20, 0x16,
+	0x8e, 0xc7, 0xdd, 0x3f, 0x41, 0x79, 0x33, 0xae, 0xbc, 0x00, 0xcf, 0x84, 0xbc, 0x5e, 0x07, 0x47,
+	0x8d, 0x38, 0x65, 0xe7, 0x37, 0x93, 0xcf, 0xdf, 0x7c, 0xbb, 0x00, 0x98, 0xaf, 0xe9, 0xaa, 0x91,
+	0xa4, 0x89, 0x05, 0xf6, 0x27, 0xb9, 0x84, 0xf0, 0x63, 0xbe, 0x26, 0x8e, 0x77, 0x06, 0x95, 0x66,
+	0x11, 0x9c, 0x6e, 0x50, 0x29, 0xf1, 0x1d, 0x23, 0x6f, 0xe1, 0xa5, 0x67, 0xbc, 0x2f, 0x93, 0x14,
+	0x26, 0xdd, 0xa0, 0x6a, 0xa8, 0x56, 0xf8, 0x9f, 0xc9, 0xb7, 0x30, 0xfa, 0x8c, 0xa2, 0x40, 0xc9,
+	0xce, 0xc1, 0xff, 0x81, 0x3b, 0xd7, 0x6f, 0x8f, 0xec, 0x02, 0x82, 0xad, 0xa8, 0x0c, 0x46, 0x0f,
+	0x2c, 0xeb, 0x8a, 0xe4, 0x97, 0x07, 0xec, 0x13, 0xc9, 0x9f, 0x42, 0x16, 0x43, 0x33, 0x17, 0x10,
+	0xe4, 0x64, 0x6a, 0x6d, 0x05, 0x02, 0xde, 0x15, 0xad, 0xe8, 0x5d, 0xa3, 0xac, 0x40, 0xc0, 0xdb,
+	0x23, 0x7b, 0x01, 0x0f, 0x75, 0xb9, 0x41, 0x32, 0x3a, 0xdb, 0x94, 0xb9, 0x24, 0x15, 0xf9, 0x0b,
+	0x2f, 0xf5, 0xf9, 0xd4, 0xd1, 0x2f, 0x16, 0xb6, 0x7f, 0x34, 0xb2, 0x8a, 0x4e, 0x3a, 0x37, 0x46,
+	0x56, 0xec, 0x12, 0x4e, 0xd7, 0xd6, 0xa9, 0x8a, 0x82, 0x85, 0x9f, 0x86, 0xcb, 0x69, 0x17, 0xce,
+	0x55, 0xe7, 0x9f, 0xf7, 0xdd, 0xe1, 0xb2, 0xa3, 0xc3, 0x65, 0x5f, 0xc3, 0xfc, 0xc0, 0xb9, 0x4b,
+	0xe7, 0x31, 0x8c, 0xc8, 0xe8, 0xc6, 0xb4, 0xde, 0xfd, 0xf4, 0x8c, 0xbb, 0x2a, 0xf9, 0xed, 0xc1,
+	0xf8, 0x1a, 0xd7, 0x62, 0x5b, 0x92, 0x64, 0xcf, 0x60, 0x52, 0x60, 0x25, 0x76, 0xbd, 0x6b, 0xcf,
+	0xba, 0x0e, 0x2d, 0x73, 0x9e, 0x9f, 0x42, 0xa8, 0xb4, 0xd0, 0x46, 0x65, 0x39, 0x15, 0xe8, 0x96,
+	0x86, 0x0e, 0xdd, 0x50, 0x81, 0xec, 0x25, 0x9c, 0x4b, 0x54, 0xa8, 0xb3, 0x9c, 0xea, 0x1a, 0x73,
+	0x5d, 0x52, 0x6d, 0xb7, 0x1f, 0xf3, 0x99, 0xe5, 0x37, 0x7b, 0xdc, 0x7e, 0xae, 0x11, 0x52, 0x97,
+	0xa2, 0xca, 0x56, 0x54, 0xec, 0x6c, 0x10, 0x63, 0x1e, 0x3a, 0x76, 0x4d, 0xc5, 0x8e, 0x3d, 0x87,
+	0xe9, 0x37, 0x51, 0x56, 0x46, 0x62, 0x26, 0x85, 0x2e, 0x29, 0x0a, 0x16, 0x5e, 0xea, 0xf1, 0x89,
+	0x83, 0xbc, 0x65, 0xc9, 0x7b, 0x60, 0xb7, 0xa8, 0xfb, 0x2d, 0xfa, 0xcb, 0x7a, 0x05, 0xe3, 0x95,
+	0x43, 0x76, 0x91, 0x70, 0x39, 0x73, 0x61, 0xee, 0x27, 0xf7, 0x03, 0xc9, 0x23, 0x98, 0x1f, 0x48,
+	0x74, 0xa9, 0x2d, 0xff, 0x78, 0x30, 0x6b, 0x63, 0xfc, 0x8a, 0x4a, 0xdf, 0xa2, 0xdc, 0x96, 0x39,
+	0xb2, 0x37, 0x70, 0xd2, 0x22, 0xc6, 0x9c, 0xda, 0xe0, 0x81, 0xc4, 0xf3, 0x03, 0xe6, 0xa2, 0xff,
+	0x00, 0xe1, 0xe0, 0x46, 0xd8, 0x13, 0x37, 0x73, 0xff, 0x7d, 0xc5, 0xf1, 0xb1, 0xd6, 0x3f, 0x95,
+	0x81, 0xc3, 0xbd, 0xca, 0xfd, 0xc5, 0xe3, 0xf8, 0x58, 0xab, 0x53, 0x59, 0x8d, 0x6c, 0xeb, 0xdd,
+	0xdf, 0x01, 0x00, 0x7a, 0xfb, 0x13, 0x4e, 0x79, 0x03, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -313,6 +478,8 @@ const _ = grpc.SupportPackageIsVersion4
 type EchoTestServiceClient interface {
 	Echo(ctx context.Context, in *EchoRequest, opts ...grpc.CallOption) (*EchoResponse, error)
 	ForwardEcho(ctx context.Context, in *ForwardEchoRequest, opts ...grpc.CallOption) (*ForwardEchoResponse, error)
+	// SetBehavior sets the behavior of the server for the requests that don't select one with headers.
+	SetBehavior(ctx context.Context, in *SetBehaviorRequest, opts ...grpc.CallOption) (*SetBehaviorResponse, error)
 }
 
 type echoTestServiceClient struct {
@@ -341,10 +508,35 @@ func (c *echoTestServiceClient) ForwardEcho(ctx context.Context, in *ForwardEcho
 	return out, nil
 }
 
+func (c *echoTestServiceClient) SetBehavior(ctx context.Context, in *SetBehaviorRequest, opts ...grpc.CallOption) (*SetBehaviorResponse, error) {
+	out := new(SetBehaviorResponse)
+	err := c.cc.Invoke(ctx, "/proto.EchoTestService/SetBehavior", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // EchoTestServiceServer is the server API for EchoTestService service.
 type EchoTestServiceServer interface {
 	Echo(context.Context, *EchoRequest) (*EchoResponse, error)
 	ForwardEcho(context.Context, *ForwardEchoRequest) (*ForwardEchoResponse, error)
+	// SetBehavior sets the behavior of the server for the requests that don't select one with headers.
+	SetBehavior(context.Context, *SetBehaviorRequest) (*SetBehaviorResponse, error)
+}
+
+// UnimplementedEchoTestServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedEchoTestServiceServer struct {
+}
+
+func (*UnimplementedEchoTestServiceServer) Echo(ctx context.Context, req *EchoRequest) (*EchoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Echo not implemented")
+}
+func (*UnimplementedEchoTestServiceServer) ForwardEcho(ctx context.Context, req *ForwardEchoRequest) (*ForwardEchoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ForwardEcho not implemented")
+}
+func (*UnimplementedEchoTestServiceServer) SetBehavior(ctx context.Context, req *SetBehaviorRequest) (*SetBehaviorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetBehavior not implemented")
 }
 
 func RegisterEchoTestServiceServer(s *grpc.Server, srv EchoTestServiceServer) {
@@ -387,6 +579,24 @@ func _EchoTestService_ForwardEcho_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EchoTestService_SetBehavior_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetBehaviorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EchoTestServiceServer).SetBehavior(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.EchoTestService/SetBehavior",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EchoTestServiceServer).SetBehavior(ctx, req.(*SetBehaviorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _EchoTestService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.EchoTestService",
 	HandlerType: (*EchoTestServiceServer)(nil),
@@ -399,6 +609,10 @@ var _EchoTestService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ForwardEcho",
 			Handler:    _EchoTestService_ForwardEcho_Handler,
 		},
+		{
+			MethodName: "SetBehavior",
+			Handler:    _EchoTestService_SetBehavior_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "echo.proto",
@@ -0,0 +1,106 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	"istio.io/istio/pkg/test/echo/common"
+	"istio.io/istio/pkg/test/echo/proto"
+)
+
+// Behavior holds the failure behavior shared by all the endpoints of a server, applied to the requests
+// that don't select one with headers. A nil Behavior responds immediately and successfully.
+type Behavior struct {
+	mu       sync.RWMutex
+	behavior *proto.Behavior
+}
+
+// Set the behavior of the server.
+func (b *Behavior) Set(behavior *proto.Behavior) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.behavior = behavior
+}
+
+// Get the behavior of the server.
+func (b *Behavior) Get() *proto.Behavior {
+	if b == nil {
+		return nil
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.behavior
+}
+
+// requestBehavior returns the behavior to apply to the request with the given headers, or nil if the
+// request must succeed immediately.
+func (c Config) requestBehavior(header func(name string) string) (*proto.Behavior, error) {
+	behavior, err := common.BehaviorFromHeaders(header)
+	if err != nil {
+		return nil, err
+	}
+	if behavior == nil {
+		behavior = c.Behavior.Get()
+	}
+	if !common.ShouldApplyBehavior(behavior) {
+		return nil, nil
+	}
+	return behavior, nil
+}
+
+// delay waits for the delay of the behavior, and returns false if the request is cancelled first.
+func delay(ctx context.Context, behavior *proto.Behavior) bool {
+	if behavior.DelayMicros <= 0 {
+		return true
+	}
+	t := time.NewTimer(common.MicrosToDuration(behavior.DelayMicros))
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// grpcCode returns the gRPC status code matching the HTTP status code, as defined by
+// https://github.com/grpc/grpc/blob/master/doc/http-grpc-status-mapping.md.
+func grpcCode(httpCode int32) codes.Code {
+	switch {
+	case httpCode < http.StatusBadRequest:
+		return codes.OK
+	case httpCode == http.StatusBadRequest:
+		return codes.Internal
+	case httpCode == http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case httpCode == http.StatusForbidden:
+		return codes.PermissionDenied
+	case httpCode == http.StatusNotFound:
+		return codes.Unimplemented
+	case httpCode == http.StatusTooManyRequests,
+		httpCode == http.StatusBadGateway,
+		httpCode == http.StatusServiceUnavailable,
+		httpCode == http.StatusGatewayTimeout:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"istio.io/istio/pkg/test/echo/common"
+	"istio.io/istio/pkg/test/echo/proto"
+)
+
+func newTestServer(behavior *Behavior) *httptest.Server {
+	return httptest.NewServer(&httpHandler{
+		Config: Config{
+			IsServerReady: func() bool { return true },
+			Behavior:      behavior,
+		},
+	})
+}
+
+func TestHTTPBehavior(t *testing.T) {
+	srv := newTestServer(&Behavior{})
+	defer srv.Close()
+
+	cases := []struct {
+		name       string
+		behavior   *proto.Behavior
+		wantCode   int
+		wantErr    bool
+		minLatency time.Duration
+	}{
+		{
+			name:     "none",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "status",
+			behavior: &proto.Behavior{StatusCode: http.StatusServiceUnavailable},
+			wantCode: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "delay",
+			behavior:   &proto.Behavior{DelayMicros: common.DurationToMicros(100 * time.Millisecond)},
+			wantCode:   http.StatusOK,
+			minLatency: 100 * time.Millisecond,
+		},
+		{
+			name:     "reset",
+			behavior: &proto.Behavior{ResetConnection: true},
+			wantErr:  true,
+		},
+		{
+			name:     "partial body",
+			behavior: &proto.Behavior{PartialBody: true},
+			wantCode: http.StatusOK,
+			wantErr:  true,
+		},
+		{
+			name:     "never applied",
+			behavior: &proto.Behavior{StatusCode: http.StatusInternalServerError, FailureRatio: 1e-12},
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", srv.URL, nil)
+			req.Header = common.BehaviorHeaders(c.behavior)
+
+			start := time.Now()
+			resp, err := srv.Client().Do(req)
+			if err == nil {
+				_, err = ioutil.ReadAll(resp.Body)
+				_ = resp.Body.Close()
+			}
+			if latency := time.Since(start); latency < c.minLatency {
+				t.Errorf("got latency %v, want at least %v", latency, c.minLatency)
+			}
+			if gotErr := err != nil; gotErr != c.wantErr {
+				t.Fatalf("got error %v, want error %v", err, c.wantErr)
+			}
+			if resp != nil && resp.StatusCode != c.wantCode {
+				t.Errorf("got status code %d, want %d", resp.StatusCode, c.wantCode)
+			}
+		})
+	}
+}
+
+func TestHTTPServerBehavior(t *testing.T) {
+	behavior := &Behavior{}
+	behavior.Set(&proto.Behavior{StatusCode: http.StatusInternalServerError})
+	srv := newTestServer(behavior)
+	defer srv.Close()
+
+	get := func(header http.Header) int {
+		t.Helper()
+		req, _ := http.NewRequest("GET", srv.URL, nil)
+		req.Header = header
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if got := get(nil); got != http.StatusInternalServerError {
+		t.Errorf("got status code %d for the server behavior, want %d", got, http.StatusInternalServerError)
+	}
+	if got := get(http.Header{"User-Agent": []string{"kube-probe/1.15"}}); got != http.StatusOK {
+		t.Errorf("got status code %d for a probe, want %d", got, http.StatusOK)
+	}
+	header := common.BehaviorHeaders(&proto.Behavior{StatusCode: http.StatusTooManyRequests})
+	if got := get(header); got != http.StatusTooManyRequests {
+		t.Errorf("got status code %d for the request behavior, want %d", got, http.StatusTooManyRequests)
+	}
+	if got := get(http.Header{common.StatusHeader: []string{"1000"}}); got != http.StatusBadRequest {
+		t.Errorf("got status code %d for an invalid behavior, want %d", got, http.StatusBadRequest)
+	}
+
+	behavior.Set(nil)
+	if got := get(nil); got != http.StatusOK {
+		t.Errorf("got status code %d after restoring the server behavior, want %d", got, http.StatusOK)
+	}
+}
+
+func TestGRPCBehavior(t *testing.T) {
+	h := &grpcHandler{Config: Config{Behavior: &Behavior{}}}
+
+	cases := []struct {
+		name     string
+		behavior *proto.Behavior
+		want     codes.Code
+	}{
+		{
+			name: "none",
+			want: codes.OK,
+		},
+		{
+			name:     "unavailable",
+			behavior: &proto.Behavior{StatusCode: http.StatusServiceUnavailable},
+			want:     codes.Unavailable,
+		},
+		{
+			name:     "forbidden",
+			behavior: &proto.Behavior{StatusCode: http.StatusForbidden},
+			want:     codes.PermissionDenied,
+		},
+		{
+			name:     "reset",
+			behavior: &proto.Behavior{ResetConnection: true},
+			want:     codes.Unavailable,
+		},
+		{
+			name:     "partial body",
+			behavior: &proto.Behavior{PartialBody: true},
+			want:     codes.OK,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			md := metadata.MD{}
+			for k, v := range common.BehaviorHeaders(c.behavior) {
+				md.Set(k, v...)
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+			_, err := h.Echo(ctx, &proto.EchoRequest{Message: "hello"})
+			if got := status.Code(err); got != c.want {
+				t.Errorf("got code %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	if _, err := h.SetBehavior(context.Background(), &proto.SetBehaviorRequest{
+		Behavior: &proto.Behavior{StatusCode: http.StatusNotFound},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Echo(context.Background(), &proto.EchoRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("got %v for the server behavior, want code %v", err, codes.Unimplemented)
+	}
+	if _, err := h.SetBehavior(context.Background(), &proto.SetBehaviorRequest{
+		Behavior: &proto.Behavior{FailureRatio: 2},
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got %v for an invalid behavior, want code %v", err, codes.InvalidArgument)
+	}
+}
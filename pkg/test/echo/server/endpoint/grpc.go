@@ -23,8 +23,10 @@ import (
 	"strconv"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"istio.io/istio/pkg/test/echo/common"
 	"istio.io/istio/pkg/test/echo/common/response"
@@ -125,6 +127,9 @@ func (h *grpcHandler) Echo(ctx context.Context, req *proto.EchoRequest) (*proto.
 	host := "-"
 	body := bytes.Buffer{}
 	md, ok := metadata.FromIncomingContext(ctx)
+	if err := h.applyBehavior(ctx, md); err != nil {
+		return nil, err
+	}
 	if ok {
 		for key, values := range md {
 			field := response.Field(key)
@@ -169,3 +174,41 @@ func (h *grpcHandler) ForwardEcho(ctx context.Context, req *proto.ForwardEchoReq
 
 	return instance.Run(ctx)
 }
+
+func (h *grpcHandler) SetBehavior(ctx context.Context, req *proto.SetBehaviorRequest) (*proto.SetBehaviorResponse, error) {
+	if err := common.ValidateBehavior(req.GetBehavior()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	log.Infof("Setting the server behavior: %v", req.GetBehavior())
+	h.Behavior.Set(req.GetBehavior())
+	return &proto.SetBehaviorResponse{}, nil
+}
+
+// applyBehavior applies the failure behavior selected for the request, and returns the error the
+// request must fail with, if any.
+func (h *grpcHandler) applyBehavior(ctx context.Context, md metadata.MD) error {
+	behavior, err := h.requestBehavior(func(name string) string {
+		if values := md.Get(name); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if behavior == nil {
+		return nil
+	}
+
+	log.Infof("GRPC Request behavior: %v", behavior)
+	if !delay(ctx, behavior) {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	if behavior.ResetConnection {
+		return status.Error(codes.Unavailable, "connection reset by the echo server")
+	}
+	if code := grpcCode(behavior.StatusCode); code != codes.OK {
+		return status.Errorf(code, "echo server responded with HTTP status code %d", behavior.StatusCode)
+	}
+	return nil
+}
@@ -30,6 +30,7 @@ import (
 
 	"istio.io/istio/pkg/test/echo/common"
 	"istio.io/istio/pkg/test/echo/common/response"
+	"istio.io/istio/pkg/test/echo/proto"
 	"istio.io/istio/pkg/test/util/retry"
 	"istio.io/pkg/log"
 )
@@ -191,6 +192,30 @@ func writeError(out *bytes.Buffer, msg string) {
 }
 
 func (h *httpHandler) echo(w http.ResponseWriter, r *http.Request) {
+	// If the request selects a failure behavior with headers, or the server has one, apply it. Kubernetes
+	// probes are exempt, so that failing instances stay in the endpoints of the service.
+	var behavior *proto.Behavior
+	var err error
+	if !strings.HasPrefix(r.UserAgent(), "kube-probe/") {
+		behavior, err = h.requestBehavior(r.Header.Get)
+	}
+	if err != nil {
+		log.Warn("behavior error: " + err.Error())
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("behavior error: " + err.Error() + "\n"))
+		return
+	}
+	if behavior != nil {
+		log.Infof("HTTP Request behavior: %v", behavior)
+		if !delay(r.Context(), behavior) {
+			return
+		}
+		if behavior.ResetConnection {
+			resetConnection(w)
+			return
+		}
+	}
+
 	body := bytes.Buffer{}
 
 	if err := r.ParseForm(); err != nil {
@@ -205,19 +230,54 @@ func (h *httpHandler) echo(w http.ResponseWriter, r *http.Request) {
 	// If the request has form ?codes=code[:chance][,code[:chance]]* return those codes, rather than 200
 	// For example, ?codes=500:1,200:1 returns 500 1/2 times and 200 1/2 times
 	// For example, ?codes=500:90,200:10 returns 500 90% of times and 200 10% of times
-	if err := setResponseFromCodes(r, w); err != nil {
+	// The status code of the behavior, if any, takes precedence.
+	if behavior != nil && behavior.StatusCode != 0 {
+		log.Infof("Response status code: %d", behavior.StatusCode)
+		w.WriteHeader(int(behavior.StatusCode))
+	} else if err := setResponseFromCodes(r, w); err != nil {
 		writeError(&body, "codes error: "+err.Error())
 	}
 
 	h.addResponsePayload(r, &body)
 
 	w.Header().Set("Content-Type", "application/text")
+	if behavior != nil && behavior.PartialBody {
+		writePartialBody(w, body.Bytes())
+		return
+	}
 	if _, err := w.Write(body.Bytes()); err != nil {
 		log.Warna(err)
 	}
 	log.Infof("Response Headers: %+v", w.Header())
 }
 
+// resetConnection closes the connection of the request without responding.
+func resetConnection(w http.ResponseWriter) {
+	if hj, ok := w.(http.Hijacker); ok {
+		if conn, _, err := hj.Hijack(); err == nil {
+			if tcp, ok := conn.(*net.TCPConn); ok {
+				// Send a RST rather than a FIN.
+				_ = tcp.SetLinger(0)
+			}
+			_ = conn.Close()
+			return
+		}
+	}
+	// HTTP/2 connections can't be hijacked, reset the stream instead.
+	panic(http.ErrAbortHandler)
+}
+
+// writePartialBody writes the first half of the body, then aborts the response.
+func writePartialBody(w http.ResponseWriter, body []byte) {
+	if _, err := w.Write(body[:len(body)/2]); err != nil {
+		log.Warna(err)
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	panic(http.ErrAbortHandler)
+}
+
 func (h *httpHandler) webSocketEcho(w http.ResponseWriter, r *http.Request) {
 	// adapted from https://github.com/gorilla/websocket/blob/master/examples/echo/server.go
 	// First send upgrade headers
@@ -38,6 +38,7 @@ type Config struct {
 	UDSServer     string
 	Dialer        common.Dialer
 	Port          *model.Port
+	Behavior      *Behavior
 }
 
 // Instance of an endpoint that serves the Echo application on a single port/protocol.
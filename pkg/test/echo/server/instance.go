@@ -46,6 +46,7 @@ type Instance struct {
 	Config
 
 	endpoints []endpoint.Instance
+	behavior  *endpoint.Behavior
 	ready     uint32
 }
 
@@ -54,7 +55,8 @@ func New(config Config) *Instance {
 	config.Dialer = config.Dialer.FillInDefaults()
 
 	return &Instance{
-		Config: config,
+		Config:   config,
+		behavior: &endpoint.Behavior{},
 	}
 }
 
@@ -109,6 +111,7 @@ func (s *Instance) newEndpoint(port *model.Port, udsServer string) (endpoint.Ins
 		TLSCert:       s.TLSCert,
 		TLSKey:        s.TLSKey,
 		Dialer:        s.Dialer,
+		Behavior:      s.behavior,
 	})
 }
 
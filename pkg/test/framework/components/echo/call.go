@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"istio.io/istio/pkg/test/echo/common/scheme"
+	"istio.io/istio/pkg/test/echo/proto"
 )
 
 // CallOptions defines options for calling a Endpoint.
@@ -55,4 +56,9 @@ type CallOptions struct {
 
 	// Message to be sent if this is a GRPC request
 	Message string
+
+	// Behavior of the Target for this call, such as a delay or a failure status code. It is sent as
+	// request headers, and takes precedence over the behavior set on the Target workloads. If not
+	// provided, the Target workloads apply their own behavior.
+	Behavior *proto.Behavior
 }
@@ -66,6 +66,10 @@ func CallEcho(c *client.Instance, opts *echo.CallOptions, outboundPortSelector O
 	for k := range opts.Headers {
 		protoHeaders = append(protoHeaders, &proto.Header{Key: k, Value: opts.Headers.Get(k)})
 	}
+	// Add the headers that select the behavior of the target.
+	for k, v := range common.BehaviorHeaders(opts.Behavior) {
+		protoHeaders = append(protoHeaders, &proto.Header{Key: k, Value: v[0]})
+	}
 
 	req := &proto.ForwardEchoRequest{
 		Url:           targetURL,
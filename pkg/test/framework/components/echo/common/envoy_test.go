@@ -186,6 +186,10 @@ func (*testConfig) ForwardEcho(context.Context, *proto.ForwardEchoRequest) (clie
 	panic("not implemented")
 }
 
+func (*testConfig) SetBehavior(context.Context, *proto.Behavior) error {
+	panic("not implemented")
+}
+
 type fakeNamespace struct {
 	name string
 }
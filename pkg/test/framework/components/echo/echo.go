@@ -108,6 +108,10 @@ type Workload interface {
 	// ForwardEcho executes specific call from this workload.
 	ForwardEcho(context.Context, *proto.ForwardEchoRequest) (client.ParsedResponses, error)
 
+	// SetBehavior sets the failure behavior of this workload, for the requests that don't select one with
+	// headers. A nil behavior restores the default of responding immediately and successfully.
+	SetBehavior(context.Context, *proto.Behavior) error
+
 	// Logs returns the logs for the app container
 	Logs() (string, error)
 	// LogsOrFail returns the logs for the app container, or aborts if an error is found
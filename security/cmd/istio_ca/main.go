@@ -29,6 +29,7 @@ import (
 
 	pkgcmd "istio.io/istio/pkg/cmd"
 	kubelib "istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/events"
 	"istio.io/istio/pkg/spiffe"
 	"istio.io/istio/security/pkg/caclient"
 	"istio.io/istio/security/pkg/cmd"
@@ -123,6 +124,12 @@ type cliOptions struct { // nolint: maligned
 
 	// Whether SDS is enabled on.
 	sdsEnabled bool
+
+	// The policy granting identities to AWS and GCP instances authenticated by their platform credentials.
+	instanceIdentityPolicyFile string
+
+	// The time after their launch within which AWS instances must first authenticate.
+	instanceIdentityMaxAge time.Duration
 }
 
 var (
@@ -275,6 +282,12 @@ func initCLI() {
 		false, "Enable dual-use mode. Generates certificates with a CommonName identical to the SAN.")
 
 	flags.BoolVar(&opts.sdsEnabled, "sds-enabled", false, "Whether SDS is enabled.")
+	flags.StringVar(&opts.instanceIdentityPolicyFile, "instance-identity-policy", "",
+		"The YAML file mapping the attributes of AWS and GCP instances to the identities they are issued. "+
+			"If set, node agents on these instances authenticate with their platform credentials.")
+	flags.DurationVar(&opts.instanceIdentityMaxAge, "instance-identity-max-age", 15*time.Minute,
+		"The time after their launch within which AWS instances must first authenticate. Later, only the node "+
+			"agent which authenticated first is accepted.")
 
 	rootCmd.AddCommand(version.CobraCommand())
 
@@ -347,11 +360,13 @@ func runCA() {
 	stopCh := make(chan struct{})
 	if !opts.serverOnly {
 		log.Infof("Creating Kubernetes controller to write issued keys and certs into secret ...")
+		recorder := events.NewRecorder(cs.CoreV1(), "citadel")
+		defer recorder.Shutdown()
 		// For workloads in K8s, we apply the configured workload cert TTL.
 		sc, err := controller.NewSecretController(ca, opts.enableNamespacesByDefault,
 			opts.workloadCertTTL, opts.workloadCertGracePeriodRatio, opts.workloadCertMinGracePeriod,
 			opts.dualUse, cs.CoreV1(), opts.signCACerts, opts.pkcs8Keys, listenedNamespaces, webhooks,
-			opts.istioCaStorageNamespace, opts.rootCertFile, opts.selfSignedCA)
+			opts.istioCaStorageNamespace, opts.rootCertFile, opts.selfSignedCA, recorder)
 		if err != nil {
 			fatalf("Failed to create secret controller: %v", err)
 		}
@@ -392,6 +407,12 @@ func runCA() {
 		if startErr != nil {
 			fatalf("Failed to create istio ca server: %v", startErr)
 		}
+		if opts.instanceIdentityPolicyFile != "" {
+			if err := caServer.AddInstanceIdentityAuthenticators(opts.instanceIdentityPolicyFile,
+				opts.instanceIdentityMaxAge, cs.CoreV1(), opts.istioCaStorageNamespace); err != nil {
+				fatalf("Failed to add instance identity authenticators: %v", err)
+			}
+		}
 		if serverErr := caServer.Run(); serverErr != nil {
 			// stop the registry-related controllers
 			ch <- struct{}{}
@@ -33,7 +33,8 @@ type GcpTokenFetcher struct {
 
 func (fetcher *GcpTokenFetcher) getTokenURI() string {
 	// The GCE metadata service URI to get identity token of current (i.e., default) service account.
-	return "instance/service-accounts/default/identity?audience=" + fetcher.Aud
+	// The full format adds the instance attributes, such as the project and zone, to the token.
+	return "instance/service-accounts/default/identity?audience=" + fetcher.Aud + "&format=full"
 }
 
 // FetchToken fetches the GCE VM identity jwt token from its metadata server.
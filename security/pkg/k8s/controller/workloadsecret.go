@@ -29,6 +29,7 @@ import (
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
 
+	"istio.io/istio/pkg/kube/events"
 	"istio.io/istio/pkg/spiffe"
 	k8ssecret "istio.io/istio/security/pkg/k8s/secret"
 	"istio.io/istio/security/pkg/listwatch"
@@ -167,6 +168,9 @@ type SecretController struct {
 	// If true, periodically sync with istio-ca-secret to load latest root certificate.
 	// Only used in self signed CA mode.
 	syncWithSelfSignedCaSecret bool
+
+	// Emits the failures to issue or refresh the certificates as events. Not emitted if nil.
+	recorder *events.Recorder
 }
 
 // NewSecretController returns a pointer to a newly constructed SecretController instance.
@@ -174,7 +178,7 @@ func NewSecretController(ca certificateAuthority, enableNamespacesByDefault bool
 	certTTL time.Duration, gracePeriodRatio float32, minGracePeriod time.Duration,
 	dualUse bool, core corev1.CoreV1Interface, forCA bool, pkcs8Key bool, namespaces []string,
 	dnsNames map[string]*DNSNameEntry, istioCaStorageNamespace, rootCertFile string,
-	selfSignedCa bool) (*SecretController, error) {
+	selfSignedCa bool, recorder *events.Recorder) (*SecretController, error) {
 
 	if gracePeriodRatio < 0 || gracePeriodRatio > 1 {
 		return nil, fmt.Errorf("grace period ratio %f should be within [0, 1]", gracePeriodRatio)
@@ -203,6 +207,7 @@ func NewSecretController(ca certificateAuthority, enableNamespacesByDefault bool
 		monitoring:                 newMonitoringMetrics(),
 		lastKCBSyncTime:            time.Time{},
 		syncWithSelfSignedCaSecret: selfSignedCa,
+		recorder:                   recorder,
 	}
 
 	for _, ns := range namespaces {
@@ -333,6 +338,8 @@ func (sc *SecretController) upsertSecret(saName, saNamespace string) {
 	if err != nil {
 		k8sControllerLog.Errorf("Failed to generate key/cert for %s/%s (error %v)",
 			saNamespace, GetSecretName(saName), err)
+		sc.recorder.Eventf(events.ObjectReference("v1", "ServiceAccount", saNamespace, saName), events.Warning,
+			"CertificateIssuanceFailed", "Failed to issue the certificate of the service account: %v", err)
 		return
 	}
 	rootCert := sc.ca.GetCAKeyCertBundle().GetRootCertPem()
@@ -357,6 +364,8 @@ func (sc *SecretController) upsertSecret(saName, saNamespace string) {
 			saNamespace, GetSecretName(saName), i+1, secretCreationRetry, err)
 		time.Sleep(time.Second)
 	}
+	sc.recorder.Eventf(events.ObjectReference("v1", "ServiceAccount", saNamespace, saName), events.Warning,
+		"CertificateIssuanceFailed", "Failed to create the secret %s of the service account: %v", GetSecretName(saName), err)
 }
 
 func (sc *SecretController) deleteSecret(saName, saNamespace string) {
@@ -534,6 +543,9 @@ func (sc *SecretController) scrtUpdated(oldObj, newObj interface{}) {
 
 		if err = sc.refreshSecret(scrt); err != nil {
 			k8sControllerLog.Errorf("Failed to refresh secret %s/%s (error: %s)", namespace, GetSecretName(name), err)
+			ref := events.ObjectReference("v1", "Secret", namespace, name)
+			ref.UID = scrt.GetUID()
+			sc.recorder.Eventf(ref, events.Warning, "CertificateRefreshFailed", "Failed to refresh the certificate: %v", err)
 		} else {
 			k8sControllerLog.Infof("Secret %s/%s refreshed successfully.", namespace, GetSecretName(name))
 		}
@@ -26,12 +26,13 @@ import (
 	"strings"
 
 	k8sauth "k8s.io/api/authentication/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 const (
-	// The default audience for SDS trustworthy JWT. This is to make sure that the CSR requests
+	// DefaultAudience is the default audience for SDS trustworthy JWT. This is to make sure that the CSR requests
 	// contain the JWTs intended for Citadel.
-	defaultAudience = "istio-ca"
+	DefaultAudience = "istio-ca"
 )
 
 type specForSaValidationRequest struct {
@@ -92,7 +93,7 @@ func (authn *K8sSvcAcctAuthn) reviewServiceAccountAtK8sAPIServer(targetToken str
 			// If the audiences are not specified, the api server will use the audience of api server,
 			// which is also the issuer of the jwt.
 			// This feature is only available on Kubernetes v1.13 and above.
-			Audiences: []string{defaultAudience},
+			Audiences: []string{DefaultAudience},
 		},
 	}
 	saReqJSON, err := json.Marshal(saReq)
@@ -146,6 +147,36 @@ func (authn *K8sSvcAcctAuthn) ValidateK8sJwt(targetToken string) ([]string, erro
 	if err != nil {
 		return nil, fmt.Errorf("unmarshal response body returns an error: %v", err)
 	}
+	return getTokenReviewResult(tokenReview)
+}
+
+// ValidateK8sJwtWithClient validates a k8s JWT with the TokenReview API of the cluster of the client.
+// Return {<namespace>, <serviceaccountname>} in the targetToken when the validation passes.
+// Otherwise, return the error.
+// targetToken: the JWT of the K8s service account to be reviewed
+func ValidateK8sJwtWithClient(client kubernetes.Interface, targetToken string) ([]string, error) {
+	isTrustworthyJwt, err := isTrustworthyJwt(targetToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if jwt is trustworthy: %v", err)
+	}
+	if !isTrustworthyJwt {
+		return nil, fmt.Errorf("legacy JWTs are not allowed and the provided jwt is not trustworthy")
+	}
+
+	tokenReview, err := client.AuthenticationV1().TokenReviews().Create(&k8sauth.TokenReview{
+		Spec: k8sauth.TokenReviewSpec{
+			Token:     targetToken,
+			Audiences: []string{DefaultAudience},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a token review response: %v", err)
+	}
+	return getTokenReviewResult(tokenReview)
+}
+
+// getTokenReviewResult returns {<namespace>, <serviceaccountname>} of an authenticated service account.
+func getTokenReviewResult(tokenReview *k8sauth.TokenReview) ([]string, error) {
 	if tokenReview.Status.Error != "" {
 		return nil, fmt.Errorf("the service account authentication returns an error: %v", tokenReview.Status.Error)
 	}
@@ -25,6 +25,9 @@ import (
 	"testing"
 
 	k8sauth "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
 )
 
 var (
@@ -237,6 +240,71 @@ func isJwtDecodable(jwt string) bool {
 	return true
 }
 
+func TestValidateK8sJwtWithClient(t *testing.T) {
+	testCases := map[string]struct {
+		jwt         string
+		status      k8sauth.TokenReviewStatus
+		expectedIDs []string
+		expectedErr string
+	}{
+		"Authenticated service account": {
+			jwt: getJwtFromFile("testdata/trustworthy-jwt.jwt", t),
+			status: k8sauth.TokenReviewStatus{
+				Authenticated: true,
+				User: k8sauth.UserInfo{
+					Username: "system:serviceaccount:default:example-pod-sa",
+					Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:default", "system:authenticated"},
+				},
+			},
+			expectedIDs: []string{"default", "example-pod-sa"},
+		},
+		"Legacy jwt": {
+			jwt:         getJwtFromFile("testdata/legacy-jwt.jwt", t),
+			expectedErr: "legacy JWTs are not allowed and the provided jwt is not trustworthy",
+		},
+		"Not authenticated": {
+			jwt:         getJwtFromFile("testdata/trustworthy-jwt.jwt", t),
+			status:      k8sauth.TokenReviewStatus{Authenticated: false},
+			expectedErr: "the token is not authenticated",
+		},
+		"Not a service account": {
+			jwt: getJwtFromFile("testdata/trustworthy-jwt.jwt", t),
+			status: k8sauth.TokenReviewStatus{
+				Authenticated: true,
+				User:          k8sauth.UserInfo{Username: "admin", Groups: []string{"system:authenticated"}},
+			},
+			expectedErr: "the token is not a service account",
+		},
+	}
+
+	for id, tc := range testCases {
+		client := fake.NewSimpleClientset()
+		var reviewed *k8sauth.TokenReview
+		client.PrependReactor("create", "tokenreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+			reviewed = action.(ktesting.CreateAction).GetObject().(*k8sauth.TokenReview)
+			return true, &k8sauth.TokenReview{Status: tc.status}, nil
+		})
+
+		ids, err := ValidateK8sJwtWithClient(client, tc.jwt)
+		if tc.expectedErr != "" {
+			if err == nil || err.Error() != tc.expectedErr {
+				t.Errorf("Test case [%s]: want error %q but got %v", id, tc.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test case [%s]: unexpected error: %v", id, err)
+			continue
+		}
+		if len(ids) != 2 || ids[0] != tc.expectedIDs[0] || ids[1] != tc.expectedIDs[1] {
+			t.Errorf("Test case [%s]: want %v but got %v", id, tc.expectedIDs, ids)
+		}
+		if reviewed.Spec.Token != tc.jwt || len(reviewed.Spec.Audiences) != 1 || reviewed.Spec.Audiences[0] != DefaultAudience {
+			t.Errorf("Test case [%s]: unexpected token review %v", id, reviewed.Spec)
+		}
+	}
+}
+
 func TestIsTrustworthyJwt(t *testing.T) {
 	testCases := []struct {
 		Name           string
@@ -0,0 +1,347 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"istio.io/pkg/filewatcher"
+
+	"istio.io/istio/pkg/spiffe"
+	"istio.io/istio/security/pkg/nodeagent/model"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+// fileDebounceDelay is how long to wait after a change of the files before reloading them, as the
+// key and the certificates are usually updated together.
+const fileDebounceDelay = 100 * time.Millisecond
+
+// FileSecretOptions configures a FileSecretManager.
+type FileSecretOptions struct {
+	// CertChainPath, KeyPath and RootCertPath are the PEM files of the workload certificate
+	// chain, of its private key, and of the root certificates.
+	CertChainPath string
+	KeyPath       string
+	RootCertPath  string
+
+	// ExpectedIdentity is the SPIFFE ID that the workload certificate must have. Any SPIFFE ID
+	// is accepted if empty.
+	ExpectedIdentity string
+}
+
+// FileSecretManager is a SecretManager serving the workload key and certificates from files, such
+// as the ones mounted from secrets managed by cert-manager, instead of requesting them from a CA.
+// The files are watched, and the proxies are pushed the new certificates once they are rotated.
+// Files that aren't valid, such as a certificate not matching the key, are ignored and the
+// previous certificates are served until the next change.
+type FileSecretManager struct {
+	options  FileSecretOptions
+	notifyCb func(ConnKey, *model.SecretItem) error
+	watcher  filewatcher.FileWatcher
+	stop     chan struct{}
+
+	mu       sync.RWMutex
+	keyCert  *model.SecretItem
+	rootCert *model.SecretItem
+	// conns are the tokens of the connections that requested a secret.
+	conns map[ConnKey]string
+}
+
+var _ SecretManager = &FileSecretManager{}
+
+// NewFileSecretManager loads the files of the options, and watches them for changes with the
+// watcher. The connections are notified of the changes with notifyCb.
+func NewFileSecretManager(options FileSecretOptions, notifyCb func(ConnKey, *model.SecretItem) error,
+	watcher filewatcher.FileWatcher) (*FileSecretManager, error) {
+	m := &FileSecretManager{
+		options:  options,
+		notifyCb: notifyCb,
+		watcher:  watcher,
+		stop:     make(chan struct{}),
+		conns:    make(map[ConnKey]string),
+	}
+	keyCert, rootCert, err := loadFileSecrets(options, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	m.keyCert, m.rootCert = keyCert, rootCert
+	recordFileSecretExpiry(keyCert, rootCert)
+	cacheLog.Infof("Loaded workload certificate from %s, valid until %v", options.CertChainPath, keyCert.ExpireTime)
+
+	for _, path := range []string{options.CertChainPath, options.KeyPath, options.RootCertPath} {
+		if err := watcher.Add(path); err != nil {
+			m.Close()
+			return nil, fmt.Errorf("failed to watch %s: %v", path, err)
+		}
+		go m.watch(path)
+	}
+	return m, nil
+}
+
+// Close stops watching the files.
+func (m *FileSecretManager) Close() {
+	close(m.stop)
+	_ = m.watcher.Close()
+}
+
+func (m *FileSecretManager) watch(path string) {
+	var timerC <-chan time.Time
+	for {
+		select {
+		case <-timerC:
+			timerC = nil
+			m.reload(time.Now())
+		case <-m.watcher.Events(path):
+			// Use a timer to debounce the updates
+			if timerC == nil {
+				timerC = time.After(fileDebounceDelay)
+			}
+		case err := <-m.watcher.Errors(path):
+			cacheLog.Warnf("Error watching %s: %v", path, err)
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// reload loads the files again, and pushes the secrets that changed to the connections.
+func (m *FileSecretManager) reload(now time.Time) {
+	keyCert, rootCert, err := loadFileSecrets(m.options, now)
+	if err != nil {
+		cacheLog.Errorf("Failed to reload the workload certificates, keeping the previous ones: %v", err)
+		numFileSecretReloadFailures.Increment()
+		return
+	}
+	recordFileSecretExpiry(keyCert, rootCert)
+
+	m.mu.Lock()
+	changed := map[string]*model.SecretItem{}
+	if keyCert.Version != m.keyCert.Version {
+		m.keyCert = keyCert
+		changed[WorkloadKeyCertResourceName] = keyCert
+		cacheLog.Infof("Reloaded workload certificate from %s, valid until %v", m.options.CertChainPath, keyCert.ExpireTime)
+	}
+	if rootCert.Version != m.rootCert.Version {
+		m.rootCert = rootCert
+		changed[RootCertReqResourceName] = rootCert
+		cacheLog.Infof("Reloaded root certificate from %s", m.options.RootCertPath)
+	}
+	notify := map[ConnKey]*model.SecretItem{}
+	for connKey, token := range m.conns {
+		if item := changed[connKey.ResourceName]; item != nil {
+			notify[connKey] = withToken(item, token)
+		}
+	}
+	m.mu.Unlock()
+
+	for connKey, item := range notify {
+		if err := m.notifyCb(connKey, item); err != nil {
+			cacheLog.Errorf("%s failed to notify secret change for proxy: %v",
+				cacheLogPrefix(connKey.ConnectionID, connKey.ResourceName), err)
+		}
+	}
+}
+
+// GenerateSecret returns the workload key and certificate chain, or the root certificate,
+// depending on the resource name.
+func (m *FileSecretManager) GenerateSecret(_ context.Context, connectionID, resourceName, token string) (*model.SecretItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var item *model.SecretItem
+	switch resourceName {
+	case WorkloadKeyCertResourceName:
+		item = m.keyCert
+	case RootCertReqResourceName:
+		item = m.rootCert
+	default:
+		return nil, fmt.Errorf("unknown resource name %q, only %q and %q are served from files",
+			resourceName, WorkloadKeyCertResourceName, RootCertReqResourceName)
+	}
+	m.conns[ConnKey{ConnectionID: connectionID, ResourceName: resourceName}] = token
+	return withToken(item, token), nil
+}
+
+// ShouldWaitForIngressGatewaySecret always returns false, as the secrets are ready once loaded.
+func (m *FileSecretManager) ShouldWaitForIngressGatewaySecret(string, string, string) bool {
+	return false
+}
+
+// SecretExist checks if the secret was already sent to the connection.
+func (m *FileSecretManager) SecretExist(connectionID, resourceName, token, version string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	connKey := ConnKey{ConnectionID: connectionID, ResourceName: resourceName}
+	t, ok := m.conns[connKey]
+	if !ok || t != token {
+		return false
+	}
+	switch resourceName {
+	case WorkloadKeyCertResourceName:
+		return m.keyCert.Version == version
+	case RootCertReqResourceName:
+		return m.rootCert.Version == version
+	default:
+		return false
+	}
+}
+
+// DeleteSecret forgets the connection.
+func (m *FileSecretManager) DeleteSecret(connectionID, resourceName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.conns, ConnKey{ConnectionID: connectionID, ResourceName: resourceName})
+}
+
+func withToken(item *model.SecretItem, token string) *model.SecretItem {
+	ret := *item
+	ret.Token = token
+	return &ret
+}
+
+// loadFileSecrets reads the files of the options, and verifies that the certificate chain matches
+// the key, is valid at the given time up to the roots, and has the expected SPIFFE ID.
+func loadFileSecrets(options FileSecretOptions, now time.Time) (keyCert, rootCert *model.SecretItem, err error) {
+	certChainPEM, err := ioutil.ReadFile(options.CertChainPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err := ioutil.ReadFile(options.KeyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	rootCertPEM, err := ioutil.ReadFile(options.RootCertPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pair, err := tls.X509KeyPair(certChainPEM, keyPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid key and certificate chain: %v", err)
+	}
+	chain := make([]*x509.Certificate, 0, len(pair.Certificate))
+	for _, der := range pair.Certificate {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid certificate chain: %v", err)
+		}
+		chain = append(chain, cert)
+	}
+	leaf := chain[0]
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(rootCertPEM) {
+		return nil, nil, fmt.Errorf("no valid root certificate in %s", options.RootCertPath)
+	}
+	rootExpireTime, err := earliestExpiry(rootCertPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return nil, nil, fmt.Errorf("invalid certificate chain: %v", err)
+	}
+	if err := verifySpiffeID(leaf, options.ExpectedIdentity); err != nil {
+		return nil, nil, err
+	}
+
+	keyCert = &model.SecretItem{
+		CertificateChain: certChainPEM,
+		PrivateKey:       keyPEM,
+		ResourceName:     WorkloadKeyCertResourceName,
+		CreatedTime:      now,
+		ExpireTime:       leaf.NotAfter,
+		Version:          contentVersion(certChainPEM, keyPEM),
+	}
+	rootCert = &model.SecretItem{
+		RootCert:     rootCertPEM,
+		ResourceName: RootCertReqResourceName,
+		CreatedTime:  now,
+		ExpireTime:   rootExpireTime,
+		Version:      contentVersion(rootCertPEM),
+	}
+	return keyCert, rootCert, nil
+}
+
+// verifySpiffeID checks that the certificate has the expected SPIFFE ID, or any SPIFFE ID if
+// expected is empty.
+func verifySpiffeID(cert *x509.Certificate, expected string) error {
+	ids, err := util.ExtractIDs(cert.Extensions)
+	if err != nil {
+		return fmt.Errorf("invalid SAN of the workload certificate: %v", err)
+	}
+	for _, id := range ids {
+		if !strings.HasPrefix(id, spiffe.URIPrefix) {
+			continue
+		}
+		if expected == "" || id == expected {
+			return nil
+		}
+	}
+	if expected == "" {
+		return fmt.Errorf("the workload certificate has no SPIFFE ID in %v", ids)
+	}
+	return fmt.Errorf("the workload certificate identities %v don't include %s", ids, expected)
+}
+
+// earliestExpiry returns the earliest expiration time of the PEM encoded certificates.
+func earliestExpiry(certsPEM []byte) (time.Time, error) {
+	var earliest time.Time
+	for block, rest := pem.Decode(certsPEM); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid root certificate: %v", err)
+		}
+		if earliest.IsZero() || c.NotAfter.Before(earliest) {
+			earliest = c.NotAfter
+		}
+	}
+	return earliest, nil
+}
+
+// contentVersion returns the version of the secret with the given content, so that loading the
+// same files again doesn't push the secrets.
+func contentVersion(contents ...[]byte) string {
+	h := sha256.New()
+	for _, c := range contents {
+		_, _ = h.Write(c)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func recordFileSecretExpiry(keyCert, rootCert *model.SecretItem) {
+	fileSecretExpiry.With(ResourceName.Value(WorkloadKeyCertResourceName)).Record(float64(keyCert.ExpireTime.Unix()))
+	fileSecretExpiry.With(ResourceName.Value(RootCertReqResourceName)).Record(float64(rootCert.ExpireTime.Unix()))
+}
@@ -0,0 +1,360 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"istio.io/pkg/filewatcher"
+
+	"istio.io/istio/security/pkg/nodeagent/model"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+const testSpiffeID = "spiffe://cluster.local/ns/default/sa/foo"
+
+type testCA struct {
+	certPEM []byte
+	keyPEM  []byte
+	cert    *x509.Certificate
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	certPEM, keyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		Org:          "Istio Test",
+		NotBefore:    time.Now().Add(-time.Hour),
+		TTL:          24 * time.Hour,
+		IsCA:         true,
+		IsSelfSigned: true,
+		RSAKeySize:   2048,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := util.ParsePemEncodedCertificate(certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{certPEM: certPEM, keyPEM: keyPEM, cert: cert}
+}
+
+// issue returns a workload certificate and key for the SAN, valid from notBefore for ttl.
+func (ca *testCA) issue(t *testing.T, san string, notBefore time.Time, ttl time.Duration) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := util.ParsePemEncodedKey(ca.keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, keyPEM, err = util.GenCertKeyFromOptions(util.CertOptions{
+		Host:       san,
+		NotBefore:  notBefore,
+		TTL:        ttl,
+		SignerCert: ca.cert,
+		SignerPriv: key,
+		IsClient:   true,
+		IsServer:   true,
+		RSAKeySize: 2048,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certPEM, keyPEM
+}
+
+type fileSecretTest struct {
+	dir     string
+	options FileSecretOptions
+}
+
+func newFileSecretTest(t *testing.T) *fileSecretTest {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "filesecret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &fileSecretTest{
+		dir: dir,
+		options: FileSecretOptions{
+			CertChainPath:    filepath.Join(dir, "cert-chain.pem"),
+			KeyPath:          filepath.Join(dir, "key.pem"),
+			RootCertPath:     filepath.Join(dir, "root-cert.pem"),
+			ExpectedIdentity: testSpiffeID,
+		},
+	}
+}
+
+func (f *fileSecretTest) cleanup() {
+	_ = os.RemoveAll(f.dir)
+}
+
+func (f *fileSecretTest) write(t *testing.T, certPEM, keyPEM, rootPEM []byte) {
+	t.Helper()
+	for path, content := range map[string][]byte{
+		f.options.CertChainPath: certPEM,
+		f.options.KeyPath:       keyPEM,
+		f.options.RootCertPath:  rootPEM,
+	} {
+		if err := ioutil.WriteFile(path, content, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+type notifications struct {
+	mu    sync.Mutex
+	items map[ConnKey]*model.SecretItem
+}
+
+func (n *notifications) notify(connKey ConnKey, item *model.SecretItem) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.items[connKey] = item
+	return nil
+}
+
+func (n *notifications) get(connKey ConnKey) *model.SecretItem {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.items[connKey]
+}
+
+func TestLoadFileSecrets(t *testing.T) {
+	ca := newTestCA(t)
+	otherCA := newTestCA(t)
+	now := time.Now()
+	certPEM, keyPEM := ca.issue(t, testSpiffeID, now.Add(-time.Minute), time.Hour)
+	_, otherKeyPEM := ca.issue(t, testSpiffeID, now.Add(-time.Minute), time.Hour)
+	expiredPEM, expiredKeyPEM := ca.issue(t, testSpiffeID, now.Add(-2*time.Hour), time.Hour)
+	otherIDPEM, otherIDKeyPEM := ca.issue(t, "spiffe://cluster.local/ns/default/sa/bar", now.Add(-time.Minute), time.Hour)
+	dnsPEM, dnsKeyPEM := ca.issue(t, "foo.default.svc", now.Add(-time.Minute), time.Hour)
+
+	cases := []struct {
+		name     string
+		cert     []byte
+		key      []byte
+		root     []byte
+		expected string
+		wantErr  string
+	}{
+		{
+			name: "valid",
+			cert: certPEM, key: keyPEM, root: ca.certPEM,
+			expected: testSpiffeID,
+		},
+		{
+			name: "any spiffe id",
+			cert: otherIDPEM, key: otherIDKeyPEM, root: ca.certPEM,
+		},
+		{
+			name: "several roots",
+			cert: certPEM, key: keyPEM, root: append(append([]byte{}, otherCA.certPEM...), ca.certPEM...),
+			expected: testSpiffeID,
+		},
+		{
+			name: "key mismatch",
+			cert: certPEM, key: otherKeyPEM, root: ca.certPEM,
+			wantErr: "invalid key and certificate chain",
+		},
+		{
+			name: "untrusted",
+			cert: certPEM, key: keyPEM, root: otherCA.certPEM,
+			wantErr: "invalid certificate chain",
+		},
+		{
+			name: "expired",
+			cert: expiredPEM, key: expiredKeyPEM, root: ca.certPEM,
+			wantErr: "invalid certificate chain",
+		},
+		{
+			name: "unexpected spiffe id",
+			cert: otherIDPEM, key: otherIDKeyPEM, root: ca.certPEM,
+			expected: testSpiffeID,
+			wantErr:  "don't include",
+		},
+		{
+			name: "no spiffe id",
+			cert: dnsPEM, key: dnsKeyPEM, root: ca.certPEM,
+			wantErr: "no SPIFFE ID",
+		},
+		{
+			name: "no root",
+			cert: certPEM, key: keyPEM, root: []byte("invalid"),
+			wantErr: "no valid root certificate",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f := newFileSecretTest(t)
+			defer f.cleanup()
+			f.write(t, c.cert, c.key, c.root)
+			f.options.ExpectedIdentity = c.expected
+
+			keyCert, rootCert, err := loadFileSecrets(f.options, now)
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("loadFileSecrets() = %v, want error containing %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadFileSecrets() failed: %v", err)
+			}
+			if !bytes.Equal(keyCert.CertificateChain, c.cert) || !bytes.Equal(keyCert.PrivateKey, c.key) {
+				t.Error("unexpected key and certificate chain")
+			}
+			if !bytes.Equal(rootCert.RootCert, c.root) {
+				t.Error("unexpected root certificate")
+			}
+			if keyCert.ExpireTime.After(now.Add(time.Hour)) || keyCert.ExpireTime.Before(now) {
+				t.Errorf("unexpected expiration time %v of the workload certificate", keyCert.ExpireTime)
+			}
+			if keyCert.Version == "" || rootCert.Version == "" {
+				t.Error("secrets have no version")
+			}
+		})
+	}
+}
+
+func TestFileSecretManager(t *testing.T) {
+	ca := newTestCA(t)
+	f := newFileSecretTest(t)
+	defer f.cleanup()
+	certPEM, keyPEM := ca.issue(t, testSpiffeID, time.Now().Add(-time.Minute), time.Hour)
+	f.write(t, certPEM, keyPEM, ca.certPEM)
+
+	n := &notifications{items: map[ConnKey]*model.SecretItem{}}
+	m, err := NewFileSecretManager(f.options, n.notify, filewatcher.NewWatcher())
+	if err != nil {
+		t.Fatalf("NewFileSecretManager() failed: %v", err)
+	}
+	defer m.Close()
+
+	keyCert, err := m.GenerateSecret(context.Background(), "conn1", WorkloadKeyCertResourceName, "token")
+	if err != nil {
+		t.Fatalf("GenerateSecret() failed: %v", err)
+	}
+	if !bytes.Equal(keyCert.CertificateChain, certPEM) || keyCert.Token != "token" {
+		t.Errorf("unexpected workload secret %+v", keyCert)
+	}
+	root, err := m.GenerateSecret(context.Background(), "conn1", RootCertReqResourceName, "token")
+	if err != nil {
+		t.Fatalf("GenerateSecret() failed: %v", err)
+	}
+	if !bytes.Equal(root.RootCert, ca.certPEM) {
+		t.Error("unexpected root certificate")
+	}
+	if _, err := m.GenerateSecret(context.Background(), "conn1", "other", "token"); err == nil {
+		t.Error("expected error for unknown resource")
+	}
+	if !m.SecretExist("conn1", WorkloadKeyCertResourceName, "token", keyCert.Version) {
+		t.Error("SecretExist() = false for the sent secret")
+	}
+	if m.SecretExist("conn1", WorkloadKeyCertResourceName, "other-token", keyCert.Version) {
+		t.Error("SecretExist() = true for another token")
+	}
+	if m.ShouldWaitForIngressGatewaySecret("conn1", WorkloadKeyCertResourceName, "token") {
+		t.Error("ShouldWaitForIngressGatewaySecret() = true")
+	}
+
+	// Rotate the workload certificate, the connection is pushed the new one.
+	rotatedPEM, rotatedKeyPEM := ca.issue(t, testSpiffeID, time.Now().Add(-time.Minute), 2*time.Hour)
+	f.write(t, rotatedPEM, rotatedKeyPEM, ca.certPEM)
+	connKey := ConnKey{ConnectionID: "conn1", ResourceName: WorkloadKeyCertResourceName}
+	deadline := time.Now().Add(10 * time.Second)
+	for n.get(connKey) == nil && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	pushed := n.get(connKey)
+	if pushed == nil {
+		t.Fatal("rotated certificate wasn't pushed")
+	}
+	if !bytes.Equal(pushed.CertificateChain, rotatedPEM) || !bytes.Equal(pushed.PrivateKey, rotatedKeyPEM) {
+		t.Error("pushed secret isn't the rotated certificate")
+	}
+	if pushed.Token != "token" {
+		t.Errorf("pushed secret has token %q, want %q", pushed.Token, "token")
+	}
+	if m.SecretExist("conn1", WorkloadKeyCertResourceName, "token", keyCert.Version) {
+		t.Error("SecretExist() = true for the previous version")
+	}
+	if n.get(ConnKey{ConnectionID: "conn1", ResourceName: RootCertReqResourceName}) != nil {
+		t.Error("unchanged root certificate was pushed")
+	}
+
+	m.DeleteSecret("conn1", WorkloadKeyCertResourceName)
+	if m.SecretExist("conn1", WorkloadKeyCertResourceName, "token", pushed.Version) {
+		t.Error("SecretExist() = true for a deleted connection")
+	}
+}
+
+func TestFileSecretManagerInvalidRotation(t *testing.T) {
+	ca := newTestCA(t)
+	f := newFileSecretTest(t)
+	defer f.cleanup()
+	certPEM, keyPEM := ca.issue(t, testSpiffeID, time.Now().Add(-time.Minute), time.Hour)
+	f.write(t, certPEM, keyPEM, ca.certPEM)
+
+	n := &notifications{items: map[ConnKey]*model.SecretItem{}}
+	_, fakeWatcher := filewatcher.NewFakeWatcher(nil)
+	m, err := NewFileSecretManager(f.options, n.notify, fakeWatcher)
+	if err != nil {
+		t.Fatalf("NewFileSecretManager() failed: %v", err)
+	}
+	defer m.Close()
+	if _, err := m.GenerateSecret(context.Background(), "conn1", WorkloadKeyCertResourceName, "token"); err != nil {
+		t.Fatalf("GenerateSecret() failed: %v", err)
+	}
+
+	// A certificate for another identity is rejected, and the previous one is kept.
+	otherPEM, otherKeyPEM := ca.issue(t, "spiffe://cluster.local/ns/default/sa/bar", time.Now().Add(-time.Minute), time.Hour)
+	f.write(t, otherPEM, otherKeyPEM, ca.certPEM)
+	m.reload(time.Now())
+	if len(n.items) != 0 {
+		t.Errorf("invalid certificate was pushed: %v", n.items)
+	}
+	keyCert, err := m.GenerateSecret(context.Background(), "conn1", WorkloadKeyCertResourceName, "token")
+	if err != nil {
+		t.Fatalf("GenerateSecret() failed: %v", err)
+	}
+	if !bytes.Equal(keyCert.CertificateChain, certPEM) {
+		t.Error("previous certificate wasn't kept")
+	}
+
+	// Loading the same files again doesn't push anything.
+	f.write(t, certPEM, keyPEM, ca.certPEM)
+	m.reload(time.Now())
+	if len(n.items) != 0 {
+		t.Errorf("unchanged certificate was pushed: %v", n.items)
+	}
+}
+
+func TestNewFileSecretManagerInvalid(t *testing.T) {
+	f := newFileSecretTest(t)
+	defer f.cleanup()
+	_, fakeWatcher := filewatcher.NewFakeWatcher(nil)
+	if _, err := NewFileSecretManager(f.options, nil, fakeWatcher); err == nil {
+		t.Error("expected error with missing files")
+	}
+}
@@ -22,7 +22,8 @@ const (
 )
 
 var (
-	RequestType = monitoring.MustCreateLabel("request_type")
+	RequestType  = monitoring.MustCreateLabel("request_type")
+	ResourceName = monitoring.MustCreateLabel("resource_name")
 )
 
 // Metrics for outgoing requests from citadel agent to external services such as token exchange server or a CA.
@@ -50,11 +51,25 @@ var (
 		monitoring.WithLabels(RequestType))
 )
 
+// Metrics for the certificates served from files.
+var (
+	fileSecretExpiry = monitoring.NewGauge(
+		"file_mounted_cert_expiry_timestamp",
+		"The expiration time of the certificates served from files, in seconds since the epoch.",
+		monitoring.WithLabels(ResourceName))
+
+	numFileSecretReloadFailures = monitoring.NewSum(
+		"file_mounted_cert_reload_failures",
+		"Number of failed reloads of the certificates served from files.")
+)
+
 func init() {
 	monitoring.MustRegister(
 		outgoingLatency,
 		numOutgoingRequests,
 		numOutgoingRetries,
 		numFailedOutgoingRequests,
+		fileSecretExpiry,
+		numFileSecretReloadFailures,
 	)
 }
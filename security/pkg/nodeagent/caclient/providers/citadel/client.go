@@ -44,15 +44,19 @@ type citadelClient struct {
 	caEndpoint    string
 	enableTLS     bool
 	caTLSRootCert []byte
+	clusterID     string
 	client        pb.IstioCertificateServiceClient
 }
 
 // NewCitadelClient create a CA client for Citadel.
-func NewCitadelClient(endpoint string, tls bool, rootCert []byte) (caClientInterface.Client, error) {
+// clusterID is the ID of the cluster of the workload, sent to the CA so that an external control plane
+// reviews the token in that cluster. It may be empty.
+func NewCitadelClient(endpoint string, tls bool, rootCert []byte, clusterID string) (caClientInterface.Client, error) {
 	c := &citadelClient{
 		caEndpoint:    endpoint,
 		enableTLS:     tls,
 		caTLSRootCert: rootCert,
+		clusterID:     clusterID,
 	}
 
 	var opts grpc.DialOption
@@ -88,7 +92,11 @@ func (c *citadelClient) CSRSign(ctx context.Context, csrPEM []byte, token string
 
 	// add Bearer prefix, which is required by Citadel.
 	token = bearerTokenPrefix + token
-	ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("Authorization", token))
+	md := metadata.Pairs("Authorization", token)
+	if c.clusterID != "" {
+		md.Set("ClusterID", c.clusterID)
+	}
+	ctx = metadata.NewOutgoingContext(ctx, md)
 	resp, err := c.client.CreateCertificate(ctx, req)
 	if err != nil {
 		citadelClientLog.Errorf("Failed to create certificate: %v", err)
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 
 	pb "istio.io/istio/security/proto"
 )
@@ -35,11 +36,16 @@ var (
 )
 
 type mockCAServer struct {
-	Certs []string
-	Err   error
+	Certs     []string
+	Err       error
+	ClusterID string
 }
 
 func (ca *mockCAServer) CreateCertificate(ctx context.Context, in *pb.IstioCertificateRequest) (*pb.IstioCertificateResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids := md.Get("clusterid"); ca.ClusterID != "" && (len(ids) != 1 || ids[0] != ca.ClusterID) {
+		return nil, fmt.Errorf("unexpected cluster ID %v", ids)
+	}
 	if ca.Err == nil {
 		return &pb.IstioCertificateResponse{CertChain: ca.Certs}, nil
 	}
@@ -49,6 +55,7 @@ func (ca *mockCAServer) CreateCertificate(ctx context.Context, in *pb.IstioCerti
 func TestCitadelClient(t *testing.T) {
 	testCases := map[string]struct {
 		server       mockCAServer
+		clusterID    string
 		expectedCert []string
 		expectedErr  string
 	}{
@@ -62,6 +69,12 @@ func TestCitadelClient(t *testing.T) {
 			expectedCert: nil,
 			expectedErr:  "rpc error: code = Unknown desc = test failure",
 		},
+		"Cluster ID": {
+			server:       mockCAServer{Certs: fakeCert, Err: nil, ClusterID: "remote"},
+			clusterID:    "remote",
+			expectedCert: fakeCert,
+			expectedErr:  "",
+		},
 		"Empty response": {
 			server:       mockCAServer{Certs: []string{}, Err: nil},
 			expectedCert: nil,
@@ -88,7 +101,7 @@ func TestCitadelClient(t *testing.T) {
 		// The goroutine starting the server may not be ready, results in flakiness.
 		time.Sleep(1 * time.Second)
 
-		cli, err := NewCitadelClient(lis.Addr().String(), false, nil)
+		cli, err := NewCitadelClient(lis.Addr().String(), false, nil, tc.clusterID)
 		if err != nil {
 			t.Errorf("Test case [%s]: failed to create ca client: %v", id, err)
 		}
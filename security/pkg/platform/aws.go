@@ -15,10 +15,16 @@
 package platform
 
 import (
+	"crypto/rand"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
 
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -51,18 +57,36 @@ C1haGgSI/A1uZUKs/Zfnph0oEI0/hu1IIJ/SKBDtN5lvmZ/IzbOPIJWirlsllQIQ
 -----END CERTIFICATE-----`
 )
 
+// AWSInstanceIdentity is the agent credential of AWS instances: the instance identity document
+// with its signature, so that Citadel can verify the document.
+type AWSInstanceIdentity struct {
+	Document string `json:"document"`
+	// Signature is the base64 encoded RSA SHA-256 signature of the document.
+	Signature string `json:"signature"`
+	// Nonce is a random value generated once by the node agent of the instance. The document never
+	// changes while the instance runs, so Citadel binds the instance to the first nonce it presents,
+	// and rejects a copied document presented with another nonce.
+	Nonce string `json:"nonce"`
+}
+
 // AwsClientImpl is the implementation of AWS metadata client.
 type AwsClientImpl struct {
 	// Root CA cert file to validate the gRPC service in CA.
 	rootCertFile string
+	// nonceFile keeps the nonce of the instance across node agent restarts.
+	nonceFile string
 
 	client *ec2metadata.EC2Metadata
+
+	mu    sync.Mutex
+	nonce string
 }
 
-// NewAwsClientImpl creates a new AwsClientImpl.
-func NewAwsClientImpl(rootCert string) *AwsClientImpl {
+// NewAwsClientImpl creates a new AwsClientImpl, keeping the nonce of the instance in nonceFile.
+func NewAwsClientImpl(rootCert, nonceFile string) *AwsClientImpl {
 	return &AwsClientImpl{
 		rootCertFile: rootCert,
+		nonceFile:    nonceFile,
 		client:       ec2metadata.New(session.Must(session.NewSession())),
 	}
 }
@@ -89,7 +113,7 @@ func (ci *AwsClientImpl) GetServiceIdentity() (string, error) {
 	return "", nil
 }
 
-func (ci *AwsClientImpl) getInstanceIdentityDocument() ([]byte, error) {
+func (ci *AwsClientImpl) getInstanceIdentityDocument() (*AWSInstanceIdentity, error) {
 	cert, err := util.ParsePemEncodedCertificate([]byte(AWSCertificatePem))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse AWS public certificate: %v", err)
@@ -114,10 +138,52 @@ func (ci *AwsClientImpl) getInstanceIdentityDocument() ([]byte, error) {
 		return nil, fmt.Errorf("failed to verify PKCS7 signature: %v", err)
 	}
 
-	return []byte(doc), nil
+	nonce, err := ci.getNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AWSInstanceIdentity{
+		Document:  doc,
+		Signature: base64.StdEncoding.EncodeToString(dec),
+		Nonce:     nonce,
+	}, nil
+}
+
+// getNonce returns the nonce of the instance, generated on first use and kept in the nonce file if
+// any, since Citadel rejects the instance if the node agent presents another nonce after a restart.
+func (ci *AwsClientImpl) getNonce() (string, error) {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	if ci.nonce != "" {
+		return ci.nonce, nil
+	}
+
+	if ci.nonceFile != "" {
+		b, err := ioutil.ReadFile(ci.nonceFile)
+		if err == nil && len(strings.TrimSpace(string(b))) > 0 {
+			ci.nonce = strings.TrimSpace(string(b))
+			return ci.nonce, nil
+		} else if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read the instance nonce: %v", err)
+		}
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate the instance nonce: %v", err)
+	}
+	nonce := hex.EncodeToString(b)
+	if ci.nonceFile != "" {
+		if err := ioutil.WriteFile(ci.nonceFile, []byte(nonce), 0600); err != nil {
+			return "", fmt.Errorf("failed to write the instance nonce: %v", err)
+		}
+	}
+	ci.nonce = nonce
+	return ci.nonce, nil
 }
 
-// GetAgentCredential retrieves the instance identity document as the
+// GetAgentCredential retrieves the signed instance identity document as the
 // agent credential used by node agent
 func (ci *AwsClientImpl) GetAgentCredential() ([]byte, error) {
 	doc, err := ci.getInstanceIdentityDocument()
@@ -15,12 +15,16 @@
 package platform
 
 import (
-	"bytes"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -29,6 +33,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+
+	"istio.io/istio/security/pkg/pki/util"
 )
 
 const (
@@ -81,12 +87,35 @@ func TestIsProperPlatform(t *testing.T) {
 }
 
 func TestNewAwsClientImpl(t *testing.T) {
-	client := NewAwsClientImpl("")
+	client := NewAwsClientImpl("", "")
 	if client == nil {
 		t.Errorf("NewAwsClientImpl should not return nil")
 	}
 }
 
+func TestAwsNonce(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aws-nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	nonceFile := filepath.Join(dir, awsNonceFile)
+
+	first, err := NewAwsClientImpl("", nonceFile).getNonce()
+	if err != nil || first == "" {
+		t.Fatalf("failed to generate the nonce: %q %v", first, err)
+	}
+	// The nonce is kept across node agent restarts.
+	second, err := NewAwsClientImpl("", nonceFile).getNonce()
+	if err != nil || second != first {
+		t.Errorf("expected the nonce %q to be kept, got %q %v", first, second, err)
+	}
+	other, err := NewAwsClientImpl("", filepath.Join(dir, "other")).getNonce()
+	if err != nil || other == first {
+		t.Errorf("expected another nonce than %q, got %q %v", first, other, err)
+	}
+}
+
 func TestAwsGetInstanceIdentityDocument(t *testing.T) {
 	testCases := map[string]struct {
 		sigFile              string
@@ -126,7 +155,7 @@ func TestAwsGetInstanceIdentityDocument(t *testing.T) {
 			client: ec2metadata.New(unit.Session, &aws.Config{Endpoint: aws.String(server.URL + "/latest")}),
 		}
 
-		docBytes, err := awsc.getInstanceIdentityDocument()
+		identity, err := awsc.getInstanceIdentityDocument()
 		if len(c.expectedErr) > 0 {
 			if err == nil {
 				t.Errorf("%s: Succeeded. Error expected: %v", id, err)
@@ -140,7 +169,7 @@ func TestAwsGetInstanceIdentityDocument(t *testing.T) {
 		}
 
 		doc := ec2metadata.EC2InstanceIdentityDocument{}
-		decode := json.NewDecoder(bytes.NewReader(docBytes)).Decode(&doc)
+		decode := json.NewDecoder(strings.NewReader(identity.Document)).Decode(&doc)
 		if decode != nil {
 			t.Fatalf("%s: Unexpected Error: %v", id, err)
 		}
@@ -198,24 +227,18 @@ func TestAwsGetServiceIdentity(t *testing.T) {
 
 func TestGetGetAgentCredential(t *testing.T) {
 	testCases := map[string]struct {
-		sigFile            string
-		doc                string
-		requestPath        string
-		expectedErr        string
-		expectedCredential string
+		sigFile          string
+		doc              string
+		requestPath      string
+		expectedErr      string
+		expectedDocument string
 	}{
 		"Good Identity": {
-			sigFile:     "testdata/sig.pem",
-			doc:         doc,
-			requestPath: "/latest/dynamic/instance-identity/pkcs7",
-			expectedErr: "",
-			expectedCredential: "\"ewogICJkZXZwYXlQcm9kdWN0Q29kZXMiIDogbnVsbCwKICAicHJpdmF0ZUlwIiA6ICIx" +
-				"MC4xNi4xNy4yNDgiLAogICJhdmFpbGFiaWxpdHlab25lIiA6ICJ1cy13ZXN0LTJiIiwKICAidmVyc2lvbiIgOi" +
-				"AiMjAxMC0wOC0zMSIsCiAgImluc3RhbmNlSWQiIDogImktMDY0NmM5ZWZlMmU2MmRjNjMiLAogICJiaWxsaW5n" +
-				"UHJvZHVjdHMiIDogbnVsbCwKICAiaW5zdGFuY2VUeXBlIiA6ICJjMy5sYXJnZSIsCiAgImFjY291bnRJZCIgOi" +
-				"AiOTc3Nzc3NjU3NjExIiwKICAiYXJjaGl0ZWN0dXJlIiA6ICJ4ODZfNjQiLAogICJrZXJuZWxJZCIgOiBudWxs" +
-				"LAogICJyYW1kaXNrSWQiIDogbnVsbCwKICAiaW1hZ2VJZCIgOiAiYW1pLWZhYmY1YzgyIiwKICAicGVuZGluZ1" +
-				"RpbWUiIDogIjIwMTctMDgtMjdUMTc6MTg6MjBaIiwKICAicmVnaW9uIiA6ICJ1cy13ZXN0LTIiCn0=\"",
+			sigFile:          "testdata/sig.pem",
+			doc:              doc,
+			requestPath:      "/latest/dynamic/instance-identity/pkcs7",
+			expectedErr:      "",
+			expectedDocument: doc,
 		},
 	}
 
@@ -246,8 +269,23 @@ func TestGetGetAgentCredential(t *testing.T) {
 			t.Fatalf("%s: Unexpected Error: %v", id, err)
 		}
 
-		if string(credential) != c.expectedCredential {
-			t.Errorf("%s: Wrong Credential. Expected %s, Actual %s", id, c.expectedCredential, string(credential))
+		identity := &AWSInstanceIdentity{}
+		if err := json.Unmarshal(credential, identity); err != nil {
+			t.Fatalf("%s: Unable to unmarshal credential %s: %v", id, string(credential), err)
+		}
+		if identity.Document != c.expectedDocument {
+			t.Errorf("%s: Wrong Document. Expected %s, Actual %s", id, c.expectedDocument, identity.Document)
+		}
+		if identity.Nonce == "" {
+			t.Errorf("%s: Expected a nonce", id)
+		}
+		sig, err := base64.StdEncoding.DecodeString(identity.Signature)
+		if err != nil {
+			t.Errorf("%s: Unable to decode signature %s: %v", id, identity.Signature, err)
+		}
+		cert, _ := util.ParsePemEncodedCertificate([]byte(AWSCertificatePem))
+		if err := cert.CheckSignature(x509.SHA256WithRSA, []byte(identity.Document), sig); err != nil {
+			t.Errorf("%s: Signature does not verify the document: %v", id, err)
 		}
 	}
 }
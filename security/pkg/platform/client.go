@@ -16,6 +16,7 @@ package platform
 
 import (
 	"fmt"
+	"path/filepath"
 
 	// Temporarily disable ID token authentication on CSR API.
 	// [TODO](myidpt): enable when the Citadel authz can work correctly.
@@ -37,19 +38,19 @@ type Client interface {
 	GetCredentialType() string
 }
 
-// NewClient is the function to create implementations of the platform metadata client.
-func NewClient(platform, rootCertFile, keyFile, certChainFile string) (Client, error) {
+// awsNonceFile is the file keeping the nonce of an AWS instance, next to its key.
+const awsNonceFile = "aws-instance-nonce"
+
+// NewClient is the function to create implementations of the platform metadata client. caAddr is
+// the address of Citadel, the audience of the GCP instance identity tokens.
+func NewClient(platform, rootCertFile, keyFile, certChainFile, caAddr string) (Client, error) {
 	switch platform {
 	case "onprem":
 		return NewOnPremClientImpl(rootCertFile, keyFile, certChainFile)
 	case "gcp":
-		// Temporarily disable ID token authentication on CSR API.
-		// [TODO](myidpt): enable when the Citadel authz can work correctly.
-		return nil, fmt.Errorf("GCP credential authentication in CSR API is disabled")
+		return NewGcpClientImpl(rootCertFile, caAddr), nil
 	case "aws":
-		// Temporarily disable ID token authentication on CSR API.
-		// [TODO](myidpt): enable when the Citadel authz can work correctly.
-		return nil, fmt.Errorf("AWS credential authentication in CSR API is disabled")
+		return NewAwsClientImpl(rootCertFile, filepath.Join(filepath.Dir(keyFile), awsNonceFile)), nil
 	case "unspecified":
 		// Temporarily disable ID token authentication on CSR API.
 		// [TODO](myidpt): enable when the Citadel authz can work correctly.
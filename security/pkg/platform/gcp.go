@@ -56,6 +56,16 @@ type GcpClientImpl struct {
 	fetcher cred.TokenFetcher
 }
 
+// NewGcpClientImpl creates a new GcpClientImpl, authenticating to the CA at caAddr with the
+// instance identity token of the VM, requested for the audience grpc://caAddr.
+func NewGcpClientImpl(rootCert, caAddr string) *GcpClientImpl {
+	return &GcpClientImpl{
+		rootCertFile: rootCert,
+		caAddr:       caAddr,
+		fetcher:      &cred.GcpTokenFetcher{Aud: fmt.Sprintf("grpc://%s", caAddr)},
+	}
+}
+
 // IsProperPlatform returns whether the client is on GCE.
 func (ci *GcpClientImpl) IsProperPlatform() bool {
 	return metadata.OnGCE()
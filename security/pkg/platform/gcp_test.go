@@ -23,6 +23,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+
+	cred "istio.io/istio/security/pkg/credential"
 )
 
 const (
@@ -295,3 +297,18 @@ func TestGcpGetCredentialTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestNewGcpClientImpl(t *testing.T) {
+	client := NewGcpClientImpl("testdata/cert-root-good.pem", "istio-citadel:8060")
+	fetcher, ok := client.fetcher.(*cred.GcpTokenFetcher)
+	if !ok {
+		t.Fatalf("unexpected token fetcher %T", client.fetcher)
+	}
+	// The audience is checked by the GCP instance identity authenticator of Citadel.
+	if fetcher.Aud != "grpc://istio-citadel:8060" {
+		t.Errorf("unexpected audience %q", fetcher.Aud)
+	}
+	if client.GetCredentialType() != "gcp" {
+		t.Errorf("unexpected credential type %q", client.GetCredentialType())
+	}
+}
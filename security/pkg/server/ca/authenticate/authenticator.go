@@ -42,6 +42,7 @@ type AuthSource int
 const (
 	AuthSourceClientCertificate AuthSource = iota
 	AuthSourceIDToken
+	AuthSourceInstanceIdentity
 )
 
 // Caller carries the identity and authentication source of a caller.
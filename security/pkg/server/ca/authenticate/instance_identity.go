@@ -0,0 +1,472 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authenticate
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	oidc "github.com/coreos/go-oidc"
+	"github.com/ghodss/yaml"
+	"golang.org/x/net/context"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+
+	"istio.io/pkg/log"
+
+	"istio.io/istio/security/pkg/pki/util"
+	"istio.io/istio/security/pkg/platform"
+)
+
+const (
+	AWSInstanceIdentityAuthenticatorType = "AWSInstanceIdentityAuthenticator"
+	GCPInstanceIdentityAuthenticatorType = "GCPInstanceIdentityAuthenticator"
+
+	// Credential types sent by the node agent, see platform.Client.GetCredentialType.
+	awsCredentialType = "aws"
+	gcpCredentialType = "gcp"
+
+	// gcpCertsURL serves the keys signing the Google ID tokens.
+	gcpCertsURL = "https://www.googleapis.com/oauth2/v3/certs"
+
+	// AWSInstanceBindingsConfigMapName is the ConfigMap holding the nonces the AWS instances are bound
+	// to, shared by all Citadel replicas.
+	AWSInstanceBindingsConfigMapName = "istio-aws-instance-bindings"
+
+	// maxAWSInstanceBindings bounds the bindings of the ConfigMap, which is limited to 1 MiB, with a
+	// binding taking about 150 bytes.
+	maxAWSInstanceBindings = 5000
+)
+
+type agentCredentialKey struct{}
+
+type agentCredential struct {
+	credentialType string
+	credential     []byte
+}
+
+// ContextWithAgentCredential returns a context carrying the platform credential sent by the node agent
+// in its CSR, so that the instance identity authenticators can verify it.
+func ContextWithAgentCredential(ctx context.Context, credentialType string, credential []byte) context.Context {
+	return context.WithValue(ctx, agentCredentialKey{}, agentCredential{
+		credentialType: credentialType,
+		credential:     credential,
+	})
+}
+
+func extractAgentCredential(ctx context.Context, credentialType string) ([]byte, error) {
+	c, ok := ctx.Value(agentCredentialKey{}).(agentCredential)
+	if !ok || len(c.credential) == 0 {
+		return nil, fmt.Errorf("no node agent credential is presented")
+	}
+	if c.credentialType != credentialType {
+		return nil, fmt.Errorf("unsupported credential type: %q", c.credentialType)
+	}
+	return c.credential, nil
+}
+
+// InstanceIdentityRule grants an identity to the instances of a cloud platform having all the
+// attributes of the rule.
+type InstanceIdentityRule struct {
+	// Platform is either "aws" or "gcp".
+	Platform string `json:"platform"`
+	// Attributes of the instance, e.g. accountId, region or instanceId on AWS, and project_id, zone
+	// or email (the service account) on GCP.
+	Attributes map[string]string `json:"attributes"`
+	// Identity is the identity granted to the instances, e.g. spiffe://cluster.local/ns/vm/sa/billing.
+	Identity string `json:"identity"`
+}
+
+// InstanceIdentityPolicy maps the attributes of cloud instances to the identities they are issued
+// certificates for.
+type InstanceIdentityPolicy struct {
+	Rules []InstanceIdentityRule `json:"rules"`
+}
+
+// LoadInstanceIdentityPolicy loads an instance identity policy from a YAML file.
+func LoadInstanceIdentityPolicy(path string) (*InstanceIdentityPolicy, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the instance identity policy: %v", err)
+	}
+	policy := &InstanceIdentityPolicy{}
+	if err := yaml.Unmarshal(b, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse the instance identity policy: %v", err)
+	}
+	for i, rule := range policy.Rules {
+		if rule.Platform != awsCredentialType && rule.Platform != gcpCredentialType {
+			return nil, fmt.Errorf("rule %d has unsupported platform %q", i, rule.Platform)
+		}
+		if rule.Identity == "" {
+			return nil, fmt.Errorf("rule %d has no identity", i)
+		}
+		// A rule without attributes would grant its identity to every instance of the platform.
+		if len(rule.Attributes) == 0 {
+			return nil, fmt.Errorf("rule %d has no attributes", i)
+		}
+	}
+	return policy, nil
+}
+
+// identities returns the identities granted to an instance of the platform with the attributes.
+func (p *InstanceIdentityPolicy) identities(platform string, attributes map[string]string) []string {
+	ids := make([]string, 0)
+	for _, rule := range p.Rules {
+		if rule.Platform != platform {
+			continue
+		}
+		matched := true
+		for k, v := range rule.Attributes {
+			if attributes[k] != v {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			ids = append(ids, rule.Identity)
+		}
+	}
+	return ids
+}
+
+// AWSInstanceIdentityAuthenticator authenticates EC2 instances by their signed instance identity
+// document. The document does not expire, so it must only be sent over TLS, and the authenticator
+// binds each instance to the nonce its node agent presents first: the document presented with
+// another nonce, or a document older than the one already presented, is rejected as a replay.
+// Instances are only bound within maxAge of their launch, so a leaked document of a long running
+// instance that was never bound can't be used. The bindings are kept in a ConfigMap, so they are
+// shared by the Citadel replicas and survive restarts. A binding expires when its instance has not
+// authenticated for bindingTTL, as its node agent renews its certificate well within that time
+// while the instance runs, and the ConfigMap holds at most maxBindings live bindings.
+type AWSInstanceIdentityAuthenticator struct {
+	cert        *x509.Certificate
+	policy      *InstanceIdentityPolicy
+	maxAge      time.Duration
+	bindingTTL  time.Duration
+	maxBindings int
+
+	core      corev1.CoreV1Interface
+	namespace string
+	now       func() time.Time
+}
+
+// awsInstance is the binding of an instance to the nonce of its node agent.
+type awsInstance struct {
+	// NonceHash is the hex encoded SHA-256 of the nonce, the nonce itself is not stored so that
+	// reading the ConfigMap doesn't allow presenting it.
+	NonceHash string `json:"nonceHash"`
+	// PendingTime is the launch time of the instance in the document, which changes when the
+	// instance is stopped and started.
+	PendingTime time.Time `json:"pendingTime"`
+	// LastSeen is when the instance last authenticated with the nonce, which keeps the binding from
+	// expiring.
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// expired returns whether the instance has not authenticated for the TTL. Bindings without LastSeen
+// expire from the launch of the instance.
+func (b awsInstance) expired(now time.Time, ttl time.Duration) bool {
+	lastSeen := b.LastSeen
+	if lastSeen.IsZero() {
+		lastSeen = b.PendingTime
+	}
+	return now.Sub(lastSeen) > ttl
+}
+
+// NewAWSInstanceIdentityAuthenticator creates a new AWSInstanceIdentityAuthenticator verifying the
+// documents with the AWS public certificate, and keeping the bindings of the instances in a ConfigMap
+// of the namespace until they expire after bindingTTL.
+func NewAWSInstanceIdentityAuthenticator(certPEM []byte, policy *InstanceIdentityPolicy, maxAge, bindingTTL time.Duration,
+	core corev1.CoreV1Interface, namespace string) (*AWSInstanceIdentityAuthenticator, error) {
+	cert, err := util.ParsePemEncodedCertificate(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse AWS public certificate: %v", err)
+	}
+	return &AWSInstanceIdentityAuthenticator{
+		cert:        cert,
+		policy:      policy,
+		maxAge:      maxAge,
+		bindingTTL:  bindingTTL,
+		maxBindings: maxAWSInstanceBindings,
+		core:        core,
+		namespace:   namespace,
+		now:         time.Now,
+	}, nil
+}
+
+func (a *AWSInstanceIdentityAuthenticator) AuthenticatorType() string {
+	return AWSInstanceIdentityAuthenticatorType
+}
+
+// Authenticate verifies the instance identity document in the node agent credential, and returns
+// the identities the policy grants to the instance.
+func (a *AWSInstanceIdentityAuthenticator) Authenticate(ctx context.Context) (*Caller, error) {
+	credential, err := extractAgentCredential(ctx, awsCredentialType)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &platform.AWSInstanceIdentity{}
+	if err := json.Unmarshal(credential, identity); err != nil {
+		return nil, fmt.Errorf("failed to parse the instance identity: %v", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(identity.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the instance identity signature: %v", err)
+	}
+	if err := a.cert.CheckSignature(x509.SHA256WithRSA, []byte(identity.Document), signature); err != nil {
+		return nil, fmt.Errorf("failed to verify the instance identity document: %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(identity.Document), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse the instance identity document: %v", err)
+	}
+	attributes := make(map[string]string, len(doc))
+	for k, v := range doc {
+		if s, ok := v.(string); ok {
+			attributes[k] = s
+		}
+	}
+
+	ids := a.policy.identities(awsCredentialType, attributes)
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no identity is granted to instance %s of account %s",
+			attributes["instanceId"], attributes["accountId"])
+	}
+
+	if identity.Nonce == "" {
+		return nil, fmt.Errorf("the instance identity has no nonce")
+	}
+	pendingTime, err := time.Parse(time.RFC3339, attributes["pendingTime"])
+	if err != nil {
+		return nil, fmt.Errorf("the instance identity document has an invalid pendingTime %q", attributes["pendingTime"])
+	}
+	if err := a.bind(attributes["instanceId"], identity.Nonce, pendingTime); err != nil {
+		return nil, err
+	}
+	return &Caller{
+		AuthSource: AuthSourceInstanceIdentity,
+		Identities: ids,
+	}, nil
+}
+
+// bind binds the instance to the nonce, or checks that it is bound to it. The ConfigMap is updated
+// with its resource version, so concurrent bindings by other replicas are retried. The expired
+// bindings are removed whenever the ConfigMap is updated.
+func (a *AWSInstanceIdentityAuthenticator) bind(instanceID, nonce string, pendingTime time.Time) error {
+	sum := sha256.Sum256([]byte(nonce))
+	nonceHash := hex.EncodeToString(sum[:])
+
+	var bindErr error
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		bindErr = nil
+		configmap, exists, err := a.getBindings()
+		if err != nil {
+			return err
+		}
+		now := a.now()
+
+		var bound awsInstance
+		value, ok := configmap.Data[instanceID]
+		if ok {
+			if err := json.Unmarshal([]byte(value), &bound); err != nil {
+				// Rebind the instance rather than locking it out.
+				log.Warnf("ignoring the invalid binding of AWS instance %s: %v", instanceID, err)
+				ok = false
+			} else if bound.expired(now, a.bindingTTL) {
+				ok = false
+			}
+		}
+		switch {
+		case !ok || pendingTime.After(bound.PendingTime):
+			// The first document of the instance, or the document of a restarted instance.
+			if age := now.Sub(pendingTime); age > a.maxAge {
+				bindErr = fmt.Errorf("instance %s was launched %v ago, longer than the %v instances must be bound within",
+					instanceID, age.Round(time.Second), a.maxAge)
+				return nil
+			}
+		case pendingTime.Before(bound.PendingTime):
+			bindErr = fmt.Errorf("the instance identity document of instance %s is older than the one presented before",
+				instanceID)
+			return nil
+		case nonceHash != bound.NonceHash:
+			bindErr = fmt.Errorf("instance %s presented another nonce than the one it is bound to, the document may be replayed",
+				instanceID)
+			return nil
+		case now.Sub(bound.LastSeen) < a.bindingTTL/2:
+			// Already bound to the nonce, and seen recently enough not to update the ConfigMap.
+			return nil
+		}
+
+		a.expireBindings(configmap.Data, now)
+		if _, ok := configmap.Data[instanceID]; !ok && len(configmap.Data) >= a.maxBindings {
+			bindErr = fmt.Errorf("instance %s can't be bound, %d instances are bound already", instanceID, len(configmap.Data))
+			return nil
+		}
+		b, err := json.Marshal(awsInstance{NonceHash: nonceHash, PendingTime: pendingTime, LastSeen: now})
+		if err != nil {
+			return err
+		}
+		configmap.Data[instanceID] = string(b)
+		if exists {
+			_, err = a.core.ConfigMaps(a.namespace).Update(configmap)
+		} else {
+			_, err = a.core.ConfigMaps(a.namespace).Create(configmap)
+			if errors.IsAlreadyExists(err) {
+				// Created by another replica, retry the update.
+				return errors.NewConflict(v1.Resource("configmaps"), AWSInstanceBindingsConfigMapName, err)
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to bind instance %s: %v", instanceID, err)
+	}
+	return bindErr
+}
+
+// expireBindings removes the expired bindings, and the invalid ones.
+func (a *AWSInstanceIdentityAuthenticator) expireBindings(bindings map[string]string, now time.Time) {
+	for instanceID, value := range bindings {
+		var bound awsInstance
+		if err := json.Unmarshal([]byte(value), &bound); err != nil || bound.expired(now, a.bindingTTL) {
+			delete(bindings, instanceID)
+		}
+	}
+}
+
+// getBindings returns the ConfigMap of the bindings, and whether it exists.
+func (a *AWSInstanceIdentityAuthenticator) getBindings() (*v1.ConfigMap, bool, error) {
+	configmap, err := a.core.ConfigMaps(a.namespace).Get(AWSInstanceBindingsConfigMapName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      AWSInstanceBindingsConfigMapName,
+				Namespace: a.namespace,
+			},
+			Data: map[string]string{},
+		}, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if configmap.Data == nil {
+		configmap.Data = map[string]string{}
+	}
+	return configmap, true, nil
+}
+
+// GCPInstanceIdentityAuthenticator authenticates GCE instances by their instance identity token,
+// a Google ID token requested in full format.
+type GCPInstanceIdentityAuthenticator struct {
+	verifier  *oidc.IDTokenVerifier
+	audiences []string
+	policy    *InstanceIdentityPolicy
+}
+
+// NewGCPInstanceIdentityAuthenticator creates a new GCPInstanceIdentityAuthenticator accepting
+// tokens for any of the audiences.
+func NewGCPInstanceIdentityAuthenticator(audiences []string, policy *InstanceIdentityPolicy) *GCPInstanceIdentityAuthenticator {
+	return newGCPInstanceIdentityAuthenticator(oidc.NewRemoteKeySet(context.Background(), gcpCertsURL), audiences, policy)
+}
+
+func newGCPInstanceIdentityAuthenticator(keySet oidc.KeySet, audiences []string,
+	policy *InstanceIdentityPolicy) *GCPInstanceIdentityAuthenticator {
+	return &GCPInstanceIdentityAuthenticator{
+		// The audience is checked against the whole list below.
+		verifier:  oidc.NewVerifier(idTokenIssuer, keySet, &oidc.Config{SkipClientIDCheck: true}),
+		audiences: audiences,
+		policy:    policy,
+	}
+}
+
+func (a *GCPInstanceIdentityAuthenticator) AuthenticatorType() string {
+	return GCPInstanceIdentityAuthenticatorType
+}
+
+// Authenticate verifies the instance identity token in the node agent credential, and returns the
+// identities the policy grants to the instance.
+func (a *GCPInstanceIdentityAuthenticator) Authenticate(ctx context.Context) (*Caller, error) {
+	credential, err := extractAgentCredential(ctx, gcpCredentialType)
+	if err != nil {
+		return nil, err
+	}
+
+	idToken, err := a.verifier.Verify(ctx, string(credential))
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify the instance identity token: %v", err)
+	}
+	if !containsAny(a.audiences, idToken.Audience) {
+		return nil, fmt.Errorf("unexpected instance identity token audience %v", idToken.Audience)
+	}
+
+	var claims struct {
+		Email  string `json:"email"`
+		Google struct {
+			ComputeEngine struct {
+				ProjectID     string      `json:"project_id"`
+				ProjectNumber json.Number `json:"project_number"`
+				Zone          string      `json:"zone"`
+				InstanceID    string      `json:"instance_id"`
+				InstanceName  string      `json:"instance_name"`
+			} `json:"compute_engine"`
+		} `json:"google"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to extract the instance attributes from the token: %v", err)
+	}
+	instance := claims.Google.ComputeEngine
+	if instance.InstanceID == "" {
+		return nil, fmt.Errorf("the instance identity token has no instance attributes, it must be requested in full format")
+	}
+
+	attributes := map[string]string{
+		"email":          claims.Email,
+		"project_id":     instance.ProjectID,
+		"project_number": instance.ProjectNumber.String(),
+		"zone":           instance.Zone,
+		"instance_id":    instance.InstanceID,
+		"instance_name":  instance.InstanceName,
+	}
+	ids := a.policy.identities(gcpCredentialType, attributes)
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no identity is granted to instance %s of project %s", instance.InstanceName, instance.ProjectID)
+	}
+	return &Caller{
+		AuthSource: AuthSourceInstanceIdentity,
+		Identities: ids,
+	}, nil
+}
+
+func containsAny(values []string, candidates []string) bool {
+	for _, c := range candidates {
+		for _, v := range values {
+			if c == v {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,569 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authenticate
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	jose "gopkg.in/square/go-jose.v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"istio.io/istio/security/pkg/pki/util"
+	"istio.io/istio/security/pkg/platform"
+)
+
+const (
+	awsDocument = `{
+  "privateIp" : "10.16.17.248",
+  "availabilityZone" : "us-west-2b",
+  "instanceId" : "i-0646c9efe2e62dc63",
+  "instanceType" : "c3.large",
+  "accountId" : "977777657611",
+  "imageId" : "ami-fabf5c82",
+  "pendingTime" : "2017-08-27T17:18:20Z",
+  "region" : "us-west-2"
+}`
+
+	awsNonce = "9f86d081884c7d659a2feaa0c55ad015"
+
+	awsBindingsNamespace = "istio-system"
+
+	testAudience = "grpc://istio-citadel:8060"
+)
+
+// awsLaunchTime is the pendingTime of awsDocument.
+var awsLaunchTime = time.Date(2017, 8, 27, 17, 18, 20, 0, time.UTC)
+
+var testPolicy = &InstanceIdentityPolicy{
+	Rules: []InstanceIdentityRule{
+		{
+			Platform:   "aws",
+			Attributes: map[string]string{"accountId": "977777657611", "region": "us-west-2"},
+			Identity:   "spiffe://cluster.local/ns/vm/sa/billing",
+		},
+		{
+			Platform:   "gcp",
+			Attributes: map[string]string{"project_id": "billing-prod", "zone": "us-central1-a"},
+			Identity:   "spiffe://cluster.local/ns/vm/sa/billing",
+		},
+	},
+}
+
+// genSigningCert generates a self-signed certificate standing in for the cloud provider's.
+func genSigningCert(t *testing.T) ([]byte, *rsa.PrivateKey) {
+	t.Helper()
+	certPEM, keyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		Host:         "ec2.amazonaws.com",
+		NotBefore:    time.Now(),
+		TTL:          time.Hour,
+		IsSelfSigned: true,
+		RSAKeySize:   2048,
+	})
+	if err != nil {
+		t.Fatalf("failed to generate the signing certificate: %v", err)
+	}
+	key, err := util.ParsePemEncodedKey(keyPEM)
+	if err != nil {
+		t.Fatalf("failed to parse the signing key: %v", err)
+	}
+	return certPEM, key.(*rsa.PrivateKey)
+}
+
+func signAWSDocument(t *testing.T, key *rsa.PrivateKey, doc, nonce string) []byte {
+	t.Helper()
+	hashed := sha256.Sum256([]byte(doc))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatalf("failed to sign the document: %v", err)
+	}
+	credential, err := json.Marshal(&platform.AWSInstanceIdentity{
+		Document:  doc,
+		Signature: base64.StdEncoding.EncodeToString(sig),
+		Nonce:     nonce,
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal the credential: %v", err)
+	}
+	return credential
+}
+
+func TestAuthenticate_awsInstanceIdentityAuthenticator(t *testing.T) {
+	certPEM, key := genSigningCert(t)
+	_, otherKey := genSigningCert(t)
+
+	authenticator, err := NewAWSInstanceIdentityAuthenticator(certPEM, testPolicy, time.Hour, 48*time.Hour,
+		fake.NewSimpleClientset().CoreV1(), awsBindingsNamespace)
+	if err != nil {
+		t.Fatalf("failed to create the authenticator: %v", err)
+	}
+	authenticator.now = func() time.Time { return awsLaunchTime.Add(time.Minute) }
+
+	testCases := map[string]struct {
+		credentialType     string
+		credential         []byte
+		expectedIDs        []string
+		authenticateErrMsg string
+	}{
+		"No credential": {
+			credentialType:     "aws",
+			authenticateErrMsg: "no node agent credential is presented",
+		},
+		"Wrong credential type": {
+			credentialType:     "onprem",
+			credential:         []byte("cert"),
+			authenticateErrMsg: "unsupported credential type: \"onprem\"",
+		},
+		"Malformed credential": {
+			credentialType:     "aws",
+			credential:         []byte("not json"),
+			authenticateErrMsg: "failed to parse the instance identity",
+		},
+		"Signed by another key": {
+			credentialType:     "aws",
+			credential:         signAWSDocument(t, otherKey, awsDocument, awsNonce),
+			authenticateErrMsg: "failed to verify the instance identity document",
+		},
+		"No matching rule": {
+			credentialType: "aws",
+			credential: signAWSDocument(t, key,
+				strings.Replace(awsDocument, "977777657611", "123456789012", 1), awsNonce),
+			authenticateErrMsg: "no identity is granted to instance i-0646c9efe2e62dc63 of account 123456789012",
+		},
+		"No nonce": {
+			credentialType:     "aws",
+			credential:         signAWSDocument(t, key, awsDocument, ""),
+			authenticateErrMsg: "the instance identity has no nonce",
+		},
+		"Valid document": {
+			credentialType: "aws",
+			credential:     signAWSDocument(t, key, awsDocument, awsNonce),
+			expectedIDs:    []string{"spiffe://cluster.local/ns/vm/sa/billing"},
+		},
+	}
+
+	for id, tc := range testCases {
+		ctx := ContextWithAgentCredential(context.Background(), tc.credentialType, tc.credential)
+		caller, err := authenticator.Authenticate(ctx)
+		if len(tc.authenticateErrMsg) > 0 {
+			if err == nil {
+				t.Errorf("Case %s: Succeeded. Error expected: %v", id, tc.authenticateErrMsg)
+			} else if !strings.HasPrefix(err.Error(), tc.authenticateErrMsg) {
+				t.Errorf("Case %s: Incorrect error message: want %s but got %s", id, tc.authenticateErrMsg, err.Error())
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("Case %s: Unexpected Error: %v", id, err)
+		}
+
+		expectedCaller := &Caller{AuthSource: AuthSourceInstanceIdentity, Identities: tc.expectedIDs}
+		if !reflect.DeepEqual(caller, expectedCaller) {
+			t.Errorf("Case %q: Unexpected caller: want %v but got %v", id, expectedCaller, caller)
+		}
+	}
+}
+
+func TestAuthenticate_awsInstanceIdentityReplay(t *testing.T) {
+	certPEM, key := genSigningCert(t)
+	client := fake.NewSimpleClientset()
+	now := awsLaunchTime.Add(2 * time.Hour)
+	newAuthenticator := func() *AWSInstanceIdentityAuthenticator {
+		authenticator, err := NewAWSInstanceIdentityAuthenticator(certPEM, testPolicy, time.Hour, 48*time.Hour,
+			client.CoreV1(), awsBindingsNamespace)
+		if err != nil {
+			t.Fatalf("failed to create the authenticator: %v", err)
+		}
+		authenticator.now = func() time.Time { return now }
+		return authenticator
+	}
+	// Replicas share the bindings through the ConfigMap.
+	replicas := []*AWSInstanceIdentityAuthenticator{newAuthenticator(), newAuthenticator()}
+	restartTime := time.Date(2017, 9, 1, 8, 0, 0, 0, time.UTC)
+	restarted := strings.Replace(awsDocument, "2017-08-27T17:18:20Z", restartTime.Format(time.RFC3339), 1)
+	const otherNonce = "e3b0c44298fc1c149afbf4c8996fb924"
+
+	steps := []struct {
+		name               string
+		now                time.Time
+		replica            int
+		credential         []byte
+		authenticateErrMsg string
+	}{
+		{
+			name:               "instance launched too long ago",
+			now:                awsLaunchTime.Add(2 * time.Hour),
+			credential:         signAWSDocument(t, key, awsDocument, awsNonce),
+			authenticateErrMsg: "instance i-0646c9efe2e62dc63 was launched 2h0m0s ago",
+		},
+		{
+			name:       "first document binds the nonce",
+			now:        awsLaunchTime.Add(time.Minute),
+			credential: signAWSDocument(t, key, awsDocument, awsNonce),
+		},
+		{
+			name:       "same nonce after the binding window",
+			now:        awsLaunchTime.Add(24 * time.Hour),
+			credential: signAWSDocument(t, key, awsDocument, awsNonce),
+		},
+		{
+			name:               "replayed document",
+			now:                awsLaunchTime.Add(time.Minute),
+			credential:         signAWSDocument(t, key, awsDocument, otherNonce),
+			authenticateErrMsg: "instance i-0646c9efe2e62dc63 presented another nonce",
+		},
+		{
+			name:               "replayed document to another replica",
+			now:                awsLaunchTime.Add(time.Minute),
+			replica:            1,
+			credential:         signAWSDocument(t, key, awsDocument, otherNonce),
+			authenticateErrMsg: "instance i-0646c9efe2e62dc63 presented another nonce",
+		},
+		{
+			name:       "restarted instance binds a new nonce",
+			now:        restartTime.Add(time.Minute),
+			replica:    1,
+			credential: signAWSDocument(t, key, restarted, otherNonce),
+		},
+		{
+			name:               "document of the previous launch",
+			now:                restartTime.Add(time.Minute),
+			credential:         signAWSDocument(t, key, awsDocument, awsNonce),
+			authenticateErrMsg: "the instance identity document of instance i-0646c9efe2e62dc63 is older",
+		},
+		{
+			name:               "previous nonce",
+			now:                restartTime.Add(time.Minute),
+			credential:         signAWSDocument(t, key, restarted, awsNonce),
+			authenticateErrMsg: "instance i-0646c9efe2e62dc63 presented another nonce",
+		},
+	}
+	for _, step := range steps {
+		now = step.now
+		ctx := ContextWithAgentCredential(context.Background(), "aws", step.credential)
+		_, err := replicas[step.replica].Authenticate(ctx)
+		if len(step.authenticateErrMsg) > 0 {
+			if err == nil || !strings.HasPrefix(err.Error(), step.authenticateErrMsg) {
+				t.Errorf("Step %q: want error %q but got %v", step.name, step.authenticateErrMsg, err)
+			}
+		} else if err != nil {
+			t.Errorf("Step %q: unexpected error: %v", step.name, err)
+		}
+	}
+
+	configmap, err := client.CoreV1().ConfigMaps(awsBindingsNamespace).Get(AWSInstanceBindingsConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get the bindings: %v", err)
+	}
+	if binding := configmap.Data["i-0646c9efe2e62dc63"]; binding == "" || strings.Contains(binding, otherNonce) {
+		t.Errorf("expected the binding to hold the hash of the nonce, got %q", binding)
+	}
+}
+
+func TestAuthenticate_awsInstanceBindingExpiry(t *testing.T) {
+	certPEM, key := genSigningCert(t)
+	client := fake.NewSimpleClientset()
+	authenticator, err := NewAWSInstanceIdentityAuthenticator(certPEM, testPolicy, time.Hour, 48*time.Hour,
+		client.CoreV1(), awsBindingsNamespace)
+	if err != nil {
+		t.Fatalf("failed to create the authenticator: %v", err)
+	}
+	authenticator.maxBindings = 2
+	now := awsLaunchTime.Add(time.Minute)
+	authenticator.now = func() time.Time { return now }
+
+	binding := func(lastSeen time.Time) string {
+		b, _ := json.Marshal(awsInstance{NonceHash: "hash", PendingTime: awsLaunchTime, LastSeen: lastSeen})
+		return string(b)
+	}
+	// The binding of an instance that stopped renewing its certificate, and a live one.
+	if _, err := client.CoreV1().ConfigMaps(awsBindingsNamespace).Create(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: AWSInstanceBindingsConfigMapName, Namespace: awsBindingsNamespace},
+		Data: map[string]string{
+			"i-stopped": binding(now.Add(-72 * time.Hour)),
+			"i-live":    binding(now.Add(-time.Hour)),
+		},
+	}); err != nil {
+		t.Fatalf("failed to create the bindings: %v", err)
+	}
+	// Another instance, launched after the live binding expires.
+	otherLaunchTime := awsLaunchTime.Add(50 * time.Hour)
+	other := strings.Replace(awsDocument, "i-0646c9efe2e62dc63", "i-0123456789abcdef0", 1)
+	other = strings.Replace(other, "2017-08-27T17:18:20Z", otherLaunchTime.Format(time.RFC3339), 1)
+
+	steps := []struct {
+		name               string
+		now                time.Time
+		document           string
+		authenticateErrMsg string
+		expectedBindings   []string
+	}{
+		{
+			name:             "expired binding is removed",
+			now:              awsLaunchTime.Add(time.Minute),
+			document:         awsDocument,
+			expectedBindings: []string{"i-0646c9efe2e62dc63", "i-live"},
+		},
+		{
+			name:               "no room for another binding",
+			now:                awsLaunchTime.Add(time.Minute),
+			document:           other,
+			authenticateErrMsg: "instance i-0123456789abcdef0 can't be bound, 2 instances are bound already",
+			expectedBindings:   []string{"i-0646c9efe2e62dc63", "i-live"},
+		},
+		{
+			name:             "renewal keeps the binding",
+			now:              awsLaunchTime.Add(40 * time.Hour),
+			document:         awsDocument,
+			expectedBindings: []string{"i-0646c9efe2e62dc63", "i-live"},
+		},
+		{
+			name:             "binding after the live one expired",
+			now:              otherLaunchTime.Add(time.Minute),
+			document:         other,
+			expectedBindings: []string{"i-0123456789abcdef0", "i-0646c9efe2e62dc63"},
+		},
+		{
+			name:               "instance that did not renew in time",
+			now:                otherLaunchTime.Add(100 * time.Hour),
+			document:           other,
+			authenticateErrMsg: "instance i-0123456789abcdef0 was launched 100h0m0s ago",
+			expectedBindings:   []string{"i-0123456789abcdef0", "i-0646c9efe2e62dc63"},
+		},
+	}
+	for _, step := range steps {
+		now = step.now
+		ctx := ContextWithAgentCredential(context.Background(), "aws", signAWSDocument(t, key, step.document, awsNonce))
+		_, err := authenticator.Authenticate(ctx)
+		if len(step.authenticateErrMsg) > 0 {
+			if err == nil || !strings.HasPrefix(err.Error(), step.authenticateErrMsg) {
+				t.Errorf("Step %q: want error %q but got %v", step.name, step.authenticateErrMsg, err)
+			}
+		} else if err != nil {
+			t.Errorf("Step %q: unexpected error: %v", step.name, err)
+		}
+
+		configmap, err := client.CoreV1().ConfigMaps(awsBindingsNamespace).Get(AWSInstanceBindingsConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Step %q: failed to get the bindings: %v", step.name, err)
+		}
+		bindings := make([]string, 0, len(configmap.Data))
+		for instanceID := range configmap.Data {
+			bindings = append(bindings, instanceID)
+		}
+		sort.Strings(bindings)
+		if !reflect.DeepEqual(bindings, step.expectedBindings) {
+			t.Errorf("Step %q: want bindings %v but got %v", step.name, step.expectedBindings, bindings)
+		}
+	}
+}
+
+// staticKeySet verifies tokens with a single local key, standing in for the Google keys.
+type staticKeySet struct {
+	key *rsa.PublicKey
+}
+
+func (s *staticKeySet) VerifySignature(ctx context.Context, jwt string) ([]byte, error) {
+	jws, err := jose.ParseSigned(jwt)
+	if err != nil {
+		return nil, err
+	}
+	return jws.Verify(s.key)
+}
+
+func signJWT(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) []byte {
+	t.Helper()
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, nil)
+	if err != nil {
+		t.Fatalf("failed to create the signer: %v", err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("failed to marshal the claims: %v", err)
+	}
+	jws, err := signer.Sign(payload)
+	if err != nil {
+		t.Fatalf("failed to sign the token: %v", err)
+	}
+	token, err := jws.CompactSerialize()
+	if err != nil {
+		t.Fatalf("failed to serialize the token: %v", err)
+	}
+	return []byte(token)
+}
+
+func gcpClaims(aud string, computeEngine map[string]interface{}) map[string]interface{} {
+	claims := map[string]interface{}{
+		"iss":   idTokenIssuer,
+		"aud":   aud,
+		"sub":   "1234567890",
+		"email": "billing@billing-prod.iam.gserviceaccount.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	if computeEngine != nil {
+		claims["google"] = map[string]interface{}{"compute_engine": computeEngine}
+	}
+	return claims
+}
+
+func TestAuthenticate_gcpInstanceIdentityAuthenticator(t *testing.T) {
+	_, key := genSigningCert(t)
+	_, otherKey := genSigningCert(t)
+
+	authenticator := newGCPInstanceIdentityAuthenticator(&staticKeySet{&key.PublicKey}, []string{testAudience}, testPolicy)
+
+	instance := map[string]interface{}{
+		"project_id":     "billing-prod",
+		"project_number": 123456789012,
+		"zone":           "us-central1-a",
+		"instance_id":    "4567890123456789012",
+		"instance_name":  "billing-1",
+	}
+	otherProject := map[string]interface{}{}
+	for k, v := range instance {
+		otherProject[k] = v
+	}
+	otherProject["project_id"] = "billing-dev"
+
+	testCases := map[string]struct {
+		credential         []byte
+		expectedIDs        []string
+		authenticateErrMsg string
+	}{
+		"Signed by another key": {
+			credential:         signJWT(t, otherKey, gcpClaims(testAudience, instance)),
+			authenticateErrMsg: "failed to verify the instance identity token",
+		},
+		"Wrong audience": {
+			credential:         signJWT(t, key, gcpClaims("grpc://other:8060", instance)),
+			authenticateErrMsg: "unexpected instance identity token audience",
+		},
+		"Standard format token": {
+			credential:         signJWT(t, key, gcpClaims(testAudience, nil)),
+			authenticateErrMsg: "the instance identity token has no instance attributes",
+		},
+		"No matching rule": {
+			credential:         signJWT(t, key, gcpClaims(testAudience, otherProject)),
+			authenticateErrMsg: "no identity is granted to instance billing-1 of project billing-dev",
+		},
+		"Valid token": {
+			credential:  signJWT(t, key, gcpClaims(testAudience, instance)),
+			expectedIDs: []string{"spiffe://cluster.local/ns/vm/sa/billing"},
+		},
+	}
+
+	for id, tc := range testCases {
+		ctx := ContextWithAgentCredential(context.Background(), "gcp", tc.credential)
+		caller, err := authenticator.Authenticate(ctx)
+		if len(tc.authenticateErrMsg) > 0 {
+			if err == nil {
+				t.Errorf("Case %s: Succeeded. Error expected: %v", id, tc.authenticateErrMsg)
+			} else if !strings.HasPrefix(err.Error(), tc.authenticateErrMsg) {
+				t.Errorf("Case %s: Incorrect error message: want %s but got %s", id, tc.authenticateErrMsg, err.Error())
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("Case %s: Unexpected Error: %v", id, err)
+		}
+
+		expectedCaller := &Caller{AuthSource: AuthSourceInstanceIdentity, Identities: tc.expectedIDs}
+		if !reflect.DeepEqual(caller, expectedCaller) {
+			t.Errorf("Case %q: Unexpected caller: want %v but got %v", id, expectedCaller, caller)
+		}
+	}
+}
+
+func TestLoadInstanceIdentityPolicy(t *testing.T) {
+	testCases := map[string]struct {
+		policy      string
+		expected    *InstanceIdentityPolicy
+		expectedErr string
+	}{
+		"Valid policy": {
+			policy: `
+rules:
+- platform: aws
+  attributes:
+    accountId: "977777657611"
+    region: us-west-2
+  identity: spiffe://cluster.local/ns/vm/sa/billing
+- platform: gcp
+  attributes:
+    project_id: billing-prod
+    zone: us-central1-a
+  identity: spiffe://cluster.local/ns/vm/sa/billing
+`,
+			expected: testPolicy,
+		},
+		"Unsupported platform": {
+			policy: `
+rules:
+- platform: azure
+  attributes:
+    subscriptionId: abc
+  identity: spiffe://cluster.local/ns/vm/sa/billing
+`,
+			expectedErr: "rule 0 has unsupported platform \"azure\"",
+		},
+		"No attributes": {
+			policy: `
+rules:
+- platform: aws
+  identity: spiffe://cluster.local/ns/vm/sa/billing
+`,
+			expectedErr: "rule 0 has no attributes",
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "instance-identity-policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for id, tc := range testCases {
+		path := filepath.Join(dir, "policy.yaml")
+		if err := ioutil.WriteFile(path, []byte(tc.policy), 0644); err != nil {
+			t.Fatal(err)
+		}
+		policy, err := LoadInstanceIdentityPolicy(path)
+		if len(tc.expectedErr) > 0 {
+			if err == nil || err.Error() != tc.expectedErr {
+				t.Errorf("Case %s: want error %s but got %v", id, tc.expectedErr, err)
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("Case %s: Unexpected Error: %v", id, err)
+		}
+		if !reflect.DeepEqual(policy, tc.expected) {
+			t.Errorf("Case %s: want policy %v but got %v", id, tc.expected, policy)
+		}
+	}
+}
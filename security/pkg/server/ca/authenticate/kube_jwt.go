@@ -15,12 +15,14 @@
 package authenticate
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 
 	"golang.org/x/net/context"
 
 	"istio.io/istio/security/pkg/k8s/tokenreview"
+	"istio.io/pkg/log"
 )
 
 const (
@@ -33,14 +35,33 @@ type tokenReviewClient interface {
 	ValidateK8sJwt(targetJWT string) ([]string, error)
 }
 
+type localTokenVerifier interface {
+	Verify(ctx context.Context, targetJWT string) ([]string, error)
+}
+
 // KubeJWTAuthenticator authenticates K8s JWTs.
 type KubeJWTAuthenticator struct {
 	client      tokenReviewClient
 	trustDomain string
+	// localVerifier verifies projected tokens without calling TokenReview, nil if disabled.
+	localVerifier localTokenVerifier
 }
 
 // NewKubeJWTAuthenticator creates a new kubeJWTAuthenticator.
 func NewKubeJWTAuthenticator(k8sAPIServerURL, caCertPath, jwtPath, trustDomain string) (*KubeJWTAuthenticator, error) {
+	return newKubeJWTAuthenticator(k8sAPIServerURL, caCertPath, jwtPath, trustDomain, "")
+}
+
+// NewKubeJWTAuthenticatorWithLocalVerification creates a new kubeJWTAuthenticator which verifies the
+// projected tokens of the issuer locally, with the keys published by the API server. TokenReview is
+// only called for the tokens of other issuers, or while the issuer can't be discovered.
+func NewKubeJWTAuthenticatorWithLocalVerification(k8sAPIServerURL, caCertPath, jwtPath, trustDomain,
+	issuer string) (*KubeJWTAuthenticator, error) {
+	return newKubeJWTAuthenticator(k8sAPIServerURL, caCertPath, jwtPath, trustDomain, issuer)
+}
+
+func newKubeJWTAuthenticator(k8sAPIServerURL, caCertPath, jwtPath, trustDomain,
+	issuer string) (*KubeJWTAuthenticator, error) {
 	// Read the CA certificate of the k8s apiserver
 	caCert, err := ioutil.ReadFile(caCertPath)
 	if err != nil {
@@ -50,10 +71,18 @@ func NewKubeJWTAuthenticator(k8sAPIServerURL, caCertPath, jwtPath, trustDomain s
 	if err != nil {
 		return nil, fmt.Errorf("failed to read Citadel JWT: %v", err)
 	}
-	return &KubeJWTAuthenticator{
+	a := &KubeJWTAuthenticator{
 		client:      tokenreview.NewK8sSvcAcctAuthn(k8sAPIServerURL, caCert, string(reviewerJWT)),
 		trustDomain: trustDomain,
-	}, nil
+	}
+	if issuer != "" {
+		a.localVerifier, err = newProjectedTokenVerifier(k8sAPIServerURL, issuer, tokenreview.DefaultAudience,
+			caCert, string(reviewerJWT))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
 }
 
 func (a *KubeJWTAuthenticator) AuthenticatorType() string {
@@ -67,9 +96,20 @@ func (a *KubeJWTAuthenticator) Authenticate(ctx context.Context) (*Caller, error
 	if err != nil {
 		return nil, fmt.Errorf("target JWT extraction error: %v", err)
 	}
-	id, err := a.client.ValidateK8sJwt(targetJWT)
-	if err != nil {
-		return nil, fmt.Errorf("failed to validate the JWT: %v", err)
+	var id []string
+	if a.localVerifier != nil {
+		if id, err = a.localVerifier.Verify(ctx, targetJWT); err != nil {
+			if !errors.Is(err, errIssuerNotDiscoverable) {
+				return nil, fmt.Errorf("failed to validate the JWT: %v", err)
+			}
+			log.Debugf("falling back to TokenReview: %v", err)
+			id = nil
+		}
+	}
+	if id == nil {
+		if id, err = a.client.ValidateK8sJwt(targetJWT); err != nil {
+			return nil, fmt.Errorf("failed to validate the JWT: %v", err)
+		}
 	}
 	if len(id) != 2 {
 		return nil, fmt.Errorf("failed to parse the JWT. Validation result length is not 2, but %d", len(id))
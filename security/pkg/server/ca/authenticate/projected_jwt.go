@@ -0,0 +1,171 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authenticate
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	oidc "github.com/coreos/go-oidc"
+	"golang.org/x/net/context"
+	jose "gopkg.in/square/go-jose.v2"
+)
+
+// discoveryRetryInterval is the minimum interval between two OIDC discoveries of the issuer, so that
+// an unavailable discovery endpoint is not called for every CSR.
+const discoveryRetryInterval = time.Minute
+
+// errIssuerNotDiscoverable is returned for the tokens whose keys can't be discovered: tokens of another
+// issuer, such as legacy service account tokens, or of the issuer when its discovery fails. Only these
+// tokens are reviewed by the API server, the other tokens failing verification are rejected.
+var errIssuerNotDiscoverable = errors.New("the issuer of the token is not discoverable")
+
+// projectedTokenVerifier verifies projected service account tokens locally, with the keys the API
+// server publishes through OIDC discovery. go-oidc caches the keys, and fetches them again when a
+// token is signed by an unknown key, so that key rotations are picked up.
+// Unlike TokenReview, it does not check that the pod the token is bound to still exists, so it
+// relies on the short lifetime of projected tokens.
+type projectedTokenVerifier struct {
+	issuer   string
+	audience string
+	// ctx carries the HTTP client used for the discovery and to fetch the keys.
+	ctx context.Context
+
+	mu            sync.Mutex
+	verifier      *oidc.IDTokenVerifier
+	lastDiscovery time.Time
+}
+
+// bearerTransport authenticates the requests to the API server with the Citadel JWT, as the discovery
+// endpoints are not public by default. The keys may be served by another host, which must not get the JWT.
+type bearerTransport struct {
+	token string
+	host  string
+	base  http.RoundTripper
+}
+
+func (t *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.host {
+		return t.base.RoundTrip(req)
+	}
+	// A RoundTripper must not modify the request.
+	req = req.Clone(req.Context())
+	req.Header.Set(httpAuthHeader, bearerTokenPrefix+t.token)
+	return t.base.RoundTrip(req)
+}
+
+func newProjectedTokenVerifier(apiServerURL, issuer, audience string, caCert []byte, token string) (*projectedTokenVerifier, error) {
+	u, err := url.Parse(apiServerURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API server URL %q: %v", apiServerURL, err)
+	}
+	// The keys may be served by a public host, trust the system roots as well as the cluster CA.
+	caCertPool, err := x509.SystemCertPool()
+	if err != nil {
+		caCertPool = x509.NewCertPool()
+	}
+	caCertPool.AppendCertsFromPEM(caCert)
+	client := &http.Client{
+		Transport: &bearerTransport{
+			token: token,
+			host:  u.Host,
+			base: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs: caCertPool,
+				},
+			},
+		},
+	}
+	return &projectedTokenVerifier{
+		issuer:   issuer,
+		audience: audience,
+		ctx:      oidc.ClientContext(context.Background(), client),
+	}, nil
+}
+
+// getVerifier returns the verifier of the issuer, discovering its keys on first use.
+func (v *projectedTokenVerifier) getVerifier() (*oidc.IDTokenVerifier, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.verifier != nil {
+		return v.verifier, nil
+	}
+	if time.Since(v.lastDiscovery) < discoveryRetryInterval {
+		return nil, fmt.Errorf("%w: OIDC discovery of %s failed less than %v ago",
+			errIssuerNotDiscoverable, v.issuer, discoveryRetryInterval)
+	}
+	v.lastDiscovery = time.Now()
+	provider, err := oidc.NewProvider(v.ctx, v.issuer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: OIDC discovery of %s failed: %v", errIssuerNotDiscoverable, v.issuer, err)
+	}
+	v.verifier = provider.Verifier(&oidc.Config{ClientID: v.audience})
+	return v.verifier, nil
+}
+
+// Verify verifies the projected token, and returns {<namespace>, <serviceaccountname>} of the token.
+func (v *projectedTokenVerifier) Verify(ctx context.Context, token string) ([]string, error) {
+	if iss, err := unverifiedIssuer(token); err != nil {
+		return nil, fmt.Errorf("failed to parse the token: %v", err)
+	} else if iss != v.issuer {
+		return nil, fmt.Errorf("%w: the token is issued by %q", errIssuerNotDiscoverable, iss)
+	}
+	verifier, err := v.getVerifier()
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify the projected token: %v", err)
+	}
+
+	var claims struct {
+		Kubernetes struct {
+			Namespace      string `json:"namespace"`
+			ServiceAccount struct {
+				Name string `json:"name"`
+			} `json:"serviceaccount"`
+		} `json:"kubernetes.io"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to extract the service account from the token: %v", err)
+	}
+	if claims.Kubernetes.Namespace == "" || claims.Kubernetes.ServiceAccount.Name == "" {
+		return nil, fmt.Errorf("the token is not a projected service account token")
+	}
+	return []string{claims.Kubernetes.Namespace, claims.Kubernetes.ServiceAccount.Name}, nil
+}
+
+// unverifiedIssuer returns the issuer claimed by the token, without verifying it.
+func unverifiedIssuer(token string) (string, error) {
+	jws, err := jose.ParseSigned(token)
+	if err != nil {
+		return "", err
+	}
+	var claims struct {
+		Issuer string `json:"iss"`
+	}
+	if err := json.Unmarshal(jws.UnsafePayloadWithoutVerification(), &claims); err != nil {
+		return "", err
+	}
+	return claims.Issuer, nil
+}
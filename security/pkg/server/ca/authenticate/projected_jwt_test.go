@@ -0,0 +1,318 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authenticate
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+	jose "gopkg.in/square/go-jose.v2"
+)
+
+// fakeIssuer serves the OIDC discovery document and the keys of a Kubernetes API server.
+type fakeIssuer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	keys    []*rsa.PrivateKey
+	fetches int
+	auth    []string
+	// jwksURI overrides the URL of the keys in the discovery document.
+	jwksURI string
+}
+
+func newFakeIssuer(keys ...*rsa.PrivateKey) *fakeIssuer {
+	f := &fakeIssuer{keys: keys}
+	f.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.auth = append(f.auth, r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			jwksURI := f.URL + "/openid/v1/jwks"
+			if f.jwksURI != "" {
+				jwksURI = f.jwksURI
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"issuer":                                f.URL,
+				"jwks_uri":                              jwksURI,
+				"id_token_signing_alg_values_supported": []string{"RS256"},
+			})
+		case "/openid/v1/jwks":
+			f.fetches++
+			keySet := jose.JSONWebKeySet{}
+			for i, key := range f.keys {
+				keySet.Keys = append(keySet.Keys, jose.JSONWebKey{
+					Key: &key.PublicKey, KeyID: fmt.Sprint(i), Algorithm: "RS256", Use: "sig"})
+			}
+			_ = json.NewEncoder(w).Encode(keySet)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return f
+}
+
+func (f *fakeIssuer) setKeys(keys ...*rsa.PrivateKey) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys = keys
+}
+
+func (f *fakeIssuer) caCert() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: f.Certificate().Raw})
+}
+
+func projectedClaims(iss, aud, ns, sa string) map[string]interface{} {
+	return map[string]interface{}{
+		"iss": iss,
+		"aud": []string{aud},
+		"sub": fmt.Sprintf("system:serviceaccount:%s:%s", ns, sa),
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"kubernetes.io": map[string]interface{}{
+			"namespace":      ns,
+			"pod":            map[string]string{"name": "foo-1", "uid": "1"},
+			"serviceaccount": map[string]string{"name": sa, "uid": "2"},
+		},
+	}
+}
+
+func TestProjectedTokenVerifier(t *testing.T) {
+	_, key := genSigningCert(t)
+	_, rotatedKey := genSigningCert(t)
+	_, otherKey := genSigningCert(t)
+
+	issuer := newFakeIssuer(key)
+	defer issuer.Close()
+	verifier, err := newProjectedTokenVerifier(issuer.URL, issuer.URL, "istio-ca", issuer.caCert(), "citadel-jwt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name        string
+		token       []byte
+		expectedIDs []string
+		expectedErr string
+	}{
+		{
+			name:        "valid token",
+			token:       signJWT(t, key, projectedClaims(issuer.URL, "istio-ca", "foo", "bar")),
+			expectedIDs: []string{"foo", "bar"},
+		},
+		{
+			name:        "wrong audience",
+			token:       signJWT(t, key, projectedClaims(issuer.URL, "api", "foo", "bar")),
+			expectedErr: "failed to verify the projected token",
+		},
+		{
+			name:        "other issuer",
+			token:       signJWT(t, key, projectedClaims("https://other", "istio-ca", "foo", "bar")),
+			expectedErr: "the issuer of the token is not discoverable",
+		},
+		{
+			name:        "unknown key",
+			token:       signJWT(t, otherKey, projectedClaims(issuer.URL, "istio-ca", "foo", "bar")),
+			expectedErr: "failed to verify the projected token",
+		},
+		{
+			name:        "not a service account token",
+			token:       signJWT(t, key, projectedClaims(issuer.URL, "istio-ca", "", "")),
+			expectedErr: "the token is not a projected service account token",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ids, err := verifier.Verify(context.Background(), string(tc.token))
+			if tc.expectedErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tc.expectedErr) {
+					t.Fatalf("want error %s but got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(ids, tc.expectedIDs) {
+				t.Errorf("want %v but got %v", tc.expectedIDs, ids)
+			}
+		})
+	}
+
+	// The keys are cached: a token signed by a known key does not fetch them again.
+	issuer.mu.Lock()
+	fetches := issuer.fetches
+	issuer.mu.Unlock()
+	if _, err := verifier.Verify(context.Background(),
+		string(signJWT(t, key, projectedClaims(issuer.URL, "istio-ca", "foo", "bar")))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	issuer.mu.Lock()
+	if issuer.fetches != fetches {
+		t.Errorf("expected the keys to be cached, fetched %d times", issuer.fetches-fetches)
+	}
+	issuer.mu.Unlock()
+
+	// After a key rotation, tokens signed by the new key are verified.
+	issuer.setKeys(key, rotatedKey)
+	ids, err := verifier.Verify(context.Background(),
+		string(signJWT(t, rotatedKey, projectedClaims(issuer.URL, "istio-ca", "foo", "bar"))))
+	if err != nil {
+		t.Fatalf("failed to verify a token signed by the rotated key: %v", err)
+	}
+	if !reflect.DeepEqual(ids, []string{"foo", "bar"}) {
+		t.Errorf("unexpected identity %v", ids)
+	}
+
+	issuer.mu.Lock()
+	defer issuer.mu.Unlock()
+	for _, auth := range issuer.auth {
+		if auth != "Bearer citadel-jwt" {
+			t.Errorf("expected requests to the issuer to carry the Citadel JWT, got %q", auth)
+		}
+	}
+}
+
+func TestProjectedTokenVerifierKeysOnAnotherHost(t *testing.T) {
+	_, key := genSigningCert(t)
+	keys := newFakeIssuer(key)
+	defer keys.Close()
+	issuer := newFakeIssuer()
+	defer issuer.Close()
+	issuer.jwksURI = keys.URL + "/openid/v1/jwks"
+
+	caCert := append(issuer.caCert(), keys.caCert()...)
+	verifier, err := newProjectedTokenVerifier(issuer.URL, issuer.URL, "istio-ca", caCert, "citadel-jwt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Verify(context.Background(),
+		string(signJWT(t, key, projectedClaims(issuer.URL, "istio-ca", "foo", "bar")))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The Citadel JWT is only sent to the API server.
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+	if len(keys.auth) == 0 {
+		t.Fatal("expected the keys to be fetched")
+	}
+	for _, auth := range keys.auth {
+		if auth != "" {
+			t.Errorf("expected no credential to be sent to the keys host, got %q", auth)
+		}
+	}
+}
+
+func TestProjectedTokenVerifierDiscoveryFailure(t *testing.T) {
+	_, key := genSigningCert(t)
+	issuer := newFakeIssuer()
+	issuer.Close()
+	verifier, err := newProjectedTokenVerifier(issuer.URL, issuer.URL, "istio-ca", issuer.caCert(), "citadel-jwt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := string(signJWT(t, key, projectedClaims(issuer.URL, "istio-ca", "foo", "bar")))
+
+	if _, err := verifier.Verify(context.Background(), token); !errors.Is(err, errIssuerNotDiscoverable) ||
+		!strings.Contains(err.Error(), "OIDC discovery of") {
+		t.Fatalf("expected a discovery error, got %v", err)
+	}
+	// The discovery is not retried for every token.
+	if _, err := verifier.Verify(context.Background(), token); !errors.Is(err, errIssuerNotDiscoverable) ||
+		!strings.Contains(err.Error(), "failed less than") {
+		t.Fatalf("expected the discovery not to be retried, got %v", err)
+	}
+}
+
+type mockLocalTokenVerifier struct {
+	id  []string
+	err error
+}
+
+func (v *mockLocalTokenVerifier) Verify(ctx context.Context, targetJWT string) ([]string, error) {
+	return v.id, v.err
+}
+
+func TestAuthenticate_localVerification(t *testing.T) {
+	testCases := map[string]struct {
+		localVerifier  localTokenVerifier
+		client         tokenReviewClient
+		expectedID     string
+		expectedErrMsg string
+	}{
+		"Verified locally": {
+			localVerifier: &mockLocalTokenVerifier{id: []string{"foo", "bar"}},
+			// TokenReview is not called.
+			client:     &mockTokenReviewClient{err: fmt.Errorf("unexpected review")},
+			expectedID: "spiffe://example.com/ns/foo/sa/bar",
+		},
+		"Falls back to TokenReview": {
+			localVerifier: &mockLocalTokenVerifier{err: fmt.Errorf("%w: legacy token", errIssuerNotDiscoverable)},
+			client:        &mockTokenReviewClient{id: []string{"foo", "baz"}},
+			expectedID:    "spiffe://example.com/ns/foo/sa/baz",
+		},
+		"Rejected locally": {
+			localVerifier: &mockLocalTokenVerifier{err: fmt.Errorf("unknown key")},
+			// TokenReview is not called.
+			client:         &mockTokenReviewClient{id: []string{"foo", "baz"}},
+			expectedErrMsg: "failed to validate the JWT: unknown key",
+		},
+		"Rejected by TokenReview": {
+			localVerifier:  &mockLocalTokenVerifier{err: fmt.Errorf("%w: legacy token", errIssuerNotDiscoverable)},
+			client:         &mockTokenReviewClient{err: fmt.Errorf("test error")},
+			expectedErrMsg: "failed to validate the JWT: test error",
+		},
+	}
+
+	for id, tc := range testCases {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{
+			"authorization": []string{"Bearer bearer-token"},
+		})
+		authenticator := &KubeJWTAuthenticator{client: tc.client, trustDomain: "example.com", localVerifier: tc.localVerifier}
+
+		actualCaller, err := authenticator.Authenticate(ctx)
+		if len(tc.expectedErrMsg) > 0 {
+			if err == nil || err.Error() != tc.expectedErrMsg {
+				t.Errorf("Case %s: want error %s but got %v", id, tc.expectedErrMsg, err)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("Case %s: Unexpected Error: %v", id, err)
+			continue
+		}
+
+		expectedCaller := &Caller{
+			AuthSource: AuthSourceIDToken,
+			Identities: []string{tc.expectedID},
+		}
+		if !reflect.DeepEqual(actualCaller, expectedCaller) {
+			t.Errorf("Case %q: Unexpected caller: want %v but got %v", id, expectedCaller, actualCaller)
+		}
+	}
+}
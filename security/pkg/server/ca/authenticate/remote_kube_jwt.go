@@ -0,0 +1,92 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authenticate
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+	"k8s.io/client-go/kubernetes"
+
+	"istio.io/istio/security/pkg/k8s/tokenreview"
+)
+
+const (
+	RemoteKubeJWTAuthenticatorType = "RemoteKubeJWTAuthenticator"
+
+	// clusterIDHeader is the metadata sent by the node agent with the ID of the cluster of the workload.
+	clusterIDHeader = "clusterid"
+)
+
+// RemoteKubeClientGetter returns the client of a remote cluster, or nil if the cluster is unknown.
+type RemoteKubeClientGetter func(clusterID string) kubernetes.Interface
+
+// RemoteKubeJWTAuthenticator authenticates the K8s JWTs of the workloads running in remote clusters,
+// when the CA runs in an external control plane. The JWT is reviewed by the API server of the cluster
+// named by the node agent, so a token of one cluster is never accepted for another.
+type RemoteKubeJWTAuthenticator struct {
+	getClient   RemoteKubeClientGetter
+	trustDomain string
+}
+
+// NewRemoteKubeJWTAuthenticator creates a new RemoteKubeJWTAuthenticator.
+func NewRemoteKubeJWTAuthenticator(getClient RemoteKubeClientGetter, trustDomain string) *RemoteKubeJWTAuthenticator {
+	return &RemoteKubeJWTAuthenticator{
+		getClient:   getClient,
+		trustDomain: trustDomain,
+	}
+}
+
+func (a *RemoteKubeJWTAuthenticator) AuthenticatorType() string {
+	return RemoteKubeJWTAuthenticatorType
+}
+
+// Authenticate authenticates the call using the K8s JWT from the context, with the TokenReview API of
+// the remote cluster of the caller. The returned Caller.Identities is in SPIFFE format.
+func (a *RemoteKubeJWTAuthenticator) Authenticate(ctx context.Context) (*Caller, error) {
+	clusterID, err := extractClusterID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client := a.getClient(clusterID)
+	if client == nil {
+		return nil, fmt.Errorf("%s is not a remote cluster", clusterID)
+	}
+	targetJWT, err := extractBearerToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("target JWT extraction error: %v", err)
+	}
+	id, err := tokenreview.ValidateK8sJwtWithClient(client, targetJWT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate the JWT in cluster %s: %v", clusterID, err)
+	}
+	return &Caller{
+		AuthSource: AuthSourceIDToken,
+		Identities: []string{fmt.Sprintf(identityTemplate, a.trustDomain, id[0], id[1])},
+	}, nil
+}
+
+func extractClusterID(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", fmt.Errorf("no metadata is attached")
+	}
+	ids := md[clusterIDHeader]
+	if len(ids) == 0 || ids[0] == "" {
+		return "", fmt.Errorf("no cluster ID is presented")
+	}
+	return ids[0], nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authenticate
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+	k8sauth "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+// fakeRemoteCluster returns a client of a cluster authenticating a single token as the service account.
+func fakeRemoteCluster(token, ns, sa string) kubernetes.Interface {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		review := action.(ktesting.CreateAction).GetObject().(*k8sauth.TokenReview)
+		if review.Spec.Token != token {
+			return true, &k8sauth.TokenReview{Status: k8sauth.TokenReviewStatus{Error: "invalid token"}}, nil
+		}
+		return true, &k8sauth.TokenReview{Status: k8sauth.TokenReviewStatus{
+			Authenticated: true,
+			User: k8sauth.UserInfo{
+				Username: fmt.Sprintf("system:serviceaccount:%s:%s", ns, sa),
+				Groups:   []string{"system:serviceaccounts", "system:authenticated"},
+			},
+		}}, nil
+	})
+	return client
+}
+
+// trustworthyJWT returns an unsigned token with an audience and an expiration, as reviewed by the API server.
+func trustworthyJWT(sub string) string {
+	encode := base64.RawStdEncoding.EncodeToString
+	payload := fmt.Sprintf(`{"aud":["istio-ca"],"exp":%d,"sub":%q}`, time.Now().Add(time.Hour).Unix(), sub)
+	return encode([]byte(`{"alg":"RS256"}`)) + "." + encode([]byte(payload)) + ".c2ln"
+}
+
+func TestRemoteKubeJWTAuthenticator(t *testing.T) {
+	east := trustworthyJWT("east")
+	west := trustworthyJWT("west")
+	clusters := map[string]kubernetes.Interface{
+		"east": fakeRemoteCluster(east, "foo", "bar"),
+		"west": fakeRemoteCluster(west, "foo", "baz"),
+	}
+	authenticator := NewRemoteKubeJWTAuthenticator(func(clusterID string) kubernetes.Interface {
+		return clusters[clusterID]
+	}, "example.com")
+
+	testCases := map[string]struct {
+		metadata       metadata.MD
+		expectedID     string
+		expectedErrMsg string
+	}{
+		"No cluster ID": {
+			metadata:       metadata.MD{"authorization": []string{"Bearer " + east}},
+			expectedErrMsg: "no cluster ID is presented",
+		},
+		"Unknown cluster": {
+			metadata: metadata.MD{
+				"authorization": []string{"Bearer " + east},
+				"clusterid":     []string{"north"},
+			},
+			expectedErrMsg: "north is not a remote cluster",
+		},
+		"No bearer token": {
+			metadata:       metadata.MD{"clusterid": []string{"east"}},
+			expectedErrMsg: "target JWT extraction error: no HTTP authorization header exists",
+		},
+		"Token of another cluster": {
+			metadata: metadata.MD{
+				"authorization": []string{"Bearer " + west},
+				"clusterid":     []string{"east"},
+			},
+			expectedErrMsg: "failed to validate the JWT in cluster east: " +
+				"the service account authentication returns an error: invalid token",
+		},
+		"Successful": {
+			metadata: metadata.MD{
+				"authorization": []string{"Bearer " + west},
+				"clusterid":     []string{"west"},
+			},
+			expectedID: "spiffe://example.com/ns/foo/sa/baz",
+		},
+	}
+
+	for id, tc := range testCases {
+		ctx := metadata.NewIncomingContext(context.Background(), tc.metadata)
+		actualCaller, err := authenticator.Authenticate(ctx)
+		if len(tc.expectedErrMsg) > 0 {
+			if err == nil || err.Error() != tc.expectedErrMsg {
+				t.Errorf("Case %s: want error %s but got %v", id, tc.expectedErrMsg, err)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("Case %s: Unexpected Error: %v", id, err)
+			continue
+		}
+
+		expectedCaller := &Caller{
+			AuthSource: AuthSourceIDToken,
+			Identities: []string{tc.expectedID},
+		}
+		if !reflect.DeepEqual(actualCaller, expectedCaller) {
+			t.Errorf("Case %q: Unexpected caller: want %v but got %v", id, expectedCaller, actualCaller)
+		}
+	}
+}
@@ -27,12 +27,15 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/status"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	caerror "istio.io/istio/security/pkg/pki/error"
 	"istio.io/istio/security/pkg/pki/util"
+	"istio.io/istio/security/pkg/platform"
 	"istio.io/istio/security/pkg/registry"
 	"istio.io/istio/security/pkg/server/ca/authenticate"
 	pb "istio.io/istio/security/proto"
+	"istio.io/pkg/env"
 	"istio.io/pkg/log"
 	"istio.io/pkg/version"
 )
@@ -45,7 +48,14 @@ const (
 	certExpirationBuffer = time.Minute
 )
 
-var serverCaLog = log.RegisterScope("serverCaLog", "Citadel server log", 0)
+var (
+	serverCaLog = log.RegisterScope("serverCaLog", "Citadel server log", 0)
+
+	localJWTIssuer = env.RegisterStringVar("CITADEL_LOCAL_JWT_ISSUER", "",
+		"The issuer of the projected service account tokens, e.g. https://kubernetes.default.svc. If set, "+
+			"the tokens are verified locally with the keys discovered from the issuer, and the TokenReview API "+
+			"is only called for the tokens which cannot be verified locally.")
+)
 
 type authenticator interface {
 	Authenticate(ctx context.Context) (*authenticate.Caller, error)
@@ -138,6 +148,7 @@ func extractRootCertExpiryTimestamp(ca CertificateAuthority) float64 {
 // [TODO](myidpt): Deprecate this function.
 func (s *Server) HandleCSR(ctx context.Context, request *pb.CsrRequest) (*pb.CsrResponse, error) {
 	s.monitoring.CSR.Increment()
+	ctx = authenticate.ContextWithAgentCredential(ctx, request.CredentialType, request.NodeAgentCredential)
 	caller := s.authenticate(ctx)
 	if caller == nil || len(caller.Identities) == 0 {
 		serverCaLog.Warn("request authentication failure, no caller identity")
@@ -240,8 +251,8 @@ func NewWithGRPC(grpc *grpc.Server, ca CertificateAuthority, ttl time.Duration,
 
 	// Only add k8s jwt authenticator if SDS is enabled.
 	if sdsEnabled {
-		authenticator, err := authenticate.NewKubeJWTAuthenticator(k8sAPIServerURL, caCertPath, jwtPath,
-			trustDomain)
+		authenticator, err := authenticate.NewKubeJWTAuthenticatorWithLocalVerification(k8sAPIServerURL, caCertPath,
+			jwtPath, trustDomain, localJWTIssuer.Get())
 		if err == nil {
 			authenticators = append(authenticators, authenticator)
 			serverCaLog.Info("added K8s JWT authenticator")
@@ -280,6 +291,34 @@ func NewWithGRPC(grpc *grpc.Server, ca CertificateAuthority, ttl time.Duration,
 	return server, nil
 }
 
+// AddInstanceIdentityAuthenticators authenticates the node agents of AWS and GCP instances with their
+// platform credentials, granting them the identities of the policy file. AWS instances must first
+// authenticate within maxAge of their launch, and are bound to their node agent in a ConfigMap of the namespace.
+// The binding of an instance expires when it has not renewed its certificate for twice the certificate TTL.
+func (s *Server) AddInstanceIdentityAuthenticators(policyFile string, maxAge time.Duration,
+	core corev1.CoreV1Interface, namespace string) error {
+	policy, err := authenticate.LoadInstanceIdentityPolicy(policyFile)
+	if err != nil {
+		return err
+	}
+
+	awsAuthenticator, err := authenticate.NewAWSInstanceIdentityAuthenticator([]byte(platform.AWSCertificatePem), policy,
+		maxAge, 2*s.serverCertTTL, core, namespace)
+	if err != nil {
+		return err
+	}
+	s.Authenticators = append(s.Authenticators, awsAuthenticator)
+	serverCaLog.Info("added AWS instance identity authenticator")
+
+	audiences := make([]string, 0, len(s.hostnames))
+	for _, host := range s.hostnames {
+		audiences = append(audiences, fmt.Sprintf("grpc://%s:%d", host, s.port))
+	}
+	s.Authenticators = append(s.Authenticators, authenticate.NewGCPInstanceIdentityAuthenticator(audiences, policy))
+	serverCaLog.Info("added GCP instance identity authenticator")
+	return nil
+}
+
 func (s *Server) createTLSServerOption() grpc.ServerOption {
 	cp := x509.NewCertPool()
 	rootCertBytes := s.ca.GetCAKeyCertBundle().GetRootCertPem()
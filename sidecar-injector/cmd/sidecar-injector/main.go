@@ -35,15 +35,23 @@ import (
 
 	"istio.io/istio/pkg/cmd"
 	"istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/kube/events"
 	"istio.io/istio/pkg/kube/inject"
 	"istio.io/istio/pkg/util"
 	"istio.io/pkg/collateral"
+	"istio.io/pkg/env"
 	"istio.io/pkg/log"
 	"istio.io/pkg/probe"
 	"istio.io/pkg/version"
 )
 
 var (
+	podNameVar = env.RegisterStringVar("POD_NAME", "",
+		"Name of the injector pod, its identity in the election of the uninjected pods controller.")
+	podNamespaceVar = env.RegisterStringVar("POD_NAMESPACE", "",
+		"Namespace of the injector pod, which holds the lock electing the uninjected pods controller. "+
+			"The controller runs in every replica if it is empty.")
+
 	flags = struct {
 		loggingOptions *log.Options
 
@@ -62,6 +70,10 @@ var (
 		webhookName            string
 		monitoringPort         int
 		reconcileWebhookConfig bool
+
+		uninjectedPodsCheckInterval  time.Duration
+		restartUninjectedPods        bool
+		uninjectedPodsRestartBackoff time.Duration
 	}{
 		loggingOptions: log.DefaultOptions(),
 	}
@@ -90,6 +102,12 @@ var (
 				HealthCheckFile:     flags.healthCheckFile,
 				MonitoringPort:      flags.monitoringPort,
 			}
+			if client, err := kube.CreateClientset(flags.kubeconfigFile, ""); err == nil {
+				parameters.EventRecorder = events.NewRecorder(client.CoreV1(), "sidecar-injector")
+				defer parameters.EventRecorder.Shutdown()
+			} else {
+				log.Warnf("Failed to create the client emitting the injection events: %v", err)
+			}
 			wh, err := inject.NewWebhook(parameters)
 			if err != nil {
 				return multierror.Prefix(err, "failed to create injection webhook")
@@ -102,6 +120,23 @@ var (
 				}
 			}
 
+			if flags.uninjectedPodsCheckInterval > 0 {
+				client, err := kube.CreateClientset(flags.kubeconfigFile, "")
+				if err != nil {
+					return multierror.Prefix(err, "failed to create the uninjected pods controller")
+				}
+				c := inject.NewUninjectedPodsController(client, wh.SidecarConfig, inject.UninjectedPodsOptions{
+					WebhookConfigName: flags.webhookConfigName,
+					WebhookName:       flags.webhookName,
+					CheckInterval:     flags.uninjectedPodsCheckInterval,
+					Restart:           flags.restartUninjectedPods,
+					RestartBackoff:    flags.uninjectedPodsRestartBackoff,
+					ElectionNamespace: podNamespaceVar.Get(),
+					Identity:          podNameVar.Get(),
+				})
+				go c.Run(stop)
+			}
+
 			go wh.Run(stop)
 			cmd.WaitSignal(stop)
 			return nil
@@ -267,6 +302,14 @@ func init() {
 		"Name of the webhook entry in the webhook config.")
 	rootCmd.PersistentFlags().BoolVar(&flags.reconcileWebhookConfig, "reconcileWebhookConfig", true,
 		"Enable managing webhook configuration.")
+	rootCmd.PersistentFlags().DurationVar(&flags.uninjectedPodsCheckInterval, "uninjectedPodsCheckInterval", 0,
+		"Configure how frequently to check for pods created without sidecar in namespaces with injection enabled. "+
+			"Value of zero disables the check.")
+	rootCmd.PersistentFlags().BoolVar(&flags.restartUninjectedPods, "restartUninjectedPods", false,
+		"Restart the deployments, statefulsets and daemonsets of the pods created without sidecar, once the injector is healthy. "+
+			"Requires --uninjectedPodsCheckInterval.")
+	rootCmd.PersistentFlags().DurationVar(&flags.uninjectedPodsRestartBackoff, "uninjectedPodsRestartBackoff", 30*time.Minute,
+		"Minimum time between two restarts of the same workload by --restartUninjectedPods.")
 	// Attach the Istio logging options to the command.
 	flags.loggingOptions.AttachCobraFlags(rootCmd)
 
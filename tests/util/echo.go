@@ -129,6 +129,10 @@ func (h *pilotTestHandler) ForwardEcho(ctx context.Context, in *echopb.ForwardEc
 	return nil, fmt.Errorf("unsupported operation")
 }
 
+func (h *pilotTestHandler) SetBehavior(ctx context.Context, in *echopb.SetBehaviorRequest) (*echopb.SetBehaviorResponse, error) {
+	return nil, fmt.Errorf("unsupported operation")
+}
+
 func (h *pilotTestHandler) WebSocketEcho(w http.ResponseWriter, r *http.Request) {
 	body := bytes.Buffer{}
 	h.addResponsePayload(r, &body) // create resp payload apriori